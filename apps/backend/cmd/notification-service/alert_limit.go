@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// alertCountKeyPrefix namespaces the Redis counters of alerts sent to a user per hour and day
+const alertCountKeyPrefix = "notification_alert_count:"
+
+// alertCounter counts the alerts sent to users in fixed windows
+type alertCounter interface {
+	Count(ctx context.Context, key string) (int, error)
+	Increment(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// redisAlertCounter keeps alert counts as Redis keys that expire after their window,
+// so limits survive restarts and are shared between service instances
+type redisAlertCounter struct {
+	client *redis.Client
+}
+
+// newRedisAlertCounter returns a Redis-backed alert counter, or nil without a client
+func newRedisAlertCounter(client *redis.Client) alertCounter {
+	if client == nil {
+		return nil
+	}
+	return &redisAlertCounter{client: client}
+}
+
+// Count returns the key's count, or 0 if it doesn't exist
+func (r *redisAlertCounter) Count(ctx context.Context, key string) (int, error) {
+	count, err := r.client.Get(ctx, key).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return count, err
+}
+
+// Increment adds one to the key, which expires after ttl
+func (r *redisAlertCounter) Increment(ctx context.Context, key string, ttl time.Duration) error {
+	pipe := r.client.TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// alertCountKeys are the counter keys for the clock hour and day containing now,
+// in the user's timezone
+func alertCountKeys(user User, now time.Time) (hour, day string) {
+	local := now.In(userLocation(user))
+	prefix := alertCountKeyPrefix + user.ID.Hex()
+	return prefix + ":hour:" + local.Format("2006010215"), prefix + ":day:" + local.Format("20060102")
+}
+
+// hasAlertLimits reports whether the user set an hourly or daily alert limit
+func hasAlertLimits(user User) bool {
+	return user.MaxAlertsPerHour > 0 || user.MaxAlertsPerDay > 0
+}
+
+// alertLimitReset returns when the user's alert limits next let an alert through,
+// or the zero time if they haven't reached them. Counter errors fail open so alerts still go out.
+func (s *NotificationService) alertLimitReset(user User, now time.Time) time.Time {
+	if !hasAlertLimits(user) || s.alertCounts == nil {
+		return time.Time{}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	local := now.In(userLocation(user))
+	hourKey, dayKey := alertCountKeys(user, now)
+
+	// The daily limit resets last, so it's checked first
+	if user.MaxAlertsPerDay > 0 {
+		sent, err := s.alertCounts.Count(ctx, dayKey)
+		if err != nil {
+			s.logger.Warn("Failed to check daily alert limit", map[string]interface{}{"user_email": user.Email, "error": err.Error()})
+			return time.Time{}
+		}
+		if sent >= user.MaxAlertsPerDay {
+			return time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, local.Location())
+		}
+	}
+	if user.MaxAlertsPerHour > 0 {
+		sent, err := s.alertCounts.Count(ctx, hourKey)
+		if err != nil {
+			s.logger.Warn("Failed to check hourly alert limit", map[string]interface{}{"user_email": user.Email, "error": err.Error()})
+			return time.Time{}
+		}
+		if sent >= user.MaxAlertsPerHour {
+			return time.Date(local.Year(), local.Month(), local.Day(), local.Hour()+1, 0, 0, 0, local.Location())
+		}
+	}
+	return time.Time{}
+}
+
+// countAlertSent counts an alert sent to the user towards their hourly and daily limits
+func (s *NotificationService) countAlertSent(user User, now time.Time) {
+	if !hasAlertLimits(user) || s.alertCounts == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Kept a little past their window so a count read just before it rolls over is still there
+	hourKey, dayKey := alertCountKeys(user, now)
+	if err := s.alertCounts.Increment(ctx, hourKey, 2*time.Hour); err != nil {
+		s.logger.Warn("Failed to count alert towards hourly limit", map[string]interface{}{"user_email": user.Email, "error": err.Error()})
+	}
+	if err := s.alertCounts.Increment(ctx, dayKey, 48*time.Hour); err != nil {
+		s.logger.Warn("Failed to count alert towards daily limit", map[string]interface{}{"user_email": user.Email, "error": err.Error()})
+	}
+}
+
+// limitedBatch is the slots held for a user who reached their alert limit
+type limitedBatch struct {
+	user  User
+	slots []SlotData
+	timer *time.Timer
+}
+
+// limitAlerts handles a batch for a user over their alert limit. By default the
+// slots are dropped; users who opted in get them in one summary once the limit resets.
+func (s *NotificationService) limitAlerts(user User, slots []SlotData, resetAt time.Time) {
+	if !user.BufferLimitedAlerts {
+		s.logger.Info("User reached their alert limit, dropping alerts", map[string]interface{}{"user_email": user.Email, "slots": len(slots), "resets_at": resetAt.Format(time.RFC3339)})
+		s.metrics.addAlertsLimited(len(slots))
+		return
+	}
+
+	s.batchMutex.Lock()
+	defer s.batchMutex.Unlock()
+
+	if s.limitHeld == nil {
+		s.limitHeld = make(map[string]*limitedBatch)
+	}
+
+	held, pending := s.limitHeld[user.Email]
+	if !pending {
+		s.logger.Info("User reached their alert limit, holding alerts for a summary", map[string]interface{}{"user_email": user.Email, "resets_at": resetAt.Format(time.RFC3339)})
+		held = &limitedBatch{}
+		held.timer = time.AfterFunc(time.Until(resetAt), func() {
+			s.releaseLimitedAlerts(user.Email)
+		})
+		s.limitHeld[user.Email] = held
+	}
+	held.user = user
+	for _, slot := range slots {
+		slot.MissedByLimit = true
+		held.slots = append(held.slots, slot)
+	}
+}
+
+// releaseLimitedAlerts sends the summary of the slots held for a user once their limit resets
+func (s *NotificationService) releaseLimitedAlerts(userEmail string) {
+	s.batchMutex.Lock()
+	held, ok := s.limitHeld[userEmail]
+	delete(s.limitHeld, userEmail)
+	// Registered under the lock so Shutdown, which waits for sends, sees this one
+	if ok {
+		s.sendWG.Add(1)
+		defer s.sendWG.Done()
+	}
+	s.batchMutex.Unlock()
+
+	if ok {
+		s.sendLimitSummary(held.user, held.slots, time.Now())
+	}
+}
+
+// releaseAllLimitedAlerts sends every held summary without waiting for the limits to reset
+func (s *NotificationService) releaseAllLimitedAlerts() {
+	s.batchMutex.Lock()
+	held := s.limitHeld
+	s.limitHeld = nil
+	s.batchMutex.Unlock()
+
+	defer s.beginEmailBatch()()
+	for _, batch := range held {
+		batch.timer.Stop()
+		s.sendLimitSummary(batch.user, batch.slots, time.Now())
+	}
+}
+
+// sendLimitSummary sends the user the slots they missed while over their alert
+// limit, skipping any whose date has passed. The summary counts as one alert.
+func (s *NotificationService) sendLimitSummary(user User, slots []SlotData, now time.Time) {
+	today := now.In(userLocation(user)).Format("2006-01-02")
+	upcoming := make([]SlotData, 0, len(slots))
+	for _, slot := range slots {
+		if slot.Date >= today {
+			upcoming = append(upcoming, slot)
+		}
+	}
+	if len(upcoming) == 0 {
+		return
+	}
+
+	if err := s.sendBatchedNotification(user, upcoming); err != nil {
+		s.logger.Error("Failed to send alert limit summary", map[string]interface{}{"user_email": user.Email, "slots": len(upcoming), "error": err.Error()})
+		return
+	}
+	s.logger.Info("Sent alert limit summary", map[string]interface{}{"user_email": user.Email, "slots": len(upcoming)})
+	s.countAlertSent(user, now)
+}
+
+// isLimitSummary reports whether the slots are a summary of alerts missed over the user's limit
+func isLimitSummary(slots []SlotData) bool {
+	for _, slot := range slots {
+		if !slot.MissedByLimit {
+			return false
+		}
+	}
+	return len(slots) > 0
+}
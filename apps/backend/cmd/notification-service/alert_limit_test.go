@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryAlertCounter is an in-memory alertCounter for tests
+type memoryAlertCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+	err    error
+}
+
+func newMemoryAlertCounter() *memoryAlertCounter {
+	return &memoryAlertCounter{counts: make(map[string]int)}
+}
+
+func (m *memoryAlertCounter) Count(ctx context.Context, key string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[key], m.err
+}
+
+func (m *memoryAlertCounter) Increment(ctx context.Context, key string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[key]++
+	return m.err
+}
+
+func TestAlertLimitReset(t *testing.T) {
+	s := newTestNotificationService()
+	counter := newMemoryAlertCounter()
+	s.alertCounts = counter
+
+	user := User{ID: primitive.NewObjectID(), Timezone: "America/New_York", MaxAlertsPerHour: 2, MaxAlertsPerDay: 3}
+	newYork := loadLocation("America/New_York")
+	now := time.Date(2024, 6, 15, 18, 20, 0, 0, newYork)
+
+	assert.True(t, s.alertLimitReset(user, now).IsZero(), "nothing sent yet")
+
+	s.countAlertSent(user, now)
+	s.countAlertSent(user, now)
+	assert.Equal(t, time.Date(2024, 6, 15, 19, 0, 0, 0, newYork), s.alertLimitReset(user, now), "hourly limit resets at the next hour")
+	assert.True(t, s.alertLimitReset(user, now.Add(time.Hour)).IsZero(), "a new hour starts a new count")
+
+	s.countAlertSent(user, now.Add(time.Hour))
+	assert.Equal(t, time.Date(2024, 6, 16, 0, 0, 0, 0, newYork), s.alertLimitReset(user, now.Add(2*time.Hour)), "daily limit resets at local midnight")
+
+	assert.True(t, s.alertLimitReset(User{ID: user.ID}, now).IsZero(), "no limits set")
+
+	counter.err = errors.New("redis down")
+	assert.True(t, s.alertLimitReset(user, now).IsZero(), "counter errors let alerts through")
+}
+
+func TestSendUserBatch_DropsAlertsOverLimit(t *testing.T) {
+	user := User{ID: primitive.NewObjectID(), Email: "strict@example.com", EmailEnabled: true, MaxAlertsPerHour: 1}
+
+	s := newTestNotificationService()
+	s.users = []User{user}
+	s.alertCounts = newMemoryAlertCounter()
+	s.metrics = newNotificationMetrics()
+	channel := newRecordingChannel()
+	s.registerChannel(ChannelEmail, channel)
+
+	s.sendUserBatch(user.Email, testAlertSlots())
+	s.sendUserBatch(user.Email, testAlertSlots())
+
+	assert.Len(t, channel.sendTimes(user.Email), 1)
+	assert.Equal(t, 3.0, testutil.ToFloat64(s.metrics.alertsLimited))
+	assert.Empty(t, s.limitHeld, "users who didn't opt in to summaries get nothing held")
+}
+
+func TestSendUserBatch_BuffersAlertsOverLimit(t *testing.T) {
+	user := User{ID: primitive.NewObjectID(), Email: "buffered@example.com", EmailEnabled: true, MaxAlertsPerDay: 1, BufferLimitedAlerts: true}
+	tomorrow := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
+	slot := func(court string) SlotData {
+		return SlotData{VenueName: "Victoria Park", CourtName: court, Date: tomorrow, StartTime: "18:00", EndTime: "19:00", BookingURL: "https://example.com/vp"}
+	}
+
+	s := newTestNotificationService()
+	s.users = []User{user}
+	s.alertCounts = newMemoryAlertCounter()
+	sender := &capturingHTMLSender{}
+	s.registerChannel(ChannelEmail, sender)
+
+	s.sendUserBatch(user.Email, []SlotData{slot("Court 1")})
+	require.Len(t, sender.slots, 1)
+
+	s.sendUserBatch(user.Email, []SlotData{slot("Court 2")})
+	s.sendUserBatch(user.Email, []SlotData{slot("Court 3"), {VenueName: "Victoria Park", Date: "2020-01-01"}})
+	assert.Equal(t, "Court 1", sender.slots[0].CourtName, "nothing more is sent while over the limit")
+	require.Contains(t, s.limitHeld, user.Email)
+	assert.Len(t, s.limitHeld[user.Email].slots, 3)
+
+	// The limit resets, and the summary goes out without the slot that's already past
+	s.releaseLimitedAlerts(user.Email)
+	require.Len(t, sender.slots, 2)
+	assert.True(t, isLimitSummary(sender.slots))
+	assert.Contains(t, sender.courtDetails, "You hit your alert limit - here are the 2 courts you missed!")
+	assert.Empty(t, s.limitHeld)
+}
+
+func TestShutdown_SendsLimitSummaries(t *testing.T) {
+	user := User{ID: primitive.NewObjectID(), Email: "buffered@example.com", EmailEnabled: true, MaxAlertsPerDay: 1, BufferLimitedAlerts: true}
+
+	s := newTestNotificationService()
+	s.users = []User{user}
+	channel := newRecordingChannel()
+	s.registerChannel(ChannelEmail, channel)
+
+	s.limitAlerts(user, []SlotData{{VenueName: "Victoria Park", Date: time.Now().AddDate(0, 0, 1).Format("2006-01-02")}}, time.Now().Add(time.Hour))
+	require.NoError(t, s.Shutdown(context.Background()))
+
+	assert.Len(t, channel.sendTimes(user.Email), 1, "held summaries are sent rather than lost")
+}
+
+func TestLimitSummaryContent(t *testing.T) {
+	missed := testAlertSlots()
+	for i := range missed {
+		missed[i].MissedByLimit = true
+	}
+
+	assert.Equal(t, "🎾 Courts you missed while over your alert limit", slotAlertSubject(missed, english))
+	assert.Equal(t, "🎾 New courts available!", slotAlertSubject(append(missed, testAlertSlots()[0]), english), "only all-missed batches are summaries")
+	assert.Equal(t, "Has alcanzado tu límite de alertas: ¡estas son las 3 pistas que te perdiste!", alertHeadline(missed, localeFor(LanguageSpanish)))
+	assert.Contains(t, formatBatchedSMSDetails(missed), "Alert limit reached. 3 courts you missed:")
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// recordingChannel captures sends so tests can assert on when each user was notified
+type recordingChannel struct {
+	mu    sync.Mutex
+	sends map[string][]time.Time
+}
+
+func newRecordingChannel() *recordingChannel {
+	return &recordingChannel{sends: make(map[string][]time.Time)}
+}
+
+func (c *recordingChannel) Send(toAddress, courtDetails, bookingLink string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sends[toAddress] = append(c.sends[toAddress], time.Now())
+	return nil
+}
+
+func (c *recordingChannel) sendTimes(address string) []time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Time(nil), c.sends[address]...)
+}
+
+// fakeTimer is a batch timer that only fires when a test calls fire
+type fakeTimer struct {
+	delay   time.Duration
+	fire    func()
+	stopped bool
+}
+
+func (t *fakeTimer) Stop() bool {
+	wasActive := !t.stopped
+	t.stopped = true
+	return wasActive
+}
+
+// fakeTimers records the batch timers a service starts, in order
+type fakeTimers []*fakeTimer
+
+func (f *fakeTimers) start(d time.Duration, fn func()) batchTimer {
+	timer := &fakeTimer{delay: d, fire: fn}
+	*f = append(*f, timer)
+	return timer
+}
+
+var batchTestSlot = SlotData{VenueName: "Victoria Park", CourtName: "Court 1", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00"}
+
+// newBatchTestService returns a service batching the users' slots over window,
+// with timers that only fire by hand and an email channel that records sends
+func newBatchTestService(window time.Duration, users ...User) (*NotificationService, *fakeTimers, *recordingChannel) {
+	timers := &fakeTimers{}
+	channel := newRecordingChannel()
+	s := newTestNotificationService()
+	s.BatchWindow = window
+	s.startBatchTimer = timers.start
+	s.users = users
+	s.registerChannel(ChannelEmail, channel)
+	return s, timers, channel
+}
+
+func TestAddSlotToBatch_PerUserTimers(t *testing.T) {
+	const window = 10 * time.Second
+
+	userA := User{ID: primitive.NewObjectID(), Email: "busy@example.com", EmailEnabled: true}
+	userB := User{ID: primitive.NewObjectID(), Email: "quiet@example.com", EmailEnabled: true}
+	s, timers, channel := newBatchTestService(window, userA, userB)
+
+	// User A gets a steady stream of slots, and user B's single slot arrives in the middle
+	s.addSlotToBatch(userA, batchTestSlot)
+	s.addSlotToBatch(userA, batchTestSlot)
+	s.addSlotToBatch(userB, batchTestSlot)
+	s.addSlotToBatch(userA, batchTestSlot)
+
+	// Each user's first slot starts their own timer for a full window, and A's
+	// later slots don't push it back
+	require.Len(t, *timers, 2)
+	timerA, timerB := (*timers)[0], (*timers)[1]
+	assert.Equal(t, window, timerA.delay)
+	assert.Equal(t, window, timerB.delay)
+
+	// B's window ends: B is sent their slot while A keeps collecting
+	timerB.fire()
+	assert.Len(t, channel.sendTimes(userB.Email), 1)
+	assert.Empty(t, channel.sendTimes(userA.Email))
+
+	// A's window ends on schedule even though slots never stopped arriving
+	s.addSlotToBatch(userA, batchTestSlot)
+	require.Len(t, *timers, 2)
+	timerA.fire()
+	assert.Len(t, channel.sendTimes(userA.Email), 1)
+
+	// A's next slot starts a new window; B only had one slot, so one notification
+	s.addSlotToBatch(userA, batchTestSlot)
+	require.Len(t, *timers, 3)
+	assert.Equal(t, window, (*timers)[2].delay)
+	assert.Len(t, channel.sendTimes(userB.Email), 1)
+}
+
+func TestFlushBatchedNotifications_OnlyFlushesRequestedUser(t *testing.T) {
+	userA := User{ID: primitive.NewObjectID(), Email: "a@example.com", EmailEnabled: true}
+	userB := User{ID: primitive.NewObjectID(), Email: "b@example.com", EmailEnabled: true}
+	s, timers, channel := newBatchTestService(time.Hour, userA, userB)
+
+	s.addSlotToBatch(userA, batchTestSlot)
+	s.addSlotToBatch(userB, batchTestSlot)
+
+	s.flushBatchedNotifications(userA.Email)
+
+	assert.Len(t, channel.sendTimes(userA.Email), 1)
+	assert.Empty(t, channel.sendTimes(userB.Email))
+	assert.True(t, (*timers)[0].stopped, "A's timer is stopped by the flush")
+	assert.False(t, (*timers)[1].stopped)
+
+	s.batchMutex.RLock()
+	assert.Contains(t, s.slotBatch, userB.Email)
+	assert.Contains(t, s.batchTimers, userB.Email)
+	assert.NotContains(t, s.batchTimers, userA.Email)
+	s.batchMutex.RUnlock()
+
+	// Flushing everything sends what's left
+	s.flushBatchedNotifications()
+	assert.Len(t, channel.sendTimes(userB.Email), 1)
+}
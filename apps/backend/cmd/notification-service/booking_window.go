@@ -0,0 +1,25 @@
+package main
+
+import (
+	"time"
+
+	"tennis-booker/internal/models"
+)
+
+// bookingWindow returns how many days ahead the slot's venue takes bookings, or 0 if unknown
+func (s *NotificationService) bookingWindow(slot SlotData) int {
+	s.usersMutex.RLock()
+	defer s.usersMutex.RUnlock()
+
+	if days, ok := s.bookingWindows[slot.VenueID]; ok {
+		return days
+	}
+	return s.bookingWindows[slot.VenueName]
+}
+
+// withinBookingWindow reports whether the slot's date can be booked as of now. Slots
+// past the venue's booking window can't be booked yet, so alerting on them only
+// sends users to a booking page that turns them away.
+func (s *NotificationService) withinBookingWindow(slot SlotData, now time.Time) bool {
+	return models.WithinBookingWindow(slot.Date, s.bookingWindow(slot), now.In(s.venueLocation(slot)))
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithinBookingWindow(t *testing.T) {
+	s := newTestNotificationService()
+	s.bookingWindows = map[string]int{"venue123": 7, "Central Court": 2}
+
+	// 23:30 UTC is already the next day in Madrid
+	now := time.Date(2024, 6, 10, 23, 30, 0, 0, time.UTC)
+	s.venueTimezones = map[string]string{"venue123": "Europe/Madrid"}
+
+	assert.True(t, s.withinBookingWindow(SlotData{VenueID: "venue123", Date: "2024-06-18"}, now))
+	assert.False(t, s.withinBookingWindow(SlotData{VenueID: "venue123", Date: "2024-06-19"}, now))
+	assert.False(t, s.withinBookingWindow(SlotData{VenueID: "venue123", Date: "2024-06-10"}, now), "already past in the venue's zone")
+
+	// Central Court has no timezone, so it's the 11th in London too
+	assert.True(t, s.withinBookingWindow(SlotData{VenueName: "Central Court", Date: "2024-06-13"}, now))
+	assert.False(t, s.withinBookingWindow(SlotData{VenueName: "Central Court", Date: "2024-06-14"}, now))
+
+	// Venues without a booking window only have past dates rejected
+	assert.True(t, s.withinBookingWindow(SlotData{VenueID: "unknown", Date: "2024-12-25"}, now))
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// venueCooldownKeyPrefix namespaces the Redis keys that mark a user+venue cooldown
+const venueCooldownKeyPrefix = "notification_cooldown:"
+
+// cooldownStore tracks which user+venue pairs are cooling down and for how long
+type cooldownStore interface {
+	Remaining(ctx context.Context, key string) (time.Duration, error)
+	Start(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// redisCooldownStore keeps cooldowns as Redis keys that expire when the cooldown ends,
+// so they survive restarts and are shared between service instances
+type redisCooldownStore struct {
+	client *redis.Client
+}
+
+// newRedisCooldownStore returns a Redis-backed cooldown store, or nil without a client
+func newRedisCooldownStore(client *redis.Client) cooldownStore {
+	if client == nil {
+		return nil
+	}
+	return &redisCooldownStore{client: client}
+}
+
+// Remaining returns how long the key has left to live, or 0 if it doesn't exist
+func (r *redisCooldownStore) Remaining(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, nil // -2: no such key, -1: no expiry (never set by us)
+	}
+	return ttl, nil
+}
+
+// Start sets the key to expire after ttl, restarting any cooldown already running
+func (r *redisCooldownStore) Start(ctx context.Context, key string, ttl time.Duration) error {
+	return r.client.Set(ctx, key, time.Now().Unix(), ttl).Err()
+}
+
+// venueKey identifies a slot's venue, preferring the stable venue ID
+func venueKey(slot SlotData) string {
+	if slot.VenueID != "" {
+		return slot.VenueID
+	}
+	return slot.VenueName
+}
+
+// venueCooldownKey is the Redis key for a user's cooldown on one venue
+func venueCooldownKey(user User, venue string) string {
+	return venueCooldownKeyPrefix + user.ID.Hex() + ":" + venue
+}
+
+// venueCooldownRemaining returns how long the user's cooldown on the slot's venue
+// has left, or 0 if there is none. Store errors fail open so alerts still go out.
+func (s *NotificationService) venueCooldownRemaining(user User, slot SlotData) time.Duration {
+	if user.VenueCooldown <= 0 || s.venueCooldowns == nil {
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	remaining, err := s.venueCooldowns.Remaining(ctx, venueCooldownKey(user, venueKey(slot)))
+	if err != nil {
+		s.logger.Warn("Failed to check venue cooldown", map[string]interface{}{"user_email": user.Email, "venue": venueKey(slot), "error": err.Error()})
+		return 0
+	}
+	return remaining
+}
+
+// startVenueCooldowns starts the user's cooldown on every venue in a batch they were just sent
+func (s *NotificationService) startVenueCooldowns(user User, slots []SlotData) {
+	if user.VenueCooldown <= 0 || s.venueCooldowns == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	started := make(map[string]bool)
+	for _, slot := range slots {
+		venue := venueKey(slot)
+		if started[venue] {
+			continue
+		}
+		started[venue] = true
+
+		if err := s.venueCooldowns.Start(ctx, venueCooldownKey(user, venue), user.VenueCooldown); err != nil {
+			s.logger.Warn("Failed to start venue cooldown", map[string]interface{}{"user_email": user.Email, "venue": venue, "error": err.Error()})
+		}
+	}
+}
+
+// cooldownBatch is the slots held for one user and venue while the cooldown runs
+type cooldownBatch struct {
+	user  User
+	slots []SlotData
+	timer *time.Timer
+}
+
+// holdForVenueCooldown keeps a slot back until the user's cooldown on its venue ends,
+// then moves every slot held for that venue into the user's next batch
+func (s *NotificationService) holdForVenueCooldown(user User, slot SlotData, wait time.Duration) {
+	s.batchMutex.Lock()
+	defer s.batchMutex.Unlock()
+
+	if s.cooldownHeld == nil {
+		s.cooldownHeld = make(map[string]*cooldownBatch)
+	}
+
+	key := user.Email + "|" + venueKey(slot)
+	held, pending := s.cooldownHeld[key]
+	if !pending {
+		s.logger.Info("Venue is cooling down, holding alerts", map[string]interface{}{"user_email": user.Email, "venue": slot.VenueName, "slot_key": slotKey(slot), "wait": wait.Round(time.Second).String()})
+		held = &cooldownBatch{user: user}
+		held.timer = time.AfterFunc(wait, func() {
+			s.releaseVenueCooldown(key)
+		})
+		s.cooldownHeld[key] = held
+	}
+	held.slots = append(held.slots, slot)
+}
+
+// releaseVenueCooldown moves the slots held for one user and venue into the user's batch
+func (s *NotificationService) releaseVenueCooldown(key string) {
+	s.batchMutex.Lock()
+	held, ok := s.cooldownHeld[key]
+	delete(s.cooldownHeld, key)
+	s.batchMutex.Unlock()
+
+	if !ok {
+		return
+	}
+	for _, slot := range held.slots {
+		s.addSlotToBatch(held.user, slot)
+	}
+}
+
+// releaseAllVenueCooldowns moves every held slot into its user's batch without
+// waiting for the cooldowns to end
+func (s *NotificationService) releaseAllVenueCooldowns() {
+	s.batchMutex.Lock()
+	held := s.cooldownHeld
+	s.cooldownHeld = nil
+	s.batchMutex.Unlock()
+
+	for _, batch := range held {
+		batch.timer.Stop()
+		for _, slot := range batch.slots {
+			s.addSlotToBatch(batch.user, slot)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryCooldownStore is an in-memory cooldownStore for tests
+type memoryCooldownStore struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+func newMemoryCooldownStore() *memoryCooldownStore {
+	return &memoryCooldownStore{expires: make(map[string]time.Time)}
+}
+
+func (m *memoryCooldownStore) Remaining(ctx context.Context, key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if remaining := time.Until(m.expires[key]); remaining > 0 {
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+func (m *memoryCooldownStore) Start(ctx context.Context, key string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.expires[key] = time.Now().Add(ttl)
+	return nil
+}
+
+func TestVenueCooldown_StartsAfterSendForEachVenue(t *testing.T) {
+	user := User{ID: primitive.NewObjectID(), Email: "player@example.com", EmailEnabled: true, VenueCooldown: time.Hour}
+
+	s := newTestNotificationService()
+	s.users = []User{user}
+	s.venueCooldowns = newMemoryCooldownStore()
+	s.registerChannel(ChannelEmail, newRecordingChannel())
+
+	victoria := SlotData{VenueID: "victoria", VenueName: "Victoria Park", StartTime: "18:00"}
+	highbury := SlotData{VenueID: "highbury", VenueName: "Highbury Fields", StartTime: "18:00"}
+
+	assert.Zero(t, s.venueCooldownRemaining(user, victoria))
+
+	s.sendUserBatch(user.Email, []SlotData{victoria, {VenueID: "victoria", StartTime: "19:00"}})
+
+	remaining := s.venueCooldownRemaining(user, victoria)
+	assert.Greater(t, remaining, 59*time.Minute)
+	assert.LessOrEqual(t, remaining, time.Hour)
+	assert.Zero(t, s.venueCooldownRemaining(user, highbury))
+
+	// Cooldowns are per user
+	other := User{ID: primitive.NewObjectID(), Email: "other@example.com", VenueCooldown: time.Hour}
+	assert.Zero(t, s.venueCooldownRemaining(other, victoria))
+}
+
+func TestVenueCooldown_ZeroDisablesCooldown(t *testing.T) {
+	user := User{ID: primitive.NewObjectID(), Email: "player@example.com", EmailEnabled: true}
+	slot := SlotData{VenueID: "victoria", VenueName: "Victoria Park"}
+
+	store := newMemoryCooldownStore()
+	s := newTestNotificationService()
+	s.users = []User{user}
+	s.venueCooldowns = store
+	s.registerChannel(ChannelEmail, newRecordingChannel())
+
+	s.sendUserBatch(user.Email, []SlotData{slot})
+	assert.Empty(t, store.expires)
+
+	// Even a cooldown left over from an earlier setting is ignored
+	require.NoError(t, store.Start(context.Background(), venueCooldownKey(user, "victoria"), time.Hour))
+	assert.Zero(t, s.venueCooldownRemaining(user, slot))
+}
+
+func TestVenueCooldown_HeldSlotsJoinNextBatch(t *testing.T) {
+	user := User{ID: primitive.NewObjectID(), Email: "player@example.com", EmailEnabled: true, VenueCooldown: time.Hour}
+
+	channel := newRecordingChannel()
+	s := newTestNotificationService()
+	s.BatchWindow = 10 * time.Millisecond
+	s.users = []User{user}
+	s.registerChannel(ChannelEmail, channel)
+
+	s.holdForVenueCooldown(user, SlotData{VenueID: "victoria", VenueName: "Victoria Park", StartTime: "18:00"}, 50*time.Millisecond)
+	s.holdForVenueCooldown(user, SlotData{VenueID: "victoria", VenueName: "Victoria Park", StartTime: "19:00"}, 40*time.Millisecond)
+
+	// Nothing goes out while the cooldown runs
+	time.Sleep(30 * time.Millisecond)
+	assert.Empty(t, channel.sendTimes(user.Email))
+
+	// Both held slots arrive together in one send once it ends
+	require.Eventually(t, func() bool {
+		return len(channel.sendTimes(user.Email)) == 1
+	}, time.Second, 5*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	assert.Len(t, channel.sendTimes(user.Email), 1)
+	assert.Empty(t, s.cooldownHeld)
+}
+
+func TestShutdown_SendsSlotsHeldForCooldown(t *testing.T) {
+	user := User{ID: primitive.NewObjectID(), Email: "player@example.com", EmailEnabled: true, VenueCooldown: time.Hour}
+
+	channel := newRecordingChannel()
+	s := newTestNotificationService()
+	s.BatchWindow = time.Hour
+	s.users = []User{user}
+	s.registerChannel(ChannelEmail, channel)
+
+	s.holdForVenueCooldown(user, SlotData{VenueID: "victoria", VenueName: "Victoria Park"}, time.Hour)
+
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.Len(t, channel.sendTimes(user.Email), 1)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultCurrency is assumed for slots published without a currency code
+const defaultCurrency = "GBP"
+
+// currencySymbols maps ISO 4217 codes to the symbol shown before the amount
+var currencySymbols = map[string]string{
+	"GBP": "£",
+	"USD": "$",
+	"EUR": "€",
+	"AUD": "A$",
+	"CAD": "C$",
+	"CHF": "CHF ",
+}
+
+// slotCurrency returns the slot's currency code, defaulting to GBP
+func slotCurrency(slot SlotData) string {
+	if slot.Currency == "" {
+		return defaultCurrency
+	}
+	return strings.ToUpper(slot.Currency)
+}
+
+// formatPrice renders a price with its currency symbol, e.g. "£12.50" or "$20.00".
+// Unknown currencies fall back to the code, e.g. "12.50 SEK".
+func formatPrice(price float64, currency string) string {
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	currency = strings.ToUpper(currency)
+
+	if symbol, ok := currencySymbols[currency]; ok {
+		return fmt.Sprintf("%s%.2f", symbol, price)
+	}
+	return fmt.Sprintf("%.2f %s", price, currency)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatPrice(t *testing.T) {
+	tests := []struct {
+		price    float64
+		currency string
+		want     string
+	}{
+		{12.5, "GBP", "£12.50"},
+		{20, "USD", "$20.00"},
+		{15.75, "EUR", "€15.75"},
+		{15.75, "eur", "€15.75"},
+		{9, "", "£9.00"},
+		{150, "SEK", "150.00 SEK"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, formatPrice(tt.price, tt.currency))
+	}
+}
+
+func TestSlotCurrency(t *testing.T) {
+	assert.Equal(t, "GBP", slotCurrency(SlotData{}))
+	assert.Equal(t, "USD", slotCurrency(SlotData{Currency: "usd"}))
+}
+
+func TestBatchedDetails_UseSlotCurrency(t *testing.T) {
+	slots := []SlotData{
+		{VenueName: "Central Park", CourtName: "Court 3", Date: "2024-06-15", StartTime: "09:00", EndTime: "10:00", Price: 30, Currency: "USD"},
+	}
+
+	assert.Contains(t, formatBatchedEmailDetails(slots, english), "($30.00)")
+	assert.Contains(t, formatBatchedSMSDetails(slots), "$30.00")
+	assert.NotContains(t, formatBatchedEmailDetails(slots, english), "£")
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Why a slot was flagged as a deal for a user
+const (
+	DealTargetPrice  = "target_price"  // Priced at or below the user's target price
+	DealBelowAverage = "below_average" // Priced well below its own recent average
+)
+
+const (
+	// dealPriceWindow is how far back a slot's recent average price looks
+	dealPriceWindow = 30 * 24 * time.Hour
+	// belowAverageDealRatio is the fraction of its recent average a slot must
+	// be priced at or under to count as a deal, i.e. at least 20% cheaper
+	belowAverageDealRatio = 0.8
+	// minDealPricePoints is how many recorded prices an average needs before
+	// it's trusted; with fewer, one odd price would make everything a deal
+	minDealPricePoints = 3
+)
+
+// priceAverageStore averages a slot's recorded prices; satisfied by database.PriceHistoryRepository
+type priceAverageStore interface {
+	AveragePrice(ctx context.Context, venueID primitive.ObjectID, courtID, startTime string, since time.Time) (float64, int, error)
+}
+
+// wantsDeals reports whether the user opted in to deal alerts by setting a target price
+func wantsDeals(user User) bool {
+	return user.TargetPrice > 0
+}
+
+// anyWantDeals reports whether any of the users opted in to deal alerts
+func anyWantDeals(users []User) bool {
+	for _, user := range users {
+		if wantsDeals(user) {
+			return true
+		}
+	}
+	return false
+}
+
+// recentAveragePrice returns the average price recorded for the slot's court
+// and start time over the last dealPriceWindow, or 0 when there isn't enough
+// history to say what's normal for it.
+func (s *NotificationService) recentAveragePrice(slot SlotData, now time.Time) float64 {
+	if s.priceHistory == nil {
+		return 0
+	}
+	venueID, err := primitive.ObjectIDFromHex(slot.VenueID)
+	if err != nil {
+		return 0
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	average, points, err := s.priceHistory.AveragePrice(ctx, venueID, slot.CourtID, slot.StartTime, now.Add(-dealPriceWindow))
+	if err != nil {
+		s.logger.Warn("Failed to load slot price history, skipping below-average deals", map[string]interface{}{"slot_key": slotKey(slot), "error": err.Error()})
+		return 0
+	}
+	if points < minDealPricePoints {
+		return 0
+	}
+	return average
+}
+
+// dealFor returns why the slot is a deal for the user, or "" if it isn't.
+// Only users with a target price get deal alerts; for them a slot is a deal
+// when it's at or below their target, or well below its recent average price.
+// Slots without a price are never deals, since the price is unknown.
+func dealFor(user User, slot SlotData, averagePrice float64) string {
+	if !wantsDeals(user) || slot.Price <= 0 {
+		return ""
+	}
+	if slot.Price <= user.TargetPrice {
+		return DealTargetPrice
+	}
+	if averagePrice > 0 && slot.Price <= averagePrice*belowAverageDealRatio {
+		return DealBelowAverage
+	}
+	return ""
+}
+
+// markDeal returns the user's copy of the slot, flagged if it's a deal for them
+func markDeal(user User, slot SlotData, averagePrice float64) SlotData {
+	slot.Deal = dealFor(user, slot, averagePrice)
+	if slot.Deal == DealBelowAverage {
+		slot.AveragePrice = averagePrice
+	}
+	return slot
+}
+
+// isDeal reports whether the slot was flagged as a deal
+func isDeal(slot SlotData) bool {
+	return slot.Deal != ""
+}
+
+// isBelowAverageDeal reports whether the slot is a deal because it's well below its recent average
+func isBelowAverageDeal(slot SlotData) bool {
+	return slot.Deal == DealBelowAverage
+}
+
+// countDeals returns how many of the slots were flagged as deals
+func countDeals(slots []SlotData) int {
+	count := 0
+	for _, slot := range slots {
+		if isDeal(slot) {
+			count++
+		}
+	}
+	return count
+}
+
+// dealNote describes why a slot is a deal in plain-text alerts
+func dealNote(slot SlotData, locale *alertLocale) string {
+	switch slot.Deal {
+	case DealTargetPrice:
+		return locale.DealTargetNote
+	case DealBelowAverage:
+		return fmt.Sprintf(locale.DealUsualNote, locale.formatPrice(slot.AveragePrice, slotCurrency(slot)))
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakePriceAverageStore returns a fixed average and records the window it was asked for
+type fakePriceAverageStore struct {
+	average float64
+	points  int
+	err     error
+	since   time.Time
+}
+
+func (f *fakePriceAverageStore) AveragePrice(ctx context.Context, venueID primitive.ObjectID, courtID, startTime string, since time.Time) (float64, int, error) {
+	f.since = since
+	return f.average, f.points, f.err
+}
+
+func TestDealFor(t *testing.T) {
+	dealSeeker := User{TargetPrice: 10}
+
+	tests := []struct {
+		name    string
+		user    User
+		price   float64
+		average float64
+		want    string
+	}{
+		{"at the target price", dealSeeker, 10, 0, DealTargetPrice},
+		{"below the target price", dealSeeker, 8, 20, DealTargetPrice},
+		{"well below the recent average", dealSeeker, 12, 16, DealBelowAverage},
+		{"slightly below the recent average", dealSeeker, 14, 16, ""},
+		{"above the target without history", dealSeeker, 12, 0, ""},
+		{"unknown price", dealSeeker, 0, 16, ""},
+		{"no target price set", User{}, 5, 16, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, dealFor(tt.user, SlotData{Price: tt.price}, tt.average))
+		})
+	}
+}
+
+func TestMarkDeal(t *testing.T) {
+	slot := SlotData{Price: 12}
+
+	belowAverage := markDeal(User{TargetPrice: 10}, slot, 16)
+	assert.Equal(t, DealBelowAverage, belowAverage.Deal)
+	assert.Equal(t, 16.0, belowAverage.AveragePrice)
+
+	notDeal := markDeal(User{}, slot, 16)
+	assert.Empty(t, notDeal.Deal)
+	assert.Zero(t, notDeal.AveragePrice, "users without a target price see the slot unchanged")
+	assert.Empty(t, slot.Deal, "other users' copies of the slot are left alone")
+}
+
+func TestRecentAveragePrice(t *testing.T) {
+	s := newTestNotificationService()
+	now := time.Now()
+	slot := SlotData{VenueID: primitive.NewObjectID().Hex(), CourtID: "c1", StartTime: "18:00"}
+
+	assert.Zero(t, s.recentAveragePrice(slot, now), "no price history store")
+
+	store := &fakePriceAverageStore{average: 15, points: minDealPricePoints}
+	s.priceHistory = store
+	assert.Equal(t, 15.0, s.recentAveragePrice(slot, now))
+	assert.Equal(t, now.Add(-dealPriceWindow), store.since)
+
+	store.points = minDealPricePoints - 1
+	assert.Zero(t, s.recentAveragePrice(slot, now), "too little history to trust")
+
+	store.points, store.err = minDealPricePoints, errors.New("mongo down")
+	assert.Zero(t, s.recentAveragePrice(slot, now))
+
+	store.err = nil
+	assert.Zero(t, s.recentAveragePrice(SlotData{VenueID: "Victoria Park"}, now), "venue ID is not an ObjectID")
+}
+
+func TestDealAlertContent(t *testing.T) {
+	deal := SlotData{
+		VenueName: "Victoria Park", CourtName: "Court 1", Date: "2024-06-15",
+		StartTime: "18:00", EndTime: "19:00", Price: 8, Deal: DealTargetPrice,
+	}
+	belowAverage := SlotData{
+		VenueName: "Victoria Park", CourtName: "Court 2", Date: "2024-06-15",
+		StartTime: "18:00", EndTime: "19:00", Price: 12, Deal: DealBelowAverage, AveragePrice: 16,
+	}
+	regular := SlotData{VenueName: "Victoria Park", CourtName: "Court 3", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 20}
+
+	assert.Equal(t, "💰 Court deal available!", slotAlertSubject([]SlotData{deal}, english))
+	assert.Equal(t, "💰 Court deal available!", slotAlertSubject([]SlotData{regular, deal}, english))
+	assert.Equal(t, "💰 Court deals available!", slotAlertSubject([]SlotData{deal, belowAverage}, english))
+	assert.Equal(t, "🎾 New court available!", slotAlertSubject([]SlotData{regular}, english))
+
+	assert.Equal(t, "A tennis court deal just became available!", alertHeadline([]SlotData{deal}, english))
+	assert.Equal(t, "3 tennis courts just became available, 2 at deal prices!", alertHeadline([]SlotData{deal, belowAverage, regular}, english))
+
+	html, err := renderCourtAlertHTML([]SlotData{belowAverage, regular}, "", english)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(html, "Deal</span>"))
+	assert.Contains(t, html, "£12.00</strong>")
+	assert.Contains(t, html, "£16.00</span>")
+
+	text := formatBatchedEmailDetails([]SlotData{deal, belowAverage, regular}, english)
+	assert.Contains(t, text, "Court 1: 18:00-19:00 (£8.00) - 💰 deal: at or below your target price")
+	assert.Contains(t, text, "Court 2: 18:00-19:00 (£12.00) - 💰 deal: usually £16.00")
+	assert.Contains(t, text, "Court 3: 18:00-19:00 (£20.00)\n")
+
+	sms := formatBatchedSMSDetails([]SlotData{deal})
+	assert.True(t, strings.HasPrefix(sms, "Tennis court deal:"))
+	assert.Contains(t, sms, "DEAL")
+}
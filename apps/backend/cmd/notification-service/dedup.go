@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"tennis-booker/internal/logging"
+	"tennis-booker/internal/models"
+)
+
+// newDeduplicationService creates the deduplication service with the deployment's
+// record retention and cleanup interval from DEDUP_WINDOW_HOURS and
+// DEDUP_CLEANUP_INTERVAL, and its windows from NOTIFICATION_DEDUP_EXACT_WINDOW and
+// NOTIFICATION_DEDUP_SIMILAR_WINDOW
+func newDeduplicationService(db *mongo.Database, logger *logging.Logger) *models.DeduplicationService {
+	svc := models.NewDeduplicationService(models.NewMongoDedupStore(db), dedupConfigFromEnv(logger))
+	svc.SetWindows(dedupWindowsFromEnv(logger))
+	return svc
+}
+
+// dedupConfigFromEnv reads DEDUP_WINDOW_HOURS, how many hours records of sent
+// notifications are kept (default 48), and DEDUP_CLEANUP_INTERVAL, how often
+// expired records are deleted (default 1h). The retention also caps the
+// exact-match window.
+func dedupConfigFromEnv(logger *logging.Logger) models.DeduplicationConfig {
+	defaults := models.DefaultDeduplicationConfig()
+	config := models.DeduplicationConfig{
+		Retention:       defaults.Retention,
+		CleanupInterval: durationFromEnv(logger, "DEDUP_CLEANUP_INTERVAL", defaults.CleanupInterval),
+	}
+
+	if value := os.Getenv("DEDUP_WINDOW_HOURS"); value != "" {
+		hours, err := strconv.Atoi(value)
+		if err != nil || hours <= 0 {
+			logger.Warn("Invalid DEDUP_WINDOW_HOURS, using the default", map[string]interface{}{"value": value, "default": defaults.Retention.String()})
+		} else {
+			config.Retention = time.Duration(hours) * time.Hour
+		}
+	}
+
+	return config
+}
+
+// startDedupCleanup starts a goroutine that deletes expired deduplication records
+// every cleanup interval, so they don't depend on the TTL index existing
+func (s *NotificationService) startDedupCleanup() {
+	interval := s.deduplicationSvc.Config().CleanupInterval
+	ticker := time.NewTicker(interval)
+	s.logger.Info("Starting deduplication record cleanup", map[string]interface{}{"interval": interval.String()})
+
+	go func() {
+		for range ticker.C {
+			s.cleanupExpiredDedupRecords()
+		}
+	}()
+}
+
+// cleanupExpiredDedupRecords deletes expired deduplication records once
+func (s *NotificationService) cleanupExpiredDedupRecords() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	deleted, err := s.deduplicationSvc.CleanupExpiredRecords(ctx)
+	if err != nil {
+		s.logger.Error("Failed to clean up expired deduplication records", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	if deleted > 0 {
+		s.logger.Info("Cleaned up expired deduplication records", map[string]interface{}{"deleted": deleted})
+	}
+}
+
+// dedupWindowsFromEnv reads NOTIFICATION_DEDUP_EXACT_WINDOW (default 24h) and
+// NOTIFICATION_DEDUP_SIMILAR_WINDOW (default 1h), e.g. "12h" or "30m". Users can
+// override either in their notification settings.
+func dedupWindowsFromEnv(logger *logging.Logger) models.DeduplicationWindows {
+	defaults := models.DefaultDeduplicationWindows()
+	return models.DeduplicationWindows{
+		ExactMatch: durationFromEnv(logger, "NOTIFICATION_DEDUP_EXACT_WINDOW", defaults.ExactMatch),
+		Similar:    durationFromEnv(logger, "NOTIFICATION_DEDUP_SIMILAR_WINDOW", defaults.Similar),
+	}
+}
+
+// durationFromEnv reads a positive duration from the environment, warning and
+// falling back to def when it is invalid
+func durationFromEnv(logger *logging.Logger, name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		logger.Warn("Invalid "+name+", using the default", map[string]interface{}{"value": value, "default": def.String()})
+		return def
+	}
+
+	return d
+}
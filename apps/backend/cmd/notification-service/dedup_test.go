@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tennis-booker/internal/models"
+)
+
+func TestDedupWindowsFromEnv(t *testing.T) {
+	logger := discardLogger()
+
+	t.Setenv("NOTIFICATION_DEDUP_EXACT_WINDOW", "")
+	t.Setenv("NOTIFICATION_DEDUP_SIMILAR_WINDOW", "")
+	assert.Equal(t, models.DefaultDeduplicationWindows(), dedupWindowsFromEnv(logger))
+
+	t.Setenv("NOTIFICATION_DEDUP_EXACT_WINDOW", "12h")
+	t.Setenv("NOTIFICATION_DEDUP_SIMILAR_WINDOW", "15m")
+	assert.Equal(t, models.DeduplicationWindows{ExactMatch: 12 * time.Hour, Similar: 15 * time.Minute}, dedupWindowsFromEnv(logger))
+
+	t.Setenv("NOTIFICATION_DEDUP_EXACT_WINDOW", "0s")
+	t.Setenv("NOTIFICATION_DEDUP_SIMILAR_WINDOW", "soon")
+	assert.Equal(t, models.DefaultDeduplicationWindows(), dedupWindowsFromEnv(logger))
+}
+
+func TestDedupConfigFromEnv(t *testing.T) {
+	logger := discardLogger()
+
+	t.Setenv("DEDUP_WINDOW_HOURS", "")
+	t.Setenv("DEDUP_CLEANUP_INTERVAL", "")
+	assert.Equal(t, models.DefaultDeduplicationConfig(), dedupConfigFromEnv(logger))
+
+	t.Setenv("DEDUP_WINDOW_HOURS", "72")
+	t.Setenv("DEDUP_CLEANUP_INTERVAL", "15m")
+	assert.Equal(t, models.DeduplicationConfig{Retention: 72 * time.Hour, CleanupInterval: 15 * time.Minute}, dedupConfigFromEnv(logger))
+
+	t.Setenv("DEDUP_WINDOW_HOURS", "0")
+	t.Setenv("DEDUP_CLEANUP_INTERVAL", "-1h")
+	assert.Equal(t, models.DefaultDeduplicationConfig(), dedupConfigFromEnv(logger))
+
+	t.Setenv("DEDUP_WINDOW_HOURS", "1.5")
+	assert.Equal(t, models.DefaultDeduplicationRetention, dedupConfigFromEnv(logger).Retention)
+}
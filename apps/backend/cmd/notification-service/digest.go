@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/models"
+)
+
+// isDigestUser reports whether the user wants a daily digest instead of instant alerts
+func isDigestUser(user User) bool {
+	return user.DeliveryMode == models.DeliveryModeDigest
+}
+
+// isDigestDue reports whether now falls in the user's digest hour, moved out of quiet hours if necessary
+func isDigestDue(user User, now time.Time) bool {
+	return now.In(userLocation(user)).Hour() == digestHour(user, now)
+}
+
+// queueForDigest stores a matched slot in pending_digests for the user's next digest
+func (s *NotificationService) queueForDigest(user User, event models.CourtAvailabilityEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := s.digestSvc.AddToDigest(ctx, user.ID, event); err != nil {
+		return err
+	}
+
+	s.logger.Info("Queued slot for daily digest", map[string]interface{}{"user_email": user.Email, "venue": event.VenueName, "slot_key": event.GenerateSlotKey()})
+	return nil
+}
+
+// startDigestScheduler runs the digest routine at the top of every hour so each
+// user's digest goes out at their configured local hour
+func (s *NotificationService) startDigestScheduler() *cron.Cron {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.digestSvc.CreateIndexes(ctx); err != nil {
+		s.logger.Warn("Failed to create pending_digests indexes", map[string]interface{}{"error": err.Error()})
+	}
+
+	c := cron.New()
+
+	_, err := c.AddFunc("0 * * * *", func() {
+		s.sendDueDigests(time.Now())
+	})
+	if err != nil {
+		s.logger.Error("Failed to schedule digest job", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+
+	c.Start()
+	s.logger.Info("Digest scheduler started (checks hourly)")
+	return c
+}
+
+// sendDueDigests sends the consolidated digest to every digest user whose send hour is now
+func (s *NotificationService) sendDueDigests(now time.Time) {
+	s.usersMutex.RLock()
+	users := s.users
+	s.usersMutex.RUnlock()
+
+	endBatch := s.beginEmailBatch()
+	for _, user := range users {
+		if !isDigestUser(user) || !isDigestDue(user, now) {
+			continue
+		}
+
+		if err := s.sendDigest(user); err != nil {
+			s.logger.Error("Failed to send digest", map[string]interface{}{"user_email": user.Email, "error": err.Error()})
+		}
+	}
+	endBatch()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if removed, err := s.digestSvc.ClearExpiredEntries(ctx); err != nil {
+		s.logger.Warn("Failed to clear expired digest entries", map[string]interface{}{"error": err.Error()})
+	} else if removed > 0 {
+		s.logger.Info("Cleared expired digest entries", map[string]interface{}{"removed": removed})
+	}
+}
+
+// sendDigest assembles the user's pending entries into one notification and clears them once sent
+func (s *NotificationService) sendDigest(user User) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	entries, err := s.digestSvc.GetPendingDigest(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	today := time.Now().In(userLocation(user)).Format("2006-01-02")
+
+	slots := make([]SlotData, 0, len(entries))
+	ids := make([]primitive.ObjectID, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+
+		// Don't include slots that have already been played
+		if entry.SlotDate < today {
+			continue
+		}
+
+		slots = append(slots, SlotData{
+			VenueID:     entry.VenueID,
+			VenueName:   entry.VenueName,
+			CourtID:     entry.CourtID,
+			CourtName:   entry.CourtName,
+			Date:        entry.SlotDate,
+			StartTime:   entry.SlotStartTime,
+			EndTime:     entry.SlotEndTime,
+			Price:       entry.Price,
+			Currency:    entry.Currency,
+			IsAvailable: true,
+			BookingURL:  entry.BookingURL,
+			ScrapedAt:   entry.DiscoveredAt,
+		})
+	}
+
+	if len(slots) > 0 {
+		if err := s.sendBatchedNotification(user, slots); err != nil {
+			return err
+		}
+		s.logger.Info("Sent daily digest", map[string]interface{}{"user_email": user.Email, "slots": len(slots)})
+	}
+
+	_, err = s.digestSvc.ClearDigestEntries(ctx, ids)
+	return err
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tennis-booker/internal/models"
+)
+
+func TestIsDigestUser(t *testing.T) {
+	assert.True(t, isDigestUser(User{DeliveryMode: models.DeliveryModeDigest}))
+	assert.False(t, isDigestUser(User{DeliveryMode: models.DeliveryModeInstant}))
+	assert.False(t, isDigestUser(User{}))
+}
+
+func TestUserLocation(t *testing.T) {
+	assert.Equal(t, "America/New_York", userLocation(User{Timezone: "America/New_York"}).String())
+	assert.Equal(t, defaultTimezone, userLocation(User{}).String())
+	assert.Equal(t, defaultTimezone, userLocation(User{Timezone: "Not/AZone"}).String())
+}
+
+func TestIsDigestDue(t *testing.T) {
+	// 12:00 UTC in June is 13:00 in London (BST) and 08:00 in New York (EDT)
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	london := User{DigestSendHour: 13}
+	newYork := User{DigestSendHour: 8, Timezone: "America/New_York"}
+	newYorkLater := User{DigestSendHour: 9, Timezone: "America/New_York"}
+
+	assert.True(t, isDigestDue(london, now))
+	assert.True(t, isDigestDue(newYork, now))
+	assert.False(t, isDigestDue(newYorkLater, now))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis queues for incoming slots and the messages that could not be decoded
+const (
+	slotQueue           = "court_slots"
+	slotDeadLetterQueue = "court_slots_dlq"
+)
+
+// replayDLQFlag is the CLI argument that reprocesses the dead-letter queue and exits
+const replayDLQFlag = "--replay-dlq"
+
+// DeadLetterEntry wraps a slot message that failed to parse or has a schema
+// version this build doesn't know; replaying after an upgrade recovers the latter
+type DeadLetterEntry struct {
+	Payload  string    `json:"payload"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failedAt"`
+}
+
+// isReplayDLQMode reports whether the service was started with --replay-dlq
+func isReplayDLQMode() bool {
+	return len(os.Args) > 1 && os.Args[1] == replayDLQFlag
+}
+
+// sendToDeadLetterQueue records an undecodable slot message so it isn't lost
+func (s *NotificationService) sendToDeadLetterQueue(payload string, parseErr error) {
+	s.parseFailures.Add(1)
+
+	if s.redisClient == nil {
+		return
+	}
+
+	entry, err := json.Marshal(DeadLetterEntry{
+		Payload:  payload,
+		Error:    parseErr.Error(),
+		FailedAt: time.Now(),
+	})
+	if err != nil {
+		s.logger.Error("Failed to marshal dead-letter entry", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.redisClient.LPush(ctx, slotDeadLetterQueue, entry).Err(); err != nil {
+		s.logger.Error("Failed to push message to the dead-letter queue", map[string]interface{}{"queue": slotDeadLetterQueue, "error": err.Error()})
+		return
+	}
+
+	s.logger.Warn("Moved malformed slot message to the dead-letter queue", map[string]interface{}{"queue": slotDeadLetterQueue})
+}
+
+// replayDeadLetterQueue reprocesses every entry currently in the DLQ.
+// Entries that still fail to parse are pushed back onto the DLQ by processSlotMessage.
+func (s *NotificationService) replayDeadLetterQueue(ctx context.Context) (int, error) {
+	// Only replay what is there now so entries that fail again aren't picked up twice
+	pending, err := s.redisClient.LLen(ctx, slotDeadLetterQueue).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for i := int64(0); i < pending; i++ {
+		raw, err := s.redisClient.RPop(ctx, slotDeadLetterQueue).Result()
+		if errors.Is(err, redis.Nil) {
+			break
+		}
+		if err != nil {
+			return replayed, err
+		}
+
+		var entry DeadLetterEntry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			// Not wrapped by us - treat the whole value as the original payload
+			entry.Payload = raw
+		}
+
+		s.processSlotMessage(entry.Payload)
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+// runReplayDLQ replays the dead-letter queue, sends any resulting notifications and returns
+func (s *NotificationService) runReplayDLQ() {
+	s.logger.Info("Replaying dead-letter queue", map[string]interface{}{"queue": slotDeadLetterQueue})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	replayed, err := s.replayDeadLetterQueue(ctx)
+	if err != nil {
+		s.logger.Error("Dead-letter replay stopped", map[string]interface{}{"replayed": replayed, "error": err.Error()})
+	}
+
+	// Send whatever matched immediately rather than waiting for the batch timer
+	s.flushBatchedNotifications()
+
+	s.logger.Info("Dead-letter replay finished", map[string]interface{}{"replayed": replayed, "still_failing": s.parseFailures.Load()})
+}
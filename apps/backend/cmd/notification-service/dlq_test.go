@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessSlotMessage_CountsParseFailures(t *testing.T) {
+	s := newTestNotificationService()
+
+	s.processSlotMessage(`{"venueId": "abc", "price": "not-a-number"`)
+	s.processSlotMessage(`not json at all`)
+
+	assert.Equal(t, int64(2), s.parseFailures.Load())
+}
+
+func TestProcessSlotMessage_RejectsUnknownSchemaVersion(t *testing.T) {
+	s := newTestNotificationService()
+
+	s.processSlotMessage(`{"schema_version": 99, "venue_id": "abc", "date": "2024-06-15", "start_time": "18:00"}`)
+
+	assert.Equal(t, int64(1), s.parseFailures.Load())
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"time"
+)
+
+// courtAlertHTMLLayout renders batched slots grouped by venue and date, with
+// text from .Text and dates and prices formatted by the template's locale.
+// Styles are inlined because Gmail and Outlook strip <style> blocks.
+const courtAlertHTMLLayout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="UTF-8">
+<meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>{{.Title}}</title>
+</head>
+<body style="margin:0;padding:0;background-color:#f4f6f8;font-family:Arial,Helvetica,sans-serif;color:#1f2933;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="background-color:#f4f6f8;">
+<tr><td align="center" style="padding:24px 12px;">
+<table role="presentation" width="600" cellpadding="0" cellspacing="0" style="max-width:600px;width:100%;background-color:#ffffff;border-radius:8px;">
+<tr><td style="padding:24px;">
+<h1 style="margin:0 0 8px 0;font-size:22px;color:#166534;">{{.Title}}</h1>
+<p style="margin:0 0 16px 0;font-size:14px;color:#52606d;">{{.Text.Intro}}</p>
+{{range .Venues}}
+<h2 style="margin:24px 0 8px 0;font-size:18px;color:#1f2933;">{{.Name}}</h2>
+{{range .Dates}}
+<h3 style="margin:16px 0 8px 0;font-size:15px;color:#52606d;">{{date .Date}}</h3>
+<table role="presentation" width="100%" cellpadding="8" cellspacing="0" style="border-collapse:collapse;font-size:14px;">
+<tr style="background-color:#f0fdf4;">
+<th align="left" style="border-bottom:1px solid #d9e2ec;">{{$.Text.Court}}</th>
+<th align="left" style="border-bottom:1px solid #d9e2ec;">{{$.Text.Time}}</th>
+<th align="left" style="border-bottom:1px solid #d9e2ec;">{{$.Text.Price}}</th>
+<th align="right" style="border-bottom:1px solid #d9e2ec;"></th>
+</tr>
+{{range .Slots}}
+<tr>
+<td style="border-bottom:1px solid #e4e7eb;">{{.CourtName}}{{if cancellation .}} <span style="display:inline-block;padding:2px 6px;background-color:#fef3c7;color:#92400e;border-radius:4px;font-size:12px;">{{$.Text.FreedUpBadge}}</span>{{end}}{{if deal .}} <span style="display:inline-block;padding:2px 6px;background-color:#dc2626;color:#ffffff;border-radius:4px;font-size:12px;font-weight:bold;">{{$.Text.DealBadge}}</span>{{end}}</td>
+<td style="border-bottom:1px solid #e4e7eb;">{{times .}}</td>
+<td style="border-bottom:1px solid #e4e7eb;">{{if deal .}}<strong style="color:#dc2626;">{{price .Price .Currency}}</strong>{{if belowAverage .}} <span style="font-size:12px;color:#52606d;text-decoration:line-through;">{{price .AveragePrice .Currency}}</span>{{end}}{{else}}{{price .Price .Currency}}{{end}}</td>
+<td align="right" style="border-bottom:1px solid #e4e7eb;">{{if .BookingURL}}<a href="{{.BookingURL}}" style="display:inline-block;padding:6px 14px;background-color:#16a34a;color:#ffffff;text-decoration:none;border-radius:4px;font-weight:bold;">{{$.Text.BookNow}}</a>{{end}}</td>
+</tr>
+{{end}}
+</table>
+{{end}}
+{{end}}
+<p style="margin:24px 0 0 0;font-size:12px;color:#9aa5b1;">{{.Text.Footer}}</p>
+{{if .UnsubscribeURL}}<p style="margin:8px 0 0 0;font-size:12px;color:#9aa5b1;"><a href="{{.UnsubscribeURL}}" style="color:#9aa5b1;">{{.Text.Unsubscribe}}</a></p>{{end}}
+</td></tr>
+</table>
+</td></tr>
+</table>
+</body>
+</html>
+`
+
+// courtAlertHTMLTemplates holds courtAlertHTMLLayout parsed for each alert locale, keyed by language
+var courtAlertHTMLTemplates = newCourtAlertHTMLTemplates()
+
+// newCourtAlertHTMLTemplates parses the alert layout once per locale, binding
+// the locale's date and price formatting into the template
+func newCourtAlertHTMLTemplates() map[string]*template.Template {
+	templates := make(map[string]*template.Template, len(alertLocales))
+	for language, locale := range alertLocales {
+		templates[language] = template.Must(template.New("court_alert_" + language).Funcs(template.FuncMap{
+			"price":        locale.formatPrice,
+			"date":         locale.formatDate,
+			"times":        slotTimeRange,
+			"cancellation": isCancellation,
+			"deal":         isDeal,
+			"belowAverage": isBelowAverageDeal,
+		}).Parse(courtAlertHTMLLayout))
+	}
+	return templates
+}
+
+// courtAlertHTMLData is the view model for courtAlertHTMLLayout
+type courtAlertHTMLData struct {
+	Title          string
+	Text           *alertLocale
+	Venues         []venueSlotGroup
+	UnsubscribeURL string
+}
+
+// venueSlotGroup holds the slots for one venue, grouped by date
+type venueSlotGroup struct {
+	Name  string
+	Dates []dateSlotGroup
+}
+
+// dateSlotGroup holds the slots for one venue on one date
+type dateSlotGroup struct {
+	Date  string
+	Slots []SlotData
+}
+
+// groupSlotsByVenueAndDate groups slots by venue then date, keeping the order in which they first appear
+func groupSlotsByVenueAndDate(slots []SlotData) []venueSlotGroup {
+	var venues []venueSlotGroup
+	venueIndex := make(map[string]int)
+	dateIndex := make(map[string]map[string]int)
+
+	for _, slot := range slots {
+		vi, ok := venueIndex[slot.VenueName]
+		if !ok {
+			vi = len(venues)
+			venueIndex[slot.VenueName] = vi
+			dateIndex[slot.VenueName] = make(map[string]int)
+			venues = append(venues, venueSlotGroup{Name: slot.VenueName})
+		}
+
+		di, ok := dateIndex[slot.VenueName][slot.Date]
+		if !ok {
+			di = len(venues[vi].Dates)
+			dateIndex[slot.VenueName][slot.Date] = di
+			venues[vi].Dates = append(venues[vi].Dates, dateSlotGroup{Date: slot.Date})
+		}
+
+		venues[vi].Dates[di].Slots = append(venues[vi].Dates[di].Slots, slot)
+	}
+
+	return venues
+}
+
+// renderCourtAlertHTML renders the HTML body for a batch of slots with the locale's template
+func renderCourtAlertHTML(slots []SlotData, unsubscribeURL string, locale *alertLocale) (string, error) {
+	var buf bytes.Buffer
+	err := courtAlertHTMLTemplates[locale.Language].Execute(&buf, courtAlertHTMLData{
+		Title:          alertHeadline(slots, locale),
+		Text:           locale,
+		Venues:         groupSlotsByVenueAndDate(slots),
+		UnsubscribeURL: unsubscribeURL,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render HTML email: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// buildMultipartMessage assembles a multipart/alternative message with plain-text and HTML parts.
+// A non-empty unsubscribeURL adds the RFC 8058 headers so mail clients offer one-click unsubscribe.
+func buildMultipartMessage(from, to, subject, textBody, htmlBody, unsubscribeURL string) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		// Clients render the last part they support, so plain text goes first
+		{"text/plain; charset=UTF-8", textBody},
+		{"text/html; charset=UTF-8", htmlBody},
+	}
+
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", part.contentType)
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create MIME part: %w", err)
+		}
+
+		qp := quotedprintable.NewWriter(partWriter)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to encode MIME part: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode MIME part: %w", err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close MIME writer: %w", err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if unsubscribeURL != "" {
+		fmt.Fprintf(&msg, "List-Unsubscribe: <%s>\r\n", unsubscribeURL)
+		msg.WriteString("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=\"%s\"\r\n", writer.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
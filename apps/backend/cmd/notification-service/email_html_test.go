@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAlertSlots() []SlotData {
+	return []SlotData{
+		{VenueName: "Victoria Park", CourtName: "Court 1", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 12, BookingURL: "https://example.com/vp/1"},
+		{VenueName: "Stratford Park", CourtName: "Court 2", Date: "2024-06-15", StartTime: "19:00", EndTime: "20:00", Price: 10, BookingURL: "https://example.com/sp/2"},
+		{VenueName: "Victoria Park", CourtName: "Court 3", Date: "2024-06-16", StartTime: "09:00", EndTime: "10:00", Price: 8, BookingURL: "https://example.com/vp/3"},
+	}
+}
+
+func TestGroupSlotsByVenueAndDate(t *testing.T) {
+	groups := groupSlotsByVenueAndDate(testAlertSlots())
+
+	require.Len(t, groups, 2)
+	assert.Equal(t, "Victoria Park", groups[0].Name)
+	require.Len(t, groups[0].Dates, 2)
+	assert.Equal(t, "2024-06-15", groups[0].Dates[0].Date)
+	assert.Equal(t, "2024-06-16", groups[0].Dates[1].Date)
+	assert.Equal(t, "Stratford Park", groups[1].Name)
+	require.Len(t, groups[1].Dates, 1)
+}
+
+func TestRenderCourtAlertHTML(t *testing.T) {
+	html, err := renderCourtAlertHTML(testAlertSlots(), "", english)
+	require.NoError(t, err)
+
+	assert.Contains(t, html, "3 tennis courts just became available!")
+	assert.Contains(t, html, "<h2 style=\"margin:24px 0 8px 0;font-size:18px;color:#1f2933;\">Victoria Park</h2>")
+	assert.Contains(t, html, `href="https://example.com/sp/2"`)
+	assert.Contains(t, html, "Book now")
+	assert.Contains(t, html, "£12.00")
+}
+
+func TestBuildMultipartMessage(t *testing.T) {
+	raw, err := buildMultipartMessage(
+		`"Tennis Court Alerts" <alerts@example.com>`,
+		"user@example.com",
+		"🎾 Tennis Court Available!",
+		"plain body\nsecond line",
+		"<p>html body</p>",
+		"",
+	)
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "🎾 Tennis Court Available!", subject)
+	assert.Equal(t, "1.0", msg.Header.Get("MIME-Version"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+
+	var contentTypes, bodies []string
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		assert.Equal(t, "quoted-printable", part.Header.Get("Content-Transfer-Encoding"))
+
+		decoded, err := io.ReadAll(quotedprintable.NewReader(part))
+		require.NoError(t, err)
+
+		contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(decoded))
+	}
+
+	require.Len(t, contentTypes, 2)
+	assert.True(t, strings.HasPrefix(contentTypes[0], "text/plain"))
+	assert.True(t, strings.HasPrefix(contentTypes[1], "text/html"))
+	assert.Equal(t, "plain body\r\nsecond line", bodies[0])
+	assert.Equal(t, "<p>html body</p>", bodies[1])
+}
+
+func TestBuildMultipartMessage_ListUnsubscribeHeaders(t *testing.T) {
+	raw, err := buildMultipartMessage(
+		`"Tennis Court Alerts" <alerts@example.com>`,
+		"user@example.com",
+		"🎾 Tennis Court Available!",
+		"plain body",
+		"<p>html body</p>",
+		"https://api.example.com/api/notifications/unsubscribe?token=abc",
+	)
+	require.NoError(t, err)
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	require.NoError(t, err)
+	assert.Equal(t, "<https://api.example.com/api/notifications/unsubscribe?token=abc>", msg.Header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+}
+
+func TestRenderCourtAlertHTML_UnsubscribeFooter(t *testing.T) {
+	html, err := renderCourtAlertHTML(testAlertSlots(), "https://api.example.com/api/notifications/unsubscribe?token=abc", english)
+	require.NoError(t, err)
+	assert.Contains(t, html, `href="https://api.example.com/api/notifications/unsubscribe?token=abc"`)
+	assert.Contains(t, html, "Unsubscribe from these alerts")
+
+	html, err = renderCourtAlertHTML(testAlertSlots(), "", english)
+	require.NoError(t, err)
+	assert.NotContains(t, html, "Unsubscribe")
+}
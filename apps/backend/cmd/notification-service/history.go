@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"tennis-booker/internal/models"
+)
+
+// Alert history statuses recorded for each delivery
+const (
+	alertStatusSent   = "sent"
+	alertStatusFailed = "failed"
+)
+
+// alertRecorder stores delivery outcomes; satisfied by models.AlertHistoryService
+type alertRecorder interface {
+	CreateAlert(ctx context.Context, alert *models.AlertHistory) error
+}
+
+// recordAlertHistory writes one alert_history entry per slot with the channel's delivery
+// outcome, so users can see what they were alerted about via /api/notifications/history
+func (s *NotificationService) recordAlertHistory(user User, channel, address string, slots []SlotData, sendErr error) {
+	if s.alertHistory == nil {
+		return
+	}
+
+	status := alertStatusSent
+	if sendErr != nil {
+		status = alertStatusFailed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, slot := range slots {
+		event := slotToAvailabilityEvent(slot)
+		alert := &models.AlertHistory{
+			UserID:        user.ID,
+			VenueID:       event.VenueID,
+			VenueName:     event.VenueName,
+			CourtID:       event.CourtID,
+			CourtName:     event.CourtName,
+			SlotDate:      event.Date,
+			SlotStartTime: event.StartTime,
+			SlotEndTime:   event.EndTime,
+			Price:         event.Price,
+			Currency:      event.Currency,
+			BookingURL:    event.BookingURL,
+			EmailAddress:  address,
+			Channel:       channel,
+			EmailStatus:   status,
+			SlotKey:       event.GenerateSlotKey(),
+		}
+		if err := s.alertHistory.CreateAlert(ctx, alert); err != nil {
+			s.logger.Warn("Failed to record alert history", map[string]interface{}{"channel": channel, "user_email": user.Email, "error": err.Error()})
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/models"
+)
+
+// memoryAlertHistory collects alert history records in memory
+type memoryAlertHistory struct {
+	mu     sync.Mutex
+	alerts []models.AlertHistory
+}
+
+func (m *memoryAlertHistory) CreateAlert(ctx context.Context, alert *models.AlertHistory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.alerts = append(m.alerts, *alert)
+	return nil
+}
+
+func TestSendBatchedNotification_RecordsAlertHistoryPerChannel(t *testing.T) {
+	history := &memoryAlertHistory{}
+	s := newTestNotificationService()
+	s.alertHistory = history
+	s.registerChannel(ChannelEmail, newRecordingChannel())
+	s.registerChannel(ChannelSMS, failingChannel{})
+
+	user := User{ID: primitive.NewObjectID(), Email: "a@example.com", EmailEnabled: true, SMSEnabled: true, PhoneNumber: "+447700900123"}
+	slots := []SlotData{
+		{VenueID: "v1", VenueName: "Victoria Park", CourtID: "c1", CourtName: "Court 1", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 10},
+		{VenueID: "v1", VenueName: "Victoria Park", CourtID: "c2", CourtName: "Court 2", Date: "2024-06-15", StartTime: "19:00", EndTime: "20:00", Price: 10},
+	}
+
+	require.Error(t, s.sendBatchedNotification(user, slots))
+
+	require.Len(t, history.alerts, 4)
+	byChannel := map[string][]models.AlertHistory{}
+	for _, alert := range history.alerts {
+		assert.Equal(t, user.ID, alert.UserID)
+		byChannel[alert.Channel] = append(byChannel[alert.Channel], alert)
+	}
+
+	require.Len(t, byChannel[ChannelEmail], 2)
+	assert.Equal(t, alertStatusSent, byChannel[ChannelEmail][0].EmailStatus)
+	assert.Equal(t, "a@example.com", byChannel[ChannelEmail][0].EmailAddress)
+	assert.Equal(t, "v1:c1:2024-06-15:18:00", byChannel[ChannelEmail][0].SlotKey)
+	assert.Equal(t, "GBP", byChannel[ChannelEmail][0].Currency)
+
+	require.Len(t, byChannel[ChannelSMS], 2)
+	assert.Equal(t, alertStatusFailed, byChannel[ChannelSMS][0].EmailStatus)
+	assert.Equal(t, "+447700900123", byChannel[ChannelSMS][0].EmailAddress)
+}
+
+func TestSendNotification_UsesEveryEnabledChannel(t *testing.T) {
+	s := newTestNotificationService()
+	email, sms := newRecordingChannel(), newRecordingChannel()
+	s.registerChannel(ChannelEmail, email)
+	s.registerChannel(ChannelSMS, sms)
+
+	user := User{Email: "a@example.com", EmailEnabled: true, SMSEnabled: true, PhoneNumber: "+447700900123"}
+	slot := SlotData{VenueID: "v1", VenueName: "Victoria Park", CourtID: "c1", CourtName: "Court 1", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 10}
+
+	require.NoError(t, s.sendNotification(user, slot))
+	assert.Len(t, email.sendTimes("a@example.com"), 1)
+	assert.Len(t, sms.sendTimes("+447700900123"), 1)
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Languages alert emails are translated into, matching display_settings.language
+const (
+	LanguageEnglish = "en"
+	LanguageFrench  = "fr"
+	LanguageSpanish = "es"
+)
+
+// alertLocale holds an alert email's text and formatting in one language.
+// Messages containing %d or %s are format strings.
+type alertLocale struct {
+	Language string
+
+	// Subject lines, picked by slotAlertSubject
+	SubjectNewCourt       string
+	SubjectNewCourts      string
+	SubjectFreedUp        string
+	SubjectFreedUpMany    string
+	SubjectDeal           string
+	SubjectDeals          string
+	SubjectCourtAvailable string // Plain alerts built from preformatted details
+	SubjectCourtsMultiple string
+	SubjectLimitSummary   string
+
+	// Headlines, picked by alertHeadline
+	HeadlineNewCourt      string
+	HeadlineNewCourts     string // %d slots
+	HeadlineFreedUp       string
+	HeadlineCancellations string // %d slots, %d cancellations
+	HeadlineDeal          string
+	HeadlineDeals         string // %d slots, %d deals
+	HeadlineLimitSummary  string // %d slots
+
+	// Body text
+	Intro          string
+	QuickLinks     string
+	CourtDetails   string
+	FreedUpNote    string
+	DealTargetNote string
+	DealUsualNote  string // %s usual price
+	FreedUpBadge   string
+	DealBadge      string
+	BookNow        string
+	PrimaryLink    string
+	Unsubscribe    string
+	Footer         string
+
+	// Field labels for table headers and single-slot alerts
+	Venue string
+	Court string
+	Date  string
+	Time  string
+	Price string
+
+	// Formatting
+	Weekdays    [7]string  // Sunday first, matching time.Weekday
+	Months      [12]string // January first
+	DateFormat  string     // %[1]s weekday, %[2]d day, %[3]s month, %[4]d year
+	AmountFirst bool       // Prices read "12,00 £" rather than "£12.00"
+	DecimalSep  string
+}
+
+// alertLocales are the supported alert languages, keyed by language code
+var alertLocales = map[string]*alertLocale{
+	LanguageEnglish: {
+		Language:              LanguageEnglish,
+		SubjectNewCourt:       "🎾 New court available!",
+		SubjectNewCourts:      "🎾 New courts available!",
+		SubjectFreedUp:        "🎾 Court freed up!",
+		SubjectFreedUpMany:    "🎾 Courts freed up!",
+		SubjectDeal:           "💰 Court deal available!",
+		SubjectDeals:          "💰 Court deals available!",
+		SubjectCourtAvailable: "🎾 Tennis Court Available!",
+		SubjectCourtsMultiple: "🎾 Multiple Tennis Courts Available!",
+		SubjectLimitSummary:   "🎾 Courts you missed while over your alert limit",
+		HeadlineNewCourt:      "A tennis court just became available!",
+		HeadlineNewCourts:     "%d tennis courts just became available!",
+		HeadlineFreedUp:       "A tennis court just freed up!",
+		HeadlineCancellations: "%d tennis courts just became available, %d freed up by cancellations!",
+		HeadlineDeal:          "A tennis court deal just became available!",
+		HeadlineDeals:         "%d tennis courts just became available, %d at deal prices!",
+		HeadlineLimitSummary:  "You hit your alert limit - here are the %d courts you missed!",
+		Intro:                 "These slots just became available - book quickly!",
+		QuickLinks:            "QUICK BOOKING LINKS",
+		CourtDetails:          "COURT DETAILS",
+		FreedUpNote:           "freed up by a cancellation",
+		DealTargetNote:        "deal: at or below your target price",
+		DealUsualNote:         "deal: usually %s",
+		FreedUpBadge:          "Freed up",
+		DealBadge:             "Deal",
+		BookNow:               "Book now",
+		PrimaryLink:           "Primary booking link",
+		Unsubscribe:           "Unsubscribe from these alerts",
+		Footer:                "Tennis Court Booking Alert System",
+		Venue:                 "Venue",
+		Court:                 "Court",
+		Date:                  "Date",
+		Time:                  "Time",
+		Price:                 "Price",
+		Weekdays:              [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		Months:                [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		DateFormat:            "%[1]s %[2]d %[3]s %[4]d",
+		DecimalSep:            ".",
+	},
+	LanguageFrench: {
+		Language:              LanguageFrench,
+		SubjectNewCourt:       "🎾 Nouveau court disponible !",
+		SubjectNewCourts:      "🎾 Nouveaux courts disponibles !",
+		SubjectFreedUp:        "🎾 Un court s'est libéré !",
+		SubjectFreedUpMany:    "🎾 Des courts se sont libérés !",
+		SubjectDeal:           "💰 Bon plan : court disponible !",
+		SubjectDeals:          "💰 Bons plans : courts disponibles !",
+		SubjectCourtAvailable: "🎾 Court de tennis disponible !",
+		SubjectCourtsMultiple: "🎾 Plusieurs courts de tennis disponibles !",
+		SubjectLimitSummary:   "🎾 Les courts manqués pendant votre limite d'alertes",
+		HeadlineNewCourt:      "Un court de tennis est désormais disponible !",
+		HeadlineNewCourts:     "%d courts de tennis sont désormais disponibles !",
+		HeadlineFreedUp:       "Un court de tennis vient de se libérer !",
+		HeadlineCancellations: "%d courts de tennis sont désormais disponibles, dont %d libérés par des annulations !",
+		HeadlineDeal:          "Un court de tennis à prix réduit est désormais disponible !",
+		HeadlineDeals:         "%d courts de tennis sont désormais disponibles, dont %d à prix réduit !",
+		HeadlineLimitSummary:  "Vous avez atteint votre limite d'alertes - voici les %d courts que vous avez manqués !",
+		Intro:                 "Ces créneaux sont désormais disponibles - réservez vite !",
+		QuickLinks:            "LIENS DE RÉSERVATION RAPIDE",
+		CourtDetails:          "DÉTAILS DES COURTS",
+		FreedUpNote:           "libéré par une annulation",
+		DealTargetNote:        "bon plan : à votre prix cible ou moins",
+		DealUsualNote:         "bon plan : habituellement %s",
+		FreedUpBadge:          "Libéré",
+		DealBadge:             "Bon plan",
+		BookNow:               "Réserver",
+		PrimaryLink:           "Lien de réservation principal",
+		Unsubscribe:           "Se désabonner de ces alertes",
+		Footer:                "Système d'alertes de réservation de courts de tennis",
+		Venue:                 "Club",
+		Court:                 "Court",
+		Date:                  "Date",
+		Time:                  "Horaire",
+		Price:                 "Prix",
+		Weekdays:              [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		Months:                [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		DateFormat:            "%[1]s %[2]d %[3]s %[4]d",
+		AmountFirst:           true,
+		DecimalSep:            ",",
+	},
+	LanguageSpanish: {
+		Language:              LanguageSpanish,
+		SubjectNewCourt:       "🎾 ¡Nueva pista disponible!",
+		SubjectNewCourts:      "🎾 ¡Nuevas pistas disponibles!",
+		SubjectFreedUp:        "🎾 ¡Se ha liberado una pista!",
+		SubjectFreedUpMany:    "🎾 ¡Se han liberado pistas!",
+		SubjectDeal:           "💰 ¡Pista en oferta disponible!",
+		SubjectDeals:          "💰 ¡Pistas en oferta disponibles!",
+		SubjectCourtAvailable: "🎾 ¡Pista de tenis disponible!",
+		SubjectCourtsMultiple: "🎾 ¡Varias pistas de tenis disponibles!",
+		SubjectLimitSummary:   "🎾 Pistas que te perdiste al superar tu límite de alertas",
+		HeadlineNewCourt:      "¡Una pista de tenis acaba de quedar disponible!",
+		HeadlineNewCourts:     "¡%d pistas de tenis acaban de quedar disponibles!",
+		HeadlineFreedUp:       "¡Una pista de tenis acaba de liberarse!",
+		HeadlineCancellations: "¡%d pistas de tenis acaban de quedar disponibles, %d liberadas por cancelaciones!",
+		HeadlineDeal:          "¡Una pista de tenis en oferta acaba de quedar disponible!",
+		HeadlineDeals:         "¡%d pistas de tenis acaban de quedar disponibles, %d a precio de oferta!",
+		HeadlineLimitSummary:  "Has alcanzado tu límite de alertas: ¡estas son las %d pistas que te perdiste!",
+		Intro:                 "Estos horarios acaban de quedar disponibles: ¡reserva rápido!",
+		QuickLinks:            "ENLACES DE RESERVA RÁPIDA",
+		CourtDetails:          "DETALLES DE LAS PISTAS",
+		FreedUpNote:           "liberada por una cancelación",
+		DealTargetNote:        "oferta: a tu precio objetivo o menos",
+		DealUsualNote:         "oferta: normalmente %s",
+		FreedUpBadge:          "Liberada",
+		DealBadge:             "Oferta",
+		BookNow:               "Reservar",
+		PrimaryLink:           "Enlace de reserva principal",
+		Unsubscribe:           "Darse de baja de estas alertas",
+		Footer:                "Sistema de alertas de reserva de pistas de tenis",
+		Venue:                 "Club",
+		Court:                 "Pista",
+		Date:                  "Fecha",
+		Time:                  "Hora",
+		Price:                 "Precio",
+		Weekdays:              [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		Months:                [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		DateFormat:            "%[1]s, %[2]d de %[3]s de %[4]d",
+		AmountFirst:           true,
+		DecimalSep:            ",",
+	},
+}
+
+// localeFor returns the alert locale for a language code such as "fr" or
+// "fr-CA", falling back to English for unset or unsupported languages
+func localeFor(language string) *alertLocale {
+	base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(language)), "-")
+	base, _, _ = strings.Cut(base, "_")
+	if locale, ok := alertLocales[base]; ok {
+		return locale
+	}
+	return alertLocales[LanguageEnglish]
+}
+
+// userLocale returns the locale of the user's preferred language
+func userLocale(user User) *alertLocale {
+	return localeFor(user.Language)
+}
+
+// formatDate renders a slot's YYYY-MM-DD date in the locale, e.g. "Saturday
+// 15 June 2024" or "samedi 15 juin 2024". Unparseable dates are returned as is.
+func (l *alertLocale) formatDate(date string) string {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return fmt.Sprintf(l.DateFormat, l.Weekdays[day.Weekday()], day.Day(), l.Months[day.Month()-1], day.Year())
+}
+
+// formatPrice renders a price the way the locale writes it, e.g. "£12.50" in
+// English or "12,50 £" in French
+func (l *alertLocale) formatPrice(price float64, currency string) string {
+	if !l.AmountFirst {
+		return formatPrice(price, currency)
+	}
+
+	if currency == "" {
+		currency = defaultCurrency
+	}
+	currency = strings.ToUpper(currency)
+
+	amount := strings.Replace(fmt.Sprintf("%.2f", price), ".", l.DecimalSep, 1)
+	if symbol, ok := currencySymbols[currency]; ok {
+		return amount + " " + strings.TrimSpace(symbol)
+	}
+	return amount + " " + currency
+}
+
+// slotPrice renders the slot's price in the locale
+func (l *alertLocale) slotPrice(slot SlotData) string {
+	return l.formatPrice(slot.Price, slotCurrency(slot))
+}
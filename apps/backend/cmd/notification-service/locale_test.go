@@ -0,0 +1,94 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tennis-booker/internal/models"
+)
+
+// english is the default alert locale
+var english = localeFor(LanguageEnglish)
+
+func TestLocaleFor(t *testing.T) {
+	assert.Equal(t, LanguageFrench, localeFor("fr").Language)
+	assert.Equal(t, LanguageFrench, localeFor("fr-CA").Language)
+	assert.Equal(t, LanguageSpanish, localeFor(" ES_mx ").Language)
+	assert.Equal(t, LanguageEnglish, localeFor("").Language)
+	assert.Equal(t, LanguageEnglish, localeFor("de").Language, "unsupported languages fall back to English")
+	assert.Equal(t, LanguageFrench, userLocale(User{Language: "fr"}).Language)
+}
+
+func TestAlertLocales_Complete(t *testing.T) {
+	for language, locale := range alertLocales {
+		assert.Equal(t, language, locale.Language)
+		assert.NotEmpty(t, locale.SubjectNewCourt, language)
+		assert.NotEmpty(t, locale.HeadlineDeals, language)
+		assert.NotEmpty(t, locale.HeadlineLimitSummary, language)
+		assert.NotEmpty(t, locale.BookNow, language)
+		assert.NotEmpty(t, locale.Unsubscribe, language)
+		assert.NotEmpty(t, locale.DateFormat, language)
+		assert.NotEmpty(t, locale.Months[11], language)
+		assert.Contains(t, courtAlertHTMLTemplates, language)
+	}
+}
+
+func TestAlertLocale_FormatDate(t *testing.T) {
+	assert.Equal(t, "Saturday 15 June 2024", english.formatDate("2024-06-15"))
+	assert.Equal(t, "samedi 15 juin 2024", localeFor(LanguageFrench).formatDate("2024-06-15"))
+	assert.Equal(t, "sábado, 15 de junio de 2024", localeFor(LanguageSpanish).formatDate("2024-06-15"))
+	assert.Equal(t, "15/06/2024", english.formatDate("15/06/2024"), "unparseable dates are left alone")
+}
+
+func TestAlertLocale_FormatPrice(t *testing.T) {
+	french := localeFor(LanguageFrench)
+
+	assert.Equal(t, "£12.50", english.formatPrice(12.5, "GBP"))
+	assert.Equal(t, "12,50 £", french.formatPrice(12.5, ""))
+	assert.Equal(t, "30,00 €", localeFor(LanguageSpanish).formatPrice(30, "eur"))
+	assert.Equal(t, "20,00 CHF", french.formatPrice(20, "CHF"))
+	assert.Equal(t, "99,00 SEK", french.formatPrice(99, "SEK"))
+}
+
+func TestLocalizedAlertContent(t *testing.T) {
+	french := localeFor(LanguageFrench)
+	slots := []SlotData{{
+		VenueName: "Victoria Park", CourtName: "Court 1", Date: "2024-06-15",
+		StartTime: "18:00", EndTime: "19:00", Price: 12, AlertType: models.AlertTypeCancellation,
+		BookingURL: "https://example.com/vp/1",
+	}}
+
+	assert.Equal(t, "🎾 Un court s'est libéré !", slotAlertSubject(slots, french))
+	assert.Equal(t, "🎾 ¡Se han liberado pistas!", slotAlertSubject(append(slots, slots...), localeFor(LanguageSpanish)))
+
+	html, err := renderCourtAlertHTML(slots, "https://unsub", french)
+	require.NoError(t, err)
+	assert.Contains(t, html, "Un court de tennis vient de se libérer !")
+	assert.Contains(t, html, "samedi 15 juin 2024")
+	assert.Contains(t, html, "12,00 £")
+	assert.Contains(t, html, "Réserver</a>")
+	assert.Contains(t, html, "Se désabonner de ces alertes")
+	assert.NotContains(t, html, "Book now")
+
+	text := formatBatchedEmailDetails(slots, french)
+	assert.Contains(t, text, "LIENS DE RÉSERVATION RAPIDE")
+	assert.Contains(t, text, "📅 samedi 15 juin 2024:")
+	assert.Contains(t, text, "Court 1: 18:00-19:00 (12,00 £) - libéré par une annulation")
+
+	body := courtAlertTextBody(text, "https://example.com/vp/1", "https://unsub", french)
+	assert.Contains(t, body, "Lien de réservation principal: https://example.com/vp/1")
+	assert.Contains(t, body, "Se désabonner de ces alertes: https://unsub")
+}
+
+func TestSendBatchedNotification_UsesUserLanguage(t *testing.T) {
+	s := newTestNotificationService()
+	sender := &capturingHTMLSender{}
+	s.registerChannel(ChannelEmail, sender)
+
+	user := User{Email: "a@example.com", EmailEnabled: true, Language: LanguageSpanish}
+	require.NoError(t, s.sendBatchedNotification(user, testAlertSlots()))
+
+	assert.Equal(t, LanguageSpanish, sender.language)
+	assert.Contains(t, sender.courtDetails, "ENLACES DE RESERVA RÁPIDA")
+}
@@ -2,14 +2,15 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
-	"net/smtp"
+	"net/mail"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -18,24 +19,51 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"tennis-booker/internal/auth"
 	"tennis-booker/internal/database"
+	"tennis-booker/internal/logging"
 	"tennis-booker/internal/models"
 	"tennis-booker/internal/secrets"
 )
 
 // User represents user preferences for notifications
 type User struct {
-	ID                  primitive.ObjectID `bson:"_id"`
-	Email               string             `bson:"email"`
-	Name                string             `bson:"name"`
-	PreferredVenues     []string           `bson:"preferredVenues"`
-	TimePreferences     TimePreferences    `bson:"timePreferences"`
-	MaxPrice            float64            `bson:"maxPrice"`
-	NotificationEnabled bool               `bson:"notificationEnabled"`
-	CreatedAt           time.Time          `bson:"createdAt"`
-	UpdatedAt           time.Time          `bson:"updatedAt"`
+	ID                  primitive.ObjectID          `bson:"_id"`
+	Email               string                      `bson:"email"`
+	Name                string                      `bson:"name"`
+	PreferredVenues     []string                    `bson:"preferredVenues"`
+	ExcludedVenues      []string                    `bson:"excludedVenues"` // Never alerted, even when also preferred
+	PreferredDays       []string                    `bson:"preferredDays"`  // Lowercase weekday names; see TimePreferences for how they combine
+	TimePreferences     TimePreferences             `bson:"timePreferences"`
+	MaxPrice            float64                     `bson:"maxPrice"`    // Compared directly with slot prices, so assumed to be in the venue's currency; 0 is no limit
+	TargetPrice         float64                     `bson:"targetPrice"` // Slots at or below it are alerted as deals; 0 opts out of deal alerts
+	NotificationEnabled bool                        `bson:"notificationEnabled"`
+	EmailEnabled        bool                        `bson:"emailEnabled"`
+	SMSEnabled          bool                        `bson:"smsEnabled"`
+	PhoneNumber         string                      `bson:"phoneNumber"`
+	DeliveryMode        string                      `bson:"deliveryMode"`
+	DigestSendHour      int                         `bson:"digestSendHour"`
+	Timezone            string                      `bson:"timezone"`
+	Language            string                      `bson:"language"` // Alert email language, e.g. "fr"; unsupported languages get English
+	WebhookURL          string                      `bson:"webhookUrl"`
+	WebhookSecret       string                      `bson:"webhookSecret"`
+	AlertWindowStart    string                      `bson:"alertWindowStart"`    // "HH:MM" in the user's timezone; alerts outside the window are held
+	AlertWindowEnd      string                      `bson:"alertWindowEnd"`      // May be earlier than the start for windows spanning midnight
+	VenueCooldown       time.Duration               `bson:"venueCooldown"`       // Minimum gap between alerts for the same venue; 0 disables it
+	DedupWindows        models.DeduplicationWindows `bson:"dedupWindows"`        // The user's overrides of the deduplication windows
+	SnoozeUntil         *time.Time                  `bson:"snoozeUntil"`         // Alerts are paused until this time
+	MaxAlertsPerHour    int                         `bson:"maxAlertsPerHour"`    // Alerts sent per clock hour; 0 is unlimited
+	MaxAlertsPerDay     int                         `bson:"maxAlertsPerDay"`     // Alerts sent per day in the user's timezone; 0 is unlimited
+	BufferLimitedAlerts bool                        `bson:"bufferLimitedAlerts"` // Over a limit, summarize what was missed once it resets instead of dropping it
+	CreatedAt           time.Time                   `bson:"createdAt"`
+	UpdatedAt           time.Time                   `bson:"updatedAt"`
 }
 
+// TimePreferences are the windows a slot must start in, in the user's timezone.
+// PreferredDays is checked first and picks which days alert at all; these
+// windows then pick the times on those days, weekday windows for Monday to
+// Friday and weekend windows for Saturday and Sunday. A preferred day whose
+// part of the week has no windows gets no alerts unless neither has any.
 type TimePreferences struct {
 	WeekdaySlots []TimeSlot `bson:"weekdaySlots"`
 	WeekendSlots []TimeSlot `bson:"weekendSlots"`
@@ -48,18 +76,24 @@ type TimeSlot struct {
 
 // SlotData represents a tennis court slot
 type SlotData struct {
-	VenueID     string    `json:"venueId"`
-	VenueName   string    `json:"venueName"`
-	Platform    string    `json:"platform"`
-	CourtID     string    `json:"courtId"`
-	CourtName   string    `json:"courtName"`
-	Date        string    `json:"date"`
-	StartTime   string    `json:"startTime"`
-	EndTime     string    `json:"endTime"`
-	Price       float64   `json:"price"`
-	IsAvailable bool      `json:"isAvailable"`
-	BookingURL  string    `json:"bookingUrl"`
-	ScrapedAt   time.Time `json:"scrapedAt"`
+	VenueID       string    `json:"venueId"`
+	VenueName     string    `json:"venueName"`
+	Platform      string    `json:"platform"`
+	CourtID       string    `json:"courtId"`
+	CourtName     string    `json:"courtName"`
+	Date          string    `json:"date"`
+	StartTime     string    `json:"startTime"`
+	EndTime       string    `json:"endTime"`
+	Price         float64   `json:"price"`
+	Currency      string    `json:"currency"` // ISO 4217 code from the scraper; empty means GBP
+	IsAvailable   bool      `json:"isAvailable"`
+	BookingURL    string    `json:"bookingUrl"`
+	ScrapedAt     time.Time `json:"scrapedAt"`
+	AlertType     string    `json:"alertType,omitempty"`     // models.AlertTypeNewSlot or models.AlertTypeCancellation; set when the slot is processed
+	Deal          string    `json:"deal,omitempty"`          // DealTargetPrice or DealBelowAverage when the slot is a deal for the recipient
+	AveragePrice  float64   `json:"averagePrice,omitempty"`  // The slot's recent average price, set for below-average deals
+	TimeZone      string    `json:"timeZone,omitempty"`      // Zone abbreviation of the date and times once converted for display, e.g. "BST"
+	MissedByLimit bool      `json:"missedByLimit,omitempty"` // Held back while the recipient was over their alert limit
 }
 
 // NotificationService handles the notification processing
@@ -67,105 +101,204 @@ type NotificationService struct {
 	db               *mongo.Database
 	redisClient      *redis.Client
 	deduplicationSvc *models.DeduplicationService
-	logger           *log.Logger
+	digestSvc        *models.DigestService
+	logger           *logging.Logger
 	users            []User
-	usersMutex       sync.RWMutex          // Protects users slice during reload
+	usersMutex       sync.RWMutex          // Protects users slice and venue settings during reload
+	venueTimezones   map[string]string     // Venue ID or name -> IANA timezone
+	bookingWindows   map[string]int        // Venue ID or name -> days ahead slots can be booked
+	venueCache       *database.VenueCache  // Venue metadata shared with the API through Redis
 	slotBatch        map[string][]SlotData // User email -> list of slots
+	batchTimers      map[string]batchTimer // User email -> timer that flushes that user's batch
+	startBatchTimer  batchTimerFunc        // Starts batch timers; time.AfterFunc unless a test replaces it
 	batchMutex       sync.RWMutex
-	batchTimer       *time.Timer
+	BatchWindow      time.Duration                  // How long to collect slots for a user before sending
+	SlotOrder        string                         // SlotOrderSoonest or SlotOrderCheapest
+	channels         map[string]NotificationChannel // Channel name -> delivery channel
+	parseFailures    atomic.Int64                   // Slot messages that failed to parse
+	metrics          *notificationMetrics           // Counters exposed on /metrics
+	alertHistory     alertRecorder                  // Records every delivery for the notification history API
+	ShutdownTimeout  time.Duration                  // How long shutdown waits for pending batches to send
+	shuttingDown     atomic.Bool                    // Set once shutdown starts; stops the engine reading new slots
+	engineWG         sync.WaitGroup                 // Running notification engine
+	sendWG           sync.WaitGroup                 // Batch flushes in progress
+	venueCooldowns   cooldownStore                  // Per-user, per-venue cooldowns; nil disables them
+	cooldownHeld     map[string]*cooldownBatch      // User email + venue -> slots held until the venue's cooldown ends
+	alertCounts      alertCounter                   // Alerts sent per user per hour and day; nil disables alert limits
+	limitHeld        map[string]*limitedBatch       // User email -> slots held until their alert limit resets
+	slotStates       slotStateStore                 // Last-known availability per slot; nil alerts on every available slot
+	priceHistory     priceAverageStore              // Recorded slot prices for below-average deals; nil disables them
+	queue            *reliableSlotQueue             // Claims court_slots messages so a crash mid-message doesn't lose them
+
+	// Signed one-click unsubscribe links added to every alert email (optional)
+	unsubscribeTokens  *auth.UnsubscribeTokenService
+	unsubscribeBaseURL string
 }
 
-// GmailService handles Gmail SMTP email notifications
-type GmailService struct {
-	smtpHost     string
-	smtpPort     string
-	fromEmail    string
-	fromPassword string
-	fromName     string
-	logger       *log.Logger
+// Notification channel names, matching the NotificationSettings flags in user_preferences
+const (
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// NotificationChannel is a delivery mechanism for court availability alerts
+type NotificationChannel interface {
+	Send(toAddress, courtDetails, bookingLink string) error
 }
 
-// NewGmailService creates a new Gmail SMTP service
-func NewGmailService(email, password, fromName string, logger *log.Logger) *GmailService {
-	return &GmailService{
-		smtpHost:     "smtp.gmail.com",
-		smtpPort:     "587",
-		fromEmail:    email,
-		fromPassword: password,
-		fromName:     fromName,
-		logger:       logger,
-	}
+// htmlAlertSender is implemented by channels that can render slots as a rich HTML alert
+type htmlAlertSender interface {
+	SendCourtAvailabilityAlertHTML(toEmail, courtDetails string, slots []SlotData, bookingLink, unsubscribeURL, language string) error
+}
+
+// Send implements NotificationChannel by emailing the alert
+func (g *SMTPService) Send(toAddress, courtDetails, bookingLink string) error {
+	return g.SendCourtAvailabilityAlert(toAddress, courtDetails, bookingLink, LanguageEnglish)
+}
+
+// SendCourtAvailabilityAlert sends email notification over SMTP, with the
+// subject and footer in the given language
+func (g *SMTPService) SendCourtAvailabilityAlert(toEmail, courtDetails, bookingLink, language string) error {
+	locale := localeFor(language)
+
+	return g.sendEmail(toEmail, courtAlertSubject(courtDetails, locale), courtAlertTextBody(courtDetails, bookingLink, "", locale))
 }
 
-// NewGmailServiceFromEnv creates a Gmail service using credentials from environment variables
-func NewGmailServiceFromEnv(secretsManager *secrets.SecretsManager, logger *log.Logger) (*GmailService, error) {
-	email, password, smtpHost, smtpPort, err := secretsManager.GetEmailCredentials()
+// SendCourtAvailabilityAlertHTML sends a multipart email with the plain-text court details
+// as a fallback and an HTML rendering of the slots grouped by venue and date, using the
+// templates for the given language. When unsubscribeURL is set it is linked from both
+// footers and advertised in the List-Unsubscribe headers.
+func (g *SMTPService) SendCourtAvailabilityAlertHTML(toEmail, courtDetails string, slots []SlotData, bookingLink, unsubscribeURL, language string) error {
+	locale := localeFor(language)
+
+	htmlBody, err := renderCourtAlertHTML(slots, unsubscribeURL, locale)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get email credentials: %w", err)
+		return err
 	}
 
-	// Use defaults if not provided
-	if smtpHost == "" {
-		smtpHost = "smtp.gmail.com"
-	}
-	if smtpPort == "" {
-		smtpPort = "587"
+	msg, err := buildMultipartMessage(
+		g.fromHeader(),
+		toEmail,
+		slotAlertSubject(slots, locale),
+		courtAlertTextBody(courtDetails, bookingLink, unsubscribeURL, locale),
+		htmlBody,
+		unsubscribeURL,
+	)
+	if err != nil {
+		return err
 	}
 
-	return &GmailService{
-		smtpHost:     smtpHost,
-		smtpPort:     smtpPort,
-		fromEmail:    email,
-		fromPassword: password,
-		fromName:     "Tennis Court Alerts",
-		logger:       logger,
-	}, nil
+	return g.deliver(toEmail, msg)
 }
 
-// SendCourtAvailabilityAlert sends email notification via Gmail SMTP
-func (g *GmailService) SendCourtAvailabilityAlert(toEmail, courtDetails, bookingLink string) error {
-	// Detect if this is a batched notification (multiple courts)
-	var subject string
+// courtAlertSubject picks the subject line based on whether this is a batched notification (multiple courts)
+func courtAlertSubject(courtDetails string, locale *alertLocale) string {
 	if strings.Contains(courtDetails, " courts just became available") {
-		subject = "🎾 Multiple Tennis Courts Available!"
-	} else {
-		subject = "🎾 Tennis Court Available!"
+		return locale.SubjectCourtsMultiple
+	}
+	return locale.SubjectCourtAvailable
+}
+
+// slotAlertSubject picks the subject line for an alert about these slots, calling
+// out deals first and then cancellations, since they're usually last-minute bargains
+func slotAlertSubject(slots []SlotData, locale *alertLocale) string {
+	deals := countDeals(slots)
+	switch cancellations := countCancellations(slots); {
+	case isLimitSummary(slots):
+		return locale.SubjectLimitSummary
+	case deals > 1:
+		return locale.SubjectDeals
+	case deals == 1:
+		return locale.SubjectDeal
+	case cancellations > 1:
+		return locale.SubjectFreedUpMany
+	case cancellations == 1:
+		return locale.SubjectFreedUp
+	case len(slots) > 1:
+		return locale.SubjectNewCourts
+	default:
+		return locale.SubjectNewCourt
+	}
+}
+
+// alertHeadline summarises a batch of slots at the top of an alert email
+func alertHeadline(slots []SlotData, locale *alertLocale) string {
+	cancellations := countCancellations(slots)
+	deals := countDeals(slots)
+	switch {
+	case isLimitSummary(slots):
+		return fmt.Sprintf(locale.HeadlineLimitSummary, len(slots))
+	case len(slots) == 1 && deals == 1:
+		return locale.HeadlineDeal
+	case deals > 0:
+		return fmt.Sprintf(locale.HeadlineDeals, len(slots), deals)
+	case len(slots) == 1 && cancellations == 1:
+		return locale.HeadlineFreedUp
+	case len(slots) == 1:
+		return locale.HeadlineNewCourt
+	case cancellations > 0:
+		return fmt.Sprintf(locale.HeadlineCancellations, len(slots), cancellations)
+	default:
+		return fmt.Sprintf(locale.HeadlineNewCourts, len(slots))
 	}
+}
 
+// courtAlertTextBody builds the plain-text email body, with an unsubscribe footer when a link is given
+func courtAlertTextBody(courtDetails, bookingLink, unsubscribeURL string, locale *alertLocale) string {
 	body := fmt.Sprintf(`%s
 
-🔗 Primary booking link: %s
+🔗 %s: %s
 
 ---
-Tennis Court Booking Alert System
-`, courtDetails, bookingLink)
+%s
+`, courtDetails, locale.PrimaryLink, bookingLink, locale.Footer)
 
-	// Send email via Gmail SMTP
-	return g.sendEmail(toEmail, subject, body)
+	if unsubscribeURL != "" {
+		body += fmt.Sprintf("%s: %s\n", locale.Unsubscribe, unsubscribeURL)
+	}
+	return body
 }
 
-func (g *GmailService) sendEmail(toEmail, subject, body string) error {
-	// Gmail SMTP configuration
-	auth := smtp.PlainAuth("", g.fromEmail, g.fromPassword, g.smtpHost)
+// fromHeader returns the formatted From header value
+func (g *SMTPService) fromHeader() string {
+	return (&mail.Address{Name: g.config.FromName, Address: g.config.FromEmail}).String()
+}
 
+func (g *SMTPService) sendEmail(toEmail, subject, body string) error {
 	// Compose message
 	msg := fmt.Sprintf("To: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s", toEmail, subject, body)
 
-	// Send email
-	addr := fmt.Sprintf("%s:%s", g.smtpHost, g.smtpPort)
-	err := smtp.SendMail(addr, auth, g.fromEmail, []string{toEmail}, []byte(msg))
+	return g.deliver(toEmail, []byte(msg))
+}
+
+// deliver sends a fully composed message over SMTP once the rate limit allows,
+// retrying transient failures
+func (g *SMTPService) deliver(toEmail string, msg []byte) error {
+	if g.sendMail == nil {
+		g.sendMail = g.sendSMTP
+	}
+
+	// Pace sends to the provider's quotas, then send. Sends over the daily
+	// quota are recorded for a retry instead of waiting for midnight.
+	if err := g.waitForRateLimit(toEmail); err != nil {
+		g.logger.Warn("Email not sent within the sending quota", map[string]interface{}{"user_email": toEmail, "error": err.Error()})
+		g.recordFailedNotification(toEmail, msg, 0, err)
+		return err
+	}
+	attempts, err := g.sendWithRetry(g.addr(), toEmail, msg)
 
 	if err != nil {
-		g.logger.Printf("❌ Failed to send email to %s: %v", toEmail, err)
+		g.logger.Error("Failed to send email", map[string]interface{}{"user_email": toEmail, "attempts": attempts, "error": err.Error()})
+		g.recordFailedNotification(toEmail, msg, attempts, err)
 		return err
 	}
 
-	g.logger.Printf("✅ Email sent successfully to %s", toEmail)
+	g.logger.Info("Email sent", map[string]interface{}{"user_email": toEmail})
 	return nil
 }
 
 // SendTestEmail sends a test email
-func (g *GmailService) SendTestEmail(toEmail string) error {
+func (g *SMTPService) SendTestEmail(toEmail string) error {
 	testDetails := fmt.Sprintf(`🎾 TEST NOTIFICATION
 
 Venue: Test Tennis Club
@@ -174,36 +307,96 @@ Date: %s
 Time: 19:00-20:00
 Price: £15.00`, time.Now().Format("2006-01-02"))
 
-	g.logger.Printf("📧 [TEST EMAIL] Sending test notification to %s", toEmail)
-	return g.SendCourtAvailabilityAlert(toEmail, testDetails, "https://example.com/book")
+	g.logger.Info("Sending test notification", map[string]interface{}{"user_email": toEmail})
+	return g.SendCourtAvailabilityAlert(toEmail, testDetails, "https://example.com/book", LanguageEnglish)
 }
 
 // NewNotificationService creates a new notification service
-func NewNotificationService(db *mongo.Database, redisClient *redis.Client, logger *log.Logger) *NotificationService {
+func NewNotificationService(db *mongo.Database, redisClient *redis.Client, logger *logging.Logger) *NotificationService {
+	venueCache := database.NewVenueCache(database.NewVenueRepository(db), database.NewRedisCacheStore(redisClient), database.VenueCacheTTLFromEnv())
+
 	return &NotificationService{
 		db:               db,
 		redisClient:      redisClient,
-		deduplicationSvc: models.NewDeduplicationService(db),
+		deduplicationSvc: newDeduplicationService(db, logger),
+		digestSvc:        models.NewDigestService(db),
 		logger:           logger,
 		slotBatch:        make(map[string][]SlotData),
+		batchTimers:      make(map[string]batchTimer),
+		BatchWindow:      batchWindowFromEnv(logger),
+		SlotOrder:        slotOrderFromEnv(logger),
+		ShutdownTimeout:  shutdownTimeoutFromEnv(logger),
+		channels:         make(map[string]NotificationChannel),
+		metrics:          newNotificationMetrics(),
+		alertHistory:     models.NewAlertHistoryService(db),
+		venueCooldowns:   newRedisCooldownStore(redisClient),
+		alertCounts:      newRedisAlertCounter(redisClient),
+		venueCache:       venueCache,
+		slotStates:       database.NewSlotStateRepository(db),
+		priceHistory:     database.NewPriceHistoryRepository(db),
+		queue:            newReliableSlotQueue(redisClient, logger),
+	}
+}
+
+// registerChannel makes a delivery channel available to the notification engine
+func (s *NotificationService) registerChannel(name string, channel NotificationChannel) {
+	if s.channels == nil {
+		s.channels = make(map[string]NotificationChannel)
+	}
+	s.channels[name] = channel
+}
+
+// slotFields identifies a slot in structured log entries
+func slotFields(slot SlotData) map[string]interface{} {
+	return map[string]interface{}{
+		"venue":      slot.VenueName,
+		"court":      slot.CourtName,
+		"slot_key":   slotKey(slot),
+		"alert_type": slot.AlertType,
 	}
 }
 
 // processSlotMessage processes a single slot message from Redis
 func (s *NotificationService) processSlotMessage(slotMessage string) {
-	var slot SlotData
-	if err := json.Unmarshal([]byte(slotMessage), &slot); err != nil {
-		s.logger.Printf("❌ Error parsing slot message: %v", err)
+	slot, err := decodeSlotMessage(slotMessage)
+	if errors.Is(err, models.ErrUnknownSchemaVersion) {
+		s.logger.Error("Slot message has a schema version this build can't decode", map[string]interface{}{"error": err.Error()})
+		s.sendToDeadLetterQueue(slotMessage, err)
 		return
 	}
+	if err != nil {
+		s.logger.Error("Failed to parse slot message", map[string]interface{}{"error": err.Error()})
+		s.sendToDeadLetterQueue(slotMessage, err)
+		return
+	}
+
+	s.logger.Info("Processing slot", slotFields(slot))
+	s.metrics.incSlotsProcessed()
 
-	s.logger.Printf("🎾 Processing slot: %s at %s (%s-%s)", slot.CourtName, slot.VenueName, slot.StartTime, slot.EndTime)
+	if !s.withinBookingWindow(slot, time.Now()) {
+		s.logger.Debug("Skipping slot outside the venue's booking window", slotFields(slot))
+		return
+	}
+
+	alertType, ok := s.observeSlot(slot, time.Now())
+	if !ok {
+		s.logger.Debug("Skipping slot that did not just become available", slotFields(slot))
+		s.metrics.incSlotsUnchanged()
+		return
+	}
+	slot.AlertType = alertType
 
 	// Check for users who might be interested in this slot
 	s.usersMutex.RLock()
 	users := s.users
 	s.usersMutex.RUnlock()
 
+	// Only look up the slot's price history when someone wants deal alerts
+	var averagePrice float64
+	if anyWantDeals(users) {
+		averagePrice = s.recentAveragePrice(slot, time.Now())
+	}
+
 	for _, user := range users {
 		if s.shouldNotifyUser(user, slot) {
 			// Use the consolidated deduplication service
@@ -216,27 +409,40 @@ func (s *NotificationService) processSlotMessage(slotMessage string) {
 				StartTime:    slot.StartTime,
 				EndTime:      slot.EndTime,
 				Price:        slot.Price,
-				Currency:     "GBP",
+				Currency:     slotCurrency(slot),
 				BookingURL:   slot.BookingURL,
 				DiscoveredAt: time.Now(),
+				AlertType:    slot.AlertType,
 			}
 
 			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-			dupCheck, err := s.deduplicationSvc.CheckForDuplicate(ctx, user.ID, event)
+			dupCheck, err := s.deduplicationSvc.CheckForDuplicate(ctx, user.ID, event, user.DedupWindows)
 			cancel()
 
 			if err != nil {
-				s.logger.Printf("❌ Error checking for duplicate: %v", err)
+				s.logger.Error("Failed to check for duplicate", map[string]interface{}{"user_email": user.Email, "slot_key": event.GenerateSlotKey(), "error": err.Error()})
 				continue
 			}
 
 			if dupCheck.IsDuplicate {
-				s.logger.Printf("🔄 Skipping duplicate for %s: %s", user.Email, dupCheck.ReasonDescription)
+				s.logger.Debug("Skipping duplicate", map[string]interface{}{"user_email": user.Email, "slot_key": event.GenerateSlotKey(), "reason_code": dupCheck.ReasonCode, "reason": dupCheck.ReasonDescription})
+				s.metrics.incDuplicatesSkipped()
 				continue
 			}
 
-			// Add to batch for this user
-			s.addSlotToBatch(user, slot)
+			if isDigestUser(user) {
+				// Hold the slot for the user's daily digest instead of alerting now
+				if err := s.queueForDigest(user, event); err != nil {
+					s.logger.Error("Failed to queue slot for digest", map[string]interface{}{"user_email": user.Email, "slot_key": event.GenerateSlotKey(), "error": err.Error()})
+					continue
+				}
+			} else if wait := s.venueCooldownRemaining(user, slot); wait > 0 {
+				// The user was alerted about this venue recently; send it with their next batch once the cooldown ends
+				s.holdForVenueCooldown(user, markDeal(user, slot, averagePrice), wait)
+			} else {
+				// Add to batch for this user
+				s.addSlotToBatch(user, markDeal(user, slot, averagePrice))
+			}
 
 			// Record the notification
 			ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
@@ -244,7 +450,7 @@ func (s *NotificationService) processSlotMessage(slotMessage string) {
 			cancel()
 
 			if err != nil {
-				s.logger.Printf("❌ Error recording notification: %v", err)
+				s.logger.Error("Failed to record notification", map[string]interface{}{"user_email": user.Email, "slot_key": event.GenerateSlotKey(), "error": err.Error()})
 			}
 		}
 	}
@@ -258,53 +464,52 @@ func main() {
 	godotenv.Load("../../.env")
 
 	// Configure logging
-	logger := log.New(os.Stdout, "[NOTIFICATION-SERVICE] ", log.LstdFlags|log.Lshortfile)
-	logger.Println("🚀 Starting Tennis Court Notification Service...")
+	logger := logging.New("notification-service")
+	logger.Info("Starting notification service")
 
 	// Check for test mode
 	if len(os.Args) > 1 && os.Args[1] == "test" {
-		logger.Println("📧 Running in test mode - sending test email...")
+		logger.Info("Running in test mode, sending test email")
 
 		// Try to use environment variables for test email
 		secretsManager, err := secrets.NewSecretsManagerFromEnv()
 		if err != nil {
-			logger.Printf("⚠️ Failed to connect to environment variables for test: %v", err)
-			logger.Println("🔄 Using fallback credentials for test...")
+			logger.Warn("Failed to load secrets for test, using fallback credentials", map[string]interface{}{"error": err.Error()})
 
 			// Fallback to environment variables (no hardcoded credentials)
 			email := os.Getenv("GMAIL_EMAIL")
 			password := os.Getenv("GMAIL_PASSWORD")
 
 			if email == "" || password == "" {
-				logger.Printf("❌ Test mode requires either environment variables or GMAIL_EMAIL/GMAIL_PASSWORD environment variables")
+				logger.Error("Test mode requires GMAIL_EMAIL and GMAIL_PASSWORD environment variables")
 				os.Exit(1)
 			}
 
-			gmailService := NewGmailService(email, password, "Tennis Court Alerts", logger)
+			emailService := NewGmailService(email, password, alertFromName, logger)
 
 			// Use the configured email for testing
-			if err := gmailService.SendTestEmail(email); err != nil {
-				logger.Printf("❌ Test email failed: %v", err)
+			if err := emailService.SendTestEmail(email); err != nil {
+				logger.Error("Test email failed", map[string]interface{}{"error": err.Error()})
 				os.Exit(1)
 			} else {
-				logger.Println("✅ Test email sent successfully!")
+				logger.Info("Test email sent")
 				os.Exit(0)
 			}
 		} else {
 			defer secretsManager.Close()
 
-			gmailService, err := NewGmailServiceFromEnv(secretsManager, logger)
+			emailService, err := NewSMTPServiceFromEnv(secretsManager, logger)
 			if err != nil {
-				logger.Printf("❌ Failed to create Gmail service from environment variables: %v", err)
+				logger.Error("Failed to create SMTP service from environment variables", map[string]interface{}{"error": err.Error()})
 				os.Exit(1)
 			}
 
 			// Use the same email that's configured in environment variables for testing
-			if err := gmailService.SendTestEmail(gmailService.fromEmail); err != nil {
-				logger.Printf("❌ Test email failed: %v", err)
+			if err := emailService.SendTestEmail(emailService.config.FromEmail); err != nil {
+				logger.Error("Test email failed", map[string]interface{}{"error": err.Error()})
 				os.Exit(1)
 			} else {
-				logger.Println("✅ Test email sent successfully using environment variables credentials!")
+				logger.Info("Test email sent")
 				os.Exit(0)
 			}
 		}
@@ -313,8 +518,7 @@ func main() {
 	// Initialize database connection using environment variables
 	connectionManager, err := database.NewConnectionManagerFromEnv()
 	if err != nil {
-		logger.Printf("⚠️ Failed to create database connection manager: %v", err)
-		logger.Println("🔄 Attempting fallback connection...")
+		logger.Warn("Failed to create database connection manager, attempting fallback connection", map[string]interface{}{"error": err.Error()})
 
 		// Fallback to environment variables
 		mongoURI := getEnvWithDefault("MONGO_URI", "")
@@ -335,9 +539,9 @@ func main() {
 
 		db, err := database.InitDatabase(mongoURI, dbName)
 		if err != nil {
-			logger.Fatalf("Failed to connect to MongoDB with fallback: %v", err)
+			logger.Fatal("Failed to connect to MongoDB with fallback", map[string]interface{}{"error": err.Error()})
 		}
-		logger.Println("✅ Connected to MongoDB using fallback credentials")
+		logger.Info("Connected to MongoDB using fallback credentials")
 
 		// Continue with the rest of the initialization using fallback
 		initializeServiceWithFallback(db, logger)
@@ -348,9 +552,9 @@ func main() {
 	// Connect to database using environment variables credentials
 	db, err := connectionManager.ConnectWithFallback()
 	if err != nil {
-		logger.Fatalf("Failed to connect to MongoDB: %v", err)
+		logger.Fatal("Failed to connect to MongoDB", map[string]interface{}{"error": err.Error()})
 	}
-	logger.Println("✅ Connected to MongoDB")
+	logger.Info("Connected to MongoDB")
 
 	// Get secrets manager for other credentials
 	secretsManager := connectionManager.GetSecretsManager()
@@ -358,8 +562,7 @@ func main() {
 	// Initialize Redis connection using environment variables
 	redisHost, redisPassword, err := secretsManager.GetRedisCredentials()
 	if err != nil {
-		logger.Printf("⚠️ Failed to get Redis credentials from environment variables: %v", err)
-		logger.Println("🔄 Using fallback Redis credentials...")
+		logger.Warn("Failed to get Redis credentials from environment variables, using fallback", map[string]interface{}{"error": err.Error()})
 		redisHost = getEnvWithDefault("REDIS_ADDR", "localhost:6379")
 		redisPassword = getEnvWithDefault("REDIS_PASSWORD", "password")
 	}
@@ -375,45 +578,68 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := redisClient.Ping(ctx).Err(); err != nil {
-		logger.Fatalf("Failed to connect to Redis: %v", err)
+		logger.Fatal("Failed to connect to Redis", map[string]interface{}{"error": err.Error()})
 	}
-	logger.Println("✅ Connected to Redis")
+	logger.ConnectionInfo("Connected to Redis", "redis", redisHost)
 
-	// Initialize Gmail service using environment variables
-	logger.Println("🔐 Attempting to get email credentials from environment variables...")
-	gmailService, err := NewGmailServiceFromEnv(secretsManager, logger)
+	// Initialize the SMTP service using environment variables
+	emailService, err := NewSMTPServiceFromEnv(secretsManager, logger)
 	if err != nil {
-		logger.Printf("⚠️ Failed to create Gmail service from environment variables: %v", err)
-		logger.Println("🔄 Attempting to use environment variables for email credentials...")
+		logger.Warn("Failed to create SMTP service from secrets, falling back to Gmail with GMAIL_EMAIL and GMAIL_PASSWORD", map[string]interface{}{"error": err.Error()})
 
 		email := os.Getenv("GMAIL_EMAIL")
 		password := os.Getenv("GMAIL_PASSWORD")
 
 		if email == "" || password == "" {
-			logger.Fatalf("❌ Failed to get email credentials from environment variables and no GMAIL_EMAIL/GMAIL_PASSWORD environment variables set")
+			logger.Fatal("No email credentials: set them in the secrets store or in GMAIL_EMAIL and GMAIL_PASSWORD")
 		}
 
-		gmailService = NewGmailService(email, password, "Tennis Court Alerts", logger)
-		logger.Println("✅ Using email credentials from environment variables")
+		emailService = NewGmailService(email, password, alertFromName, logger)
+		logger.Info("Using email credentials from GMAIL_EMAIL and GMAIL_PASSWORD")
 	} else {
-		logger.Println("✅ Successfully retrieved email credentials from environment variables")
+		logger.Info("Using email credentials from the secrets store")
 	}
 
+	// Retry transient SMTP failures and keep undeliverable emails for inspection
+	configureEmailRetries(emailService, redisClient, logger)
+	configureEmailRateLimit(emailService, logger)
+
+	// Initialize optional Twilio SMS service
+	twilioService := newTwilioServiceWithFallback(secretsManager, logger)
+
 	// Create notification service
-	service := &NotificationService{
-		db:          db,
-		redisClient: redisClient,
-		logger:      logger,
+	service := NewNotificationService(db, redisClient, logger)
+	service.registerChannel(ChannelEmail, emailService)
+	if twilioService != nil {
+		service.registerChannel(ChannelSMS, twilioService)
 	}
+	service.registerChannel(ChannelWebhook, NewWebhookService(logger))
+	configureUnsubscribeLinks(service, secretsManager, logger)
 
 	// Load users
 	if err := service.loadUsers(); err != nil {
-		logger.Fatalf("Failed to load users: %v", err)
+		logger.Fatal("Failed to load users", map[string]interface{}{"error": err.Error()})
+	}
+
+	// Replay mode: reprocess the dead-letter queue and exit
+	if isReplayDLQMode() {
+		service.runReplayDLQ()
+		redisClient.Close()
+		return
 	}
 
 	// Start periodic preference reload
 	service.startPeriodicPreferenceReload()
 
+	// Start periodic deduplication record cleanup
+	service.startDedupCleanup()
+
+	// Start daily digest scheduler
+	digestScheduler := service.startDigestScheduler()
+
+	// Expose Prometheus metrics
+	metricsServer := service.startMetricsServer()
+
 	// Log service status
 	service.logServiceStatus()
 
@@ -422,21 +648,29 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	// Start notification engine in a goroutine
-	go func() {
-		service.startNotificationEngine(gmailService)
-	}()
+	service.runNotificationEngine()
+
+	// Send the sample alerts users ask for to check their addresses
+	service.runTestNotificationWorker()
 
 	// Wait for shutdown signal
 	<-sigChan
-	logger.Println("🛑 Shutdown signal received, stopping notification service...")
+	logger.ShutdownInfo("Stopping notification service", "signal_received")
+
+	// Send anything still waiting in a batch before closing connections
+	service.shutdown()
 
 	// Cleanup
+	if digestScheduler != nil {
+		digestScheduler.Stop()
+	}
+	service.stopMetricsServer(metricsServer)
 	redisClient.Close()
-	logger.Println("✅ Notification service stopped gracefully")
+	logger.Info("Notification service stopped gracefully")
 }
 
 // initializeServiceWithFallback initializes the service using fallback credentials
-func initializeServiceWithFallback(db *mongo.Database, logger *log.Logger) {
+func initializeServiceWithFallback(db *mongo.Database, logger *logging.Logger) {
 	// Load environment variables again to ensure they're available
 	godotenv.Load("../../.env")
 
@@ -455,32 +689,61 @@ func initializeServiceWithFallback(db *mongo.Database, logger *log.Logger) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := redisClient.Ping(ctx).Err(); err != nil {
-		logger.Fatalf("Failed to connect to Redis: %v", err)
+		logger.Fatal("Failed to connect to Redis", map[string]interface{}{"error": err.Error()})
 	}
-	logger.Println("✅ Connected to Redis")
+	logger.ConnectionInfo("Connected to Redis", "redis", redisAddr)
 
-	// Initialize Gmail service from environment variables
+	// Fallback mode sends through Gmail with credentials from environment variables
 	email := os.Getenv("GMAIL_EMAIL")
 	password := os.Getenv("GMAIL_PASSWORD")
 
 	if email == "" || password == "" {
-		logger.Fatalf("❌ GMAIL_EMAIL and GMAIL_PASSWORD environment variables are required for fallback mode")
+		logger.Fatal("GMAIL_EMAIL and GMAIL_PASSWORD environment variables are required for fallback mode")
 	}
 
-	gmailService := NewGmailService(email, password, "Tennis Court Alerts", logger)
-	logger.Println("✅ Using email credentials from environment variables")
+	emailService := NewGmailService(email, password, alertFromName, logger)
+	logger.Info("Using email credentials from GMAIL_EMAIL and GMAIL_PASSWORD")
+
+	// Retry transient SMTP failures and keep undeliverable emails for inspection
+	configureEmailRetries(emailService, redisClient, logger)
+	configureEmailRateLimit(emailService, logger)
+
+	// Initialize optional Twilio SMS service
+	twilioService := newTwilioServiceWithFallback(nil, logger)
 
 	// Create notification service using the proper constructor
 	service := NewNotificationService(db, redisClient, logger)
+	service.registerChannel(ChannelEmail, emailService)
+	if twilioService != nil {
+		service.registerChannel(ChannelSMS, twilioService)
+	}
+	service.registerChannel(ChannelWebhook, NewWebhookService(logger))
+	configureUnsubscribeLinks(service, nil, logger)
 
 	// Load users
 	if err := service.loadUsers(); err != nil {
-		logger.Fatalf("Failed to load users: %v", err)
+		logger.Fatal("Failed to load users", map[string]interface{}{"error": err.Error()})
+	}
+
+	// Replay mode: reprocess the dead-letter queue and exit
+	if isReplayDLQMode() {
+		service.runReplayDLQ()
+		redisClient.Close()
+		return
 	}
 
 	// Start periodic preference reload
 	service.startPeriodicPreferenceReload()
 
+	// Start periodic deduplication record cleanup
+	service.startDedupCleanup()
+
+	// Start daily digest scheduler
+	digestScheduler := service.startDigestScheduler()
+
+	// Expose Prometheus metrics
+	metricsServer := service.startMetricsServer()
+
 	// Log service status
 	service.logServiceStatus()
 
@@ -489,48 +752,107 @@ func initializeServiceWithFallback(db *mongo.Database, logger *log.Logger) {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	// Start notification engine in a goroutine
-	go func() {
-		service.startNotificationEngine(gmailService)
-	}()
+	service.runNotificationEngine()
+
+	// Send the sample alerts users ask for to check their addresses
+	service.runTestNotificationWorker()
 
 	// Wait for shutdown signal
 	<-sigChan
-	logger.Println("🛑 Shutdown signal received, stopping notification service...")
+	logger.ShutdownInfo("Stopping notification service", "signal_received")
+
+	// Send anything still waiting in a batch before closing connections
+	service.shutdown()
 
 	// Cleanup
+	if digestScheduler != nil {
+		digestScheduler.Stop()
+	}
+	service.stopMetricsServer(metricsServer)
 	redisClient.Close()
-	logger.Println("✅ Notification service stopped gracefully")
+	logger.Info("Notification service stopped gracefully")
 }
 
 // startPeriodicPreferenceReload starts a goroutine that reloads user preferences every 5 minutes
 func (s *NotificationService) startPeriodicPreferenceReload() {
 	ticker := time.NewTicker(5 * time.Minute)
-	s.logger.Println("🔄 Starting periodic preference reload (every 5 minutes)...")
+	s.logger.Info("Starting periodic preference reload", map[string]interface{}{"interval": "5m"})
 
 	go func() {
 		for range ticker.C {
-			s.logger.Println("🔄 Reloading user preferences...")
+			s.logger.Debug("Reloading user preferences")
 			if err := s.loadUsers(); err != nil {
-				s.logger.Printf("❌ Failed to reload user preferences: %v", err)
+				s.logger.Error("Failed to reload user preferences", map[string]interface{}{"error": err.Error()})
 				// Don't exit - keep trying on next tick
 			} else {
 				s.usersMutex.RLock()
 				userCount := len(s.users)
 				s.usersMutex.RUnlock()
-				s.logger.Printf("✅ Successfully reloaded preferences for %d users", userCount)
+				s.logger.Info("Reloaded user preferences", map[string]interface{}{"users": userCount})
 			}
 		}
 	}()
 }
 
+// userPreferencesDoc is the part of a user_preferences document the notification service reads
+type userPreferencesDoc struct {
+	ID     primitive.ObjectID `bson:"_id"`
+	UserID primitive.ObjectID `bson:"user_id"`
+	Times  []struct {
+		Start string `bson:"start"`
+		End   string `bson:"end"`
+	} `bson:"times"`
+	WeekdayTimes []struct {
+		Start string `bson:"start"`
+		End   string `bson:"end"`
+	} `bson:"weekday_times"`
+	WeekendTimes []struct {
+		Start string `bson:"start"`
+		End   string `bson:"end"`
+	} `bson:"weekend_times"`
+	MaxPrice             float64  `bson:"max_price"`
+	TargetPrice          float64  `bson:"target_price"`
+	PreferredVenues      []string `bson:"preferred_venues"`
+	ExcludedVenues       []string `bson:"excluded_venues"`
+	PreferredDays        []string `bson:"preferred_days"`
+	NotificationSettings struct {
+		Email                         bool       `bson:"email"`
+		EmailAddress                  string     `bson:"email_address"`
+		SMS                           bool       `bson:"sms"`
+		PhoneNumber                   string     `bson:"phone_number"`
+		DeliveryMode                  string     `bson:"delivery_mode"`
+		DigestSendHour                *int       `bson:"digest_send_hour"`
+		WebhookURL                    string     `bson:"webhook_url"`
+		WebhookSecret                 string     `bson:"webhook_secret"`
+		AlertTimeWindowStart          string     `bson:"alert_time_window_start"`
+		AlertTimeWindowEnd            string     `bson:"alert_time_window_end"`
+		VenueCooldownMinutes          int        `bson:"venue_cooldown_minutes"`
+		MaxAlertsPerHour              int        `bson:"max_alerts_per_hour"`
+		MaxAlertsPerDay               int        `bson:"max_alerts_per_day"`
+		BufferLimitedAlerts           bool       `bson:"buffer_limited_alerts"`
+		ExactDuplicateWindowMinutes   int        `bson:"exact_duplicate_window_minutes"`
+		SimilarDuplicateWindowMinutes int        `bson:"similar_duplicate_window_minutes"`
+		SnoozeUntil                   *time.Time `bson:"snooze_until"`
+		Unsubscribed                  bool       `bson:"unsubscribed"`
+	} `bson:"notification_settings"`
+	DisplaySettings struct {
+		Timezone string `bson:"timezone"`
+		Language string `bson:"language"`
+	} `bson:"display_settings"`
+}
+
 // loadUsers loads user preferences from MongoDB
 func (s *NotificationService) loadUsers() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Query user_preferences collection for users with notifications enabled
+	// Query user_preferences collection for users with at least one channel enabled
 	filter := bson.M{
-		"notification_settings.email":        true,
+		"$or": []bson.M{
+			{"notification_settings.email": true},
+			{"notification_settings.sms": true},
+			{"notification_settings.webhook_url": bson.M{"$exists": true, "$ne": ""}},
+		},
 		"notification_settings.unsubscribed": bson.M{"$ne": true},
 	}
 
@@ -541,28 +863,7 @@ func (s *NotificationService) loadUsers() error {
 	defer cursor.Close(ctx)
 
 	// Load user preferences and convert to User struct
-	var userPrefs []struct {
-		ID     primitive.ObjectID `bson:"_id"`
-		UserID primitive.ObjectID `bson:"user_id"`
-		Times  []struct {
-			Start string `bson:"start"`
-			End   string `bson:"end"`
-		} `bson:"times"`
-		WeekdayTimes []struct {
-			Start string `bson:"start"`
-			End   string `bson:"end"`
-		} `bson:"weekday_times"`
-		WeekendTimes []struct {
-			Start string `bson:"start"`
-			End   string `bson:"end"`
-		} `bson:"weekend_times"`
-		MaxPrice             float64  `bson:"max_price"`
-		PreferredVenues      []string `bson:"preferred_venues"`
-		NotificationSettings struct {
-			Email        bool   `bson:"email"`
-			EmailAddress string `bson:"email_address"`
-		} `bson:"notification_settings"`
-	}
+	var userPrefs []userPreferencesDoc
 
 	if err := cursor.All(ctx, &userPrefs); err != nil {
 		return err
@@ -571,97 +872,189 @@ func (s *NotificationService) loadUsers() error {
 	// Convert to User structs and get user details
 	newUsers := []User{}
 	for _, pref := range userPrefs {
-		// Get user details from users collection
-		var userDoc struct {
-			Email string `bson:"email"`
-			Name  string `bson:"name"`
-		}
-
-		userFilter := bson.M{"_id": pref.UserID}
-		err := s.db.Collection("users").FindOne(ctx, userFilter).Decode(&userDoc)
+		user, err := s.userFromPreferences(ctx, pref)
 		if err != nil {
-			s.logger.Printf("⚠️ Failed to load user details for user_id %s: %v", pref.UserID.Hex(), err)
+			s.logger.Warn("Failed to load user details", map[string]interface{}{"user_id": pref.UserID.Hex(), "error": err.Error()})
 			continue
 		}
-
-		// Convert time preferences to the expected format
-		var weekdaySlots, weekendSlots []TimeSlot
-
-		// Use new weekday/weekend specific times if available
-		if len(pref.WeekdayTimes) > 0 || len(pref.WeekendTimes) > 0 {
-			// Use the new separate weekday/weekend times
-			for _, timeRange := range pref.WeekdayTimes {
-				weekdaySlots = append(weekdaySlots, TimeSlot{
-					Start: timeRange.Start,
-					End:   timeRange.End,
-				})
-			}
-			for _, timeRange := range pref.WeekendTimes {
-				weekendSlots = append(weekendSlots, TimeSlot{
-					Start: timeRange.Start,
-					End:   timeRange.End,
-				})
-			}
-		} else if len(pref.Times) > 0 {
-			// Fallback to legacy times field (treat as both weekday and weekend)
-			for _, timeRange := range pref.Times {
-				slot := TimeSlot{
-					Start: timeRange.Start,
-					End:   timeRange.End,
-				}
-				weekdaySlots = append(weekdaySlots, slot)
-				weekendSlots = append(weekendSlots, slot)
-			}
-		}
-
-		user := User{
-			ID:              pref.UserID,
-			Email:           userDoc.Email,
-			Name:            userDoc.Name,
-			PreferredVenues: pref.PreferredVenues,
-			TimePreferences: TimePreferences{
-				WeekdaySlots: weekdaySlots,
-				WeekendSlots: weekendSlots,
-			},
-			MaxPrice:            pref.MaxPrice,
-			NotificationEnabled: true, // We already filtered for this
-		}
-
-		// Use email from notification settings if available, otherwise from user doc
-		if pref.NotificationSettings.EmailAddress != "" {
-			user.Email = pref.NotificationSettings.EmailAddress
-		}
-
 		newUsers = append(newUsers, user)
 	}
 
+	// Load venue timezones so slot times can be converted into each user's zone,
+	// and booking windows so slots that can't be booked yet are ignored
+	venueTimezones, bookingWindows, err := s.loadVenueSettings(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to load venue settings, assuming the default timezone and no booking windows", map[string]interface{}{"timezone": defaultTimezone, "error": err.Error()})
+	}
+
 	// Atomically replace the users slice
 	s.usersMutex.Lock()
 	s.users = newUsers
+	if venueTimezones != nil {
+		s.venueTimezones = venueTimezones
+		s.bookingWindows = bookingWindows
+	}
 	s.usersMutex.Unlock()
 
-	s.logger.Printf("✅ Loaded %d users with notifications enabled", len(newUsers))
+	s.logger.Info("Loaded users with notifications enabled", map[string]interface{}{"users": len(newUsers)})
 	return nil
 }
 
+// loadUser loads one user's current preferences, whether or not they have a channel enabled
+func (s *NotificationService) loadUser(ctx context.Context, userID primitive.ObjectID) (User, error) {
+	var pref userPreferencesDoc
+	if err := s.db.Collection("user_preferences").FindOne(ctx, bson.M{"user_id": userID}).Decode(&pref); err != nil {
+		return User{}, err
+	}
+	return s.userFromPreferences(ctx, pref)
+}
+
+// userFromPreferences builds a User from their preferences and account details
+func (s *NotificationService) userFromPreferences(ctx context.Context, pref userPreferencesDoc) (User, error) {
+	// Get user details from users collection
+	var userDoc struct {
+		Email string `bson:"email"`
+		Name  string `bson:"name"`
+	}
+	if err := s.db.Collection("users").FindOne(ctx, bson.M{"_id": pref.UserID}).Decode(&userDoc); err != nil {
+		return User{}, err
+	}
+
+	// Convert time preferences to the expected format
+	var weekdaySlots, weekendSlots []TimeSlot
+
+	// Use new weekday/weekend specific times if available
+	if len(pref.WeekdayTimes) > 0 || len(pref.WeekendTimes) > 0 {
+		// Use the new separate weekday/weekend times
+		for _, timeRange := range pref.WeekdayTimes {
+			weekdaySlots = append(weekdaySlots, TimeSlot{
+				Start: timeRange.Start,
+				End:   timeRange.End,
+			})
+		}
+		for _, timeRange := range pref.WeekendTimes {
+			weekendSlots = append(weekendSlots, TimeSlot{
+				Start: timeRange.Start,
+				End:   timeRange.End,
+			})
+		}
+	} else if len(pref.Times) > 0 {
+		// Fallback to legacy times field (treat as both weekday and weekend)
+		for _, timeRange := range pref.Times {
+			slot := TimeSlot{
+				Start: timeRange.Start,
+				End:   timeRange.End,
+			}
+			weekdaySlots = append(weekdaySlots, slot)
+			weekendSlots = append(weekendSlots, slot)
+		}
+	}
+
+	user := User{
+		ID:              pref.UserID,
+		Email:           userDoc.Email,
+		Name:            userDoc.Name,
+		PreferredVenues: pref.PreferredVenues,
+		ExcludedVenues:  pref.ExcludedVenues,
+		PreferredDays:   pref.PreferredDays,
+		TimePreferences: TimePreferences{
+			WeekdaySlots: weekdaySlots,
+			WeekendSlots: weekendSlots,
+		},
+		MaxPrice:            pref.MaxPrice,
+		TargetPrice:         pref.TargetPrice,
+		NotificationEnabled: !pref.NotificationSettings.Unsubscribed,
+		EmailEnabled:        pref.NotificationSettings.Email,
+		SMSEnabled:          pref.NotificationSettings.SMS && pref.NotificationSettings.PhoneNumber != "",
+		PhoneNumber:         pref.NotificationSettings.PhoneNumber,
+		DeliveryMode:        pref.NotificationSettings.DeliveryMode,
+		DigestSendHour:      models.DefaultDigestSendHour,
+		Timezone:            pref.DisplaySettings.Timezone,
+		Language:            pref.DisplaySettings.Language,
+		WebhookURL:          pref.NotificationSettings.WebhookURL,
+		WebhookSecret:       pref.NotificationSettings.WebhookSecret,
+		AlertWindowStart:    pref.NotificationSettings.AlertTimeWindowStart,
+		AlertWindowEnd:      pref.NotificationSettings.AlertTimeWindowEnd,
+		SnoozeUntil:         pref.NotificationSettings.SnoozeUntil,
+		MaxAlertsPerHour:    pref.NotificationSettings.MaxAlertsPerHour,
+		MaxAlertsPerDay:     pref.NotificationSettings.MaxAlertsPerDay,
+		BufferLimitedAlerts: pref.NotificationSettings.BufferLimitedAlerts,
+	}
+
+	if minutes := pref.NotificationSettings.VenueCooldownMinutes; minutes > 0 {
+		user.VenueCooldown = time.Duration(minutes) * time.Minute
+	}
+
+	user.DedupWindows = models.NotificationSettings{
+		ExactDuplicateWindowMinutes:   pref.NotificationSettings.ExactDuplicateWindowMinutes,
+		SimilarDuplicateWindowMinutes: pref.NotificationSettings.SimilarDuplicateWindowMinutes,
+	}.DeduplicationWindows()
+
+	if user.DeliveryMode == "" {
+		user.DeliveryMode = models.DeliveryModeInstant
+	}
+	if hour := pref.NotificationSettings.DigestSendHour; hour != nil && *hour >= 0 && *hour <= 23 {
+		user.DigestSendHour = *hour
+	}
+
+	// Use email from notification settings if available, otherwise from user doc
+	if pref.NotificationSettings.EmailAddress != "" {
+		user.Email = pref.NotificationSettings.EmailAddress
+	}
+
+	return user, nil
+}
+
+// loadVenueSettings builds lookups of venue ID and name to the venue's timezone and booking window
+func (s *NotificationService) loadVenueSettings(ctx context.Context) (map[string]string, map[string]int, error) {
+	venues, err := s.venueCache.GetVenuesCached(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	timezones := make(map[string]string, len(venues)*2)
+	bookingWindows := make(map[string]int, len(venues)*2)
+	for _, venue := range venues {
+		if venue.Timezone != "" {
+			timezones[venue.ID.Hex()] = venue.Timezone
+			timezones[venue.Name] = venue.Timezone
+		}
+		if venue.BookingWindow > 0 {
+			bookingWindows[venue.ID.Hex()] = venue.BookingWindow
+			bookingWindows[venue.Name] = venue.BookingWindow
+		}
+	}
+
+	return timezones, bookingWindows, nil
+}
+
 // startNotificationEngine starts listening for Redis notifications with batching
-func (s *NotificationService) startNotificationEngine(gmailService *GmailService) {
-	s.logger.Println("🔔 Starting notification engine - listening for court slots...")
-	s.slotBatch = make(map[string][]SlotData)
+func (s *NotificationService) startNotificationEngine() {
+	s.logger.Info("Starting notification engine", map[string]interface{}{"queue": slotQueue, "batch_window": s.batchWindow().String()})
+	s.createSlotStateIndexes()
+
+	s.startQueueReaper()
 
-	for {
-		// Block and wait for messages from Redis queue
-		result, err := s.redisClient.BRPop(context.Background(), 0, "court_slots").Result()
+	for !s.shuttingDown.Load() {
+		// Block and wait for messages from Redis queue, waking periodically to check for shutdown
+		payload, err := s.queue.Claim(context.Background(), enginePollTimeout)
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
 		if err != nil {
-			s.logger.Printf("Error reading from Redis queue: %v", err)
+			s.logger.Error("Failed to read from Redis queue", map[string]interface{}{"queue": slotQueue, "error": err.Error()})
 			time.Sleep(5 * time.Second)
 			continue
 		}
 
-		// result[0] is the queue name, result[1] is the data
-		if len(result) > 1 {
-			s.processSlotMessage(result[1])
+		s.processSlotMessage(payload)
+
+		// Until acknowledged the message stays in this worker's processing list for the reaper
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := s.queue.Ack(ctx, payload); err != nil {
+			s.logger.Error("Failed to acknowledge slot message", map[string]interface{}{"queue": slotQueue, "error": err.Error()})
 		}
+		cancel()
 	}
 }
 
@@ -672,7 +1065,7 @@ func (s *NotificationService) addSlotToBatch(user User, slot SlotData) {
 
 	// Deduplication is now handled in processSlotMessage, so this is redundant
 
-	s.logger.Printf("Slot matches preferences for user: %s", user.Email)
+	s.logger.Debug("Slot matches preferences", map[string]interface{}{"user_email": user.Email, "venue": slot.VenueName, "slot_key": slotKey(slot)})
 
 	// Add to batch
 	if s.slotBatch[user.Email] == nil {
@@ -680,201 +1073,281 @@ func (s *NotificationService) addSlotToBatch(user User, slot SlotData) {
 	}
 	s.slotBatch[user.Email] = append(s.slotBatch[user.Email], slot)
 
-	// Reset/start the batch timer (10 seconds)
-	if s.batchTimer != nil {
-		s.batchTimer.Stop()
+	// Start this user's batch timer on their first slot. Each user has their own
+	// timer so a steady stream of slots for one user can't hold back anyone else.
+	// During the user's quiet hours the timer runs until their alert window opens.
+	if s.batchTimers == nil {
+		s.batchTimers = make(map[string]batchTimer)
+	}
+	if _, pending := s.batchTimers[user.Email]; !pending {
+		userEmail := user.Email
+		s.batchTimers[userEmail] = s.afterBatchDelay(s.batchDelay(user, time.Now()), func() {
+			s.flushBatchedNotifications(userEmail)
+		})
 	}
-	s.batchTimer = time.AfterFunc(10*time.Second, func() {
-		s.flushBatchedNotifications()
-	})
 }
 
-// flushBatchedNotifications processes all batched notifications
-func (s *NotificationService) flushBatchedNotifications() {
-	s.batchMutex.Lock()
-	currentBatch := s.slotBatch
-	s.slotBatch = make(map[string][]SlotData) // Reset batch
-	s.batchMutex.Unlock()
+// batchTimer is the part of *time.Timer a pending batch needs
+type batchTimer interface {
+	Stop() bool
+}
 
-	// Create Gmail service
-	email := os.Getenv("GMAIL_EMAIL")
-	password := os.Getenv("GMAIL_PASSWORD")
-	gmailService := NewGmailService(email, password, "Tennis Court Alerts", s.logger)
+// batchTimerFunc calls f after d, like time.AfterFunc
+type batchTimerFunc func(d time.Duration, f func()) batchTimer
 
-	// Send notifications for each user's batch
-	for userEmail, slots := range currentBatch {
-		if len(slots) > 0 {
-			// Find user by email
-			s.usersMutex.RLock()
-			var user User
-			for _, u := range s.users {
-				if u.Email == userEmail {
-					user = u
-					break
-				}
-			}
-			s.usersMutex.RUnlock()
+// afterBatchDelay calls f after d using startBatchTimer, or time.AfterFunc when it isn't set
+func (s *NotificationService) afterBatchDelay(d time.Duration, f func()) batchTimer {
+	if s.startBatchTimer != nil {
+		return s.startBatchTimer(d, f)
+	}
+	return time.AfterFunc(d, f)
+}
 
-			// Send consolidated notification
-			if err := s.sendBatchedNotification(user, slots, gmailService); err != nil {
-				s.logger.Printf("Error sending batched notification to %s: %v", userEmail, err)
-			}
-		}
+// batchWindow returns the configured batch window, defaulting to 10 seconds
+func (s *NotificationService) batchWindow() time.Duration {
+	if s.BatchWindow <= 0 {
+		return defaultBatchWindow
 	}
+	return s.BatchWindow
 }
 
-// Removed duplicate function - using the complete implementation below
+// flushBatchedNotifications sends the batches for the given users, or for every
+// user with pending slots when called without arguments
+func (s *NotificationService) flushBatchedNotifications(userEmails ...string) {
+	s.batchMutex.Lock()
+	if len(userEmails) == 0 {
+		for userEmail := range s.slotBatch {
+			userEmails = append(userEmails, userEmail)
+		}
+	}
 
-// shouldNotifyUser checks if a user should be notified about a slot using the existing retention service logic
-func (s *NotificationService) shouldNotifyUser(user User, slot SlotData) bool {
-	// Check venue preference
-	venueMatch := false
-	for _, venue := range user.PreferredVenues {
-		if venue == slot.VenueName {
-			venueMatch = true
-			break
+	currentBatch := make(map[string][]SlotData, len(userEmails))
+	for _, userEmail := range userEmails {
+		if slots, ok := s.slotBatch[userEmail]; ok {
+			currentBatch[userEmail] = slots
+			delete(s.slotBatch, userEmail)
+		}
+		if timer, ok := s.batchTimers[userEmail]; ok {
+			timer.Stop()
+			delete(s.batchTimers, userEmail)
 		}
 	}
-	if !venueMatch {
-		return false
+	// Registered under the lock so Shutdown, which flushes before waiting, sees every send
+	if len(currentBatch) > 0 {
+		s.sendWG.Add(1)
+		defer s.sendWG.Done()
 	}
+	s.batchMutex.Unlock()
 
-	// Check price
-	if slot.Price > user.MaxPrice {
-		return false
+	// Send notifications for each user's batch, over one email connection when there are several
+	if len(currentBatch) > 1 {
+		defer s.beginEmailBatch()()
+	}
+	for userEmail, slots := range currentBatch {
+		s.sendUserBatch(userEmail, slots)
 	}
-
-	// Check time preferences
-	return s.matchesTimePreferences(user.TimePreferences, slot)
 }
 
-// matchesTimePreferences checks if slot time matches user preferences
-func (s *NotificationService) matchesTimePreferences(prefs TimePreferences, slot SlotData) bool {
-	// Parse slot date to determine if it's a weekend
-	slotTime, err := time.Parse("2006-01-02", slot.Date)
-	if err != nil {
-		s.logger.Printf("Error parsing slot date: %v", err)
-		return false
+// sendUserBatch sends a consolidated notification for one user's batched slots
+func (s *NotificationService) sendUserBatch(userEmail string, slots []SlotData) {
+	if len(slots) == 0 {
+		return
 	}
 
-	var relevantSlots []TimeSlot
-	if slotTime.Weekday() == time.Saturday || slotTime.Weekday() == time.Sunday {
-		relevantSlots = prefs.WeekendSlots
-	} else {
-		relevantSlots = prefs.WeekdaySlots
+	// Find user by email
+	s.usersMutex.RLock()
+	var user User
+	for _, u := range s.users {
+		if u.Email == userEmail {
+			user = u
+			break
+		}
 	}
+	s.usersMutex.RUnlock()
 
-	// Check if slot time falls within any preferred time slot
-	for _, timeSlot := range relevantSlots {
-		if s.timeInRange(slot.StartTime, timeSlot.Start, timeSlot.End) {
-			return true
-		}
+	if resetAt := s.alertLimitReset(user, time.Now()); !resetAt.IsZero() {
+		s.limitAlerts(user, slots, resetAt)
+		return
+	}
+
+	// Send consolidated notification
+	if err := s.sendBatchedNotification(user, slots); err != nil {
+		s.logger.Error("Failed to send batched notification", map[string]interface{}{"user_email": userEmail, "slots": len(slots), "error": err.Error()})
+		return
 	}
 
-	return false
+	s.countAlertSent(user, time.Now())
+	s.startVenueCooldowns(user, slots)
 }
 
-// timeInRange checks if a time falls within a range
-func (s *NotificationService) timeInRange(timeStr, start, end string) bool {
-	slotTime, err := time.Parse("15:04", timeStr)
-	if err != nil {
-		return false
-	}
+// sendNotification sends a single-slot alert on every channel the user has enabled,
+// the same way a batch is delivered
+func (s *NotificationService) sendNotification(user User, slot SlotData) error {
+	return s.sendBatchedNotification(user, []SlotData{slot})
+}
 
-	startTime, err := time.Parse("15:04", start)
-	if err != nil {
-		return false
+// sendBatchedNotification sends a consolidated alert for multiple slots on every channel the user has enabled
+func (s *NotificationService) sendBatchedNotification(user User, slots []SlotData) error {
+	if len(slots) == 0 {
+		return nil
 	}
 
-	endTime, err := time.Parse("15:04", end)
-	if err != nil {
-		return false
+	// Most urgent (or cheapest) slots first, so every channel shows them at the top
+	slots = sortSlotsForAlert(slots, s.SlotOrder)
+
+	var errs []error
+	if user.EmailEnabled {
+		if channel, ok := s.channels[ChannelEmail]; ok {
+			err := s.deliverOnChannel(user, ChannelEmail, user.Email, slots, func() error {
+				return s.sendEmailAlert(channel, user, slots)
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("email: %w", err))
+			}
+		}
+	}
+	if user.SMSEnabled {
+		if channel, ok := s.channels[ChannelSMS]; ok {
+			err := s.deliverOnChannel(user, ChannelSMS, user.PhoneNumber, slots, func() error {
+				return sendSMSAlert(channel, user, slots)
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("sms: %w", err))
+			}
+		}
+	}
+	if user.WebhookURL != "" {
+		if channel, ok := s.channels[ChannelWebhook]; ok {
+			err := s.deliverOnChannel(user, ChannelWebhook, user.WebhookURL, slots, func() error {
+				return sendWebhookAlert(channel, user, slots)
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("webhook: %w", err))
+			}
+		}
 	}
 
-	return (slotTime.After(startTime) || slotTime.Equal(startTime)) && slotTime.Before(endTime)
+	return errors.Join(errs...)
 }
 
-// isDuplicateNotification checks if this notification was already sent
-// isDuplicateNotification is now replaced by the consolidated deduplication service
+// sendEmailAlert emails the slots to the user, as HTML when the channel supports it.
+// The first slot's booking URL is the primary link.
+func (s *NotificationService) sendEmailAlert(channel NotificationChannel, user User, slots []SlotData) error {
+	// Show dates and times in the user's timezone; history keeps the venue's
+	displaySlots := s.slotsForDisplay(user, slots)
+	courtDetails := formatBatchedEmailDetails(displaySlots, userLocale(user))
 
-// sendNotification sends an email notification
-func (s *NotificationService) sendNotification(user User, slot SlotData, gmailService *GmailService) error {
-	courtDetails := fmt.Sprintf(`Venue: %s
-Court: %s
-Date: %s
-Time: %s--%s
-Price: £%.2f`,
-		slot.VenueName,
-		slot.CourtName,
-		slot.Date,
-		slot.StartTime,
-		slot.EndTime,
-		slot.Price)
+	if htmlChannel, ok := channel.(htmlAlertSender); ok {
+		return htmlChannel.SendCourtAvailabilityAlertHTML(user.Email, courtDetails, displaySlots, slots[0].BookingURL, s.unsubscribeURL(user), user.Language)
+	}
+	return channel.Send(user.Email, courtDetails, slots[0].BookingURL)
+}
 
-	return gmailService.SendCourtAvailabilityAlert(user.Email, courtDetails, slot.BookingURL)
+// sendSMSAlert texts a summary of the slots to the user's phone
+func sendSMSAlert(channel NotificationChannel, user User, slots []SlotData) error {
+	return channel.Send(user.PhoneNumber, formatBatchedSMSDetails(slots), slots[0].BookingURL)
 }
 
-// sendBatchedNotification sends a consolidated email for multiple slots
-func (s *NotificationService) sendBatchedNotification(user User, slots []SlotData, gmailService *GmailService) error {
-	if len(slots) == 0 {
-		return nil
+// sendWebhookAlert posts the slots to the user's webhook
+func sendWebhookAlert(channel NotificationChannel, user User, slots []SlotData) error {
+	if slotChannel, ok := channel.(slotAlertSender); ok {
+		return slotChannel.SendSlotAlert(user, slots)
 	}
+	return channel.Send(user.WebhookURL, formatBatchedEmailDetails(slots, localeFor(LanguageEnglish)), slots[0].BookingURL)
+}
 
-	// Group slots by venue and date for better organization
-	venueGroups := make(map[string]map[string][]SlotData)
-	for _, slot := range slots {
-		if venueGroups[slot.VenueName] == nil {
-			venueGroups[slot.VenueName] = make(map[string][]SlotData)
-		}
-		if venueGroups[slot.VenueName][slot.Date] == nil {
-			venueGroups[slot.VenueName][slot.Date] = make([]SlotData, 0)
-		}
-		venueGroups[slot.VenueName][slot.Date] = append(venueGroups[slot.VenueName][slot.Date], slot)
-	}
+// deliverOnChannel runs a channel delivery, recording its latency in metrics and
+// its outcome in the user's alert history
+func (s *NotificationService) deliverOnChannel(user User, channel, address string, slots []SlotData, send func() error) error {
+	start := time.Now()
+	err := send()
+	s.metrics.observeSend(channel, time.Since(start), err)
+	s.recordAlertHistory(user, channel, address, slots, err)
+	return err
+}
+
+// formatBatchedEmailDetails builds the email body for a batch of slots in the locale's language
+func formatBatchedEmailDetails(slots []SlotData, locale *alertLocale) string {
+	// Group slots by venue and date, keeping the order the slots were sorted in
+	venueGroups := groupSlotsByVenueAndDate(slots)
 
 	// Build consolidated details
 	var courtDetails strings.Builder
-	slotCount := len(slots)
-
-	if slotCount == 1 {
-		courtDetails.WriteString("🎾 A tennis court just became available!\n\n")
-	} else {
-		courtDetails.WriteString(fmt.Sprintf("🎾 %d tennis courts just became available!\n\n", slotCount))
-	}
+	courtDetails.WriteString("🎾 " + alertHeadline(slots, locale) + "\n\n")
 
 	// Add booking links section at the top for quick access
-	courtDetails.WriteString("🔗 QUICK BOOKING LINKS:\n")
+	courtDetails.WriteString("🔗 " + locale.QuickLinks + ":\n")
 	for i, slot := range slots {
-		courtDetails.WriteString(fmt.Sprintf("  %d. %s %s %s-%s: %s\n",
-			i+1, slot.VenueName, slot.CourtName, slot.StartTime, slot.EndTime, slot.BookingURL))
+		courtDetails.WriteString(fmt.Sprintf("  %d. %s %s %s: %s\n",
+			i+1, slot.VenueName, slot.CourtName, slotTimeRange(slot), slot.BookingURL))
 	}
-	courtDetails.WriteString("\n📋 COURT DETAILS:\n")
+	courtDetails.WriteString("\n📋 " + locale.CourtDetails + ":\n")
 
 	// Organize by venue and date
-	for venueName, dates := range venueGroups {
-		courtDetails.WriteString(fmt.Sprintf("\n🏟️ %s:\n", venueName))
+	for _, venue := range venueGroups {
+		courtDetails.WriteString(fmt.Sprintf("\n🏟️ %s:\n", venue.Name))
 
-		for date, venueSlots := range dates {
-			courtDetails.WriteString(fmt.Sprintf("  📅 %s:\n", date))
+		for _, date := range venue.Dates {
+			courtDetails.WriteString(fmt.Sprintf("  📅 %s:\n", locale.formatDate(date.Date)))
 
-			for _, slot := range venueSlots {
-				courtDetails.WriteString(fmt.Sprintf("    • %s: %s-%s (£%.2f)\n",
-					slot.CourtName, slot.StartTime, slot.EndTime, slot.Price))
+			for _, slot := range date.Slots {
+				courtDetails.WriteString(fmt.Sprintf("    • %s: %s (%s)",
+					slot.CourtName, slotTimeRange(slot), locale.slotPrice(slot)))
+				if isCancellation(slot) {
+					courtDetails.WriteString(" - " + locale.FreedUpNote)
+				}
+				if isDeal(slot) {
+					courtDetails.WriteString(" - 💰 " + dealNote(slot, locale))
+				}
+				courtDetails.WriteString("\n")
 			}
 		}
 	}
 
-	courtDetails.WriteString("\n⚡ These slots just became available - book quickly!")
+	courtDetails.WriteString("\n⚡ " + locale.Intro)
+
+	return courtDetails.String()
+}
+
+// formatBatchedSMSDetails builds a compact, emoji-free SMS body for a batch of slots
+func formatBatchedSMSDetails(slots []SlotData) string {
+	var details strings.Builder
+
+	if isLimitSummary(slots) {
+		details.WriteString(fmt.Sprintf("Alert limit reached. %d courts you missed:\n", len(slots)))
+	} else if len(slots) == 1 && isDeal(slots[0]) {
+		details.WriteString("Tennis court deal:\n")
+	} else if len(slots) == 1 && isCancellation(slots[0]) {
+		details.WriteString("Tennis court freed up:\n")
+	} else if len(slots) == 1 {
+		details.WriteString("Tennis court available:\n")
+	} else {
+		details.WriteString(fmt.Sprintf("%d tennis courts available:\n", len(slots)))
+	}
 
-	// Use the first slot's booking URL as the primary link (they should all be for the same venue group anyway)
-	primaryBookingURL := slots[0].BookingURL
+	for i, slot := range slots {
+		price := formatPrice(slot.Price, slotCurrency(slot))
+		if isDeal(slot) {
+			price += " DEAL"
+		}
+		line := fmt.Sprintf("%d. %s %s %s %s-%s %s %s\n",
+			i+1, slot.VenueName, slot.CourtName, slot.Date, slot.StartTime, slot.EndTime,
+			price, slot.BookingURL)
 
-	return gmailService.SendCourtAvailabilityAlert(user.Email, courtDetails.String(), primaryBookingURL)
+		// Stop before the carrier limit rather than cutting a slot in half
+		if details.Len()+len(line) >= maxSMSLength-32 {
+			details.WriteString(fmt.Sprintf("+%d more - see the app for details", len(slots)-i))
+			break
+		}
+		details.WriteString(line)
+	}
+
+	return truncateSMS(sanitizeSMSText(details.String()))
 }
 
 // SendTestNotification sends a test notification
-func (s *NotificationService) SendTestNotification(email string, gmailService *GmailService) error {
-	return gmailService.SendTestEmail(email)
+func (s *NotificationService) SendTestNotification(email string, emailService *SMTPService) error {
+	return emailService.SendTestEmail(email)
 }
 
 // logServiceStatus logs the current status of services
@@ -882,22 +1355,62 @@ func (s *NotificationService) logServiceStatus() {
 	s.usersMutex.RLock()
 	defer s.usersMutex.RUnlock()
 
-	s.logger.Println("📊 Service Status:")
-	s.logger.Println("  ✅ Email Service: ENABLED (Gmail SMTP Real)")
-	s.logger.Println("  ✅ Redis Listener: ENABLED")
-	s.logger.Println("  ✅ MongoDB Connection: ENABLED")
-	s.logger.Println("  ✅ Duplicate Prevention: ENABLED")
-	s.logger.Println("  ✅ Periodic Preference Reload: ENABLED (every 5 minutes)")
-	s.logger.Printf("  ✅ Users Loaded: %d", len(s.users))
-
-	if len(s.users) > 0 {
-		user := s.users[0]
-		s.logger.Printf("  📧 Monitoring for: %s", user.Email)
-		s.logger.Printf("  🏟️ Preferred venues: %v", user.PreferredVenues)
-		s.logger.Printf("  ⏰ Weekday slots: %v", user.TimePreferences.WeekdaySlots)
-		s.logger.Printf("  🌅 Weekend slots: %v", user.TimePreferences.WeekendSlots)
-		s.logger.Printf("  💰 Max price: £%.2f", user.MaxPrice)
+	_, smsEnabled := s.channels[ChannelSMS]
+	s.logger.Info("Service status", map[string]interface{}{
+		"email_enabled":       true,
+		"sms_enabled":         smsEnabled,
+		"batch_window":        s.batchWindow().String(),
+		"slot_order":          s.SlotOrder,
+		"preference_reload":   "5m",
+		"digest_schedule":     "hourly",
+		"users":               len(s.users),
+		"slot_parse_failures": s.parseFailures.Load(),
+		"dead_letter_queue":   slotDeadLetterQueue,
+	})
+}
+
+// defaultBatchWindow is how long slots are collected per user before a notification is sent
+const defaultBatchWindow = 10 * time.Second
+
+// batchWindowFromEnv reads NOTIFICATION_BATCH_WINDOW (e.g. "30s", "2m"), defaulting to 10s
+func batchWindowFromEnv(logger *logging.Logger) time.Duration {
+	value := os.Getenv("NOTIFICATION_BATCH_WINDOW")
+	if value == "" {
+		return defaultBatchWindow
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil || window <= 0 {
+		logger.Warn("Invalid NOTIFICATION_BATCH_WINDOW, using the default", map[string]interface{}{"value": value, "default": defaultBatchWindow.String()})
+		return defaultBatchWindow
+	}
+
+	return window
+}
+
+// configureEmailRetries applies the SMTP retry policy from SMTP_MAX_ATTEMPTS and
+// SMTP_RETRY_BASE_DELAY and records final failures in Redis
+func configureEmailRetries(emailService *SMTPService, redisClient *redis.Client, logger *logging.Logger) {
+	maxAttempts := defaultSMTPMaxAttempts
+	if value := os.Getenv("SMTP_MAX_ATTEMPTS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed > 0 {
+			maxAttempts = parsed
+		} else {
+			logger.Warn("Invalid SMTP_MAX_ATTEMPTS, using the default", map[string]interface{}{"value": value, "default": maxAttempts})
+		}
 	}
+
+	baseDelay := defaultSMTPRetryBaseDelay
+	if value := os.Getenv("SMTP_RETRY_BASE_DELAY"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed >= 0 {
+			baseDelay = parsed
+		} else {
+			logger.Warn("Invalid SMTP_RETRY_BASE_DELAY, using the default", map[string]interface{}{"value": value, "default": baseDelay.String()})
+		}
+	}
+
+	emailService.SetRetryPolicy(maxAttempts, baseDelay)
+	emailService.SetFailedNotificationQueue(redisClient)
 }
 
 // getEnvWithDefault returns environment variable value or default if not set
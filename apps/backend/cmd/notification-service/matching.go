@@ -0,0 +1,80 @@
+package main
+
+import (
+	"time"
+
+	"tennis-booker/internal/matching"
+	"tennis-booker/internal/models"
+)
+
+// shouldNotifyUser checks if a user should be notified about a slot, using the
+// matcher the retention service and slot stream share
+func (s *NotificationService) shouldNotifyUser(user User, slot SlotData) bool {
+	if isSnoozed(user, time.Now()) {
+		return false
+	}
+
+	matchingSlot, ok := s.slotForMatching(slot, s.venueLocation(slot), userLocation(user))
+	if !ok {
+		return false
+	}
+
+	matches, reason := matching.Matches(matchingSlot, userMatchingPreferences(user))
+	if !matches {
+		s.logger.Debug("Slot does not match user preferences", map[string]interface{}{"slot_key": slotKey(slot), "user_email": user.Email, "reason": reason})
+	}
+	return matches
+}
+
+// matchesTimePreferences checks if slot time matches user preferences.
+// Slot times are in the venue's timezone; preferences are in the user's timezone.
+func (s *NotificationService) matchesTimePreferences(prefs TimePreferences, slot SlotData, venueLoc, userLoc *time.Location) bool {
+	matchingSlot, ok := s.slotForMatching(slot, venueLoc, userLoc)
+	if !ok {
+		return false
+	}
+
+	matches, _ := matching.Matches(matchingSlot, matching.Preferences{
+		WeekdayTimes: timeRanges(prefs.WeekdaySlots),
+		WeekendTimes: timeRanges(prefs.WeekendSlots),
+	})
+	return matches
+}
+
+// slotForMatching converts the slot for the matcher, with its start moved into the
+// user's zone - which can also move it to a different day
+func (s *NotificationService) slotForMatching(slot SlotData, venueLoc, userLoc *time.Location) (matching.Slot, bool) {
+	start, err := slotStartInZone(slot, venueLoc, userLoc)
+	if err != nil {
+		s.logger.Warn("Failed to parse slot date and time", map[string]interface{}{"slot_key": slotKey(slot), "error": err.Error()})
+		return matching.Slot{}, false
+	}
+
+	return matching.Slot{
+		VenueID: slot.VenueID,
+		Start:   start,
+		Price:   slot.Price,
+	}, true
+}
+
+// userMatchingPreferences returns the filters set on the user. Filters left
+// empty match every slot.
+func userMatchingPreferences(user User) matching.Preferences {
+	return matching.Preferences{
+		PreferredVenues: user.PreferredVenues,
+		ExcludedVenues:  user.ExcludedVenues,
+		PreferredDays:   user.PreferredDays,
+		WeekdayTimes:    timeRanges(user.TimePreferences.WeekdaySlots),
+		WeekendTimes:    timeRanges(user.TimePreferences.WeekendSlots),
+		MaxPrice:        user.MaxPrice,
+	}
+}
+
+// timeRanges converts the user's time slots to the ranges the matcher takes
+func timeRanges(slots []TimeSlot) []models.TimeRange {
+	ranges := make([]models.TimeRange, 0, len(slots))
+	for _, slot := range slots {
+		ranges = append(ranges, models.TimeRange{Start: slot.Start, End: slot.End})
+	}
+	return ranges
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/retention"
+)
+
+func TestShouldNotifyUser_SharedMatcher(t *testing.T) {
+	s := newTestNotificationService()
+	evenings := []TimeSlot{{Start: "18:00", End: "21:00"}}
+
+	// Saturday 18:00 at a London venue, for a London user
+	slot := SlotData{VenueID: "venue123", VenueName: "Victoria Park", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 20}
+
+	tests := []struct {
+		name string
+		user User
+		want bool
+	}{
+		{
+			name: "no preferences",
+			user: User{},
+			want: true,
+		},
+		{
+			name: "venue by ID",
+			user: User{PreferredVenues: []string{"venue123"}},
+			want: true,
+		},
+		{
+			name: "venue by name",
+			user: User{PreferredVenues: []string{"Victoria Park"}},
+			want: false,
+		},
+		{
+			name: "other venue",
+			user: User{PreferredVenues: []string{"venue456"}},
+			want: false,
+		},
+		{
+			name: "excluded venue",
+			user: User{ExcludedVenues: []string{"venue123"}},
+			want: false,
+		},
+		{
+			name: "other venue excluded",
+			user: User{ExcludedVenues: []string{"venue456"}},
+			want: true,
+		},
+		{
+			name: "excluded venue that isn't preferred",
+			user: User{PreferredVenues: []string{"venue456"}, ExcludedVenues: []string{"venue123"}},
+			want: false,
+		},
+		{
+			name: "excluded venue that is also preferred",
+			user: User{PreferredVenues: []string{"venue123"}, ExcludedVenues: []string{"venue123"}},
+			want: false,
+		},
+		{
+			name: "within max price",
+			user: User{MaxPrice: 20},
+			want: true,
+		},
+		{
+			name: "over max price",
+			user: User{MaxPrice: 15},
+			want: false,
+		},
+		{
+			name: "weekend evenings",
+			user: User{TimePreferences: TimePreferences{WeekendSlots: evenings}},
+			want: true,
+		},
+		{
+			name: "weekday evenings only",
+			user: User{TimePreferences: TimePreferences{WeekdaySlots: evenings}},
+			want: false,
+		},
+		{
+			name: "preferred day",
+			user: User{PreferredDays: []string{"saturday"}},
+			want: true,
+		},
+		{
+			name: "tuesday and thursday only",
+			user: User{PreferredDays: []string{"tuesday", "thursday"}},
+			want: false,
+		},
+		{
+			name: "preferred day with windows for its part of the week",
+			user: User{PreferredDays: []string{"saturday"}, TimePreferences: TimePreferences{WeekendSlots: evenings}},
+			want: true,
+		},
+		{
+			name: "preferred day without windows for its part of the week",
+			user: User{PreferredDays: []string{"saturday"}, TimePreferences: TimePreferences{WeekdaySlots: evenings}},
+			want: false,
+		},
+		{
+			name: "windows don't widen the preferred days",
+			user: User{PreferredDays: []string{"tuesday"}, TimePreferences: TimePreferences{WeekendSlots: evenings}},
+			want: false,
+		},
+		{
+			// 18:00 Saturday in London is 02:00 Sunday in Tokyo
+			name: "day is the user's day",
+			user: User{PreferredDays: []string{"sunday"}, Timezone: "Asia/Tokyo"},
+			want: true,
+		},
+		{
+			name: "venue's day isn't used",
+			user: User{PreferredDays: []string{"saturday"}, Timezone: "Asia/Tokyo"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, s.shouldNotifyUser(tt.user, slot))
+		})
+	}
+}
+
+// The retention service reads preferences straight from user_preferences; both
+// must reach the same decision for the same user and slot
+func TestShouldNotifyUser_AgreesWithRetention(t *testing.T) {
+	s := newTestNotificationService()
+	venueID := primitive.NewObjectID()
+	evenings := []models.TimeRange{{Start: "18:00", End: "21:00"}}
+
+	// Saturday and Monday 18:00 at a London venue, for a London user
+	saturday := models.CourtSlot{VenueID: venueID, VenueName: "Victoria Park", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 20}
+	monday := saturday
+	monday.Date = "2024-06-17"
+
+	tests := []struct {
+		name string
+		pref models.UserPreferences
+	}{
+		{name: "no preferences"},
+		{name: "preferred venue ID", pref: models.UserPreferences{PreferredVenues: []string{venueID.Hex()}}},
+		{name: "other venue ID", pref: models.UserPreferences{PreferredVenues: []string{primitive.NewObjectID().Hex()}}},
+		{name: "venue name", pref: models.UserPreferences{PreferredVenues: []string{"Victoria Park"}}},
+		{name: "excluded venue", pref: models.UserPreferences{ExcludedVenues: []string{venueID.Hex()}}},
+		{name: "other venue excluded", pref: models.UserPreferences{ExcludedVenues: []string{primitive.NewObjectID().Hex()}}},
+		{name: "excluded and not preferred", pref: models.UserPreferences{PreferredVenues: []string{primitive.NewObjectID().Hex()}, ExcludedVenues: []string{venueID.Hex()}}},
+		{name: "excluded and preferred", pref: models.UserPreferences{PreferredVenues: []string{venueID.Hex()}, ExcludedVenues: []string{venueID.Hex()}}},
+		{name: "within max price", pref: models.UserPreferences{MaxPrice: 20}},
+		{name: "over max price", pref: models.UserPreferences{MaxPrice: 15}},
+		{name: "weekday evenings", pref: models.UserPreferences{WeekdayTimes: evenings}},
+		{name: "weekend evenings", pref: models.UserPreferences{WeekendTimes: evenings}},
+		{name: "preferred day", pref: models.UserPreferences{PreferredDays: []string{"saturday"}}},
+		{name: "tuesday and thursday only", pref: models.UserPreferences{PreferredDays: []string{"tuesday", "thursday"}}},
+		{name: "preferred weekday with weekend windows", pref: models.UserPreferences{PreferredDays: []string{"monday"}, WeekendTimes: evenings}},
+		{name: "preferred weekend day with weekend windows", pref: models.UserPreferences{PreferredDays: []string{"saturday"}, WeekendTimes: evenings}},
+		{name: "weekend mornings", pref: models.UserPreferences{WeekendTimes: []models.TimeRange{{Start: "09:00", End: "12:00"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := User{
+				PreferredVenues: tt.pref.PreferredVenues,
+				ExcludedVenues:  tt.pref.ExcludedVenues,
+				PreferredDays:   tt.pref.PreferredDays,
+				TimePreferences: TimePreferences{
+					WeekdaySlots: timeSlots(tt.pref.WeekdayTimes),
+					WeekendSlots: timeSlots(tt.pref.WeekendTimes),
+				},
+				MaxPrice: tt.pref.MaxPrice,
+				Timezone: "Europe/London",
+			}
+
+			for _, slot := range []models.CourtSlot{saturday, monday} {
+				want, err := retention.DoesSlotMatchActivePreferences(slot, []models.UserPreferences{tt.pref})
+				require.NoError(t, err)
+
+				got := s.shouldNotifyUser(user, SlotData{
+					VenueID:   slot.VenueID.Hex(),
+					VenueName: slot.VenueName,
+					Date:      slot.Date,
+					StartTime: slot.StartTime,
+					EndTime:   slot.EndTime,
+					Price:     slot.Price,
+				})
+				assert.Equal(t, want, got, "slot on %s", slot.Date)
+			}
+		})
+	}
+}
+
+func timeSlots(ranges []models.TimeRange) []TimeSlot {
+	slots := make([]TimeSlot, 0, len(ranges))
+	for _, r := range ranges {
+		slots = append(slots, TimeSlot{Start: r.Start, End: r.End})
+	}
+	return slots
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// defaultMetricsPort is used when NOTIFICATION_METRICS_PORT is not set
+const defaultMetricsPort = "9091"
+
+// sendLatencyBuckets are the upper bounds (in seconds) of the send latency histogram
+var sendLatencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// notificationMetrics holds the metrics exposed on /metrics, registered on a
+// registry of its own. All methods are safe to call on a nil receiver so tests
+// can skip metrics entirely.
+type notificationMetrics struct {
+	registry *prometheus.Registry
+
+	slotsProcessed    prometheus.Counter
+	duplicatesSkipped prometheus.Counter
+	slotsUnchanged    prometheus.Counter
+	alertsLimited     prometheus.Counter
+	notificationsSent *prometheus.CounterVec // By channel
+	sendFailures      *prometheus.CounterVec // By channel
+	sendLatency       prometheus.Histogram
+}
+
+func newNotificationMetrics() *notificationMetrics {
+	m := &notificationMetrics{
+		registry: prometheus.NewRegistry(),
+		slotsProcessed: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "notification_slots_processed_total",
+			Help: "Slot messages processed.",
+		}),
+		duplicatesSkipped: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "notification_duplicates_skipped_total",
+			Help: "Matched slots skipped as duplicates.",
+		}),
+		slotsUnchanged: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "notification_slots_unchanged_total",
+			Help: "Slot messages skipped because the slot did not just become available.",
+		}),
+		alertsLimited: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "notification_alerts_limited_total",
+			Help: "Matched slots dropped because the user reached their alert limit.",
+		}),
+		notificationsSent: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "notification_sent_total",
+			Help: "Notifications delivered successfully.",
+		}, []string{"channel"}),
+		sendFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "notification_send_failures_total",
+			Help: "Notifications that failed to deliver.",
+		}, []string{"channel"}),
+		sendLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "notification_send_latency_seconds",
+			Help:    "Time taken to deliver a notification.",
+			Buckets: sendLatencyBuckets,
+		}),
+	}
+	m.registry.MustRegister(
+		m.slotsProcessed,
+		m.duplicatesSkipped,
+		m.slotsUnchanged,
+		m.alertsLimited,
+		m.notificationsSent,
+		m.sendFailures,
+		m.sendLatency,
+	)
+	return m
+}
+
+// incSlotsProcessed counts a slot message that was parsed and evaluated against users
+func (m *notificationMetrics) incSlotsProcessed() {
+	if m == nil {
+		return
+	}
+	m.slotsProcessed.Inc()
+}
+
+// incDuplicatesSkipped counts a matched slot that was suppressed by deduplication
+func (m *notificationMetrics) incDuplicatesSkipped() {
+	if m == nil {
+		return
+	}
+	m.duplicatesSkipped.Inc()
+}
+
+// incSlotsUnchanged counts a slot message skipped because the slot didn't just become available
+func (m *notificationMetrics) incSlotsUnchanged() {
+	if m == nil {
+		return
+	}
+	m.slotsUnchanged.Inc()
+}
+
+// addAlertsLimited counts matched slots dropped because the user reached their alert limit
+func (m *notificationMetrics) addAlertsLimited(slots int) {
+	if m == nil {
+		return
+	}
+	m.alertsLimited.Add(float64(slots))
+}
+
+// observeSend records the outcome and latency of a single channel delivery
+func (m *notificationMetrics) observeSend(channel string, duration time.Duration, err error) {
+	if m == nil {
+		return
+	}
+
+	if err != nil {
+		m.sendFailures.WithLabelValues(channel).Inc()
+	} else {
+		m.notificationsSent.WithLabelValues(channel).Inc()
+	}
+	m.sendLatency.Observe(duration.Seconds())
+}
+
+// Metrics read from the service when scraped
+var (
+	slotParseFailuresDesc = prometheus.NewDesc("notification_slot_parse_failures_total",
+		"Slot messages moved to the dead-letter queue.", nil, nil)
+	venueCacheHitsDesc = prometheus.NewDesc("notification_venue_cache_hits_total",
+		"Venue lookups served from the Redis cache.", nil, nil)
+	venueCacheMissesDesc = prometheus.NewDesc("notification_venue_cache_misses_total",
+		"Venue lookups that read MongoDB.", nil, nil)
+	emailDailyQuotaDesc = prometheus.NewDesc("notification_email_daily_quota_remaining",
+		"Emails that can still be sent today within SMTP_MAX_PER_DAY.", nil, nil)
+)
+
+// serviceCollector reports the service's own counters, along with the venue
+// cache and email quota when the service has them
+type serviceCollector struct {
+	s *NotificationService
+}
+
+func (c serviceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- slotParseFailuresDesc
+	ch <- venueCacheHitsDesc
+	ch <- venueCacheMissesDesc
+	ch <- emailDailyQuotaDesc
+}
+
+func (c serviceCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(slotParseFailuresDesc, prometheus.CounterValue, float64(c.s.parseFailures.Load()))
+
+	if c.s.venueCache != nil {
+		stats := c.s.venueCache.Stats()
+		ch <- prometheus.MustNewConstMetric(venueCacheHitsDesc, prometheus.CounterValue, float64(stats.Hits))
+		ch <- prometheus.MustNewConstMetric(venueCacheMissesDesc, prometheus.CounterValue, float64(stats.Misses))
+	}
+
+	if quota, ok := c.s.channels[ChannelEmail].(dailyQuotaReporter); ok {
+		if remaining, capped := quota.RemainingDailyQuota(); capped {
+			ch <- prometheus.MustNewConstMetric(emailDailyQuotaDesc, prometheus.GaugeValue, float64(remaining))
+		}
+	}
+}
+
+// metricsHandler serves the service's metrics for Prometheus to scrape
+func (s *NotificationService) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	metrics := s.metrics
+	if metrics == nil {
+		metrics = newNotificationMetrics()
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(serviceCollector{s})
+	gatherers := prometheus.Gatherers{metrics.registry, registry}
+
+	promhttp.HandlerFor(gatherers, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// dailyQuotaReporter is implemented by channels with a cap on sends per day
+type dailyQuotaReporter interface {
+	RemainingDailyQuota() (int, bool)
+}
+
+// startMetricsServer exposes /metrics on NOTIFICATION_METRICS_PORT in the background
+func (s *NotificationService) startMetricsServer() *http.Server {
+	port := getEnvWithDefault("NOTIFICATION_METRICS_PORT", defaultMetricsPort)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.metricsHandler)
+
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		s.logger.Info("Metrics server listening", map[string]interface{}{"port": port, "path": "/metrics"})
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			s.logger.Error("Metrics server stopped", map[string]interface{}{"error": err.Error()})
+		}
+	}()
+
+	return server
+}
+
+// stopMetricsServer shuts the metrics server down, allowing in-flight scrapes to finish
+func (s *NotificationService) stopMetricsServer(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		s.logger.Warn("Failed to shut down metrics server", map[string]interface{}{"error": err.Error()})
+	}
+}
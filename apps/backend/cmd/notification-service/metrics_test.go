@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// failingChannel always returns an error
+type failingChannel struct{}
+
+func (failingChannel) Send(toAddress, courtDetails, bookingLink string) error {
+	return errors.New("delivery failed")
+}
+
+func TestMetricsHandler_ExposesCounters(t *testing.T) {
+	s := newTestNotificationService()
+	s.metrics = newNotificationMetrics()
+
+	s.metrics.incSlotsProcessed()
+	s.metrics.incSlotsProcessed()
+	s.metrics.incDuplicatesSkipped()
+	s.metrics.observeSend(ChannelEmail, 200*time.Millisecond, nil)
+	s.metrics.observeSend(ChannelSMS, 3*time.Second, errors.New("boom"))
+	s.parseFailures.Add(4)
+
+	rec := httptest.NewRecorder()
+	s.metricsHandler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	body := rec.Body.String()
+	assert.Contains(t, body, "notification_slots_processed_total 2\n")
+	assert.Contains(t, body, "notification_duplicates_skipped_total 1\n")
+	assert.Contains(t, body, "notification_slot_parse_failures_total 4\n")
+	assert.Contains(t, body, `notification_sent_total{channel="email"} 1`)
+	assert.Contains(t, body, `notification_send_failures_total{channel="sms"} 1`)
+	assert.Contains(t, body, "# TYPE notification_send_latency_seconds histogram")
+	assert.Contains(t, body, `notification_send_latency_seconds_bucket{le="0.25"} 1`)
+	assert.Contains(t, body, `notification_send_latency_seconds_bucket{le="5"} 2`)
+	assert.Contains(t, body, `notification_send_latency_seconds_bucket{le="+Inf"} 2`)
+	assert.Contains(t, body, "notification_send_latency_seconds_count 2\n")
+}
+
+func TestSendBatchedNotification_RecordsMetrics(t *testing.T) {
+	s := newTestNotificationService()
+	s.metrics = newNotificationMetrics()
+	s.registerChannel(ChannelEmail, newRecordingChannel())
+	s.registerChannel(ChannelSMS, failingChannel{})
+
+	user := User{ID: primitive.NewObjectID(), Email: "a@example.com", EmailEnabled: true, SMSEnabled: true, PhoneNumber: "+447700900123"}
+	err := s.sendBatchedNotification(user, []SlotData{{VenueName: "Victoria Park", Date: "2024-06-15"}})
+	require.Error(t, err)
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.notificationsSent.WithLabelValues(ChannelEmail)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.sendFailures.WithLabelValues(ChannelSMS)))
+
+	var latency dto.Metric
+	require.NoError(t, s.metrics.sendLatency.Write(&latency))
+	assert.Equal(t, uint64(2), latency.GetHistogram().GetSampleCount())
+}
@@ -0,0 +1,94 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/testenv"
+)
+
+func TestIntegration_ProcessSlotMessage_AlertsOncePerSlot(t *testing.T) {
+	env, cleanup := testenv.SetupTestEnv(t)
+	defer cleanup()
+
+	s := NewNotificationService(env.DB, env.Redis, discardLogger())
+	channel := newRecordingChannel()
+	s.registerChannel(ChannelEmail, channel)
+
+	allDay := []TimeSlot{{Start: "00:00", End: "23:59"}}
+	user := User{
+		ID:              primitive.NewObjectID(),
+		Email:           "player@example.com",
+		PreferredVenues: []string{"venue-1"},
+		TimePreferences: TimePreferences{WeekdaySlots: allDay, WeekendSlots: allDay},
+		MaxPrice:        50,
+		EmailEnabled:    true,
+	}
+	s.users = []User{user}
+
+	event := models.CourtAvailabilityEvent{
+		SchemaVersion: models.CourtAvailabilitySchemaVersion,
+		VenueID:       "venue-1",
+		VenueName:     "Victoria Park",
+		CourtID:       "court-1",
+		CourtName:     "Court 1",
+		Date:          time.Now().AddDate(0, 0, 2).Format("2006-01-02"),
+		StartTime:     "18:00",
+		EndTime:       "19:00",
+		Price:         12.50,
+		BookingURL:    "https://example.com/book",
+		DiscoveredAt:  time.Now(),
+	}
+	message, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	// The scraper reports the slot on consecutive runs
+	s.processSlotMessage(string(message))
+	s.processSlotMessage(string(message))
+	s.flushBatchedNotifications()
+
+	assert.Len(t, channel.sendTimes(user.Email), 1)
+	assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.slotsProcessed))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.slotsUnchanged), "the second report isn't a new slot")
+
+	// The alert was recorded, so it isn't repeated even if the slot state is lost
+	dupCheck, err := s.deduplicationSvc.CheckForDuplicate(context.Background(), user.ID, event, user.DedupWindows)
+	require.NoError(t, err)
+	assert.True(t, dupCheck.IsDuplicate)
+}
+
+func TestIntegration_SMTPService_RecordsSendsOverDailyQuota(t *testing.T) {
+	env, cleanup := testenv.SetupTestEnv(t)
+	defer cleanup()
+
+	g := NewGmailService("alerts@example.com", "app-password", alertFromName, discardLogger())
+	g.SetRetryPolicy(1, 0)
+	g.SetFailedNotificationQueue(env.Redis)
+	g.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error { return nil }
+	g.SetRateLimit(0, 1)
+
+	require.NoError(t, g.sendEmail("player@example.com", "Court available", "Court 1 at 18:00"))
+	assert.ErrorIs(t, g.sendEmail("player@example.com", "Court available", "Court 2 at 18:00"), errDailyQuotaExhausted)
+
+	payloads, err := env.Redis.LRange(context.Background(), failedNotificationsQueue, 0, -1).Result()
+	require.NoError(t, err)
+	require.Len(t, payloads, 1)
+
+	var failed FailedNotification
+	require.NoError(t, json.Unmarshal([]byte(payloads[0]), &failed))
+	assert.Equal(t, "player@example.com", failed.To)
+	assert.Contains(t, failed.Message, "Court 2 at 18:00")
+	assert.Equal(t, errDailyQuotaExhausted.Error(), failed.Error)
+	assert.False(t, failed.Permanent, "retried once the quota resets")
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"time"
+
+	"tennis-booker/internal/models"
+)
+
+// inQuietHours reports whether now falls outside the user's alert time window, in the user's timezone
+func inQuietHours(user User, now time.Time) bool {
+	return !models.IsWithinAlertTimeWindow(user.AlertWindowStart, user.AlertWindowEnd, now.In(userLocation(user)))
+}
+
+// quietHoursDelay returns how long until the user's alert window next opens, or 0 if it is open now
+func quietHoursDelay(user User, now time.Time) time.Duration {
+	local := now.In(userLocation(user))
+	open := models.NextAlertWindowOpen(user.AlertWindowStart, user.AlertWindowEnd, local)
+	if !open.After(local) {
+		return 0
+	}
+	return open.Sub(local)
+}
+
+// batchDelay is how long to hold a new batch for the user: the batch window, or
+// until their quiet hours end if that is later. Matched slots are buffered
+// rather than dropped so the user still hears about them once the window opens.
+func (s *NotificationService) batchDelay(user User, now time.Time) time.Duration {
+	delay := s.batchWindow()
+	if quiet := quietHoursDelay(user, now); quiet > delay {
+		s.logger.Info("User is in quiet hours, holding alerts", map[string]interface{}{"user_email": user.Email, "wait": quiet.Round(time.Minute).String()})
+		return quiet
+	}
+	return delay
+}
+
+// digestHour is the local hour the user's digest goes out: their chosen hour, or
+// the hour their alert window opens if the chosen hour falls in quiet hours
+func digestHour(user User, now time.Time) int {
+	local := now.In(userLocation(user))
+	chosen := time.Date(local.Year(), local.Month(), local.Day(), user.DigestSendHour, 0, 0, 0, local.Location())
+
+	open := models.NextAlertWindowOpen(user.AlertWindowStart, user.AlertWindowEnd, chosen)
+	if open.Minute() > 0 {
+		// The digest check runs on the hour, so wait for the first full hour inside the window
+		return (open.Hour() + 1) % 24
+	}
+	return open.Hour()
+}
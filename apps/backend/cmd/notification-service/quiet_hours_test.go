@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestInQuietHours(t *testing.T) {
+	user := User{AlertWindowStart: "07:00", AlertWindowEnd: "22:00", Timezone: "Europe/London"}
+
+	// June: London is UTC+1
+	assert.True(t, inQuietHours(user, time.Date(2024, 6, 15, 2, 0, 0, 0, time.UTC)))  // 03:00 local
+	assert.False(t, inQuietHours(user, time.Date(2024, 6, 15, 6, 0, 0, 0, time.UTC))) // 07:00 local
+	assert.True(t, inQuietHours(user, time.Date(2024, 6, 15, 21, 1, 0, 0, time.UTC))) // 22:01 local
+
+	assert.False(t, inQuietHours(User{}, time.Date(2024, 6, 15, 2, 0, 0, 0, time.UTC)))
+}
+
+func TestQuietHoursDelay(t *testing.T) {
+	user := User{AlertWindowStart: "07:00", AlertWindowEnd: "22:00", Timezone: "Europe/London"}
+
+	// 03:00 local -> window opens in 4 hours
+	assert.Equal(t, 4*time.Hour, quietHoursDelay(user, time.Date(2024, 6, 15, 2, 0, 0, 0, time.UTC)))
+	// 23:30 local -> opens at 07:00 the next day
+	assert.Equal(t, 7*time.Hour+30*time.Minute, quietHoursDelay(user, time.Date(2024, 6, 15, 22, 30, 0, 0, time.UTC)))
+	// Inside the window
+	assert.Equal(t, time.Duration(0), quietHoursDelay(user, time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestAddSlotToBatch_HoldsSlotsDuringQuietHours(t *testing.T) {
+	now := time.Now().In(loadLocation("UTC"))
+
+	// A window that opened an hour ago and closed a minute ago, so the user is in quiet hours
+	user := User{
+		ID:               primitive.NewObjectID(),
+		Email:            "sleeper@example.com",
+		EmailEnabled:     true,
+		Timezone:         "UTC",
+		AlertWindowStart: now.Add(-time.Hour).Format("15:04"),
+		AlertWindowEnd:   now.Add(-2 * time.Minute).Format("15:04"),
+	}
+	require.True(t, inQuietHours(user, now))
+
+	channel := newRecordingChannel()
+	s := newTestNotificationService()
+	s.BatchWindow = 10 * time.Millisecond
+	s.users = []User{user}
+	s.registerChannel(ChannelEmail, channel)
+
+	s.addSlotToBatch(user, SlotData{VenueName: "Victoria Park", CourtName: "Court 1", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00"})
+
+	// Well past the batch window the slot is still buffered, not sent or dropped
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, channel.sendTimes(user.Email))
+
+	s.batchMutex.RLock()
+	assert.Len(t, s.slotBatch[user.Email], 1)
+	s.batchMutex.RUnlock()
+
+	s.flushBatchedNotifications()
+}
+
+func TestDigestHour_MovesOutOfQuietHours(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 8, digestHour(User{DigestSendHour: 8}, now))
+	assert.Equal(t, 0, digestHour(User{DigestSendHour: 0}, now), "midnight is a valid choice")
+	assert.Equal(t, 8, digestHour(User{DigestSendHour: 8, AlertWindowStart: "07:00", AlertWindowEnd: "22:00"}, now))
+	assert.Equal(t, 9, digestHour(User{DigestSendHour: 6, AlertWindowStart: "09:00", AlertWindowEnd: "22:00"}, now))
+	assert.Equal(t, 10, digestHour(User{DigestSendHour: 6, AlertWindowStart: "09:30", AlertWindowEnd: "22:00"}, now))
+}
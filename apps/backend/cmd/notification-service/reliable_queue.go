@@ -0,0 +1,299 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"tennis-booker/internal/logging"
+)
+
+// Each worker claims a slot message by moving it from court_slots into its own
+// processing list, and removes it from there once the message is handled. A
+// worker that dies mid-message leaves it in its processing list, where the
+// reaper finds it after the visibility timeout and puts it back on court_slots.
+// A message that has been abandoned more than the allowed number of times is
+// moved to the dead-letter queue instead, so one poison message can't take
+// down every worker in turn.
+const (
+	slotProcessingKeyPrefix = "court_slots:processing:" // + worker ID: messages the worker is handling
+	slotClaimedKeyPrefix    = "court_slots:claimed:"    // + worker ID: when the worker claimed its messages
+	slotWorkersKey          = "court_slots:workers"     // Workers that may have a processing list
+	slotRetriesKey          = "court_slots:retries"     // Message digest -> times the message was requeued
+
+	defaultVisibilityTimeout = 5 * time.Minute
+	defaultMaxQueueRetries   = 3
+	reaperInterval           = time.Minute
+)
+
+// forgetWorkerScript removes a worker from the workers set only if its
+// processing list is empty, so a message claimed concurrently isn't orphaned
+var forgetWorkerScript = redis.NewScript(`
+if redis.call('LLEN', KEYS[1]) == 0 then
+	redis.call('DEL', KEYS[2])
+	return redis.call('SREM', KEYS[3], ARGV[1])
+end
+return 0
+`)
+
+// reliableSlotQueue reads court_slots so that a message is only removed once it
+// has been handled
+type reliableSlotQueue struct {
+	client            *redis.Client
+	workerID          string
+	visibilityTimeout time.Duration // How long a claimed message may go unacknowledged
+	maxRetries        int           // Requeues before a message is dead-lettered
+}
+
+// newReliableSlotQueue returns a queue configured from the environment, or nil without a client
+func newReliableSlotQueue(client *redis.Client, logger *logging.Logger) *reliableSlotQueue {
+	if client == nil {
+		return nil
+	}
+	return &reliableSlotQueue{
+		client:            client,
+		workerID:          workerIDFromEnv(),
+		visibilityTimeout: visibilityTimeoutFromEnv(logger),
+		maxRetries:        maxQueueRetriesFromEnv(logger),
+	}
+}
+
+// workerIDFromEnv reads NOTIFICATION_WORKER_ID, defaulting to hostname-pid so
+// each process gets its own processing list
+func workerIDFromEnv() string {
+	if id := os.Getenv("NOTIFICATION_WORKER_ID"); id != "" {
+		return id
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "worker"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// visibilityTimeoutFromEnv reads NOTIFICATION_QUEUE_VISIBILITY_TIMEOUT (e.g. "5m"), defaulting to 5m
+func visibilityTimeoutFromEnv(logger *logging.Logger) time.Duration {
+	value := os.Getenv("NOTIFICATION_QUEUE_VISIBILITY_TIMEOUT")
+	if value == "" {
+		return defaultVisibilityTimeout
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		logger.Warn("Invalid NOTIFICATION_QUEUE_VISIBILITY_TIMEOUT, using the default", map[string]interface{}{"value": value, "default": defaultVisibilityTimeout.String()})
+		return defaultVisibilityTimeout
+	}
+
+	return timeout
+}
+
+// maxQueueRetriesFromEnv reads NOTIFICATION_QUEUE_MAX_RETRIES, defaulting to 3
+func maxQueueRetriesFromEnv(logger *logging.Logger) int {
+	value := os.Getenv("NOTIFICATION_QUEUE_MAX_RETRIES")
+	if value == "" {
+		return defaultMaxQueueRetries
+	}
+
+	retries, err := strconv.Atoi(value)
+	if err != nil || retries < 0 {
+		logger.Warn("Invalid NOTIFICATION_QUEUE_MAX_RETRIES, using the default", map[string]interface{}{"value": value, "default": defaultMaxQueueRetries})
+		return defaultMaxQueueRetries
+	}
+
+	return retries
+}
+
+func slotProcessingKey(workerID string) string { return slotProcessingKeyPrefix + workerID }
+
+func slotClaimedKey(workerID string) string { return slotClaimedKeyPrefix + workerID }
+
+// messageDigest identifies a message in the retries hash without storing it twice
+func messageDigest(payload string) string {
+	sum := sha256.Sum256([]byte(payload))
+	return hex.EncodeToString(sum[:])
+}
+
+// Claim waits up to timeout for a message and moves it into this worker's
+// processing list. It returns redis.Nil when no message arrived.
+func (q *reliableSlotQueue) Claim(ctx context.Context, timeout time.Duration) (string, error) {
+	payload, err := q.client.BRPopLPush(ctx, slotQueue, slotProcessingKey(q.workerID), timeout).Result()
+	if err != nil {
+		return "", err
+	}
+
+	// Registered after the claim so the reaper can't forget the worker in between
+	_, err = q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, slotWorkersKey, q.workerID)
+		pipe.Set(ctx, slotClaimedKey(q.workerID), time.Now().Unix(), 0)
+		return nil
+	})
+	return payload, err
+}
+
+// Ack removes a handled message from this worker's processing list
+func (q *reliableSlotQueue) Ack(ctx context.Context, payload string) error {
+	_, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LRem(ctx, slotProcessingKey(q.workerID), 1, payload)
+		pipe.Del(ctx, slotClaimedKey(q.workerID))
+		pipe.HDel(ctx, slotRetriesKey, messageDigest(payload))
+		return nil
+	})
+	return err
+}
+
+// reapResult counts what one reaper pass did
+type reapResult struct {
+	Requeued     int
+	DeadLettered int
+}
+
+// Reap returns messages that any worker has held longer than the visibility
+// timeout to court_slots, dead-lettering those that have used up their retries.
+// Workers with nothing in flight are forgotten.
+func (q *reliableSlotQueue) Reap(ctx context.Context, now time.Time) (reapResult, error) {
+	var result reapResult
+
+	workers, err := q.client.SMembers(ctx, slotWorkersKey).Result()
+	if err != nil {
+		return result, err
+	}
+
+	for _, workerID := range workers {
+		processingKey := slotProcessingKey(workerID)
+		claimedKey := slotClaimedKey(workerID)
+
+		forgotten, err := forgetWorkerScript.Run(ctx, q.client, []string{processingKey, claimedKey, slotWorkersKey}, workerID).Int()
+		if err != nil {
+			return result, err
+		}
+		if forgotten > 0 {
+			continue
+		}
+
+		claimedAt, err := q.client.Get(ctx, claimedKey).Int64()
+		if errors.Is(err, redis.Nil) {
+			// The worker died before recording its claim; start the clock now
+			q.client.SetNX(ctx, claimedKey, now.Unix(), 0)
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		if now.Sub(time.Unix(claimedAt, 0)) < q.visibilityTimeout {
+			continue
+		}
+
+		if err := q.reapWorker(ctx, workerID, &result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// reapWorker moves every message out of an expired processing list. Each
+// message goes straight back onto court_slots, so it is never lost, and is
+// then taken off again for the dead-letter queue if it is out of retries.
+func (q *reliableSlotQueue) reapWorker(ctx context.Context, workerID string, result *reapResult) error {
+	processingKey := slotProcessingKey(workerID)
+
+	for {
+		payload, err := q.client.RPopLPush(ctx, processingKey, slotQueue).Result()
+		if errors.Is(err, redis.Nil) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		digest := messageDigest(payload)
+		retries, err := q.client.HIncrBy(ctx, slotRetriesKey, digest, 1).Result()
+		if err != nil {
+			return err
+		}
+		if retries <= int64(q.maxRetries) {
+			result.Requeued++
+			continue
+		}
+
+		// Another worker may already have claimed it again, in which case it gets one more try
+		removed, err := q.client.LRem(ctx, slotQueue, 1, payload).Result()
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			continue
+		}
+
+		entry, err := json.Marshal(DeadLetterEntry{
+			Payload:  payload,
+			Error:    fmt.Sprintf("abandoned by worker %s after %d retries", workerID, q.maxRetries),
+			FailedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := q.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.LPush(ctx, slotDeadLetterQueue, entry)
+			pipe.HDel(ctx, slotRetriesKey, digest)
+			return nil
+		}); err != nil {
+			return err
+		}
+		result.DeadLettered++
+	}
+
+	return q.client.Del(ctx, slotClaimedKey(workerID)).Err()
+}
+
+// startQueueReaper periodically requeues messages abandoned by dead workers
+// until the service shuts down
+func (s *NotificationService) startQueueReaper() {
+	if s.queue == nil {
+		return
+	}
+
+	s.logger.Info("Starting queue reaper", map[string]interface{}{
+		"worker_id":          s.queue.workerID,
+		"visibility_timeout": s.queue.visibilityTimeout.String(),
+		"max_retries":        s.queue.maxRetries,
+	})
+
+	go func() {
+		ticker := time.NewTicker(reaperInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if s.shuttingDown.Load() {
+				return
+			}
+			s.reapQueue()
+		}
+	}()
+}
+
+// reapQueue runs one reaper pass and logs anything it moved
+func (s *NotificationService) reapQueue() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	result, err := s.queue.Reap(ctx, time.Now())
+	if err != nil {
+		s.logger.Error("Queue reaper failed", map[string]interface{}{"error": err.Error()})
+	}
+	if result.Requeued > 0 || result.DeadLettered > 0 {
+		s.logger.Warn("Recovered slot messages abandoned by a worker", map[string]interface{}{
+			"requeued":      result.Requeued,
+			"dead_lettered": result.DeadLettered,
+			"queue":         slotQueue,
+		})
+	}
+}
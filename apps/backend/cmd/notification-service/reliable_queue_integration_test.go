@@ -0,0 +1,156 @@
+//go:build integration
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tennis-booker/internal/testenv"
+)
+
+// newIntegrationSlotQueue returns a queue for workerID on the test Redis
+func newIntegrationSlotQueue(client *redis.Client, workerID string, maxRetries int) *reliableSlotQueue {
+	return &reliableSlotQueue{
+		client:            client,
+		workerID:          workerID,
+		visibilityTimeout: time.Minute,
+		maxRetries:        maxRetries,
+	}
+}
+
+// claimAndAbandon claims the next message as q's worker without acknowledging it
+func claimAndAbandon(t *testing.T, q *reliableSlotQueue) string {
+	t.Helper()
+	payload, err := q.Claim(context.Background(), time.Second)
+	require.NoError(t, err)
+	return payload
+}
+
+// afterVisibilityTimeout is a reaper time at which every current claim has expired
+func afterVisibilityTimeout(q *reliableSlotQueue) time.Time {
+	return time.Now().Add(q.visibilityTimeout + time.Second)
+}
+
+func TestIntegration_ReliableSlotQueue_RequeuesAbandonedMessage(t *testing.T) {
+	env, cleanup := testenv.SetupTestEnv(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	worker := newIntegrationSlotQueue(env.Redis, "worker-1", 3)
+	reaper := newIntegrationSlotQueue(env.Redis, "worker-2", 3)
+	require.NoError(t, env.Redis.LPush(ctx, slotQueue, `{"venue_id":"venue-1"}`).Err())
+
+	payload := claimAndAbandon(t, worker)
+	assert.Zero(t, env.Redis.LLen(ctx, slotQueue).Val())
+
+	// Within the visibility timeout the claim is left alone
+	result, err := reaper.Reap(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, reapResult{}, result)
+	assert.Equal(t, []string{payload}, env.Redis.LRange(ctx, slotProcessingKey("worker-1"), 0, -1).Val())
+
+	// After it, the message goes back on court_slots and its requeue is counted
+	result, err = reaper.Reap(ctx, afterVisibilityTimeout(reaper))
+	require.NoError(t, err)
+	assert.Equal(t, reapResult{Requeued: 1}, result)
+	assert.Equal(t, []string{payload}, env.Redis.LRange(ctx, slotQueue, 0, -1).Val())
+	assert.Zero(t, env.Redis.LLen(ctx, slotProcessingKey("worker-1")).Val())
+	assert.Equal(t, "1", env.Redis.HGet(ctx, slotRetriesKey, messageDigest(payload)).Val())
+
+	// The worker has nothing in flight, so the next pass forgets it
+	_, err = reaper.Reap(ctx, afterVisibilityTimeout(reaper))
+	require.NoError(t, err)
+	assert.False(t, env.Redis.SIsMember(ctx, slotWorkersKey, "worker-1").Val())
+}
+
+func TestIntegration_ReliableSlotQueue_DeadLettersAfterMaxRetries(t *testing.T) {
+	env, cleanup := testenv.SetupTestEnv(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	q := newIntegrationSlotQueue(env.Redis, "worker-1", 2)
+	require.NoError(t, env.Redis.LPush(ctx, slotQueue, `{"venue_id":"poison"}`).Err())
+
+	var payload string
+	for retry := 1; retry <= q.maxRetries; retry++ {
+		payload = claimAndAbandon(t, q)
+		result, err := q.Reap(ctx, afterVisibilityTimeout(q))
+		require.NoError(t, err)
+		assert.Equal(t, reapResult{Requeued: 1}, result, "retry %d", retry)
+	}
+
+	// Abandoned once more than allowed, it is dead-lettered instead of requeued
+	claimAndAbandon(t, q)
+	result, err := q.Reap(ctx, afterVisibilityTimeout(q))
+	require.NoError(t, err)
+	assert.Equal(t, reapResult{DeadLettered: 1}, result)
+
+	assert.Zero(t, env.Redis.LLen(ctx, slotQueue).Val())
+	assert.Zero(t, env.Redis.LLen(ctx, slotProcessingKey("worker-1")).Val())
+	assert.False(t, env.Redis.HExists(ctx, slotRetriesKey, messageDigest(payload)).Val())
+
+	entries := env.Redis.LRange(ctx, slotDeadLetterQueue, 0, -1).Val()
+	require.Len(t, entries, 1)
+	var entry DeadLetterEntry
+	require.NoError(t, json.Unmarshal([]byte(entries[0]), &entry))
+	assert.Equal(t, payload, entry.Payload)
+	assert.Contains(t, entry.Error, "worker-1")
+}
+
+func TestIntegration_ReliableSlotQueue_AckClearsMessage(t *testing.T) {
+	env, cleanup := testenv.SetupTestEnv(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	q := newIntegrationSlotQueue(env.Redis, "worker-1", 3)
+	require.NoError(t, env.Redis.LPush(ctx, slotQueue, `{"venue_id":"venue-1"}`).Err())
+
+	// Abandon it once so it has a retries entry, then handle it
+	claimAndAbandon(t, q)
+	_, err := q.Reap(ctx, afterVisibilityTimeout(q))
+	require.NoError(t, err)
+	payload := claimAndAbandon(t, q)
+	require.True(t, env.Redis.HExists(ctx, slotRetriesKey, messageDigest(payload)).Val())
+
+	require.NoError(t, q.Ack(ctx, payload))
+
+	assert.Zero(t, env.Redis.LLen(ctx, slotProcessingKey("worker-1")).Val())
+	assert.Zero(t, env.Redis.Exists(ctx, slotClaimedKey("worker-1")).Val())
+	assert.False(t, env.Redis.HExists(ctx, slotRetriesKey, messageDigest(payload)).Val())
+
+	// Nothing is left to requeue
+	result, err := q.Reap(ctx, afterVisibilityTimeout(q))
+	require.NoError(t, err)
+	assert.Equal(t, reapResult{}, result)
+	assert.Zero(t, env.Redis.LLen(ctx, slotQueue).Val())
+}
+
+func TestIntegration_ReliableSlotQueue_StartsClockForUnrecordedClaim(t *testing.T) {
+	env, cleanup := testenv.SetupTestEnv(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// A worker that died between claiming a message and recording the claim
+	require.NoError(t, env.Redis.LPush(ctx, slotProcessingKey("worker-1"), `{"venue_id":"venue-1"}`).Err())
+	require.NoError(t, env.Redis.SAdd(ctx, slotWorkersKey, "worker-1").Err())
+
+	q := newIntegrationSlotQueue(env.Redis, "worker-2", 3)
+	now := time.Now()
+	result, err := q.Reap(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, reapResult{}, result, "the message gets a full visibility timeout from now")
+	claimedAt, err := env.Redis.Get(ctx, slotClaimedKey("worker-1")).Int64()
+	require.NoError(t, err)
+	assert.Equal(t, now.Unix(), claimedAt)
+
+	result, err = q.Reap(ctx, now.Add(q.visibilityTimeout))
+	require.NoError(t, err)
+	assert.Equal(t, reapResult{Requeued: 1}, result)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReliableQueueSettingsFromEnv(t *testing.T) {
+	logger := discardLogger()
+
+	t.Setenv("NOTIFICATION_QUEUE_VISIBILITY_TIMEOUT", "")
+	t.Setenv("NOTIFICATION_QUEUE_MAX_RETRIES", "")
+	assert.Equal(t, defaultVisibilityTimeout, visibilityTimeoutFromEnv(logger))
+	assert.Equal(t, defaultMaxQueueRetries, maxQueueRetriesFromEnv(logger))
+
+	t.Setenv("NOTIFICATION_QUEUE_VISIBILITY_TIMEOUT", "90s")
+	t.Setenv("NOTIFICATION_QUEUE_MAX_RETRIES", "0")
+	assert.Equal(t, 90*time.Second, visibilityTimeoutFromEnv(logger))
+	assert.Equal(t, 0, maxQueueRetriesFromEnv(logger)) // Dead-letter on the first abandonment
+
+	t.Setenv("NOTIFICATION_QUEUE_VISIBILITY_TIMEOUT", "-1m")
+	t.Setenv("NOTIFICATION_QUEUE_MAX_RETRIES", "many")
+	assert.Equal(t, defaultVisibilityTimeout, visibilityTimeoutFromEnv(logger))
+	assert.Equal(t, defaultMaxQueueRetries, maxQueueRetriesFromEnv(logger))
+}
+
+func TestWorkerIDFromEnv(t *testing.T) {
+	t.Setenv("NOTIFICATION_WORKER_ID", "")
+	hostname, _ := os.Hostname()
+	assert.True(t, strings.HasPrefix(workerIDFromEnv(), hostname+"-"))
+
+	t.Setenv("NOTIFICATION_WORKER_ID", "notifier-1")
+	assert.Equal(t, "notifier-1", workerIDFromEnv())
+	assert.Equal(t, "court_slots:processing:notifier-1", slotProcessingKey(workerIDFromEnv()))
+}
+
+func TestNewReliableSlotQueue_RequiresClient(t *testing.T) {
+	assert.Nil(t, newReliableSlotQueue(nil, discardLogger()))
+}
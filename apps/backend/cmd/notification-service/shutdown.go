@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"tennis-booker/internal/logging"
+)
+
+// defaultShutdownTimeout bounds how long shutdown waits for pending batches to be sent
+const defaultShutdownTimeout = 30 * time.Second
+
+// enginePollTimeout is how long each queue read blocks, so the engine notices shutdown promptly
+const enginePollTimeout = time.Second
+
+// shutdownTimeoutFromEnv reads NOTIFICATION_SHUTDOWN_TIMEOUT (e.g. "30s"), defaulting to 30s
+func shutdownTimeoutFromEnv(logger *logging.Logger) time.Duration {
+	value := os.Getenv("NOTIFICATION_SHUTDOWN_TIMEOUT")
+	if value == "" {
+		return defaultShutdownTimeout
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		logger.Warn("Invalid NOTIFICATION_SHUTDOWN_TIMEOUT, using the default", map[string]interface{}{"value": value, "default": defaultShutdownTimeout.String()})
+		return defaultShutdownTimeout
+	}
+
+	return timeout
+}
+
+// runNotificationEngine starts the notification engine in the background and
+// tracks it so Shutdown can wait for the last slot it read to be batched
+func (s *NotificationService) runNotificationEngine() {
+	s.engineWG.Add(1)
+	go func() {
+		defer s.engineWG.Done()
+		s.startNotificationEngine()
+	}()
+}
+
+// Shutdown stops the notification engine reading new slots, sends every pending
+// batch immediately instead of waiting for its timer, and waits for those sends
+// to finish. Slots held for a venue cooldown are sent too rather than lost, as
+// are summaries held for users over their alert limit.
+// It returns an error if ctx expires first, cancelling sends still waiting on
+// a channel's rate limit so they're recorded as failed rather than left hanging.
+func (s *NotificationService) Shutdown(ctx context.Context) error {
+	s.shuttingDown.Store(true)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.engineWG.Wait()
+		s.releaseAllVenueCooldowns()
+		s.flushBatchedNotifications()
+		s.releaseAllLimitedAlerts()
+		s.sendWG.Wait()
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.cancelPendingSends()
+		return fmt.Errorf("pending notifications not sent before shutdown timeout: %w", ctx.Err())
+	}
+}
+
+// pendingSendCanceler is implemented by channels whose sends can wait on a rate limit
+type pendingSendCanceler interface {
+	CancelPendingSends()
+}
+
+// cancelPendingSends stops every channel's sends that are waiting on a rate limit
+func (s *NotificationService) cancelPendingSends() {
+	for _, channel := range s.channels {
+		if canceler, ok := channel.(pendingSendCanceler); ok {
+			canceler.CancelPendingSends()
+		}
+	}
+}
+
+// shutdown runs Shutdown bounded by the configured timeout and logs the outcome
+func (s *NotificationService) shutdown() {
+	timeout := s.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	s.logger.Info("Flushing pending notification batches", map[string]interface{}{"timeout": timeout.String()})
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); err != nil {
+		s.logger.Warn("Shutdown did not finish sending batches", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	s.logger.Info("Pending notification batches sent")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// blockingChannel holds every send until released
+type blockingChannel struct {
+	release chan struct{}
+}
+
+func (c *blockingChannel) Send(toAddress, courtDetails, bookingLink string) error {
+	<-c.release
+	return nil
+}
+
+func TestShutdown_FlushesPendingBatches(t *testing.T) {
+	user := User{ID: primitive.NewObjectID(), Email: "player@example.com", EmailEnabled: true}
+
+	channel := newRecordingChannel()
+	s := newTestNotificationService()
+	s.BatchWindow = time.Hour // Would never be sent without shutdown
+	s.users = []User{user}
+	s.registerChannel(ChannelEmail, channel)
+
+	s.addSlotToBatch(user, SlotData{VenueName: "Victoria Park", CourtName: "Court 1", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00"})
+	require.Empty(t, channel.sendTimes(user.Email))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Shutdown(ctx))
+
+	assert.Len(t, channel.sendTimes(user.Email), 1)
+	assert.Empty(t, s.slotBatch)
+	assert.Empty(t, s.batchTimers)
+}
+
+func TestShutdown_TimesOutOnStuckSend(t *testing.T) {
+	user := User{ID: primitive.NewObjectID(), Email: "player@example.com", EmailEnabled: true}
+
+	channel := &blockingChannel{release: make(chan struct{})}
+	defer close(channel.release)
+
+	s := newTestNotificationService()
+	s.BatchWindow = time.Hour
+	s.users = []User{user}
+	s.registerChannel(ChannelEmail, channel)
+	s.addSlotToBatch(user, SlotData{VenueName: "Victoria Park"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestShutdown_WaitsForTimerFlushInProgress(t *testing.T) {
+	user := User{ID: primitive.NewObjectID(), Email: "player@example.com", EmailEnabled: true}
+
+	channel := &blockingChannel{release: make(chan struct{})}
+	s := newTestNotificationService()
+	s.BatchWindow = 10 * time.Millisecond
+	s.users = []User{user}
+	s.registerChannel(ChannelEmail, channel)
+	s.addSlotToBatch(user, SlotData{VenueName: "Victoria Park"})
+
+	// Let the batch timer fire and block inside the send
+	require.Eventually(t, func() bool {
+		s.batchMutex.RLock()
+		defer s.batchMutex.RUnlock()
+		return len(s.slotBatch) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Shutdown(context.Background()) }()
+
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned while a send was still in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(channel.release)
+	require.NoError(t, <-done)
+}
+
+func TestShutdownTimeoutFromEnv(t *testing.T) {
+	t.Setenv("NOTIFICATION_SHUTDOWN_TIMEOUT", "")
+	assert.Equal(t, defaultShutdownTimeout, shutdownTimeoutFromEnv(newTestNotificationService().logger))
+
+	t.Setenv("NOTIFICATION_SHUTDOWN_TIMEOUT", "5s")
+	assert.Equal(t, 5*time.Second, shutdownTimeoutFromEnv(newTestNotificationService().logger))
+
+	t.Setenv("NOTIFICATION_SHUTDOWN_TIMEOUT", "soon")
+	assert.Equal(t, defaultShutdownTimeout, shutdownTimeoutFromEnv(newTestNotificationService().logger))
+}
@@ -0,0 +1,34 @@
+package main
+
+import "tennis-booker/internal/models"
+
+// decodeSlotMessage parses a message from the slot queue with the shared
+// versioned decoder, so messages from older producers are migrated and ones
+// from newer producers fail with models.ErrUnknownSchemaVersion
+func decodeSlotMessage(message string) (SlotData, error) {
+	event, err := models.DecodeCourtAvailabilityEvent([]byte(message))
+	if err != nil {
+		return SlotData{}, err
+	}
+	return slotFromAvailabilityEvent(event), nil
+}
+
+// slotFromAvailabilityEvent converts an availability event into the slot the
+// notification pipeline works with
+func slotFromAvailabilityEvent(event models.CourtAvailabilityEvent) SlotData {
+	return SlotData{
+		VenueID:     event.VenueID,
+		VenueName:   event.VenueName,
+		Platform:    event.Platform,
+		CourtID:     event.CourtID,
+		CourtName:   event.CourtName,
+		Date:        event.Date,
+		StartTime:   event.StartTime,
+		EndTime:     event.EndTime,
+		Price:       event.Price,
+		Currency:    event.Currency,
+		IsAvailable: event.IsAvailable(),
+		BookingURL:  event.BookingURL,
+		ScrapedAt:   event.DiscoveredAt,
+	}
+}
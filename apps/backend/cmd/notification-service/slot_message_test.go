@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tennis-booker/internal/models"
+)
+
+func TestDecodeSlotMessage_AvailabilityEvent(t *testing.T) {
+	slot, err := decodeSlotMessage(`{"schema_version":1,"venue_id":"v1","venue_name":"Victoria Park","platform":"courtside",` +
+		`"court_id":"c1","court_name":"Court 1","date":"2024-06-15","start_time":"18:00","end_time":"19:00",` +
+		`"price":12.5,"currency":"GBP","booking_url":"https://example.com/vp","discovered_at":"2024-06-10T09:00:00Z"}`)
+	require.NoError(t, err)
+
+	assert.Equal(t, SlotData{
+		VenueID: "v1", VenueName: "Victoria Park", Platform: "courtside", CourtID: "c1", CourtName: "Court 1",
+		Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 12.5, Currency: "GBP",
+		IsAvailable: true, BookingURL: "https://example.com/vp", ScrapedAt: time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC),
+	}, slot)
+
+	booked, err := decodeSlotMessage(`{"schema_version":1,"venue_id":"v1","court_id":"c1","available":false}`)
+	require.NoError(t, err)
+	assert.False(t, booked.IsAvailable)
+}
+
+func TestDecodeSlotMessage_LegacySlotData(t *testing.T) {
+	slot, err := decodeSlotMessage(`{"venueId":"v1","venueName":"Victoria Park","courtId":"c1","date":"2024-06-15","startTime":"18:00","isAvailable":true}`)
+	require.NoError(t, err)
+	assert.Equal(t, "Victoria Park", slot.VenueName)
+	assert.Equal(t, "18:00", slot.StartTime)
+	assert.True(t, slot.IsAvailable)
+
+	_, err = decodeSlotMessage("not json")
+	assert.Error(t, err)
+}
+
+func TestDecodeSlotMessage_UnknownSchemaVersion(t *testing.T) {
+	_, err := decodeSlotMessage(`{"schema_version":99,"venue_id":"v1"}`)
+	assert.ErrorIs(t, err, models.ErrUnknownSchemaVersion)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"tennis-booker/internal/logging"
+)
+
+// Slot orderings for batched notifications
+const (
+	SlotOrderSoonest  = "soonest"  // Earliest start first, then cheapest
+	SlotOrderCheapest = "cheapest" // Cheapest first, then earliest start
+)
+
+// slotOrderFromEnv reads NOTIFICATION_SLOT_ORDER, defaulting to soonest-first
+func slotOrderFromEnv(logger *logging.Logger) string {
+	value := strings.ToLower(strings.TrimSpace(os.Getenv("NOTIFICATION_SLOT_ORDER")))
+	switch value {
+	case "":
+		return SlotOrderSoonest
+	case SlotOrderSoonest, SlotOrderCheapest:
+		return value
+	default:
+		logger.Warn("Invalid NOTIFICATION_SLOT_ORDER, using the default", map[string]interface{}{"value": value, "default": SlotOrderSoonest})
+		return SlotOrderSoonest
+	}
+}
+
+// sortSlotsForAlert returns a copy of slots in the order they should appear in a
+// notification. Venue and court names break any remaining ties so output is stable.
+func sortSlotsForAlert(slots []SlotData, order string) []SlotData {
+	sorted := make([]SlotData, len(slots))
+	copy(sorted, slots)
+
+	// Dates are YYYY-MM-DD and times HH:MM, so string comparison is chronological
+	startsBefore := func(a, b SlotData) (less, decided bool) {
+		if a.Date != b.Date {
+			return a.Date < b.Date, true
+		}
+		if a.StartTime != b.StartTime {
+			return a.StartTime < b.StartTime, true
+		}
+		return false, false
+	}
+	cheaper := func(a, b SlotData) (less, decided bool) {
+		if a.Price != b.Price {
+			return a.Price < b.Price, true
+		}
+		return false, false
+	}
+
+	primary, secondary := startsBefore, cheaper
+	if order == SlotOrderCheapest {
+		primary, secondary = cheaper, startsBefore
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if less, ok := primary(a, b); ok {
+			return less
+		}
+		if less, ok := secondary(a, b); ok {
+			return less
+		}
+		if a.VenueName != b.VenueName {
+			return a.VenueName < b.VenueName
+		}
+		return a.CourtName < b.CourtName
+	})
+
+	return sorted
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func unorderedSlots() []SlotData {
+	return []SlotData{
+		{VenueName: "Victoria Park", CourtName: "Court 2", Date: "2024-06-16", StartTime: "09:00", EndTime: "10:00", Price: 8},
+		{VenueName: "Stratford Park", CourtName: "Court 1", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 12},
+		{VenueName: "Victoria Park", CourtName: "Court 1", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 10},
+		{VenueName: "Victoria Park", CourtName: "Court 3", Date: "2024-06-15", StartTime: "07:00", EndTime: "08:00", Price: 15},
+	}
+}
+
+func slotLabels(slots []SlotData) []string {
+	labels := make([]string, len(slots))
+	for i, slot := range slots {
+		labels[i] = slot.VenueName + "/" + slot.CourtName
+	}
+	return labels
+}
+
+func TestSortSlotsForAlert_Soonest(t *testing.T) {
+	input := unorderedSlots()
+	sorted := sortSlotsForAlert(input, SlotOrderSoonest)
+
+	assert.Equal(t, []string{
+		"Victoria Park/Court 3",  // 15th 07:00
+		"Victoria Park/Court 1",  // 15th 18:00, £10
+		"Stratford Park/Court 1", // 15th 18:00, £12
+		"Victoria Park/Court 2",  // 16th
+	}, slotLabels(sorted))
+
+	// Input is left untouched
+	assert.Equal(t, "Victoria Park/Court 2", slotLabels(input)[0])
+}
+
+func TestSortSlotsForAlert_Cheapest(t *testing.T) {
+	sorted := sortSlotsForAlert(unorderedSlots(), SlotOrderCheapest)
+
+	assert.Equal(t, []string{
+		"Victoria Park/Court 2",
+		"Victoria Park/Court 1",
+		"Stratford Park/Court 1",
+		"Victoria Park/Court 3",
+	}, slotLabels(sorted))
+}
+
+func TestFormatBatchedEmailDetails_StableOrder(t *testing.T) {
+	sorted := sortSlotsForAlert(unorderedSlots(), SlotOrderSoonest)
+
+	first := formatBatchedEmailDetails(sorted, english)
+	for i := 0; i < 20; i++ {
+		require.Equal(t, first, formatBatchedEmailDetails(sorted, english))
+	}
+
+	// Quick links follow the sorted order
+	quickLinks := first[strings.Index(first, "QUICK BOOKING LINKS"):strings.Index(first, "COURT DETAILS")]
+	assert.Less(t, strings.Index(quickLinks, "Court 3"), strings.Index(quickLinks, "Court 1"))
+
+	// Venues appear in order of their soonest slot
+	assert.Less(t, strings.Index(first, "🏟️ Victoria Park"), strings.Index(first, "🏟️ Stratford Park"))
+}
+
+func TestSendBatchedNotification_SendsSoonestFirst(t *testing.T) {
+	s := newTestNotificationService()
+	s.SlotOrder = SlotOrderSoonest
+
+	sender := &capturingHTMLSender{}
+	s.registerChannel(ChannelEmail, sender)
+
+	user := User{Email: "a@example.com", EmailEnabled: true}
+	require.NoError(t, s.sendBatchedNotification(user, unorderedSlots()))
+
+	require.Len(t, sender.slots, 4)
+	assert.Equal(t, "Court 3", sender.slots[0].CourtName)
+}
+
+// capturingHTMLSender records the slots and language passed to the HTML alert path
+type capturingHTMLSender struct {
+	slots        []SlotData
+	courtDetails string
+	language     string
+}
+
+func (c *capturingHTMLSender) Send(toAddress, courtDetails, bookingLink string) error {
+	return nil
+}
+
+func (c *capturingHTMLSender) SendCourtAvailabilityAlertHTML(toEmail, courtDetails string, slots []SlotData, bookingLink, unsubscribeURL, language string) error {
+	c.slots = slots
+	c.courtDetails = courtDetails
+	c.language = language
+	return nil
+}
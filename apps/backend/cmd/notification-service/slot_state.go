@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"tennis-booker/internal/database"
+	"tennis-booker/internal/models"
+)
+
+// slotStateStore remembers each slot's last-known availability; satisfied by database.SlotStateRepository
+type slotStateStore interface {
+	Observe(ctx context.Context, slotKey string, available bool, seenAt time.Time) (database.SlotTransition, error)
+}
+
+// slotKey identifies a slot the same way the deduplication service does
+func slotKey(slot SlotData) string {
+	event := models.CourtAvailabilityEvent{
+		VenueID:   slot.VenueID,
+		CourtID:   slot.CourtID,
+		Date:      slot.Date,
+		StartTime: slot.StartTime,
+	}
+	return event.GenerateSlotKey()
+}
+
+// slotSeenAt is when the scraper saw the slot, falling back to now for messages without a timestamp
+func slotSeenAt(slot SlotData, now time.Time) time.Time {
+	if slot.ScrapedAt.IsZero() {
+		return now
+	}
+	return slot.ScrapedAt
+}
+
+// observeSlot records the slot's availability and reports whether it has just
+// become available, with the alert type that describes how: a slot seen for the
+// first time is a new slot, and one that frees up after being booked is a
+// cancellation. Slots that were already available when last scraped are not
+// alerted on again. Without a state store, or if the store fails, any available
+// slot counts as a new slot and deduplication suppresses repeats.
+func (s *NotificationService) observeSlot(slot SlotData, now time.Time) (string, bool) {
+	if s.slotStates == nil {
+		return models.AlertTypeNewSlot, slot.IsAvailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	transition, err := s.slotStates.Observe(ctx, slotKey(slot), slot.IsAvailable, slotSeenAt(slot, now))
+	if err != nil {
+		s.logger.Warn("Failed to record slot state, falling back to deduplication", map[string]interface{}{"slot_key": slotKey(slot), "error": err.Error()})
+		return models.AlertTypeNewSlot, slot.IsAvailable
+	}
+
+	switch transition {
+	case database.SlotAppeared:
+		return models.AlertTypeNewSlot, true
+	case database.SlotReopened:
+		return models.AlertTypeCancellation, true
+	default:
+		return "", false
+	}
+}
+
+// isCancellation reports whether the slot freed up after being booked
+func isCancellation(slot SlotData) bool {
+	return slot.AlertType == models.AlertTypeCancellation
+}
+
+// countCancellations returns how many of the slots freed up after being booked
+func countCancellations(slots []SlotData) int {
+	count := 0
+	for _, slot := range slots {
+		if isCancellation(slot) {
+			count++
+		}
+	}
+	return count
+}
+
+// createSlotStateIndexes sets up expiry of slot states that are no longer scraped
+func (s *NotificationService) createSlotStateIndexes() {
+	repo, ok := s.slotStates.(*database.SlotStateRepository)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := repo.CreateIndexes(ctx); err != nil {
+		s.logger.Warn("Failed to create slot_states indexes", map[string]interface{}{"error": err.Error()})
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tennis-booker/internal/database"
+	"tennis-booker/internal/models"
+)
+
+// fakeSlotStateStore keeps slot availability in memory
+type fakeSlotStateStore struct {
+	available map[string]bool
+	err       error
+}
+
+func newFakeSlotStateStore() *fakeSlotStateStore {
+	return &fakeSlotStateStore{available: make(map[string]bool)}
+}
+
+func (f *fakeSlotStateStore) Observe(ctx context.Context, slotKey string, available bool, seenAt time.Time) (database.SlotTransition, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	previous, seen := f.available[slotKey]
+	f.available[slotKey] = available
+	switch {
+	case !seen && available:
+		return database.SlotAppeared, nil
+	case !seen:
+		return database.SlotUnavailable, nil
+	case previous == available:
+		return database.SlotUnchanged, nil
+	case available:
+		return database.SlotReopened, nil
+	default:
+		return database.SlotTaken, nil
+	}
+}
+
+func TestObserveSlot(t *testing.T) {
+	s := newTestNotificationService()
+	store := newFakeSlotStateStore()
+	s.slotStates = store
+	now := time.Now()
+
+	slot := SlotData{VenueID: "v1", CourtID: "c1", Date: "2024-06-15", StartTime: "18:00", IsAvailable: true}
+	booked := slot
+	booked.IsAvailable = false
+
+	observe := func(slot SlotData) string {
+		alertType, ok := s.observeSlot(slot, now)
+		if !ok {
+			return "skipped"
+		}
+		return alertType
+	}
+
+	assert.Equal(t, models.AlertTypeNewSlot, observe(slot), "first sighting of an available slot")
+	assert.Equal(t, "skipped", observe(slot), "still available since the last scrape")
+	assert.Equal(t, "skipped", observe(booked), "slot was booked")
+	assert.Equal(t, models.AlertTypeCancellation, observe(slot), "slot freed up again")
+	assert.Equal(t, map[string]bool{"v1:c1:2024-06-15:18:00": true}, store.available)
+
+	store.err = errors.New("mongo down")
+	assert.Equal(t, models.AlertTypeNewSlot, observe(slot), "falls back to deduplication when the store fails")
+	assert.Equal(t, "skipped", observe(booked))
+
+	s.slotStates = nil
+	assert.Equal(t, models.AlertTypeNewSlot, observe(slot))
+	assert.Equal(t, "skipped", observe(booked))
+}
+
+func TestSlotAlertSubject(t *testing.T) {
+	newSlot := SlotData{AlertType: models.AlertTypeNewSlot}
+	freedUp := SlotData{AlertType: models.AlertTypeCancellation}
+
+	assert.Equal(t, "🎾 New court available!", slotAlertSubject([]SlotData{newSlot}, english))
+	assert.Equal(t, "🎾 New courts available!", slotAlertSubject([]SlotData{newSlot, newSlot}, english))
+	assert.Equal(t, "🎾 Court freed up!", slotAlertSubject([]SlotData{freedUp}, english))
+	assert.Equal(t, "🎾 Court freed up!", slotAlertSubject([]SlotData{newSlot, freedUp}, english))
+	assert.Equal(t, "🎾 Courts freed up!", slotAlertSubject([]SlotData{freedUp, freedUp}, english))
+
+	assert.Equal(t, "A tennis court just freed up!", alertHeadline([]SlotData{freedUp}, english))
+	assert.Equal(t, "2 tennis courts just became available, 1 freed up by cancellations!", alertHeadline([]SlotData{newSlot, freedUp}, english))
+}
+
+func TestCancellationAlertContent(t *testing.T) {
+	slots := []SlotData{{
+		VenueName: "Victoria Park", CourtName: "Court 1", Date: "2024-06-15",
+		StartTime: "18:00", EndTime: "19:00", Price: 12, AlertType: models.AlertTypeCancellation,
+	}}
+
+	html, err := renderCourtAlertHTML(slots, "", english)
+	require.NoError(t, err)
+	assert.Contains(t, html, "A tennis court just freed up!")
+	assert.Contains(t, html, "Freed up</span>")
+
+	assert.Contains(t, formatBatchedEmailDetails(slots, english), "Court 1: 18:00-19:00 (£12.00) - freed up by a cancellation")
+	assert.True(t, strings.HasPrefix(formatBatchedSMSDetails(slots), "Tennis court freed up:"))
+}
+
+func TestProcessSlotMessage_SkipsUnchangedSlots(t *testing.T) {
+	s := newTestNotificationService()
+	s.metrics = newNotificationMetrics()
+	s.slotStates = newFakeSlotStateStore()
+
+	message, err := json.Marshal(SlotData{VenueID: "v1", CourtID: "c1", Date: time.Now().Format("2006-01-02"), StartTime: "18:00", IsAvailable: true})
+	require.NoError(t, err)
+
+	s.processSlotMessage(string(message))
+	s.processSlotMessage(string(message))
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.slotsProcessed))
+	assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.slotsUnchanged))
+}
+
+func TestSlotSeenAt(t *testing.T) {
+	now := time.Now()
+	scraped := now.Add(-time.Minute)
+
+	assert.Equal(t, scraped, slotSeenAt(SlotData{ScrapedAt: scraped}, now))
+	assert.Equal(t, now, slotSeenAt(SlotData{}, now))
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+	"unicode"
+
+	"tennis-booker/internal/logging"
+	"tennis-booker/internal/secrets"
+)
+
+// maxSMSLength is the longest message body Twilio will accept (it splits into segments beyond 160 chars)
+const maxSMSLength = 1600
+
+// TwilioService handles SMS notifications via the Twilio REST API
+type TwilioService struct {
+	accountSID string
+	authToken  string
+	fromNumber string
+	apiBaseURL string
+	httpClient *http.Client
+	logger     *logging.Logger
+}
+
+// NewTwilioService creates a new Twilio SMS service
+func NewTwilioService(accountSID, authToken, fromNumber string, logger *logging.Logger) *TwilioService {
+	return &TwilioService{
+		accountSID: accountSID,
+		authToken:  authToken,
+		fromNumber: fromNumber,
+		apiBaseURL: "https://api.twilio.com/2010-04-01",
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+		logger:     logger,
+	}
+}
+
+// NewTwilioServiceFromEnv creates a Twilio service using credentials from environment variables
+func NewTwilioServiceFromEnv(secretsManager *secrets.SecretsManager, logger *logging.Logger) (*TwilioService, error) {
+	accountSID, authToken, fromNumber, err := secretsManager.GetTwilioCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Twilio credentials: %w", err)
+	}
+
+	return NewTwilioService(accountSID, authToken, fromNumber, logger), nil
+}
+
+// newTwilioServiceWithFallback tries the secrets manager first and falls back to the
+// standard Twilio environment variable names. Returns nil when SMS is not configured.
+func newTwilioServiceWithFallback(secretsManager *secrets.SecretsManager, logger *logging.Logger) *TwilioService {
+	if secretsManager != nil {
+		twilioService, err := NewTwilioServiceFromEnv(secretsManager, logger)
+		if err == nil {
+			logger.Info("Using Twilio credentials from the secrets store")
+			return twilioService
+		}
+		logger.Warn("Failed to create Twilio service from secrets, trying environment variables", map[string]interface{}{"error": err.Error()})
+	}
+
+	accountSID := os.Getenv("TWILIO_ACCOUNT_SID")
+	authToken := os.Getenv("TWILIO_AUTH_TOKEN")
+	fromNumber := os.Getenv("TWILIO_PHONE_NUMBER")
+	if accountSID == "" || authToken == "" || fromNumber == "" {
+		logger.Warn("Twilio credentials not configured, SMS notifications disabled")
+		return nil
+	}
+
+	logger.Info("Using Twilio credentials from environment variables")
+	return NewTwilioService(accountSID, authToken, fromNumber, logger)
+}
+
+// Send implements NotificationChannel by sending an SMS to the given phone number
+func (t *TwilioService) Send(toAddress, courtDetails, bookingLink string) error {
+	return t.SendCourtAvailabilitySMS(toAddress, courtDetails, bookingLink)
+}
+
+// SendCourtAvailabilitySMS sends a court availability alert as an SMS
+func (t *TwilioService) SendCourtAvailabilitySMS(toNumber, courtDetails, bookingLink string) error {
+	body := courtDetails
+	if bookingLink != "" && !strings.Contains(courtDetails, bookingLink) {
+		body = fmt.Sprintf("%s\nBook: %s", courtDetails, bookingLink)
+	}
+
+	return t.sendSMS(toNumber, truncateSMS(sanitizeSMSText(body)))
+}
+
+func (t *TwilioService) sendSMS(toNumber, body string) error {
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", t.apiBaseURL, t.accountSID)
+
+	form := url.Values{}
+	form.Set("To", toNumber)
+	form.Set("From", t.fromNumber)
+	form.Set("Body", body)
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create Twilio request: %w", err)
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		t.logger.Error("Failed to send SMS", map[string]interface{}{"phone": toNumber, "error": err.Error()})
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("twilio returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+		t.logger.Error("Failed to send SMS", map[string]interface{}{"phone": toNumber, "error": err.Error()})
+		return err
+	}
+
+	t.logger.Info("SMS sent", map[string]interface{}{"phone": toNumber})
+	return nil
+}
+
+// sanitizeSMSText strips emoji and other pictographic symbols that carriers tend to mangle
+func sanitizeSMSText(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range text {
+		switch {
+		case r > 0xFFFF, unicode.Is(unicode.So, r):
+			// Emoji and pictographs
+		case r == 0x200D, r >= 0xFE00 && r <= 0xFE0F:
+			// Zero-width joiners and variation selectors used in emoji sequences
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	// Collapse the whitespace left behind by removed emoji
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// truncateSMS trims a message so it stays under the Twilio body limit
+func truncateSMS(text string) string {
+	runes := []rune(text)
+	if len(runes) < maxSMSLength {
+		return text
+	}
+
+	const ellipsis = "..."
+	cut := maxSMSLength - 1 - len(ellipsis)
+	// Prefer cutting at a line boundary so we don't leave half a slot
+	if idx := strings.LastIndex(string(runes[:cut]), "\n"); idx > 0 {
+		return string(runes[:cut])[:idx] + "\n" + ellipsis
+	}
+	return string(runes[:cut]) + ellipsis
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeSMSText(t *testing.T) {
+	input := "🎾 2 tennis courts just became available!\n  🏟️ Central Park:\n    • Court 1: 18:00-19:00 (£12.00)"
+
+	result := sanitizeSMSText(input)
+
+	assert.Equal(t, "2 tennis courts just became available!\nCentral Park:\n• Court 1: 18:00-19:00 (£12.00)", result)
+}
+
+func TestFormatBatchedSMSDetails_StaysUnderLimit(t *testing.T) {
+	slots := make([]SlotData, 0, 100)
+	for i := 0; i < 100; i++ {
+		slots = append(slots, SlotData{
+			VenueName:  "Victoria Park Tennis Centre",
+			CourtName:  "Court 1",
+			Date:       "2024-06-15",
+			StartTime:  "18:00",
+			EndTime:    "19:00",
+			Price:      12.5,
+			BookingURL: "https://example.com/book/victoria-park/court-1",
+		})
+	}
+
+	body := formatBatchedSMSDetails(slots)
+
+	assert.Less(t, utf8.RuneCountInString(body), maxSMSLength)
+	assert.True(t, strings.HasPrefix(body, "100 tennis courts available:"))
+	assert.Contains(t, body, "more - see the app for details")
+}
+
+func TestTruncateSMS(t *testing.T) {
+	short := "Court available"
+	assert.Equal(t, short, truncateSMS(short))
+
+	long := strings.Repeat("0123456789\n", 200)
+	truncated := truncateSMS(long)
+	assert.Less(t, utf8.RuneCountInString(truncated), maxSMSLength)
+	assert.True(t, strings.HasSuffix(truncated, "\n..."))
+}
+
+func TestTwilioService_Send(t *testing.T) {
+	var gotPath, gotUser, gotPass string
+	var gotForm url.Values
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotUser, gotPass, _ = r.BasicAuth()
+		body, _ := io.ReadAll(r.Body)
+		gotForm, _ = url.ParseQuery(string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	service := NewTwilioService("AC123", "secret", "+15005550006", discardLogger())
+	service.apiBaseURL = server.URL
+
+	err := service.Send("+447700900123", "🎾 Court 1 at 18:00", "https://example.com/book")
+	require.NoError(t, err)
+
+	assert.Equal(t, "/Accounts/AC123/Messages.json", gotPath)
+	assert.Equal(t, "AC123", gotUser)
+	assert.Equal(t, "secret", gotPass)
+	assert.Equal(t, "+447700900123", gotForm.Get("To"))
+	assert.Equal(t, "+15005550006", gotForm.Get("From"))
+	assert.Equal(t, "Court 1 at 18:00\nBook: https://example.com/book", gotForm.Get("Body"))
+}
+
+func TestTwilioService_SendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code": 21211, "message": "Invalid 'To' Phone Number"}`))
+	}))
+	defer server.Close()
+
+	service := NewTwilioService("AC123", "secret", "+15005550006", discardLogger())
+	service.apiBaseURL = server.URL
+
+	err := service.Send("not-a-number", "Court 1", "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 400")
+}
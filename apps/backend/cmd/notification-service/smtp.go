@@ -0,0 +1,329 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"tennis-booker/internal/logging"
+	"tennis-booker/internal/secrets"
+)
+
+// alertFromName is the sender name alert emails come from
+const alertFromName = "Tennis Court Alerts"
+
+// TLS modes for the SMTP connection, set with SMTP_TLS_MODE
+const (
+	SMTPTLSStartTLS = "starttls" // Upgrade a plain connection with STARTTLS, usually on port 587
+	SMTPTLSImplicit = "implicit" // TLS from the first byte, usually on port 465
+	SMTPTLSNone     = "none"     // No TLS, only for relays on a trusted network
+)
+
+// Authentication mechanisms, set with SMTP_AUTH
+const (
+	SMTPAuthPlain = "plain"
+	SMTPAuthLogin = "login" // For servers such as Office 365 that don't offer PLAIN
+	SMTPAuthNone  = "none"  // For relays that accept mail without credentials
+)
+
+// smtpTimeout bounds connecting to the server and each message sent over the connection
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig is how SMTPService reaches and signs in to the mail server
+type SMTPConfig struct {
+	Host      string
+	Port      string
+	Username  string // Defaults to FromEmail; SendGrid, for one, expects "apikey"
+	Password  string
+	FromEmail string
+	FromName  string
+	TLSMode   string // SMTPTLSStartTLS, SMTPTLSImplicit or SMTPTLSNone; defaults by port
+	Auth      string // SMTPAuthPlain, SMTPAuthLogin or SMTPAuthNone; defaults to PLAIN
+}
+
+// GmailSMTPConfig is the preset for sending through Gmail with an app password
+func GmailSMTPConfig(email, password, fromName string) SMTPConfig {
+	return SMTPConfig{
+		Host:      "smtp.gmail.com",
+		Port:      "587",
+		Password:  password,
+		FromEmail: email,
+		FromName:  fromName,
+		TLSMode:   SMTPTLSStartTLS,
+		Auth:      SMTPAuthPlain,
+	}
+}
+
+// withDefaults fills in the username, TLS mode and auth mechanism when unset.
+// Port 465 is implicit TLS by convention; anything else starts plain and
+// upgrades with STARTTLS.
+func (c SMTPConfig) withDefaults() SMTPConfig {
+	if c.Username == "" {
+		c.Username = c.FromEmail
+	}
+	if c.TLSMode == "" {
+		c.TLSMode = SMTPTLSStartTLS
+		if c.Port == "465" {
+			c.TLSMode = SMTPTLSImplicit
+		}
+	}
+	if c.Auth == "" {
+		c.Auth = SMTPAuthPlain
+	}
+	return c
+}
+
+// validate checks the TLS mode and auth mechanism are ones SMTPService supports
+func (c SMTPConfig) validate() error {
+	switch c.TLSMode {
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return fmt.Errorf("unsupported SMTP TLS mode %q", c.TLSMode)
+	}
+	switch c.Auth {
+	case SMTPAuthPlain, SMTPAuthLogin, SMTPAuthNone:
+	default:
+		return fmt.Errorf("unsupported SMTP auth mechanism %q", c.Auth)
+	}
+	if c.Host == "" || c.Port == "" {
+		return errors.New("SMTP host and port are required")
+	}
+	return nil
+}
+
+// SMTPService sends email notifications through any SMTP server
+type SMTPService struct {
+	config SMTPConfig
+	logger *logging.Logger
+
+	// Retry policy for transient SMTP failures
+	maxAttempts    int
+	retryBaseDelay time.Duration
+
+	// redisClient receives notifications that still fail after all retries (optional)
+	redisClient *redis.Client
+
+	// sendMail is swapped out in tests
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+
+	// batch is the connection reused between BeginBatch and EndBatch
+	batch smtpBatch
+
+	// rateLimiter paces sends to the provider's quotas (optional)
+	rateLimiter *emailRateLimiter
+
+	// sendCtx interrupts rate limit waits when cancelSends is called
+	sendCtx     context.Context
+	cancelSends context.CancelFunc
+}
+
+// NewSMTPService creates an SMTP service from the config, filling in defaults
+// for anything unset. It doesn't connect until the first email is sent.
+func NewSMTPService(config SMTPConfig, logger *logging.Logger) (*SMTPService, error) {
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return newSMTPService(config, logger), nil
+}
+
+// NewGmailService creates an SMTP service using the Gmail preset
+func NewGmailService(email, password, fromName string, logger *logging.Logger) *SMTPService {
+	return newSMTPService(GmailSMTPConfig(email, password, fromName).withDefaults(), logger)
+}
+
+// newSMTPService creates an SMTP service from a complete, valid config
+func newSMTPService(config SMTPConfig, logger *logging.Logger) *SMTPService {
+	g := &SMTPService{
+		config:         config,
+		logger:         logger,
+		maxAttempts:    defaultSMTPMaxAttempts,
+		retryBaseDelay: defaultSMTPRetryBaseDelay,
+	}
+	g.sendMail = g.sendSMTP
+	g.sendCtx, g.cancelSends = context.WithCancel(context.Background())
+	return g
+}
+
+// NewSMTPServiceFromEnv creates an SMTP service from the email credentials in
+// the secrets store. The host and port default to Gmail's; SMTP_USERNAME,
+// SMTP_TLS_MODE and SMTP_AUTH configure other providers and relays.
+func NewSMTPServiceFromEnv(secretsManager *secrets.SecretsManager, logger *logging.Logger) (*SMTPService, error) {
+	email, password, smtpHost, smtpPort, err := secretsManager.GetEmailCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get email credentials: %w", err)
+	}
+
+	config := SMTPConfig{
+		Host:      smtpHost,
+		Port:      smtpPort,
+		Username:  os.Getenv("SMTP_USERNAME"),
+		Password:  password,
+		FromEmail: email,
+		FromName:  alertFromName,
+		TLSMode:   strings.ToLower(os.Getenv("SMTP_TLS_MODE")),
+		Auth:      strings.ToLower(os.Getenv("SMTP_AUTH")),
+	}
+
+	// Use defaults if not provided
+	if config.Host == "" {
+		config.Host = "smtp.gmail.com"
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+
+	return NewSMTPService(config, logger)
+}
+
+// addr returns the server's host:port
+func (g *SMTPService) addr() string {
+	return net.JoinHostPort(g.config.Host, g.config.Port)
+}
+
+// auth returns the credentials for the configured mechanism, or nil to send without authenticating
+func (g *SMTPService) auth() smtp.Auth {
+	switch g.config.Auth {
+	case SMTPAuthNone:
+		return nil
+	case SMTPAuthLogin:
+		return &loginAuth{username: g.config.Username, password: g.config.Password, host: g.config.Host}
+	default:
+		return smtp.PlainAuth("", g.config.Username, g.config.Password, g.config.Host)
+	}
+}
+
+// smtpConnection is an open SMTP connection, kept alongside its client so
+// the deadline can be extended for each message sent over it
+type smtpConnection struct {
+	conn   net.Conn
+	client *smtp.Client
+}
+
+// dial connects to the server, negotiates TLS as configured and authenticates
+func (g *SMTPService) dial(addr string, a smtp.Auth) (*smtpConnection, error) {
+	tlsConfig := &tls.Config{ServerName: g.config.Host}
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+
+	var conn net.Conn
+	var err error
+	if g.config.TLSMode == SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	client, err := smtp.NewClient(conn, g.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if g.config.TLSMode == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("smtp: server doesn't support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	if a != nil {
+		if err := client.Auth(a); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return &smtpConnection{conn: conn, client: client}, nil
+}
+
+// send sends one message over the connection, which is left ready for the next
+func (c *smtpConnection) send(from string, to []string, msg []byte) error {
+	c.conn.SetDeadline(time.Now().Add(smtpTimeout))
+
+	if err := c.client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := c.client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// sendSMTP sends one message over a new connection. It stands in for
+// smtp.SendMail, which can't use implicit TLS and only upgrades to TLS when
+// the server happens to offer it.
+func (g *SMTPService) sendSMTP(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	conn, err := g.dial(addr, a)
+	if err != nil {
+		return err
+	}
+	defer conn.client.Close()
+
+	if err := conn.send(from, to, msg); err != nil {
+		return err
+	}
+	return conn.client.Quit()
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp doesn't provide.
+// Like smtp.PlainAuth it won't send credentials over an unencrypted
+// connection to anything but localhost.
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("smtp: unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("smtp: wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("smtp: unexpected LOGIN challenge %q", fromServer)
+	}
+}
+
+// isLocalhost reports whether the SMTP server name is the local machine
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
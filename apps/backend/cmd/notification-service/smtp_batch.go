@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"net/smtp"
+	"net/textproto"
+	"sync"
+	"time"
+)
+
+// smtpBatch holds the connection shared by the emails sent during a batch,
+// with counts for measuring what reusing it saved
+type smtpBatch struct {
+	mu       sync.Mutex
+	depth    int             // Open BeginBatch calls
+	conn     *smtpConnection // Nil until the first send, and after the connection fails
+	perSend  bool            // Reconnecting failed, so the rest of the batch sends one connection per email
+	started  time.Time
+	sent     int           // Emails sent over a reused connection
+	connects int           // Connections opened for the batch
+	connTime time.Duration // Time spent connecting, negotiating TLS and authenticating
+}
+
+// batchSender is implemented by channels that can hold one connection open
+// across the sends of a batch
+type batchSender interface {
+	BeginBatch()
+	EndBatch()
+}
+
+// BeginBatch sends the emails that follow over one connection, until
+// EndBatch. Batches nest, so overlapping flushes share the connection.
+func (g *SMTPService) BeginBatch() {
+	g.batch.mu.Lock()
+	defer g.batch.mu.Unlock()
+
+	if g.batch.depth == 0 {
+		g.batch.perSend = false
+		g.batch.started = time.Now()
+		g.batch.sent, g.batch.connects, g.batch.connTime = 0, 0, 0
+	}
+	g.batch.depth++
+}
+
+// EndBatch closes the batch's connection once the outermost batch ends and
+// logs the connection setup time reusing it saved
+func (g *SMTPService) EndBatch() {
+	g.batch.mu.Lock()
+	defer g.batch.mu.Unlock()
+
+	if g.batch.depth == 0 {
+		return
+	}
+	g.batch.depth--
+	if g.batch.depth > 0 {
+		return
+	}
+
+	if g.batch.conn != nil {
+		g.batch.conn.client.Quit()
+		g.batch.conn.client.Close()
+		g.batch.conn = nil
+	}
+
+	if g.batch.sent == 0 || g.batch.connects == 0 {
+		return
+	}
+	averageConnect := g.batch.connTime / time.Duration(g.batch.connects)
+	g.logger.Info("Sent email batch over a reused SMTP connection", map[string]interface{}{
+		"emails":             g.batch.sent,
+		"connections":        g.batch.connects,
+		"duration":           time.Since(g.batch.started).String(),
+		"average_connect":    averageConnect.String(),
+		"connect_time_saved": (averageConnect * time.Duration(g.batch.sent-g.batch.connects)).String(),
+	})
+}
+
+// inBatch reports whether a batch is open
+func (g *SMTPService) inBatch() bool {
+	g.batch.mu.Lock()
+	defer g.batch.mu.Unlock()
+	return g.batch.depth > 0
+}
+
+// sendBatched sends over the batch's connection, opening it on first use.
+// The server refusing a message leaves the connection usable; a broken
+// connection is dropped and the message sent on a connection of its own,
+// with the next email reconnecting. If reconnecting fails, the rest of the
+// batch falls back to one connection per email.
+func (g *SMTPService) sendBatched(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+	g.batch.mu.Lock()
+	defer g.batch.mu.Unlock()
+
+	if g.batch.perSend || g.batch.depth == 0 {
+		return g.sendMail(addr, a, from, to, msg)
+	}
+
+	if g.batch.conn == nil {
+		started := time.Now()
+		conn, err := g.dial(addr, a)
+		if err != nil {
+			g.logger.Warn("Failed to open a reusable SMTP connection, sending the rest of the batch one connection per email", map[string]interface{}{"error": err.Error()})
+			g.batch.perSend = true
+			return g.sendMail(addr, a, from, to, msg)
+		}
+		g.batch.conn = conn
+		g.batch.connects++
+		g.batch.connTime += time.Since(started)
+	}
+
+	err := g.batch.conn.send(from, to, msg)
+	if err == nil {
+		g.batch.sent++
+		return nil
+	}
+
+	var reply *textproto.Error
+	if errors.As(err, &reply) && g.batch.conn.client.Reset() == nil {
+		return err
+	}
+
+	g.logger.Warn("Reusable SMTP connection failed, sending on a new connection", map[string]interface{}{"error": err.Error()})
+	g.batch.conn.client.Close()
+	g.batch.conn = nil
+	return g.sendMail(addr, a, from, to, msg)
+}
+
+// beginEmailBatch lets the email channel reuse one connection for the sends
+// that follow. Call the returned function once they're done.
+func (s *NotificationService) beginEmailBatch() func() {
+	channel, ok := s.channels[ChannelEmail].(batchSender)
+	if !ok {
+		return func() {}
+	}
+	channel.BeginBatch()
+	return channel.EndBatch
+}
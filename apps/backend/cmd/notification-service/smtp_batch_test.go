@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/smtp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBatchTestSMTPService(t *testing.T, server *fakeSMTPServer) *SMTPService {
+	g, err := NewSMTPService(server.config(), discardLogger())
+	require.NoError(t, err)
+	g.SetRetryPolicy(1, 0)
+	return g
+}
+
+func sendTestEmails(t *testing.T, g *SMTPService, count int) {
+	for i := 0; i < count; i++ {
+		require.NoError(t, g.sendEmail("player@example.com", "Court available", "Court 1 at 18:00"))
+	}
+}
+
+func TestSMTPService_BatchReusesConnection(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	g := newBatchTestSMTPService(t, server)
+
+	g.BeginBatch()
+	sendTestEmails(t, g, 3)
+	g.EndBatch()
+
+	commands, messages, logins := server.received()
+	assert.Equal(t, 1, server.connectionCount())
+	assert.Len(t, messages, 3)
+	assert.Len(t, logins, 1, "authenticated once for the whole batch")
+	assert.Equal(t, "QUIT", commands[len(commands)-1], "the connection is closed when the batch ends")
+}
+
+func TestSMTPService_SendsPerEmailOutsideBatch(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	g := newBatchTestSMTPService(t, server)
+
+	sendTestEmails(t, g, 3)
+
+	_, messages, _ := server.received()
+	assert.Equal(t, 3, server.connectionCount())
+	assert.Len(t, messages, 3)
+}
+
+func TestSMTPService_BatchReconnectsAfterConnectionDrops(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	server.dropAfter = 2
+	g := newBatchTestSMTPService(t, server)
+
+	g.BeginBatch()
+	sendTestEmails(t, g, 4)
+	g.EndBatch()
+
+	_, messages, _ := server.received()
+	assert.Len(t, messages, 4, "the email that hit the dropped connection went out on its own")
+	assert.Equal(t, 3, server.connectionCount(), "batch connection, one-off resend, then a new batch connection")
+}
+
+func TestSMTPService_BatchFallsBackWhenConnectFails(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	g := newBatchTestSMTPService(t, server)
+	config := server.config()
+	config.Port = "1"
+	g.config = config.withDefaults()
+
+	sent := 0
+	g.sendMail = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		sent++
+		return nil
+	}
+
+	g.BeginBatch()
+	sendTestEmails(t, g, 2)
+	g.EndBatch()
+
+	assert.Equal(t, 2, sent, "each email is sent on its own once the batch connection can't be opened")
+	assert.False(t, g.inBatch())
+}
+
+func TestSMTPService_NestedBatches(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	g := newBatchTestSMTPService(t, server)
+
+	g.BeginBatch()
+	g.BeginBatch()
+	sendTestEmails(t, g, 1)
+	g.EndBatch()
+	sendTestEmails(t, g, 1)
+	g.EndBatch()
+	g.EndBatch() // Unbalanced ends are ignored
+
+	assert.Equal(t, 1, server.connectionCount())
+	assert.False(t, g.inBatch())
+}
+
+// recordingBatchChannel records batches opened on the email channel
+type recordingBatchChannel struct {
+	*recordingChannel
+	begun, ended int
+}
+
+func (c *recordingBatchChannel) BeginBatch() { c.begun++ }
+func (c *recordingBatchChannel) EndBatch()   { c.ended++ }
+
+func TestFlushBatchedNotifications_BatchesEmailConnection(t *testing.T) {
+	s := newTestNotificationService()
+	channel := &recordingBatchChannel{recordingChannel: newRecordingChannel()}
+	s.registerChannel(ChannelEmail, channel)
+	s.users = []User{
+		{Email: "a@example.com", EmailEnabled: true},
+		{Email: "b@example.com", EmailEnabled: true},
+	}
+	s.slotBatch = map[string][]SlotData{
+		"a@example.com": testAlertSlots(),
+		"b@example.com": testAlertSlots(),
+	}
+
+	s.flushBatchedNotifications()
+	assert.Equal(t, 1, channel.begun)
+	assert.Equal(t, 1, channel.ended)
+
+	s.slotBatch = map[string][]SlotData{"a@example.com": testAlertSlots()}
+	s.flushBatchedNotifications()
+	assert.Equal(t, 1, channel.begun, "a single email doesn't need a batch")
+}
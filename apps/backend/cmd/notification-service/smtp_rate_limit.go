@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"tennis-booker/internal/logging"
+)
+
+// errDailyQuotaExhausted is returned for sends over the daily cap. They fail
+// straight away and are recorded for a retry, rather than held until midnight.
+var errDailyQuotaExhausted = errors.New("daily email quota exhausted")
+
+// emailRateLimiter paces outbound email to stay inside the provider's quotas:
+// a token bucket refilling perSecond sends a second, and a cap on sends per
+// UTC day. Sends over the per-second limit wait their turn, so a large batch
+// flush spreads itself out; sends over the daily cap fail. A zero limit is
+// unlimited.
+type emailRateLimiter struct {
+	mu        sync.Mutex
+	perSecond float64
+	burst     float64 // Sends the bucket holds when full
+	tokens    float64
+	refilled  time.Time
+	perDay    int
+	day       string // UTC date sentToday counts for
+	sentToday int
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+// newEmailRateLimiter creates a limiter allowing perSecond sends a second,
+// in bursts of up to one second's worth, and perDay sends a day
+func newEmailRateLimiter(perSecond float64, perDay int) *emailRateLimiter {
+	burst := math.Max(1, math.Ceil(perSecond))
+	return &emailRateLimiter{
+		perSecond: perSecond,
+		burst:     burst,
+		tokens:    burst,
+		perDay:    perDay,
+		now:       time.Now,
+		sleep:     sleepContext,
+	}
+}
+
+// wait blocks until an email can be sent within the per-second limit and
+// counts it, returning how long the send was held back. It returns
+// errDailyQuotaExhausted without waiting once today's quota is used up, and
+// ctx's error if ctx is done first.
+func (l *emailRateLimiter) wait(ctx context.Context) (time.Duration, error) {
+	var waited time.Duration
+	for {
+		delay, err := l.reserve(l.now())
+		if err != nil {
+			return waited, err
+		}
+		if delay <= 0 {
+			return waited, nil
+		}
+		if err := l.sleep(ctx, delay); err != nil {
+			return waited, err
+		}
+		waited += delay
+	}
+}
+
+// reserve takes a send from the bucket and today's quota when both have one
+// to spare, returning 0, or returns how long to wait before trying again.
+// It returns errDailyQuotaExhausted when today's quota is used up.
+func (l *emailRateLimiter) reserve(now time.Time) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(now)
+
+	if l.perDay > 0 && l.sentToday >= l.perDay {
+		return 0, errDailyQuotaExhausted
+	}
+	if l.perSecond > 0 && l.tokens < 1 {
+		return time.Duration((1 - l.tokens) / l.perSecond * float64(time.Second)), nil
+	}
+
+	if l.perSecond > 0 {
+		l.tokens--
+	}
+	l.sentToday++
+	return 0, nil
+}
+
+// sleepContext sleeps for d, returning ctx's error if ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// advance refills the bucket for the time since the last refill and starts a
+// new daily count at midnight UTC
+func (l *emailRateLimiter) advance(now time.Time) {
+	if today := now.UTC().Format("2006-01-02"); today != l.day {
+		l.day = today
+		l.sentToday = 0
+	}
+
+	if !l.refilled.IsZero() && l.perSecond > 0 {
+		l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.refilled).Seconds()*l.perSecond)
+	}
+	l.refilled = now
+}
+
+// remainingToday returns how many more emails can be sent today, and false
+// when there's no daily cap
+func (l *emailRateLimiter) remainingToday() (int, bool) {
+	if l == nil || l.perDay <= 0 {
+		return 0, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.advance(l.now())
+	return l.perDay - l.sentToday, true
+}
+
+// SetRateLimit paces sends to perSecond a second and perDay a day; zero leaves either unlimited
+func (g *SMTPService) SetRateLimit(perSecond float64, perDay int) {
+	if perSecond <= 0 && perDay <= 0 {
+		g.rateLimiter = nil
+		return
+	}
+	g.rateLimiter = newEmailRateLimiter(math.Max(0, perSecond), max(0, perDay))
+}
+
+// RemainingDailyQuota returns how many more emails can be sent today, and
+// false when sends aren't capped per day
+func (g *SMTPService) RemainingDailyQuota() (int, bool) {
+	return g.rateLimiter.remainingToday()
+}
+
+// waitForRateLimit holds a send back until it fits within the rate limits. It
+// fails once the daily quota is used up or CancelPendingSends is called.
+func (g *SMTPService) waitForRateLimit(toEmail string) error {
+	if g.rateLimiter == nil {
+		return nil
+	}
+
+	waited, err := g.rateLimiter.wait(g.sendContext())
+	if waited >= time.Second {
+		g.logger.Info("Held email back to stay within the sending quota", map[string]interface{}{"user_email": toEmail, "waited": waited.String()})
+	}
+	return err
+}
+
+// sendContext is done once CancelPendingSends is called
+func (g *SMTPService) sendContext() context.Context {
+	if g.sendCtx == nil {
+		return context.Background()
+	}
+	return g.sendCtx
+}
+
+// CancelPendingSends stops sends waiting on the rate limit, which then fail and
+// are recorded as failed notifications. Shutdown calls it once its timeout expires.
+func (g *SMTPService) CancelPendingSends() {
+	if g.cancelSends != nil {
+		g.cancelSends()
+	}
+}
+
+// configureEmailRateLimit applies SMTP_MAX_PER_SECOND and SMTP_MAX_PER_DAY,
+// which should be set to the provider's quotas, e.g. 500 a day for Gmail
+func configureEmailRateLimit(emailService *SMTPService, logger *logging.Logger) {
+	var perSecond float64
+	if value := os.Getenv("SMTP_MAX_PER_SECOND"); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed >= 0 {
+			perSecond = parsed
+		} else {
+			logger.Warn("Invalid SMTP_MAX_PER_SECOND, sending without a per-second limit", map[string]interface{}{"value": value})
+		}
+	}
+
+	var perDay int
+	if value := os.Getenv("SMTP_MAX_PER_DAY"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil && parsed >= 0 {
+			perDay = parsed
+		} else {
+			logger.Warn("Invalid SMTP_MAX_PER_DAY, sending without a daily limit", map[string]interface{}{"value": value})
+		}
+	}
+
+	emailService.SetRateLimit(perSecond, perDay)
+	if perSecond > 0 || perDay > 0 {
+		logger.Info("Email sending rate limited", map[string]interface{}{"per_second": perSecond, "per_day": perDay})
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeClockRateLimiter returns a limiter whose sleeps advance a fake clock
+// instead of blocking, along with the clock
+func newFakeClockRateLimiter(perSecond float64, perDay int, start time.Time) (*emailRateLimiter, *time.Time) {
+	now := start
+	l := newEmailRateLimiter(perSecond, perDay)
+	l.now = func() time.Time { return now }
+	l.sleep = func(ctx context.Context, d time.Duration) error {
+		now = now.Add(d)
+		return nil
+	}
+	return l, &now
+}
+
+func TestEmailRateLimiter_PacesPerSecond(t *testing.T) {
+	start := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	l, now := newFakeClockRateLimiter(2, 0, start)
+
+	for i := 0; i < 6; i++ {
+		_, err := l.wait(context.Background())
+		require.NoError(t, err)
+	}
+
+	// A burst of 2, then one every half second
+	assert.Equal(t, 2*time.Second, now.Sub(start))
+}
+
+func TestEmailRateLimiter_FailsFastWhenDailyCapReached(t *testing.T) {
+	start := time.Date(2024, 6, 15, 23, 0, 0, 0, time.UTC)
+	l, now := newFakeClockRateLimiter(0, 3, start)
+
+	for i := 0; i < 3; i++ {
+		waited, err := l.wait(context.Background())
+		require.NoError(t, err)
+		assert.Zero(t, waited)
+	}
+	remaining, capped := l.remainingToday()
+	assert.True(t, capped)
+	assert.Equal(t, 0, remaining)
+
+	waited, err := l.wait(context.Background())
+	assert.ErrorIs(t, err, errDailyQuotaExhausted)
+	assert.Zero(t, waited, "the fourth send doesn't wait for midnight UTC")
+	assert.Equal(t, start, *now)
+
+	*now = time.Date(2024, 6, 16, 0, 0, 0, 0, time.UTC)
+	_, err = l.wait(context.Background())
+	require.NoError(t, err, "the quota resets at midnight UTC")
+	remaining, _ = l.remainingToday()
+	assert.Equal(t, 2, remaining)
+}
+
+func TestEmailRateLimiter_WaitStopsWhenContextCancelled(t *testing.T) {
+	l := newEmailRateLimiter(0.001, 0) // One send, then about 17 minutes until the next
+
+	_, err := l.wait(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = l.wait(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSMTPService_FailsSendsOverDailyQuota(t *testing.T) {
+	g := NewGmailService("alerts@example.com", "app-password", alertFromName, discardLogger())
+	g.SetRetryPolicy(1, 0)
+	sent := 0
+	g.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sent++
+		return nil
+	}
+	g.SetRateLimit(0, 1)
+
+	require.NoError(t, g.sendEmail("player@example.com", "Court available", "Court 1 at 18:00"))
+
+	start := time.Now()
+	err := g.sendEmail("player@example.com", "Court available", "Court 2 at 18:00")
+	assert.ErrorIs(t, err, errDailyQuotaExhausted)
+	assert.True(t, isTransientSMTPError(err), "recorded as retryable")
+	assert.Less(t, time.Since(start), time.Second, "doesn't wait for midnight")
+	assert.Equal(t, 1, sent)
+}
+
+func TestSMTPService_CancelPendingSendsInterruptsRateLimitWait(t *testing.T) {
+	g := NewGmailService("alerts@example.com", "app-password", alertFromName, discardLogger())
+	g.SetRetryPolicy(1, 0)
+	g.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error { return nil }
+	g.SetRateLimit(0.001, 0)
+	require.NoError(t, g.sendEmail("player@example.com", "Court available", "Court 1 at 18:00"))
+
+	done := make(chan error, 1)
+	go func() { done <- g.sendEmail("player@example.com", "Court available", "Court 2 at 18:00") }()
+
+	g.CancelPendingSends()
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("send still waiting on the rate limit after CancelPendingSends")
+	}
+}
+
+func TestEmailRateLimiter_UncappedReportsNoQuota(t *testing.T) {
+	var l *emailRateLimiter
+	_, capped := l.remainingToday()
+	assert.False(t, capped)
+
+	_, capped = newEmailRateLimiter(5, 0).remainingToday()
+	assert.False(t, capped)
+}
+
+func TestSMTPService_SendsWithinRateLimit(t *testing.T) {
+	g := NewGmailService("alerts@example.com", "app-password", alertFromName, discardLogger())
+	g.SetRetryPolicy(1, 0)
+	sent := 0
+	g.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sent++
+		return nil
+	}
+
+	g.SetRateLimit(0, 5)
+	start := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	g.rateLimiter.now = func() time.Time { return start }
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, g.sendEmail("player@example.com", "Court available", "Court 1 at 18:00"))
+	}
+
+	assert.Equal(t, 2, sent)
+	remaining, capped := g.RemainingDailyQuota()
+	assert.True(t, capped)
+	assert.Equal(t, 3, remaining)
+
+	g.SetRateLimit(0, 0)
+	assert.Nil(t, g.rateLimiter, "no limits means no limiter")
+}
+
+func TestMetricsHandler_ExposesDailyEmailQuota(t *testing.T) {
+	s := newTestNotificationService()
+	g := NewGmailService("alerts@example.com", "app-password", alertFromName, discardLogger())
+	g.SetRateLimit(1, 500)
+	s.registerChannel(ChannelEmail, g)
+
+	rec := httptest.NewRecorder()
+	s.metricsHandler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	assert.Contains(t, body, "# TYPE notification_email_daily_quota_remaining gauge")
+	assert.Contains(t, body, "notification_email_daily_quota_remaining 500\n")
+}
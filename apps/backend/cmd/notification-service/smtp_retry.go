@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/textproto"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Default SMTP retry policy: 3 attempts with 1s, 2s backoff between them
+const (
+	defaultSMTPMaxAttempts    = 3
+	defaultSMTPRetryBaseDelay = 1 * time.Second
+)
+
+// failedNotificationsQueue is the Redis list that holds notifications that could not be delivered
+const failedNotificationsQueue = "failed_notifications"
+
+// FailedNotification is the payload pushed to Redis when an email exhausts its retries
+type FailedNotification struct {
+	Channel   string    `json:"channel"`
+	To        string    `json:"to"`
+	Message   string    `json:"message"`
+	Error     string    `json:"error"`
+	Attempts  int       `json:"attempts"`
+	Permanent bool      `json:"permanent"`
+	FailedAt  time.Time `json:"failedAt"`
+}
+
+// isTransientSMTPError reports whether an SMTP error is worth retrying.
+// 4xx replies (rate limiting, mailbox busy) and network failures are transient;
+// 5xx replies such as unknown recipient or bad credentials are permanent.
+// Sends refused by our own quota or cancelled at shutdown are transient too.
+func isTransientSMTPError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, errDailyQuotaExhausted) || errors.Is(err, context.Canceled) {
+		return true
+	}
+
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryBackoff returns the delay before the given retry (1-based): base, 2*base, 4*base...
+func retryBackoff(base time.Duration, retry int) time.Duration {
+	if retry < 1 {
+		return 0
+	}
+	return base * time.Duration(1<<(retry-1))
+}
+
+// SetRetryPolicy overrides the number of send attempts and the base backoff delay
+func (g *SMTPService) SetRetryPolicy(maxAttempts int, baseDelay time.Duration) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	if baseDelay < 0 {
+		baseDelay = 0
+	}
+	g.maxAttempts = maxAttempts
+	g.retryBaseDelay = baseDelay
+}
+
+// SetFailedNotificationQueue enables recording undeliverable emails in Redis
+func (g *SMTPService) SetFailedNotificationQueue(redisClient *redis.Client) {
+	g.redisClient = redisClient
+}
+
+// sendWithRetry sends the message, retrying transient failures with exponential backoff
+func (g *SMTPService) sendWithRetry(addr, toEmail string, msg []byte) (int, error) {
+	maxAttempts := g.maxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	send := g.sendMail
+	if g.inBatch() {
+		send = g.sendBatched
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		err = send(addr, g.auth(), g.config.FromEmail, []string{toEmail}, msg)
+		if err == nil {
+			return attempt, nil
+		}
+
+		if !isTransientSMTPError(err) {
+			g.logger.Error("Permanent SMTP failure", map[string]interface{}{"user_email": toEmail, "error": err.Error()})
+			return attempt, err
+		}
+
+		if attempt < maxAttempts {
+			delay := retryBackoff(g.retryBaseDelay, attempt)
+			g.logger.Warn("Transient SMTP failure, retrying", map[string]interface{}{
+				"user_email":   toEmail,
+				"attempt":      attempt,
+				"max_attempts": maxAttempts,
+				"retry_in":     delay.String(),
+				"error":        err.Error(),
+			})
+			time.Sleep(delay)
+		}
+	}
+
+	return maxAttempts, err
+}
+
+// recordFailedNotification pushes an undeliverable message to the failed_notifications list
+func (g *SMTPService) recordFailedNotification(toEmail string, msg []byte, attempts int, sendErr error) {
+	if g.redisClient == nil {
+		return
+	}
+
+	payload, err := json.Marshal(FailedNotification{
+		Channel:   ChannelEmail,
+		To:        toEmail,
+		Message:   string(msg),
+		Error:     sendErr.Error(),
+		Attempts:  attempts,
+		Permanent: !isTransientSMTPError(sendErr),
+		FailedAt:  time.Now(),
+	})
+	if err != nil {
+		g.logger.Error("Failed to marshal failed notification", map[string]interface{}{"user_email": toEmail, "error": err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := g.redisClient.LPush(ctx, failedNotificationsQueue, payload).Err(); err != nil {
+		g.logger.Error("Failed to record failed notification", map[string]interface{}{"user_email": toEmail, "error": err.Error()})
+		return
+	}
+
+	g.logger.Warn("Recorded failed notification", map[string]interface{}{"user_email": toEmail, "queue": failedNotificationsQueue})
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsTransientSMTPError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		transient bool
+	}{
+		{"nil", nil, false},
+		{"rate limited", &textproto.Error{Code: 421, Msg: "4.7.0 Try again later"}, true},
+		{"mailbox busy", &textproto.Error{Code: 450, Msg: "Mailbox unavailable"}, true},
+		{"unknown recipient", &textproto.Error{Code: 550, Msg: "5.1.1 User unknown"}, false},
+		{"auth failure", &textproto.Error{Code: 535, Msg: "5.7.8 Bad credentials"}, false},
+		{"wrapped 4xx", fmt.Errorf("send: %w", &textproto.Error{Code: 451}), true},
+		{"network error", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"connection dropped", io.EOF, true},
+		{"other", errors.New("something else"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.transient, isTransientSMTPError(tt.err))
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, retryBackoff(time.Second, 1))
+	assert.Equal(t, 2*time.Second, retryBackoff(time.Second, 2))
+	assert.Equal(t, 4*time.Second, retryBackoff(time.Second, 3))
+	assert.Equal(t, time.Duration(0), retryBackoff(time.Second, 0))
+}
+
+func newTestSMTPService(sendMail func(string, smtp.Auth, string, []string, []byte) error) *SMTPService {
+	g := NewGmailService("alerts@example.com", "secret", "Tennis Court Alerts", discardLogger())
+	g.SetRetryPolicy(3, time.Millisecond)
+	g.sendMail = sendMail
+	return g
+}
+
+func TestSMTPService_RetriesTransientFailures(t *testing.T) {
+	calls := 0
+	g := newTestSMTPService(func(string, smtp.Auth, string, []string, []byte) error {
+		calls++
+		if calls < 3 {
+			return &textproto.Error{Code: 421, Msg: "Try again later"}
+		}
+		return nil
+	})
+
+	err := g.deliver("user@example.com", []byte("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestSMTPService_DoesNotRetryPermanentFailures(t *testing.T) {
+	calls := 0
+	g := newTestSMTPService(func(string, smtp.Auth, string, []string, []byte) error {
+		calls++
+		return &textproto.Error{Code: 550, Msg: "User unknown"}
+	})
+
+	err := g.deliver("nobody@example.com", []byte("hello"))
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestSMTPService_GivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	g := newTestSMTPService(func(string, smtp.Auth, string, []string, []byte) error {
+		calls++
+		return &textproto.Error{Code: 421, Msg: "Try again later"}
+	})
+
+	err := g.deliver("user@example.com", []byte("hello"))
+	require.Error(t, err)
+	assert.Equal(t, 3, calls)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"net"
+	"net/smtp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer is a plain-text SMTP server that accepts every message,
+// recording the commands and messages it receives
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	commands    []string
+	messages    []string
+	logins      []string // "username:password" from AUTH LOGIN
+	connections int
+	dropAfter   int // Hang up after this many messages on a connection, when set
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+// config returns an SMTP config pointing at the server, without TLS
+func (f *fakeSMTPServer) config() SMTPConfig {
+	host, port, _ := net.SplitHostPort(f.listener.Addr().String())
+	return SMTPConfig{
+		Host:      host,
+		Port:      port,
+		Username:  "relay-user",
+		Password:  "secret",
+		FromEmail: "alerts@example.com",
+		FromName:  alertFromName,
+		TLSMode:   SMTPTLSNone,
+		Auth:      SMTPAuthLogin,
+	}
+}
+
+func (f *fakeSMTPServer) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.connections++
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	readLine := func() (string, bool) {
+		line, err := reader.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err == nil
+	}
+	decode := func(line string) string {
+		decoded, _ := base64.StdEncoding.DecodeString(line)
+		return string(decoded)
+	}
+
+	reply("220 fake.example.com ESMTP")
+	delivered := 0
+	for {
+		line, ok := readLine()
+		if !ok {
+			return
+		}
+		verb := strings.ToUpper(strings.Fields(line + " x")[0])
+		f.mu.Lock()
+		f.commands = append(f.commands, verb)
+		f.mu.Unlock()
+
+		switch verb {
+		case "EHLO":
+			reply("250-fake.example.com")
+			reply("250-PIPELINING")
+			reply("250 AUTH PLAIN LOGIN")
+		case "AUTH":
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+			username, _ := readLine()
+			reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+			password, _ := readLine()
+			f.mu.Lock()
+			f.logins = append(f.logins, decode(username)+":"+decode(password))
+			f.mu.Unlock()
+			reply("235 Authentication successful")
+		case "DATA":
+			reply("354 Go ahead")
+			var message strings.Builder
+			for {
+				dataLine, ok := readLine()
+				if !ok || dataLine == "." {
+					break
+				}
+				message.WriteString(dataLine + "\n")
+			}
+			f.mu.Lock()
+			f.messages = append(f.messages, message.String())
+			dropAfter := f.dropAfter
+			f.mu.Unlock()
+			reply("250 Queued")
+			if delivered++; delivered == dropAfter {
+				return
+			}
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (f *fakeSMTPServer) connectionCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connections
+}
+
+func (f *fakeSMTPServer) received() (commands, messages, logins []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.commands...), append([]string(nil), f.messages...), append([]string(nil), f.logins...)
+}
+
+func TestSMTPConfig_Defaults(t *testing.T) {
+	submission := SMTPConfig{Host: "smtp.sendgrid.net", Port: "587", FromEmail: "alerts@example.com"}.withDefaults()
+	assert.Equal(t, SMTPTLSStartTLS, submission.TLSMode)
+	assert.Equal(t, SMTPAuthPlain, submission.Auth)
+	assert.Equal(t, "alerts@example.com", submission.Username, "the username defaults to the from address")
+
+	smtps := SMTPConfig{Host: "smtp.mailgun.org", Port: "465", Username: "postmaster@mg.example.com"}.withDefaults()
+	assert.Equal(t, SMTPTLSImplicit, smtps.TLSMode, "port 465 is implicit TLS")
+	assert.Equal(t, "postmaster@mg.example.com", smtps.Username)
+
+	gmail := GmailSMTPConfig("alerts@example.com", "app-password", alertFromName)
+	assert.Equal(t, "smtp.gmail.com", gmail.Host)
+	assert.Equal(t, SMTPTLSStartTLS, gmail.TLSMode)
+}
+
+func TestNewSMTPService_RejectsUnsupportedSettings(t *testing.T) {
+	_, err := NewSMTPService(SMTPConfig{Host: "relay", Port: "25", TLSMode: "ssl"}, discardLogger())
+	assert.ErrorContains(t, err, "TLS mode")
+
+	_, err = NewSMTPService(SMTPConfig{Host: "relay", Port: "25", Auth: "cram-md5"}, discardLogger())
+	assert.ErrorContains(t, err, "auth mechanism")
+
+	_, err = NewSMTPService(SMTPConfig{Port: "25"}, discardLogger())
+	assert.Error(t, err)
+}
+
+func TestSMTPService_SendsWithLoginAuth(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	g, err := NewSMTPService(server.config(), discardLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, g.sendEmail("player@example.com", "Court available", "Court 1 at 18:00"))
+
+	commands, messages, logins := server.received()
+	assert.Equal(t, []string{"EHLO", "AUTH", "MAIL", "RCPT", "DATA", "QUIT"}, commands)
+	assert.Equal(t, []string{"relay-user:secret"}, logins)
+	require.Len(t, messages, 1)
+	assert.Contains(t, messages[0], "Subject: Court available")
+}
+
+func TestSMTPService_SendsWithoutAuth(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	config := server.config()
+	config.Auth = SMTPAuthNone
+	g, err := NewSMTPService(config, discardLogger())
+	require.NoError(t, err)
+
+	require.NoError(t, g.sendEmail("player@example.com", "Court available", "Court 1 at 18:00"))
+
+	commands, _, _ := server.received()
+	assert.NotContains(t, commands, "AUTH")
+}
+
+func TestSMTPService_RequiresStartTLS(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	config := server.config()
+	config.TLSMode = SMTPTLSStartTLS
+	g, err := NewSMTPService(config, discardLogger())
+	require.NoError(t, err)
+	g.SetRetryPolicy(1, 0)
+
+	err = g.sendEmail("player@example.com", "Court available", "Court 1 at 18:00")
+	assert.ErrorContains(t, err, "STARTTLS", "credentials are never sent in the clear to a server without STARTTLS")
+
+	_, messages, logins := server.received()
+	assert.Empty(t, logins)
+	assert.Empty(t, messages)
+}
+
+func TestLoginAuth(t *testing.T) {
+	auth := &loginAuth{username: "user", password: "pass", host: "smtp.example.com"}
+
+	_, _, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: false})
+	assert.ErrorContains(t, err, "unencrypted", "no credentials over plain connections to remote servers")
+
+	mechanism, _, err := auth.Start(&smtp.ServerInfo{Name: "smtp.example.com", TLS: true})
+	require.NoError(t, err)
+	assert.Equal(t, "LOGIN", mechanism)
+
+	username, err := auth.Next([]byte("Username:"), true)
+	require.NoError(t, err)
+	assert.Equal(t, "user", string(username))
+	password, err := auth.Next([]byte("Password:"), true)
+	require.NoError(t, err)
+	assert.Equal(t, "pass", string(password))
+
+	_, err = auth.Next([]byte("Token:"), true)
+	assert.Error(t, err)
+}
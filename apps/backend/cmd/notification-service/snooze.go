@@ -0,0 +1,9 @@
+package main
+
+import "time"
+
+// isSnoozed reports whether the user has paused alerts until after now. Alerts
+// resume by themselves once the snooze ends; nothing needs to clear it.
+func isSnoozed(user User, now time.Time) bool {
+	return user.SnoozeUntil != nil && user.SnoozeUntil.After(now)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSnoozed(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	assert.True(t, isSnoozed(User{SnoozeUntil: &later}, now))
+	assert.False(t, isSnoozed(User{SnoozeUntil: &earlier}, now), "alerts resume once the snooze has passed")
+	assert.False(t, isSnoozed(User{}, now))
+}
+
+func TestShouldNotifyUser_SkipsSnoozedUsers(t *testing.T) {
+	s := newTestNotificationService()
+	user := User{
+		PreferredVenues: []string{"venue123"},
+		MaxPrice:        50,
+		TimePreferences: TimePreferences{
+			WeekdaySlots: []TimeSlot{{Start: "00:00", End: "23:59"}},
+			WeekendSlots: []TimeSlot{{Start: "00:00", End: "23:59"}},
+		},
+	}
+	slot := SlotData{VenueID: "venue123", VenueName: "Victoria Park", Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 20}
+	assert.True(t, s.shouldNotifyUser(user, slot))
+
+	until := time.Now().Add(24 * time.Hour)
+	user.SnoozeUntil = &until
+	assert.False(t, s.shouldNotifyUser(user, slot))
+
+	expired := time.Now().Add(-time.Minute)
+	user.SnoozeUntil = &expired
+	assert.True(t, s.shouldNotifyUser(user, slot))
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"tennis-booker/internal/models"
+	redisevents "tennis-booker/internal/redis"
+)
+
+// runTestNotificationWorker starts sending the sample alerts users ask for
+// from POST /api/notifications/test. Shutdown waits for it like the engine.
+func (s *NotificationService) runTestNotificationWorker() {
+	s.engineWG.Add(1)
+	go func() {
+		defer s.engineWG.Done()
+		s.startTestNotificationWorker()
+	}()
+}
+
+// startTestNotificationWorker reads test notification requests from the backend
+// until shutdown, replying to each with whether the sample alert was sent
+func (s *NotificationService) startTestNotificationWorker() {
+	s.logger.Info("Starting test notification worker", map[string]interface{}{"queue": redisevents.TestNotificationQueue})
+
+	for !s.shuttingDown.Load() {
+		request, err := s.redisClient.BLPop(context.Background(), enginePollTimeout, redisevents.TestNotificationQueue).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			s.logger.Error("Failed to read from Redis queue", map[string]interface{}{"queue": redisevents.TestNotificationQueue, "error": err.Error()})
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		s.handleTestNotificationRequest(request[1], time.Now())
+	}
+}
+
+// handleTestNotificationRequest sends one test notification and pushes the
+// result to the request's reply key. Requests the backend has stopped waiting
+// for are dropped, so the user doesn't get an alert after being told it failed.
+func (s *NotificationService) handleTestNotificationRequest(payload string, now time.Time) {
+	var req models.TestNotificationRequest
+	if err := json.Unmarshal([]byte(payload), &req); err != nil || req.ID == "" {
+		s.logger.Warn("Dropping malformed test notification request", map[string]interface{}{"payload": payload})
+		return
+	}
+	if now.Unix() >= req.ExpiresAt {
+		s.logger.Warn("Dropping expired test notification request", map[string]interface{}{"request_id": req.ID, "user_id": req.UserID})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result := models.TestNotificationResult{RequestID: req.ID, Channel: req.Channel}
+	address, err := s.sendTestNotificationToUser(ctx, req.UserID, req.Channel, now)
+	result.Address = maskAddress(req.Channel, address)
+	if err != nil {
+		result.Error = err.Error()
+		s.logger.Warn("Test notification failed", map[string]interface{}{"user_id": req.UserID, "channel": req.Channel, "error": err.Error()})
+	} else {
+		result.Success = true
+		s.logger.Info("Test notification sent", map[string]interface{}{"user_id": req.UserID, "channel": req.Channel})
+	}
+
+	reply, err := json.Marshal(result)
+	if err != nil {
+		s.logger.Error("Failed to encode test notification reply", map[string]interface{}{"request_id": req.ID, "error": err.Error()})
+		return
+	}
+	replyKey := redisevents.TestNotificationReplyPrefix + req.ID
+	pipe := s.redisClient.TxPipeline()
+	pipe.RPush(ctx, replyKey, reply)
+	pipe.Expire(ctx, replyKey, models.TestNotificationTimeout)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Error("Failed to send test notification reply", map[string]interface{}{"request_id": req.ID, "error": err.Error()})
+	}
+}
+
+// sendTestNotificationToUser loads the user's current preferences and sends them
+// a sample alert on the channel, returning the address it went to
+func (s *NotificationService) sendTestNotificationToUser(ctx context.Context, userID, channel string, now time.Time) (string, error) {
+	id, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return "", fmt.Errorf("invalid user ID %q", userID)
+	}
+
+	user, err := s.loadUser(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return "", errors.New("no notification preferences saved yet")
+	}
+	if err != nil {
+		s.logger.Error("Failed to load user for test notification", map[string]interface{}{"user_id": userID, "error": err.Error()})
+		return "", errors.New("failed to load notification preferences")
+	}
+
+	return s.sendSampleAlert(user, channel, now)
+}
+
+// sendSampleAlert sends a sample alert to the address the user has configured
+// for the channel, whether or not alerts on it are enabled, since checking it
+// works is often why they'd turn it on. It isn't recorded in alert history.
+func (s *NotificationService) sendSampleAlert(user User, channelName string, now time.Time) (string, error) {
+	var address string
+	var send func(NotificationChannel, User, []SlotData) error
+	switch channelName {
+	case ChannelEmail:
+		address, send = user.Email, s.sendEmailAlert
+	case ChannelSMS:
+		address, send = user.PhoneNumber, sendSMSAlert
+	case ChannelWebhook:
+		address, send = user.WebhookURL, sendWebhookAlert
+	default:
+		return "", fmt.Errorf("unknown channel %q", channelName)
+	}
+	if address == "" {
+		return "", fmt.Errorf("no %s address configured", channelName)
+	}
+
+	channel, ok := s.channels[channelName]
+	if !ok {
+		return address, fmt.Errorf("%s alerts are not available", channelName)
+	}
+
+	start := time.Now()
+	err := send(channel, user, []SlotData{sampleAlertSlot(now)})
+	s.metrics.observeSend(channelName, time.Since(start), err)
+	if err != nil {
+		return address, fmt.Errorf("failed to send: %w", err)
+	}
+	return address, nil
+}
+
+// sampleAlertSlot is the made-up slot test notifications show: a court at a
+// clearly fictional venue tomorrow evening
+func sampleAlertSlot(now time.Time) SlotData {
+	return SlotData{
+		VenueID:    "test",
+		VenueName:  "Tennis Booker Test Club",
+		CourtID:    "test-court",
+		CourtName:  "Test Court",
+		Date:       now.In(loadLocation(defaultTimezone)).AddDate(0, 0, 1).Format("2006-01-02"),
+		StartTime:  "19:00",
+		EndTime:    "20:00",
+		Price:      15,
+		Currency:   defaultCurrency,
+		BookingURL: "https://example.com/test-booking",
+		Platform:   "test",
+		ScrapedAt:  now,
+	}
+}
+
+// maskAddress hides most of an address before it's echoed back to the client:
+// "a***@example.com", "*******4567" or a webhook's scheme and host
+func maskAddress(channel, address string) string {
+	if address == "" {
+		return ""
+	}
+
+	switch channel {
+	case ChannelEmail:
+		local, domain, ok := strings.Cut(address, "@")
+		if !ok || local == "" {
+			return "***"
+		}
+		return local[:1] + "***@" + domain
+	case ChannelSMS:
+		if len(address) <= 4 {
+			return strings.Repeat("*", len(address))
+		}
+		return strings.Repeat("*", len(address)-4) + address[len(address)-4:]
+	case ChannelWebhook:
+		parsed, err := url.Parse(address)
+		if err != nil || parsed.Host == "" {
+			return "***"
+		}
+		return parsed.Scheme + "://" + parsed.Host + "/***"
+	}
+	return "***"
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSendSampleAlert(t *testing.T) {
+	s := newTestNotificationService()
+	email := &capturingHTMLSender{}
+	other := newRecordingChannel()
+	s.registerChannel(ChannelEmail, email)
+	s.registerChannel(ChannelSMS, other)
+	s.registerChannel(ChannelWebhook, other)
+
+	now := time.Date(2024, 6, 14, 12, 0, 0, 0, time.UTC)
+	// Alerts are off on every channel; a test still goes to the configured address
+	user := User{ID: primitive.NewObjectID(), Email: "a@example.com", PhoneNumber: "+447700900123", WebhookURL: "https://hooks.example.com/tb", Language: LanguageFrench}
+
+	address, err := s.sendSampleAlert(user, ChannelEmail, now)
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com", address)
+	require.Len(t, email.slots, 1)
+	assert.Equal(t, "Tennis Booker Test Club", email.slots[0].VenueName)
+	assert.Equal(t, "2024-06-15", email.slots[0].Date)
+	assert.Equal(t, LanguageFrench, email.language, "the sample is in the user's language")
+
+	_, err = s.sendSampleAlert(user, ChannelSMS, now)
+	require.NoError(t, err)
+	assert.Len(t, other.sendTimes("+447700900123"), 1)
+
+	_, err = s.sendSampleAlert(user, ChannelWebhook, now)
+	require.NoError(t, err)
+	assert.Len(t, other.sendTimes("https://hooks.example.com/tb"), 1)
+}
+
+func TestSendSampleAlert_Errors(t *testing.T) {
+	s := newTestNotificationService()
+	s.registerChannel(ChannelEmail, failingChannel{})
+	now := time.Now()
+
+	_, err := s.sendSampleAlert(User{}, ChannelEmail, now)
+	assert.EqualError(t, err, "no email address configured")
+
+	_, err = s.sendSampleAlert(User{PhoneNumber: "+447700900123"}, ChannelSMS, now)
+	assert.EqualError(t, err, "sms alerts are not available", "SMS isn't configured on this server")
+
+	_, err = s.sendSampleAlert(User{Email: "a@example.com"}, "push", now)
+	assert.Error(t, err)
+
+	address, err := s.sendSampleAlert(User{Email: "a@example.com"}, ChannelEmail, now)
+	assert.Equal(t, "a@example.com", address)
+	assert.ErrorContains(t, err, "failed to send")
+}
+
+func TestHandleTestNotificationRequest_DropsStaleRequests(t *testing.T) {
+	s := newTestNotificationService()
+	sender := &capturingHTMLSender{}
+	s.registerChannel(ChannelEmail, sender)
+	now := time.Now()
+
+	// Neither reaches Redis or MongoDB, which the test service doesn't have
+	s.handleTestNotificationRequest(`{not json`, now)
+	s.handleTestNotificationRequest(`{"id":"r1","user_id":"`+primitive.NewObjectID().Hex()+`","channel":"email","expires_at":`+
+		strconv.FormatInt(now.Add(-time.Second).Unix(), 10)+`}`, now)
+
+	assert.Empty(t, sender.slots)
+}
+
+func TestMaskAddress(t *testing.T) {
+	assert.Equal(t, "a***@example.com", maskAddress(ChannelEmail, "alice@example.com"))
+	assert.Equal(t, "*********0123", maskAddress(ChannelSMS, "+447700900123"))
+	assert.Equal(t, "https://hooks.example.com/***", maskAddress(ChannelWebhook, "https://hooks.example.com/secret-token"))
+	assert.Equal(t, "***", maskAddress(ChannelEmail, "not-an-email"))
+	assert.Equal(t, "", maskAddress(ChannelSMS, ""))
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultTimezone is used for users without display_settings.timezone and venues without a timezone
+const defaultTimezone = "Europe/London"
+
+// locationCache avoids re-reading the tz database for every slot/user comparison
+var locationCache sync.Map // zone name -> *time.Location
+
+// loadLocation resolves an IANA zone name, falling back to the default timezone
+func loadLocation(name string) *time.Location {
+	if name == "" {
+		name = defaultTimezone
+	}
+
+	if cached, ok := locationCache.Load(name); ok {
+		return cached.(*time.Location)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		if name == defaultTimezone {
+			return time.UTC
+		}
+		return loadLocation(defaultTimezone)
+	}
+
+	locationCache.Store(name, loc)
+	return loc
+}
+
+// userLocation resolves the user's configured timezone, falling back to the default
+func userLocation(user User) *time.Location {
+	return loadLocation(user.Timezone)
+}
+
+// venueLocation resolves the timezone a slot's times are listed in
+func (s *NotificationService) venueLocation(slot SlotData) *time.Location {
+	s.usersMutex.RLock()
+	defer s.usersMutex.RUnlock()
+
+	if tz, ok := s.venueTimezones[slot.VenueID]; ok {
+		return loadLocation(tz)
+	}
+	if tz, ok := s.venueTimezones[slot.VenueName]; ok {
+		return loadLocation(tz)
+	}
+	return loadLocation(defaultTimezone)
+}
+
+// slotStartInZone converts a slot's start from venue local time into the given zone
+func slotStartInZone(slot SlotData, venueLoc, userLoc *time.Location) (time.Time, error) {
+	start, err := time.ParseInLocation("2006-01-02 15:04", slot.Date+" "+slot.StartTime, venueLoc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return start.In(userLoc), nil
+}
+
+// calculateDuration returns how long a slot lasts. Both times are read in the
+// venue's zone so a clock change mid-slot is accounted for, and an end at or
+// before the start is taken to be on the next day, e.g. 23:00-00:00.
+func calculateDuration(slot SlotData, venueLoc *time.Location) (time.Duration, error) {
+	start, err := time.ParseInLocation("2006-01-02 15:04", slot.Date+" "+slot.StartTime, venueLoc)
+	if err != nil {
+		return 0, err
+	}
+	end, err := time.ParseInLocation("2006-01-02 15:04", slot.Date+" "+slot.EndTime, venueLoc)
+	if err != nil {
+		return 0, err
+	}
+	if !end.After(start) {
+		next := start.AddDate(0, 0, 1)
+		end = time.Date(next.Year(), next.Month(), next.Day(), end.Hour(), end.Minute(), 0, 0, venueLoc)
+	}
+	return end.Sub(start), nil
+}
+
+// slotInZone returns a copy of the slot for display, with its date and times
+// converted from the venue's zone into loc and labelled with loc's abbreviation.
+// A late-night slot can move to another date. Slots whose times can't be
+// parsed are returned unchanged.
+func slotInZone(slot SlotData, venueLoc, loc *time.Location) SlotData {
+	start, err := slotStartInZone(slot, venueLoc, loc)
+	if err != nil {
+		return slot
+	}
+	if duration, err := calculateDuration(slot, venueLoc); err == nil {
+		slot.EndTime = start.Add(duration).Format("15:04")
+	}
+	slot.Date = start.Format("2006-01-02")
+	slot.StartTime = start.Format("15:04")
+	slot.TimeZone = start.Format("MST")
+	return slot
+}
+
+// slotsForDisplay converts the slots into the user's timezone for showing in an alert
+func (s *NotificationService) slotsForDisplay(user User, slots []SlotData) []SlotData {
+	userLoc := userLocation(user)
+	display := make([]SlotData, len(slots))
+	for i, slot := range slots {
+		display[i] = slotInZone(slot, s.venueLocation(slot), userLoc)
+	}
+	return display
+}
+
+// slotTimeRange renders the slot's times, with the zone abbreviation once they've been converted for display
+func slotTimeRange(slot SlotData) string {
+	times := slot.StartTime + "-" + slot.EndTime
+	if slot.TimeZone != "" {
+		times += " " + slot.TimeZone
+	}
+	return times
+}
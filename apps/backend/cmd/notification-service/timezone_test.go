@@ -0,0 +1,179 @@
+package main
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tennis-booker/internal/logging"
+)
+
+// discardLogger returns a logger that drops everything written to it
+func discardLogger() *logging.Logger {
+	logger := logging.New("notification-service-test")
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+func newTestNotificationService() *NotificationService {
+	return &NotificationService{
+		logger:    discardLogger(),
+		slotBatch: make(map[string][]SlotData),
+		channels:  make(map[string]NotificationChannel),
+	}
+}
+
+func TestSlotStartInZone(t *testing.T) {
+	london := loadLocation("Europe/London")
+	newYork := loadLocation("America/New_York")
+
+	slot := SlotData{Date: "2024-06-15", StartTime: "18:00"}
+
+	start, err := slotStartInZone(slot, london, newYork)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-06-15 13:00", start.Format("2006-01-02 15:04"))
+}
+
+func TestMatchesTimePreferences_Timezones(t *testing.T) {
+	s := newTestNotificationService()
+	london := loadLocation("Europe/London")
+	newYork := loadLocation("America/New_York")
+
+	// Saturday 18:00 at a London venue is 13:00 in New York
+	slot := SlotData{VenueName: "Victoria Park", Date: "2024-06-15", StartTime: "18:00"}
+	prefs := TimePreferences{
+		WeekendSlots: []TimeSlot{{Start: "12:00", End: "14:00"}},
+	}
+
+	assert.True(t, s.matchesTimePreferences(prefs, slot, london, newYork), "should match for a New York user")
+	assert.False(t, s.matchesTimePreferences(prefs, slot, london, london), "should not match for a London user")
+}
+
+func TestMatchesTimePreferences_DayChangesAcrossZones(t *testing.T) {
+	s := newTestNotificationService()
+	london := loadLocation("Europe/London")
+	newYork := loadLocation("America/New_York")
+
+	// Monday 02:00 in London is Sunday 21:00 in New York, so weekend preferences apply
+	slot := SlotData{Date: "2024-06-17", StartTime: "02:00"}
+	prefs := TimePreferences{
+		WeekdaySlots: []TimeSlot{{Start: "20:00", End: "22:00"}},
+		WeekendSlots: []TimeSlot{{Start: "20:00", End: "22:00"}},
+	}
+
+	assert.True(t, s.matchesTimePreferences(prefs, slot, london, newYork))
+
+	prefs.WeekendSlots = nil
+	assert.False(t, s.matchesTimePreferences(prefs, slot, london, newYork))
+}
+
+func TestMatchesTimePreferences_DSTBoundary(t *testing.T) {
+	s := newTestNotificationService()
+	london := loadLocation("Europe/London")
+
+	// UK clocks go forward on 31 March 2024 while US clocks changed on 10 March,
+	// so the London/New York offset is 4 hours on the Saturday before and 5 hours the day after
+	prefs := TimePreferences{
+		WeekendSlots: []TimeSlot{{Start: "14:00", End: "15:00"}},
+	}
+
+	before := SlotData{Date: "2024-03-30", StartTime: "18:00"}
+	after := SlotData{Date: "2024-03-31", StartTime: "18:00"}
+
+	assert.True(t, s.matchesTimePreferences(prefs, before, london, loadLocation("America/New_York")))
+	assert.False(t, s.matchesTimePreferences(prefs, after, london, loadLocation("America/New_York")))
+}
+
+func TestVenueLocation(t *testing.T) {
+	s := newTestNotificationService()
+	s.venueTimezones = map[string]string{
+		"venue123":      "Europe/Madrid",
+		"Central Court": "America/New_York",
+	}
+
+	assert.Equal(t, "Europe/Madrid", s.venueLocation(SlotData{VenueID: "venue123"}).String())
+	assert.Equal(t, "America/New_York", s.venueLocation(SlotData{VenueName: "Central Court"}).String())
+	assert.Equal(t, defaultTimezone, s.venueLocation(SlotData{VenueID: "unknown"}).String())
+}
+
+func TestLoadLocation_FallsBackToDefault(t *testing.T) {
+	assert.Equal(t, defaultTimezone, loadLocation("").String())
+	assert.Equal(t, defaultTimezone, loadLocation("Mars/Olympus_Mons").String())
+	assert.Equal(t, time.UTC.String(), loadLocation("UTC").String())
+}
+
+func TestCalculateDuration(t *testing.T) {
+	london := loadLocation("Europe/London")
+
+	tests := []struct {
+		name       string
+		date       string
+		start, end string
+		want       time.Duration
+	}{
+		{"same day", "2024-06-15", "18:00", "19:30", 90 * time.Minute},
+		{"ends at midnight", "2024-06-15", "23:00", "00:00", time.Hour},
+		{"crosses midnight", "2024-06-15", "23:30", "00:30", time.Hour},
+		{"clocks go forward mid-slot", "2024-03-31", "00:30", "02:30", time.Hour},
+		{"clocks go back mid-slot", "2024-10-27", "00:30", "02:30", 3 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := calculateDuration(SlotData{Date: tt.date, StartTime: tt.start, EndTime: tt.end}, london)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := calculateDuration(SlotData{Date: "2024-06-15", StartTime: "18:00"}, london)
+	assert.Error(t, err)
+}
+
+func TestSlotInZone_NearMidnight(t *testing.T) {
+	london := loadLocation("Europe/London")
+	newYork := loadLocation("America/New_York")
+
+	// 23:30 in London is still the evening before in New York
+	lateLondon := SlotData{Date: "2024-06-15", StartTime: "23:30", EndTime: "00:30"}
+	shown := slotInZone(lateLondon, london, newYork)
+	assert.Equal(t, "2024-06-15", shown.Date)
+	assert.Equal(t, "18:30-19:30 EDT", slotTimeRange(shown))
+
+	// 21:00 in New York is the early hours of the next day in London
+	lateNewYork := SlotData{Date: "2024-06-15", StartTime: "21:00", EndTime: "22:00"}
+	shown = slotInZone(lateNewYork, newYork, london)
+	assert.Equal(t, "2024-06-16", shown.Date)
+	assert.Equal(t, "02:00-03:00 BST", slotTimeRange(shown))
+	assert.Equal(t, "2024-06-15", lateNewYork.Date, "the original slot is left alone")
+
+	// Winter time has a different abbreviation
+	shown = slotInZone(SlotData{Date: "2024-12-14", StartTime: "23:00", EndTime: "00:00"}, london, london)
+	assert.Equal(t, "2024-12-14", shown.Date)
+	assert.Equal(t, "23:00-00:00 GMT", slotTimeRange(shown))
+
+	unparseable := SlotData{Date: "soon", StartTime: "18:00", EndTime: "19:00"}
+	assert.Equal(t, unparseable, slotInZone(unparseable, london, newYork))
+}
+
+func TestSendBatchedNotification_ShowsUserTimezone(t *testing.T) {
+	s := newTestNotificationService()
+	s.venueTimezones = map[string]string{"Victoria Park": "Europe/London"}
+	sender := &capturingHTMLSender{}
+	s.registerChannel(ChannelEmail, sender)
+
+	slots := []SlotData{{VenueName: "Victoria Park", CourtName: "Court 1", Date: "2024-06-15", StartTime: "23:30", EndTime: "00:30", Price: 12}}
+	user := User{Email: "a@example.com", EmailEnabled: true, Timezone: "Asia/Tokyo"}
+	require.NoError(t, s.sendBatchedNotification(user, slots))
+
+	require.Len(t, sender.slots, 1)
+	assert.Equal(t, "2024-06-16", sender.slots[0].Date)
+	assert.Contains(t, sender.courtDetails, "Sunday 16 June 2024")
+	assert.Contains(t, sender.courtDetails, "Court 1: 07:30-08:30 JST (£12.00)")
+
+	html, err := renderCourtAlertHTML(sender.slots, "", english)
+	require.NoError(t, err)
+	assert.Contains(t, html, "07:30-08:30 JST")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/logging"
+	"tennis-booker/internal/secrets"
+)
+
+// defaultAPIBaseURL is where the API server serves /api/notifications/unsubscribe
+const defaultAPIBaseURL = "http://localhost:8080"
+
+// unsubscribePath is the API route that handles unsubscribe links
+const unsubscribePath = "/api/notifications/unsubscribe"
+
+// envJWTSecretProvider reads the signing secret from JWT_SECRET, matching the API server's fallback
+type envJWTSecretProvider struct{}
+
+// GetJWTSecret returns JWT_SECRET or an error if it is unset
+func (envJWTSecretProvider) GetJWTSecret() (string, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return "", fmt.Errorf("JWT_SECRET environment variable is not set")
+	}
+	return secret, nil
+}
+
+// SetUnsubscribeLinks enables signed unsubscribe links pointing at the API server at baseURL
+func (s *NotificationService) SetUnsubscribeLinks(tokens *auth.UnsubscribeTokenService, baseURL string) {
+	s.unsubscribeTokens = tokens
+	s.unsubscribeBaseURL = strings.TrimRight(baseURL, "/")
+}
+
+// unsubscribeURL returns the user's one-click unsubscribe link, or "" if links are disabled
+func (s *NotificationService) unsubscribeURL(user User) string {
+	if s.unsubscribeTokens == nil || user.ID.IsZero() {
+		return ""
+	}
+
+	token, err := s.unsubscribeTokens.GenerateToken(user.ID.Hex(), auth.DefaultUnsubscribeTokenTTL)
+	if err != nil {
+		s.logger.Warn("Failed to create unsubscribe link", map[string]interface{}{"user_email": user.Email, "error": err.Error()})
+		return ""
+	}
+
+	return s.unsubscribeBaseURL + unsubscribePath + "?token=" + url.QueryEscape(token)
+}
+
+// configureUnsubscribeLinks signs links with the same secret as the API server so it can verify them
+func configureUnsubscribeLinks(service *NotificationService, secretsManager *secrets.SecretsManager, logger *logging.Logger) {
+	var provider auth.JWTSecretsProvider = envJWTSecretProvider{}
+	if secretsManager != nil {
+		provider = secretsManager
+	}
+
+	if _, err := provider.GetJWTSecret(); err != nil {
+		logger.Warn("Unsubscribe links disabled", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	service.SetUnsubscribeLinks(auth.NewUnsubscribeTokenService(provider), getEnvWithDefault("API_BASE_URL", defaultAPIBaseURL))
+}
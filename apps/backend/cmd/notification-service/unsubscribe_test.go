@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/auth"
+)
+
+type staticSecretProvider string
+
+func (p staticSecretProvider) GetJWTSecret() (string, error) { return string(p), nil }
+
+func TestUnsubscribeURL(t *testing.T) {
+	s := newTestNotificationService()
+	user := User{ID: primitive.NewObjectID(), Email: "a@example.com"}
+
+	// Disabled until configured
+	assert.Empty(t, s.unsubscribeURL(user))
+
+	tokens := auth.NewUnsubscribeTokenService(staticSecretProvider("test-secret"))
+	s.SetUnsubscribeLinks(tokens, "https://api.example.com/")
+
+	link := s.unsubscribeURL(user)
+	require.True(t, strings.HasPrefix(link, "https://api.example.com/api/notifications/unsubscribe?token="), link)
+
+	parsed, err := url.Parse(link)
+	require.NoError(t, err)
+	userID, err := tokens.ValidateToken(parsed.Query().Get("token"))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID.Hex(), userID)
+}
+
+func TestCourtAlertTextBody_UnsubscribeFooter(t *testing.T) {
+	assert.Contains(t, courtAlertTextBody("details", "https://book", "https://unsub", english), "Unsubscribe from these alerts: https://unsub")
+	assert.NotContains(t, courtAlertTextBody("details", "https://book", "", english), "Unsubscribe")
+}
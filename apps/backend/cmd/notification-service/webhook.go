@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"tennis-booker/internal/logging"
+	"tennis-booker/internal/models"
+)
+
+// ChannelWebhook is the channel name for user-configured webhook integrations
+const ChannelWebhook = "webhook"
+
+// webhookTimeout bounds each webhook delivery so a slow receiver can't stall a batch
+const webhookTimeout = 5 * time.Second
+
+// Headers sent with every webhook delivery
+const (
+	webhookSignatureHeader = "X-Tennis-Booker-Signature"
+	webhookEventHeader     = "X-Tennis-Booker-Event"
+	webhookEventType       = "court_availability"
+)
+
+// WebhookPayload is the JSON body POSTed to a user's webhook URL
+type WebhookPayload struct {
+	Event  string                          `json:"event"`
+	UserID string                          `json:"user_id"`
+	SentAt time.Time                       `json:"sent_at"`
+	Slots  []models.CourtAvailabilityEvent `json:"slots"`
+}
+
+// slotAlertSender is implemented by channels that deliver the raw slots for a specific user
+type slotAlertSender interface {
+	SendSlotAlert(user User, slots []SlotData) error
+}
+
+// WebhookService delivers court availability alerts to user-configured HTTP endpoints
+type WebhookService struct {
+	httpClient *http.Client
+	checkURL   func(string) error // Vets a URL before anything is sent to it
+	logger     *logging.Logger
+}
+
+// NewWebhookService creates a new webhook service. Its client only connects to
+// public addresses, so a URL whose host resolves somewhere internal is refused
+// even though it passed validation when it was saved.
+func NewWebhookService(logger *logging.Logger) *WebhookService {
+	dialer := &net.Dialer{Timeout: webhookTimeout, Control: webhookDialControl}
+	return &WebhookService{
+		httpClient: &http.Client{
+			Timeout: webhookTimeout,
+			Transport: &http.Transport{
+				DialContext:         dialer.DialContext,
+				TLSHandshakeTimeout: webhookTimeout,
+			},
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 3 {
+					return fmt.Errorf("stopped after %d redirects", len(via))
+				}
+				return models.ValidateWebhookURL(req.URL.String())
+			},
+		},
+		checkURL: models.ValidateWebhookURL,
+		logger:   logger,
+	}
+}
+
+// webhookDialControl refuses connections to addresses that aren't public. It
+// runs after DNS resolution, for every address tried.
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid webhook address %q: %w", address, err)
+	}
+	if ip := net.ParseIP(host); !models.IsPublicIP(ip) {
+		return fmt.Errorf("webhook address %s is not a public address", host)
+	}
+	return nil
+}
+
+// Send implements NotificationChannel. It POSTs a plain summary without user context
+// or a signature; SendSlotAlert is used whenever the user and slots are known.
+func (w *WebhookService) Send(toAddress, courtDetails, bookingLink string) error {
+	body, err := json.Marshal(map[string]string{
+		"event":         webhookEventType,
+		"court_details": courtDetails,
+		"booking_url":   bookingLink,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	return w.post(toAddress, "", body)
+}
+
+// SendSlotAlert POSTs the slots to the user's webhook URL, signing the body with the
+// user's secret when one is configured
+func (w *WebhookService) SendSlotAlert(user User, slots []SlotData) error {
+	if user.WebhookURL == "" {
+		return fmt.Errorf("user %s has no webhook URL configured", user.ID.Hex())
+	}
+
+	payload := WebhookPayload{
+		Event:  webhookEventType,
+		UserID: user.ID.Hex(),
+		SentAt: time.Now().UTC(),
+		Slots:  make([]models.CourtAvailabilityEvent, 0, len(slots)),
+	}
+	for _, slot := range slots {
+		payload.Slots = append(payload.Slots, slotToAvailabilityEvent(slot))
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	sendErr := w.post(user.WebhookURL, user.WebhookSecret, body)
+	if sendErr != nil {
+		w.logger.Error("Webhook delivery failed", map[string]interface{}{"url": user.WebhookURL, "user_id": user.ID.Hex(), "error": sendErr.Error()})
+	} else {
+		w.logger.Info("Webhook delivered", map[string]interface{}{"url": user.WebhookURL, "user_id": user.ID.Hex(), "slots": len(slots)})
+	}
+
+	return sendErr
+}
+
+// post sends the JSON body, adding an HMAC signature header when a secret is set
+func (w *WebhookService) post(url, secret string, body []byte) error {
+	if err := w.checkURL(url); err != nil {
+		return fmt.Errorf("webhook URL not allowed: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "TennisBooker-Webhook/1.0")
+	req.Header.Set(webhookEventHeader, webhookEventType)
+	if secret != "" {
+		req.Header.Set(webhookSignatureHeader, signWebhookPayload(secret, body))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// signWebhookPayload returns the signature header value: "sha256=" + hex(HMAC-SHA256(secret, body))
+func signWebhookPayload(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// slotToAvailabilityEvent converts a scraped slot to the shared availability event shape
+func slotToAvailabilityEvent(slot SlotData) models.CourtAvailabilityEvent {
+	return models.CourtAvailabilityEvent{
+		VenueID:      slot.VenueID,
+		VenueName:    slot.VenueName,
+		CourtID:      slot.CourtID,
+		CourtName:    slot.CourtName,
+		Date:         slot.Date,
+		StartTime:    slot.StartTime,
+		EndTime:      slot.EndTime,
+		Price:        slot.Price,
+		Currency:     slotCurrency(slot),
+		BookingURL:   slot.BookingURL,
+		DiscoveredAt: slot.ScrapedAt,
+		AlertType:    slot.AlertType,
+	}
+}
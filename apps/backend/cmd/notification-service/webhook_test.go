@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestWebhookService_SendSlotAlert_SignsPayload(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		signature = r.Header.Get(webhookSignatureHeader)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := newTestWebhookService(server)
+
+	user := User{ID: primitive.NewObjectID(), WebhookURL: server.URL, WebhookSecret: "s3cret"}
+	slot := SlotData{VenueID: "v1", VenueName: "Victoria Park", CourtID: "c1", CourtName: "Court 1",
+		Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 12.5}
+
+	require.NoError(t, webhook.SendSlotAlert(user, []SlotData{slot}))
+
+	assert.Equal(t, signWebhookPayload("s3cret", body), signature)
+
+	var payload WebhookPayload
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, user.ID.Hex(), payload.UserID)
+	require.Len(t, payload.Slots, 1)
+	assert.Equal(t, "Court 1", payload.Slots[0].CourtName)
+	assert.Equal(t, 12.5, payload.Slots[0].Price)
+}
+
+func TestWebhookService_NoSignatureWithoutSecret(t *testing.T) {
+	signed := true
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, signed = r.Header[webhookSignatureHeader]
+	}))
+	defer server.Close()
+
+	webhook := newTestWebhookService(server)
+	user := User{ID: primitive.NewObjectID(), WebhookURL: server.URL}
+
+	require.NoError(t, webhook.SendSlotAlert(user, []SlotData{{VenueName: "Victoria Park"}}))
+	assert.False(t, signed)
+}
+
+func TestWebhookService_Non2xxIsAnError(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook := newTestWebhookService(server)
+	user := User{ID: primitive.NewObjectID(), WebhookURL: server.URL}
+
+	err := webhook.SendSlotAlert(user, []SlotData{{VenueName: "Victoria Park"}, {VenueName: "Highbury"}})
+	require.Error(t, err)
+}
+
+func TestWebhookService_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	webhook := newTestWebhookService(server)
+	webhook.httpClient.Timeout = 50 * time.Millisecond
+	user := User{ID: primitive.NewObjectID(), WebhookURL: server.URL}
+
+	start := time.Now()
+	require.Error(t, webhook.SendSlotAlert(user, []SlotData{{VenueName: "Victoria Park"}}))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, webhookTimeout, NewWebhookService(nil).httpClient.Timeout)
+}
+
+// newTestWebhookService sends to the local test server, which the production
+// client and URL check would refuse
+func newTestWebhookService(server *httptest.Server) *WebhookService {
+	webhook := NewWebhookService(discardLogger())
+	webhook.httpClient = server.Client()
+	webhook.httpClient.Timeout = webhookTimeout
+	webhook.checkURL = func(string) error { return nil }
+	return webhook
+}
+
+func TestWebhookService_RefusesDisallowedURLs(t *testing.T) {
+	webhook := NewWebhookService(discardLogger())
+	user := User{ID: primitive.NewObjectID()}
+
+	for _, url := range []string{"http://hooks.example.com/tennis", "https://169.254.169.254/latest/meta-data/", "https://localhost:8080/admin"} {
+		user.WebhookURL = url
+		err := webhook.SendSlotAlert(user, []SlotData{{VenueName: "Victoria Park"}})
+		require.Error(t, err, url)
+		assert.Contains(t, err.Error(), "not allowed", url)
+	}
+}
+
+// A URL that passed validation but reaches an internal address, e.g. through
+// DNS, is refused when the connection is made
+func TestWebhookService_RefusesInternalAddressesAtDial(t *testing.T) {
+	reached := false
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	defer server.Close()
+
+	webhook := NewWebhookService(discardLogger())
+	webhook.checkURL = func(string) error { return nil }
+	user := User{ID: primitive.NewObjectID(), WebhookURL: server.URL}
+
+	err := webhook.SendSlotAlert(user, []SlotData{{VenueName: "Victoria Park"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a public address")
+	assert.False(t, reached)
+}
+
+func TestWebhookDialControl(t *testing.T) {
+	assert.NoError(t, webhookDialControl("tcp4", "93.184.216.34:443", nil))
+	assert.NoError(t, webhookDialControl("tcp6", "[2606:2800:220:1:248:1893:25c8:1946]:443", nil))
+	assert.Error(t, webhookDialControl("tcp4", "127.0.0.1:443", nil))
+	assert.Error(t, webhookDialControl("tcp4", "10.1.2.3:443", nil))
+	assert.Error(t, webhookDialControl("tcp4", "169.254.169.254:80", nil))
+	assert.Error(t, webhookDialControl("tcp6", "[::1]:443", nil))
+	assert.Error(t, webhookDialControl("tcp4", "not-an-address", nil))
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"tennis-booker/internal/retention"
+)
+
+// healthCheckTimeout bounds the database ping behind /healthz and /readyz
+const healthCheckTimeout = 2 * time.Second
+
+// cycleStatus tracks the retention cycles run by this process for the health server
+type cycleStatus struct {
+	running atomic.Bool
+
+	mu          sync.Mutex
+	cycles      int64
+	failures    int64
+	lastMetrics *retention.RetentionMetrics
+}
+
+// cycleStarted marks a cycle as in progress
+func (c *cycleStatus) cycleStarted() {
+	c.running.Store(true)
+}
+
+// cycleFinished records the outcome of the cycle that just ended
+func (c *cycleStatus) cycleFinished(metrics *retention.RetentionMetrics, err error) {
+	c.mu.Lock()
+	c.cycles++
+	if err != nil {
+		c.failures++
+	}
+	if metrics != nil {
+		c.lastMetrics = metrics
+	}
+	c.mu.Unlock()
+
+	c.running.Store(false)
+}
+
+// Metrics reported for the cycles run by this process
+var (
+	cycleRunningDesc = prometheus.NewDesc("retention_cycle_running",
+		"Whether a retention cycle is in progress.", nil, nil)
+	cyclesDesc = prometheus.NewDesc("retention_cycles_total",
+		"Retention cycles run.", nil, nil)
+	cycleFailuresDesc = prometheus.NewDesc("retention_cycle_failures_total",
+		"Retention cycles that returned an error.", nil, nil)
+	lastCycleTimestampDesc = prometheus.NewDesc("retention_last_cycle_timestamp_seconds",
+		"Unix time the last retention cycle finished.", nil, nil)
+	lastCycleDurationDesc = prometheus.NewDesc("retention_last_cycle_duration_seconds",
+		"Duration of the last retention cycle.", nil, nil)
+	lastCycleCandidateSlotsDesc = prometheus.NewDesc("retention_last_cycle_candidate_slots",
+		"Candidate slots found in the last cycle.", nil, nil)
+	lastCycleSlotsIdentifiedDesc = prometheus.NewDesc("retention_last_cycle_slots_identified",
+		"Slots identified for deletion in the last cycle.", nil, nil)
+	lastCycleSlotsDeletedDesc = prometheus.NewDesc("retention_last_cycle_slots_deleted",
+		"Slots deleted in the last cycle.", nil, nil)
+	lastCycleSlotsArchivedDesc = prometheus.NewDesc("retention_last_cycle_slots_archived",
+		"Slots archived in the last cycle.", nil, nil)
+	lastCycleActivePreferencesDesc = prometheus.NewDesc("retention_last_cycle_active_preferences",
+		"Active preferences in the last cycle.", nil, nil)
+	lastCycleErrorsDesc = prometheus.NewDesc("retention_last_cycle_errors",
+		"Errors encountered in the last cycle.", nil, nil)
+	lastCycleDryRunDesc = prometheus.NewDesc("retention_last_cycle_dry_run",
+		"Whether the last cycle ran in dry-run mode.", nil, nil)
+	lastCycleDeletedDocumentsDesc = prometheus.NewDesc("retention_last_cycle_deleted_documents",
+		"Documents deleted per collection in the last cycle.", []string{"collection"}, nil)
+)
+
+// Describe implements prometheus.Collector
+func (c *cycleStatus) Describe(ch chan<- *prometheus.Desc) {
+	ch <- cycleRunningDesc
+	ch <- cyclesDesc
+	ch <- cycleFailuresDesc
+	ch <- lastCycleTimestampDesc
+	ch <- lastCycleDurationDesc
+	ch <- lastCycleCandidateSlotsDesc
+	ch <- lastCycleSlotsIdentifiedDesc
+	ch <- lastCycleSlotsDeletedDesc
+	ch <- lastCycleSlotsArchivedDesc
+	ch <- lastCycleActivePreferencesDesc
+	ch <- lastCycleErrorsDesc
+	ch <- lastCycleDryRunDesc
+	ch <- lastCycleDeletedDocumentsDesc
+}
+
+// Collect implements prometheus.Collector, reporting the cycle counters and,
+// once a cycle has finished, the last cycle's metrics as gauges
+func (c *cycleStatus) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(cycleRunningDesc, prometheus.GaugeValue, boolToFloat(c.running.Load()))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch <- prometheus.MustNewConstMetric(cyclesDesc, prometheus.CounterValue, float64(c.cycles))
+	ch <- prometheus.MustNewConstMetric(cycleFailuresDesc, prometheus.CounterValue, float64(c.failures))
+
+	m := c.lastMetrics
+	if m == nil {
+		return
+	}
+
+	gauge := func(desc *prometheus.Desc, value float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value, labels...)
+	}
+	gauge(lastCycleTimestampDesc, float64(m.EndTime.UnixNano())/1e9)
+	gauge(lastCycleDurationDesc, m.Duration.Seconds())
+	gauge(lastCycleCandidateSlotsDesc, float64(m.CandidateSlotsFound))
+	gauge(lastCycleSlotsIdentifiedDesc, float64(m.SlotsIdentifiedForDeletion))
+	gauge(lastCycleSlotsDeletedDesc, float64(m.SlotsActuallyDeleted))
+	gauge(lastCycleSlotsArchivedDesc, float64(m.SlotsArchived))
+	gauge(lastCycleActivePreferencesDesc, float64(m.ActivePreferencesCount))
+	gauge(lastCycleErrorsDesc, float64(m.ErrorsEncountered))
+	gauge(lastCycleDryRunDesc, boolToFloat(m.DryRunMode))
+	for collection, deleted := range m.DeletedByCollection {
+		gauge(lastCycleDeletedDocumentsDesc, float64(deleted), collection)
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// checkDatabase pings MongoDB, bounded by healthCheckTimeout
+func (app *RetentionServiceApp) checkDatabase(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	if app.pingDB != nil {
+		return app.pingDB(ctx)
+	}
+	return app.db.Client().Ping(ctx, nil)
+}
+
+// healthzHandler reports whether the process is up and can reach the database
+func (app *RetentionServiceApp) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	if err := app.checkDatabase(r.Context()); err != nil {
+		http.Error(w, "database unreachable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// readyzHandler reports ready only between cycles and while the database is reachable
+func (app *RetentionServiceApp) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	if app.status.running.Load() {
+		http.Error(w, "retention cycle in progress", http.StatusServiceUnavailable)
+		return
+	}
+	if err := app.checkDatabase(r.Context()); err != nil {
+		http.Error(w, "database unreachable: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "ready")
+}
+
+// metricsHandler serves the last cycle's metrics for Prometheus to scrape
+func (app *RetentionServiceApp) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(&app.status)
+
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// startHealthServer exposes /healthz, /readyz and /metrics on RETENTION_HEALTH_PORT in the background
+func (app *RetentionServiceApp) startHealthServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", app.healthzHandler)
+	mux.HandleFunc("/readyz", app.readyzHandler)
+	mux.HandleFunc("/metrics", app.metricsHandler)
+
+	server := &http.Server{
+		Addr:              ":" + app.config.HealthPort,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		app.logger.Printf("🩺 Health endpoints available on :%s (/healthz, /readyz, /metrics)", app.config.HealthPort)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			app.logger.Printf("❌ Health server stopped: %v", err)
+		}
+	}()
+
+	return server
+}
+
+// stopHealthServer shuts the health server down, allowing in-flight probes to finish
+func (app *RetentionServiceApp) stopHealthServer(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		app.logger.Printf("⚠️ Error shutting down health server: %v", err)
+	}
+}
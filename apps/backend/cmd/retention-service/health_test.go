@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tennis-booker/internal/retention"
+)
+
+func newTestApp(dbErr error) *RetentionServiceApp {
+	return &RetentionServiceApp{
+		logger: log.New(io.Discard, "", 0),
+		config: DefaultAppConfig(),
+		pingDB: func(ctx context.Context) error { return dbErr },
+	}
+}
+
+func serve(handler http.HandlerFunc, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestHealthz(t *testing.T) {
+	assert.Equal(t, http.StatusOK, serve(newTestApp(nil).healthzHandler, "/healthz").Code)
+
+	app := newTestApp(errors.New("connection refused"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(app.healthzHandler, "/healthz").Code)
+
+	// A cycle in progress doesn't make the process unhealthy
+	app = newTestApp(nil)
+	app.status.cycleStarted()
+	assert.Equal(t, http.StatusOK, serve(app.healthzHandler, "/healthz").Code)
+}
+
+func TestReadyz(t *testing.T) {
+	app := newTestApp(nil)
+	assert.Equal(t, http.StatusOK, serve(app.readyzHandler, "/readyz").Code)
+
+	app.status.cycleStarted()
+	rec := serve(app.readyzHandler, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "in progress")
+
+	app.status.cycleFinished(&retention.RetentionMetrics{}, nil)
+	assert.Equal(t, http.StatusOK, serve(app.readyzHandler, "/readyz").Code)
+
+	app = newTestApp(errors.New("connection refused"))
+	assert.Equal(t, http.StatusServiceUnavailable, serve(app.readyzHandler, "/readyz").Code)
+}
+
+func TestMetricsHandler_ExposesLastCycle(t *testing.T) {
+	app := newTestApp(nil)
+
+	rec := serve(app.metricsHandler, "/metrics")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "retention_cycles_total 0\n")
+	assert.NotContains(t, rec.Body.String(), "retention_last_cycle", "no cycle has run yet")
+
+	end := time.Unix(1718000000, 0)
+	app.status.cycleStarted()
+	app.status.cycleFinished(&retention.RetentionMetrics{
+		EndTime:                    end,
+		Duration:                   1500 * time.Millisecond,
+		CandidateSlotsFound:        40,
+		SlotsIdentifiedForDeletion: 30,
+		SlotsActuallyDeleted:       30,
+		DeletedByCollection:        map[string]int{"court_slots": 30, "scraping_logs": 12},
+	}, nil)
+	app.status.cycleStarted()
+	app.status.cycleFinished(nil, errors.New("mongo down"))
+
+	rec = serve(app.metricsHandler, "/metrics")
+	body := rec.Body.String()
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.Contains(t, body, "retention_cycle_running 0\n")
+	assert.Contains(t, body, "retention_cycles_total 2\n")
+	assert.Contains(t, body, "retention_cycle_failures_total 1\n")
+	assert.Contains(t, body, "retention_last_cycle_timestamp_seconds 1.718e+09\n")
+	assert.Contains(t, body, "retention_last_cycle_duration_seconds 1.5\n")
+	assert.Contains(t, body, "retention_last_cycle_slots_deleted 30\n")
+	assert.Contains(t, body, `retention_last_cycle_deleted_documents{collection="scraping_logs"} 12`)
+}
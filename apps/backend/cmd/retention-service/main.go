@@ -18,6 +18,7 @@ import (
 
 	"tennis-booker/internal/database"
 	"tennis-booker/internal/retention"
+	"tennis-booker/internal/secrets"
 )
 
 // RetentionServiceApp manages the retention service application
@@ -26,6 +27,8 @@ type RetentionServiceApp struct {
 	logger           *log.Logger
 	config           AppConfig
 	db               *mongo.Database
+	status           cycleStatus                     // Cycle state reported by the health server
+	pingDB           func(ctx context.Context) error // Overrides the MongoDB ping in tests
 }
 
 // AppConfig holds application-level configuration
@@ -37,6 +40,10 @@ type AppConfig struct {
 	// Retention configuration
 	RetentionConfig retention.RetentionConfig
 
+	// Archive destination: "local" (RetentionConfig.ArchiveDir) or "s3"
+	ArchiveSink string
+	S3Archive   retention.S3Config
+
 	// Database
 	MongoURI     string
 	DatabaseName string
@@ -49,6 +56,7 @@ type AppConfig struct {
 
 	// Operational
 	GracefulShutdownTimeout time.Duration
+	HealthPort              string // Serves /healthz, /readyz and /metrics when set
 }
 
 // DefaultAppConfig returns sensible defaults for the application
@@ -57,6 +65,7 @@ func DefaultAppConfig() AppConfig {
 		CronExpression:          "0 3 * * *", // Daily at 3 AM UTC
 		RunOnce:                 false,
 		RetentionConfig:         retention.DefaultRetentionConfig(),
+		ArchiveSink:             retention.ArchiveSinkLocal,
 		DatabaseName:            "tennis_booker",
 		EnableMetrics:           true,
 		MetricsOutputFile:       "/var/log/retention-metrics.json",
@@ -92,10 +101,34 @@ func LoadConfigFromEnv() AppConfig {
 		}
 	}
 
+	if windows := os.Getenv("RETENTION_COLLECTION_WINDOW_HOURS"); windows != "" {
+		config.RetentionConfig.CollectionWindows = parseCollectionWindows(windows)
+	}
+
 	if dryRun := os.Getenv("RETENTION_DRY_RUN"); dryRun == "true" {
 		config.RetentionConfig.DryRun = true
 	}
 
+	if archive := os.Getenv("RETENTION_ARCHIVE_BEFORE_DELETE"); archive == "true" {
+		config.RetentionConfig.ArchiveBeforeDelete = true
+	}
+
+	if archiveDir := os.Getenv("RETENTION_ARCHIVE_DIR"); archiveDir != "" {
+		config.RetentionConfig.ArchiveDir = archiveDir
+	}
+
+	if sink := os.Getenv("RETENTION_ARCHIVE_SINK"); sink != "" {
+		config.ArchiveSink = strings.ToLower(sink)
+	}
+
+	config.S3Archive.Endpoint = os.Getenv("RETENTION_ARCHIVE_S3_ENDPOINT")
+	config.S3Archive.Region = os.Getenv("RETENTION_ARCHIVE_S3_REGION")
+	config.S3Archive.Bucket = os.Getenv("RETENTION_ARCHIVE_S3_BUCKET")
+	config.S3Archive.Prefix = os.Getenv("RETENTION_ARCHIVE_S3_PREFIX")
+	if pathStyle := os.Getenv("RETENTION_ARCHIVE_S3_PATH_STYLE"); pathStyle == "true" {
+		config.S3Archive.UsePathStyle = true
+	}
+
 	if logLevel := os.Getenv("RETENTION_LOG_LEVEL"); logLevel != "" {
 		config.RetentionConfig.LogLevel = logLevel
 		config.LogLevel = logLevel
@@ -125,6 +158,10 @@ func LoadConfigFromEnv() AppConfig {
 	}
 
 	// Operational
+	if healthPort := os.Getenv("RETENTION_HEALTH_PORT"); healthPort != "" {
+		config.HealthPort = healthPort
+	}
+
 	if timeout := os.Getenv("RETENTION_SHUTDOWN_TIMEOUT"); timeout != "" {
 		if duration, err := time.ParseDuration(timeout); err == nil {
 			config.GracefulShutdownTimeout = duration
@@ -134,8 +171,41 @@ func LoadConfigFromEnv() AppConfig {
 	return config
 }
 
+// parseCollectionWindows parses per-collection windows in hours, e.g.
+// "scraping_logs=72,alert_history=2160". Malformed entries are skipped.
+func parseCollectionWindows(value string) map[string]time.Duration {
+	windows := make(map[string]time.Duration)
+	for _, entry := range strings.Split(value, ",") {
+		collection, hours, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		if h, err := strconv.Atoi(strings.TrimSpace(hours)); err == nil {
+			windows[strings.TrimSpace(collection)] = time.Duration(h) * time.Hour
+		}
+	}
+	return windows
+}
+
+// Validate checks the settings the retention service itself owns. The cron
+// expression is parsed the way the scheduler will parse it, so a typo fails at
+// startup rather than once the database is connected.
+func (c AppConfig) Validate() error {
+	if c.RunOnce {
+		return nil
+	}
+	if _, err := cron.ParseStandard(c.CronExpression); err != nil {
+		return fmt.Errorf("invalid RETENTION_CRON_EXPRESSION %q: %w", c.CronExpression, err)
+	}
+	return nil
+}
+
 // NewRetentionServiceApp creates a new retention service application
 func NewRetentionServiceApp(config AppConfig) (*RetentionServiceApp, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Configure logger
 	logger := configureLogger(config.LogLevel, config.LogFormat)
 
@@ -164,6 +234,18 @@ func NewRetentionServiceApp(config AppConfig) (*RetentionServiceApp, error) {
 	// Create retention service
 	retentionService := retention.NewRetentionService(config.RetentionConfig, db, logger)
 
+	switch config.ArchiveSink {
+	case retention.ArchiveSinkLocal:
+	case retention.ArchiveSinkS3:
+		sink, err := newS3ArchiveSink(config.S3Archive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure S3 archive sink: %w", err)
+		}
+		retentionService.SetArchiveSink(sink)
+	default:
+		return nil, fmt.Errorf("unknown archive sink %q (expected %s or %s)", config.ArchiveSink, retention.ArchiveSinkLocal, retention.ArchiveSinkS3)
+	}
+
 	// Validate configuration
 	if err := retentionService.ValidateConfiguration(); err != nil {
 		return nil, fmt.Errorf("invalid retention configuration: %w", err)
@@ -177,6 +259,21 @@ func NewRetentionServiceApp(config AppConfig) (*RetentionServiceApp, error) {
 	}, nil
 }
 
+// newS3ArchiveSink creates the S3 archive sink, loading its credentials from the secrets manager
+func newS3ArchiveSink(config retention.S3Config) (*retention.S3ArchiveSink, error) {
+	secretsManager, err := secrets.NewSecretsManagerFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	config.AccessKeyID, config.SecretAccessKey, err = secretsManager.GetS3Credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	return retention.NewS3ArchiveSink(config)
+}
+
 // Run starts the retention service application
 func (app *RetentionServiceApp) Run(ctx context.Context) error {
 	app.logger.Println("🚀 Starting Tennis Court Data Retention Service...")
@@ -229,7 +326,9 @@ func (app *RetentionServiceApp) runRetentionCycle(ctx context.Context) error {
 	startTime := time.Now()
 
 	// Run retention cycle
+	app.status.cycleStarted()
 	metrics, err := app.retentionService.RunRetentionCycle(ctx)
+	app.status.cycleFinished(metrics, err)
 	if err != nil {
 		app.logger.Printf("❌ Retention cycle failed: %v", err)
 		return err
@@ -257,20 +356,27 @@ func (app *RetentionServiceApp) logConfiguration() {
 
 	if config.LogFormat == "json" {
 		configJSON, _ := json.Marshal(map[string]interface{}{
-			"retention_window": config.RetentionConfig.RetentionWindow.String(),
-			"batch_size":       config.RetentionConfig.BatchSize,
-			"dry_run":          config.RetentionConfig.DryRun,
-			"cron_expression":  config.CronExpression,
-			"run_once":         config.RunOnce,
-			"enable_metrics":   config.EnableMetrics,
-			"log_level":        config.LogLevel,
+			"retention_window":   config.RetentionConfig.RetentionWindow.String(),
+			"collection_windows": config.RetentionConfig.CollectionWindows,
+			"batch_size":         config.RetentionConfig.BatchSize,
+			"dry_run":            config.RetentionConfig.DryRun,
+			"archive":            config.RetentionConfig.ArchiveBeforeDelete,
+			"archive_sink":       config.ArchiveSink,
+			"cron_expression":    config.CronExpression,
+			"run_once":           config.RunOnce,
+			"enable_metrics":     config.EnableMetrics,
+			"log_level":          config.LogLevel,
 		})
 		app.logger.Printf("📋 Configuration: %s", string(configJSON))
 	} else {
 		app.logger.Printf("📋 Configuration:")
 		app.logger.Printf("  - Retention Window: %v", config.RetentionConfig.RetentionWindow)
+		for collection, window := range config.RetentionConfig.CollectionWindows {
+			app.logger.Printf("  - Retention Window (%s): %v", collection, window)
+		}
 		app.logger.Printf("  - Batch Size: %d", config.RetentionConfig.BatchSize)
 		app.logger.Printf("  - Dry Run: %v", config.RetentionConfig.DryRun)
+		app.logger.Printf("  - Archive: %v (sink: %s)", config.RetentionConfig.ArchiveBeforeDelete, config.ArchiveSink)
 		app.logger.Printf("  - Cron Expression: %s", config.CronExpression)
 		app.logger.Printf("  - Run Once: %v", config.RunOnce)
 		app.logger.Printf("  - Enable Metrics: %v", config.EnableMetrics)
@@ -288,6 +394,8 @@ func (app *RetentionServiceApp) logRetentionResults(metrics *retention.Retention
 			"slots_checked_against_prefs":   metrics.SlotsCheckedAgainstPrefs,
 			"slots_identified_for_deletion": metrics.SlotsIdentifiedForDeletion,
 			"slots_actually_deleted":        metrics.SlotsActuallyDeleted,
+			"slots_archived":                metrics.SlotsArchived,
+			"deleted_by_collection":         metrics.DeletedByCollection,
 			"active_preferences_count":      metrics.ActivePreferencesCount,
 			"errors_encountered":            metrics.ErrorsEncountered,
 			"dry_run_mode":                  metrics.DryRunMode,
@@ -301,6 +409,10 @@ func (app *RetentionServiceApp) logRetentionResults(metrics *retention.Retention
 		app.logger.Printf("  - Slots Checked Against Preferences: %d", metrics.SlotsCheckedAgainstPrefs)
 		app.logger.Printf("  - Slots Identified for Deletion: %d", metrics.SlotsIdentifiedForDeletion)
 		app.logger.Printf("  - Slots Actually Deleted: %d", metrics.SlotsActuallyDeleted)
+		app.logger.Printf("  - Slots Archived: %d", metrics.SlotsArchived)
+		for collection, deleted := range metrics.DeletedByCollection {
+			app.logger.Printf("  - Deleted from %s: %d", collection, deleted)
+		}
 		app.logger.Printf("  - Active Preferences Count: %d", metrics.ActivePreferencesCount)
 		app.logger.Printf("  - Errors Encountered: %d", metrics.ErrorsEncountered)
 		app.logger.Printf("  - Dry Run Mode: %v", metrics.DryRunMode)
@@ -318,6 +430,8 @@ func (app *RetentionServiceApp) saveMetrics(metrics *retention.RetentionMetrics)
 		"slots_checked_against_prefs":   metrics.SlotsCheckedAgainstPrefs,
 		"slots_identified_for_deletion": metrics.SlotsIdentifiedForDeletion,
 		"slots_actually_deleted":        metrics.SlotsActuallyDeleted,
+		"slots_archived":                metrics.SlotsArchived,
+		"deleted_by_collection":         metrics.DeletedByCollection,
 		"active_preferences_count":      metrics.ActivePreferencesCount,
 		"errors_encountered":            metrics.ErrorsEncountered,
 		"dry_run_mode":                  metrics.DryRunMode,
@@ -403,6 +517,80 @@ func handleDryRunMode(logger *log.Logger) error {
 	return app.runRetentionCycle(ctx)
 }
 
+// handleEstimateMode reports what a retention cycle would delete and how much
+// space that would free, without deleting anything
+func handleEstimateMode(logger *log.Logger) error {
+	logger.Println("📏 Running in estimate mode...")
+
+	config := LoadConfigFromEnv()
+	config.RunOnce = true
+	config.RetentionConfig.DryRun = true
+
+	app, err := NewRetentionServiceApp(config)
+	if err != nil {
+		return fmt.Errorf("failed to create retention service app: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	estimate, err := app.retentionService.Estimate(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to estimate retention: %w", err)
+	}
+
+	app.logEstimate(estimate)
+	return nil
+}
+
+// logEstimate logs a retention estimate in the configured log format
+func (app *RetentionServiceApp) logEstimate(estimate *retention.RetentionEstimate) {
+	if app.config.LogFormat == "json" {
+		collections := make([]map[string]interface{}, 0, len(estimate.Collections))
+		for _, c := range estimate.Collections {
+			collections = append(collections, map[string]interface{}{
+				"collection":         c.Collection,
+				"retention_window":   c.RetentionWindow.String(),
+				"documents":          c.Documents,
+				"total_documents":    c.TotalDocuments,
+				"avg_document_bytes": c.AvgDocumentBytes,
+				"reclaimable_bytes":  c.ReclaimableBytes,
+			})
+		}
+
+		estimateJSON, _ := json.Marshal(map[string]interface{}{
+			"event":             "retention_estimate",
+			"collections":       collections,
+			"total_documents":   estimate.TotalDocuments,
+			"reclaimable_bytes": estimate.ReclaimableBytes,
+			"timestamp":         time.Now().UTC().Format(time.RFC3339),
+		})
+		app.logger.Printf("📏 %s", string(estimateJSON))
+		return
+	}
+
+	app.logger.Printf("📏 Retention Estimate:")
+	for _, c := range estimate.Collections {
+		app.logger.Printf("  - %s (window %v): %d of %d documents, ~%s",
+			c.Collection, c.RetentionWindow, c.Documents, c.TotalDocuments, formatBytes(c.ReclaimableBytes))
+	}
+	app.logger.Printf("  - Total: %d documents, ~%s reclaimable", estimate.TotalDocuments, formatBytes(estimate.ReclaimableBytes))
+}
+
+// formatBytes renders a byte count with a binary unit, e.g. 1.5 MiB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
 func main() {
 	// Load environment variables
 	godotenv.Load()
@@ -423,6 +611,11 @@ func main() {
 				logger.Fatalf("❌ Dry-run mode failed: %v", err)
 			}
 			return
+		case "estimate":
+			if err := handleEstimateMode(logger); err != nil {
+				logger.Fatalf("❌ Estimate mode failed: %v", err)
+			}
+			return
 		case "help", "-h", "--help":
 			fmt.Println("Tennis Court Data Retention Service")
 			fmt.Println("")
@@ -430,18 +623,31 @@ func main() {
 			fmt.Println("  retention-service              Run in scheduled mode")
 			fmt.Println("  retention-service test         Run in test mode (dry-run with debug logging)")
 			fmt.Println("  retention-service dry-run      Run once in dry-run mode")
+			fmt.Println("  retention-service estimate     Report what would be deleted and the space it would free")
 			fmt.Println("  retention-service help         Show this help message")
 			fmt.Println("")
 			fmt.Println("Environment Variables:")
 			fmt.Println("  RETENTION_CRON_EXPRESSION      Cron expression for scheduling (default: '0 3 * * *')")
 			fmt.Println("  RETENTION_RUN_ONCE             Run once and exit (default: false)")
 			fmt.Println("  RETENTION_WINDOW_HOURS          Hours before slots are eligible for deletion (default: 168)")
+			fmt.Println("  RETENTION_COLLECTION_WINDOW_HOURS Per-collection windows, e.g. scraping_logs=72,alert_history=2160")
 			fmt.Println("  RETENTION_BATCH_SIZE            Batch size for deletions (default: 1000)")
 			fmt.Println("  RETENTION_DRY_RUN               Enable dry-run mode (default: false)")
+			fmt.Println("  RETENTION_ARCHIVE_BEFORE_DELETE Archive slots to gzipped JSON Lines before deleting (default: false)")
+			fmt.Println("  RETENTION_ARCHIVE_DIR           Directory for archive files (default: archive)")
+			fmt.Println("  RETENTION_ARCHIVE_SINK          Where archives go: local, s3 (default: local)")
+			fmt.Println("  RETENTION_ARCHIVE_S3_ENDPOINT   S3-compatible endpoint, e.g. for MinIO (default: AWS)")
+			fmt.Println("  RETENTION_ARCHIVE_S3_REGION     S3 region (default: us-east-1)")
+			fmt.Println("  RETENTION_ARCHIVE_S3_BUCKET     S3 bucket for archives")
+			fmt.Println("  RETENTION_ARCHIVE_S3_PREFIX     Key prefix for archive objects")
+			fmt.Println("  RETENTION_ARCHIVE_S3_PATH_STYLE Use path-style bucket addressing, needed for MinIO (default: false)")
+			fmt.Println("  S3_ACCESS_KEY_ID                S3 access key (via secrets manager)")
+			fmt.Println("  S3_SECRET_ACCESS_KEY            S3 secret key (via secrets manager)")
 			fmt.Println("  RETENTION_LOG_LEVEL             Log level: info, debug (default: info)")
 			fmt.Println("  RETENTION_LOG_FORMAT            Log format: text, json (default: json)")
 			fmt.Println("  RETENTION_ENABLE_METRICS        Enable metrics collection (default: true)")
 			fmt.Println("  RETENTION_METRICS_FILE          Metrics output file (default: /var/log/retention-metrics.json)")
+			fmt.Println("  RETENTION_HEALTH_PORT           Port for /healthz, /readyz and /metrics (default: disabled)")
 			fmt.Println("  MONGO_URI                       MongoDB connection URI")
 			fmt.Println("  DATABASE_NAME                   Database name (default: tennis_booker)")
 			return
@@ -473,6 +679,12 @@ func main() {
 		cancel()
 	}()
 
+	// Expose health checks and metrics for Kubernetes probes
+	if config.HealthPort != "" {
+		healthServer := app.startHealthServer()
+		defer app.stopHealthServer(healthServer)
+	}
+
 	// Run the application
 	if err := app.Run(ctx); err != nil {
 		logger.Fatalf("❌ Retention service failed: %v", err)
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppConfigValidate_CronExpression(t *testing.T) {
+	tests := []struct {
+		name    string
+		cron    string
+		runOnce bool
+		wantErr bool
+	}{
+		{name: "default schedule", cron: "0 3 * * *"},
+		{name: "descriptor", cron: "@daily"},
+		{name: "seconds field is not accepted", cron: "0 0 3 * * *", wantErr: true},
+		{name: "typo", cron: "0 3 * *", wantErr: true},
+		{name: "empty", cron: "", wantErr: true},
+		{name: "ignored when running once", cron: "not a schedule", runOnce: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultAppConfig()
+			config.CronExpression = tt.cron
+			config.RunOnce = tt.runOnce
+
+			err := config.Validate()
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "RETENTION_CRON_EXPRESSION")
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestNewRetentionServiceApp_RejectsInvalidCron(t *testing.T) {
+	config := DefaultAppConfig()
+	config.CronExpression = "every day at 3"
+
+	// Fails before trying to reach MongoDB
+	app, err := NewRetentionServiceApp(config)
+	assert.Nil(t, app)
+	assert.ErrorContains(t, err, "RETENTION_CRON_EXPRESSION")
+}
@@ -20,6 +20,9 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Configuration is invalid: %v", err)
+	}
 
 	// Initialize database
 	mongoDb, err := database.InitDatabase(cfg.MongoDB.URI, cfg.MongoDB.Database)
@@ -63,6 +66,12 @@ func main() {
 		log.Printf("Creating court slots for venue: %s", venue.Name)
 
 		for dateIndex, date := range dates {
+			// Slots can't be booked beyond the venue's booking window, so don't seed them
+			if !models.WithinBookingWindow(date, venue.BookingWindow, now) {
+				log.Printf("Skipping %s for %s: outside its %d-day booking window", date, venue.Name, venue.BookingWindow)
+				continue
+			}
+
 			var slots []models.Slot
 
 			// Generate slots based on number of courts
@@ -9,8 +9,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"tennis-booker/internal/database"
 	"tennis-booker/internal/models"
 )
 
@@ -29,10 +29,11 @@ func main() {
 	}
 
 	// Connect to MongoDB
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	connConfig := database.ConnectionConfigFromEnv()
+	ctx, cancel := context.WithTimeout(context.Background(), connConfig.ConnectTimeout)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	client, err := mongo.Connect(ctx, connConfig.ClientOptions(mongoURI))
 	if err != nil {
 		log.Fatalf("Failed to connect to MongoDB: %v", err)
 	}
@@ -69,9 +70,11 @@ func main() {
 			Provider: "courtsides",
 			URL:      "https://tennistowerhamlets.com/book/courts/victoria-park#book",
 			Location: models.Location{
-				Address:  "Victoria Park, London",
-				City:     "London",
-				PostCode: "E9 7DE",
+				Address:   "Victoria Park, London",
+				City:      "London",
+				PostCode:  "E9 7DE",
+				Latitude:  51.5362,
+				Longitude: -0.0403,
 			},
 			Courts: []models.Court{
 				{ID: "1", Name: "Court 1", Surface: "Hard", Indoor: false, Floodlights: true},
@@ -106,9 +109,11 @@ func main() {
 			Provider: "lta_clubspark",
 			URL:      "https://stratford.newhamparkstennis.org.uk/Booking/BookByDate#?date=2025-06-09&role=guest",
 			Location: models.Location{
-				Address:  "Stratford Park, London",
-				City:     "London",
-				PostCode: "E15 1DA",
+				Address:   "Stratford Park, London",
+				City:      "London",
+				PostCode:  "E15 1DA",
+				Latitude:  51.5393,
+				Longitude: -0.0046,
 			},
 			Courts: []models.Court{
 				{ID: "1", Name: "Court 1", Surface: "Hard", Indoor: false, Floodlights: true},
@@ -146,9 +151,11 @@ func main() {
 			Provider: "courtsides",
 			URL:      "https://tennistowerhamlets.com/book/courts/ropemakers-field#book",
 			Location: models.Location{
-				Address:  "Ropemakers Field, London",
-				City:     "London",
-				PostCode: "E14 0JY",
+				Address:   "Ropemakers Field, London",
+				City:      "London",
+				PostCode:  "E14 0JY",
+				Latitude:  51.5115,
+				Longitude: -0.0306,
 			},
 			Courts: []models.Court{
 				{ID: "1", Name: "Court 1", Surface: "Hard", Indoor: false, Floodlights: true},
@@ -181,9 +188,11 @@ func main() {
 			Provider: "courtsides",
 			URL:      "https://tennistowerhamlets.com/book/courts/bethnal-green-gardens#book",
 			Location: models.Location{
-				Address:  "Bethnal Green Gardens, London",
-				City:     "London",
-				PostCode: "E2 9PA",
+				Address:   "Bethnal Green Gardens, London",
+				City:      "London",
+				PostCode:  "E2 9PA",
+				Latitude:  51.5272,
+				Longitude: -0.0557,
 			},
 			Courts: []models.Court{
 				{ID: "1", Name: "Court 1", Surface: "Hard", Indoor: false, Floodlights: true},
@@ -218,9 +227,11 @@ func main() {
 			Provider: "courtsides",
 			URL:      "https://tennistowerhamlets.com/book/courts/st-johns-park#book",
 			Location: models.Location{
-				Address:  "St Johns Park, London",
-				City:     "London",
-				PostCode: "E14 3DG",
+				Address:   "St Johns Park, London",
+				City:      "London",
+				PostCode:  "E14 3DG",
+				Latitude:  51.4949,
+				Longitude: -0.0097,
 			},
 			Courts: []models.Court{
 				{ID: "1", Name: "Court 1", Surface: "Hard", Indoor: false, Floodlights: true},
@@ -253,9 +264,11 @@ func main() {
 			Provider: "courtsides",
 			URL:      "https://tennistowerhamlets.com/book/courts/king-edward-memorial-park#book",
 			Location: models.Location{
-				Address:  "King Edward Memorial Park, London",
-				City:     "London",
-				PostCode: "E1W 3ER",
+				Address:   "King Edward Memorial Park, London",
+				City:      "London",
+				PostCode:  "E1W 3ER",
+				Latitude:  51.509,
+				Longitude: -0.0513,
 			},
 			Courts: []models.Court{
 				{ID: "1", Name: "Court 1", Surface: "Hard", Indoor: false, Floodlights: false},
@@ -288,9 +301,11 @@ func main() {
 			Provider: "courtsides",
 			URL:      "https://tennistowerhamlets.com/book/courts/poplar-rec-ground#book",
 			Location: models.Location{
-				Address:  "Poplar Recreation Ground, London",
-				City:     "London",
-				PostCode: "E14 0JA",
+				Address:   "Poplar Recreation Ground, London",
+				City:      "London",
+				PostCode:  "E14 0JA",
+				Latitude:  51.5094,
+				Longitude: -0.0139,
 			},
 			Courts: []models.Court{
 				{ID: "1", Name: "Court 1", Surface: "Hard", Indoor: false, Floodlights: false},
@@ -317,11 +332,50 @@ func main() {
 			CreatedAt:        time.Now(),
 			UpdatedAt:        time.Now(),
 		},
+		{
+			// Example Playtomic club: set tenant_id to the club's Playtomic tenant ID
+			// (from its playtomic.io URL) and activate it to start scraping
+			ID:       primitive.NewObjectID(),
+			Name:     "Example Playtomic Club",
+			Provider: "playtomic",
+			URL:      "https://playtomic.io/example-club/00000000-0000-0000-0000-000000000000",
+			Location: models.Location{
+				Address:  "Example Club, London",
+				City:     "London",
+				PostCode: "E1 6AN",
+			},
+			Timezone: "Europe/London",
+			Courts: []models.Court{
+				{ID: "00000000-0000-0000-0000-000000000001", Name: "Court 1", Surface: "Hard", Indoor: false, Floodlights: true},
+				{ID: "00000000-0000-0000-0000-000000000002", Name: "Court 2", Surface: "Hard", Indoor: false, Floodlights: true},
+			},
+			BookingWindow: 14,
+			ScraperConfig: models.ScraperConfig{
+				Type:               "playtomic",
+				RequiresLogin:      false,
+				RetryCount:         3,
+				TimeoutSeconds:     30,
+				UseHeadlessBrowser: false,
+				CustomParameters: map[string]interface{}{
+					"tenant_id": "00000000-0000-0000-0000-000000000000",
+					"sport_id":  "TENNIS",
+					"page_size": 50,
+				},
+			},
+			ScrapingInterval: 10,
+			IsActive:         false,
+			CreatedAt:        time.Now(),
+			UpdatedAt:        time.Now(),
+		},
 	}
 
 	// Insert venues
 	venueCollection := db.Collection("venues")
 	for _, venue := range venues {
+		if err := venue.Validate(); err != nil {
+			log.Fatalf("Invalid venue %s: %v", venue.Name, err)
+		}
+		venue.Location.SyncPoint()
 		_, err := venueCollection.InsertOne(ctx, venue)
 		if err != nil {
 			log.Fatalf("Failed to insert venue %s: %v", venue.Name, err)
@@ -329,6 +383,11 @@ func main() {
 		log.Printf("✅ Inserted venue: %s (%s provider)", venue.Name, venue.Provider)
 	}
 
+	// Nearby venue search needs the 2dsphere index on location.point
+	if err := database.NewVenueRepository(db).CreateIndexes(ctx); err != nil {
+		log.Printf("Warning: Failed to create venue indexes: %v", err)
+	}
+
 	log.Printf("🎾 Successfully seeded %d venues!", len(venues))
 	log.Println("Venues seeded:")
 	for _, venue := range venues {
@@ -9,8 +9,10 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"golang.org/x/crypto/bcrypt"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/database"
 )
 
 // User represents a user account for authentication
@@ -57,10 +59,28 @@ type NotificationSettings struct {
 }
 
 func hashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), auth.BcryptCostFromEnv())
 	return string(bytes), err
 }
 
+// venueIDsByName returns the hex IDs of the named venues, skipping any that
+// haven't been seeded
+func venueIDsByName(ctx context.Context, db *mongo.Database, names ...string) []string {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		var venue struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		err := db.Collection("venues").FindOne(ctx, bson.M{"name": name}).Decode(&venue)
+		if err != nil {
+			log.Printf("Warning: Venue %q not found, leaving it out of preferred venues: %v", name, err)
+			continue
+		}
+		ids = append(ids, venue.ID.Hex())
+	}
+	return ids
+}
+
 func main() {
 	log.Println("Starting user seeding process...")
 
@@ -76,10 +96,11 @@ func main() {
 	}
 
 	// Connect to MongoDB
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	connConfig := database.ConnectionConfigFromEnv()
+	ctx, cancel := context.WithTimeout(context.Background(), connConfig.ConnectTimeout)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	client, err := mongo.Connect(ctx, connConfig.ClientOptions(mongoURI))
 	if err != nil {
 		log.Fatalf("Failed to connect to MongoDB: %v", err)
 	}
@@ -109,6 +130,9 @@ func main() {
 		log.Println("Cleared existing user preferences")
 	}
 
+	// Preferences hold venue IDs, so look up the seeded venues
+	preferredVenues := venueIDsByName(ctx, db, "Victoria Park", "Stratford Park", "Ropemakers Field")
+
 	// Create demo users
 	demoUsers := []struct {
 		email    string
@@ -155,14 +179,10 @@ func main() {
 			WeekendTimes: []TimeRange{
 				{Start: "11:00", End: "20:00"}, // Weekends: 11am-8pm
 			},
-			MaxPrice: 100.0,
-			PreferredVenues: []string{
-				"Victoria Park",
-				"Stratford Park",
-				"Ropemakers Field",
-			},
-			ExcludedVenues: []string{},
-			PreferredDays:  []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"},
+			MaxPrice:        100.0,
+			PreferredVenues: preferredVenues,
+			ExcludedVenues:  []string{},
+			PreferredDays:   []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"},
 			NotificationSettings: NotificationSettings{
 				Email:                true,
 				EmailAddress:         demoUser.email,
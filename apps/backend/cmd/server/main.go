@@ -10,14 +10,18 @@ import (
 	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/joho/godotenv"
+	"github.com/redis/go-redis/v9"
 
 	"tennis-booker/internal/auth"
 	"tennis-booker/internal/config"
 	"tennis-booker/internal/database"
+	"tennis-booker/internal/email"
 	"tennis-booker/internal/handlers"
 	"tennis-booker/internal/logging"
 	"tennis-booker/internal/middleware"
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/ratelimit"
+	redisevents "tennis-booker/internal/redis"
 	"tennis-booker/internal/secrets"
 )
 
@@ -33,20 +37,36 @@ func (f *FallbackJWTProvider) GetJWTSecret() (string, error) {
 	return secret, nil
 }
 
+// GetTOTPEncryptionKey returns the two-factor secret encryption key from environment variables
+func (f *FallbackJWTProvider) GetTOTPEncryptionKey() (string, error) {
+	key := os.Getenv("TOTP_ENCRYPTION_KEY")
+	if key == "" {
+		return "", fmt.Errorf("TOTP_ENCRYPTION_KEY environment variable is not set")
+	}
+	return key, nil
+}
+
 func main() {
 	// Initialize structured logging
 	logger := logging.New("tennis-server")
 
-	// Load environment variables if .env file exists
-	if err := godotenv.Load(); err != nil {
-		logger.Debug("No .env file found, using environment variables")
-	}
-
-	// Load configuration
+	// Load configuration, including the .env file if there is one
 	cfg, err := config.Load()
 	if err != nil {
 		logger.Fatal("Failed to load configuration", map[string]interface{}{"error": err.Error()})
 	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal("Configuration is invalid, refusing to start", map[string]interface{}{"error": err.Error()})
+	}
+	liveConfig := config.NewLive(cfg)
+	applyLogLevel(cfg, logger)
+	if cfg.CORS.AllowsAnyOrigin() {
+		if cfg.IsProduction() {
+			logger.Warn("!!! CORS_ALLOWED_ORIGINS contains \"*\" in production: any website can call this API. Cookie and credentialed requests will be rejected by browsers. List the frontend origins explicitly !!!", map[string]interface{}{"cors_origins": cfg.CORS.AllowedOrigins})
+		} else {
+			logger.Info("CORS allows any origin, credentials disabled", map[string]interface{}{"cors_origins": cfg.CORS.AllowedOrigins})
+		}
+	}
 
 	// Initialize database connection with fallback
 	var mongoDb database.Database
@@ -100,56 +120,251 @@ func main() {
 		jwtService = auth.NewJWTService(fallbackProvider, cfg.JWT.Issuer)
 	}
 
+	// Revoke tokens on logout and lock accounts after repeated failed logins.
+	// Without Redis, logout can't invalidate tokens before they expire and there is no lockout.
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Address,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	defer redisClient.Close()
+
+	pingCtx, pingCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	redisErr := redisClient.Ping(pingCtx).Err()
+	pingCancel()
+	if redisErr != nil {
+		logger.Warn("Redis unavailable, token revocation and account lockout disabled", map[string]interface{}{"error": redisErr.Error()})
+	} else {
+		jwtService.SetRevocationStore(auth.NewRedisTokenRevocationStore(redisClient))
+		logger.ConnectionInfo("Connected to Redis for token revocation and account lockout", "redis", cfg.Redis.Address)
+	}
+
+	// Rate limits requests, and its state shows why a client is being throttled. Counts
+	// are kept in Redis unless RATE_LIMIT_STORE=memory, for single-node deployments.
+	var rateLimiter *ratelimit.Limiter
+	rateLimitConfig, err := ratelimit.ConfigFromEnv()
+	if err == nil && rateLimitConfig.Store != ratelimit.StoreMemory {
+		err = redisErr
+		rateLimitConfig.RedisAddr = cfg.Redis.Address
+		rateLimitConfig.RedisPassword = cfg.Redis.Password
+		rateLimitConfig.RedisDB = cfg.Redis.DB
+	}
+	if err == nil {
+		rateLimiter, err = ratelimit.NewLimiter(rateLimitConfig)
+	}
+	if err != nil {
+		logger.Warn("Rate limiter unavailable, requests won't be rate limited", map[string]interface{}{"error": err.Error()})
+	} else {
+		defer rateLimiter.Close()
+	}
+
+	// Unsubscribe links in alert emails are signed with the same secret as JWTs
+	var unsubscribeTokens *auth.UnsubscribeTokenService
+	if secretsManager != nil {
+		unsubscribeTokens = auth.NewUnsubscribeTokenService(secretsManager)
+	} else {
+		unsubscribeTokens = auth.NewUnsubscribeTokenService(&FallbackJWTProvider{})
+	}
+
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(jwtService, mongoDb)
+	if redisErr == nil {
+		authHandler.SetAccountLockout(auth.NewRedisAccountLockout(redisClient))
+	}
+	// Reset tokens are looked up by hash, and expired ones removed by a TTL index
+	indexCtx, indexCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	if err := models.NewPasswordResetService(mongoDb.GetMongoDB()).CreateIndexes(indexCtx); err != nil {
+		logger.Warn("Failed to create password reset indexes, expired reset tokens won't be removed", map[string]interface{}{"error": err.Error()})
+	}
+	indexCancel()
+	if mailer := email.NewSMTPSender(cfg.Email); mailer.Configured() {
+		// Reset links open the frontend's reset page
+		frontendURL := os.Getenv("FRONTEND_URL")
+		if frontendURL == "" {
+			frontendURL = "http://localhost:3000"
+		}
+		authHandler.SetPasswordResetMailer(mailer, frontendURL)
+	} else {
+		logger.Warn("SMTP not configured, password reset emails disabled")
+	}
+	var totpKeys auth.TOTPKeyProvider = &FallbackJWTProvider{}
+	if secretsManager != nil {
+		totpKeys = secretsManager
+	}
+	if _, err := totpKeys.GetTOTPEncryptionKey(); err == nil {
+		authHandler.SetTwoFactorCipher(auth.NewTOTPSecretCipher(totpKeys))
+	} else {
+		logger.Warn("TOTP encryption key not configured, two-factor authentication disabled")
+	}
 	courtHandler := handlers.NewCourtHandler(mongoDb)
+	venueRepo := database.NewVenueRepository(mongoDb.GetMongoDB())
+	venueCache := database.NewVenueCache(venueRepo, nil, 0)
+	if redisErr == nil {
+		// Venue listings and dashboard stats are served from Redis; without it every request reads MongoDB
+		cacheStore := database.NewRedisCacheStore(redisClient)
+		venueCache = database.NewVenueCache(venueRepo, cacheStore, database.VenueCacheTTLFromEnv())
+		courtHandler.SetVenueCache(venueCache)
+		courtHandler.SetStatsCache(cacheStore)
+	}
+	venueAdminHandler := handlers.NewVenueAdminHandler(venueRepo, venueCache)
+	if redisErr == nil {
+		venueAdminHandler.SetTestScraper(redisevents.NewTestScrapeClient(redisClient))
+	} else {
+		logger.Warn("Redis unavailable, venue test scrapes disabled")
+	}
 	userHandler := handlers.NewUserHandler(mongoDb, jwtService)
 	systemHandler := handlers.NewSystemHandler(mongoDb)
+	systemHandler.SetFeatureFlags(liveConfig)
 	healthHandler := handlers.NewHealthHandler(secretsManager, mongoDb)
+	healthHandler.SetRedisCheck(func(ctx context.Context) error { return redisClient.Ping(ctx).Err() })
+	if mailer := email.NewSMTPSender(cfg.Email); mailer.Configured() {
+		healthHandler.SetSMTPCheck(mailer.Check)
+	}
+	notificationHandler := handlers.NewNotificationHandler(mongoDb, unsubscribeTokens)
+	if rateLimiter != nil && redisErr == nil {
+		// Test notifications go out through the notification service, and are only
+		// offered when they can be rate limited
+		notificationHandler.SetTestNotifier(redisevents.NewTestNotificationClient(redisClient), rateLimiter)
+	} else {
+		logger.Warn("Redis or rate limiter unavailable, test notifications disabled")
+	}
+	bookingHandler := handlers.NewBookingHandler(mongoDb)
+	slotStreamHandler := handlers.NewSlotStreamHandler(mongoDb, jwtService)
+	if redisErr == nil {
+		slotStreamHandler.SetAvailabilitySubscriber(redisevents.NewAvailabilitySubscriber(redisClient))
+	} else {
+		logger.Warn("Redis unavailable, live slot availability stream disabled")
+	}
 
 	// Setup router
 	router := mux.NewRouter()
 
+	// Rate limit middleware, or a pass-through when the limiter is unavailable
+	rateLimited := func(newMiddleware func(*ratelimit.Limiter) func(http.Handler) http.Handler) mux.MiddlewareFunc {
+		if rateLimiter == nil {
+			return func(next http.Handler) http.Handler { return next }
+		}
+		return newMiddleware(rateLimiter)
+	}
+	// Runs after JWTMiddleware, so users are limited by ID and exempt roles are seen
+	userRateLimited := rateLimited(ratelimit.CombinedRateLimitMiddleware)
+
+	// Request IDs first, so every later middleware and handler can log them
+	router.Use(middleware.RequestIDMiddleware())
+
 	// CORS middleware
-	router.Use(middleware.CORSMiddleware())
+	router.Use(middleware.CORSMiddleware(cfg.CORS))
 
 	// Health endpoints
 	router.HandleFunc("/api/health", healthHandler.Health).Methods("GET", "OPTIONS")
+	router.HandleFunc("/api/health/deep", healthHandler.DeepHealth).Methods("GET", "OPTIONS")
 	router.HandleFunc("/api/system/health", healthHandler.SystemHealth).Methods("GET", "OPTIONS")
 
-	// Auth endpoints
+	// Auth endpoints. Credential checks get the strict auth limit and password
+	// resets, which send email, the sensitive one.
+	authLimited := rateLimited(ratelimit.AuthRateLimitMiddleware)
+	sensitiveLimited := rateLimited(ratelimit.SensitiveRateLimitMiddleware)
+	ipLimited := rateLimited(ratelimit.IPRateLimitMiddleware)
 	authRouter := router.PathPrefix("/api/auth").Subrouter()
-	authRouter.HandleFunc("/login", authHandler.Login).Methods("POST", "OPTIONS")
-	authRouter.HandleFunc("/register", authHandler.Register).Methods("POST", "OPTIONS")
-	authRouter.HandleFunc("/refresh", authHandler.RefreshToken).Methods("POST", "OPTIONS")
-	authRouter.HandleFunc("/logout", authHandler.Logout).Methods("POST", "OPTIONS")
+	authRouter.Handle("/login", authLimited(http.HandlerFunc(authHandler.Login))).Methods("POST", "OPTIONS")
+	authRouter.Handle("/register", authLimited(http.HandlerFunc(authHandler.Register))).Methods("POST", "OPTIONS")
+	authRouter.Handle("/refresh", ipLimited(http.HandlerFunc(authHandler.RefreshToken))).Methods("POST", "OPTIONS")
+	authRouter.Handle("/logout", ipLimited(http.HandlerFunc(authHandler.Logout))).Methods("POST", "OPTIONS")
+	authRouter.Handle("/forgot-password", sensitiveLimited(http.HandlerFunc(authHandler.ForgotPassword))).Methods("POST", "OPTIONS")
+	authRouter.Handle("/reset-password", sensitiveLimited(http.HandlerFunc(authHandler.ResetPassword))).Methods("POST", "OPTIONS")
+	authRouter.Handle("/2fa/validate", authLimited(http.HandlerFunc(authHandler.ValidateTwoFactor))).Methods("POST", "OPTIONS")
 
 	// Protected auth endpoints
 	protectedAuthRouter := authRouter.PathPrefix("").Subrouter()
 	protectedAuthRouter.Use(middleware.JWTMiddleware(jwtService))
+	protectedAuthRouter.Use(userRateLimited)
 	protectedAuthRouter.HandleFunc("/me", authHandler.GetCurrentUser).Methods("GET", "OPTIONS")
+	protectedAuthRouter.HandleFunc("/2fa/enroll", authHandler.EnrollTwoFactor).Methods("POST", "OPTIONS")
+	protectedAuthRouter.HandleFunc("/2fa/verify", authHandler.VerifyTwoFactor).Methods("POST", "OPTIONS")
 
 	// User endpoints
 	userRouter := router.PathPrefix("/api/users").Subrouter()
 	userRouter.Use(middleware.JWTMiddleware(jwtService))
+	userRouter.Use(userRateLimited)
 	userRouter.HandleFunc("/preferences", userHandler.GetPreferences).Methods("GET", "OPTIONS")
 	userRouter.HandleFunc("/preferences", userHandler.UpdatePreferences).Methods("PUT", "OPTIONS")
-	userRouter.HandleFunc("/notifications", userHandler.GetNotifications).Methods("GET", "OPTIONS")
+	userRouter.HandleFunc("/preferences/venues", userHandler.ReplacePreferredVenues).Methods("PUT", "OPTIONS")
+
+	// Snoozing and notification history disappear while the notifications feature is off
+	notificationsEnabled := middleware.RequireFeature(liveConfig, config.FeatureNotifications)
+	userRouter.Handle("/preferences/snooze", notificationsEnabled(http.HandlerFunc(userHandler.SnoozeNotifications))).Methods("POST", "OPTIONS")
+	userRouter.Handle("/preferences/webhook-secret", notificationsEnabled(http.HandlerFunc(userHandler.RotateWebhookSecret))).Methods("POST", "OPTIONS")
+	userRouter.Handle("/notifications", notificationsEnabled(http.HandlerFunc(userHandler.GetNotifications))).Methods("GET", "OPTIONS")
+
+	// Notification endpoints (unsubscribe is authenticated by its signed token, not a JWT,
+	// and keeps working with notifications disabled so links in old emails still work)
+	notificationRouter := router.PathPrefix("/api/notifications").Subrouter()
+	notificationRouter.Handle("/unsubscribe", ipLimited(http.HandlerFunc(notificationHandler.Unsubscribe))).Methods("GET", "POST", "OPTIONS")
+
+	protectedNotificationRouter := notificationRouter.PathPrefix("").Subrouter()
+	protectedNotificationRouter.Use(middleware.JWTMiddleware(jwtService))
+	protectedNotificationRouter.Use(userRateLimited)
+	protectedNotificationRouter.Use(notificationsEnabled)
+	protectedNotificationRouter.HandleFunc("/history", notificationHandler.GetHistory).Methods("GET", "OPTIONS")
+	protectedNotificationRouter.HandleFunc("/test", notificationHandler.SendTestNotification).Methods("POST", "OPTIONS")
+
+	// Alert endpoints
+	alertRouter := router.PathPrefix("/api/alerts").Subrouter()
+	alertRouter.Use(middleware.JWTMiddleware(jwtService))
+	alertRouter.Use(userRateLimited)
+	alertRouter.Use(notificationsEnabled)
+	alertRouter.HandleFunc("/reset", notificationHandler.ResetAlert).Methods("POST", "OPTIONS")
+	alertRouter.HandleFunc("/stats", notificationHandler.GetAlertStats).Methods("GET", "OPTIONS")
+
+	// Booking endpoints
+	bookingRouter := router.PathPrefix("/api/bookings").Subrouter()
+	bookingRouter.Use(middleware.JWTMiddleware(jwtService))
+	bookingRouter.Use(userRateLimited)
+	bookingRouter.HandleFunc("", bookingHandler.GetBookings).Methods("GET", "OPTIONS")
+	bookingRouter.HandleFunc("", bookingHandler.CreateBooking).Methods("POST", "OPTIONS")
+	bookingRouter.HandleFunc("/{id}/status", bookingHandler.UpdateBookingStatus).Methods("PATCH", "OPTIONS")
 
 	// Court endpoints
 	courtRouter := router.PathPrefix("/api").Subrouter()
+	courtRouter.Use(rateLimited(ratelimit.DataRateLimitMiddleware))
 	courtRouter.HandleFunc("/venues", courtHandler.GetVenues).Methods("GET", "OPTIONS")
+	courtRouter.HandleFunc("/venues/near", courtHandler.GetNearbyVenues).Methods("GET", "OPTIONS")
+	courtRouter.HandleFunc("/venues/{id}/scrape-health", courtHandler.GetVenueScrapeHealth).Methods("GET", "OPTIONS")
+	courtRouter.HandleFunc("/venues/{id}/price-history", courtHandler.GetVenuePriceHistory).Methods("GET", "OPTIONS")
 	courtRouter.HandleFunc("/courts", courtHandler.GetCourtSlots).Methods("GET", "OPTIONS")
-	courtRouter.HandleFunc("/dashboard/stats", courtHandler.GetDashboardStats).Methods("GET", "OPTIONS")
+	courtRouter.Handle("/dashboard/stats", middleware.RequireFeature(liveConfig, config.FeatureAnalytics)(http.HandlerFunc(courtHandler.GetDashboardStats))).Methods("GET", "OPTIONS")
+
+	// Venue management (admins only)
+	venueAdminRouter := router.PathPrefix("/api/venues").Subrouter()
+	venueAdminRouter.Use(middleware.JWTMiddleware(jwtService))
+	venueAdminRouter.Use(userRateLimited)
+	venueAdminRouter.HandleFunc("", venueAdminHandler.CreateVenue).Methods("POST", "OPTIONS")
+	venueAdminRouter.HandleFunc("/test-scrape", venueAdminHandler.TestScrape).Methods("POST", "OPTIONS")
+	venueAdminRouter.HandleFunc("/{id}", venueAdminHandler.UpdateVenue).Methods("PUT", "OPTIONS")
+	venueAdminRouter.HandleFunc("/{id}", venueAdminHandler.DeleteVenue).Methods("DELETE", "OPTIONS")
+
+	// Live availability over WebSocket, authenticated by a token in the query string
+	router.HandleFunc("/api/ws/slots", slotStreamHandler.StreamSlots).Methods("GET")
 
 	// System endpoints
 	systemRouter := router.PathPrefix("/api/system").Subrouter()
 	systemRouter.HandleFunc("/status", systemHandler.GetStatus).Methods("GET", "OPTIONS")
+	systemRouter.HandleFunc("/features", systemHandler.GetFeatures).Methods("GET", "OPTIONS")
 	systemRouter.HandleFunc("/logs", systemHandler.GetScrapingLogs).Methods("GET", "OPTIONS")
+	systemRouter.HandleFunc("/events", systemHandler.StreamEvents).Methods("GET")
 	systemRouter.HandleFunc("/pause", systemHandler.PauseScraping).Methods("POST", "OPTIONS")
 	systemRouter.HandleFunc("/resume", systemHandler.ResumeScraping).Methods("POST", "OPTIONS")
 	systemRouter.HandleFunc("/restart", systemHandler.RestartSystem).Methods("POST", "OPTIONS")
 
+	// Protected system endpoints (admins only)
+	if rateLimiter != nil {
+		rateLimitHandler := handlers.NewRateLimitHandler(rateLimiter)
+		adminSystemRouter := systemRouter.PathPrefix("").Subrouter()
+		adminSystemRouter.Use(middleware.JWTMiddleware(jwtService))
+		adminSystemRouter.Use(userRateLimited)
+		adminSystemRouter.HandleFunc("/rate-limit-status/{key}", rateLimitHandler.GetLimitState).Methods("GET", "OPTIONS")
+	}
+
 	// Start server
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%s", cfg.Server.Port),
@@ -159,6 +374,14 @@ func main() {
 		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout) * time.Second,
 	}
 
+	// Shutdown doesn't close hijacked WebSocket connections, and waits for
+	// streaming responses that never go idle
+	srv.RegisterOnShutdown(slotStreamHandler.Close)
+	srv.RegisterOnShutdown(systemHandler.Close)
+
+	// Apply runtime-safe settings on SIGHUP
+	go reloadOnSIGHUP(liveConfig, rateLimiter, logger)
+
 	// Channel to listen for interrupt signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+
+	"tennis-booker/internal/config"
+	"tennis-booker/internal/logging"
+	"tennis-booker/internal/ratelimit"
+)
+
+// reloadOnSIGHUP reloads the configuration each time the process receives
+// SIGHUP, e.g. from `kill -HUP <pid>`, without dropping connections
+func reloadOnSIGHUP(live *config.Live, rateLimiter *ratelimit.Limiter, logger *logging.Logger) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		reloadConfig(live, rateLimiter, logger)
+	}
+}
+
+// reloadConfig re-reads the configuration and applies the settings that can
+// change at runtime. Settings that can't, such as the listen port, are logged
+// as requiring a restart.
+func reloadConfig(live *config.Live, rateLimiter *ratelimit.Limiter, logger *logging.Logger) {
+	logger.Info("Reloading configuration")
+
+	result, err := live.Reload()
+	if err != nil {
+		logger.Error("Configuration reload failed, keeping the running configuration", map[string]interface{}{"error": err.Error()})
+		return
+	}
+
+	applyLogLevel(live.Get(), logger)
+	for _, setting := range result.RestartRequired {
+		logger.Warn("Configuration change requires restart", map[string]interface{}{"setting": setting})
+	}
+
+	if rateLimiter != nil {
+		if changed := reloadRateLimits(rateLimiter, logger); changed {
+			result.Applied = append(result.Applied, "rate limits")
+		}
+	}
+
+	logger.Info("Configuration reloaded", map[string]interface{}{"applied": result.Applied})
+}
+
+// applyLogLevel sets the level of every logger from the configuration
+func applyLogLevel(cfg *config.Config, logger *logging.Logger) {
+	level, err := logging.ParseLevel(cfg.Logging.Level)
+	if err != nil {
+		logger.Warn("Ignoring invalid log level", map[string]interface{}{"error": err.Error()})
+		return
+	}
+	logging.SetLevel(level)
+}
+
+// reloadRateLimits applies rate limits from the environment to the running
+// limiter and reports whether any changed
+func reloadRateLimits(rateLimiter *ratelimit.Limiter, logger *logging.Logger) bool {
+	next, err := ratelimit.ConfigFromEnv()
+	if err != nil {
+		logger.Error("Rate limit reload failed, keeping the running limits", map[string]interface{}{"error": err.Error()})
+		return false
+	}
+
+	running := rateLimiter.GetConfig()
+	if !reflect.DeepEqual(running.TrustedProxies, next.TrustedProxies) {
+		logger.Warn("Configuration change requires restart", map[string]interface{}{"setting": ratelimit.TrustedProxiesEnv})
+	}
+	if !reflect.DeepEqual(running.ExemptRoles, next.ExemptRoles) {
+		logger.Warn("Configuration change requires restart", map[string]interface{}{"setting": ratelimit.ExemptRolesEnv})
+	}
+	if running.Store != next.Store {
+		logger.Warn("Configuration change requires restart", map[string]interface{}{"setting": ratelimit.StoreEnv})
+	}
+
+	changed, err := rateLimiter.UpdateLimits(next)
+	if err != nil {
+		logger.Error("Rate limit reload failed, keeping the running limits", map[string]interface{}{"error": err.Error()})
+		return false
+	}
+	return changed
+}
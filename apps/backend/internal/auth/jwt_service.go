@@ -2,6 +2,9 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"time"
 
@@ -25,15 +28,37 @@ type JWTSecretsProvider interface {
 type JWTService struct {
 	secretsProvider JWTSecretsProvider
 	issuer          string
+	revocationStore TokenRevocationStore // Optional; without it tokens can't be revoked before expiry
 }
 
 // AppClaims represents the custom claims for our application
 type AppClaims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
+	Role     string `json:"role,omitempty"`    // Empty for regular users
+	Purpose  string `json:"purpose,omitempty"` // Empty for access and refresh tokens
 	jwt.RegisteredClaims
 }
 
+// RoleAdmin is the role claim given to administrators
+const RoleAdmin = "admin"
+
+// Lifetimes of the access and refresh tokens issued at login
+const (
+	AccessTokenTTL  = 24 * time.Hour
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// TwoFactorChallengePurpose marks the short-lived token Login returns when a
+// second factor is still needed. It is only accepted by ValidateTwoFactorChallenge.
+const TwoFactorChallengePurpose = "2fa_challenge"
+
+// TwoFactorChallengeTTL is how long the user has to enter their 2FA code after their password
+const TwoFactorChallengeTTL = 5 * time.Minute
+
+// ErrWrongTokenPurpose is returned when a token is used for something it wasn't issued for
+var ErrWrongTokenPurpose = errors.New("token is not valid for this purpose")
+
 // NewJWTService creates a new JWT service with the provided secrets provider
 func NewJWTService(secretsProvider JWTSecretsProvider, issuer string) *JWTService {
 	return &JWTService{
@@ -42,24 +67,54 @@ func NewJWTService(secretsProvider JWTSecretsProvider, issuer string) *JWTServic
 	}
 }
 
+// SetRevocationStore enables token revocation, checked on every ValidateToken call
+func (js *JWTService) SetRevocationStore(store TokenRevocationStore) {
+	js.revocationStore = store
+}
+
 // GenerateToken generates a new JWT token for the given user
 func (js *JWTService) GenerateToken(userID, username string, expirationDuration time.Duration) (string, error) {
+	return js.generateToken(userID, username, "", "", expirationDuration)
+}
+
+// GenerateTokenWithRole generates a new JWT token carrying the user's role
+func (js *JWTService) GenerateTokenWithRole(userID, username, role string, expirationDuration time.Duration) (string, error) {
+	return js.generateToken(userID, username, role, "", expirationDuration)
+}
+
+// GenerateTwoFactorChallenge generates the token that stands in for a login until
+// the user's 2FA code is checked
+func (js *JWTService) GenerateTwoFactorChallenge(userID, username string) (string, error) {
+	return js.generateToken(userID, username, "", TwoFactorChallengePurpose, TwoFactorChallengeTTL)
+}
+
+// generateToken generates a signed token with the given role and purpose
+func (js *JWTService) generateToken(userID, username, role, purpose string, expirationDuration time.Duration) (string, error) {
 	// Fetch JWT secret from Vault
 	jwtSecret, err := js.secretsProvider.GetJWTSecret()
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch JWT secret from Vault: %w", err)
 	}
 
+	// Unique token ID so the token can be revoked on logout
+	tokenID, err := generateTokenID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+
 	// Create claims
 	claims := AppClaims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
+		Purpose:  purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expirationDuration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    js.issuer,
 			Subject:   userID,
+			ID:        tokenID,
 		},
 	}
 
@@ -75,8 +130,32 @@ func (js *JWTService) GenerateToken(userID, username string, expirationDuration
 	return tokenString, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
+// ValidateToken validates an access or refresh token and returns the claims
 func (js *JWTService) ValidateToken(tokenString string) (*AppClaims, error) {
+	claims, err := js.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, ErrWrongTokenPurpose
+	}
+	return claims, nil
+}
+
+// ValidateTwoFactorChallenge validates a token from GenerateTwoFactorChallenge
+func (js *JWTService) ValidateTwoFactorChallenge(tokenString string) (*AppClaims, error) {
+	claims, err := js.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != TwoFactorChallengePurpose {
+		return nil, ErrWrongTokenPurpose
+	}
+	return claims, nil
+}
+
+// parseToken verifies a token's signature, expiry and revocation status
+func (js *JWTService) parseToken(tokenString string) (*AppClaims, error) {
 	// Fetch JWT secret from Vault
 	jwtSecret, err := js.secretsProvider.GetJWTSecret()
 	if err != nil {
@@ -97,17 +176,98 @@ func (js *JWTService) ValidateToken(tokenString string) (*AppClaims, error) {
 	}
 
 	// Extract and validate claims
-	if claims, ok := token.Claims.(*AppClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*AppClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("invalid JWT token or claims")
 	}
 
-	return nil, fmt.Errorf("invalid JWT token or claims")
+	// Reject revoked tokens. Tokens issued before token IDs were added can't be
+	// revoked and stay valid until they expire.
+	if js.revocationStore != nil && claims.ID != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), revocationTimeout)
+		defer cancel()
+
+		revoked, err := js.revocationStore.IsRevoked(ctx, claims.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to check token revocation: %v", ErrRevocationStore, err)
+		}
+		if revoked {
+			return nil, ErrTokenRevoked
+		}
+	}
+
+	return claims, nil
+}
+
+// RevokeToken revokes a token so ValidateToken rejects it for the rest of its
+// lifetime. Revoking an already revoked or expired token is a no-op.
+func (js *JWTService) RevokeToken(tokenString string) error {
+	if js.revocationStore == nil {
+		return ErrRevocationUnavailable
+	}
+
+	claims, err := js.ValidateToken(tokenString)
+	if err != nil {
+		if errors.Is(err, ErrTokenRevoked) || errors.Is(err, jwt.ErrTokenExpired) {
+			return nil
+		}
+		return err
+	}
+	if claims.ID == "" {
+		return ErrTokenNotRevocable
+	}
+
+	// Keep the revocation only as long as the token would have been valid
+	var ttl time.Duration // Zero keeps it forever, for tokens without an expiry
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+		if ttl <= 0 {
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), revocationTimeout)
+	defer cancel()
+
+	if err := js.revocationStore.Revoke(ctx, claims.ID, ttl); err != nil {
+		return fmt.Errorf("%w: failed to revoke token: %v", ErrRevocationStore, err)
+	}
+	return nil
+}
+
+// generateTokenID returns a random 128-bit token ID for the jti claim
+func generateTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
 }
 
 // GenerateRefreshToken generates a refresh token with longer expiration
 func (js *JWTService) GenerateRefreshToken(userID, username string) (string, error) {
-	// Refresh tokens typically have longer expiration (e.g., 7 days)
-	return js.GenerateToken(userID, username, 7*24*time.Hour)
+	return js.GenerateToken(userID, username, RefreshTokenTTL)
+}
+
+// ExpiresIn returns the whole seconds left before a token expires, read from its
+// exp claim, so responses report the lifetime the token was actually minted with.
+// It is meant for tokens the service has just generated and doesn't re-check the
+// signature.
+func (js *JWTService) ExpiresIn(tokenString string) (int64, error) {
+	claims := &AppClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, claims); err != nil {
+		return 0, fmt.Errorf("failed to parse JWT token: %w", err)
+	}
+	if claims.ExpiresAt == nil {
+		return 0, errors.New("token has no expiry")
+	}
+
+	// exp is truncated to the second, so compare whole seconds to report the full TTL
+	remaining := claims.ExpiresAt.Unix() - time.Now().Unix()
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
 }
 
 // RefreshAccessToken generates a new access token from a valid refresh token
@@ -119,7 +279,7 @@ func (js *JWTService) RefreshAccessToken(refreshToken string, accessTokenDuratio
 	}
 
 	// Generate new access token with the same user info
-	return js.GenerateToken(claims.UserID, claims.Username, accessTokenDuration)
+	return js.GenerateTokenWithRole(claims.UserID, claims.Username, claims.Role, accessTokenDuration)
 }
 
 // GetUserClaimsFromContext extracts user claims from the request context
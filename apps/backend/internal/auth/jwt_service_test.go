@@ -107,6 +107,30 @@ func TestJWTService_ValidateToken(t *testing.T) {
 	mockSecretsProvider.AssertExpectations(t)
 }
 
+func TestJWTService_ExpiresIn(t *testing.T) {
+	mockSecretsProvider := &MockJWTSecretsProvider{}
+	jwtService := NewJWTService(mockSecretsProvider, "tennis-booker")
+	mockSecretsProvider.On("GetJWTSecret").Return("test-secret-key", nil)
+
+	for _, ttl := range []time.Duration{15 * time.Minute, AccessTokenTTL, RefreshTokenTTL} {
+		token, err := jwtService.GenerateToken("user123", "testuser", ttl)
+		require.NoError(t, err)
+
+		seconds, err := jwtService.ExpiresIn(token)
+		require.NoError(t, err)
+		assert.InDelta(t, ttl.Seconds(), float64(seconds), 1, ttl.String()) // A second may tick over after minting
+	}
+
+	expired, err := jwtService.GenerateToken("user123", "testuser", -time.Minute)
+	require.NoError(t, err)
+	seconds, err := jwtService.ExpiresIn(expired)
+	require.NoError(t, err)
+	assert.Zero(t, seconds)
+
+	_, err = jwtService.ExpiresIn("not-a-token")
+	assert.Error(t, err)
+}
+
 func TestJWTService_ValidateToken_InvalidToken(t *testing.T) {
 	mockSecretsProvider := &MockJWTSecretsProvider{}
 	jwtService := NewJWTService(mockSecretsProvider, "tennis-booker")
@@ -269,3 +293,51 @@ func TestJWTService_TokenSigningMethod(t *testing.T) {
 
 	mockSecretsProvider.AssertExpectations(t)
 }
+
+func TestJWTService_TwoFactorChallengeIsNotAnAccessToken(t *testing.T) {
+	mockSecretsProvider := &MockJWTSecretsProvider{}
+	jwtService := NewJWTService(mockSecretsProvider, "tennis-booker")
+	mockSecretsProvider.On("GetJWTSecret").Return("test-secret-key", nil)
+
+	challenge, err := jwtService.GenerateTwoFactorChallenge("user123", "testuser")
+	require.NoError(t, err)
+
+	claims, err := jwtService.ValidateTwoFactorChallenge(challenge)
+	require.NoError(t, err)
+	assert.Equal(t, "user123", claims.UserID)
+	assert.Equal(t, TwoFactorChallengePurpose, claims.Purpose)
+
+	_, err = jwtService.ValidateToken(challenge)
+	assert.ErrorIs(t, err, ErrWrongTokenPurpose)
+
+	accessToken, err := jwtService.GenerateToken("user123", "testuser", time.Hour)
+	require.NoError(t, err)
+	_, err = jwtService.ValidateTwoFactorChallenge(accessToken)
+	assert.ErrorIs(t, err, ErrWrongTokenPurpose)
+}
+
+func TestJWTService_RoleClaim(t *testing.T) {
+	mockSecretsProvider := &MockJWTSecretsProvider{}
+	jwtService := NewJWTService(mockSecretsProvider, "tennis-booker")
+	mockSecretsProvider.On("GetJWTSecret").Return("test-secret-key", nil)
+
+	refreshToken, err := jwtService.GenerateTokenWithRole("user123", "admin@example.com", RoleAdmin, time.Hour)
+	require.NoError(t, err)
+
+	claims, err := jwtService.ValidateToken(refreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, RoleAdmin, claims.Role)
+
+	// The role carries over to refreshed access tokens
+	accessToken, err := jwtService.RefreshAccessToken(refreshToken, time.Minute)
+	require.NoError(t, err)
+	claims, err = jwtService.ValidateToken(accessToken)
+	require.NoError(t, err)
+	assert.Equal(t, RoleAdmin, claims.Role)
+
+	plain, err := jwtService.GenerateToken("user456", "user@example.com", time.Hour)
+	require.NoError(t, err)
+	claims, err = jwtService.ValidateToken(plain)
+	require.NoError(t, err)
+	assert.Empty(t, claims.Role)
+}
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockoutDuration(t *testing.T) {
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{0, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{7, 4 * time.Minute},
+		{10, 32 * time.Minute},
+		{11, time.Hour}, // 64 minutes, capped
+		{100, time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, LockoutDuration(tt.failures, DefaultLockoutThreshold, DefaultLockoutBase, DefaultLockoutMax), "failures=%d", tt.failures)
+	}
+}
+
+func TestNormalizeAccount(t *testing.T) {
+	assert.Equal(t, "player@example.com", normalizeAccount("  Player@Example.COM "))
+}
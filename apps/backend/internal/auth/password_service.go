@@ -4,10 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// EnvBcryptCost sets the bcrypt cost for new password hashes
+const EnvBcryptCost = "BCRYPT_COST"
+
 // PasswordService defines the interface for password operations
 type PasswordService interface {
 	HashPassword(ctx context.Context, password string) (string, error)
@@ -33,6 +38,21 @@ func NewBcryptPasswordServiceWithCost(cost int) *BcryptPasswordService {
 	}
 }
 
+// NewBcryptPasswordServiceFromEnv creates a bcrypt password service using BcryptCostFromEnv
+func NewBcryptPasswordServiceFromEnv() *BcryptPasswordService {
+	return NewBcryptPasswordServiceWithCost(BcryptCostFromEnv())
+}
+
+// BcryptCostFromEnv returns BCRYPT_COST, or bcrypt.DefaultCost if it is unset or
+// outside bcrypt's supported range
+func BcryptCostFromEnv() int {
+	cost, err := strconv.Atoi(os.Getenv(EnvBcryptCost))
+	if err != nil || cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return bcrypt.DefaultCost
+	}
+	return cost
+}
+
 // HashPassword hashes a plain text password using bcrypt
 func (s *BcryptPasswordService) HashPassword(ctx context.Context, password string) (string, error) {
 	if password == "" {
@@ -81,6 +101,14 @@ func (s *BcryptPasswordService) VerifyPassword(ctx context.Context, hashedPasswo
 	return nil
 }
 
+// NeedsRehash reports whether a bcrypt hash was made with a lower cost than the
+// service's, so it should be replaced the next time the password is known.
+// Unreadable hashes are left alone.
+func (s *BcryptPasswordService) NeedsRehash(hashedPassword string) bool {
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err == nil && cost < s.cost
+}
+
 // GetCost returns the current bcrypt cost factor
 func (s *BcryptPasswordService) GetCost() int {
 	return s.cost
@@ -265,3 +265,34 @@ func TestBcryptPasswordService_Performance(t *testing.T) {
 		}
 	})
 }
+
+func TestBcryptCostFromEnv(t *testing.T) {
+	t.Setenv(EnvBcryptCost, "")
+	assert.Equal(t, bcrypt.DefaultCost, BcryptCostFromEnv())
+
+	t.Setenv(EnvBcryptCost, "12")
+	assert.Equal(t, 12, BcryptCostFromEnv())
+	assert.Equal(t, 12, NewBcryptPasswordServiceFromEnv().GetCost())
+
+	for _, invalid := range []string{"3", "32", "high"} {
+		t.Setenv(EnvBcryptCost, invalid)
+		assert.Equal(t, bcrypt.DefaultCost, BcryptCostFromEnv(), invalid)
+	}
+}
+
+func TestBcryptPasswordService_NeedsRehash(t *testing.T) {
+	ctx := context.Background()
+	lowCost, err := NewBcryptPasswordServiceWithCost(bcrypt.MinCost).HashPassword(ctx, "password123")
+	require.NoError(t, err)
+
+	service := NewBcryptPasswordServiceWithCost(bcrypt.MinCost + 1)
+	assert.True(t, service.NeedsRehash(lowCost))
+
+	current, err := service.HashPassword(ctx, "password123")
+	require.NoError(t, err)
+	assert.False(t, service.NeedsRehash(current))
+
+	// A lower target never downgrades a stronger hash
+	assert.False(t, NewBcryptPasswordServiceWithCost(bcrypt.MinCost).NeedsRehash(current))
+	assert.False(t, service.NeedsRehash("not-a-bcrypt-hash"))
+}
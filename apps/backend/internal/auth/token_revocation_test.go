@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRevocationStore is an in-memory TokenRevocationStore for tests
+type memoryRevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Duration
+	err     error
+}
+
+func newMemoryRevocationStore() *memoryRevocationStore {
+	return &memoryRevocationStore{revoked: make(map[string]time.Duration)}
+}
+
+func (m *memoryRevocationStore) Revoke(ctx context.Context, tokenID string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.revoked[tokenID] = ttl
+	return nil
+}
+
+func (m *memoryRevocationStore) IsRevoked(ctx context.Context, tokenID string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return false, m.err
+	}
+	_, ok := m.revoked[tokenID]
+	return ok, nil
+}
+
+func newRevocableJWTService() (*JWTService, *memoryRevocationStore) {
+	provider := &MockJWTSecretsProvider{}
+	provider.On("GetJWTSecret").Return("test-secret-key", nil)
+
+	store := newMemoryRevocationStore()
+	jwtService := NewJWTService(provider, "tennis-booker")
+	jwtService.SetRevocationStore(store)
+	return jwtService, store
+}
+
+func TestJWTService_GenerateToken_UniqueTokenIDs(t *testing.T) {
+	jwtService, _ := newRevocableJWTService()
+
+	first, err := jwtService.GenerateToken("user123", "testuser", time.Hour)
+	require.NoError(t, err)
+	second, err := jwtService.GenerateToken("user123", "testuser", time.Hour)
+	require.NoError(t, err)
+
+	firstClaims, err := jwtService.ValidateToken(first)
+	require.NoError(t, err)
+	secondClaims, err := jwtService.ValidateToken(second)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, firstClaims.ID)
+	assert.NotEqual(t, firstClaims.ID, secondClaims.ID)
+}
+
+func TestJWTService_RevokeToken(t *testing.T) {
+	jwtService, store := newRevocableJWTService()
+
+	token, err := jwtService.GenerateToken("user123", "testuser", time.Hour)
+	require.NoError(t, err)
+	other, err := jwtService.GenerateToken("user123", "testuser", time.Hour)
+	require.NoError(t, err)
+
+	require.NoError(t, jwtService.RevokeToken(token))
+
+	_, err = jwtService.ValidateToken(token)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	// Other tokens for the same user are unaffected
+	_, err = jwtService.ValidateToken(other)
+	assert.NoError(t, err)
+
+	// The revocation lasts for the token's remaining lifetime
+	require.Len(t, store.revoked, 1)
+	for _, ttl := range store.revoked {
+		assert.Greater(t, ttl, 59*time.Minute)
+		assert.LessOrEqual(t, ttl, time.Hour)
+	}
+
+	// Revoking again is a no-op
+	assert.NoError(t, jwtService.RevokeToken(token))
+}
+
+func TestJWTService_RevokeToken_RefreshTokenCannotBeUsed(t *testing.T) {
+	jwtService, _ := newRevocableJWTService()
+
+	refreshToken, err := jwtService.GenerateRefreshToken("user123", "testuser")
+	require.NoError(t, err)
+	require.NoError(t, jwtService.RevokeToken(refreshToken))
+
+	_, err = jwtService.RefreshAccessToken(refreshToken, time.Hour)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestJWTService_RevokeToken_Errors(t *testing.T) {
+	jwtService, store := newRevocableJWTService()
+
+	// Garbage tokens are rejected
+	assert.Error(t, jwtService.RevokeToken("not-a-token"))
+
+	// Expired tokens need no revocation
+	expired, err := jwtService.GenerateToken("user123", "testuser", -time.Minute)
+	require.NoError(t, err)
+	assert.NoError(t, jwtService.RevokeToken(expired))
+	assert.Empty(t, store.revoked)
+
+	// Without a store there is nowhere to record the revocation
+	provider := &MockJWTSecretsProvider{}
+	provider.On("GetJWTSecret").Return("test-secret-key", nil)
+	withoutStore := NewJWTService(provider, "tennis-booker")
+	token, err := withoutStore.GenerateToken("user123", "testuser", time.Hour)
+	require.NoError(t, err)
+	assert.ErrorIs(t, withoutStore.RevokeToken(token), ErrRevocationUnavailable)
+}
+
+func TestJWTService_ValidateToken_RevocationStoreError(t *testing.T) {
+	jwtService, store := newRevocableJWTService()
+
+	token, err := jwtService.GenerateToken("user123", "testuser", time.Hour)
+	require.NoError(t, err)
+
+	// A token can't be trusted if we can't confirm it hasn't been revoked
+	store.err = errors.New("connection refused")
+	_, err = jwtService.ValidateToken(token)
+	assert.ErrorIs(t, err, ErrRevocationStore)
+	assert.ErrorIs(t, jwtService.RevokeToken(token), ErrRevocationStore)
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcTOTPSecret is the RFC 6238 SHA1 test key "12345678901234567890" in base32
+const rfcTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+type staticTOTPKey struct {
+	key string
+	err error
+}
+
+func (s staticTOTPKey) GetTOTPEncryptionKey() (string, error) {
+	return s.key, s.err
+}
+
+func TestGenerateTOTPCode_RFC6238Vectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := GenerateTOTPCode(rfcTOTPSecret, time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.code, code, "t=%d", tt.unix)
+	}
+}
+
+func TestValidateTOTPCode(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	code, err := GenerateTOTPCode(rfcTOTPSecret, now)
+	require.NoError(t, err)
+
+	step, ok := ValidateTOTPCode(rfcTOTPSecret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/30, step)
+
+	// One step of clock drift either way is allowed, two is not
+	_, ok = ValidateTOTPCode(rfcTOTPSecret, code, now.Add(30*time.Second))
+	assert.True(t, ok)
+	_, ok = ValidateTOTPCode(rfcTOTPSecret, code, now.Add(-30*time.Second))
+	assert.True(t, ok)
+	_, ok = ValidateTOTPCode(rfcTOTPSecret, code, now.Add(90*time.Second))
+	assert.False(t, ok)
+
+	for _, bad := range []string{"", "12345", "1234567", "abcdef"} {
+		_, ok = ValidateTOTPCode(rfcTOTPSecret, bad, now)
+		assert.False(t, ok, bad)
+	}
+
+	_, ok = ValidateTOTPCode("not base32!", code, now)
+	assert.False(t, ok)
+}
+
+func TestGenerateTOTPKey(t *testing.T) {
+	key, err := GenerateTOTPKey("Tennis Booker", "player@example.com")
+	require.NoError(t, err)
+	assert.Len(t, key.Secret(), 32)
+
+	other, err := GenerateTOTPKey("Tennis Booker", "player@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, key.Secret(), other.Secret())
+
+	code, err := GenerateTOTPCode(key.Secret(), time.Now())
+	require.NoError(t, err)
+	_, ok := ValidateTOTPCode(key.Secret(), code, time.Now())
+	assert.True(t, ok)
+
+	raw := key.URL()
+	assert.True(t, strings.HasPrefix(raw, "otpauth://totp/"))
+
+	parsed, err := url.Parse(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "/Tennis Booker:player@example.com", parsed.Path)
+	assert.Equal(t, key.Secret(), parsed.Query().Get("secret"))
+	assert.Equal(t, "Tennis Booker", parsed.Query().Get("issuer"))
+	assert.Equal(t, "6", parsed.Query().Get("digits"))
+	assert.Equal(t, "30", parsed.Query().Get("period"))
+	assert.Equal(t, "SHA1", parsed.Query().Get("algorithm"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(RecoveryCodeCount)
+	require.NoError(t, err)
+	require.Len(t, codes, RecoveryCodeCount)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.Regexp(t, `^[0-9a-f]{5}-[0-9a-f]{5}$`, code)
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+
+	// Hashes ignore case, dashes and surrounding whitespace
+	hash := HashRecoveryCode("abcde-12345")
+	assert.Equal(t, hash, HashRecoveryCode(" ABCDE12345 "))
+	assert.NotEqual(t, hash, HashRecoveryCode("abcde-12346"))
+	assert.NotContains(t, hash, "abcde")
+}
+
+func TestTOTPSecretCipher(t *testing.T) {
+	cipher := NewTOTPSecretCipher(staticTOTPKey{key: "encryption-key"})
+
+	encrypted, err := cipher.Encrypt(rfcTOTPSecret)
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, rfcTOTPSecret)
+
+	again, err := cipher.Encrypt(rfcTOTPSecret)
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again, "each encryption should use a fresh nonce")
+
+	decrypted, err := cipher.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, rfcTOTPSecret, decrypted)
+
+	_, err = NewTOTPSecretCipher(staticTOTPKey{key: "other-key"}).Decrypt(encrypted)
+	assert.ErrorIs(t, err, ErrInvalidTOTPSecret)
+
+	_, err = cipher.Decrypt("garbage")
+	assert.ErrorIs(t, err, ErrInvalidTOTPSecret)
+
+	_, err = NewTOTPSecretCipher(staticTOTPKey{err: errors.New("no key")}).Encrypt(rfcTOTPSecret)
+	assert.Error(t, err)
+}
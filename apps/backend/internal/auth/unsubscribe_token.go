@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultUnsubscribeTokenTTL is how long an unsubscribe link in an email stays valid
+const DefaultUnsubscribeTokenTTL = 30 * 24 * time.Hour
+
+// Unsubscribe token validation errors
+var (
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+	ErrExpiredUnsubscribeToken = errors.New("unsubscribe token has expired")
+)
+
+// unsubscribeTokenPurpose separates unsubscribe signatures from anything else signed with the JWT secret
+const unsubscribeTokenPurpose = "unsubscribe"
+
+// UnsubscribeTokenService issues and verifies signed one-click unsubscribe tokens.
+// A token is "<user id>.<expiry unix>.<signature>" where the signature is an
+// HMAC-SHA256 of the user id and expiry keyed by the JWT secret.
+type UnsubscribeTokenService struct {
+	secretsProvider JWTSecretsProvider
+}
+
+// NewUnsubscribeTokenService creates a token service that signs with the provider's JWT secret
+func NewUnsubscribeTokenService(secretsProvider JWTSecretsProvider) *UnsubscribeTokenService {
+	return &UnsubscribeTokenService{
+		secretsProvider: secretsProvider,
+	}
+}
+
+// GenerateToken creates an unsubscribe token for the user that expires after ttl
+func (s *UnsubscribeTokenService) GenerateToken(userID string, ttl time.Duration) (string, error) {
+	if userID == "" || strings.Contains(userID, ".") {
+		return "", fmt.Errorf("invalid user ID for unsubscribe token: %q", userID)
+	}
+
+	expiry := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	signature, err := s.sign(userID, expiry)
+	if err != nil {
+		return "", err
+	}
+
+	return userID + "." + expiry + "." + signature, nil
+}
+
+// ValidateToken verifies the token's signature and expiry and returns the user ID it was issued for
+func (s *UnsubscribeTokenService) ValidateToken(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return "", ErrInvalidUnsubscribeToken
+	}
+	userID, expiry, signature := parts[0], parts[1], parts[2]
+
+	expected, err := s.sign(userID, expiry)
+	if err != nil {
+		return "", err
+	}
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return "", ErrInvalidUnsubscribeToken
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", ErrInvalidUnsubscribeToken
+	}
+	if time.Now().Unix() > expiresAt {
+		return "", ErrExpiredUnsubscribeToken
+	}
+
+	return userID, nil
+}
+
+// sign returns the base64url HMAC-SHA256 of the user ID and expiry
+func (s *UnsubscribeTokenService) sign(userID, expiry string) (string, error) {
+	secret, err := s.secretsProvider.GetJWTSecret()
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch signing secret: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsubscribeTokenPurpose + ":" + userID + ":" + expiry))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsubscribeTokenService_RoundTrip(t *testing.T) {
+	mockSecretsProvider := &MockJWTSecretsProvider{}
+	mockSecretsProvider.On("GetJWTSecret").Return("test-secret-key", nil)
+	service := NewUnsubscribeTokenService(mockSecretsProvider)
+
+	token, err := service.GenerateToken("507f1f77bcf86cd799439011", time.Hour)
+	require.NoError(t, err)
+
+	userID, err := service.ValidateToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "507f1f77bcf86cd799439011", userID)
+}
+
+func TestUnsubscribeTokenService_RejectsTampering(t *testing.T) {
+	mockSecretsProvider := &MockJWTSecretsProvider{}
+	mockSecretsProvider.On("GetJWTSecret").Return("test-secret-key", nil)
+	service := NewUnsubscribeTokenService(mockSecretsProvider)
+
+	token, err := service.GenerateToken("507f1f77bcf86cd799439011", time.Hour)
+	require.NoError(t, err)
+
+	parts := strings.Split(token, ".")
+	forged := "507f1f77bcf86cd799439012." + parts[1] + "." + parts[2]
+
+	_, err = service.ValidateToken(forged)
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+
+	_, err = service.ValidateToken("not-a-token")
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+}
+
+func TestUnsubscribeTokenService_RejectsOtherSecret(t *testing.T) {
+	signer := &MockJWTSecretsProvider{}
+	signer.On("GetJWTSecret").Return("secret-one", nil)
+	verifier := &MockJWTSecretsProvider{}
+	verifier.On("GetJWTSecret").Return("secret-two", nil)
+
+	token, err := NewUnsubscribeTokenService(signer).GenerateToken("507f1f77bcf86cd799439011", time.Hour)
+	require.NoError(t, err)
+
+	_, err = NewUnsubscribeTokenService(verifier).ValidateToken(token)
+	assert.ErrorIs(t, err, ErrInvalidUnsubscribeToken)
+}
+
+func TestUnsubscribeTokenService_Expired(t *testing.T) {
+	mockSecretsProvider := &MockJWTSecretsProvider{}
+	mockSecretsProvider.On("GetJWTSecret").Return("test-secret-key", nil)
+	service := NewUnsubscribeTokenService(mockSecretsProvider)
+
+	token, err := service.GenerateToken("507f1f77bcf86cd799439011", -time.Minute)
+	require.NoError(t, err)
+
+	_, err = service.ValidateToken(token)
+	assert.ErrorIs(t, err, ErrExpiredUnsubscribeToken)
+}
@@ -6,8 +6,6 @@ import (
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/joho/godotenv"
 )
 
 // Config holds all configuration for the application
@@ -17,8 +15,25 @@ type Config struct {
 	Redis   RedisConfig
 	JWT     JWTConfig
 	Email   EmailConfig
+	SMS     SMSConfig
 	CORS    CORSConfig
 	Scraper ScraperConfig
+	Logging LoggingConfig
+
+	// Features maps feature flag names to whether they're enabled
+	Features map[string]bool
+}
+
+// Feature flags, each toggled with FEATURE_<NAME>=true|false
+const (
+	FeatureAnalytics     = "analytics"
+	FeatureNotifications = "notifications"
+)
+
+// defaultFeatures lists the known feature flags and whether they're on by default
+var defaultFeatures = map[string]bool{
+	FeatureAnalytics:     true,
+	FeatureNotifications: true,
 }
 
 // ServerConfig holds server-specific configuration
@@ -64,6 +79,13 @@ type EmailConfig struct {
 	FromEmail    string
 }
 
+// SMSConfig holds Twilio configuration for SMS alerts
+type SMSConfig struct {
+	TwilioAccountSID  string
+	TwilioAuthToken   string
+	TwilioPhoneNumber string
+}
+
 // CORSConfig holds CORS configuration
 type CORSConfig struct {
 	AllowedOrigins []string
@@ -77,19 +99,20 @@ type ScraperConfig struct {
 	Interval int // in minutes
 }
 
+// LoggingConfig holds the settings read by the logging package
+type LoggingConfig struct {
+	Level  string
+	Format string
+}
+
 
 // Global configuration instance
 var AppConfig *Config
 
 // Load loads configuration from environment variables and .env files
 func Load() (*Config, error) {
-	// Try to load .env file (silently fail if not found)
-	if err := godotenv.Load(); err != nil {
-		// Try loading from parent directories for different execution contexts
-		_ = godotenv.Load("../.env")
-		_ = godotenv.Load("../../.env")
-		// Don't fail if .env file is not found - it's optional
-	}
+	// Load .env file if there is one; variables already set take precedence
+	loadEnvFiles(false)
 	return &Config{
 		Server: ServerConfig{
 			Port:         getEnv("PORT", "8080"),
@@ -124,13 +147,13 @@ func Load() (*Config, error) {
 			SMTPPassword: getEnv("GMAIL_PASSWORD", ""),
 			FromEmail:    getEnv("FROM_EMAIL", ""),
 		},
+		SMS: SMSConfig{
+			TwilioAccountSID:  getEnv("TWILIO_ACCOUNT_SID", ""),
+			TwilioAuthToken:   getEnv("TWILIO_AUTH_TOKEN", ""),
+			TwilioPhoneNumber: getEnv("TWILIO_PHONE_NUMBER", ""),
+		},
 		CORS: CORSConfig{
-			AllowedOrigins: getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{
-				"http://localhost:3000",
-				"http://localhost:5173",
-				"http://127.0.0.1:3000",
-				"http://127.0.0.1:5173",
-			}),
+			AllowedOrigins: corsAllowedOrigins(),
 			AllowedMethods: getEnvAsSlice("CORS_ALLOWED_METHODS", []string{
 				"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH",
 			}),
@@ -142,9 +165,39 @@ func Load() (*Config, error) {
 			Enabled:  getEnvAsBool("SCRAPER_ENABLED", true),
 			Interval: getEnvAsInt("SCRAPER_INTERVAL", 30), // 30 minutes
 		},
+		Logging: LoggingConfig{
+			Level:  getEnv("LOG_LEVEL", "INFO"),
+			Format: getEnv("LOG_FORMAT", "text"),
+		},
+		Features: loadFeatures(),
 	}, nil
 }
 
+// corsAllowedOrigins reads CORS_ALLOWED_ORIGINS, always including FRONTEND_URL
+// so the deployed frontend doesn't have to be listed twice
+func corsAllowedOrigins() []string {
+	origins := getEnvAsSlice("CORS_ALLOWED_ORIGINS", []string{
+		"http://localhost:3000",
+		"http://localhost:5173",
+		"http://127.0.0.1:3000",
+		"http://127.0.0.1:5173",
+	})
+	if frontendURL := strings.TrimSuffix(os.Getenv("FRONTEND_URL"), "/"); frontendURL != "" {
+		origins = append(origins, frontendURL)
+	}
+	return origins
+}
+
+// AllowsAnyOrigin reports whether the "*" wildcard is configured, which should only be used in development
+func (c CORSConfig) AllowsAnyOrigin() bool {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 // IsProduction returns true if running in production environment
 func (c *Config) IsProduction() bool {
 	return c.Server.Environment == "production"
@@ -184,123 +237,133 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 
 func getEnvAsSlice(key string, defaultValue []string) []string {
 	if value := os.Getenv(key); value != "" {
-		return strings.Split(value, ",")
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
 	}
 	return defaultValue
 }
 
-// Validate configuration
-func validateConfig(config *Config) error {
-	// Validate required fields
-	if config.Server.Port == "" {
-		return fmt.Errorf("server.port is required")
-	}
+// logLevels are the LOG_LEVEL values understood by the logging package
+var logLevels = []string{"DEBUG", "INFO", "WARN", "ERROR", "FATAL"}
 
-	if config.Server.Host == "" {
-		return fmt.Errorf("server.host is required")
-	}
+// environments are the recognised ENVIRONMENT values
+var environments = []string{"development", "local", "test", "production"}
 
-	if config.Server.ReadTimeout <= 0 {
-		return fmt.Errorf("server.readTimeout must be positive")
+// Validate checks the configuration for values that would otherwise only fail
+// at runtime, and returns a single error listing every problem found
+func (c *Config) Validate() error {
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
 	}
 
-	if config.Server.WriteTimeout <= 0 {
-		return fmt.Errorf("server.writeTimeout must be positive")
+	if !validPort(c.Server.Port) {
+		addProblem("PORT must be a number between 1 and 65535, got %q", c.Server.Port)
 	}
-
-	if config.Server.IdleTimeout <= 0 {
-		return fmt.Errorf("server.idleTimeout must be positive")
+	if c.Server.Host == "" {
+		addProblem("HOST is required")
 	}
-
-	if config.Server.Environment == "" {
-		return fmt.Errorf("server.environment is required")
+	if c.Server.ReadTimeout <= 0 {
+		addProblem("READ_TIMEOUT must be positive, got %d", c.Server.ReadTimeout)
 	}
-
-	if config.MongoDB.URI == "" {
-		return fmt.Errorf("mongoDB.uri is required")
+	if c.Server.WriteTimeout <= 0 {
+		addProblem("WRITE_TIMEOUT must be positive, got %d", c.Server.WriteTimeout)
 	}
-
-	if config.MongoDB.Database == "" {
-		return fmt.Errorf("mongoDB.database is required")
+	if c.Server.IdleTimeout <= 0 {
+		addProblem("IDLE_TIMEOUT must be positive, got %d", c.Server.IdleTimeout)
 	}
-
-	if config.MongoDB.Username == "" {
-		return fmt.Errorf("mongoDB.username is required")
+	if !contains(environments, c.Server.Environment) {
+		addProblem("ENVIRONMENT must be one of %s, got %q", strings.Join(environments, ", "), c.Server.Environment)
 	}
 
-	if config.MongoDB.Password == "" {
-		return fmt.Errorf("mongoDB.password is required")
+	if c.MongoDB.Database == "" {
+		addProblem("DB_NAME is required")
 	}
-
-	if config.MongoDB.Host == "" {
-		return fmt.Errorf("mongoDB.host is required")
+	if c.MongoDB.URI == "" {
+		if c.MongoDB.Host == "" {
+			addProblem("MONGO_URI or MONGO_HOST is required")
+		}
+		if !validPort(c.MongoDB.Port) {
+			addProblem("MONGO_PORT must be a number between 1 and 65535, got %q", c.MongoDB.Port)
+		}
 	}
 
-	if config.MongoDB.Port == "" {
-		return fmt.Errorf("mongoDB.port is required")
+	if c.Redis.Address == "" {
+		addProblem("REDIS_ADDR is required")
 	}
-
-	if config.Redis.Address == "" {
-		return fmt.Errorf("redis.address is required")
+	if c.Redis.DB < 0 {
+		addProblem("REDIS_DB must not be negative, got %d", c.Redis.DB)
 	}
 
-	if config.Redis.DB < 0 {
-		return fmt.Errorf("redis.db must be non-negative")
+	if c.JWT.Issuer == "" {
+		addProblem("JWT_ISSUER is required")
 	}
-
-	if config.JWT.Issuer == "" {
-		return fmt.Errorf("jwt.issuer is required")
+	if c.JWT.AccessTokenTTL <= 0 {
+		addProblem("JWT_ACCESS_TTL must be positive, got %d", c.JWT.AccessTokenTTL)
 	}
-
-	if config.JWT.AccessTokenTTL <= 0 {
-		return fmt.Errorf("jwt.accessTokenTTL must be positive")
+	if c.JWT.RefreshTokenTTL <= 0 {
+		addProblem("JWT_REFRESH_TTL must be positive, got %d", c.JWT.RefreshTokenTTL)
 	}
 
-	if config.JWT.RefreshTokenTTL <= 0 {
-		return fmt.Errorf("jwt.refreshTokenTTL must be positive")
+	if c.Email.Configured() && !validPort(c.Email.SMTPPort) {
+		addProblem("SMTP_PORT must be a number between 1 and 65535, got %q", c.Email.SMTPPort)
 	}
-
-	if config.Email.SMTPHost == "" {
-		return fmt.Errorf("email.smtpHost is required")
+	// Alerts are the point of the service, so production must be able to send them
+	if c.IsProduction() && !c.Email.Configured() && !c.SMS.Configured() {
+		addProblem("at least one notification channel must be configured: set GMAIL_EMAIL and GMAIL_PASSWORD, or TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_PHONE_NUMBER")
 	}
 
-	if config.Email.SMTPPort == "" {
-		return fmt.Errorf("email.smtpPort is required")
+	if len(c.CORS.AllowedOrigins) == 0 {
+		addProblem("CORS_ALLOWED_ORIGINS must not be empty")
 	}
-
-	if config.Email.SMTPUsername == "" {
-		return fmt.Errorf("email.smtpUsername is required")
+	if len(c.CORS.AllowedMethods) == 0 {
+		addProblem("CORS_ALLOWED_METHODS must not be empty")
 	}
 
-	if config.Email.SMTPPassword == "" {
-		return fmt.Errorf("email.smtpPassword is required")
+	if c.Scraper.Interval <= 0 {
+		addProblem("SCRAPER_INTERVAL must be a positive number of minutes, got %d", c.Scraper.Interval)
 	}
 
-	if config.Email.FromEmail == "" {
-		return fmt.Errorf("email.fromEmail is required")
+	if !contains(logLevels, strings.ToUpper(c.Logging.Level)) {
+		addProblem("LOG_LEVEL must be one of %s, got %q", strings.Join(logLevels, ", "), c.Logging.Level)
 	}
-
-	if len(config.CORS.AllowedOrigins) == 0 {
-		return fmt.Errorf("cors.allowedOrigins is required")
+	if c.Logging.Format != "text" && c.Logging.Format != "json" {
+		addProblem("LOG_FORMAT must be text or json, got %q", c.Logging.Format)
 	}
 
-	if len(config.CORS.AllowedMethods) == 0 {
-		return fmt.Errorf("cors.allowedMethods is required")
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
 	}
+	return nil
+}
 
-	if len(config.CORS.AllowedHeaders) == 0 {
-		return fmt.Errorf("cors.allowedHeaders is required")
-	}
+// Configured reports whether SMTP credentials are set
+func (e EmailConfig) Configured() bool {
+	return e.SMTPHost != "" && e.SMTPPort != "" && e.SMTPUsername != "" && e.SMTPPassword != ""
+}
 
-	if !config.Scraper.Enabled {
-		return fmt.Errorf("scraper.enabled must be true")
-	}
+// Configured reports whether Twilio credentials are set
+func (s SMSConfig) Configured() bool {
+	return s.TwilioAccountSID != "" && s.TwilioAuthToken != "" && s.TwilioPhoneNumber != ""
+}
 
-	if config.Scraper.Interval <= 0 {
-		return fmt.Errorf("scraper.interval must be positive")
-	}
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 1 && n <= 65535
+}
 
-	return nil
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
 
 // Set global config
@@ -318,10 +381,27 @@ func (c *Config) GetDatabaseTimeout() time.Duration {
 	return time.Duration(c.Server.IdleTimeout) * time.Second
 }
 
-// IsFeatureEnabled checks if a feature flag is enabled
+// loadFeatures reads FEATURE_<NAME> for each known feature flag
+func loadFeatures() map[string]bool {
+	features := make(map[string]bool, len(defaultFeatures))
+	for feature, enabled := range defaultFeatures {
+		features[feature] = getEnvAsBool("FEATURE_"+strings.ToUpper(feature), enabled)
+	}
+	return features
+}
+
+// IsFeatureEnabled checks if a feature flag is enabled. Unknown features are disabled.
 func (c *Config) IsFeatureEnabled(feature string) bool {
-	// Implementation of IsFeatureEnabled method
-	return false // Placeholder, actual implementation needed
+	return c.Features[feature]
+}
+
+// FeatureFlags returns a copy of every feature flag's state
+func (c *Config) FeatureFlags() map[string]bool {
+	flags := make(map[string]bool, len(c.Features))
+	for feature, enabled := range c.Features {
+		flags[feature] = enabled
+	}
+	return flags
 }
 
 // GetScraperIntervalDuration returns the scraper interval as a time.Duration
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -88,47 +89,124 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func validTestConfig() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:         "8080",
+			Host:         "localhost",
+			ReadTimeout:  30,
+			WriteTimeout: 30,
+			IdleTimeout:  120,
+			Environment:  "test",
+		},
+		MongoDB: MongoDBConfig{
+			Database: "tennis_booking",
+			Host:     "localhost",
+			Port:     "27017",
+		},
+		Redis: RedisConfig{Address: "localhost:6379"},
+		JWT: JWTConfig{
+			Issuer:          "tennis-booker",
+			AccessTokenTTL:  24,
+			RefreshTokenTTL: 168,
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: []string{"http://localhost:3000"},
+			AllowedMethods: []string{"GET"},
+		},
+		Scraper: ScraperConfig{
+			Enabled:  true,
+			Interval: 30,
+		},
+		Logging: LoggingConfig{Level: "info", Format: "text"},
+	}
+}
+
 func TestConfigValidation(t *testing.T) {
 	tests := []struct {
-		name        string
-		config      *Config
-		expectError bool
+		name     string
+		modify   func(c *Config)
+		problems []string
 	}{
 		{
-			name: "valid configuration",
-			config: &Config{
-				Server: ServerConfig{
-					Port:        "8080",
-					Host:        "localhost",
-					Environment: "test",
-				},
-				MongoDB: MongoDBConfig{
-					Database: "tennis_booking",
-					Host:     "localhost",
-					Port:     "27017",
-				},
-				Scraper: ScraperConfig{
-					Enabled:  true,
-					Interval: 30,
-				},
+			name:   "valid configuration",
+			modify: func(c *Config) {},
+		},
+		{
+			name: "mongo URI without host",
+			modify: func(c *Config) {
+				c.MongoDB = MongoDBConfig{URI: "mongodb://mongo:27017", Database: "tennis_booking"}
+			},
+		},
+		{
+			name:     "typo'd port",
+			modify:   func(c *Config) { c.Server.Port = "80800" },
+			problems: []string{"PORT must be a number between 1 and 65535"},
+		},
+		{
+			name:     "non-positive scraper interval",
+			modify:   func(c *Config) { c.Scraper.Interval = 0 },
+			problems: []string{"SCRAPER_INTERVAL must be a positive number"},
+		},
+		{
+			name: "unknown log settings",
+			modify: func(c *Config) {
+				c.Logging = LoggingConfig{Level: "verbose", Format: "xml"}
 			},
-			expectError: false,
+			problems: []string{"LOG_LEVEL must be one of", "LOG_FORMAT must be text or json"},
 		},
 		{
-			name:        "empty config",
-			config:      &Config{},
-			expectError: false, // Our config doesn't require validation
+			name:     "production without a notification channel",
+			modify:   func(c *Config) { c.Server.Environment = "production" },
+			problems: []string{"at least one notification channel must be configured"},
+		},
+		{
+			name: "production with SMS only",
+			modify: func(c *Config) {
+				c.Server.Environment = "production"
+				c.SMS = SMSConfig{TwilioAccountSID: "AC123", TwilioAuthToken: "token", TwilioPhoneNumber: "+441234567890"}
+			},
+		},
+		{
+			name: "every problem is reported",
+			modify: func(c *Config) {
+				c.Server.Environment = "staging"
+				c.MongoDB = MongoDBConfig{}
+				c.Redis.Address = ""
+				c.JWT.AccessTokenTTL = -1
+			},
+			problems: []string{"ENVIRONMENT must be one of", "DB_NAME is required", "MONGO_URI or MONGO_HOST is required", "MONGO_PORT must be", "REDIS_ADDR is required", "JWT_ACCESS_TTL must be positive"},
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Since our config doesn't have validation, just test that it exists
-			assert.NotNil(t, tt.config)
+			config := validTestConfig()
+			tt.modify(config)
+
+			err := config.Validate()
+			if len(tt.problems) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, problem := range tt.problems {
+				assert.Contains(t, err.Error(), problem)
+			}
+			assert.Equal(t, len(tt.problems), strings.Count(err.Error(), "\n  - "))
 		})
 	}
 }
 
+func TestLoadedDefaultsAreValid(t *testing.T) {
+	os.Setenv("ENVIRONMENT", "development")
+	defer os.Unsetenv("ENVIRONMENT")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.NoError(t, config.Validate())
+}
+
 func TestConfigHelperMethods(t *testing.T) {
 	config := &Config{
 		Server: ServerConfig{
@@ -167,3 +245,28 @@ func TestIsLocal(t *testing.T) {
 	assert.True(t, config.IsLocal())
 	assert.False(t, config.IsProduction())
 }
+
+func TestCORSAllowedOrigins(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", " https://tennis.example.com, https://*.vercel.app ,")
+	t.Setenv("FRONTEND_URL", "https://app.example.com/")
+
+	origins := corsAllowedOrigins()
+	assert.Equal(t, []string{"https://tennis.example.com", "https://*.vercel.app", "https://app.example.com"}, origins)
+	assert.False(t, CORSConfig{AllowedOrigins: origins}.AllowsAnyOrigin())
+	assert.True(t, CORSConfig{AllowedOrigins: []string{"http://localhost:3000", "*"}}.AllowsAnyOrigin())
+}
+
+func TestFeatureFlags(t *testing.T) {
+	t.Setenv("FEATURE_ANALYTICS", "false")
+
+	config, err := Load()
+	require.NoError(t, err)
+	assert.False(t, config.IsFeatureEnabled(FeatureAnalytics))
+	assert.True(t, config.IsFeatureEnabled(FeatureNotifications), "enabled by default")
+	assert.False(t, config.IsFeatureEnabled("unknown"))
+
+	flags := config.FeatureFlags()
+	assert.Equal(t, map[string]bool{FeatureAnalytics: false, FeatureNotifications: true}, flags)
+	flags[FeatureAnalytics] = true
+	assert.False(t, config.IsFeatureEnabled(FeatureAnalytics), "FeatureFlags returns a copy")
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/joho/godotenv"
+)
+
+// envFiles are read in order when ".env" isn't in the working directory, for
+// binaries started from a subdirectory
+var envFiles = []string{"../.env", "../../.env"}
+
+var (
+	// processEnv records which variables were set before any .env file was read.
+	// They take precedence over .env files, on reload as well as at startup.
+	processEnvOnce sync.Once
+	processEnv     map[string]bool
+
+	// fileEnvKeys are the variables last set from a .env file
+	fileEnvMu   sync.Mutex
+	fileEnvKeys = map[string]bool{}
+)
+
+// loadEnvFiles copies variables from the .env file into the environment. With
+// refresh set, variables previously loaded from the file are overwritten, or
+// unset if they have been removed from it.
+func loadEnvFiles(refresh bool) {
+	processEnvOnce.Do(func() {
+		processEnv = make(map[string]bool)
+		for _, entry := range os.Environ() {
+			key, _, _ := strings.Cut(entry, "=")
+			processEnv[key] = true
+		}
+	})
+
+	values, err := godotenv.Read()
+	if err != nil {
+		// Files read first win, as they do with godotenv.Load
+		values = map[string]string{}
+		for i := len(envFiles) - 1; i >= 0; i-- {
+			fileValues, err := godotenv.Read(envFiles[i])
+			if err != nil {
+				continue
+			}
+			for key, value := range fileValues {
+				values[key] = value
+			}
+		}
+	}
+
+	fileEnvMu.Lock()
+	defer fileEnvMu.Unlock()
+
+	if refresh {
+		for key := range fileEnvKeys {
+			if _, ok := values[key]; !ok {
+				os.Unsetenv(key)
+				delete(fileEnvKeys, key)
+			}
+		}
+	}
+	for key, value := range values {
+		if processEnv[key] {
+			continue
+		}
+		if _, set := os.LookupEnv(key); set && !(refresh && fileEnvKeys[key]) {
+			continue
+		}
+		os.Setenv(key, value)
+		fileEnvKeys[key] = true
+	}
+}
+
+// Live holds the running configuration. Reload swaps in settings that can
+// change without a restart; readers always see a complete, validated Config.
+type Live struct {
+	current atomic.Pointer[Config]
+	mu      sync.Mutex // Serialises reloads
+}
+
+// ReloadResult lists what a reload changed, by environment variable name
+type ReloadResult struct {
+	Applied         []string // Now in effect
+	RestartRequired []string // Changed, but only take effect after a restart
+}
+
+// setting is a configuration value that Reload compares between loads
+type setting struct {
+	name      string
+	hotReload bool
+	value     func(c *Config) interface{}
+}
+
+// settings lists what Reload compares. Hot-reloadable settings are copied into
+// the live configuration; anything else that changes is reported as needing a restart.
+var settings = []setting{
+	{"LOG_LEVEL", true, func(c *Config) interface{} { return &c.Logging.Level }},
+	{"SCRAPER_ENABLED", true, func(c *Config) interface{} { return &c.Scraper.Enabled }},
+	{"SCRAPER_INTERVAL", true, func(c *Config) interface{} { return &c.Scraper.Interval }},
+	{"FEATURE_* flags", true, func(c *Config) interface{} { return &c.Features }},
+	{"LOG_FORMAT", false, func(c *Config) interface{} { return &c.Logging.Format }},
+	{"PORT", false, func(c *Config) interface{} { return &c.Server.Port }},
+	{"HOST", false, func(c *Config) interface{} { return &c.Server.Host }},
+	{"READ_TIMEOUT", false, func(c *Config) interface{} { return &c.Server.ReadTimeout }},
+	{"WRITE_TIMEOUT", false, func(c *Config) interface{} { return &c.Server.WriteTimeout }},
+	{"IDLE_TIMEOUT", false, func(c *Config) interface{} { return &c.Server.IdleTimeout }},
+	{"ENVIRONMENT", false, func(c *Config) interface{} { return &c.Server.Environment }},
+	{"MongoDB settings", false, func(c *Config) interface{} { return &c.MongoDB }},
+	{"Redis settings", false, func(c *Config) interface{} { return &c.Redis }},
+	{"JWT settings", false, func(c *Config) interface{} { return &c.JWT }},
+	{"SMTP settings", false, func(c *Config) interface{} { return &c.Email }},
+	{"Twilio settings", false, func(c *Config) interface{} { return &c.SMS }},
+	{"CORS settings", false, func(c *Config) interface{} { return &c.CORS }},
+}
+
+// NewLive wraps the configuration loaded at startup
+func NewLive(cfg *Config) *Live {
+	live := &Live{}
+	live.current.Store(cfg)
+	return live
+}
+
+// Get returns the current configuration, which must not be modified
+func (l *Live) Get() *Config {
+	return l.current.Load()
+}
+
+// IsFeatureEnabled checks a feature flag in the current configuration
+func (l *Live) IsFeatureEnabled(feature string) bool {
+	return l.Get().IsFeatureEnabled(feature)
+}
+
+// FeatureFlags returns the state of every feature flag in the current configuration
+func (l *Live) FeatureFlags() map[string]bool {
+	return l.Get().FeatureFlags()
+}
+
+// Reload re-reads the .env file and environment, validates the result and
+// applies the settings that are safe to change at runtime. On error the
+// running configuration is left as it was.
+func (l *Live) Reload() (ReloadResult, error) {
+	loadEnvFiles(true)
+	next, err := Load()
+	if err != nil {
+		return ReloadResult{}, err
+	}
+	if err := next.Validate(); err != nil {
+		return ReloadResult{}, err
+	}
+	return l.Apply(next), nil
+}
+
+// Apply copies the hot-reloadable settings of next into the live configuration
+// and reports which settings changed
+func (l *Live) Apply(next *Config) ReloadResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	current := l.current.Load()
+	updated := *current
+
+	var result ReloadResult
+	for _, s := range settings {
+		running := reflect.ValueOf(s.value(&updated)).Elem()
+		loaded := reflect.ValueOf(s.value(next)).Elem()
+		if reflect.DeepEqual(running.Interface(), loaded.Interface()) {
+			continue
+		}
+		if !s.hotReload {
+			result.RestartRequired = append(result.RestartRequired, s.name)
+			continue
+		}
+		running.Set(loaded)
+		result.Applied = append(result.Applied, s.name)
+	}
+
+	l.current.Store(&updated)
+	return result
+}
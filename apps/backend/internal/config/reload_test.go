@@ -0,0 +1,70 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveApply(t *testing.T) {
+	running := validTestConfig()
+	live := NewLive(running)
+
+	next := validTestConfig()
+	next.Logging.Level = "DEBUG"
+	next.Scraper.Interval = 10
+	next.Features = map[string]bool{FeatureAnalytics: false}
+	next.Server.Port = "9090"
+	next.CORS.AllowedOrigins = []string{"https://tennis.example.com"}
+
+	result := live.Apply(next)
+	assert.Equal(t, []string{"LOG_LEVEL", "SCRAPER_INTERVAL", "FEATURE_* flags"}, result.Applied)
+	assert.Equal(t, []string{"PORT", "CORS settings"}, result.RestartRequired)
+
+	current := live.Get()
+	assert.Equal(t, "DEBUG", current.Logging.Level)
+	assert.Equal(t, 10, current.Scraper.Interval)
+	assert.False(t, live.IsFeatureEnabled(FeatureAnalytics))
+	assert.Equal(t, "8080", current.Server.Port, "port only changes on restart")
+	assert.Equal(t, []string{"http://localhost:3000"}, current.CORS.AllowedOrigins)
+	assert.Equal(t, "info", running.Logging.Level, "the previous configuration is not modified")
+
+	result = live.Apply(next)
+	assert.Empty(t, result.Applied, "nothing new to apply")
+	assert.Equal(t, []string{"PORT", "CORS settings"}, result.RestartRequired, "still waiting for a restart")
+}
+
+func TestLoadEnvFiles_Refresh(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	writeEnv := func(contents string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte(contents), 0o600))
+	}
+	t.Setenv("RELOAD_TEST_SET_BY_SHELL", "shell")
+	defer os.Unsetenv("RELOAD_TEST_FROM_FILE")
+
+	writeEnv("RELOAD_TEST_FROM_FILE=one\nRELOAD_TEST_SET_BY_SHELL=file\n")
+	loadEnvFiles(false)
+	assert.Equal(t, "one", os.Getenv("RELOAD_TEST_FROM_FILE"))
+	assert.Equal(t, "shell", os.Getenv("RELOAD_TEST_SET_BY_SHELL"))
+
+	writeEnv("RELOAD_TEST_FROM_FILE=two\nRELOAD_TEST_SET_BY_SHELL=file\n")
+	loadEnvFiles(false)
+	assert.Equal(t, "one", os.Getenv("RELOAD_TEST_FROM_FILE"), "only a refresh overwrites file values")
+	loadEnvFiles(true)
+	assert.Equal(t, "two", os.Getenv("RELOAD_TEST_FROM_FILE"))
+	assert.Equal(t, "shell", os.Getenv("RELOAD_TEST_SET_BY_SHELL"), "variables set outside the file keep precedence")
+
+	writeEnv("")
+	loadEnvFiles(true)
+	_, set := os.LookupEnv("RELOAD_TEST_FROM_FILE")
+	assert.False(t, set, "variables removed from the file are unset")
+	assert.Equal(t, "shell", os.Getenv("RELOAD_TEST_SET_BY_SHELL"))
+}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AlertStatsDays is how many days of per-day alert counts AlertStats covers
+const AlertStatsDays = 30
+
+// AlertStats summarizes the alerts sent to one user. Totals, rates and the
+// venue breakdown cover the user's whole alert history; Daily covers the last
+// AlertStatsDays days, oldest first, with a zero for days without alerts.
+type AlertStats struct {
+	TotalAlerts        int               `json:"totalAlerts"`
+	Delivered          int               `json:"delivered"`
+	Failed             int               `json:"failed"`
+	DeliveryRate       float64           `json:"deliveryRate"`               // Percentage of alerts that didn't fail
+	AverageTimeOfDay   string            `json:"averageTimeOfDay,omitempty"` // HH:MM the alerts were sent at, on average
+	Venues             []VenueAlertCount `json:"venues"`                     // Most alerts first
+	Daily              []DailyAlertCount `json:"daily"`
+	HourlyDistribution [24]int           `json:"hourlyDistribution"` // Alerts sent in each hour of the day
+	Timezone           string            `json:"timezone"`
+}
+
+// VenueAlertCount is how many alerts one venue produced
+type VenueAlertCount struct {
+	VenueID   string `json:"venueId"`
+	VenueName string `json:"venueName"`
+	Alerts    int    `json:"alerts"`
+	Failed    int    `json:"failed"`
+}
+
+// DailyAlertCount is how many alerts were sent on one day
+type DailyAlertCount struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Alerts int    `json:"alerts"`
+	Failed int    `json:"failed"`
+}
+
+// AlertStatsRepository aggregates a user's alert_history
+type AlertStatsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAlertStatsRepository creates a new alert stats repository
+func NewAlertStatsRepository(db *mongo.Database) *AlertStatsRepository {
+	return &AlertStatsRepository{collection: db.Collection("alert_history")}
+}
+
+// CreateIndexes creates the user_id + created_at index the stats pipelines
+// and the notification history pages read alert_history by
+func (r *AlertStatsRepository) CreateIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return err
+}
+
+// UserAlertStats computes the user's alert stats as of now, bucketing days and
+// times of day in loc
+func (r *AlertStatsRepository) UserAlertStats(ctx context.Context, userID primitive.ObjectID, now time.Time, loc *time.Location) (*AlertStats, error) {
+	now = now.In(loc)
+	since := startOfDay(now).AddDate(0, 0, -(AlertStatsDays - 1))
+
+	var result struct {
+		Totals []struct {
+			Total       int     `bson:"total"`
+			Failed      int     `bson:"failed"`
+			MinuteOfDay float64 `bson:"minute_of_day"`
+		} `bson:"totals"`
+		Venues []struct {
+			ID     string `bson:"_id"`
+			Name   string `bson:"venue_name"`
+			Alerts int    `bson:"alerts"`
+			Failed int    `bson:"failed"`
+		} `bson:"venues"`
+		Daily []struct {
+			Date   string `bson:"_id"`
+			Alerts int    `bson:"alerts"`
+			Failed int    `bson:"failed"`
+		} `bson:"daily"`
+		Hours []struct {
+			Hour   int `bson:"_id"`
+			Alerts int `bson:"alerts"`
+		} `bson:"hours"`
+	}
+	if err := aggregateOne(ctx, r.collection, userAlertStatsPipeline(userID, since, loc.String()), &result); err != nil {
+		return nil, err
+	}
+
+	stats := &AlertStats{
+		Venues:   make([]VenueAlertCount, 0, len(result.Venues)),
+		Daily:    make([]DailyAlertCount, 0, AlertStatsDays),
+		Timezone: loc.String(),
+	}
+	if len(result.Totals) > 0 {
+		totals := result.Totals[0]
+		stats.TotalAlerts = totals.Total
+		stats.Failed = totals.Failed
+		stats.Delivered = totals.Total - totals.Failed
+		if totals.Total > 0 {
+			stats.DeliveryRate = float64(stats.Delivered) * 100 / float64(totals.Total)
+			minutes := int(math.Round(totals.MinuteOfDay)) % (24 * 60)
+			stats.AverageTimeOfDay = fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+		}
+	}
+	for _, venue := range result.Venues {
+		stats.Venues = append(stats.Venues, VenueAlertCount{VenueID: venue.ID, VenueName: venue.Name, Alerts: venue.Alerts, Failed: venue.Failed})
+	}
+	for _, hour := range result.Hours {
+		if hour.Hour >= 0 && hour.Hour < 24 {
+			stats.HourlyDistribution[hour.Hour] = hour.Alerts
+		}
+	}
+
+	daily := make(map[string]DailyAlertCount, len(result.Daily))
+	for _, day := range result.Daily {
+		daily[day.Date] = DailyAlertCount{Date: day.Date, Alerts: day.Alerts, Failed: day.Failed}
+	}
+	for day := since; !day.After(now); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		count, ok := daily[date]
+		if !ok {
+			count = DailyAlertCount{Date: date}
+		}
+		stats.Daily = append(stats.Daily, count)
+	}
+
+	return stats, nil
+}
+
+// alertFailed is 1 for alerts whose delivery failed or bounced, otherwise 0
+var alertFailed = bson.M{"$cond": bson.A{
+	bson.M{"$in": bson.A{"$email_status", bson.A{"failed", "bounced"}}}, 1, 0,
+}}
+
+// userAlertStatsPipeline computes every part of a user's alert stats in one
+// pass over their alert_history, which the user_id + created_at index serves.
+// Days and hours are bucketed in timezone.
+func userAlertStatsPipeline(userID primitive.ObjectID, since time.Time, timezone string) mongo.Pipeline {
+	minuteOfDay := bson.M{"$add": bson.A{
+		bson.M{"$multiply": bson.A{bson.M{"$hour": bson.M{"date": "$created_at", "timezone": timezone}}, 60}},
+		bson.M{"$minute": bson.M{"date": "$created_at", "timezone": timezone}},
+	}}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID}}},
+		{{Key: "$facet", Value: bson.M{
+			"totals": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":           nil,
+					"total":         bson.M{"$sum": 1},
+					"failed":        bson.M{"$sum": alertFailed},
+					"minute_of_day": bson.M{"$avg": minuteOfDay},
+				}},
+			},
+			"venues": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":        "$venue_id",
+					"venue_name": bson.M{"$last": "$venue_name"},
+					"alerts":     bson.M{"$sum": 1},
+					"failed":     bson.M{"$sum": alertFailed},
+				}},
+				bson.M{"$sort": bson.D{{Key: "alerts", Value: -1}, {Key: "_id", Value: 1}}},
+			},
+			"daily": bson.A{
+				bson.M{"$match": bson.M{"created_at": bson.M{"$gte": since}}},
+				bson.M{"$group": bson.M{
+					"_id":    bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$created_at", "timezone": timezone}},
+					"alerts": bson.M{"$sum": 1},
+					"failed": bson.M{"$sum": alertFailed},
+				}},
+			},
+			"hours": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":    bson.M{"$hour": bson.M{"date": "$created_at", "timezone": timezone}},
+					"alerts": bson.M{"$sum": 1},
+				}},
+			},
+		}}},
+	}
+}
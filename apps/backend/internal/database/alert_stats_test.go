@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAlertStatsRepository_UserAlertStats(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+	now := time.Date(2024, 6, 15, 20, 0, 0, 0, london)
+	userID := primitive.NewObjectID()
+
+	alert := func(venueID, venueName, status string, createdAt time.Time) bson.M {
+		return bson.M{"user_id": userID, "venue_id": venueID, "venue_name": venueName, "email_status": status, "created_at": createdAt}
+	}
+	_, err = db.Collection("alert_history").InsertMany(ctx, []interface{}{
+		alert("vp", "Victoria Park", "sent", time.Date(2024, 6, 15, 18, 0, 0, 0, london)),
+		alert("vp", "Victoria Park", "failed", time.Date(2024, 6, 15, 19, 0, 0, 0, london)),
+		alert("vp", "Victoria Park", "delivered", time.Date(2024, 6, 14, 17, 0, 0, 0, london)),
+		alert("sp", "Stratford Park", "sent", time.Date(2024, 6, 10, 18, 0, 0, 0, london)),
+		alert("sp", "Stratford Park", "sent", time.Date(2024, 4, 1, 18, 0, 0, 0, london)),                       // Before the daily window
+		bson.M{"user_id": primitive.NewObjectID(), "venue_id": "vp", "email_status": "sent", "created_at": now}, // Someone else's
+	})
+	require.NoError(t, err)
+
+	repo := NewAlertStatsRepository(db)
+	require.NoError(t, repo.CreateIndexes(ctx))
+
+	stats, err := repo.UserAlertStats(ctx, userID, now, london)
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, stats.TotalAlerts)
+	assert.Equal(t, 4, stats.Delivered)
+	assert.Equal(t, 1, stats.Failed)
+	assert.InDelta(t, 80, stats.DeliveryRate, 0.01)
+	assert.Equal(t, "18:00", stats.AverageTimeOfDay)
+	assert.Equal(t, 3, stats.HourlyDistribution[18], "hours are in the requested timezone")
+
+	require.Len(t, stats.Venues, 2)
+	assert.Equal(t, VenueAlertCount{VenueID: "vp", VenueName: "Victoria Park", Alerts: 3, Failed: 1}, stats.Venues[0])
+	assert.Equal(t, 2, stats.Venues[1].Alerts)
+
+	require.Len(t, stats.Daily, AlertStatsDays)
+	assert.Equal(t, "2024-05-17", stats.Daily[0].Date)
+	assert.Equal(t, DailyAlertCount{Date: "2024-06-15", Alerts: 2, Failed: 1}, stats.Daily[AlertStatsDays-1])
+	assert.Equal(t, 1, stats.Daily[AlertStatsDays-2].Alerts)
+	assert.Zero(t, stats.Daily[AlertStatsDays-3].Alerts)
+}
+
+func TestAlertStatsRepository_UserAlertStats_NoAlerts(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	stats, err := NewAlertStatsRepository(db).UserAlertStats(context.Background(), primitive.NewObjectID(), time.Now(), time.UTC)
+	require.NoError(t, err)
+
+	assert.Zero(t, stats.TotalAlerts)
+	assert.Empty(t, stats.AverageTimeOfDay)
+	assert.Empty(t, stats.Venues)
+	assert.Len(t, stats.Daily, AlertStatsDays)
+}
@@ -13,6 +13,32 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	// ErrBookingNotFound is returned when no booking has the requested ID
+	ErrBookingNotFound = errors.New("booking not found")
+
+	// ErrBookingStatusChanged is returned by TransitionStatus when the booking is
+	// no longer in the expected status, e.g. because another request changed it
+	ErrBookingStatusChanged = errors.New("booking status changed")
+
+	// ErrSlotAlreadyBooked is returned by Create when another booking that
+	// hasn't been cancelled holds the same venue, court, date and start time
+	ErrSlotAlreadyBooked = errors.New("slot already booked")
+)
+
+const (
+	// slotBookingIndexName is the unique index that prevents double-booking a slot
+	slotBookingIndexName = "unique_held_slot_booking"
+)
+
+// legacySlotBookingIndexNames are earlier versions of that index. The first also
+// counted cancelled bookings and the second failed ones, so slots that were never
+// held could not be booked again.
+var legacySlotBookingIndexNames = []string{
+	"venue_id_1_court_id_1_date_1_start_time_1",
+	"unique_active_slot_booking",
+}
+
 // BookingRepository handles database operations for bookings
 type BookingRepository struct {
 	collection *mongo.Collection
@@ -58,6 +84,9 @@ func (r *BookingRepository) Create(ctx context.Context, booking *models.Booking)
 
 	// Insert the booking
 	result, err := r.collection.InsertOne(ctx, booking)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrSlotAlreadyBooked
+	}
 	if err != nil {
 		return err
 	}
@@ -76,7 +105,7 @@ func (r *BookingRepository) FindByID(ctx context.Context, id primitive.ObjectID)
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&booking)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("booking not found")
+			return nil, ErrBookingNotFound
 		}
 		return nil, err
 	}
@@ -99,6 +128,42 @@ func (r *BookingRepository) FindByUserID(ctx context.Context, userID primitive.O
 	return bookings, nil
 }
 
+// FindByUserIDPage retrieves one page of a user's bookings, latest date first,
+// and the user's total number of bookings. The sort uses the user_id + date index.
+func (r *BookingRepository) FindByUserIDPage(ctx context.Context, userID primitive.ObjectID, skip, limit int64) ([]*models.Booking, int64, error) {
+	filter := bson.M{"user_id": userID}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(bson.D{
+		{Key: "date", Value: -1},
+		{Key: "start_time", Value: -1},
+		{Key: "_id", Value: -1},
+	})
+	if skip > 0 {
+		opts.SetSkip(skip)
+	}
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	bookings := []*models.Booking{}
+	if err := cursor.All(ctx, &bookings); err != nil {
+		return nil, 0, err
+	}
+
+	return bookings, total, nil
+}
+
 // FindByVenueID retrieves bookings for a specific venue
 func (r *BookingRepository) FindByVenueID(ctx context.Context, venueID primitive.ObjectID) ([]*models.Booking, error) {
 	cursor, err := r.collection.Find(ctx, bson.M{"venue_id": venueID})
@@ -173,25 +238,45 @@ func (r *BookingRepository) Update(ctx context.Context, booking *models.Booking)
 // UpdateStatus updates the status of a booking
 func (r *BookingRepository) UpdateStatus(ctx context.Context, id primitive.ObjectID, status models.BookingStatus) error {
 	filter := bson.M{"_id": id}
-	update := bson.M{
-		"$set": bson.M{
-			"status":     status,
-			"updated_at": time.Now(),
-		},
+	_, err := r.collection.UpdateOne(ctx, filter, statusUpdate(status))
+	return err
+}
+
+// TransitionStatus moves a booking from one status to another. The update only
+// applies while the booking is still in the from status, so concurrent changes
+// can't both succeed; ErrBookingStatusChanged is returned if it isn't.
+// Callers check the transition is allowed with BookingStatus.CanTransitionTo.
+func (r *BookingRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to models.BookingStatus) error {
+	filter := bson.M{"_id": id, "status": from}
+	result, err := r.collection.UpdateOne(ctx, filter, statusUpdate(to))
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrBookingStatusChanged
+	}
+	return nil
+}
+
+// statusUpdate sets a booking's status along with its status timestamps
+func statusUpdate(status models.BookingStatus) bson.M {
+	now := time.Now()
+	set := bson.M{
+		"status":     status,
+		"updated_at": now,
 	}
 
 	// Add booked_at timestamp if status is confirmed
 	if status == models.BookingStatusConfirmed {
-		update["$set"].(bson.M)["booked_at"] = time.Now()
+		set["booked_at"] = now
 	}
 
 	// Add cancelled_at timestamp if status is cancelled
 	if status == models.BookingStatusCancelled {
-		update["$set"].(bson.M)["cancelled_at"] = time.Now()
+		set["cancelled_at"] = now
 	}
 
-	_, err := r.collection.UpdateOne(ctx, filter, update)
-	return err
+	return bson.M{"$set": set}
 }
 
 // AddBookingAttempt adds a booking attempt to a booking
@@ -243,9 +328,10 @@ func (r *BookingRepository) List(ctx context.Context, skip, limit int64) ([]*mod
 
 // CreateIndexes creates any necessary indexes for the bookings collection
 func (r *BookingRepository) CreateIndexes(ctx context.Context) error {
-	// Create an index on the user_id field
-	userIDIndex := mongo.IndexModel{
-		Keys: bson.D{{Key: "user_id", Value: 1}},
+	// Create a compound index on user_id and date for listing a user's bookings.
+	// It also serves queries on user_id alone.
+	userDateIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "date", Value: -1}},
 	}
 
 	// Create an index on the venue_id field
@@ -263,8 +349,9 @@ func (r *BookingRepository) CreateIndexes(ctx context.Context) error {
 		Keys: bson.D{{Key: "status", Value: 1}},
 	}
 
-	// Create a compound index on venue_id, court_id, date, start_time
-	// This helps ensure we don't double-book the same court
+	// Create a unique compound index on venue_id, court_id, date, start_time
+	// This ensures we don't double-book the same court. Only pending and confirmed
+	// bookings hold a slot, so a cancelled or failed slot can be booked again.
 	bookingConstraintIndex := mongo.IndexModel{
 		Keys: bson.D{
 			{Key: "venue_id", Value: 1},
@@ -272,12 +359,26 @@ func (r *BookingRepository) CreateIndexes(ctx context.Context) error {
 			{Key: "date", Value: 1},
 			{Key: "start_time", Value: 1},
 		},
-		Options: options.Index().SetUnique(true),
+		Options: options.Index().
+			SetName(slotBookingIndexName).
+			SetUnique(true).
+			// Partial indexes don't support $ne, so the other statuses are listed
+			SetPartialFilterExpression(bson.M{"status": bson.M{"$in": bson.A{
+				models.BookingStatusPending,
+				models.BookingStatusConfirmed,
+			}}}),
+	}
+
+	// Drop the earlier unique indexes, which also counted cancelled or failed bookings
+	for _, name := range legacySlotBookingIndexNames {
+		if err := r.dropIndexIfExists(ctx, name); err != nil {
+			return err
+		}
 	}
 
 	// Create indexes
 	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
-		userIDIndex,
+		userDateIndex,
 		venueIDIndex,
 		dateIndex,
 		statusIndex,
@@ -285,3 +386,13 @@ func (r *BookingRepository) CreateIndexes(ctx context.Context) error {
 	})
 	return err
 }
+
+// dropIndexIfExists drops the named index, ignoring it if it doesn't exist
+func (r *BookingRepository) dropIndexIfExists(ctx context.Context, name string) error {
+	_, err := r.collection.Indexes().DropOne(ctx, name)
+	var cmdErr mongo.CommandError
+	if errors.As(err, &cmdErr) && (cmdErr.Name == "IndexNotFound" || cmdErr.Name == "NamespaceNotFound") {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/testenv"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newIntegrationBooking(venueID primitive.ObjectID) *models.Booking {
+	return &models.Booking{
+		UserID:    primitive.NewObjectID(),
+		VenueID:   venueID,
+		CourtID:   "court1",
+		Date:      "2024-06-15",
+		StartTime: "18:00",
+		EndTime:   "19:00",
+		VenueName: "Victoria Park",
+		CourtName: "Court 1",
+	}
+}
+
+func TestIntegration_BookingRepository_PreventsDoubleBooking(t *testing.T) {
+	env, cleanup := testenv.SetupTestEnv(t)
+	defer cleanup()
+
+	repo := NewBookingRepository(env.DB)
+	ctx := context.Background()
+	if err := repo.CreateIndexes(ctx); err != nil {
+		t.Fatalf("Failed to create indexes: %v", err)
+	}
+
+	venueID := primitive.NewObjectID()
+	first := newIntegrationBooking(venueID)
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("Failed to create booking: %v", err)
+	}
+
+	if err := repo.Create(ctx, newIntegrationBooking(venueID)); !errors.Is(err, ErrSlotAlreadyBooked) {
+		t.Fatalf("Expected ErrSlotAlreadyBooked, got %v", err)
+	}
+
+	// Cancelling the first booking frees the slot
+	if err := repo.TransitionStatus(ctx, first.ID, models.BookingStatusPending, models.BookingStatusCancelled); err != nil {
+		t.Fatalf("Failed to cancel booking: %v", err)
+	}
+	if err := repo.Create(ctx, newIntegrationBooking(venueID)); err != nil {
+		t.Fatalf("Expected the cancelled slot to be bookable, got %v", err)
+	}
+}
+
+func TestIntegration_BookingRepository_TransitionStatus(t *testing.T) {
+	env, cleanup := testenv.SetupTestEnv(t)
+	defer cleanup()
+
+	repo := NewBookingRepository(env.DB)
+	ctx := context.Background()
+
+	booking := newIntegrationBooking(primitive.NewObjectID())
+	if err := repo.Create(ctx, booking); err != nil {
+		t.Fatalf("Failed to create booking: %v", err)
+	}
+
+	if err := repo.TransitionStatus(ctx, booking.ID, models.BookingStatusPending, models.BookingStatusConfirmed); err != nil {
+		t.Fatalf("Failed to confirm booking: %v", err)
+	}
+
+	// A second change from pending loses the race
+	err := repo.TransitionStatus(ctx, booking.ID, models.BookingStatusPending, models.BookingStatusFailed)
+	if !errors.Is(err, ErrBookingStatusChanged) {
+		t.Fatalf("Expected ErrBookingStatusChanged, got %v", err)
+	}
+
+	found, err := repo.FindByID(ctx, booking.ID)
+	if err != nil {
+		t.Fatalf("Failed to find booking: %v", err)
+	}
+	if found.Status != models.BookingStatusConfirmed {
+		t.Errorf("Expected status %s, got %s", models.BookingStatusConfirmed, found.Status)
+	}
+	if found.BookedAt.IsZero() {
+		t.Errorf("Confirmed booking should have booked_at set")
+	}
+}
@@ -2,12 +2,14 @@ package database
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"tennis-booker/internal/models"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestBookingRepository_Create(t *testing.T) {
@@ -461,6 +463,142 @@ func TestBookingRepository_UpdateStatus(t *testing.T) {
 	}
 }
 
+// newSlotBookingFixture returns a repository with its indexes created and a
+// func making bookings that all target the same slot
+func newSlotBookingFixture(t *testing.T, db *mongo.Database) (*BookingRepository, func() *models.Booking) {
+	t.Helper()
+
+	repo := NewBookingRepository(db)
+	if err := repo.CreateIndexes(context.Background()); err != nil {
+		t.Fatalf("Failed to create indexes: %v", err)
+	}
+
+	venueID := primitive.NewObjectID()
+	newBooking := func() *models.Booking {
+		return &models.Booking{
+			UserID:    primitive.NewObjectID(),
+			VenueID:   venueID,
+			CourtID:   "court1",
+			Date:      "2023-06-10",
+			StartTime: "10:00",
+			EndTime:   "11:00",
+		}
+	}
+	return repo, newBooking
+}
+
+func TestBookingRepository_Create_RejectsDoubleBooking(t *testing.T) {
+	tests := []struct {
+		name    string
+		release models.BookingStatus
+	}{
+		{name: "cancelled booking releases the slot", release: models.BookingStatusCancelled},
+		{name: "failed attempt releases the slot", release: models.BookingStatusFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, db, cleanup := setupTestDB(t)
+			defer cleanup()
+
+			repo, newBooking := newSlotBookingFixture(t, db)
+			ctx := context.Background()
+
+			first := newBooking()
+			if err := repo.Create(ctx, first); err != nil {
+				t.Fatalf("Failed to create booking: %v", err)
+			}
+
+			// A second booking for the same slot must be rejected while the first holds it
+			if err := repo.Create(ctx, newBooking()); !errors.Is(err, ErrSlotAlreadyBooked) {
+				t.Errorf("Expected ErrSlotAlreadyBooked, got %v", err)
+			}
+
+			if err := repo.TransitionStatus(ctx, first.ID, models.BookingStatusPending, tt.release); err != nil {
+				t.Fatalf("Failed to move booking to %s: %v", tt.release, err)
+			}
+			if err := repo.Create(ctx, newBooking()); err != nil {
+				t.Errorf("Expected the slot to be bookable once the first booking is %s, got %v", tt.release, err)
+			}
+		})
+	}
+}
+
+func TestBookingRepository_TransitionStatus(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewBookingRepository(db)
+	ctx := context.Background()
+
+	booking := &models.Booking{
+		UserID:    primitive.NewObjectID(),
+		VenueID:   primitive.NewObjectID(),
+		CourtID:   "court1",
+		Date:      "2023-06-10",
+		StartTime: "10:00",
+		EndTime:   "11:00",
+	}
+	if err := repo.Create(ctx, booking); err != nil {
+		t.Fatalf("Failed to create booking: %v", err)
+	}
+
+	if err := repo.TransitionStatus(ctx, booking.ID, models.BookingStatusPending, models.BookingStatusConfirmed); err != nil {
+		t.Fatalf("Failed to confirm booking: %v", err)
+	}
+
+	// The booking is no longer pending, so a second transition from pending must fail
+	err := repo.TransitionStatus(ctx, booking.ID, models.BookingStatusPending, models.BookingStatusCancelled)
+	if !errors.Is(err, ErrBookingStatusChanged) {
+		t.Errorf("Expected ErrBookingStatusChanged, got %v", err)
+	}
+
+	foundBooking, err := repo.FindByID(ctx, booking.ID)
+	if err != nil {
+		t.Fatalf("Failed to find booking by ID: %v", err)
+	}
+	if foundBooking.Status != models.BookingStatusConfirmed {
+		t.Errorf("Expected status %s, got %s", models.BookingStatusConfirmed, foundBooking.Status)
+	}
+	if foundBooking.BookedAt.IsZero() {
+		t.Errorf("Expected booked_at to be set")
+	}
+}
+
+func TestBookingRepository_FindByUserIDPage(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewBookingRepository(db)
+	ctx := context.Background()
+	userID := primitive.NewObjectID()
+
+	for _, date := range []string{"2023-06-10", "2023-06-12", "2023-06-11"} {
+		booking := &models.Booking{
+			UserID:    userID,
+			VenueID:   primitive.NewObjectID(),
+			CourtID:   "court1",
+			Date:      date,
+			StartTime: "10:00",
+			EndTime:   "11:00",
+		}
+		if err := repo.Create(ctx, booking); err != nil {
+			t.Fatalf("Failed to create booking: %v", err)
+		}
+	}
+
+	bookings, total, err := repo.FindByUserIDPage(ctx, userID, 1, 1)
+	if err != nil {
+		t.Fatalf("Failed to find bookings: %v", err)
+	}
+	if total != 3 {
+		t.Errorf("Expected total 3, got %d", total)
+	}
+	if len(bookings) != 1 || bookings[0].Date != "2023-06-11" {
+		t.Errorf("Expected the second latest booking (2023-06-11), got %v", bookings)
+	}
+}
+
 func TestBookingRepository_AddBookingAttempt(t *testing.T) {
 	_, db, cleanup := setupTestDB(t)
 	defer cleanup()
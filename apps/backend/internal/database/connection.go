@@ -25,12 +25,15 @@ type DatabaseConfig struct {
 type ConnectionManager struct {
 	secretsManager *secrets.SecretsManager
 	config         *DatabaseConfig
+	connConfig     ConnectionConfig
 }
 
-// NewConnectionManager creates a new database connection manager
+// NewConnectionManager creates a new database connection manager, with pool
+// and timeout settings from ConnectionConfigFromEnv
 func NewConnectionManager(secretsManager *secrets.SecretsManager) *ConnectionManager {
 	return &ConnectionManager{
 		secretsManager: secretsManager,
+		connConfig:     ConnectionConfigFromEnv(),
 	}
 }
 
@@ -131,8 +134,7 @@ func (cm *ConnectionManager) Connect() (*mongo.Database, error) {
 
 	log.Printf("Connecting to MongoDB at %s (database: %s)", cm.config.Host, dbName)
 
-	// Use the existing InitDatabase function
-	db, err := InitDatabase(uri, dbName)
+	db, err := InitDatabaseWithConfig(uri, dbName, cm.connConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -188,7 +190,7 @@ func (cm *ConnectionManager) connectFromEnv() (*mongo.Database, error) {
 
 	log.Printf("⚠️ Using fallback connection: %s", uri)
 
-	db, err := InitDatabase(uri, dbName)
+	db, err := InitDatabaseWithConfig(uri, dbName, cm.connConfig)
 	if err != nil {
 		return nil, fmt.Errorf("fallback connection failed: %w", err)
 	}
@@ -213,6 +215,16 @@ func (cm *ConnectionManager) Close() error {
 	return nil
 }
 
+// SetConnectionConfig overrides the pool and timeout settings used by Connect
+func (cm *ConnectionManager) SetConnectionConfig(config ConnectionConfig) {
+	cm.connConfig = config
+}
+
+// GetConnectionConfig returns the pool and timeout settings used by Connect
+func (cm *ConnectionManager) GetConnectionConfig() ConnectionConfig {
+	return cm.connConfig
+}
+
 // GetSecretsManager returns the underlying secrets manager
 func (cm *ConnectionManager) GetSecretsManager() *secrets.SecretsManager {
 	return cm.secretsManager
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Environment variables that tune MongoDB connections
+const (
+	EnvMongoMaxPoolSize            = "MONGO_MAX_POOL_SIZE"
+	EnvMongoMinPoolSize            = "MONGO_MIN_POOL_SIZE"
+	EnvMongoConnectTimeout         = "MONGO_CONNECT_TIMEOUT"
+	EnvMongoSocketTimeout          = "MONGO_SOCKET_TIMEOUT"
+	EnvMongoServerSelectionTimeout = "MONGO_SERVER_SELECTION_TIMEOUT"
+)
+
+// ConnectionConfig holds MongoDB connection pool and timeout settings
+type ConnectionConfig struct {
+	MaxPoolSize            uint64        // Maximum connections per server
+	MinPoolSize            uint64        // Connections kept open even when idle
+	ConnectTimeout         time.Duration // Establishing a connection, and the initial connect and ping
+	SocketTimeout          time.Duration // Waiting on a single read or write
+	ServerSelectionTimeout time.Duration // Finding a suitable server for an operation
+}
+
+// DefaultConnectionConfig returns the settings used when no environment overrides are set
+func DefaultConnectionConfig() ConnectionConfig {
+	return ConnectionConfig{
+		MaxPoolSize:            100,
+		MinPoolSize:            0,
+		ConnectTimeout:         10 * time.Second,
+		SocketTimeout:          30 * time.Second,
+		ServerSelectionTimeout: 10 * time.Second,
+	}
+}
+
+// ConnectionConfigFromEnv returns the default settings overridden by any
+// MONGO_* pool and timeout environment variables. Pool sizes are integers and
+// timeouts are Go durations such as "5s"; invalid values are logged and ignored.
+func ConnectionConfigFromEnv() ConnectionConfig {
+	config := DefaultConnectionConfig()
+
+	config.MaxPoolSize = envUint(EnvMongoMaxPoolSize, config.MaxPoolSize)
+	config.MinPoolSize = envUint(EnvMongoMinPoolSize, config.MinPoolSize)
+	config.ConnectTimeout = envDuration(EnvMongoConnectTimeout, config.ConnectTimeout)
+	config.SocketTimeout = envDuration(EnvMongoSocketTimeout, config.SocketTimeout)
+	config.ServerSelectionTimeout = envDuration(EnvMongoServerSelectionTimeout, config.ServerSelectionTimeout)
+
+	if config.MaxPoolSize > 0 && config.MinPoolSize > config.MaxPoolSize {
+		log.Printf("⚠️ %s (%d) is larger than %s (%d), using %d",
+			EnvMongoMinPoolSize, config.MinPoolSize, EnvMongoMaxPoolSize, config.MaxPoolSize, config.MaxPoolSize)
+		config.MinPoolSize = config.MaxPoolSize
+	}
+
+	return config
+}
+
+// ClientOptions returns client options for uri with these pool and timeout settings
+func (c ConnectionConfig) ClientOptions(uri string) *options.ClientOptions {
+	return options.Client().
+		ApplyURI(uri).
+		SetMaxPoolSize(c.MaxPoolSize).
+		SetMinPoolSize(c.MinPoolSize).
+		SetConnectTimeout(c.ConnectTimeout).
+		SetSocketTimeout(c.SocketTimeout).
+		SetServerSelectionTimeout(c.ServerSelectionTimeout)
+}
+
+func envUint(key string, defaultValue uint64) uint64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		log.Printf("⚠️ Ignoring invalid %s=%q: %v", key, value, err)
+		return defaultValue
+	}
+	return parsed
+}
+
+func envDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil || parsed <= 0 {
+		log.Printf("⚠️ Ignoring invalid %s=%q, expected a positive duration such as 10s", key, value)
+		return defaultValue
+	}
+	return parsed
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionConfigFromEnv_Defaults(t *testing.T) {
+	for _, key := range []string{EnvMongoMaxPoolSize, EnvMongoMinPoolSize, EnvMongoConnectTimeout, EnvMongoSocketTimeout, EnvMongoServerSelectionTimeout} {
+		t.Setenv(key, "")
+	}
+
+	assert.Equal(t, DefaultConnectionConfig(), ConnectionConfigFromEnv())
+}
+
+func TestConnectionConfigFromEnv_Overrides(t *testing.T) {
+	t.Setenv(EnvMongoMaxPoolSize, "200")
+	t.Setenv(EnvMongoMinPoolSize, "10")
+	t.Setenv(EnvMongoConnectTimeout, "5s")
+	t.Setenv(EnvMongoSocketTimeout, "1m")
+	t.Setenv(EnvMongoServerSelectionTimeout, "3s")
+
+	config := ConnectionConfigFromEnv()
+	assert.Equal(t, uint64(200), config.MaxPoolSize)
+	assert.Equal(t, uint64(10), config.MinPoolSize)
+	assert.Equal(t, 5*time.Second, config.ConnectTimeout)
+	assert.Equal(t, time.Minute, config.SocketTimeout)
+	assert.Equal(t, 3*time.Second, config.ServerSelectionTimeout)
+
+	opts := config.ClientOptions("mongodb://localhost:27017")
+	assert.Equal(t, uint64(200), *opts.MaxPoolSize)
+	assert.Equal(t, uint64(10), *opts.MinPoolSize)
+	assert.Equal(t, 5*time.Second, *opts.ConnectTimeout)
+	assert.Equal(t, time.Minute, *opts.SocketTimeout)
+	assert.Equal(t, 3*time.Second, *opts.ServerSelectionTimeout)
+}
+
+func TestConnectionConfigFromEnv_InvalidValues(t *testing.T) {
+	t.Setenv(EnvMongoMaxPoolSize, "-1")
+	t.Setenv(EnvMongoMinPoolSize, "lots")
+	t.Setenv(EnvMongoConnectTimeout, "10")
+	t.Setenv(EnvMongoSocketTimeout, "-5s")
+	t.Setenv(EnvMongoServerSelectionTimeout, "")
+
+	assert.Equal(t, DefaultConnectionConfig(), ConnectionConfigFromEnv())
+
+	// The minimum pool can't exceed the maximum
+	t.Setenv(EnvMongoMaxPoolSize, "5")
+	t.Setenv(EnvMongoMinPoolSize, "20")
+	assert.Equal(t, uint64(5), ConnectionConfigFromEnv().MinPoolSize)
+}
@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DashboardAggregates are the dashboard figures computed across the venues,
+// slots, scraping_logs and alert_history collections
+type DashboardAggregates struct {
+	TotalVenues            int     `json:"totalVenues"`
+	ActiveVenues           int     `json:"activeVenues"`
+	CourtsTracked          int     `json:"courtsTracked"`          // Courts at active venues
+	AvailableNext24h       int     `json:"availableNext24h"`       // Available slots starting in the next 24 hours
+	SlotsFoundToday        int     `json:"slotsFoundToday"`        // Slots found by today's scrapes
+	NotificationsSentToday int     `json:"notificationsSentToday"` // Alerts sent today, excluding failed deliveries
+	ScrapeSuccessRate      float64 `json:"scrapeSuccessRate"`      // Percentage of scrapes in the last 24 hours that succeeded
+}
+
+// DashboardStatsRepository computes dashboard aggregates
+type DashboardStatsRepository struct {
+	venues       *mongo.Collection
+	slots        *mongo.Collection
+	scrapingLogs *mongo.Collection
+	alertHistory *mongo.Collection
+}
+
+// NewDashboardStatsRepository creates a new dashboard stats repository
+func NewDashboardStatsRepository(db *mongo.Database) *DashboardStatsRepository {
+	return &DashboardStatsRepository{
+		venues:       db.Collection("venues"),
+		slots:        db.Collection("slots"),
+		scrapingLogs: db.Collection("scraping_logs"),
+		alertHistory: db.Collection("alert_history"),
+	}
+}
+
+// Aggregate computes the dashboard aggregates as of now. "Today" starts at
+// midnight in now's location.
+func (r *DashboardStatsRepository) Aggregate(ctx context.Context, now time.Time) (*DashboardAggregates, error) {
+	var stats DashboardAggregates
+	midnight := startOfDay(now)
+
+	var venues struct {
+		Total  int `bson:"total"`
+		Active int `bson:"active"`
+		Courts int `bson:"courts"`
+	}
+	if err := aggregateOne(ctx, r.venues, venuesPipeline(), &venues); err != nil {
+		return nil, err
+	}
+	stats.TotalVenues = venues.Total
+	stats.ActiveVenues = venues.Active
+	stats.CourtsTracked = venues.Courts
+
+	var available struct {
+		Count int `bson:"count"`
+	}
+	if err := aggregateOne(ctx, r.slots, availableSlotsPipeline(now), &available); err != nil {
+		return nil, err
+	}
+	stats.AvailableNext24h = available.Count
+
+	var scrapes struct {
+		Scrapes         int `bson:"scrapes"`
+		Succeeded       int `bson:"succeeded"`
+		SlotsFoundToday int `bson:"slots_found_today"`
+	}
+	if err := aggregateOne(ctx, r.scrapingLogs, scrapeOutcomesPipeline(now, midnight), &scrapes); err != nil {
+		return nil, err
+	}
+	stats.SlotsFoundToday = scrapes.SlotsFoundToday
+	if scrapes.Scrapes > 0 {
+		stats.ScrapeSuccessRate = float64(scrapes.Succeeded) * 100 / float64(scrapes.Scrapes)
+	}
+
+	var sent struct {
+		Count int `bson:"count"`
+	}
+	if err := aggregateOne(ctx, r.alertHistory, notificationsSentPipeline(midnight), &sent); err != nil {
+		return nil, err
+	}
+	stats.NotificationsSentToday = sent.Count
+
+	return &stats, nil
+}
+
+// aggregateOne runs a pipeline that produces at most one document and decodes
+// it into result, leaving result untouched when there is no output
+func aggregateOne(ctx context.Context, collection *mongo.Collection, pipeline mongo.Pipeline, result interface{}) error {
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	if cursor.Next(ctx) {
+		return cursor.Decode(result)
+	}
+	return cursor.Err()
+}
+
+// startOfDay returns midnight at the start of t's day, in t's location
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// venuesPipeline counts all venues, the active ones and the courts at active venues
+func venuesPipeline() mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{
+			"_id":    nil,
+			"total":  bson.M{"$sum": 1},
+			"active": bson.M{"$sum": bson.M{"$cond": bson.A{"$is_active", 1, 0}}},
+			"courts": bson.M{"$sum": bson.M{"$cond": bson.A{
+				"$is_active",
+				bson.M{"$size": bson.M{"$ifNull": bson.A{"$courts", bson.A{}}}},
+				0,
+			}}},
+		}}},
+	}
+}
+
+// availableSlotsPipeline counts available slots starting within 24 hours of
+// now. Slots store their date and start time as strings, which sort correctly.
+func availableSlotsPipeline(now time.Time) mongo.Pipeline {
+	today := now.Format("2006-01-02")
+	tomorrow := now.AddDate(0, 0, 1).Format("2006-01-02")
+	clock := now.Format("15:04")
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"available": true,
+			"$or": bson.A{
+				bson.M{"date": today, "start_time": bson.M{"$gte": clock}},
+				bson.M{"date": tomorrow, "start_time": bson.M{"$lt": clock}},
+			},
+		}}},
+		{{Key: "$count", Value: "count"}},
+	}
+}
+
+// scrapeOutcomesPipeline counts the last 24 hours of scrapes, how many
+// succeeded, and the slots found by scrapes since midnight
+func scrapeOutcomesPipeline(now, midnight time.Time) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"scrape_timestamp": bson.M{"$gte": now.Add(-24 * time.Hour)}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":       nil,
+			"scrapes":   bson.M{"$sum": 1},
+			"succeeded": bson.M{"$sum": bson.M{"$cond": bson.A{"$success", 1, 0}}},
+			"slots_found_today": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$gte": bson.A{"$scrape_timestamp", midnight}},
+				slotsFoundCount,
+				0,
+			}}},
+		}}},
+	}
+}
+
+// slotsFoundCount is a log's slots_found as a number. The scraper stores the
+// count, while logs written through ScrapingLogRepository hold the slots.
+var slotsFoundCount = bson.M{"$cond": bson.A{
+	bson.M{"$isArray": "$slots_found"},
+	bson.M{"$size": "$slots_found"},
+	bson.M{"$ifNull": bson.A{"$slots_found", 0}},
+}}
+
+// notificationsSentPipeline counts alerts sent since midnight that didn't fail
+func notificationsSentPipeline(midnight time.Time) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"alert_sent_at": bson.M{"$gte": midnight},
+			"email_status":  bson.M{"$ne": "failed"},
+		}}},
+		{{Key: "$count", Value: "count"}},
+	}
+}
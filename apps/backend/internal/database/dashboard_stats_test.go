@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestStartOfDay(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	now := time.Date(2024, 6, 15, 18, 30, 0, 0, london)
+	assert.Equal(t, time.Date(2024, 6, 15, 0, 0, 0, 0, london), startOfDay(now))
+}
+
+func TestDashboardStatsRepository_Aggregate(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Date(2024, 6, 15, 18, 0, 0, 0, time.UTC)
+
+	_, err := db.Collection("venues").InsertMany(ctx, []interface{}{
+		bson.M{"name": "Victoria Park", "is_active": true, "courts": bson.A{bson.M{"name": "Court 1"}, bson.M{"name": "Court 2"}}},
+		bson.M{"name": "Stratford Park", "is_active": true},
+		bson.M{"name": "Closed Club", "is_active": false, "courts": bson.A{bson.M{"name": "Court 1"}}},
+	})
+	require.NoError(t, err)
+
+	_, err = db.Collection("slots").InsertMany(ctx, []interface{}{
+		bson.M{"available": true, "date": "2024-06-15", "start_time": "19:00"}, // Later today
+		bson.M{"available": true, "date": "2024-06-16", "start_time": "09:00"}, // Tomorrow morning
+		bson.M{"available": true, "date": "2024-06-16", "start_time": "19:00"}, // More than 24h away
+		bson.M{"available": true, "date": "2024-06-15", "start_time": "10:00"}, // Already started
+		bson.M{"available": false, "date": "2024-06-15", "start_time": "20:00"},
+	})
+	require.NoError(t, err)
+
+	_, err = db.Collection("scraping_logs").InsertMany(ctx, []interface{}{
+		bson.M{"scrape_timestamp": now.Add(-time.Hour), "success": true, "slots_found": bson.A{bson.M{}, bson.M{}}},
+		bson.M{"scrape_timestamp": now.Add(-time.Hour), "success": true, "slots_found": 3}, // Written by the scraper
+		bson.M{"scrape_timestamp": now.Add(-2 * time.Hour), "success": false},
+		bson.M{"scrape_timestamp": now.Add(-20 * time.Hour), "success": true, "slots_found": bson.A{bson.M{}}}, // Yesterday
+		bson.M{"scrape_timestamp": now.Add(-48 * time.Hour), "success": false},                                 // Outside the window
+	})
+	require.NoError(t, err)
+
+	_, err = db.Collection("alert_history").InsertMany(ctx, []interface{}{
+		bson.M{"alert_sent_at": now.Add(-time.Hour), "email_status": "sent"},
+		bson.M{"alert_sent_at": now.Add(-time.Hour), "email_status": "failed"},
+		bson.M{"alert_sent_at": now.Add(-20 * time.Hour), "email_status": "sent"},
+	})
+	require.NoError(t, err)
+
+	stats, err := NewDashboardStatsRepository(db).Aggregate(ctx, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stats.TotalVenues)
+	assert.Equal(t, 2, stats.ActiveVenues)
+	assert.Equal(t, 2, stats.CourtsTracked)
+	assert.Equal(t, 2, stats.AvailableNext24h)
+	assert.Equal(t, 5, stats.SlotsFoundToday)
+	assert.Equal(t, 1, stats.NotificationsSentToday)
+	assert.InDelta(t, 75, stats.ScrapeSuccessRate, 0.01)
+}
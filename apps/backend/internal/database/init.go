@@ -6,19 +6,23 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 )
 
-// InitDatabase initializes the MongoDB connection and returns a database instance
+// InitDatabase initializes the MongoDB connection and returns a database instance,
+// using the pool and timeout settings from ConnectionConfigFromEnv
 func InitDatabase(uri, dbName string) (*mongo.Database, error) {
+	return InitDatabaseWithConfig(uri, dbName, ConnectionConfigFromEnv())
+}
+
+// InitDatabaseWithConfig initializes the MongoDB connection with the given pool and timeout settings
+func InitDatabaseWithConfig(uri, dbName string, config ConnectionConfig) (*mongo.Database, error) {
 	// Create a context with timeout for the connection
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.ConnectTimeout)
 	defer cancel()
 
 	// Connect to MongoDB
-	clientOptions := options.Client().ApplyURI(uri)
-	client, err := mongo.Connect(ctx, clientOptions)
+	client, err := mongo.Connect(ctx, config.ClientOptions(uri))
 	if err != nil {
 		return nil, err
 	}
@@ -67,6 +71,26 @@ func CreateAllIndexes(db *mongo.Database) error {
 		return err
 	}
 
+	log.Println("Creating indexes for slots collection...")
+	if err := NewSlotsRepository(db).CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	log.Println("Creating indexes for slot_states collection...")
+	if err := NewSlotStateRepository(db).CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	log.Println("Creating indexes for price_history collection...")
+	if err := NewPriceHistoryRepository(db).CreateIndexes(ctx); err != nil {
+		return err
+	}
+
+	log.Println("Creating indexes for alert_history collection...")
+	if err := NewAlertStatsRepository(db).CreateIndexes(ctx); err != nil {
+		return err
+	}
+
 	log.Println("All indexes created successfully")
 	return nil
 }
@@ -2,10 +2,8 @@ package database
 
 import (
 	"context"
-	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // MongoClient wraps the MongoDB client with additional functionality
@@ -14,16 +12,16 @@ type MongoClient struct {
 	database *mongo.Database
 }
 
-// NewMongoClient creates a new MongoDB client connection
+// NewMongoClient creates a new MongoDB client connection, using the pool and
+// timeout settings from ConnectionConfigFromEnv
 func NewMongoClient(uri, databaseName string) (*MongoClient, error) {
-	// Set client options
-	clientOptions := options.Client().ApplyURI(uri)
+	config := ConnectionConfigFromEnv()
 
 	// Connect to MongoDB
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), config.ConnectTimeout)
 	defer cancel()
 
-	client, err := mongo.Connect(ctx, clientOptions)
+	client, err := mongo.Connect(ctx, config.ClientOptions(uri))
 	if err != nil {
 		return nil, err
 	}
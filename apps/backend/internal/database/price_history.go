@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PricePoint is a slot's price as first seen by the scraper. The scraper only
+// records a point when a slot's price differs from the last one it recorded.
+type PricePoint struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty"`
+	VenueID    primitive.ObjectID `bson:"venue_id"`
+	CourtID    string             `bson:"court_id"`
+	CourtName  string             `bson:"court_name"`
+	Date       string             `bson:"date"`       // YYYY-MM-DD
+	StartTime  string             `bson:"start_time"` // HH:MM
+	Price      float64            `bson:"price"`
+	Currency   string             `bson:"currency"`
+	ObservedAt time.Time          `bson:"observed_at"`
+}
+
+// PriceHistoryQuery selects a venue's price points. Court matches a court ID
+// or name, since some platforms report their own IDs; empty fields match all.
+type PriceHistoryQuery struct {
+	VenueID   primitive.ObjectID
+	Court     string
+	StartTime string
+	Since     time.Time
+	Limit     int64
+}
+
+// PriceHistoryRepository handles operations on the price_history collection
+type PriceHistoryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPriceHistoryRepository creates a new price history repository
+func NewPriceHistoryRepository(db *mongo.Database) *PriceHistoryRepository {
+	return &PriceHistoryRepository{
+		collection: db.Collection("price_history"),
+	}
+}
+
+// FindSeries returns the matching price points, oldest first
+func (r *PriceHistoryRepository) FindSeries(ctx context.Context, query PriceHistoryQuery) ([]*PricePoint, error) {
+	filter := bson.M{"venue_id": query.VenueID}
+	if query.Court != "" {
+		filter["$or"] = []bson.M{{"court_id": query.Court}, {"court_name": query.Court}}
+	}
+	if query.StartTime != "" {
+		filter["start_time"] = query.StartTime
+	}
+	if !query.Since.IsZero() {
+		filter["observed_at"] = bson.M{"$gte": query.Since}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "observed_at", Value: 1}})
+	if query.Limit > 0 {
+		opts.SetLimit(query.Limit)
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	points := []*PricePoint{}
+	if err := cursor.All(ctx, &points); err != nil {
+		return nil, err
+	}
+	return points, nil
+}
+
+// AveragePrice returns the mean of the prices recorded for a court's start
+// time since the given time, across all dates, with how many points it covers.
+// It returns 0 points when nothing was recorded.
+func (r *PriceHistoryRepository) AveragePrice(ctx context.Context, venueID primitive.ObjectID, courtID, startTime string, since time.Time) (float64, int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"venue_id":    venueID,
+			"court_id":    courtID,
+			"start_time":  startTime,
+			"observed_at": bson.M{"$gte": since},
+		}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     nil,
+			"average": bson.M{"$avg": "$price"},
+			"points":  bson.M{"$sum": 1},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Average float64 `bson:"average"`
+		Points  int     `bson:"points"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return 0, 0, err
+	}
+	if len(results) == 0 {
+		return 0, 0, nil
+	}
+	return results[0].Average, results[0].Points, nil
+}
+
+// Insert stores a price point
+func (r *PriceHistoryRepository) Insert(ctx context.Context, point *PricePoint) error {
+	result, err := r.collection.InsertOne(ctx, point)
+	if err != nil {
+		return err
+	}
+	point.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// CreateIndexes creates the indexes behind venue price history lookups and
+// the scraper's lookup of each slot's last recorded price
+func (r *PriceHistoryRepository) CreateIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "venue_id", Value: 1}, {Key: "start_time", Value: 1}, {Key: "observed_at", Value: 1}}},
+		{Keys: bson.D{{Key: "venue_id", Value: 1}, {Key: "date", Value: 1}, {Key: "observed_at", Value: 1}}},
+	})
+	return err
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPriceHistoryRepository_FindSeries(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPriceHistoryRepository(db)
+	ctx := context.Background()
+	if err := repo.CreateIndexes(ctx); err != nil {
+		t.Fatalf("Failed to create indexes: %v", err)
+	}
+
+	venueID := primitive.NewObjectID()
+	start := time.Now().Add(-48 * time.Hour).Truncate(time.Millisecond)
+	points := []*PricePoint{
+		{VenueID: venueID, CourtID: "1", CourtName: "Court 1", Date: "2024-01-15", StartTime: "18:00", Price: 12, ObservedAt: start},
+		{VenueID: venueID, CourtID: "1", CourtName: "Court 1", Date: "2024-01-15", StartTime: "18:00", Price: 15, ObservedAt: start.Add(24 * time.Hour)},
+		{VenueID: venueID, CourtID: "1", CourtName: "Court 1", Date: "2024-01-15", StartTime: "09:00", Price: 8, ObservedAt: start},
+		{VenueID: venueID, CourtID: "2", CourtName: "Court 2", Date: "2024-01-15", StartTime: "18:00", Price: 14, ObservedAt: start},
+		{VenueID: primitive.NewObjectID(), CourtID: "1", CourtName: "Court 1", Date: "2024-01-15", StartTime: "18:00", Price: 20, ObservedAt: start},
+	}
+	for _, point := range points {
+		if err := repo.Insert(ctx, point); err != nil {
+			t.Fatalf("Failed to insert price point: %v", err)
+		}
+	}
+
+	series, err := repo.FindSeries(ctx, PriceHistoryQuery{VenueID: venueID, Court: "Court 1", StartTime: "18:00"})
+	if err != nil {
+		t.Fatalf("Failed to find price series: %v", err)
+	}
+	if len(series) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(series))
+	}
+	if series[0].Price != 12 || series[1].Price != 15 {
+		t.Errorf("Expected prices 12 then 15, got %v then %v", series[0].Price, series[1].Price)
+	}
+
+	recent, err := repo.FindSeries(ctx, PriceHistoryQuery{VenueID: venueID, Court: "1", StartTime: "18:00", Since: start.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to find recent price series: %v", err)
+	}
+	if len(recent) != 1 || recent[0].Price != 15 {
+		t.Errorf("Expected only the later point, got %+v", recent)
+	}
+
+	all, err := repo.FindSeries(ctx, PriceHistoryQuery{VenueID: venueID})
+	if err != nil {
+		t.Fatalf("Failed to find venue price history: %v", err)
+	}
+	if len(all) != 4 {
+		t.Errorf("Expected 4 points for the venue, got %d", len(all))
+	}
+}
+
+func TestPriceHistoryRepository_AveragePrice(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewPriceHistoryRepository(db)
+	ctx := context.Background()
+
+	venueID := primitive.NewObjectID()
+	start := time.Now().Add(-48 * time.Hour).Truncate(time.Millisecond)
+	points := []*PricePoint{
+		{VenueID: venueID, CourtID: "1", Date: "2024-01-15", StartTime: "18:00", Price: 12, ObservedAt: start},
+		{VenueID: venueID, CourtID: "1", Date: "2024-01-16", StartTime: "18:00", Price: 18, ObservedAt: start.Add(24 * time.Hour)},
+		{VenueID: venueID, CourtID: "1", Date: "2024-01-15", StartTime: "09:00", Price: 6, ObservedAt: start},
+		{VenueID: venueID, CourtID: "2", Date: "2024-01-15", StartTime: "18:00", Price: 30, ObservedAt: start},
+	}
+	for _, point := range points {
+		if err := repo.Insert(ctx, point); err != nil {
+			t.Fatalf("Failed to insert price point: %v", err)
+		}
+	}
+
+	average, count, err := repo.AveragePrice(ctx, venueID, "1", "18:00", start)
+	if err != nil {
+		t.Fatalf("Failed to average prices: %v", err)
+	}
+	if average != 15 || count != 2 {
+		t.Errorf("Expected an average of 15 over 2 points, got %v over %d", average, count)
+	}
+
+	average, count, err = repo.AveragePrice(ctx, venueID, "1", "18:00", start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Failed to average recent prices: %v", err)
+	}
+	if average != 18 || count != 1 {
+		t.Errorf("Expected only the later point, got %v over %d", average, count)
+	}
+
+	_, count, err = repo.AveragePrice(ctx, venueID, "3", "18:00", start)
+	if err != nil {
+		t.Fatalf("Failed to average prices for an unknown court: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected no points for an unknown court, got %d", count)
+	}
+}
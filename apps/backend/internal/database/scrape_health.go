@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ScrapeHealth summarises a venue's recent scraping logs
+type ScrapeHealth struct {
+	VenueID              primitive.ObjectID
+	Since                time.Time
+	TotalScrapes         int64
+	SuccessfulScrapes    int64
+	AvgDurationMs        float64
+	LastSuccessfulScrape *time.Time // May be before Since; nil if the venue has never scraped successfully
+	CommonErrors         []ErrorCount
+}
+
+// ErrorCount is how often one error string appeared in scraping logs
+type ErrorCount struct {
+	Error string `bson:"_id"`
+	Count int64  `bson:"count"`
+}
+
+// SuccessRate returns the fraction of scrapes that succeeded, or 0 when there were none
+func (h *ScrapeHealth) SuccessRate() float64 {
+	if h.TotalScrapes == 0 {
+		return 0
+	}
+	return float64(h.SuccessfulScrapes) / float64(h.TotalScrapes)
+}
+
+// GetScrapeHealth aggregates a venue's scraping logs since the given time into
+// its success rate, average duration and most frequent errors. The match uses
+// the venue_id + scrape_timestamp index.
+func (r *ScrapingLogRepository) GetScrapeHealth(ctx context.Context, venueID primitive.ObjectID, since time.Time, topErrors int) (*ScrapeHealth, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"venue_id":         venueID,
+			"scrape_timestamp": bson.M{"$gte": since},
+		}}},
+		{{Key: "$facet", Value: bson.M{
+			"summary": bson.A{
+				bson.M{"$group": bson.M{
+					"_id":          nil,
+					"total":        bson.M{"$sum": 1},
+					"successful":   bson.M{"$sum": bson.M{"$cond": bson.A{"$success", 1, 0}}},
+					"avg_duration": bson.M{"$avg": "$scrape_duration_ms"},
+				}},
+			},
+			"errors": bson.A{
+				bson.M{"$unwind": "$errors"},
+				bson.M{"$group": bson.M{"_id": "$errors", "count": bson.M{"$sum": 1}}},
+				bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+				bson.M{"$limit": topErrors},
+			},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Summary []struct {
+			Total       int64   `bson:"total"`
+			Successful  int64   `bson:"successful"`
+			AvgDuration float64 `bson:"avg_duration"`
+		} `bson:"summary"`
+		Errors []ErrorCount `bson:"errors"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	health := &ScrapeHealth{VenueID: venueID, Since: since, CommonErrors: []ErrorCount{}}
+	if len(results) > 0 {
+		if len(results[0].Summary) > 0 {
+			summary := results[0].Summary[0]
+			health.TotalScrapes = summary.Total
+			health.SuccessfulScrapes = summary.Successful
+			health.AvgDurationMs = summary.AvgDuration
+		}
+		if results[0].Errors != nil {
+			health.CommonErrors = results[0].Errors
+		}
+	}
+
+	// The last success is looked up without the window, so a venue that has been
+	// failing for longer than it still shows when it last worked
+	var last struct {
+		ScrapeTimestamp time.Time `bson:"scrape_timestamp"`
+	}
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: "scrape_timestamp", Value: -1}}).
+		SetProjection(bson.M{"scrape_timestamp": 1})
+	err = r.collection.FindOne(ctx, bson.M{"venue_id": venueID, "success": true}, opts).Decode(&last)
+	switch {
+	case err == nil:
+		health.LastSuccessfulScrape = &last.ScrapeTimestamp
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return nil, err
+	}
+
+	return health, nil
+}
@@ -261,6 +261,55 @@ func (r *ScrapingLogRepository) List(ctx context.Context, skip, limit int64) ([]
 	return logs, nil
 }
 
+// ScrapeOutcome summarises one completed scrape
+type ScrapeOutcome struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	VenueID    primitive.ObjectID `bson:"venue_id" json:"venueId"`
+	VenueName  string             `bson:"venue_name" json:"venueName"`
+	Success    bool               `bson:"success" json:"success"`
+	SlotsFound int                `bson:"slots_found" json:"slotsFound"`
+	DurationMs int                `bson:"scrape_duration_ms" json:"durationMs"`
+	ScrapedAt  time.Time          `bson:"scrape_timestamp" json:"scrapedAt"`
+	Errors     []string           `bson:"errors" json:"errors,omitempty"`
+
+	// The venue's selectors may have stopped matching its pages
+	SuspectedBrokenSelector bool `bson:"suspected_broken_selector" json:"suspectedBrokenSelector,omitempty"`
+}
+
+// FindOutcomesAfter returns up to limit scrapes logged after the log with ID
+// after, oldest first. ObjectIDs start with their creation time, so a
+// timestamp-only ID such as primitive.NewObjectIDFromTimestamp(now) finds the
+// scrapes logged from then on.
+func (r *ScrapingLogRepository) FindOutcomesAfter(ctx context.Context, after primitive.ObjectID, limit int64) ([]ScrapeOutcome, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"_id": bson.M{"$gt": after}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$project", Value: bson.M{
+			"venue_id":                  1,
+			"venue_name":                1,
+			"success":                   1,
+			"slots_found":               slotsFoundCount,
+			"scrape_duration_ms":        1,
+			"scrape_timestamp":          1,
+			"errors":                    1,
+			"suspected_broken_selector": 1,
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	outcomes := []ScrapeOutcome{}
+	if err := cursor.All(ctx, &outcomes); err != nil {
+		return nil, err
+	}
+	return outcomes, nil
+}
+
 // CreateIndexes creates any necessary indexes for the scraping_logs collection
 func (r *ScrapingLogRepository) CreateIndexes(ctx context.Context) error {
 	// Create an index on the venue_id field
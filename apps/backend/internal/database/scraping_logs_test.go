@@ -10,6 +10,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -290,3 +291,80 @@ func TestScrapingLogRepository_DeleteOlderThan(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, int64(6), count) // Should have 6 logs left (0 to 5 days ago)
 }
+
+func TestScrapingLogRepository_GetScrapeHealth(t *testing.T) {
+	_, repo, cleanup := setupScrapingLogTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	venueID := primitive.NewObjectID()
+	now := time.Now()
+
+	logs := []*models.ScrapingLog{
+		// Outside the window: only counts as the last success
+		{VenueID: venueID, ScrapeTimestamp: now.Add(-48 * time.Hour), Success: true, ScrapeDurationMs: 9000},
+		{VenueID: venueID, ScrapeTimestamp: now.Add(-3 * time.Hour), Success: false, ScrapeDurationMs: 3000, Errors: []string{"timeout", "login failed"}},
+		{VenueID: venueID, ScrapeTimestamp: now.Add(-2 * time.Hour), Success: false, ScrapeDurationMs: 2000, Errors: []string{"timeout"}},
+		{VenueID: venueID, ScrapeTimestamp: now.Add(-1 * time.Hour), Success: true, ScrapeDurationMs: 1000},
+		// Another venue's logs are ignored
+		{VenueID: primitive.NewObjectID(), ScrapeTimestamp: now, Success: false, Errors: []string{"timeout"}},
+	}
+	for _, log := range logs {
+		require.NoError(t, repo.Create(ctx, log))
+	}
+
+	health, err := repo.GetScrapeHealth(ctx, venueID, now.Add(-24*time.Hour), 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), health.TotalScrapes)
+	assert.Equal(t, int64(1), health.SuccessfulScrapes)
+	assert.InDelta(t, 1.0/3, health.SuccessRate(), 0.0001)
+	assert.Equal(t, 2000.0, health.AvgDurationMs)
+	require.NotNil(t, health.LastSuccessfulScrape)
+	assert.WithinDuration(t, now.Add(-1*time.Hour), *health.LastSuccessfulScrape, time.Second)
+	assert.Equal(t, []ErrorCount{{Error: "timeout", Count: 2}, {Error: "login failed", Count: 1}}, health.CommonErrors)
+
+	// A venue with no logs reports nothing rather than an error
+	health, err = repo.GetScrapeHealth(ctx, primitive.NewObjectID(), now.Add(-24*time.Hour), 5)
+	require.NoError(t, err)
+	assert.Zero(t, health.TotalScrapes)
+	assert.Zero(t, health.SuccessRate())
+	assert.Nil(t, health.LastSuccessfulScrape)
+	assert.Empty(t, health.CommonErrors)
+}
+
+func TestScrapingLogRepository_FindOutcomesAfter(t *testing.T) {
+	db, repo, cleanup := setupScrapingLogTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	start := time.Now().Add(-time.Minute)
+	venueID := primitive.NewObjectID()
+
+	earlier := primitive.NewObjectIDFromTimestamp(start.Add(-time.Hour))
+	first, second, third := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	_, err := db.Collection("scraping_logs").InsertMany(ctx, []interface{}{
+		bson.M{"_id": earlier, "venue_id": venueID, "success": true, "slots_found": 9},
+		// The scraper stores a count, ScrapingLogRepository.Create stores the slots
+		bson.M{"_id": first, "venue_id": venueID, "venue_name": "Victoria Park", "success": true, "slots_found": 3, "scrape_duration_ms": 1200, "scrape_timestamp": start},
+		bson.M{"_id": second, "venue_id": venueID, "success": true, "slots_found": bson.A{bson.M{}, bson.M{}}, "suspected_broken_selector": true},
+		bson.M{"_id": third, "venue_id": venueID, "success": false, "errors": bson.A{"timeout"}},
+	})
+	require.NoError(t, err)
+
+	outcomes, err := repo.FindOutcomesAfter(ctx, primitive.NewObjectIDFromTimestamp(start), 2)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 2)
+	assert.Equal(t, first, outcomes[0].ID)
+	assert.Equal(t, "Victoria Park", outcomes[0].VenueName)
+	assert.Equal(t, 3, outcomes[0].SlotsFound)
+	assert.Equal(t, 1200, outcomes[0].DurationMs)
+	assert.Equal(t, 2, outcomes[1].SlotsFound)
+	assert.False(t, outcomes[0].SuspectedBrokenSelector)
+	assert.True(t, outcomes[1].SuspectedBrokenSelector)
+
+	outcomes, err = repo.FindOutcomesAfter(ctx, second, 10)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 1)
+	assert.False(t, outcomes[0].Success)
+	assert.Equal(t, []string{"timeout"}, outcomes[0].Errors)
+}
@@ -0,0 +1,331 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"tennis-booker/internal/models"
+)
+
+// Slot search sort orders
+const (
+	SlotSortTime  = "time"
+	SlotSortPrice = "price"
+	SlotSortVenue = "venue"
+)
+
+// SlotSearch filters available slots by the slot itself and by the court it is on
+type SlotSearch struct {
+	VenueID  *primitive.ObjectID
+	Provider string   // Scraper platform, e.g. "clubspark"
+	Date     string   // YYYY-MM-DD; today onwards when empty
+	MinPrice *float64 // Inclusive
+	MaxPrice *float64 // Inclusive
+	TimeFrom string   // HH:MM; slots starting at or after this time
+	TimeTo   string   // HH:MM; slots ending at or before this time
+
+	// Court attributes, matched against the venue's court list
+	Surface     string
+	Indoor      *bool
+	Floodlights *bool
+
+	Sort  string // SlotSortTime (default), SlotSortPrice or SlotSortVenue
+	Skip  int64
+	Limit int64
+
+	// After restricts SearchAvailableSlotsAfter to slots following this one in time order
+	After *SlotCursor
+}
+
+// SlotCursor is a slot's position in time order (date, start time, then ID),
+// used for keyset pagination
+type SlotCursor struct {
+	Date      string
+	StartTime string
+	ID        primitive.ObjectID
+}
+
+// hasCourtFilter reports whether the search needs the venues' court lists
+func (s SlotSearch) hasCourtFilter() bool {
+	return s.Surface != "" || s.Indoor != nil || s.Floodlights != nil
+}
+
+// courtMatches reports whether a court has every attribute the search asks for
+func (s SlotSearch) courtMatches(court models.Court) bool {
+	if s.Surface != "" && !strings.EqualFold(court.Surface, s.Surface) {
+		return false
+	}
+	if s.Indoor != nil && court.Indoor != *s.Indoor {
+		return false
+	}
+	if s.Floodlights != nil && court.Floodlights != *s.Floodlights {
+		return false
+	}
+	return true
+}
+
+// SearchAvailableSlots returns one page of available slots matching search, and
+// the total number of matching slots across all pages
+func (r *SlotsRepository) SearchAvailableSlots(ctx context.Context, search SlotSearch) ([]*models.CourtSlot, int64, error) {
+	search.After = nil
+
+	filter, err := r.searchFilter(ctx, search)
+	if err != nil {
+		return nil, 0, err
+	}
+	if filter == nil {
+		return []*models.CourtSlot{}, 0, nil
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find().SetSort(slotSearchSort(search.Sort))
+	if search.Skip > 0 {
+		opts.SetSkip(search.Skip)
+	}
+	if search.Limit > 0 {
+		opts.SetLimit(search.Limit)
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	slots, err := decodeSlots(ctx, cursor)
+	if err != nil {
+		return nil, 0, err
+	}
+	return slots, total, nil
+}
+
+// SearchAvailableSlotsAfter returns up to search.Limit available slots matching
+// search that come after search.After in time order, or the first page when it
+// is nil. Sort and Skip are ignored. The returned cursor marks the last slot and
+// is nil when there are no more pages. Unlike offset pages, rows added or removed
+// between requests don't cause later pages to skip or repeat slots.
+func (r *SlotsRepository) SearchAvailableSlotsAfter(ctx context.Context, search SlotSearch) ([]*models.CourtSlot, *SlotCursor, error) {
+	filter, err := r.searchFilter(ctx, search)
+	if err != nil {
+		return nil, nil, err
+	}
+	if filter == nil {
+		return []*models.CourtSlot{}, nil, nil
+	}
+
+	opts := options.Find().SetSort(slotSearchSort(SlotSortTime))
+	if search.Limit > 0 {
+		// One extra slot shows whether there is another page
+		opts.SetLimit(search.Limit + 1)
+	}
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cursor.Close(ctx)
+
+	slots, err := decodeSlots(ctx, cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if search.Limit <= 0 || int64(len(slots)) <= search.Limit {
+		return slots, nil, nil
+	}
+	slots = slots[:search.Limit]
+	last := slots[len(slots)-1]
+	lastID, err := primitive.ObjectIDFromHex(last.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return slots, &SlotCursor{Date: last.Date, StartTime: last.StartTime, ID: lastID}, nil
+}
+
+// searchFilter builds the slots query for search, looking up the matching
+// courts when it filters on court attributes. It returns a nil filter when no
+// court matches, so nothing can.
+func (r *SlotsRepository) searchFilter(ctx context.Context, search SlotSearch) (bson.M, error) {
+	var courts []bson.M
+	if search.hasCourtFilter() {
+		var err error
+		courts, err = r.matchingCourts(ctx, search)
+		if err != nil || len(courts) == 0 {
+			return nil, err
+		}
+	}
+	return slotSearchFilter(search, courts, time.Now()), nil
+}
+
+// matchingCourts returns a slot filter clause per venue court with the searched
+// attributes. Scrapers don't always report the configured court ID, so a slot
+// matches a court by ID or by name.
+func (r *SlotsRepository) matchingCourts(ctx context.Context, search SlotSearch) ([]bson.M, error) {
+	venueFilter := bson.M{"is_active": true}
+	if search.VenueID != nil {
+		venueFilter["_id"] = *search.VenueID
+	}
+
+	opts := options.Find().SetProjection(bson.M{"courts": 1})
+	cursor, err := r.collection.Database().Collection("venues").Find(ctx, venueFilter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var venues []models.Venue
+	if err := cursor.All(ctx, &venues); err != nil {
+		return nil, err
+	}
+
+	var clauses []bson.M
+	for _, venue := range venues {
+		var ids, names []string
+		for _, court := range venue.Courts {
+			if search.courtMatches(court) {
+				ids = append(ids, court.ID)
+				names = append(names, court.Name)
+			}
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		clauses = append(clauses,
+			bson.M{"venue_id": venue.ID, "court_id": bson.M{"$in": ids}},
+			bson.M{"venue_id": venue.ID, "court_name": bson.M{"$in": names}},
+		)
+	}
+
+	return clauses, nil
+}
+
+// slotSearchFilter builds the slots query; courts, when non-nil, restricts it to those courts
+func slotSearchFilter(search SlotSearch, courts []bson.M, now time.Time) bson.M {
+	filter := bson.M{"available": true}
+
+	if search.Date != "" {
+		filter["date"] = search.Date
+	} else {
+		filter["date"] = bson.M{"$gte": now.Format("2006-01-02")}
+	}
+	if search.VenueID != nil {
+		filter["venue_id"] = *search.VenueID
+	}
+	if search.Provider != "" {
+		filter["platform"] = search.Provider
+	}
+
+	price := bson.M{}
+	if search.MinPrice != nil {
+		price["$gte"] = *search.MinPrice
+	}
+	if search.MaxPrice != nil {
+		price["$lte"] = *search.MaxPrice
+	}
+	if len(price) > 0 {
+		filter["price"] = price
+	}
+
+	// HH:MM strings sort the same as the times they represent
+	if search.TimeFrom != "" {
+		filter["start_time"] = bson.M{"$gte": search.TimeFrom}
+	}
+	if search.TimeTo != "" {
+		filter["end_time"] = bson.M{"$lte": search.TimeTo}
+	}
+
+	if courts != nil {
+		filter["$or"] = courts
+	}
+
+	// Keyset pagination: slots after the cursor in (date, start_time, _id) order
+	if after := search.After; after != nil {
+		filter["$and"] = []bson.M{{"$or": []bson.M{
+			{"date": bson.M{"$gt": after.Date}},
+			{"date": after.Date, "start_time": bson.M{"$gt": after.StartTime}},
+			{"date": after.Date, "start_time": after.StartTime, "_id": bson.M{"$gt": after.ID}},
+		}}}
+	}
+
+	return filter
+}
+
+// slotSearchSort returns the sort for a SlotSearch.Sort value; each matches an
+// index from CreateIndexes, with _id breaking ties so pages are stable
+func slotSearchSort(sort string) bson.D {
+	switch sort {
+	case SlotSortPrice:
+		return bson.D{{Key: "price", Value: 1}, {Key: "date", Value: 1}, {Key: "start_time", Value: 1}, {Key: "_id", Value: 1}}
+	case SlotSortVenue:
+		return bson.D{{Key: "venue_name", Value: 1}, {Key: "date", Value: 1}, {Key: "start_time", Value: 1}, {Key: "_id", Value: 1}}
+	default:
+		return bson.D{{Key: "date", Value: 1}, {Key: "start_time", Value: 1}, {Key: "_id", Value: 1}}
+	}
+}
+
+// CreateIndexes creates the indexes behind the available-slot queries and their sort orders
+func (r *SlotsRepository) CreateIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "available", Value: 1}, {Key: "date", Value: 1}, {Key: "start_time", Value: 1}}},
+		{Keys: bson.D{{Key: "available", Value: 1}, {Key: "price", Value: 1}, {Key: "date", Value: 1}, {Key: "start_time", Value: 1}}},
+		{Keys: bson.D{{Key: "available", Value: 1}, {Key: "venue_name", Value: 1}, {Key: "date", Value: 1}, {Key: "start_time", Value: 1}}},
+		// Time-ordered pages of a single venue, including keyset pages
+		{Keys: bson.D{{Key: "available", Value: 1}, {Key: "venue_id", Value: 1}, {Key: "date", Value: 1}, {Key: "start_time", Value: 1}}},
+	})
+	return err
+}
+
+// decodeSlots reads slot documents as stored by the scraper into CourtSlots
+func decodeSlots(ctx context.Context, cursor *mongo.Cursor) ([]*models.CourtSlot, error) {
+	slots := []*models.CourtSlot{}
+	for cursor.Next(ctx) {
+		var dbSlot struct {
+			ID         primitive.ObjectID `bson:"_id"`
+			VenueID    primitive.ObjectID `bson:"venue_id"`
+			VenueName  string             `bson:"venue_name"`
+			CourtID    string             `bson:"court_id"`
+			CourtName  string             `bson:"court_name"`
+			Date       string             `bson:"date"`
+			StartTime  string             `bson:"start_time"`
+			EndTime    string             `bson:"end_time"`
+			Price      float64            `bson:"price"`
+			Currency   string             `bson:"currency"`
+			Available  bool               `bson:"available"`
+			BookingURL string             `bson:"booking_url"`
+			ScrapedAt  time.Time          `bson:"scraped_at"`
+			Platform   string             `bson:"platform"`
+		}
+
+		if err := cursor.Decode(&dbSlot); err != nil {
+			continue // Skip invalid slots
+		}
+
+		slots = append(slots, &models.CourtSlot{
+			ID:          dbSlot.ID.Hex(),
+			VenueID:     dbSlot.VenueID,
+			VenueName:   dbSlot.VenueName,
+			CourtID:     dbSlot.CourtID,
+			CourtName:   dbSlot.CourtName,
+			Date:        dbSlot.Date,
+			StartTime:   dbSlot.StartTime,
+			EndTime:     dbSlot.EndTime,
+			Price:       dbSlot.Price,
+			Currency:    dbSlot.Currency,
+			Available:   dbSlot.Available,
+			BookingURL:  dbSlot.BookingURL,
+			Provider:    dbSlot.Platform,
+			LastScraped: dbSlot.ScrapedAt,
+		})
+	}
+
+	return slots, cursor.Err()
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tennis-booker/internal/models"
+)
+
+func TestSlotSearchFilter(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	minPrice, maxPrice := 10.0, 25.0
+	venueID := primitive.NewObjectID()
+
+	assert.Equal(t, bson.M{
+		"available": true,
+		"date":      bson.M{"$gte": "2024-06-10"},
+	}, slotSearchFilter(SlotSearch{}, nil, now))
+
+	courts := []bson.M{{"venue_id": venueID, "court_id": bson.M{"$in": []string{"1"}}}}
+	assert.Equal(t, bson.M{
+		"available":  true,
+		"date":       "2024-06-15",
+		"venue_id":   venueID,
+		"price":      bson.M{"$gte": minPrice, "$lte": maxPrice},
+		"start_time": bson.M{"$gte": "18:00"},
+		"end_time":   bson.M{"$lte": "21:00"},
+		"$or":        courts,
+	}, slotSearchFilter(SlotSearch{
+		VenueID:  &venueID,
+		Date:     "2024-06-15",
+		MinPrice: &minPrice,
+		MaxPrice: &maxPrice,
+		TimeFrom: "18:00",
+		TimeTo:   "21:00",
+	}, courts, now))
+}
+
+func TestSlotSearch_CourtMatches(t *testing.T) {
+	indoor, lit := true, true
+	court := models.Court{ID: "1", Surface: "Hard", Indoor: true, Floodlights: false}
+
+	assert.True(t, SlotSearch{Surface: "hard"}.courtMatches(court))
+	assert.True(t, SlotSearch{Indoor: &indoor}.courtMatches(court))
+	assert.False(t, SlotSearch{Surface: "clay"}.courtMatches(court))
+	assert.False(t, SlotSearch{Floodlights: &lit}.courtMatches(court))
+}
+
+func TestSlotSearchSort(t *testing.T) {
+	assert.Equal(t, "date", slotSearchSort("")[0].Key)
+	assert.Equal(t, "price", slotSearchSort(SlotSortPrice)[0].Key)
+	assert.Equal(t, "venue_name", slotSearchSort(SlotSortVenue)[0].Key)
+}
+
+func TestSlotSearchFilter_After(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	id := primitive.NewObjectID()
+
+	filter := slotSearchFilter(SlotSearch{After: &SlotCursor{Date: "2024-06-12", StartTime: "18:00", ID: id}}, nil, now)
+
+	assert.Equal(t, []bson.M{{"$or": []bson.M{
+		{"date": bson.M{"$gt": "2024-06-12"}},
+		{"date": "2024-06-12", "start_time": bson.M{"$gt": "18:00"}},
+		{"date": "2024-06-12", "start_time": "18:00", "_id": bson.M{"$gt": id}},
+	}}}, filter["$and"])
+	assert.Equal(t, bson.M{"$gte": "2024-06-10"}, filter["date"], "the date filter still applies")
+}
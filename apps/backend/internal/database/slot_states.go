@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// slotStateTTL is how long a slot's state is kept after it was last scraped.
+// Slots that stop appearing have usually passed, so their state is no longer needed.
+const slotStateTTL = 7 * 24 * time.Hour
+
+// SlotTransition describes how a slot's availability changed since it was last scraped
+type SlotTransition string
+
+// Slot transitions returned by SlotStateRepository.Observe
+const (
+	SlotUnchanged   SlotTransition = "unchanged"   // Same availability as last time
+	SlotAppeared    SlotTransition = "appeared"    // First time the slot was seen, and it is available
+	SlotReopened    SlotTransition = "reopened"    // Was unavailable, now available
+	SlotTaken       SlotTransition = "taken"       // Was available, now unavailable
+	SlotUnavailable SlotTransition = "unavailable" // First time the slot was seen, and it is unavailable
+)
+
+// BecameAvailable reports whether the slot has just become bookable
+func (t SlotTransition) BecameAvailable() bool {
+	return t == SlotAppeared || t == SlotReopened
+}
+
+// SlotState is the last-known availability of a slot, keyed by
+// CourtAvailabilityEvent.GenerateSlotKey
+type SlotState struct {
+	SlotKey     string    `bson:"_id"`
+	Available   bool      `bson:"available"`
+	FirstSeenAt time.Time `bson:"first_seen_at"`
+	LastSeenAt  time.Time `bson:"last_seen_at"`
+}
+
+// slotTransition compares a slot's previous state, nil if it has never been
+// seen, with its availability now
+func slotTransition(previous *SlotState, available bool) SlotTransition {
+	switch {
+	case previous == nil && available:
+		return SlotAppeared
+	case previous == nil:
+		return SlotUnavailable
+	case previous.Available == available:
+		return SlotUnchanged
+	case available:
+		return SlotReopened
+	default:
+		return SlotTaken
+	}
+}
+
+// SlotStateRepository handles operations on the slot_states collection
+type SlotStateRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSlotStateRepository creates a new slot state repository
+func NewSlotStateRepository(db *mongo.Database) *SlotStateRepository {
+	return &SlotStateRepository{
+		collection: db.Collection("slot_states"),
+	}
+}
+
+// Observe records the slot's availability as of seenAt and returns how it
+// changed. The read and write are a single upsert, so concurrent consumers
+// see each transition exactly once.
+func (r *SlotStateRepository) Observe(ctx context.Context, slotKey string, available bool, seenAt time.Time) (SlotTransition, error) {
+	update := bson.M{
+		"$set":         bson.M{"available": available, "last_seen_at": seenAt},
+		"$setOnInsert": bson.M{"first_seen_at": seenAt},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var previous SlotState
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": slotKey}, update, opts).Decode(&previous)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return slotTransition(nil, available), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return slotTransition(&previous, available), nil
+}
+
+// FindByKey returns the stored state of a slot, or nil if it has never been seen
+func (r *SlotStateRepository) FindByKey(ctx context.Context, slotKey string) (*SlotState, error) {
+	var state SlotState
+	err := r.collection.FindOne(ctx, bson.M{"_id": slotKey}).Decode(&state)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// CreateIndexes expires slot states that haven't been scraped for slotStateTTL
+func (r *SlotStateRepository) CreateIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "last_seen_at", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(int32(slotStateTTL.Seconds())),
+	})
+	return err
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSlotTransition(t *testing.T) {
+	available := &SlotState{Available: true}
+	unavailable := &SlotState{Available: false}
+
+	tests := []struct {
+		name      string
+		previous  *SlotState
+		available bool
+		want      SlotTransition
+	}{
+		{"new available slot", nil, true, SlotAppeared},
+		{"new unavailable slot", nil, false, SlotUnavailable},
+		{"still available", available, true, SlotUnchanged},
+		{"still unavailable", unavailable, false, SlotUnchanged},
+		{"freed up", unavailable, true, SlotReopened},
+		{"booked", available, false, SlotTaken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slotTransition(tt.previous, tt.available)
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+			if got.BecameAvailable() != (tt.want == SlotAppeared || tt.want == SlotReopened) {
+				t.Errorf("Unexpected BecameAvailable for %s", got)
+			}
+		})
+	}
+}
+
+func TestSlotStateRepository_Observe(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewSlotStateRepository(db)
+	ctx := context.Background()
+	key := primitive.NewObjectID().Hex() + ":court1:2024-01-15:18:00"
+	first := time.Now().Truncate(time.Millisecond)
+
+	steps := []struct {
+		available bool
+		want      SlotTransition
+	}{
+		{true, SlotAppeared},
+		{true, SlotUnchanged},
+		{false, SlotTaken},
+		{false, SlotUnchanged},
+		{true, SlotReopened},
+	}
+
+	for i, step := range steps {
+		got, err := repo.Observe(ctx, key, step.available, first.Add(time.Duration(i)*time.Minute))
+		if err != nil {
+			t.Fatalf("Failed to observe slot: %v", err)
+		}
+		if got != step.want {
+			t.Errorf("Step %d: expected %s, got %s", i, step.want, got)
+		}
+	}
+
+	state, err := repo.FindByKey(ctx, key)
+	if err != nil {
+		t.Fatalf("Failed to find slot state: %v", err)
+	}
+	if state == nil || !state.Available {
+		t.Fatalf("Expected an available slot state, got %+v", state)
+	}
+	if !state.FirstSeenAt.Equal(first) {
+		t.Errorf("Expected first seen at %v, got %v", first, state.FirstSeenAt)
+	}
+	if want := first.Add(4 * time.Minute); !state.LastSeenAt.Equal(want) {
+		t.Errorf("Expected last seen at %v, got %v", want, state.LastSeenAt)
+	}
+
+	missing, err := repo.FindByKey(ctx, "no-such-slot")
+	if err != nil || missing != nil {
+		t.Errorf("Expected no state for an unseen slot, got %+v, %v", missing, err)
+	}
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson"
+	"tennis-booker/internal/models"
+)
+
+const (
+	// venueCacheKey holds every venue, BSON-encoded
+	venueCacheKey = "cache:venues:all"
+
+	// DefaultVenueCacheTTL is how long cached venues are served when VENUE_CACHE_TTL is not set
+	DefaultVenueCacheTTL = 5 * time.Minute
+
+	// EnvVenueCacheTTL overrides DefaultVenueCacheTTL, as a Go duration such as "10m"
+	EnvVenueCacheTTL = "VENUE_CACHE_TTL"
+)
+
+// ErrCacheMiss is returned by a CacheStore when the key is not cached
+var ErrCacheMiss = errors.New("cache miss")
+
+// CacheStore holds cached values that expire after a TTL
+type CacheStore interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// RedisCacheStore is a CacheStore backed by Redis keys
+type RedisCacheStore struct {
+	client *redis.Client
+}
+
+// NewRedisCacheStore creates a cache store backed by the given Redis client
+func NewRedisCacheStore(client *redis.Client) *RedisCacheStore {
+	return &RedisCacheStore{client: client}
+}
+
+// Get returns the cached value, or ErrCacheMiss
+func (s *RedisCacheStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	}
+	return value, err
+}
+
+// Set caches value for ttl
+func (s *RedisCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Delete removes a cached value
+func (s *RedisCacheStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, key).Err()
+}
+
+// venueLister loads every venue from MongoDB; VenueRepository implements it
+type venueLister interface {
+	List(ctx context.Context, skip, limit int64) ([]*models.Venue, error)
+}
+
+// VenueCacheStats counts venue cache lookups
+type VenueCacheStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"` // Includes lookups served from MongoDB because the cache was unavailable
+}
+
+// VenueCache is a read-through cache of the venues collection. Venue metadata
+// rarely changes, so readers share one cached copy that expires after the TTL
+// or when Invalidate is called after a venue is changed. Without a store, or
+// when the store fails, venues are read straight from MongoDB.
+type VenueCache struct {
+	venues venueLister
+	store  CacheStore // Nil disables caching
+	ttl    time.Duration
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// NewVenueCache creates a venue cache; store may be nil when Redis is unavailable
+func NewVenueCache(venues *VenueRepository, store CacheStore, ttl time.Duration) *VenueCache {
+	return newVenueCache(venues, store, ttl)
+}
+
+func newVenueCache(venues venueLister, store CacheStore, ttl time.Duration) *VenueCache {
+	if ttl <= 0 {
+		ttl = DefaultVenueCacheTTL
+	}
+	return &VenueCache{venues: venues, store: store, ttl: ttl}
+}
+
+// VenueCacheTTLFromEnv returns VENUE_CACHE_TTL, or DefaultVenueCacheTTL if it is unset or invalid
+func VenueCacheTTLFromEnv() time.Duration {
+	return envDuration(EnvVenueCacheTTL, DefaultVenueCacheTTL)
+}
+
+// cachedVenues is the document stored under venueCacheKey
+type cachedVenues struct {
+	Venues []*models.Venue `bson:"venues"`
+}
+
+// GetVenuesCached returns every venue, active or not, from the cache when
+// possible and from MongoDB otherwise, refreshing the cache on a miss
+func (c *VenueCache) GetVenuesCached(ctx context.Context) ([]*models.Venue, error) {
+	if c.store != nil {
+		data, err := c.store.Get(ctx, venueCacheKey)
+		if err == nil {
+			var cached cachedVenues
+			if err := bson.Unmarshal(data, &cached); err == nil {
+				c.hits.Add(1)
+				return cached.Venues, nil
+			}
+			log.Printf("⚠️ Discarding unreadable venue cache entry: %v", err)
+		} else if !errors.Is(err, ErrCacheMiss) {
+			log.Printf("⚠️ Venue cache unavailable, reading venues from MongoDB: %v", err)
+		}
+	}
+
+	c.misses.Add(1)
+	venues, err := c.venues.List(ctx, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if venues == nil {
+		venues = []*models.Venue{}
+	}
+
+	if c.store != nil {
+		data, err := bson.Marshal(cachedVenues{Venues: venues})
+		if err == nil {
+			err = c.store.Set(ctx, venueCacheKey, data, c.ttl)
+		}
+		if err != nil {
+			log.Printf("⚠️ Failed to cache venues: %v", err)
+		}
+	}
+
+	return venues, nil
+}
+
+// Invalidate drops the cached venues so the next read reloads them; call it
+// after creating, updating or deleting a venue
+func (c *VenueCache) Invalidate(ctx context.Context) error {
+	if c.store == nil {
+		return nil
+	}
+	return c.store.Delete(ctx, venueCacheKey)
+}
+
+// Stats returns the number of cache hits and misses so far
+func (c *VenueCache) Stats() VenueCacheStats {
+	return VenueCacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tennis-booker/internal/models"
+)
+
+// memoryCacheStore is an in-memory CacheStore for tests
+type memoryCacheStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+	ttls   map[string]time.Duration
+	err    error
+}
+
+func newMemoryCacheStore() *memoryCacheStore {
+	return &memoryCacheStore{values: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (m *memoryCacheStore) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	value, ok := m.values[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (m *memoryCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.values[key] = value
+	m.ttls[key] = ttl
+	return nil
+}
+
+func (m *memoryCacheStore) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	delete(m.values, key)
+	return nil
+}
+
+// countingVenueLister returns fixed venues and counts how often it was asked
+type countingVenueLister struct {
+	venues []*models.Venue
+	calls  int
+}
+
+func (l *countingVenueLister) List(ctx context.Context, skip, limit int64) ([]*models.Venue, error) {
+	l.calls++
+	return l.venues, nil
+}
+
+func TestVenueCache_ReadThrough(t *testing.T) {
+	lister := &countingVenueLister{venues: []*models.Venue{{
+		ID:       primitive.NewObjectID(),
+		Name:     "Victoria Park",
+		Location: models.Location{City: "London", Latitude: 51.5362, Longitude: -0.0403},
+		Courts:   []models.Court{{ID: "1", Name: "Court 1"}},
+	}}}
+	store := newMemoryCacheStore()
+	cache := newVenueCache(lister, store, time.Minute)
+	ctx := context.Background()
+
+	venues, err := cache.GetVenuesCached(ctx)
+	require.NoError(t, err)
+	require.Len(t, venues, 1)
+	assert.Equal(t, time.Minute, store.ttls[venueCacheKey])
+
+	venues, err = cache.GetVenuesCached(ctx)
+	require.NoError(t, err)
+	require.Len(t, venues, 1)
+	assert.Equal(t, "Victoria Park", venues[0].Name)
+	assert.Equal(t, "Court 1", venues[0].Courts[0].Name)
+	assert.Equal(t, 1, lister.calls, "second read should be served from the cache")
+	assert.Equal(t, VenueCacheStats{Hits: 1, Misses: 1}, cache.Stats())
+
+	// Invalidation forces the next read back to MongoDB
+	require.NoError(t, cache.Invalidate(ctx))
+	_, err = cache.GetVenuesCached(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, lister.calls)
+	assert.Equal(t, VenueCacheStats{Hits: 1, Misses: 2}, cache.Stats())
+}
+
+func TestVenueCache_FallsBackWithoutRedis(t *testing.T) {
+	lister := &countingVenueLister{venues: []*models.Venue{{Name: "Victoria Park"}}}
+	store := newMemoryCacheStore()
+	store.err = errors.New("connection refused")
+	ctx := context.Background()
+
+	for _, cache := range []*VenueCache{newVenueCache(lister, store, time.Minute), newVenueCache(lister, nil, 0)} {
+		venues, err := cache.GetVenuesCached(ctx)
+		require.NoError(t, err)
+		assert.Len(t, venues, 1)
+		assert.Equal(t, VenueCacheStats{Misses: 1}, cache.Stats())
+	}
+	assert.Equal(t, 2, lister.calls)
+}
+
+func TestVenueCacheTTLFromEnv(t *testing.T) {
+	t.Setenv(EnvVenueCacheTTL, "")
+	assert.Equal(t, DefaultVenueCacheTTL, VenueCacheTTLFromEnv())
+
+	t.Setenv(EnvVenueCacheTTL, "30s")
+	assert.Equal(t, 30*time.Second, VenueCacheTTLFromEnv())
+
+	t.Setenv(EnvVenueCacheTTL, "soon")
+	assert.Equal(t, DefaultVenueCacheTTL, VenueCacheTTLFromEnv())
+}
@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// VenuePreferencesMigration reports what MigrateVenuePreferences changed
+type VenuePreferencesMigration struct {
+	Scanned    int               // Preference documents with a venue list
+	Updated    int               // Documents rewritten, or that would be in a dry run
+	Converted  int               // Venue names replaced by IDs
+	Unresolved []UnresolvedVenue // Entries matching no venue, left as they were
+}
+
+// UnresolvedVenue is a venue list entry that is neither a venue ID nor a venue's name
+type UnresolvedVenue struct {
+	UserID primitive.ObjectID
+	Field  string // preferred_venues or excluded_venues
+	Value  string
+}
+
+// MigrateVenuePreferences rewrites venue names in user_preferences'
+// preferred_venues and excluded_venues to the venues' ObjectID hex strings,
+// which is what the matcher compares. Names are matched exactly, then
+// ignoring case and surrounding spaces; entries that match no venue, or more
+// than one, are left alone and reported. With dryRun nothing is written.
+// Running it again is harmless.
+func MigrateVenuePreferences(ctx context.Context, db *mongo.Database, dryRun bool) (*VenuePreferencesMigration, error) {
+	resolver, err := loadVenueResolver(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := db.Collection("user_preferences")
+	filter := bson.M{
+		"$or": []bson.M{
+			{"preferred_venues": bson.M{"$exists": true, "$ne": []interface{}{}}},
+			{"excluded_venues": bson.M{"$exists": true, "$ne": []interface{}{}}},
+		},
+	}
+	projection := bson.M{"user_id": 1, "preferred_venues": 1, "excluded_venues": 1}
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find venue preferences: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	migration := &VenuePreferencesMigration{}
+	for cursor.Next(ctx) {
+		var doc struct {
+			ID              primitive.ObjectID `bson:"_id"`
+			UserID          primitive.ObjectID `bson:"user_id"`
+			PreferredVenues []string           `bson:"preferred_venues"`
+			ExcludedVenues  []string           `bson:"excluded_venues"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode venue preferences: %w", err)
+		}
+		migration.Scanned++
+
+		set := bson.M{}
+		for _, field := range []struct {
+			name   string
+			venues []string
+		}{
+			{"preferred_venues", doc.PreferredVenues},
+			{"excluded_venues", doc.ExcludedVenues},
+		} {
+			resolved, converted, unresolved := resolver.resolve(field.venues)
+			for _, value := range unresolved {
+				migration.Unresolved = append(migration.Unresolved, UnresolvedVenue{UserID: doc.UserID, Field: field.name, Value: value})
+			}
+			if converted > 0 || len(resolved) != len(field.venues) {
+				migration.Converted += converted
+				set[field.name] = resolved
+			}
+		}
+		if len(set) == 0 {
+			continue
+		}
+
+		migration.Updated++
+		if dryRun {
+			continue
+		}
+		if _, err := collection.UpdateByID(ctx, doc.ID, bson.M{"$set": set}); err != nil {
+			return nil, fmt.Errorf("failed to update venue preferences for user %s: %w", doc.UserID.Hex(), err)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read venue preferences: %w", err)
+	}
+
+	return migration, nil
+}
+
+// venueResolver maps venue list entries to venue IDs
+type venueResolver struct {
+	ids    map[string]bool   // Hex IDs of every venue
+	byName map[string]string // Exact name to hex ID; "" when several venues share it
+	folded map[string]string // Lowercased, trimmed name to hex ID; "" when several venues share it
+}
+
+// loadVenueResolver reads every venue's ID and name
+func loadVenueResolver(ctx context.Context, db *mongo.Database) (*venueResolver, error) {
+	cursor, err := db.Collection("venues").Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"name": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find venues: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var venues []venueName
+	if err := cursor.All(ctx, &venues); err != nil {
+		return nil, fmt.Errorf("failed to decode venues: %w", err)
+	}
+	return newVenueResolver(venues), nil
+}
+
+// venueName is a venue's ID and name
+type venueName struct {
+	ID   primitive.ObjectID `bson:"_id"`
+	Name string             `bson:"name"`
+}
+
+func newVenueResolver(venues []venueName) *venueResolver {
+	r := &venueResolver{
+		ids:    make(map[string]bool, len(venues)),
+		byName: make(map[string]string, len(venues)),
+		folded: make(map[string]string, len(venues)),
+	}
+	for _, venue := range venues {
+		id := venue.ID.Hex()
+		r.ids[id] = true
+		addVenueName(r.byName, venue.Name, id)
+		addVenueName(r.folded, foldVenueName(venue.Name), id)
+	}
+	return r
+}
+
+// addVenueName maps name to id, or to "" if another venue already has the name
+func addVenueName(names map[string]string, name, id string) {
+	if existing, ok := names[name]; ok && existing != id {
+		names[name] = "" // Ambiguous
+	} else {
+		names[name] = id
+	}
+}
+
+// resolve replaces venue names with IDs, keeping IDs and the list's order and
+// dropping duplicates. It returns how many names were replaced and the entries
+// it couldn't resolve, which stay in the list.
+func (r *venueResolver) resolve(venues []string) (resolved []string, converted int, unresolved []string) {
+	resolved = make([]string, 0, len(venues))
+	seen := make(map[string]bool, len(venues))
+	for _, venue := range venues {
+		value := venue
+		if !r.ids[venue] {
+			// An ambiguous exact name is also ambiguous folded, so it stays unresolved
+			id, exact := r.byName[venue]
+			if !exact {
+				id = r.folded[foldVenueName(venue)]
+			}
+			if id != "" {
+				value = id
+				converted++
+			} else {
+				unresolved = append(unresolved, venue)
+			}
+		}
+
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		resolved = append(resolved, value)
+	}
+	return resolved, converted, unresolved
+}
+
+// foldVenueName normalizes a name for case-insensitive matching
+func foldVenueName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
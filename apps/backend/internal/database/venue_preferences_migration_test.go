@@ -0,0 +1,142 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/models"
+)
+
+func TestVenueResolver_Resolve(t *testing.T) {
+	victoria, stratford := primitive.NewObjectID(), primitive.NewObjectID()
+	twinA, twinB := primitive.NewObjectID(), primitive.NewObjectID()
+	sameNameA, sameNameB := primitive.NewObjectID(), primitive.NewObjectID()
+	resolver := newVenueResolver([]venueName{
+		{ID: victoria, Name: "Victoria Park"},
+		{ID: stratford, Name: "Stratford Park"},
+		{ID: twinA, Name: "Riverside"},
+		{ID: twinB, Name: "riverside"},
+		{ID: sameNameA, Name: "Highbury Fields"},
+		{ID: sameNameB, Name: "Highbury Fields"},
+	})
+
+	tests := []struct {
+		name           string
+		venues         []string
+		wantResolved   []string
+		wantConverted  int
+		wantUnresolved []string
+	}{
+		{
+			name:         "IDs are kept",
+			venues:       []string{victoria.Hex(), stratford.Hex()},
+			wantResolved: []string{victoria.Hex(), stratford.Hex()},
+		},
+		{
+			name:          "names become IDs",
+			venues:        []string{"Victoria Park", stratford.Hex()},
+			wantResolved:  []string{victoria.Hex(), stratford.Hex()},
+			wantConverted: 1,
+		},
+		{
+			name:          "names match ignoring case and spaces",
+			venues:        []string{" victoria park "},
+			wantResolved:  []string{victoria.Hex()},
+			wantConverted: 1,
+		},
+		{
+			name:          "exact names win over ambiguous folded names",
+			venues:        []string{"riverside"},
+			wantResolved:  []string{twinB.Hex()},
+			wantConverted: 1,
+		},
+		{
+			name:           "ambiguous folded names are unresolved",
+			venues:         []string{"RIVERSIDE"},
+			wantResolved:   []string{"RIVERSIDE"},
+			wantUnresolved: []string{"RIVERSIDE"},
+		},
+		{
+			name:           "names shared by several venues are unresolved",
+			venues:         []string{"Highbury Fields", "highbury fields"},
+			wantResolved:   []string{"Highbury Fields", "highbury fields"},
+			wantUnresolved: []string{"Highbury Fields", "highbury fields"},
+		},
+		{
+			name:           "unknown names and IDs are unresolved",
+			venues:         []string{"Closed Club", "665f1c2a9b1e8a0000000000"},
+			wantResolved:   []string{"Closed Club", "665f1c2a9b1e8a0000000000"},
+			wantUnresolved: []string{"Closed Club", "665f1c2a9b1e8a0000000000"},
+		},
+		{
+			name:          "a name and its ID collapse into one entry",
+			venues:        []string{victoria.Hex(), "Victoria Park"},
+			wantResolved:  []string{victoria.Hex()},
+			wantConverted: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, converted, unresolved := resolver.resolve(tt.venues)
+			assert.Equal(t, tt.wantResolved, resolved)
+			assert.Equal(t, tt.wantConverted, converted)
+			assert.Equal(t, tt.wantUnresolved, unresolved)
+		})
+	}
+}
+
+func TestMigrateVenuePreferences(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	victoria, stratford := primitive.NewObjectID(), primitive.NewObjectID()
+	_, err := db.Collection("venues").InsertMany(ctx, []interface{}{
+		models.Venue{ID: victoria, Name: "Victoria Park"},
+		models.Venue{ID: stratford, Name: "Stratford Park"},
+	})
+	require.NoError(t, err)
+
+	byName, byID, unknown := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	_, err = db.Collection("user_preferences").InsertMany(ctx, []interface{}{
+		models.UserPreferences{ID: primitive.NewObjectID(), UserID: byName, PreferredVenues: []string{"Victoria Park"}, ExcludedVenues: []string{"stratford park"}},
+		models.UserPreferences{ID: primitive.NewObjectID(), UserID: byID, PreferredVenues: []string{victoria.Hex()}},
+		models.UserPreferences{ID: primitive.NewObjectID(), UserID: unknown, PreferredVenues: []string{"Closed Club"}},
+	})
+	require.NoError(t, err)
+
+	venuesOf := func(userID primitive.ObjectID) models.UserPreferences {
+		var pref models.UserPreferences
+		require.NoError(t, db.Collection("user_preferences").FindOne(ctx, bson.M{"user_id": userID}).Decode(&pref))
+		return pref
+	}
+
+	// A dry run reports without writing
+	migration, err := MigrateVenuePreferences(ctx, db, true)
+	require.NoError(t, err)
+	assert.Equal(t, 3, migration.Scanned)
+	assert.Equal(t, 1, migration.Updated)
+	assert.Equal(t, 2, migration.Converted)
+	assert.Equal(t, []UnresolvedVenue{{UserID: unknown, Field: "preferred_venues", Value: "Closed Club"}}, migration.Unresolved)
+	assert.Equal(t, []string{"Victoria Park"}, venuesOf(byName).PreferredVenues)
+
+	migration, err = MigrateVenuePreferences(ctx, db, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, migration.Updated)
+	assert.Equal(t, []string{victoria.Hex()}, venuesOf(byName).PreferredVenues)
+	assert.Equal(t, []string{stratford.Hex()}, venuesOf(byName).ExcludedVenues)
+	assert.Equal(t, []string{victoria.Hex()}, venuesOf(byID).PreferredVenues)
+	assert.Equal(t, []string{"Closed Club"}, venuesOf(unknown).PreferredVenues)
+
+	// Running it again changes nothing
+	migration, err = MigrateVenuePreferences(ctx, db, false)
+	require.NoError(t, err)
+	assert.Zero(t, migration.Updated)
+	assert.Zero(t, migration.Converted)
+}
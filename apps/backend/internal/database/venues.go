@@ -13,6 +13,15 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var (
+	// ErrVenueNotFound is returned when no venue has the requested ID or name
+	ErrVenueNotFound = errors.New("venue not found")
+
+	// ErrDuplicateVenueName is returned by Create and Update when another venue
+	// already has the name; names are unique
+	ErrDuplicateVenueName = errors.New("a venue with that name already exists")
+)
+
 // VenueRepository handles database operations for venues
 type VenueRepository struct {
 	collection *mongo.Collection
@@ -35,9 +44,13 @@ func (r *VenueRepository) Create(ctx context.Context, venue *models.Venue) error
 	now := time.Now()
 	venue.CreatedAt = now
 	venue.UpdatedAt = now
+	venue.Location.SyncPoint()
 
 	// Insert the venue
 	result, err := r.collection.InsertOne(ctx, venue)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicateVenueName
+	}
 	if err != nil {
 		return err
 	}
@@ -56,7 +69,7 @@ func (r *VenueRepository) FindByID(ctx context.Context, id primitive.ObjectID) (
 	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&venue)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("venue not found")
+			return nil, ErrVenueNotFound
 		}
 		return nil, err
 	}
@@ -69,7 +82,7 @@ func (r *VenueRepository) FindByName(ctx context.Context, name string) (*models.
 	err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&venue)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return nil, errors.New("venue not found")
+			return nil, ErrVenueNotFound
 		}
 		return nil, err
 	}
@@ -100,12 +113,37 @@ func (r *VenueRepository) Update(ctx context.Context, venue *models.Venue) error
 
 	// Update timestamp
 	venue.UpdatedAt = time.Now()
+	venue.Location.SyncPoint()
 
 	// Update the venue
 	filter := bson.M{"_id": venue.ID}
 	update := bson.M{"$set": venue}
-	_, err := r.collection.UpdateOne(ctx, filter, update)
-	return err
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrDuplicateVenueName
+	}
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrVenueNotFound
+	}
+	return nil
+}
+
+// SetActive activates or deactivates a venue. Inactive venues aren't scraped
+// or offered for alerts, but their history is kept.
+func (r *VenueRepository) SetActive(ctx context.Context, id primitive.ObjectID, active bool) error {
+	filter := bson.M{"_id": id}
+	update := bson.M{"$set": bson.M{"is_active": active, "updated_at": time.Now()}}
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrVenueNotFound
+	}
+	return nil
 }
 
 // UpdateLastScraped updates the last_scraped_at field for a venue
@@ -147,6 +185,40 @@ func (r *VenueRepository) List(ctx context.Context, skip, limit int64) ([]*model
 	return venues, nil
 }
 
+// FindMissingIDs returns the given IDs that don't belong to any venue, in their original order
+func (r *VenueRepository) FindMissingIDs(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var found []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	existing := make(map[primitive.ObjectID]bool, len(found))
+	for _, venue := range found {
+		existing[venue.ID] = true
+	}
+
+	var missing []primitive.ObjectID
+	for _, id := range ids {
+		if !existing[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
 // ListActive retrieves all active venues
 func (r *VenueRepository) ListActive(ctx context.Context) ([]*models.Venue, error) {
 	cursor, err := r.collection.Find(ctx, bson.M{"is_active": true})
@@ -181,11 +253,54 @@ func (r *VenueRepository) CreateIndexes(ctx context.Context) error {
 		Keys: bson.D{{Key: "is_active", Value: 1}},
 	}
 
+	// Create a geospatial index for nearby venue searches
+	pointIndex := mongo.IndexModel{
+		Keys: bson.D{{Key: "location.point", Value: "2dsphere"}},
+	}
+
 	// Create indexes
 	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
 		nameIndex,
 		providerIndex,
 		activeIndex,
+		pointIndex,
 	})
 	return err
 }
+
+// VenueDistance is a venue found by FindNear with its distance from the search point
+type VenueDistance struct {
+	models.Venue   `bson:",inline"`
+	DistanceMeters float64 `bson:"distance_meters"`
+}
+
+// FindNear retrieves active venues within radiusMeters of a point, nearest first.
+// It needs the 2dsphere index from CreateIndexes.
+func (r *VenueRepository) FindNear(ctx context.Context, lat, lng, radiusMeters float64, limit int64) ([]*VenueDistance, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.M{
+			"near":          models.NewGeoPoint(lat, lng),
+			"distanceField": "distance_meters",
+			"maxDistance":   radiusMeters,
+			"spherical":     true,
+			"key":           "location.point",
+			"query":         bson.M{"is_active": true},
+		}}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	venues := []*VenueDistance{}
+	if err := cursor.All(ctx, &venues); err != nil {
+		return nil, err
+	}
+
+	return venues, nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -129,6 +130,33 @@ func TestVenueRepository_FindByID(t *testing.T) {
 	}
 }
 
+func TestVenueRepository_FindMissingIDs(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewVenueRepository(db)
+	ctx := context.Background()
+
+	venue := &models.Venue{
+		Name:          "Test Tennis Club",
+		Provider:      "lta",
+		BookingWindow: 7,
+		IsActive:      true,
+	}
+	if err := repo.Create(ctx, venue); err != nil {
+		t.Fatalf("Failed to create venue: %v", err)
+	}
+
+	unknown := primitive.NewObjectID()
+	missing, err := repo.FindMissingIDs(ctx, []primitive.ObjectID{venue.ID, unknown})
+	if err != nil {
+		t.Fatalf("Failed to check venue IDs: %v", err)
+	}
+	if len(missing) != 1 || missing[0] != unknown {
+		t.Errorf("Expected only %s to be missing, got %v", unknown.Hex(), missing)
+	}
+}
+
 func TestVenueRepository_FindByName(t *testing.T) {
 	_, db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -292,6 +320,41 @@ func TestVenueRepository_Update(t *testing.T) {
 	}
 }
 
+func TestVenueRepository_SetActive(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewVenueRepository(db)
+	ctx := context.Background()
+
+	venue := &models.Venue{Name: "Deactivated Tennis Club", BookingWindow: 7, IsActive: true}
+	if err := repo.Create(ctx, venue); err != nil {
+		t.Fatalf("Failed to create venue: %v", err)
+	}
+
+	if err := repo.SetActive(ctx, venue.ID, false); err != nil {
+		t.Fatalf("Failed to deactivate venue: %v", err)
+	}
+
+	foundVenue, err := repo.FindByID(ctx, venue.ID)
+	if err != nil {
+		t.Fatalf("Failed to find venue by ID: %v", err)
+	}
+	if foundVenue.IsActive {
+		t.Errorf("Expected venue to be inactive")
+	}
+	if !foundVenue.UpdatedAt.After(venue.UpdatedAt) {
+		t.Errorf("Expected updated time to be after creation")
+	}
+
+	if err := repo.SetActive(ctx, primitive.NewObjectID(), false); !errors.Is(err, ErrVenueNotFound) {
+		t.Errorf("Expected ErrVenueNotFound for a missing venue, got %v", err)
+	}
+	if err := repo.Update(ctx, &models.Venue{ID: primitive.NewObjectID(), Name: "Missing"}); !errors.Is(err, ErrVenueNotFound) {
+		t.Errorf("Expected ErrVenueNotFound when updating a missing venue, got %v", err)
+	}
+}
+
 func TestVenueRepository_UpdateLastScraped(t *testing.T) {
 	_, db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -495,3 +558,58 @@ func TestVenueRepository_CreateIndexes(t *testing.T) {
 		t.Errorf("Expected error when creating venue with duplicate name, got nil")
 	}
 }
+
+func TestVenueRepository_FindNear(t *testing.T) {
+	_, db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	repo := NewVenueRepository(db)
+	ctx := context.Background()
+
+	if err := repo.CreateIndexes(ctx); err != nil {
+		t.Fatalf("Failed to create indexes: %v", err)
+	}
+
+	venues := []*models.Venue{
+		{
+			Name:     "Victoria Park",
+			Location: models.Location{Latitude: 51.5362, Longitude: -0.0403},
+			IsActive: true,
+		},
+		{
+			Name:     "Bethnal Green Gardens",
+			Location: models.Location{Latitude: 51.5272, Longitude: -0.0557},
+			IsActive: true,
+		},
+		{
+			Name:     "Wimbledon Park",
+			Location: models.Location{Latitude: 51.4340, Longitude: -0.2050},
+			IsActive: true,
+		},
+		{
+			Name:     "No Coordinates Club",
+			IsActive: true,
+		},
+	}
+	for _, venue := range venues {
+		if err := repo.Create(ctx, venue); err != nil {
+			t.Fatalf("Failed to create venue: %v", err)
+		}
+	}
+
+	// Search from Mile End, roughly 1.3km from Victoria Park and 1.6km from Bethnal Green
+	nearby, err := repo.FindNear(ctx, 51.5250, -0.0330, 5000, 10)
+	if err != nil {
+		t.Fatalf("Failed to find nearby venues: %v", err)
+	}
+
+	if len(nearby) != 2 {
+		t.Fatalf("Expected 2 venues within 5km, got %d", len(nearby))
+	}
+	if nearby[0].Name != "Victoria Park" || nearby[1].Name != "Bethnal Green Gardens" {
+		t.Errorf("Expected nearest first, got %s then %s", nearby[0].Name, nearby[1].Name)
+	}
+	if nearby[0].DistanceMeters <= 0 || nearby[0].DistanceMeters > nearby[1].DistanceMeters {
+		t.Errorf("Unexpected distances %.0fm and %.0fm", nearby[0].DistanceMeters, nearby[1].DistanceMeters)
+	}
+}
@@ -0,0 +1,91 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	"tennis-booker/internal/config"
+)
+
+// SMTPSender sends plain-text emails through the SMTP server in the app config
+type SMTPSender struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+// NewSMTPSender creates a sender from the email config. The from address
+// defaults to the SMTP username when FROM_EMAIL is not set.
+func NewSMTPSender(cfg config.EmailConfig) *SMTPSender {
+	from := cfg.FromEmail
+	if from == "" {
+		from = cfg.SMTPUsername
+	}
+
+	return &SMTPSender{
+		host:     cfg.SMTPHost,
+		port:     cfg.SMTPPort,
+		username: cfg.SMTPUsername,
+		password: cfg.SMTPPassword,
+		from:     from,
+	}
+}
+
+// Configured reports whether enough settings are present to send email
+func (s *SMTPSender) Configured() bool {
+	return s.host != "" && s.port != "" && s.username != "" && s.password != ""
+}
+
+// Send sends a plain-text email
+func (s *SMTPSender) Send(to, subject, body string) error {
+	auth := smtp.PlainAuth("", s.username, s.password, s.host)
+	addr := s.host + ":" + s.port
+
+	if err := smtp.SendMail(addr, auth, s.from, []string{to}, buildMessage(s.from, to, subject, body)); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", to, err)
+	}
+	return nil
+}
+
+// Check connects to the SMTP server and issues a NOOP, verifying it's up and
+// speaking SMTP without authenticating or sending anything
+func (s *SMTPSender) Check(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(s.host, s.port))
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		return fmt.Errorf("SMTP server did not greet: %w", err)
+	}
+	defer client.Close()
+
+	if err := client.Noop(); err != nil {
+		return fmt.Errorf("SMTP NOOP failed: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMessage formats a plain-text RFC 5322 message
+func buildMessage(from, to, subject, body string) []byte {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+	return []byte(msg.String())
+}
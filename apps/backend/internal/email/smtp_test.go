@@ -0,0 +1,101 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"tennis-booker/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSMTPSender(t *testing.T) {
+	sender := NewSMTPSender(config.EmailConfig{
+		SMTPHost:     "smtp.example.com",
+		SMTPPort:     "587",
+		SMTPUsername: "alerts@example.com",
+		SMTPPassword: "secret",
+	})
+
+	assert.True(t, sender.Configured())
+	assert.Equal(t, "alerts@example.com", sender.from, "from defaults to the SMTP username")
+
+	assert.False(t, NewSMTPSender(config.EmailConfig{SMTPHost: "smtp.example.com", SMTPPort: "587"}).Configured())
+}
+
+func TestBuildMessage(t *testing.T) {
+	msg := string(buildMessage("from@example.com", "to@example.com", "Reset your password", "Hello"))
+
+	headers, body, found := strings.Cut(msg, "\r\n\r\n")
+	assert.True(t, found)
+	assert.Contains(t, headers, "To: to@example.com")
+	assert.Contains(t, headers, "Subject: Reset your password")
+	assert.Contains(t, headers, "Content-Type: text/plain")
+	assert.Equal(t, "Hello", body)
+}
+
+// fakeSMTPServer answers one connection with a minimal SMTP dialogue and
+// reports the commands it received
+func fakeSMTPServer(t *testing.T) (config.EmailConfig, <-chan []string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	commands := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var received []string
+		defer func() { commands <- received }()
+
+		conn.Write([]byte("220 fake.example.com ESMTP\r\n"))
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(strings.Fields(line)[0])
+			received = append(received, command)
+			switch command {
+			case "QUIT":
+				conn.Write([]byte("221 bye\r\n"))
+				return
+			default:
+				conn.Write([]byte("250 ok\r\n"))
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return config.EmailConfig{SMTPHost: host, SMTPPort: port}, commands
+}
+
+func TestSMTPSender_Check(t *testing.T) {
+	cfg, commands := fakeSMTPServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	require.NoError(t, NewSMTPSender(cfg).Check(ctx))
+	assert.Equal(t, []string{"EHLO", "NOOP", "QUIT"}, <-commands, "nothing is authenticated or sent")
+}
+
+func TestSMTPSender_CheckUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.Error(t, NewSMTPSender(config.EmailConfig{SMTPHost: host, SMTPPort: port}).Check(ctx))
+}
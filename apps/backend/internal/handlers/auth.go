@@ -1,12 +1,18 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"tennis-booker/internal/auth"
 	"tennis-booker/internal/database"
+	"tennis-booker/internal/logging"
 	"tennis-booker/internal/models"
 	"tennis-booker/internal/utils"
 
@@ -16,18 +22,38 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// authLogger records authentication and account security events
+var authLogger = logging.New("tennis-server")
+
 // AuthHandler handles authentication requests
 type AuthHandler struct {
 	jwtService *auth.JWTService
 	db         database.Database
+	passwords  *auth.BcryptPasswordService // Hashes new passwords at the configured cost
+
+	// Password reset (optional; forgot-password is unavailable without a mailer)
+	passwordResets *models.PasswordResetService
+	resetMailer    Mailer
+	resetURLBase   string
+
+	// Locks accounts after repeated failed logins (optional)
+	accountLockout auth.AccountLockout
+
+	// Encrypts TOTP secrets (optional; the 2FA endpoints are unavailable without it)
+	totpCipher *auth.TOTPSecretCipher
 }
 
 // NewAuthHandler creates a new auth handler
 func NewAuthHandler(jwtService *auth.JWTService, db database.Database) *AuthHandler {
-	return &AuthHandler{
+	h := &AuthHandler{
 		jwtService: jwtService,
 		db:         db,
+		passwords:  auth.NewBcryptPasswordServiceFromEnv(),
+	}
+	if mongoDB := db.GetMongoDB(); mongoDB != nil {
+		h.passwordResets = models.NewPasswordResetService(mongoDB)
 	}
+	return h
 }
 
 // LoginRequest represents a login request
@@ -48,6 +74,7 @@ type RegisterRequest struct {
 type AuthResponse struct {
 	AccessToken  string      `json:"accessToken"`
 	RefreshToken string      `json:"refreshToken"`
+	ExpiresIn    int64       `json:"expiresIn"` // Seconds until the access token expires
 	User         models.User `json:"user"`
 }
 
@@ -56,6 +83,17 @@ type RefreshRequest struct {
 	RefreshToken string `json:"refreshToken" validate:"required"`
 }
 
+// LogoutRequest represents a logout request. The refresh token is optional and is
+// revoked along with the access token from the Authorization header.
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// SetAccountLockout enables locking accounts after repeated failed logins
+func (h *AuthHandler) SetAccountLockout(lockout auth.AccountLockout) {
+	h.accountLockout = lockout
+}
+
 // Login handles user login
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
@@ -65,15 +103,22 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find user by email
-	ctx, cancel := utils.WithDBTimeout()
+	ctx, cancel := utils.WithDBTimeoutFrom(r.Context())
 	defer cancel()
 
+	// Locked accounts are refused before the password is checked
+	if lockedFor := h.accountLockedFor(ctx, req.Email); lockedFor > 0 {
+		writeAccountLocked(w, lockedFor)
+		return
+	}
+
 	collection := h.db.Collection("users")
 	var user models.User
 	err := collection.FindOne(ctx, bson.M{"email": req.Email}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			utils.WriteError(w, "Invalid credentials", http.StatusUnauthorized)
+			// Unknown emails count towards a lockout too, so responses don't reveal which accounts exist
+			h.loginFailed(ctx, w, req.Email)
 			return
 		}
 		utils.WriteError(w, "Internal server error", http.StatusInternalServerError)
@@ -82,18 +127,79 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 
 	// Verify password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.HashedPassword), []byte(req.Password)); err != nil {
-		utils.WriteError(w, "Invalid credentials", http.StatusUnauthorized)
+		h.loginFailed(ctx, w, req.Email)
+		return
+	}
+
+	// Hashes made at a lower cost than configured are upgraded while the password is at hand
+	h.upgradePasswordHash(ctx, user, req.Password)
+
+	// With 2FA on, the password alone only earns a challenge token to exchange for a
+	// code. Failed logins aren't reset yet, so code guesses still count towards a lockout.
+	if user.TwoFactorEnabled {
+		challengeToken, err := h.jwtService.GenerateTwoFactorChallenge(user.ID.Hex(), user.Email)
+		if err != nil {
+			utils.WriteError(w, "Failed to generate two-factor challenge", http.StatusInternalServerError)
+			return
+		}
+		utils.WriteSuccess(w, TwoFactorChallengeResponse{
+			Status:         TwoFactorRequiredStatus,
+			ChallengeToken: challengeToken,
+		})
 		return
 	}
 
+	h.resetLoginFailures(ctx, req.Email)
+	h.completeLogin(ctx, w, user)
+}
+
+// upgradePasswordHash rehashes a just-verified password at the configured cost if
+// the stored hash used a lower one. It is best effort: on failure the old hash
+// stays in place and the login goes ahead.
+func (h *AuthHandler) upgradePasswordHash(ctx context.Context, user models.User, password string) {
+	if !h.passwords.NeedsRehash(user.HashedPassword) {
+		return
+	}
+
+	hashedPassword, err := h.passwords.HashPassword(ctx, password)
+	if err != nil {
+		authLogger.WithContext(ctx).Error("Failed to rehash password", map[string]interface{}{"user_id": user.ID.Hex(), "error": err.Error()})
+		return
+	}
+
+	// Matching the old hash leaves a password changed in the meantime alone. This is
+	// not a password change, so password_changed_at and existing tokens are untouched.
+	_, err = h.db.Collection("users").UpdateOne(ctx,
+		bson.M{"_id": user.ID, "hashed_password": user.HashedPassword},
+		bson.M{"$set": bson.M{"hashed_password": hashedPassword}},
+	)
+	if err != nil {
+		authLogger.WithContext(ctx).Error("Failed to store rehashed password", map[string]interface{}{"user_id": user.ID.Hex(), "error": err.Error()})
+	}
+}
+
+// expiresIn returns the seconds until a just-issued access token expires, from
+// the token itself so it always matches the lifetime it was minted with
+func (h *AuthHandler) expiresIn(accessToken string) int64 {
+	seconds, err := h.jwtService.ExpiresIn(accessToken)
+	if err != nil {
+		return int64(auth.AccessTokenTTL.Seconds())
+	}
+	return seconds
+}
+
+// completeLogin issues access and refresh tokens for an authenticated user and records the login
+func (h *AuthHandler) completeLogin(ctx context.Context, w http.ResponseWriter, user models.User) {
+	collection := h.db.Collection("users")
+
 	// Generate tokens
-	accessToken, err := h.jwtService.GenerateToken(user.ID.Hex(), user.Email, 24*time.Hour)
+	accessToken, err := h.jwtService.GenerateTokenWithRole(user.ID.Hex(), user.Email, user.Role, auth.AccessTokenTTL)
 	if err != nil {
 		http.Error(w, "Failed to generate access token", http.StatusInternalServerError)
 		return
 	}
 
-	refreshToken, err := h.jwtService.GenerateToken(user.ID.Hex(), user.Email, 7*24*time.Hour)
+	refreshToken, err := h.jwtService.GenerateTokenWithRole(user.ID.Hex(), user.Email, user.Role, auth.RefreshTokenTTL)
 	if err != nil {
 		http.Error(w, "Failed to generate refresh token", http.StatusInternalServerError)
 		return
@@ -118,6 +224,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	response := AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
+		ExpiresIn:    h.expiresIn(accessToken),
 		User:         user,
 	}
 
@@ -149,7 +256,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := h.passwords.HashPassword(ctx, req.Password)
 	if err != nil {
 		http.Error(w, "Failed to hash password", http.StatusInternalServerError)
 		return
@@ -160,7 +267,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		ID:             primitive.NewObjectID(),
 		Email:          req.Email,
 		Username:       req.Email, // Use email as username for now
-		HashedPassword: string(hashedPassword),
+		HashedPassword: hashedPassword,
 		Name:           req.FirstName + " " + req.LastName,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
@@ -174,13 +281,13 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate tokens
-	accessToken, err := h.jwtService.GenerateToken(user.ID.Hex(), user.Email, 24*time.Hour)
+	accessToken, err := h.jwtService.GenerateTokenWithRole(user.ID.Hex(), user.Email, user.Role, auth.AccessTokenTTL)
 	if err != nil {
 		http.Error(w, "Failed to generate access token", http.StatusInternalServerError)
 		return
 	}
 
-	refreshToken, err := h.jwtService.GenerateToken(user.ID.Hex(), user.Email, 7*24*time.Hour)
+	refreshToken, err := h.jwtService.GenerateTokenWithRole(user.ID.Hex(), user.Email, user.Role, auth.RefreshTokenTTL)
 	if err != nil {
 		http.Error(w, "Failed to generate refresh token", http.StatusInternalServerError)
 		return
@@ -192,6 +299,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	response := AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
+		ExpiresIn:    h.expiresIn(accessToken),
 		User:         user,
 	}
 
@@ -235,15 +343,21 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Refresh tokens issued before a password change are no longer valid
+	if issuedBeforePasswordChange(claims, user) {
+		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+		return
+	}
+
 	// Generate new access token
-	accessToken, err := h.jwtService.GenerateToken(user.ID.Hex(), user.Email, 24*time.Hour)
+	accessToken, err := h.jwtService.GenerateTokenWithRole(user.ID.Hex(), user.Email, user.Role, auth.AccessTokenTTL)
 	if err != nil {
 		http.Error(w, "Failed to generate access token", http.StatusInternalServerError)
 		return
 	}
 
 	// Generate new refresh token
-	newRefreshToken, err := h.jwtService.GenerateToken(user.ID.Hex(), user.Email, 7*24*time.Hour)
+	newRefreshToken, err := h.jwtService.GenerateTokenWithRole(user.ID.Hex(), user.Email, user.Role, auth.RefreshTokenTTL)
 	if err != nil {
 		http.Error(w, "Failed to generate refresh token", http.StatusInternalServerError)
 		return
@@ -255,13 +369,22 @@ func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	response := AuthResponse{
 		AccessToken:  accessToken,
 		RefreshToken: newRefreshToken,
+		ExpiresIn:    h.expiresIn(accessToken),
 		User:         user,
 	}
 
 	utils.WriteSuccess(w, response)
 }
 
-// GetCurrentUser returns the current authenticated user
+// CurrentUserResponse is the response for GET /api/auth/me: the user's profile
+// with their alert preferences alongside
+type CurrentUserResponse struct {
+	models.User
+	Preferences UserPreferencesResponse `json:"preferences"`
+}
+
+// GetCurrentUser returns the current authenticated user's profile and
+// preferences. Users who haven't saved preferences yet get the defaults.
 func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context using utility function
 	userID, ok := utils.RequireAuth(w, r)
@@ -287,17 +410,107 @@ func (h *AuthHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	// Don't return password
 	user.HashedPassword = ""
 
+	var preferences models.UserPreferences
+	err = h.db.Collection("user_preferences").FindOne(ctx, bson.M{"user_id": userID}).Decode(&preferences)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			utils.WriteError(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		// Not stored until the user saves their own
+		preferences = defaultUserPreferences(userID)
+	}
+
+	response := CurrentUserResponse{
+		User:        user,
+		Preferences: toUserPreferencesResponse(preferences),
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	json.NewEncoder(w).Encode(response)
 }
 
-// Logout handles user logout
+// Logout handles user logout by revoking the access token from the Authorization
+// header and the refresh token from the body, if given. Invalid or expired tokens
+// are ignored so logout always succeeds unless revocation itself fails.
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	// For JWT, logout is typically handled client-side by discarding the token
-	// For more secure implementations, you'd maintain a blacklist of tokens
+	// The body is optional; without one only the access token is revoked
+	var req LogoutRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+
+	for _, token := range []string{bearerToken(r), req.RefreshToken} {
+		if token == "" {
+			continue
+		}
+		if err := h.jwtService.RevokeToken(token); errors.Is(err, auth.ErrRevocationStore) {
+			utils.WriteError(w, "Failed to log out", http.StatusInternalServerError)
+			return
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(map[string]string{
 		"message": "Logged out successfully",
 	})
 }
+
+// bearerToken returns the token from a "Bearer <token>" Authorization header, or ""
+func bearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// accountLockedFor returns how long the account is locked for, or 0. Lockout
+// store errors don't block logins.
+func (h *AuthHandler) accountLockedFor(ctx context.Context, email string) time.Duration {
+	if h.accountLockout == nil {
+		return 0
+	}
+
+	lockedFor, err := h.accountLockout.LockedFor(ctx, email)
+	if err != nil {
+		authLogger.WithContext(ctx).Error("Account lockout: failed to check lock", map[string]interface{}{"email": email, "error": err.Error()})
+		return 0
+	}
+	return lockedFor
+}
+
+// resetLoginFailures clears the failed login count after a successful login
+func (h *AuthHandler) resetLoginFailures(ctx context.Context, email string) {
+	if h.accountLockout == nil {
+		return
+	}
+	if err := h.accountLockout.Reset(ctx, email); err != nil {
+		authLogger.WithContext(ctx).Error("Account lockout: failed to reset failed login count", map[string]interface{}{"email": email, "error": err.Error()})
+	}
+}
+
+// loginFailed records a failed login and responds with "Invalid credentials", or
+// with the lockout response if this failure locked the account
+func (h *AuthHandler) loginFailed(ctx context.Context, w http.ResponseWriter, email string) {
+	if h.accountLockout != nil {
+		lockedFor, err := h.accountLockout.RecordFailure(ctx, email)
+		if err != nil {
+			authLogger.WithContext(ctx).Error("Account lockout: failed to record failed login", map[string]interface{}{"email": email, "error": err.Error()})
+		}
+		if lockedFor > 0 {
+			authLogger.WithContext(ctx).Warn("Account locked after repeated failed logins", map[string]interface{}{"email": email, "locked_for": lockedFor.String(), "event": "security"})
+			writeAccountLocked(w, lockedFor)
+			return
+		}
+	}
+
+	utils.WriteError(w, "Invalid credentials", http.StatusUnauthorized)
+}
+
+// writeAccountLocked responds to a login for a locked account. The response is the
+// same whether or not the account exists.
+func writeAccountLocked(w http.ResponseWriter, lockedFor time.Duration) {
+	retryAfter := int(math.Ceil(lockedFor.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	utils.WriteError(w, "Too many failed login attempts. Please try again later.", http.StatusTooManyRequests)
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tennis-booker/internal/database"
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/utils"
+)
+
+// BookingRepositoryInterface defines the interface for booking repository operations
+type BookingRepositoryInterface interface {
+	Create(ctx context.Context, booking *models.Booking) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Booking, error)
+	FindByUserIDPage(ctx context.Context, userID primitive.ObjectID, skip, limit int64) ([]*models.Booking, int64, error)
+	TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to models.BookingStatus) error
+}
+
+// BookingHandler handles booking requests
+type BookingHandler struct {
+	bookings BookingRepositoryInterface
+}
+
+// NewBookingHandler creates a new booking handler
+func NewBookingHandler(db database.Database) *BookingHandler {
+	return &BookingHandler{
+		bookings: database.NewBookingRepository(db.GetMongoDB()),
+	}
+}
+
+// bookingPageBounds paginates GET /api/bookings
+var bookingPageBounds = PageBounds{DefaultLimit: 20, MaxLimit: 100, MaxOffset: 10000}
+
+// CreateBookingRequest is the body of POST /api/bookings
+type CreateBookingRequest struct {
+	VenueID   string  `json:"venueId"`
+	VenueName string  `json:"venueName"`
+	CourtID   string  `json:"courtId"`
+	CourtName string  `json:"courtName"`
+	Date      string  `json:"date"`      // YYYY-MM-DD
+	StartTime string  `json:"startTime"` // HH:MM
+	EndTime   string  `json:"endTime"`   // HH:MM
+	Price     float64 `json:"price"`
+	Currency  string  `json:"currency"`
+	Notes     string  `json:"notes"`
+}
+
+// UpdateBookingStatusRequest is the body of PATCH /api/bookings/{id}/status
+type UpdateBookingStatusRequest struct {
+	Status models.BookingStatus `json:"status"`
+}
+
+// BookingsResponse is the paginated response for GET /api/bookings
+type BookingsResponse struct {
+	Bookings []*models.Booking `json:"bookings"`
+	PageInfo
+}
+
+// toBooking validates the request and returns the pending booking it describes
+func (req CreateBookingRequest) toBooking(userID primitive.ObjectID) (*models.Booking, error) {
+	venueID, err := primitive.ObjectIDFromHex(req.VenueID)
+	if err != nil {
+		return nil, errors.New("venueId must be a valid venue ID")
+	}
+	if req.CourtID == "" {
+		return nil, errors.New("courtId is required")
+	}
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		return nil, errors.New("date must be in YYYY-MM-DD format")
+	}
+	start, err := time.Parse("15:04", req.StartTime)
+	if err != nil {
+		return nil, errors.New("startTime must be in HH:MM format")
+	}
+	end, err := time.Parse("15:04", req.EndTime)
+	if err != nil {
+		return nil, errors.New("endTime must be in HH:MM format")
+	}
+	if !end.After(start) {
+		return nil, errors.New("endTime must be after startTime")
+	}
+	if req.Price < 0 {
+		return nil, errors.New("price must not be negative")
+	}
+
+	return &models.Booking{
+		UserID:    userID,
+		VenueID:   venueID,
+		VenueName: req.VenueName,
+		CourtID:   req.CourtID,
+		CourtName: req.CourtName,
+		Date:      req.Date,
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Price:     req.Price,
+		Currency:  req.Currency,
+		Notes:     req.Notes,
+		Status:    models.BookingStatusPending,
+	}, nil
+}
+
+// CreateBooking handles POST /api/bookings, recording a pending booking for the current user
+func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return // RequireAuth already wrote the error response
+	}
+
+	var req CreateBookingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	booking, err := req.toBooking(userID)
+	if err != nil {
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = h.bookings.Create(ctx, booking)
+	if errors.Is(err, database.ErrSlotAlreadyBooked) {
+		utils.WriteError(w, "This slot is already booked", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, "Failed to create booking", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteCreated(w, booking)
+}
+
+// GetBookings handles GET /api/bookings?limit=&offset=, listing the current user's bookings
+func (h *BookingHandler) GetBookings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return // RequireAuth already wrote the error response
+	}
+
+	page, err := parsePagination(r.URL.Query(), bookingPageBounds)
+	if err != nil {
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bookings, total, err := h.bookings.FindByUserIDPage(ctx, userID, page.Offset, page.Limit)
+	if err != nil {
+		utils.WriteError(w, "Failed to fetch bookings", http.StatusInternalServerError)
+		return
+	}
+	if bookings == nil {
+		bookings = []*models.Booking{}
+	}
+
+	utils.WriteSuccess(w, BookingsResponse{Bookings: bookings, PageInfo: page.PageInfo(total)})
+}
+
+// UpdateBookingStatus handles PATCH /api/bookings/{id}/status. Only pending
+// bookings can change status, to confirmed, failed or cancelled.
+func (h *BookingHandler) UpdateBookingStatus(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return // RequireAuth already wrote the error response
+	}
+
+	bookingID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, "Invalid booking ID", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateBookingStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if !req.Status.IsValid() {
+		utils.WriteError(w, fmt.Sprintf("Unknown booking status %q", req.Status), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	booking, err := h.bookings.FindByID(ctx, bookingID)
+	if errors.Is(err, database.ErrBookingNotFound) || (err == nil && booking.UserID != userID) {
+		// Other users' bookings are reported as missing rather than forbidden
+		utils.WriteError(w, "Booking not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, "Failed to fetch booking", http.StatusInternalServerError)
+		return
+	}
+
+	if !booking.Status.CanTransitionTo(req.Status) {
+		utils.WriteError(w, fmt.Sprintf("Cannot change a %s booking to %s", booking.Status, req.Status), http.StatusConflict)
+		return
+	}
+
+	err = h.bookings.TransitionStatus(ctx, bookingID, booking.Status, req.Status)
+	if errors.Is(err, database.ErrBookingStatusChanged) {
+		utils.WriteError(w, "Booking status was changed by another request", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, "Failed to update booking status", http.StatusInternalServerError)
+		return
+	}
+
+	updated, err := h.bookings.FindByID(ctx, bookingID)
+	if err != nil {
+		utils.WriteError(w, "Failed to fetch booking", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteSuccess(w, updated)
+}
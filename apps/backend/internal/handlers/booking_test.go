@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/database"
+	"tennis-booker/internal/models"
+)
+
+// MockBookingRepository keeps bookings in memory
+type MockBookingRepository struct {
+	bookings map[primitive.ObjectID]*models.Booking
+	skip     int64
+	limit    int64
+}
+
+func NewMockBookingRepository(bookings ...*models.Booking) *MockBookingRepository {
+	m := &MockBookingRepository{bookings: map[primitive.ObjectID]*models.Booking{}}
+	for _, booking := range bookings {
+		m.bookings[booking.ID] = booking
+	}
+	return m
+}
+
+func (m *MockBookingRepository) Create(ctx context.Context, booking *models.Booking) error {
+	for _, existing := range m.bookings {
+		if existing.VenueID == booking.VenueID && existing.CourtID == booking.CourtID &&
+			existing.Date == booking.Date && existing.StartTime == booking.StartTime &&
+			existing.Status != models.BookingStatusCancelled {
+			return database.ErrSlotAlreadyBooked
+		}
+	}
+	booking.ID = primitive.NewObjectID()
+	m.bookings[booking.ID] = booking
+	return nil
+}
+
+func (m *MockBookingRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Booking, error) {
+	booking, ok := m.bookings[id]
+	if !ok {
+		return nil, database.ErrBookingNotFound
+	}
+	copied := *booking
+	return &copied, nil
+}
+
+func (m *MockBookingRepository) FindByUserIDPage(ctx context.Context, userID primitive.ObjectID, skip, limit int64) ([]*models.Booking, int64, error) {
+	m.skip, m.limit = skip, limit
+	var bookings []*models.Booking
+	for _, booking := range m.bookings {
+		if booking.UserID == userID {
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, int64(len(bookings)), nil
+}
+
+func (m *MockBookingRepository) TransitionStatus(ctx context.Context, id primitive.ObjectID, from, to models.BookingStatus) error {
+	booking, ok := m.bookings[id]
+	if !ok || booking.Status != from {
+		return database.ErrBookingStatusChanged
+	}
+	booking.Status = to
+	return nil
+}
+
+func withUser(req *http.Request, userID primitive.ObjectID) *http.Request {
+	claims := &auth.AppClaims{UserID: userID.Hex(), Username: "testuser"}
+	return req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+}
+
+func TestBookingHandler_CreateBooking(t *testing.T) {
+	repo := NewMockBookingRepository()
+	handler := &BookingHandler{bookings: repo}
+	userID := primitive.NewObjectID()
+	venueID := primitive.NewObjectID()
+
+	body := `{"venueId":"` + venueID.Hex() + `","courtId":"court1","courtName":"Court 1","date":"2024-01-15","startTime":"18:00","endTime":"19:00","price":25}`
+	w := httptest.NewRecorder()
+	handler.CreateBooking(w, withUser(httptest.NewRequest(http.MethodPost, "/api/bookings", strings.NewReader(body)), userID))
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var booking models.Booking
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&booking))
+	assert.Equal(t, userID, booking.UserID)
+	assert.Equal(t, venueID, booking.VenueID)
+	assert.Equal(t, models.BookingStatusPending, booking.Status)
+	assert.Len(t, repo.bookings, 1)
+
+	w = httptest.NewRecorder()
+	handler.CreateBooking(w, withUser(httptest.NewRequest(http.MethodPost, "/api/bookings", strings.NewReader(body)), primitive.NewObjectID()))
+	assert.Equal(t, http.StatusConflict, w.Code, "the slot is already booked")
+}
+
+func TestBookingHandler_CreateBooking_Validation(t *testing.T) {
+	handler := &BookingHandler{bookings: NewMockBookingRepository()}
+	userID := primitive.NewObjectID()
+	venueID := primitive.NewObjectID().Hex()
+
+	for name, body := range map[string]string{
+		"malformed body":   `{`,
+		"bad venue ID":     `{"venueId":"nope","courtId":"1","date":"2024-01-15","startTime":"18:00","endTime":"19:00"}`,
+		"missing court":    `{"venueId":"` + venueID + `","date":"2024-01-15","startTime":"18:00","endTime":"19:00"}`,
+		"bad date":         `{"venueId":"` + venueID + `","courtId":"1","date":"15/01/2024","startTime":"18:00","endTime":"19:00"}`,
+		"bad time":         `{"venueId":"` + venueID + `","courtId":"1","date":"2024-01-15","startTime":"6pm","endTime":"19:00"}`,
+		"end before start": `{"venueId":"` + venueID + `","courtId":"1","date":"2024-01-15","startTime":"19:00","endTime":"18:00"}`,
+		"negative price":   `{"venueId":"` + venueID + `","courtId":"1","date":"2024-01-15","startTime":"18:00","endTime":"19:00","price":-1}`,
+	} {
+		w := httptest.NewRecorder()
+		handler.CreateBooking(w, withUser(httptest.NewRequest(http.MethodPost, "/api/bookings", strings.NewReader(body)), userID))
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+
+	w := httptest.NewRecorder()
+	handler.CreateBooking(w, httptest.NewRequest(http.MethodPost, "/api/bookings", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestBookingHandler_GetBookings(t *testing.T) {
+	userID := primitive.NewObjectID()
+	repo := NewMockBookingRepository(
+		&models.Booking{ID: primitive.NewObjectID(), UserID: userID, Status: models.BookingStatusPending},
+		&models.Booking{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Status: models.BookingStatusPending},
+	)
+	handler := &BookingHandler{bookings: repo}
+
+	w := httptest.NewRecorder()
+	handler.GetBookings(w, withUser(httptest.NewRequest(http.MethodGet, "/api/bookings?limit=500&offset=0", nil), userID))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response BookingsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Len(t, response.Bookings, 1)
+	assert.Equal(t, int64(1), response.Total)
+	assert.Equal(t, bookingPageBounds.MaxLimit, repo.limit, "limit is capped")
+
+	w = httptest.NewRecorder()
+	handler.GetBookings(w, withUser(httptest.NewRequest(http.MethodGet, "/api/bookings?offset=-1", nil), userID))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBookingHandler_UpdateBookingStatus(t *testing.T) {
+	userID := primitive.NewObjectID()
+	pending := &models.Booking{ID: primitive.NewObjectID(), UserID: userID, Status: models.BookingStatusPending}
+	confirmed := &models.Booking{ID: primitive.NewObjectID(), UserID: userID, Status: models.BookingStatusConfirmed}
+	otherUsers := &models.Booking{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), Status: models.BookingStatusPending}
+	handler := &BookingHandler{bookings: NewMockBookingRepository(pending, confirmed, otherUsers)}
+
+	update := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/api/bookings/"+id+"/status", strings.NewReader(body))
+		req = mux.SetURLVars(withUser(req, userID), map[string]string{"id": id})
+		w := httptest.NewRecorder()
+		handler.UpdateBookingStatus(w, req)
+		return w
+	}
+
+	w := update(pending.ID.Hex(), `{"status":"confirmed"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var booking models.Booking
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&booking))
+	assert.Equal(t, models.BookingStatusConfirmed, booking.Status)
+
+	assert.Equal(t, http.StatusConflict, update(confirmed.ID.Hex(), `{"status":"pending"}`).Code)
+	assert.Equal(t, http.StatusConflict, update(confirmed.ID.Hex(), `{"status":"cancelled"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, update(pending.ID.Hex(), `{"status":"booked"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, update("not-an-id", `{"status":"cancelled"}`).Code)
+	assert.Equal(t, http.StatusNotFound, update(primitive.NewObjectID().Hex(), `{"status":"cancelled"}`).Code)
+	assert.Equal(t, http.StatusNotFound, update(otherUsers.ID.Hex(), `{"status":"cancelled"}`).Code)
+}
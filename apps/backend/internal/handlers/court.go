@@ -2,22 +2,38 @@ package handlers
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
+	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
 	"tennis-booker/internal/database"
+	"tennis-booker/internal/logging"
 	"tennis-booker/internal/models"
+	"tennis-booker/internal/utils"
 )
 
+// courtLogger records failures behind the venue and court endpoints
+var courtLogger = logging.New("tennis-server")
+
 // VenueRepositoryInterface defines the interface for venue repository operations
 type VenueRepositoryInterface interface {
 	ListActive(ctx context.Context) ([]*models.Venue, error)
+	FindNear(ctx context.Context, lat, lng, radiusMeters float64, limit int64) ([]*database.VenueDistance, error)
+}
+
+// VenueCacheInterface defines the interface for reading venues through the venue cache
+type VenueCacheInterface interface {
+	GetVenuesCached(ctx context.Context) ([]*models.Venue, error)
 }
 
 // ScrapingLogRepositoryInterface defines the interface for scraping log repository operations
@@ -25,6 +41,12 @@ type ScrapingLogRepositoryInterface interface {
 	GetAvailableCourtSlots(ctx context.Context, limit int64) ([]*models.CourtSlot, error)
 	GetAvailableCourtSlotsByVenue(ctx context.Context, venueID primitive.ObjectID, limit int64) ([]*models.CourtSlot, error)
 	GetAvailableCourtSlotsWithFilters(ctx context.Context, filter models.CourtSlotFilter, limit int64) ([]*models.CourtSlot, error)
+	GetScrapeHealth(ctx context.Context, venueID primitive.ObjectID, since time.Time, topErrors int) (*database.ScrapeHealth, error)
+}
+
+// PriceHistoryRepositoryInterface defines the interface for reading slot price history
+type PriceHistoryRepositoryInterface interface {
+	FindSeries(ctx context.Context, query database.PriceHistoryQuery) ([]*database.PricePoint, error)
 }
 
 // SlotsRepositoryInterface defines the interface for slots repository operations
@@ -36,28 +58,61 @@ type SlotsRepositoryInterface interface {
 	CountSlotsByDate(ctx context.Context, date string) (int64, error)
 	CountSlotsByDateRange(ctx context.Context, startDate, endDate string) (int64, error)
 	GetActivePlatforms(ctx context.Context) ([]string, error)
+	SearchAvailableSlots(ctx context.Context, search database.SlotSearch) ([]*models.CourtSlot, int64, error)
+	SearchAvailableSlotsAfter(ctx context.Context, search database.SlotSearch) ([]*models.CourtSlot, *database.SlotCursor, error)
 }
 
+// DashboardStatsInterface computes the dashboard aggregates
+type DashboardStatsInterface interface {
+	Aggregate(ctx context.Context, now time.Time) (*database.DashboardAggregates, error)
+}
+
+// dashboardStatsCacheKey holds the last dashboard stats response, JSON-encoded
+const dashboardStatsCacheKey = "cache:dashboard:stats"
+
+// dashboardStatsCacheTTL keeps dashboard refreshes from recomputing the aggregates
+const dashboardStatsCacheTTL = 60 * time.Second
+
 // CourtHandler handles court and venue related requests
 type CourtHandler struct {
 	db              database.Database
+	venueRepo       VenueRepositoryInterface
+	venueCache      VenueCacheInterface
 	scrapingLogRepo ScrapingLogRepositoryInterface
 	slotsRepo       SlotsRepositoryInterface
+	priceHistory    PriceHistoryRepositoryInterface
+	dashboardStats  DashboardStatsInterface
+	statsCache      database.CacheStore // Nil disables caching of dashboard stats
 }
 
 // NewCourtHandler creates a new court handler
 func NewCourtHandler(db database.Database) *CourtHandler {
 	// Create repositories
+	venueRepo := database.NewVenueRepository(db.GetMongoDB())
 	scrapingLogRepo := database.NewScrapingLogRepository(db.GetMongoDB())
 	slotsRepo := database.NewSlotsRepository(db.GetMongoDB())
 
 	return &CourtHandler{
 		db:              db,
+		venueRepo:       venueRepo,
+		venueCache:      database.NewVenueCache(venueRepo, nil, 0), // Uncached until SetVenueCache
 		scrapingLogRepo: scrapingLogRepo,
 		slotsRepo:       slotsRepo,
+		priceHistory:    database.NewPriceHistoryRepository(db.GetMongoDB()),
+		dashboardStats:  database.NewDashboardStatsRepository(db.GetMongoDB()),
 	}
 }
 
+// SetVenueCache serves venue listings through a shared (e.g. Redis-backed) venue cache
+func (h *CourtHandler) SetVenueCache(cache VenueCacheInterface) {
+	h.venueCache = cache
+}
+
+// SetStatsCache caches dashboard stats in a shared (e.g. Redis-backed) store
+func (h *CourtHandler) SetStatsCache(store database.CacheStore) {
+	h.statsCache = store
+}
+
 // VenueResponse represents venue data for API responses
 type VenueResponse struct {
 	ID          string `json:"id"`
@@ -75,6 +130,9 @@ type VenueResponse struct {
 		Lng float64 `json:"lng"`
 	} `json:"coordinates"`
 	TotalCourts int `json:"totalCourts"`
+
+	// MatchedField is the field a search matched on: name, postCode or address
+	MatchedField string `json:"matchedField,omitempty"`
 }
 
 // CourtSlotResponse represents court slot data for API responses
@@ -99,15 +157,19 @@ type CourtSlotResponse struct {
 
 // DashboardStatsResponse represents dashboard statistics
 type DashboardStatsResponse struct {
-	TotalVenues     int `json:"totalVenues"`
+	database.DashboardAggregates
 	TotalCourtSlots int `json:"totalCourtSlots"`
 	AvailableSlots  int `json:"availableSlots"`
 	TodaySlots      int `json:"todaySlots"`
 	WeekSlots       int `json:"weekSlots"`
 	ActivePlatforms int `json:"activePlatforms"`
+
+	// When the stats were computed; cached stats can be up to a minute old
+	GeneratedAt time.Time `json:"generatedAt"`
 }
 
-// GetVenues handles the GET /api/venues endpoint
+// GetVenues handles the GET /api/venues endpoint. With q, only venues whose
+// name, postcode or address matches are returned, best matches first.
 func (h *CourtHandler) GetVenues(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -115,74 +177,143 @@ func (h *CourtHandler) GetVenues(w http.ResponseWriter, r *http.Request) {
 	// Get query parameters
 	query := r.URL.Query()
 	platform := query.Get("platform")
-	city := query.Get("city")
-	limitStr := query.Get("limit")
-	offsetStr := query.Get("offset")
-
-	// Build filter
-	filter := bson.M{}
-	if platform != "" {
-		filter["platform"] = platform
-	}
-	if city != "" {
-		// Sanitize city input to prevent regex injection
-		sanitizedCity := strings.ReplaceAll(strings.ReplaceAll(city, "\\", "\\\\"), "$", "\\$")
-		filter["city"] = bson.M{"$regex": sanitizedCity, "$options": "i"}
-	}
-
-	// Set up options
-	opts := options.Find()
-	if limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-			opts.SetLimit(int64(limit))
+	if platform == "" {
+		platform = query.Get("provider") // The name GET /api/courts uses
+	}
+	city := strings.ToLower(query.Get("city"))
+	search := strings.TrimSpace(query.Get("q"))
+
+	allVenues, err := h.venueCache.GetVenuesCached(ctx)
+	if err != nil {
+		http.Error(w, "Failed to fetch venues", http.StatusInternalServerError)
+		return
+	}
+
+	// Filter the cached venues; city matches case-insensitively anywhere in the name
+	var venues []models.Venue
+	for _, venue := range allVenues {
+		if platform != "" && venue.Provider != platform {
+			continue
 		}
+		if city != "" && !strings.Contains(strings.ToLower(venue.Location.City), city) {
+			continue
+		}
+		venues = append(venues, *venue)
 	}
-	if offsetStr != "" {
-		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
-			opts.SetSkip(int64(offset))
+
+	var matches []venueMatch
+	if search != "" {
+		matches = searchVenues(venues, search)
+	} else {
+		// Sort by name
+		sort.SliceStable(venues, func(i, j int) bool { return venues[i].Name < venues[j].Name })
+		matches = make([]venueMatch, len(venues))
+		for i, venue := range venues {
+			matches[i] = venueMatch{venue: venue}
 		}
 	}
 
-	// Sort by name
-	opts.SetSort(bson.D{{Key: "name", Value: 1}})
+	if offset, err := strconv.Atoi(query.Get("offset")); err == nil && offset > 0 {
+		matches = matches[min(offset, len(matches)):]
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
 
-	// Query venues
-	collection := h.db.Collection("venues")
-	cursor, err := collection.Find(ctx, filter, opts)
-	if err != nil {
-		http.Error(w, "Failed to fetch venues", http.StatusInternalServerError)
+	// Convert to response format
+	response := make([]VenueResponse, len(matches))
+	for i, match := range matches {
+		response[i] = newVenueResponse(match.venue)
+		response[i].MatchedField = match.field
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// newVenueResponse converts a venue to its API representation
+func newVenueResponse(venue models.Venue) VenueResponse {
+	return VenueResponse{
+		ID:         venue.ID.Hex(),
+		Name:       venue.Name,
+		Address:    venue.Location.Address,
+		City:       venue.Location.City,
+		PostCode:   venue.Location.PostCode,
+		Phone:      "", // Not available in current model
+		Email:      "", // Not available in current model
+		Website:    venue.URL,
+		Platform:   venue.Provider,
+		PlatformID: venue.ID.Hex(), // Use venue ID as platform ID
+		Coordinates: struct {
+			Lat float64 `json:"lat"`
+			Lng float64 `json:"lng"`
+		}{
+			Lat: venue.Location.Latitude,
+			Lng: venue.Location.Longitude,
+		},
+		TotalCourts: len(venue.Courts),
+	}
+}
+
+// Nearby venue search bounds
+const (
+	defaultNearbyRadiusKm = 5.0
+	maxNearbyRadiusKm     = 50.0
+	defaultNearbyLimit    = 20
+)
+
+// NearbyVenueResponse is a venue with its distance from the searched point
+type NearbyVenueResponse struct {
+	VenueResponse
+	DistanceKm float64 `json:"distanceKm"`
+}
+
+// GetNearbyVenues handles the GET /api/venues/near endpoint, returning active
+// venues within radius_km (default 5, at most 50) of lat/lng, nearest first
+func (h *CourtHandler) GetNearbyVenues(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := r.URL.Query()
+
+	lat, err := strconv.ParseFloat(query.Get("lat"), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		http.Error(w, "lat must be a number between -90 and 90", http.StatusBadRequest)
+		return
+	}
+	lng, err := strconv.ParseFloat(query.Get("lng"), 64)
+	if err != nil || lng < -180 || lng > 180 {
+		http.Error(w, "lng must be a number between -180 and 180", http.StatusBadRequest)
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var venues []models.Venue
-	if err := cursor.All(ctx, &venues); err != nil {
-		http.Error(w, "Failed to decode venues", http.StatusInternalServerError)
+	radiusKm := defaultNearbyRadiusKm
+	if radiusStr := query.Get("radius_km"); radiusStr != "" {
+		radiusKm, err = strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radiusKm <= 0 || radiusKm > maxNearbyRadiusKm {
+			http.Error(w, fmt.Sprintf("radius_km must be greater than 0 and at most %g", maxNearbyRadiusKm), http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := int64(defaultNearbyLimit)
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = int64(parsedLimit)
+		}
+	}
+
+	venues, err := h.venueRepo.FindNear(ctx, lat, lng, radiusKm*1000, limit)
+	if err != nil {
+		http.Error(w, "Failed to fetch nearby venues", http.StatusInternalServerError)
 		return
 	}
 
-	// Convert to response format
-	response := make([]VenueResponse, len(venues))
+	response := make([]NearbyVenueResponse, len(venues))
 	for i, venue := range venues {
-		response[i] = VenueResponse{
-			ID:         venue.ID.Hex(),
-			Name:       venue.Name,
-			Address:    venue.Location.Address,
-			City:       venue.Location.City,
-			PostCode:   venue.Location.PostCode,
-			Phone:      "", // Not available in current model
-			Email:      "", // Not available in current model
-			Website:    venue.URL,
-			Platform:   venue.Provider,
-			PlatformID: venue.ID.Hex(), // Use venue ID as platform ID
-			Coordinates: struct {
-				Lat float64 `json:"lat"`
-				Lng float64 `json:"lng"`
-			}{
-				Lat: venue.Location.Latitude,
-				Lng: venue.Location.Longitude,
-			},
-			TotalCourts: len(venue.Courts),
+		response[i] = NearbyVenueResponse{
+			VenueResponse: newVenueResponse(venue.Venue),
+			DistanceKm:    math.Round(venue.DistanceMeters) / 1000,
 		}
 	}
 
@@ -190,53 +321,266 @@ func (h *CourtHandler) GetVenues(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// GetCourtSlots handles the GET /api/courts endpoint
-func (h *CourtHandler) GetCourtSlots(w http.ResponseWriter, r *http.Request) {
+// Scrape health reporting bounds
+const (
+	defaultScrapeHealthWindow = 24 * time.Hour
+	maxScrapeHealthWindow     = 30 * 24 * time.Hour // Scraping logs expire after 30 days
+	scrapeHealthTopErrors     = 5
+)
+
+// ScrapeErrorResponse is one of a venue's most frequent scraping errors
+type ScrapeErrorResponse struct {
+	Error string `json:"error"`
+	Count int64  `json:"count"`
+}
+
+// ScrapeHealthResponse represents how reliably a venue has been scraped
+type ScrapeHealthResponse struct {
+	VenueID              string                `json:"venueId"`
+	Window               string                `json:"window"`
+	TotalScrapes         int64                 `json:"totalScrapes"`
+	SuccessfulScrapes    int64                 `json:"successfulScrapes"`
+	SuccessRate          float64               `json:"successRate"`
+	AvgDurationMs        float64               `json:"avgDurationMs"`
+	LastSuccessfulScrape *time.Time            `json:"lastSuccessfulScrape"`
+	CommonErrors         []ScrapeErrorResponse `json:"commonErrors"`
+}
+
+// GetVenueScrapeHealth handles the GET /api/venues/{id}/scrape-health endpoint,
+// summarising the venue's scraping logs over window (e.g. 24h or 7d, default 24h)
+func (h *CourtHandler) GetVenueScrapeHealth(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Get query parameters
+	venueID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid venue ID", http.StatusBadRequest)
+		return
+	}
+
+	window := defaultScrapeHealthWindow
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		window, err = parseWindow(windowStr)
+		if err != nil || window <= 0 || window > maxScrapeHealthWindow {
+			http.Error(w, "window must be a duration such as 24h or 7d, at most 30d", http.StatusBadRequest)
+			return
+		}
+	}
+
+	health, err := h.scrapingLogRepo.GetScrapeHealth(ctx, venueID, time.Now().Add(-window), scrapeHealthTopErrors)
+	if err != nil {
+		http.Error(w, "Failed to fetch scrape health", http.StatusInternalServerError)
+		return
+	}
+
+	response := ScrapeHealthResponse{
+		VenueID:              venueID.Hex(),
+		Window:               window.String(),
+		TotalScrapes:         health.TotalScrapes,
+		SuccessfulScrapes:    health.SuccessfulScrapes,
+		SuccessRate:          health.SuccessRate(),
+		AvgDurationMs:        health.AvgDurationMs,
+		LastSuccessfulScrape: health.LastSuccessfulScrape,
+		CommonErrors:         make([]ScrapeErrorResponse, len(health.CommonErrors)),
+	}
+	for i, e := range health.CommonErrors {
+		response.CommonErrors[i] = ScrapeErrorResponse{Error: e.Error, Count: e.Count}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Price history reporting bounds
+const (
+	defaultPriceHistoryWindow = 30 * 24 * time.Hour
+	maxPriceHistoryWindow     = 365 * 24 * time.Hour
+	maxPriceHistoryPoints     = 1000
+)
+
+// PricePointResponse is a slot's price from when the scraper first saw it
+type PricePointResponse struct {
+	Date       string    `json:"date"`
+	StartTime  string    `json:"startTime"`
+	CourtID    string    `json:"courtId"`
+	CourtName  string    `json:"courtName"`
+	Price      float64   `json:"price"`
+	Currency   string    `json:"currency"`
+	ObservedAt time.Time `json:"observedAt"`
+}
+
+// PriceHistoryResponse represents how a venue's slot prices have changed
+type PriceHistoryResponse struct {
+	VenueID string               `json:"venueId"`
+	Court   string               `json:"court,omitempty"`
+	Time    string               `json:"time,omitempty"`
+	Window  string               `json:"window"`
+	Points  []PricePointResponse `json:"points"`
+}
+
+// GetVenuePriceHistory handles the GET /api/venues/{id}/price-history endpoint.
+// It returns each price change the scraper saw over window (default 30d),
+// optionally for one court (ID or name) and start time (HH:MM), oldest first.
+func (h *CourtHandler) GetVenuePriceHistory(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	venueID, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid venue ID", http.StatusBadRequest)
+		return
+	}
+
 	query := r.URL.Query()
-	venueID := query.Get("venueId")
-	date := query.Get("date")
-	limitStr := query.Get("limit")
+	startTime := query.Get("time")
+	if startTime != "" {
+		if _, err := time.Parse("15:04", startTime); err != nil || len(startTime) != 5 {
+			http.Error(w, "time must be in HH:MM format", http.StatusBadRequest)
+			return
+		}
+	}
 
-	// Parse limit
-	limit := int64(100) // Default limit
-	if limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = int64(parsedLimit)
+	window := defaultPriceHistoryWindow
+	if windowStr := query.Get("window"); windowStr != "" {
+		window, err = parseWindow(windowStr)
+		if err != nil || window <= 0 || window > maxPriceHistoryWindow {
+			http.Error(w, "window must be a duration such as 24h or 30d, at most 365d", http.StatusBadRequest)
+			return
 		}
 	}
 
-	var courtSlots []*models.CourtSlot
-	var err error
+	points, err := h.priceHistory.FindSeries(ctx, database.PriceHistoryQuery{
+		VenueID:   venueID,
+		Court:     query.Get("court"),
+		StartTime: startTime,
+		Since:     time.Now().Add(-window),
+		Limit:     maxPriceHistoryPoints,
+	})
+	if err != nil {
+		http.Error(w, "Failed to fetch price history", http.StatusInternalServerError)
+		return
+	}
 
-	// If venue ID is specified, use venue-specific query
-	if venueID != "" {
-		venueObjID, err := primitive.ObjectIDFromHex(venueID)
+	response := PriceHistoryResponse{
+		VenueID: venueID.Hex(),
+		Court:   query.Get("court"),
+		Time:    startTime,
+		Window:  window.String(),
+		Points:  make([]PricePointResponse, len(points)),
+	}
+	for i, point := range points {
+		response.Points[i] = PricePointResponse{
+			Date:       point.Date,
+			StartTime:  point.StartTime,
+			CourtID:    point.CourtID,
+			CourtName:  point.CourtName,
+			Price:      point.Price,
+			Currency:   point.Currency,
+			ObservedAt: point.ObservedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseWindow parses a Go duration, also accepting whole days such as "7d"
+func parseWindow(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// GetCourtSlots handles the GET /api/courts endpoint. Slots can be filtered by
+// venueId, provider, date, court surface, indoor and floodlights, price_min/price_max and
+// time_from/time_to (HH:MM), sorted with sort=time|price|venue and paged with
+// limit/offset. The total number of matches is returned in the response and in
+// X-Total-Count.
+//
+// For infinite scroll, pass after instead of offset: empty for the first page,
+// then the nextCursor from the previous page. Cursor pages are always in time
+// order and don't skip or repeat slots when slots change between requests.
+func (h *CourtHandler) GetCourtSlots(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := r.URL.Query()
+	search, err := parseSlotSearch(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if query.Has("after") {
+		h.getCourtSlotsAfter(ctx, w, query, search)
+		return
+	}
+
+	courtSlots, total, err := h.slotsRepo.SearchAvailableSlots(ctx, search)
+	if err != nil {
+		http.Error(w, "Failed to fetch court slots", http.StatusInternalServerError)
+		return
+	}
+
+	response := CourtSlotsResponse{
+		Slots:    toCourtSlotResponses(courtSlots),
+		PageInfo: Pagination{Limit: search.Limit, Offset: search.Skip}.PageInfo(total),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	json.NewEncoder(w).Encode(response)
+}
+
+// getCourtSlotsAfter serves a cursor page of GET /api/courts
+func (h *CourtHandler) getCourtSlotsAfter(ctx context.Context, w http.ResponseWriter, query url.Values, search database.SlotSearch) {
+	if query.Get("offset") != "" {
+		http.Error(w, "after and offset can't be used together", http.StatusBadRequest)
+		return
+	}
+	if search.Sort != "" && search.Sort != database.SlotSortTime {
+		http.Error(w, "after can only be used with sort=time", http.StatusBadRequest)
+		return
+	}
+
+	if after := query.Get("after"); after != "" {
+		cursor, err := decodeSlotCursor(after)
 		if err != nil {
-			http.Error(w, "Invalid venue ID", http.StatusBadRequest)
+			http.Error(w, "Invalid cursor", http.StatusBadRequest)
 			return
 		}
-		courtSlots, err = h.slotsRepo.GetAvailableSlotsByVenue(ctx, venueObjID, limit)
-	} else if date != "" {
-		// If date is specified, use date-specific query
-		courtSlots, err = h.slotsRepo.GetAvailableSlotsByDate(ctx, date, limit)
-	} else {
-		// General query for all available slots
-		courtSlots, err = h.slotsRepo.GetAvailableSlots(ctx, limit)
+		search.After = cursor
 	}
 
+	courtSlots, next, err := h.slotsRepo.SearchAvailableSlotsAfter(ctx, search)
 	if err != nil {
 		http.Error(w, "Failed to fetch court slots", http.StatusInternalServerError)
 		return
 	}
 
-	// Convert to response format
-	response := make([]CourtSlotResponse, len(courtSlots))
+	response := CourtSlotsCursorResponse{
+		Slots:   toCourtSlotResponses(courtSlots),
+		Limit:   search.Limit,
+		HasMore: next != nil,
+	}
+	if next != nil {
+		response.NextCursor = encodeSlotCursor(*next)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// toCourtSlotResponses converts slots to the response format
+func toCourtSlotResponses(courtSlots []*models.CourtSlot) []CourtSlotResponse {
+	responses := make([]CourtSlotResponse, len(courtSlots))
 	for i, slot := range courtSlots {
-		response[i] = CourtSlotResponse{
+		responses[i] = CourtSlotResponse{
 			ID:         slot.ID,
 			VenueID:    slot.VenueID.Hex(),
 			VenueName:  slot.VenueName,
@@ -255,59 +599,243 @@ func (h *CourtHandler) GetCourtSlots(w http.ResponseWriter, r *http.Request) {
 			UpdatedAt:  slot.LastScraped,
 		}
 	}
+	return responses
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+// slotCursorJSON is the encoded form of a database.SlotCursor. Clients treat
+// the encoded cursor as opaque.
+type slotCursorJSON struct {
+	Date      string `json:"d"`
+	StartTime string `json:"t"`
+	ID        string `json:"id"`
+}
+
+// encodeSlotCursor encodes a cursor for the nextCursor response field
+func encodeSlotCursor(cursor database.SlotCursor) string {
+	data, _ := json.Marshal(slotCursorJSON{Date: cursor.Date, StartTime: cursor.StartTime, ID: cursor.ID.Hex()})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSlotCursor decodes an after query parameter from encodeSlotCursor
+func decodeSlotCursor(encoded string) (*database.SlotCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded slotCursorJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	if _, err := time.Parse("2006-01-02", decoded.Date); err != nil {
+		return nil, err
+	}
+	id, err := primitive.ObjectIDFromHex(decoded.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &database.SlotCursor{Date: decoded.Date, StartTime: decoded.StartTime, ID: id}, nil
+}
+
+// courtSlotPageBounds paginates GET /api/courts
+var courtSlotPageBounds = PageBounds{DefaultLimit: 100, MaxLimit: 500, MaxOffset: 10000}
+
+// CourtSlotsResponse is the paginated response for GET /api/courts
+type CourtSlotsResponse struct {
+	Slots []CourtSlotResponse `json:"slots"`
+	PageInfo
+}
+
+// CourtSlotsCursorResponse is the response for GET /api/courts?after=...; pass
+// NextCursor as after to fetch the next page
+type CourtSlotsCursorResponse struct {
+	Slots      []CourtSlotResponse `json:"slots"`
+	Limit      int64               `json:"limit"`
+	HasMore    bool                `json:"hasMore"`
+	NextCursor string              `json:"nextCursor,omitempty"`
 }
 
-// GetDashboardStats provides statistics for the dashboard
+// parseSlotSearch reads the GET /api/courts query parameters
+func parseSlotSearch(query url.Values) (database.SlotSearch, error) {
+	search := database.SlotSearch{
+		Provider: query.Get("provider"),
+		Date:     query.Get("date"),
+		Surface:  query.Get("surface"),
+		TimeFrom: query.Get("time_from"),
+		TimeTo:   query.Get("time_to"),
+		Sort:     query.Get("sort"),
+	}
+
+	if venueID := query.Get("venueId"); venueID != "" {
+		venueObjID, err := primitive.ObjectIDFromHex(venueID)
+		if err != nil {
+			return search, errors.New("Invalid venue ID")
+		}
+		search.VenueID = &venueObjID
+	}
+
+	if search.Date != "" {
+		if _, err := time.Parse("2006-01-02", search.Date); err != nil {
+			return search, errors.New("date must be YYYY-MM-DD")
+		}
+	}
+
+	for _, name := range []string{"time_from", "time_to"} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		// Times are compared as strings, so they must be zero-padded
+		if _, err := time.Parse("15:04", value); err != nil || len(value) != 5 {
+			return search, fmt.Errorf("%s must be HH:MM", name)
+		}
+	}
+
+	var err error
+	if search.Indoor, err = parseOptionalBool(query, "indoor"); err != nil {
+		return search, err
+	}
+	if search.Floodlights, err = parseOptionalBool(query, "floodlights"); err != nil {
+		return search, err
+	}
+	if search.MinPrice, err = parseOptionalPrice(query, "price_min"); err != nil {
+		return search, err
+	}
+	if search.MaxPrice, err = parseOptionalPrice(query, "price_max"); err != nil {
+		return search, err
+	}
+	if search.MinPrice != nil && search.MaxPrice != nil && *search.MinPrice > *search.MaxPrice {
+		return search, errors.New("price_min must not be greater than price_max")
+	}
+
+	switch search.Sort {
+	case "", database.SlotSortTime, database.SlotSortPrice, database.SlotSortVenue:
+	default:
+		return search, errors.New("sort must be one of time, price or venue")
+	}
+
+	page, err := parsePagination(query, courtSlotPageBounds)
+	if err != nil {
+		return search, err
+	}
+	search.Limit = page.Limit
+	search.Skip = page.Offset
+
+	return search, nil
+}
+
+// parseOptionalBool reads a true/false query parameter, returning nil when it is absent
+func parseOptionalBool(query url.Values, name string) (*bool, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be true or false", name)
+	}
+	return &parsed, nil
+}
+
+// parseOptionalPrice reads a non-negative price query parameter, returning nil when it is absent
+func parseOptionalPrice(query url.Values, name string) (*float64, error) {
+	value := query.Get(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || parsed < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative number", name)
+	}
+	return &parsed, nil
+}
+
+// GetDashboardStats provides statistics for the dashboard. Results are cached
+// for dashboardStatsCacheTTL so dashboard refreshes don't rerun the aggregations.
 func (h *CourtHandler) GetDashboardStats(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := utils.WithDBTimeoutFrom(r.Context())
 	defer cancel()
 
-	stats := DashboardStatsResponse{}
+	if h.statsCache != nil {
+		data, err := h.statsCache.Get(ctx, dashboardStatsCacheKey)
+		if err == nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(data)
+			return
+		}
+		if !errors.Is(err, database.ErrCacheMiss) {
+			courtLogger.WithContext(ctx).Warn("Dashboard stats cache unavailable", map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	stats, complete := h.computeDashboardStats(ctx, time.Now())
+	data, err := json.Marshal(stats)
+	if err != nil {
+		http.Error(w, "Failed to encode dashboard stats", http.StatusInternalServerError)
+		return
+	}
+
+	// Don't keep serving figures that are missing because a query failed
+	if h.statsCache != nil && complete {
+		if err := h.statsCache.Set(ctx, dashboardStatsCacheKey, data, dashboardStatsCacheTTL); err != nil {
+			courtLogger.WithContext(ctx).Warn("Failed to cache dashboard stats", map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// computeDashboardStats gathers the dashboard statistics, reporting whether
+// every query succeeded
+func (h *CourtHandler) computeDashboardStats(ctx context.Context, now time.Time) (DashboardStatsResponse, bool) {
+	stats := DashboardStatsResponse{GeneratedAt: now.UTC()}
+	complete := true
 
-	// Count total venues
-	venueCollection := h.db.Collection("venues")
-	totalVenues, err := venueCollection.CountDocuments(ctx, bson.M{})
+	aggregates, err := h.dashboardStats.Aggregate(ctx, now)
 	if err == nil {
-		stats.TotalVenues = int(totalVenues)
+		stats.DashboardAggregates = *aggregates
+	} else {
+		courtLogger.WithContext(ctx).Error("Failed to aggregate dashboard stats", map[string]interface{}{"error": err.Error()})
+		complete = false
 	}
 
 	// Get available court slots count
 	availableCount, err := h.slotsRepo.CountAvailableSlots(ctx)
-	if err == nil {
-		stats.TotalCourtSlots = int(availableCount)
-		stats.AvailableSlots = int(availableCount) // All counted slots are available
-
-		// Count today's slots
-		today := time.Now().Format("2006-01-02")
-		todayCount, todayErr := h.slotsRepo.CountSlotsByDate(ctx, today)
-		if todayErr == nil {
-			stats.TodaySlots = int(todayCount)
-		}
+	if err != nil {
+		return stats, false
+	}
+	stats.TotalCourtSlots = int(availableCount)
+	stats.AvailableSlots = int(availableCount) // All counted slots are available
 
-		// Count this week's slots using efficient date range query
-		now := time.Now()
-		weekStart := now.AddDate(0, 0, -int(now.Weekday()))
-		weekEnd := weekStart.AddDate(0, 0, 7)
-		weekStartStr := weekStart.Format("2006-01-02")
-		weekEndStr := weekEnd.Format("2006-01-02")
+	// Count today's slots
+	today := now.Format("2006-01-02")
+	todayCount, err := h.slotsRepo.CountSlotsByDate(ctx, today)
+	if err == nil {
+		stats.TodaySlots = int(todayCount)
+	} else {
+		complete = false
+	}
 
-		weekCount, weekErr := h.slotsRepo.CountSlotsByDateRange(ctx, weekStartStr, weekEndStr)
-		if weekErr == nil {
-			stats.WeekSlots = int(weekCount)
-		}
+	// Count this week's slots using efficient date range query
+	weekStart := now.AddDate(0, 0, -int(now.Weekday()))
+	weekEnd := weekStart.AddDate(0, 0, 7)
+	weekCount, err := h.slotsRepo.CountSlotsByDateRange(ctx, weekStart.Format("2006-01-02"), weekEnd.Format("2006-01-02"))
+	if err == nil {
+		stats.WeekSlots = int(weekCount)
+	} else {
+		complete = false
+	}
 
-		// Count active platforms
-		platforms, platformErr := h.slotsRepo.GetActivePlatforms(ctx)
-		if platformErr == nil {
-			stats.ActivePlatforms = len(platforms)
-		}
+	// Count active platforms
+	platforms, err := h.slotsRepo.GetActivePlatforms(ctx)
+	if err == nil {
+		stats.ActivePlatforms = len(platforms)
+	} else {
+		complete = false
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(stats)
+	return stats, complete
 }
 
 // calculateDuration calculates the duration in minutes between start and end time
@@ -2,19 +2,30 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tennis-booker/internal/database"
 	"tennis-booker/internal/models"
 )
 
 // MockVenueRepository for testing
 type MockVenueRepository struct {
 	venues []*models.Venue
+	nearby []*database.VenueDistance
 	err    error
+
+	// Arguments of the last FindNear call
+	nearLat, nearLng, nearRadius float64
 }
 
 func (m *MockVenueRepository) ListActive(ctx context.Context) ([]*models.Venue, error) {
@@ -24,10 +35,36 @@ func (m *MockVenueRepository) ListActive(ctx context.Context) ([]*models.Venue,
 	return m.venues, nil
 }
 
+// GetVenuesCached lets the mock stand in for the venue cache
+func (m *MockVenueRepository) GetVenuesCached(ctx context.Context) ([]*models.Venue, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.venues, nil
+}
+
+func (m *MockVenueRepository) FindNear(ctx context.Context, lat, lng, radiusMeters float64, limit int64) ([]*database.VenueDistance, error) {
+	m.nearLat, m.nearLng, m.nearRadius = lat, lng, radiusMeters
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.nearby, nil
+}
+
 // MockScrapingLogRepository for testing
 type MockScrapingLogRepository struct {
-	courtSlots []*models.CourtSlot
-	err        error
+	courtSlots  []*models.CourtSlot
+	health      *database.ScrapeHealth
+	healthSince time.Time
+	err         error
+}
+
+func (m *MockScrapingLogRepository) GetScrapeHealth(ctx context.Context, venueID primitive.ObjectID, since time.Time, topErrors int) (*database.ScrapeHealth, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.healthSince = since
+	return m.health, nil
 }
 
 func (m *MockScrapingLogRepository) GetAvailableCourtSlots(ctx context.Context, limit int64) ([]*models.CourtSlot, error) {
@@ -445,3 +482,478 @@ func TestCourtHandler_ListCourtsWithFilters(t *testing.T) {
 	// Skip test that requires real database connection
 	t.Skip("Skipping test that requires real database connection - needs integration test setup")
 }
+
+func TestParseSlotSearch(t *testing.T) {
+	venueID := primitive.NewObjectID()
+	query := url.Values{
+		"venueId":     {venueID.Hex()},
+		"date":        {"2024-06-15"},
+		"surface":     {"clay"},
+		"indoor":      {"false"},
+		"floodlights": {"true"},
+		"price_min":   {"10"},
+		"price_max":   {"25.5"},
+		"time_from":   {"18:00"},
+		"time_to":     {"21:00"},
+		"sort":        {"price"},
+		"limit":       {"20"},
+		"offset":      {"40"},
+	}
+
+	search, err := parseSlotSearch(query)
+	require.NoError(t, err)
+	assert.Equal(t, venueID, *search.VenueID)
+	assert.Equal(t, "2024-06-15", search.Date)
+	assert.Equal(t, "clay", search.Surface)
+	assert.False(t, *search.Indoor)
+	assert.True(t, *search.Floodlights)
+	assert.Equal(t, 10.0, *search.MinPrice)
+	assert.Equal(t, 25.5, *search.MaxPrice)
+	assert.Equal(t, "18:00", search.TimeFrom)
+	assert.Equal(t, "21:00", search.TimeTo)
+	assert.Equal(t, database.SlotSortPrice, search.Sort)
+	assert.Equal(t, int64(20), search.Limit)
+	assert.Equal(t, int64(40), search.Skip)
+
+	defaults, err := parseSlotSearch(url.Values{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), defaults.Limit)
+	assert.Nil(t, defaults.Indoor)
+	assert.Nil(t, defaults.MinPrice)
+}
+
+func TestParseSlotSearch_RejectsInvalidParameters(t *testing.T) {
+	for name, query := range map[string]url.Values{
+		"venue ID":       {"venueId": {"not-an-id"}},
+		"date":           {"date": {"15/06/2024"}},
+		"indoor":         {"indoor": {"sometimes"}},
+		"negative price": {"price_min": {"-1"}},
+		"price range":    {"price_min": {"30"}, "price_max": {"20"}},
+		"unpadded time":  {"time_from": {"9:00"}},
+		"sort":           {"sort": {"distance"}},
+	} {
+		_, err := parseSlotSearch(query)
+		assert.Error(t, err, name)
+	}
+}
+
+// MockSearchSlotsRepository returns one offset page of slots from SearchAvailableSlots
+type MockSearchSlotsRepository struct {
+	SlotsRepositoryInterface
+	slots  []*models.CourtSlot
+	total  int64
+	search database.SlotSearch // Search of the last call
+}
+
+func (m *MockSearchSlotsRepository) SearchAvailableSlots(ctx context.Context, search database.SlotSearch) ([]*models.CourtSlot, int64, error) {
+	m.search = search
+	return m.slots, m.total, nil
+}
+
+func TestCourtHandler_GetCourtSlots_Pagination(t *testing.T) {
+	repo := &MockSearchSlotsRepository{
+		slots: []*models.CourtSlot{
+			{ID: primitive.NewObjectID().Hex(), Date: "2024-06-15", StartTime: "09:00"},
+			{ID: primitive.NewObjectID().Hex(), Date: "2024-06-15", StartTime: "10:00"},
+		},
+		total: 7,
+	}
+	handler := &CourtHandler{slotsRepo: repo}
+
+	w := httptest.NewRecorder()
+	handler.GetCourtSlots(w, httptest.NewRequest(http.MethodGet, "/api/courts?limit=2&offset=4", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, int64(2), repo.search.Limit)
+	assert.Equal(t, int64(4), repo.search.Skip)
+
+	var page CourtSlotsResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Slots, 2)
+	assert.Equal(t, int64(7), page.Total)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, "7", w.Header().Get("X-Total-Count"))
+}
+
+// MockCursorSlotsRepository pages through slots with SearchAvailableSlotsAfter
+type MockCursorSlotsRepository struct {
+	SlotsRepositoryInterface
+	slots []*models.CourtSlot
+	after *database.SlotCursor // Cursor of the last call
+}
+
+func (m *MockCursorSlotsRepository) SearchAvailableSlotsAfter(ctx context.Context, search database.SlotSearch) ([]*models.CourtSlot, *database.SlotCursor, error) {
+	m.after = search.After
+
+	start := 0
+	if search.After != nil {
+		for i, slot := range m.slots {
+			if slot.ID == search.After.ID.Hex() {
+				start = i + 1
+			}
+		}
+	}
+	end := start + int(search.Limit)
+	if end >= len(m.slots) {
+		return m.slots[start:], nil, nil
+	}
+	last := m.slots[end-1]
+	id, _ := primitive.ObjectIDFromHex(last.ID)
+	return m.slots[start:end], &database.SlotCursor{Date: last.Date, StartTime: last.StartTime, ID: id}, nil
+}
+
+func TestCourtHandler_GetCourtSlots_Cursor(t *testing.T) {
+	repo := &MockCursorSlotsRepository{}
+	for _, start := range []string{"09:00", "10:00", "11:00"} {
+		repo.slots = append(repo.slots, &models.CourtSlot{ID: primitive.NewObjectID().Hex(), Date: "2024-06-15", StartTime: start})
+	}
+	handler := &CourtHandler{slotsRepo: repo}
+
+	getPage := func(t *testing.T, query string) CourtSlotsCursorResponse {
+		w := httptest.NewRecorder()
+		handler.GetCourtSlots(w, httptest.NewRequest(http.MethodGet, "/api/courts?"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var page CourtSlotsCursorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		return page
+	}
+
+	first := getPage(t, "after=&limit=2")
+	assert.Nil(t, repo.after)
+	require.Len(t, first.Slots, 2)
+	assert.True(t, first.HasMore)
+	require.NotEmpty(t, first.NextCursor)
+
+	second := getPage(t, "limit=2&after="+first.NextCursor)
+	require.NotNil(t, repo.after)
+	assert.Equal(t, repo.slots[1].ID, repo.after.ID.Hex())
+	assert.Equal(t, "10:00", repo.after.StartTime)
+	require.Len(t, second.Slots, 1)
+	assert.Equal(t, "11:00", second.Slots[0].StartTime)
+	assert.False(t, second.HasMore)
+	assert.Empty(t, second.NextCursor)
+}
+
+func TestCourtHandler_GetCourtSlots_RejectsInvalidCursorRequests(t *testing.T) {
+	handler := &CourtHandler{slotsRepo: &MockCursorSlotsRepository{}}
+
+	for name, query := range map[string]string{
+		"malformed cursor": "after=not-a-cursor",
+		"with offset":      "after=&offset=20",
+		"with price sort":  "after=&sort=price",
+	} {
+		w := httptest.NewRecorder()
+		handler.GetCourtSlots(w, httptest.NewRequest(http.MethodGet, "/api/courts?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+	}
+}
+
+func TestSlotCursor_RoundTrip(t *testing.T) {
+	cursor := database.SlotCursor{Date: "2024-06-15", StartTime: "18:30", ID: primitive.NewObjectID()}
+
+	decoded, err := decodeSlotCursor(encodeSlotCursor(cursor))
+	require.NoError(t, err)
+	assert.Equal(t, cursor, *decoded)
+}
+
+func TestCourtHandler_GetNearbyVenues(t *testing.T) {
+	victoriaPark := &database.VenueDistance{
+		Venue: models.Venue{
+			ID:       primitive.NewObjectID(),
+			Name:     "Victoria Park",
+			Location: models.Location{Latitude: 51.5362, Longitude: -0.0403},
+		},
+		DistanceMeters: 1342.6,
+	}
+	repo := &MockVenueRepository{nearby: []*database.VenueDistance{victoriaPark}}
+	handler := &CourtHandler{venueRepo: repo}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/venues/near?lat=51.525&lng=-0.033&radius_km=3", nil)
+	w := httptest.NewRecorder()
+	handler.GetNearbyVenues(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 51.525, repo.nearLat)
+	assert.Equal(t, -0.033, repo.nearLng)
+	assert.Equal(t, 3000.0, repo.nearRadius)
+
+	var response []NearbyVenueResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response, 1)
+	assert.Equal(t, "Victoria Park", response[0].Name)
+	assert.Equal(t, 51.5362, response[0].Coordinates.Lat)
+	assert.Equal(t, 1.343, response[0].DistanceKm)
+}
+
+func TestCourtHandler_GetNearbyVenues_Validation(t *testing.T) {
+	handler := &CourtHandler{venueRepo: &MockVenueRepository{}}
+
+	for _, query := range []string{
+		"",
+		"lat=51.5",
+		"lat=91&lng=0",
+		"lat=51.5&lng=-181",
+		"lat=51.5&lng=0&radius_km=0",
+		"lat=51.5&lng=0&radius_km=500",
+	} {
+		w := httptest.NewRecorder()
+		handler.GetNearbyVenues(w, httptest.NewRequest(http.MethodGet, "/api/venues/near?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	handler = &CourtHandler{venueRepo: &MockVenueRepository{err: errors.New("no 2dsphere index")}}
+	w := httptest.NewRecorder()
+	handler.GetNearbyVenues(w, httptest.NewRequest(http.MethodGet, "/api/venues/near?lat=51.5&lng=0", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestCourtHandler_GetVenueScrapeHealth(t *testing.T) {
+	venueID := primitive.NewObjectID()
+	lastSuccess := time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)
+	repo := &MockScrapingLogRepository{health: &database.ScrapeHealth{
+		VenueID:              venueID,
+		TotalScrapes:         8,
+		SuccessfulScrapes:    6,
+		AvgDurationMs:        1250.5,
+		LastSuccessfulScrape: &lastSuccess,
+		CommonErrors:         []database.ErrorCount{{Error: "timeout", Count: 2}},
+	}}
+	handler := &CourtHandler{scrapingLogRepo: repo}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/venues/"+venueID.Hex()+"/scrape-health?window=7d", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": venueID.Hex()})
+	w := httptest.NewRecorder()
+	before := time.Now()
+	handler.GetVenueScrapeHealth(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.WithinDuration(t, before.Add(-7*24*time.Hour), repo.healthSince, time.Second)
+
+	var response ScrapeHealthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, venueID.Hex(), response.VenueID)
+	assert.Equal(t, 0.75, response.SuccessRate)
+	assert.Equal(t, 1250.5, response.AvgDurationMs)
+	require.NotNil(t, response.LastSuccessfulScrape)
+	assert.True(t, lastSuccess.Equal(*response.LastSuccessfulScrape))
+	assert.Equal(t, []ScrapeErrorResponse{{Error: "timeout", Count: 2}}, response.CommonErrors)
+}
+
+func TestCourtHandler_GetVenueScrapeHealth_Validation(t *testing.T) {
+	venueID := primitive.NewObjectID().Hex()
+	handler := &CourtHandler{scrapingLogRepo: &MockScrapingLogRepository{health: &database.ScrapeHealth{}}}
+
+	for _, tc := range []struct{ id, query string }{
+		{"not-an-id", ""},
+		{venueID, "window=soon"},
+		{venueID, "window=-1h"},
+		{venueID, "window=31d"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/venues/"+tc.id+"/scrape-health?"+tc.query, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": tc.id})
+		w := httptest.NewRecorder()
+		handler.GetVenueScrapeHealth(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, tc)
+	}
+}
+
+// MockPriceHistoryRepository returns canned price points and records the query
+type MockPriceHistoryRepository struct {
+	points []*database.PricePoint
+	query  database.PriceHistoryQuery
+}
+
+func (m *MockPriceHistoryRepository) FindSeries(ctx context.Context, query database.PriceHistoryQuery) ([]*database.PricePoint, error) {
+	m.query = query
+	return m.points, nil
+}
+
+func TestCourtHandler_GetVenuePriceHistory(t *testing.T) {
+	venueID := primitive.NewObjectID()
+	observed := time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)
+	repo := &MockPriceHistoryRepository{points: []*database.PricePoint{
+		{VenueID: venueID, CourtID: "1", CourtName: "Court 1", Date: "2024-01-15", StartTime: "18:00", Price: 12, Currency: "GBP", ObservedAt: observed},
+		{VenueID: venueID, CourtID: "1", CourtName: "Court 1", Date: "2024-01-15", StartTime: "18:00", Price: 15, Currency: "GBP", ObservedAt: observed.Add(24 * time.Hour)},
+	}}
+	handler := &CourtHandler{priceHistory: repo}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/venues/"+venueID.Hex()+"/price-history?court=Court+1&time=18:00&window=7d", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": venueID.Hex()})
+	w := httptest.NewRecorder()
+	before := time.Now()
+	handler.GetVenuePriceHistory(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, venueID, repo.query.VenueID)
+	assert.Equal(t, "Court 1", repo.query.Court)
+	assert.Equal(t, "18:00", repo.query.StartTime)
+	assert.WithinDuration(t, before.Add(-7*24*time.Hour), repo.query.Since, time.Second)
+
+	var response PriceHistoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Points, 2)
+	assert.Equal(t, 12.0, response.Points[0].Price)
+	assert.Equal(t, 15.0, response.Points[1].Price)
+	assert.True(t, observed.Equal(response.Points[0].ObservedAt))
+}
+
+func TestCourtHandler_GetVenuePriceHistory_Validation(t *testing.T) {
+	venueID := primitive.NewObjectID().Hex()
+	handler := &CourtHandler{priceHistory: &MockPriceHistoryRepository{}}
+
+	for _, tc := range []struct{ id, query string }{
+		{"not-an-id", ""},
+		{venueID, "time=6pm"},
+		{venueID, "time=18:0"},
+		{venueID, "window=366d"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/venues/"+tc.id+"/price-history?"+tc.query, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": tc.id})
+		w := httptest.NewRecorder()
+		handler.GetVenuePriceHistory(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, tc)
+	}
+}
+
+func TestCourtHandler_GetVenues_FromCache(t *testing.T) {
+	cache := &MockVenueRepository{venues: []*models.Venue{
+		{ID: primitive.NewObjectID(), Name: "Victoria Park", Provider: "courtsides", Location: models.Location{City: "London"}},
+		{ID: primitive.NewObjectID(), Name: "Clissold Park", Provider: "lta", Location: models.Location{City: "London"}},
+		{ID: primitive.NewObjectID(), Name: "Albert Park", Provider: "lta", Location: models.Location{City: "Manchester"}},
+		{ID: primitive.NewObjectID(), Name: "Ropemakers Field", Provider: "lta", Location: models.Location{City: "London"}},
+	}}
+	handler := &CourtHandler{venueCache: cache}
+
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"Albert Park", "Clissold Park", "Ropemakers Field", "Victoria Park"}},
+		{"platform=lta&city=lond", []string{"Clissold Park", "Ropemakers Field"}},
+		{"city=LONDON&offset=1&limit=1", []string{"Ropemakers Field"}},
+		{"offset=10", []string{}},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		handler.GetVenues(w, httptest.NewRequest(http.MethodGet, "/api/venues?"+tt.query, nil))
+		require.Equal(t, http.StatusOK, w.Code, tt.query)
+
+		var response []VenueResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		names := []string{}
+		for _, venue := range response {
+			names = append(names, venue.Name)
+		}
+		assert.Equal(t, tt.expected, names, tt.query)
+	}
+
+	handler = &CourtHandler{venueCache: &MockVenueRepository{err: errors.New("mongo down")}}
+	w := httptest.NewRecorder()
+	handler.GetVenues(w, httptest.NewRequest(http.MethodGet, "/api/venues", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+// MockDashboardStats counts how often the dashboard aggregates are computed
+type MockDashboardStats struct {
+	aggregates *database.DashboardAggregates
+	err        error
+	calls      int
+}
+
+func (m *MockDashboardStats) Aggregate(ctx context.Context, now time.Time) (*database.DashboardAggregates, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.aggregates, nil
+}
+
+// MockSlotsRepository answers the slot counts used by the dashboard
+type MockSlotsRepository struct {
+	SlotsRepositoryInterface
+	available int64
+}
+
+func (m *MockSlotsRepository) CountAvailableSlots(ctx context.Context) (int64, error) {
+	return m.available, nil
+}
+
+func (m *MockSlotsRepository) CountSlotsByDate(ctx context.Context, date string) (int64, error) {
+	return 3, nil
+}
+
+func (m *MockSlotsRepository) CountSlotsByDateRange(ctx context.Context, startDate, endDate string) (int64, error) {
+	return 7, nil
+}
+
+func (m *MockSlotsRepository) GetActivePlatforms(ctx context.Context) ([]string, error) {
+	return []string{"lta", "courtsides"}, nil
+}
+
+// MockCacheStore is an in-memory database.CacheStore
+type MockCacheStore struct {
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func (m *MockCacheStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, ok := m.values[key]
+	if !ok {
+		return nil, database.ErrCacheMiss
+	}
+	return value, nil
+}
+
+func (m *MockCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.values[key] = value
+	m.ttls[key] = ttl
+	return nil
+}
+
+func (m *MockCacheStore) Delete(ctx context.Context, key string) error {
+	delete(m.values, key)
+	return nil
+}
+
+func TestCourtHandler_GetDashboardStats_Cached(t *testing.T) {
+	aggregator := &MockDashboardStats{aggregates: &database.DashboardAggregates{
+		TotalVenues: 3, ActiveVenues: 2, CourtsTracked: 8, AvailableNext24h: 5,
+		SlotsFoundToday: 12, NotificationsSentToday: 4, ScrapeSuccessRate: 90,
+	}}
+	cache := &MockCacheStore{values: map[string][]byte{}, ttls: map[string]time.Duration{}}
+	handler := &CourtHandler{dashboardStats: aggregator, slotsRepo: &MockSlotsRepository{available: 20}}
+	handler.SetStatsCache(cache)
+
+	getStats := func() DashboardStatsResponse {
+		w := httptest.NewRecorder()
+		handler.GetDashboardStats(w, httptest.NewRequest(http.MethodGet, "/api/dashboard/stats", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var stats DashboardStatsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stats))
+		return stats
+	}
+
+	first := getStats()
+	assert.Equal(t, 3, first.TotalVenues)
+	assert.Equal(t, 8, first.CourtsTracked)
+	assert.Equal(t, 4, first.NotificationsSentToday)
+	assert.Equal(t, 90.0, first.ScrapeSuccessRate)
+	assert.Equal(t, 20, first.AvailableSlots)
+	assert.Equal(t, 2, first.ActivePlatforms)
+	assert.WithinDuration(t, time.Now(), first.GeneratedAt, time.Minute)
+	assert.Equal(t, dashboardStatsCacheTTL, cache.ttls[dashboardStatsCacheKey])
+
+	second := getStats()
+	assert.Equal(t, 1, aggregator.calls, "served from the cache")
+	assert.True(t, first.GeneratedAt.Equal(second.GeneratedAt))
+
+	// Incomplete stats are returned but not cached
+	cache.values = map[string][]byte{}
+	aggregator.err = errors.New("mongo down")
+	stats := getStats()
+	assert.Equal(t, 0, stats.TotalVenues)
+	assert.Equal(t, 20, stats.AvailableSlots)
+	assert.Empty(t, cache.values)
+}
@@ -3,18 +3,31 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"tennis-booker/internal/database"
 	"tennis-booker/internal/secrets"
 )
 
+// deepHealthTimeout bounds each dependency check in DeepHealth
+const deepHealthTimeout = 5 * time.Second
+
+// DependencyCheck verifies one dependency is reachable, returning nil when it is
+type DependencyCheck func(ctx context.Context) error
+
+// errNotConfigured is reported for dependencies the server was started without
+var errNotConfigured = errors.New("not configured")
+
 // HealthHandler handles health check requests
 type HealthHandler struct {
 	secretsManager *secrets.SecretsManager
 	db             database.Database
+	redisCheck     DependencyCheck
+	smtpCheck      DependencyCheck
 }
 
 // NewHealthHandler creates a new health handler
@@ -45,8 +58,33 @@ type SystemHealthResponse struct {
 	Uptime      string                 `json:"uptime"`
 }
 
+// DependencyStatus is one dependency's result in the deep health check
+type DependencyStatus struct {
+	Healthy   bool   `json:"healthy"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// DeepHealthResponse reports every dependency a running system needs
+type DeepHealthResponse struct {
+	Healthy      bool                        `json:"healthy"`
+	Timestamp    time.Time                   `json:"timestamp"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
 var startTime = time.Now()
 
+// SetRedisCheck sets how DeepHealth checks Redis; without one Redis is reported unhealthy
+func (h *HealthHandler) SetRedisCheck(check DependencyCheck) {
+	h.redisCheck = check
+}
+
+// SetSMTPCheck adds an SMTP check to DeepHealth. SMTP is optional, so
+// without one it isn't checked at all.
+func (h *HealthHandler) SetSMTPCheck(check DependencyCheck) {
+	h.smtpCheck = check
+}
+
 // Health handles basic health check
 func (h *HealthHandler) Health(w http.ResponseWriter, r *http.Request) {
 	response := HealthResponse{
@@ -120,6 +158,87 @@ func (h *HealthHandler) SystemHealth(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// DeepHealth checks MongoDB, Redis, the secrets manager and, when configured,
+// SMTP together, for readiness gating. Checks run concurrently and each is
+// bounded by deepHealthTimeout; any failure returns 503.
+func (h *HealthHandler) DeepHealth(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]DependencyCheck{
+		"mongodb": h.pingDatabase,
+		"redis":   h.redisCheck,
+		"secrets": h.checkSecrets,
+	}
+	if h.smtpCheck != nil {
+		checks["smtp"] = h.smtpCheck
+	}
+
+	response := DeepHealthResponse{
+		Healthy:      true,
+		Timestamp:    time.Now(),
+		Dependencies: runDependencyChecks(r.Context(), checks),
+	}
+	for _, status := range response.Dependencies {
+		if !status.Healthy {
+			response.Healthy = false
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !response.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// runDependencyChecks runs the checks concurrently, each with its own timeout
+func runDependencyChecks(ctx context.Context, checks map[string]DependencyCheck) map[string]DependencyStatus {
+	results := make(map[string]DependencyStatus, len(checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check DependencyCheck) {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, deepHealthTimeout)
+			defer cancel()
+
+			started := time.Now()
+			err := errNotConfigured
+			if check != nil {
+				err = check(checkCtx)
+			}
+			status := DependencyStatus{Healthy: err == nil, LatencyMS: time.Since(started).Milliseconds()}
+			if err != nil {
+				status.Error = err.Error()
+			}
+
+			mu.Lock()
+			results[name] = status
+			mu.Unlock()
+		}(name, check)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// pingDatabase pings MongoDB
+func (h *HealthHandler) pingDatabase(ctx context.Context) error {
+	if h.db == nil {
+		return errNotConfigured
+	}
+	return h.db.Ping(ctx)
+}
+
+// checkSecrets checks the secrets manager the server reads credentials from
+func (h *HealthHandler) checkSecrets(ctx context.Context) error {
+	if h.secretsManager == nil {
+		return errNotConfigured
+	}
+	return h.secretsManager.HealthCheck()
+}
+
 // checkDatabase verifies database connectivity
 func (h *HealthHandler) checkDatabase() bool {
 	if h.db == nil {
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tennis-booker/internal/secrets"
+)
+
+// unreachableDatabase fails every ping
+type unreachableDatabase struct {
+	MockDatabase
+}
+
+func (d *unreachableDatabase) Ping(ctx context.Context) error {
+	return errors.New("server selection timeout")
+}
+
+func deepHealth(t *testing.T, handler *HealthHandler) (int, DeepHealthResponse) {
+	rr := httptest.NewRecorder()
+	handler.DeepHealth(rr, httptest.NewRequest(http.MethodGet, "/api/health/deep", nil))
+
+	var response DeepHealthResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	return rr.Code, response
+}
+
+func TestDeepHealth_AllHealthy(t *testing.T) {
+	handler := NewHealthHandler(secrets.NewSecretsManager(), NewMockDatabase())
+	handler.SetRedisCheck(func(ctx context.Context) error { return nil })
+	handler.SetSMTPCheck(func(ctx context.Context) error { return nil })
+
+	code, response := deepHealth(t, handler)
+
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, response.Healthy)
+	assert.Len(t, response.Dependencies, 4)
+	for name, status := range response.Dependencies {
+		assert.True(t, status.Healthy, name)
+		assert.Empty(t, status.Error, name)
+	}
+}
+
+func TestDeepHealth_ReportsEachFailure(t *testing.T) {
+	handler := NewHealthHandler(nil, &unreachableDatabase{})
+	handler.SetRedisCheck(func(ctx context.Context) error {
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline, "checks are bounded")
+		return errors.New("connection refused")
+	})
+
+	code, response := deepHealth(t, handler)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, response.Healthy)
+	assert.Equal(t, "server selection timeout", response.Dependencies["mongodb"].Error)
+	assert.Equal(t, "connection refused", response.Dependencies["redis"].Error)
+	assert.Equal(t, "not configured", response.Dependencies["secrets"].Error)
+	assert.NotContains(t, response.Dependencies, "smtp", "SMTP is only checked when configured")
+}
+
+func TestDeepHealth_RedisCheckRequired(t *testing.T) {
+	handler := NewHealthHandler(secrets.NewSecretsManager(), NewMockDatabase())
+
+	code, response := deepHealth(t, handler)
+
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.True(t, response.Dependencies["mongodb"].Healthy)
+	assert.False(t, response.Dependencies["redis"].Healthy)
+}
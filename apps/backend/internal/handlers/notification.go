@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/database"
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationHandler handles notification-related requests
+type NotificationHandler struct {
+	db                database.Database
+	unsubscribeTokens *auth.UnsubscribeTokenService
+	unsubscriber      UnsubscriberInterface
+	alertHistory      *models.AlertHistoryService
+	deduplication     *models.DeduplicationService
+	alertStats        AlertStatsInterface
+	testNotifier      TestNotifierInterface
+	testLimiter       TestNotificationLimiter
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(db database.Database, unsubscribeTokens *auth.UnsubscribeTokenService) *NotificationHandler {
+	h := &NotificationHandler{
+		db:                db,
+		unsubscribeTokens: unsubscribeTokens,
+	}
+	if mongoDB := db.GetMongoDB(); mongoDB != nil {
+		h.unsubscriber = models.NewPreferenceService(mongoDB)
+		h.alertHistory = models.NewAlertHistoryService(mongoDB)
+		h.deduplication = models.NewDeduplicationService(models.NewMongoDedupStore(mongoDB), models.DefaultDeduplicationConfig())
+		h.alertStats = database.NewAlertStatsRepository(mongoDB)
+	}
+	return h
+}
+
+// historyPageBounds paginates GET /api/notifications/history
+var historyPageBounds = PageBounds{DefaultLimit: 20, MaxLimit: 100, MaxOffset: 10000}
+
+// AlertHistoryEntry is a single past alert in the notification history response
+type AlertHistoryEntry struct {
+	ID         string    `json:"id"`
+	VenueID    string    `json:"venueId"`
+	VenueName  string    `json:"venueName"`
+	CourtName  string    `json:"courtName"`
+	Date       string    `json:"date"`
+	StartTime  string    `json:"startTime"`
+	EndTime    string    `json:"endTime"`
+	Price      float64   `json:"price"`
+	Currency   string    `json:"currency"`
+	BookingURL string    `json:"bookingUrl"`
+	Channel    string    `json:"channel"`
+	Status     string    `json:"status"`
+	SentAt     time.Time `json:"sentAt"`
+}
+
+// AlertHistoryResponse is the paginated response for GET /api/notifications/history
+type AlertHistoryResponse struct {
+	Alerts []AlertHistoryEntry `json:"alerts"`
+	PageInfo
+}
+
+// GetHistory handles GET /api/notifications/history?limit=&offset=
+func (h *NotificationHandler) GetHistory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return // RequireAuth already wrote the error response
+	}
+
+	page, err := parsePagination(r.URL.Query(), historyPageBounds)
+	if err != nil {
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.alertHistory == nil {
+		utils.WriteError(w, "Notification history is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	alerts, total, err := h.alertHistory.GetUserAlertHistoryPage(ctx, userID, page.Limit, page.Offset)
+	if err != nil {
+		utils.WriteError(w, "Failed to fetch notification history", http.StatusInternalServerError)
+		return
+	}
+
+	response := AlertHistoryResponse{
+		Alerts:   make([]AlertHistoryEntry, 0, len(alerts)),
+		PageInfo: page.PageInfo(total),
+	}
+	for _, alert := range alerts {
+		response.Alerts = append(response.Alerts, toAlertHistoryEntry(alert))
+	}
+
+	utils.WriteSuccess(w, response)
+}
+
+// ResetAlertRequest is the body of POST /api/alerts/reset. Identify the slot by
+// its slot key, "venueId:courtId:date:startTime", or by the four fields.
+type ResetAlertRequest struct {
+	SlotKey   string `json:"slotKey,omitempty"`
+	VenueID   string `json:"venueId,omitempty"`
+	CourtID   string `json:"courtId,omitempty"`
+	Date      string `json:"date,omitempty"`      // YYYY-MM-DD
+	StartTime string `json:"startTime,omitempty"` // HH:MM
+}
+
+// ResetAlertResponse says how many deduplication records were cleared
+type ResetAlertResponse struct {
+	SlotKey string `json:"slotKey"`
+	Cleared int64  `json:"cleared"`
+}
+
+// resetSlotKey returns the slot key the request identifies
+func (req ResetAlertRequest) resetSlotKey() (string, error) {
+	fields := req.VenueID != "" || req.CourtID != "" || req.Date != "" || req.StartTime != ""
+	switch {
+	case req.SlotKey != "" && fields:
+		return "", errors.New("Set either slotKey or venueId, courtId, date and startTime, not both")
+	case req.SlotKey != "":
+		return req.SlotKey, nil
+	case req.VenueID == "" || req.CourtID == "" || req.Date == "" || req.StartTime == "":
+		return "", errors.New("Set slotKey, or venueId, courtId, date and startTime")
+	}
+
+	if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+		return "", errors.New("date must be in YYYY-MM-DD format")
+	}
+	if _, err := time.Parse("15:04", req.StartTime); err != nil {
+		return "", errors.New("startTime must be in HH:MM format")
+	}
+
+	event := models.CourtAvailabilityEvent{
+		VenueID:   req.VenueID,
+		CourtID:   req.CourtID,
+		Date:      req.Date,
+		StartTime: req.StartTime,
+	}
+	return event.GenerateSlotKey(), nil
+}
+
+// ResetAlert handles POST /api/alerts/reset. It clears the authenticated user's
+// deduplication record for a slot, so they are alerted about it again the next
+// time it is seen becoming available instead of waiting for the record to expire.
+func (h *NotificationHandler) ResetAlert(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return // RequireAuth already wrote the error response
+	}
+
+	var req ResetAlertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	slotKey, err := req.resetSlotKey()
+	if err != nil {
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.deduplication == nil {
+		utils.WriteError(w, "Alert reset is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Records are matched on the caller's user ID, so nobody can clear another user's alerts
+	cleared, err := h.deduplication.ClearSlot(ctx, userID, slotKey)
+	if err != nil {
+		utils.WriteError(w, "Failed to reset alert", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteSuccess(w, ResetAlertResponse{SlotKey: slotKey, Cleared: cleared})
+}
+
+// AlertStatsInterface aggregates a user's alert history
+type AlertStatsInterface interface {
+	UserAlertStats(ctx context.Context, userID primitive.ObjectID, now time.Time, loc *time.Location) (*database.AlertStats, error)
+}
+
+// defaultAlertStatsTimezone buckets alert stats when no timezone is given
+const defaultAlertStatsTimezone = "Europe/London"
+
+// GetAlertStats handles GET /api/alerts/stats?timezone=. It returns the
+// authenticated user's alert totals, delivery rate, per-venue breakdown,
+// per-day counts for the last 30 days and when in the day alerts arrive,
+// with days and hours in the given IANA timezone.
+func (h *NotificationHandler) GetAlertStats(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return // RequireAuth already wrote the error response
+	}
+
+	timezone := r.URL.Query().Get("timezone")
+	if timezone == "" {
+		timezone = defaultAlertStatsTimezone
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "Local" {
+		utils.WriteError(w, "timezone must be an IANA timezone such as Europe/London", http.StatusBadRequest)
+		return
+	}
+
+	if h.alertStats == nil {
+		utils.WriteError(w, "Alert stats are unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stats, err := h.alertStats.UserAlertStats(ctx, userID, time.Now(), loc)
+	if err != nil {
+		utils.WriteError(w, "Failed to compute alert stats", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteSuccess(w, stats)
+}
+
+// toAlertHistoryEntry converts a stored alert to its API representation
+func toAlertHistoryEntry(alert models.AlertHistory) AlertHistoryEntry {
+	channel := alert.Channel
+	if channel == "" {
+		channel = "email" // Alerts recorded before channels were tracked were all emails
+	}
+
+	return AlertHistoryEntry{
+		ID:         alert.ID.Hex(),
+		VenueID:    alert.VenueID,
+		VenueName:  alert.VenueName,
+		CourtName:  alert.CourtName,
+		Date:       alert.SlotDate,
+		StartTime:  alert.SlotStartTime,
+		EndTime:    alert.SlotEndTime,
+		Price:      alert.Price,
+		Currency:   alert.Currency,
+		BookingURL: alert.BookingURL,
+		Channel:    channel,
+		Status:     alert.EmailStatus,
+		SentAt:     alert.AlertSentAt,
+	}
+}
+
+// parseCappedInt parses an optional integer query parameter. Values below min are
+// rejected; values above max are capped to max.
+func parseCappedInt(value string, defaultValue, min, max int64) (int64, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if parsed < min {
+		return 0, fmt.Errorf("value %d is below the minimum of %d", parsed, min)
+	}
+	if parsed > max {
+		return max, nil
+	}
+	return parsed, nil
+}
+
+// UnsubscriberInterface turns off a user's alerts; satisfied by *models.PreferenceService
+type UnsubscriberInterface interface {
+	Unsubscribe(ctx context.Context, userID primitive.ObjectID) error
+}
+
+// unsubscribeConfirmPage is shown when the unsubscribe link from an email is
+// opened. Nothing changes until the form is submitted, so link scanners and
+// prefetchers that follow the GET can't unsubscribe anyone.
+var unsubscribeConfirmPage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>Unsubscribe</title></head>
+<body style="font-family:Arial,Helvetica,sans-serif;text-align:center;padding:48px;">
+<h1>Unsubscribe from court alerts?</h1>
+<p>You will no longer receive tennis court alerts. You can turn them back on from your notification settings.</p>
+<form method="POST" action="/api/notifications/unsubscribe">
+<input type="hidden" name="token" value="{{.}}">
+<button type="submit">Unsubscribe</button>
+</form>
+</body>
+</html>
+`))
+
+// unsubscribedPage is shown after the confirmation form is submitted
+const unsubscribedPage = `<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"><title>Unsubscribed</title></head>
+<body style="font-family:Arial,Helvetica,sans-serif;text-align:center;padding:48px;">
+<h1>You have been unsubscribed</h1>
+<p>You will no longer receive tennis court alerts. You can turn them back on from your notification settings.</p>
+</body>
+</html>
+`
+
+// Unsubscribe handles GET and POST /api/notifications/unsubscribe?token=...
+// GET comes from the link in the email footer and only renders a confirmation
+// page. POST unsubscribes: either the confirmation form, or the RFC 8058
+// one-click request mail clients send for the List-Unsubscribe-Post header.
+func (h *NotificationHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	// FormValue reads the token from the query string or, for the form, the body
+	token := r.FormValue("token")
+	if token == "" {
+		http.Error(w, "Missing unsubscribe token", http.StatusBadRequest)
+		return
+	}
+
+	userIDStr, err := h.unsubscribeTokens.ValidateToken(token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidUnsubscribeToken) || errors.Is(err, auth.ErrExpiredUnsubscribeToken) {
+			http.Error(w, "Invalid or expired unsubscribe link", http.StatusBadRequest)
+			return
+		}
+		http.Error(w, "Failed to verify unsubscribe link", http.StatusInternalServerError)
+		return
+	}
+
+	userID, err := primitive.ObjectIDFromHex(userIDStr)
+	if err != nil {
+		http.Error(w, "Invalid or expired unsubscribe link", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		unsubscribeConfirmPage.Execute(w, token)
+		return
+	}
+
+	if h.unsubscriber == nil {
+		http.Error(w, "Unsubscribe is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := h.unsubscriber.Unsubscribe(ctx, userID); err != nil {
+		http.Error(w, "Failed to unsubscribe", http.StatusInternalServerError)
+		return
+	}
+
+	if r.PostFormValue("List-Unsubscribe") == "One-Click" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, unsubscribedPage)
+}
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func setupTestNotificationHandler() (*NotificationHandler, *auth.UnsubscribeTokenService) {
+	tokens := auth.NewUnsubscribeTokenService(&MockSecretsProvider{secret: "test-secret"})
+	return NewNotificationHandler(&MockDatabase{}, tokens), tokens
+}
+
+func TestNotificationHandler_Unsubscribe_RejectsBadTokens(t *testing.T) {
+	handler, tokens := setupTestNotificationHandler()
+
+	expired, err := tokens.GenerateToken("507f1f77bcf86cd799439011", -time.Minute)
+	require.NoError(t, err)
+
+	otherSecret := auth.NewUnsubscribeTokenService(&MockSecretsProvider{secret: "other-secret"})
+	forged, err := otherSecret.GenerateToken("507f1f77bcf86cd799439011", time.Hour)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{"missing token", "/api/notifications/unsubscribe"},
+		{"malformed token", "/api/notifications/unsubscribe?token=garbage"},
+		{"expired token", "/api/notifications/unsubscribe?token=" + expired},
+		{"wrong signature", "/api/notifications/unsubscribe?token=" + forged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, method := range []string{http.MethodGet, http.MethodPost} {
+				w := httptest.NewRecorder()
+				handler.Unsubscribe(w, httptest.NewRequest(method, tt.url, nil))
+				assert.Equal(t, http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
+
+func TestNotificationHandler_Unsubscribe_RejectsNonObjectIDUser(t *testing.T) {
+	handler, tokens := setupTestNotificationHandler()
+
+	token, err := tokens.GenerateToken("not-an-object-id", time.Hour)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.Unsubscribe(w, httptest.NewRequest(http.MethodGet, "/api/notifications/unsubscribe?token="+token, nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// MockUnsubscriber records which users were unsubscribed
+type MockUnsubscriber struct {
+	unsubscribed []primitive.ObjectID
+}
+
+func (m *MockUnsubscriber) Unsubscribe(ctx context.Context, userID primitive.ObjectID) error {
+	m.unsubscribed = append(m.unsubscribed, userID)
+	return nil
+}
+
+func TestNotificationHandler_Unsubscribe(t *testing.T) {
+	handler, tokens := setupTestNotificationHandler()
+	unsubscriber := &MockUnsubscriber{}
+	handler.unsubscriber = unsubscriber
+
+	userID := primitive.NewObjectID()
+	token, err := tokens.GenerateToken(userID.Hex(), time.Hour)
+	require.NoError(t, err)
+	link := "/api/notifications/unsubscribe?token=" + token
+
+	t.Run("GET only asks for confirmation", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.Unsubscribe(w, httptest.NewRequest(http.MethodGet, link, nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `<form method="POST"`)
+		assert.Contains(t, w.Body.String(), `value="`+token+`"`)
+		assert.Empty(t, unsubscriber.unsubscribed)
+	})
+
+	t.Run("confirmation form", func(t *testing.T) {
+		unsubscriber.unsubscribed = nil
+		req := httptest.NewRequest(http.MethodPost, "/api/notifications/unsubscribe", strings.NewReader("token="+token))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.Unsubscribe(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "You have been unsubscribed")
+		assert.Equal(t, []primitive.ObjectID{userID}, unsubscriber.unsubscribed)
+	})
+
+	t.Run("one-click POST", func(t *testing.T) {
+		unsubscriber.unsubscribed = nil
+		req := httptest.NewRequest(http.MethodPost, link, strings.NewReader("List-Unsubscribe=One-Click"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		handler.Unsubscribe(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Body.String())
+		assert.Equal(t, []primitive.ObjectID{userID}, unsubscriber.unsubscribed)
+	})
+}
+
+func TestNotificationHandler_GetHistory_RequiresAuth(t *testing.T) {
+	handler, _ := setupTestNotificationHandler()
+
+	w := httptest.NewRecorder()
+	handler.GetHistory(w, httptest.NewRequest(http.MethodGet, "/api/notifications/history", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestNotificationHandler_GetHistory_ValidatesPagination(t *testing.T) {
+	handler, _ := setupTestNotificationHandler()
+	claims := &auth.AppClaims{UserID: primitive.NewObjectID().Hex(), Username: "testuser"}
+
+	for _, query := range []string{"limit=0", "limit=abc", "offset=-1"} {
+		req := httptest.NewRequest(http.MethodGet, "/api/notifications/history?"+query, nil)
+		req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+
+		w := httptest.NewRecorder()
+		handler.GetHistory(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestNotificationHandler_ResetAlert_RequiresAuth(t *testing.T) {
+	handler, _ := setupTestNotificationHandler()
+
+	w := httptest.NewRecorder()
+	handler.ResetAlert(w, httptest.NewRequest(http.MethodPost, "/api/alerts/reset", strings.NewReader(`{"slotKey":"v:c:2024-06-15:18:00"}`)))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestNotificationHandler_ResetAlert_ValidatesSlot(t *testing.T) {
+	handler, _ := setupTestNotificationHandler()
+	claims := &auth.AppClaims{UserID: primitive.NewObjectID().Hex(), Username: "testuser"}
+
+	bodies := map[string]string{
+		"invalid json":   `{`,
+		"empty":          `{}`,
+		"both forms":     `{"slotKey":"v:c:2024-06-15:18:00","venueId":"v"}`,
+		"missing court":  `{"venueId":"v","date":"2024-06-15","startTime":"18:00"}`,
+		"bad date":       `{"venueId":"v","courtId":"c","date":"15/06/2024","startTime":"18:00"}`,
+		"bad start time": `{"venueId":"v","courtId":"c","date":"2024-06-15","startTime":"6pm"}`,
+	}
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/alerts/reset", strings.NewReader(body))
+			req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+
+			w := httptest.NewRecorder()
+			handler.ResetAlert(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
+
+func TestResetAlertRequest_SlotKey(t *testing.T) {
+	key, err := ResetAlertRequest{VenueID: "v", CourtID: "c", Date: "2024-06-15", StartTime: "18:00"}.resetSlotKey()
+	require.NoError(t, err)
+	assert.Equal(t, "v:c:2024-06-15:18:00", key)
+
+	key, err = ResetAlertRequest{SlotKey: "v:c:2024-06-15:18:00"}.resetSlotKey()
+	require.NoError(t, err)
+	assert.Equal(t, "v:c:2024-06-15:18:00", key)
+}
+
+// MockAlertStats returns canned stats and records the timezone asked for
+type MockAlertStats struct {
+	userID primitive.ObjectID
+	loc    *time.Location
+}
+
+func (m *MockAlertStats) UserAlertStats(ctx context.Context, userID primitive.ObjectID, now time.Time, loc *time.Location) (*database.AlertStats, error) {
+	m.userID, m.loc = userID, loc
+	return &database.AlertStats{TotalAlerts: 4, Delivered: 3, Failed: 1, DeliveryRate: 75, Timezone: loc.String()}, nil
+}
+
+func TestNotificationHandler_GetAlertStats(t *testing.T) {
+	handler, _ := setupTestNotificationHandler()
+	stats := &MockAlertStats{}
+	handler.alertStats = stats
+	userID := primitive.NewObjectID()
+
+	statsRequest := func(query string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/api/alerts/stats"+query, nil)
+		return req.WithContext(auth.SetUserClaimsInContext(req.Context(), &auth.AppClaims{UserID: userID.Hex(), Username: "testuser"}))
+	}
+
+	w := httptest.NewRecorder()
+	handler.GetAlertStats(w, statsRequest("?timezone=America/New_York"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response database.AlertStats
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 4, response.TotalAlerts)
+	assert.Equal(t, "America/New_York", response.Timezone)
+	assert.Equal(t, userID, stats.userID)
+
+	w = httptest.NewRecorder()
+	handler.GetAlertStats(w, statsRequest(""))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, defaultAlertStatsTimezone, stats.loc.String())
+
+	for _, query := range []string{"?timezone=Mars/Olympus", "?timezone=Local"} {
+		w = httptest.NewRecorder()
+		handler.GetAlertStats(w, statsRequest(query))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	w = httptest.NewRecorder()
+	handler.GetAlertStats(w, httptest.NewRequest(http.MethodGet, "/api/alerts/stats", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestParseCappedInt(t *testing.T) {
+	value, err := parseCappedInt("", 20, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), value)
+
+	value, err = parseCappedInt("50", 20, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), value)
+
+	value, err = parseCappedInt("500", 20, 1, 100)
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), value)
+
+	_, err = parseCappedInt("0", 20, 1, 100)
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"errors"
+	"net/url"
+)
+
+// PageBounds sets the defaults and caps for a paginated list endpoint
+type PageBounds struct {
+	DefaultLimit int64
+	MaxLimit     int64 // Larger limits are capped to this
+	MaxOffset    int64 // Larger offsets are capped to this; deep pages should narrow the filter instead
+}
+
+// Pagination is the page requested by the limit and offset query parameters
+type Pagination struct {
+	Limit  int64
+	Offset int64
+}
+
+// PageInfo describes a returned page; paginated response envelopes embed it
+type PageInfo struct {
+	Limit   int64 `json:"limit"`
+	Offset  int64 `json:"offset"`
+	Total   int64 `json:"total"`
+	HasMore bool  `json:"hasMore"`
+}
+
+// parsePagination reads limit and offset from the query, applying the bounds.
+// The caller should sort on an indexed field, with _id as a tie-breaker, so
+// that pages don't shift between requests.
+func parsePagination(query url.Values, bounds PageBounds) (Pagination, error) {
+	limit, err := parseCappedInt(query.Get("limit"), bounds.DefaultLimit, 1, bounds.MaxLimit)
+	if err != nil {
+		return Pagination{}, errors.New("limit must be a positive integer")
+	}
+	offset, err := parseCappedInt(query.Get("offset"), 0, 0, bounds.MaxOffset)
+	if err != nil {
+		return Pagination{}, errors.New("offset must be a non-negative integer")
+	}
+	return Pagination{Limit: limit, Offset: offset}, nil
+}
+
+// PageInfo describes the page of results returned for p out of total matches
+func (p Pagination) PageInfo(total int64) PageInfo {
+	return PageInfo{
+		Limit:   p.Limit,
+		Offset:  p.Offset,
+		Total:   total,
+		HasMore: p.Offset+p.Limit < total,
+	}
+}
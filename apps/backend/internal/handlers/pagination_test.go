@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePagination(t *testing.T) {
+	bounds := PageBounds{DefaultLimit: 20, MaxLimit: 100, MaxOffset: 1000}
+
+	page, err := parsePagination(url.Values{}, bounds)
+	require.NoError(t, err)
+	assert.Equal(t, Pagination{Limit: 20, Offset: 0}, page)
+
+	page, err = parsePagination(url.Values{"limit": {"500"}, "offset": {"5000"}}, bounds)
+	require.NoError(t, err)
+	assert.Equal(t, Pagination{Limit: 100, Offset: 1000}, page, "limit and offset are capped")
+
+	for _, query := range []url.Values{
+		{"limit": {"0"}},
+		{"limit": {"ten"}},
+		{"offset": {"-1"}},
+	} {
+		_, err := parsePagination(query, bounds)
+		assert.Error(t, err, query.Encode())
+	}
+}
+
+func TestPagination_PageInfo(t *testing.T) {
+	page := Pagination{Limit: 20, Offset: 40}
+
+	assert.Equal(t, PageInfo{Limit: 20, Offset: 40, Total: 61, HasMore: true}, page.PageInfo(61))
+	assert.False(t, page.PageInfo(60).HasMore)
+	assert.False(t, page.PageInfo(0).HasMore)
+}
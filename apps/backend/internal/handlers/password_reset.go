@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// minPasswordLength is the shortest password accepted when setting a password
+const minPasswordLength = 8
+
+// forgotPasswordMessage is returned whether or not the email belongs to an account,
+// so the endpoint can't be used to find out who is registered
+const forgotPasswordMessage = "If an account exists for that email, a password reset link has been sent"
+
+// Mailer sends plain-text emails
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// ForgotPasswordRequest represents a forgot-password request
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResetPasswordRequest represents a reset-password request
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,min=8"`
+}
+
+// SetPasswordResetMailer enables the forgot-password flow. Reset links point at
+// resetURLBase + "/reset-password?token=...".
+func (h *AuthHandler) SetPasswordResetMailer(mailer Mailer, resetURLBase string) {
+	h.resetMailer = mailer
+	h.resetURLBase = strings.TrimRight(resetURLBase, "/")
+}
+
+// ForgotPassword handles POST /api/auth/forgot-password. It emails a single-use
+// reset link to the account with that email, if there is one, and responds the
+// same way either way.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" {
+		utils.WriteError(w, "Email is required", http.StatusBadRequest)
+		return
+	}
+
+	if h.passwordResets == nil || h.resetMailer == nil {
+		utils.WriteError(w, "Password reset is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Look up the account and send the email in the background so the response
+	// time doesn't reveal whether the email is registered
+	go h.sendPasswordResetEmail(r.Context(), email)
+
+	utils.WriteSuccess(w, map[string]string{"message": forgotPasswordMessage})
+}
+
+// sendPasswordResetEmail creates a reset token for the account with the given
+// email and emails the reset link. Unknown emails are silently ignored.
+func (h *AuthHandler) sendPasswordResetEmail(requestCtx context.Context, email string) {
+	ctx, cancel := utils.WithDBTimeoutFrom(requestCtx)
+	defer cancel()
+
+	// Accounts keep the email's case from registration, so match it case-insensitively
+	var user models.User
+	err := h.db.Collection("users").FindOne(ctx, bson.M{"email": email}, options.FindOne().SetCollation(caseInsensitiveCollation)).Decode(&user)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			authLogger.WithContext(ctx).Error("Password reset: failed to look up user", map[string]interface{}{"email": email, "error": err.Error()})
+		}
+		return
+	}
+
+	token, err := h.passwordResets.CreateResetToken(ctx, user.ID, models.PasswordResetTokenTTL)
+	if err != nil {
+		authLogger.WithContext(ctx).Error("Password reset: failed to create reset token", map[string]interface{}{"user_id": user.ID.Hex(), "error": err.Error()})
+		return
+	}
+
+	resetLink := fmt.Sprintf("%s/reset-password?token=%s", h.resetURLBase, url.QueryEscape(token))
+	body := fmt.Sprintf(`Hi %s,
+
+We received a request to reset the password for your Tennis Booker account.
+
+Reset your password: %s
+
+This link expires in 1 hour and can only be used once. If you didn't ask to reset
+your password, you can ignore this email and your password won't change.
+`, user.Name, resetLink)
+
+	if err := h.resetMailer.Send(user.Email, "Reset your Tennis Booker password", body); err != nil {
+		authLogger.WithContext(ctx).Error("Password reset: failed to send email", map[string]interface{}{"user_id": user.ID.Hex(), "error": err.Error()})
+	}
+}
+
+// ResetPassword handles POST /api/auth/reset-password. It sets a new password
+// using a token from a reset email, then invalidates the token and any other
+// outstanding reset tokens. Refresh tokens issued before the change stop working
+// because RefreshToken checks them against the password change time.
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Token == "" {
+		utils.WriteError(w, "Reset token is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.NewPassword) < minPasswordLength {
+		utils.WriteError(w, fmt.Sprintf("Password must be at least %d characters", minPasswordLength), http.StatusBadRequest)
+		return
+	}
+
+	if h.passwordResets == nil {
+		utils.WriteError(w, "Password reset is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := utils.WithDBTimeoutFrom(r.Context())
+	defer cancel()
+
+	hashedPassword, err := h.passwords.HashPassword(ctx, req.NewPassword)
+	if err != nil {
+		utils.WriteError(w, "Failed to hash password", http.StatusInternalServerError)
+		return
+	}
+
+	// The token is only used up if the password is actually changed
+	var userID primitive.ObjectID
+	err = h.passwordResets.ConsumeResetToken(ctx, req.Token, func(id primitive.ObjectID) error {
+		userID = id
+		now := time.Now()
+		_, err := h.db.Collection("users").UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+			"$set": bson.M{
+				"hashed_password":     hashedPassword,
+				"password_changed_at": now,
+				"updated_at":          now,
+			},
+		})
+		return err
+	})
+	if err != nil {
+		if err == models.ErrInvalidPasswordResetToken {
+			utils.WriteError(w, "Invalid or expired reset token", http.StatusBadRequest)
+			return
+		}
+		utils.WriteError(w, "Failed to update password", http.StatusInternalServerError)
+		return
+	}
+
+	// The password has changed, so failing to clean up is logged rather than reported
+	if err := h.passwordResets.InvalidateUserResetTokens(ctx, userID); err != nil {
+		authLogger.WithContext(ctx).Error("Password reset: failed to invalidate reset tokens", map[string]interface{}{"user_id": userID.Hex(), "error": err.Error()})
+	}
+
+	utils.WriteSuccess(w, map[string]string{"message": "Password has been reset"})
+}
+
+// caseInsensitiveCollation compares strings ignoring case, e.g. to find an account by email
+var caseInsensitiveCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// issuedBeforePasswordChange reports whether a token predates the user's last
+// password change. JWT timestamps have one-second precision, so the change time
+// is truncated to match, and a token from the same second counts as older: it
+// may have been issued just before the change.
+func issuedBeforePasswordChange(claims *auth.AppClaims, user models.User) bool {
+	if user.PasswordChangedAt == nil || claims.IssuedAt == nil {
+		return false
+	}
+	return !claims.IssuedAt.Time.After(user.PasswordChangedAt.Truncate(time.Second))
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/models"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingMailer captures sent emails
+type recordingMailer struct {
+	sent []string
+}
+
+func (m *recordingMailer) Send(to, subject, body string) error {
+	m.sent = append(m.sent, to)
+	return nil
+}
+
+func postJSON(handler http.HandlerFunc, path string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+func TestAuthHandler_ForgotPassword_Validation(t *testing.T) {
+	jwtService := auth.NewJWTService(&MockSecretsProvider{secret: "test-secret-key"}, "test-issuer")
+	handler := NewAuthHandler(jwtService, NewMockDatabase())
+	handler.SetPasswordResetMailer(&recordingMailer{}, "http://localhost:3000/")
+
+	w := postJSON(handler.ForgotPassword, "/api/auth/forgot-password", ForgotPasswordRequest{Email: " "})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/forgot-password", bytes.NewBufferString("{"))
+	w = httptest.NewRecorder()
+	handler.ForgotPassword(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Without a database the flow is unavailable
+	w = postJSON(handler.ForgotPassword, "/api/auth/forgot-password", ForgotPasswordRequest{Email: "player@example.com"})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestAuthHandler_ResetPassword_Validation(t *testing.T) {
+	jwtService := auth.NewJWTService(&MockSecretsProvider{secret: "test-secret-key"}, "test-issuer")
+	handler := NewAuthHandler(jwtService, NewMockDatabase())
+
+	tests := []struct {
+		name string
+		req  ResetPasswordRequest
+		code int
+	}{
+		{"missing token", ResetPasswordRequest{NewPassword: "long-enough-password"}, http.StatusBadRequest},
+		{"short password", ResetPasswordRequest{Token: "abc", NewPassword: "short"}, http.StatusBadRequest},
+		{"no database", ResetPasswordRequest{Token: "abc", NewPassword: "long-enough-password"}, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := postJSON(handler.ResetPassword, "/api/auth/reset-password", tt.req)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
+
+func TestIssuedBeforePasswordChange(t *testing.T) {
+	changedAt := time.Date(2024, 6, 15, 12, 0, 0, 500*int(time.Millisecond), time.UTC)
+	user := models.User{PasswordChangedAt: &changedAt}
+
+	claims := func(issuedAt time.Time) *auth.AppClaims {
+		return &auth.AppClaims{RegisteredClaims: jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(issuedAt)}}
+	}
+
+	assert.True(t, issuedBeforePasswordChange(claims(changedAt.Add(-time.Hour)), user))
+	// Issued in the same second as the change (JWT times are whole seconds), so
+	// possibly before it
+	assert.True(t, issuedBeforePasswordChange(claims(changedAt), user))
+	assert.True(t, issuedBeforePasswordChange(claims(changedAt.Truncate(time.Second)), user))
+	assert.False(t, issuedBeforePasswordChange(claims(changedAt.Truncate(time.Second).Add(time.Second)), user))
+	assert.False(t, issuedBeforePasswordChange(claims(changedAt.Add(time.Minute)), user))
+
+	// Never changed
+	assert.False(t, issuedBeforePasswordChange(claims(changedAt.Add(-time.Hour)), models.User{}))
+}
@@ -0,0 +1,61 @@
+//go:build integration
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/database"
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/testenv"
+)
+
+func TestIntegration_UpdatePreferences_KeepsSnooze(t *testing.T) {
+	env, cleanup := testenv.SetupTestEnv(t)
+	defer cleanup()
+
+	jwtService := auth.NewJWTService(&MockSecretsProvider{secret: "test-secret"}, "HS256")
+	userHandler := NewUserHandler(database.NewMongoDB(env.DB), jwtService)
+
+	userID := primitive.NewObjectID()
+	snoozeUntil := time.Now().Add(7 * 24 * time.Hour).UTC().Truncate(time.Millisecond)
+	preferences := defaultUserPreferences(userID)
+	preferences.NotificationSettings.SnoozeUntil = &snoozeUntil
+	_, err := env.DB.Collection("user_preferences").InsertOne(context.Background(), preferences)
+	require.NoError(t, err)
+
+	claims := &auth.AppClaims{UserID: userID.Hex(), Username: "testuser"}
+	update := func(body string) {
+		req := httptest.NewRequest(http.MethodPut, "/api/users/preferences", bytes.NewBufferString(body))
+		req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+		w := httptest.NewRecorder()
+		userHandler.UpdatePreferences(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+	storedSnooze := func() *time.Time {
+		var stored models.UserPreferences
+		require.NoError(t, env.DB.Collection("user_preferences").FindOne(context.Background(), bson.M{"user_id": userID}).Decode(&stored))
+		return stored.NotificationSettings.SnoozeUntil
+	}
+
+	// Leaving the snooze out doesn't cancel it
+	update(`{"maxPrice": 30, "notificationSettings": {"email": true}}`)
+	require.NotNil(t, storedSnooze())
+	assert.True(t, snoozeUntil.Equal(*storedSnooze()))
+
+	// Nor can a client set it past the snooze endpoint's limits
+	update(`{"maxPrice": 30, "notificationSettings": {"email": true, "snooze_until": "2099-01-01T00:00:00Z"}}`)
+	require.NotNil(t, storedSnooze())
+	assert.True(t, snoozeUntil.Equal(*storedSnooze()))
+}
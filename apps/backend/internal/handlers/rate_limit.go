@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"tennis-booker/internal/ratelimit"
+	"tennis-booker/internal/utils"
+
+	"github.com/gorilla/mux"
+)
+
+// RateLimitStateProvider reads the live state of a rate limit key
+type RateLimitStateProvider interface {
+	GetLimitState(ctx context.Context, key string) (limit, remaining int, resetAt time.Time, err error)
+}
+
+// RateLimitStateResponse represents the state of one rate limit key
+type RateLimitStateResponse struct {
+	Key       string    `json:"key"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// RateLimitHandler handles rate limit inspection requests
+type RateLimitHandler struct {
+	limiter RateLimitStateProvider
+}
+
+// NewRateLimitHandler creates a new rate limit handler
+func NewRateLimitHandler(limiter RateLimitStateProvider) *RateLimitHandler {
+	return &RateLimitHandler{
+		limiter: limiter,
+	}
+}
+
+// GetLimitState handles GET /api/system/rate-limit-status/{key}, where key is a
+// limiter type and identifier such as "ip:203.0.113.1" or "user:<id>". Reading the
+// state doesn't count as a request against the limit. Admins only.
+func (h *RateLimitHandler) GetLimitState(w http.ResponseWriter, r *http.Request) {
+	if !utils.RequireAdmin(w, r) {
+		return
+	}
+
+	key := mux.Vars(r)["key"]
+
+	ctx, cancel := utils.WithStandardTimeout()
+	defer cancel()
+
+	limit, remaining, resetAt, err := h.limiter.GetLimitState(ctx, key)
+	if err != nil {
+		if errors.Is(err, ratelimit.ErrInvalidLimitKey) {
+			utils.WriteError(w, "Invalid rate limit key, expected <type>:<identifier>", http.StatusBadRequest)
+			return
+		}
+		utils.WriteError(w, "Failed to read rate limit state", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteSuccess(w, RateLimitStateResponse{
+		Key:       key,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetAt:   resetAt,
+	})
+}
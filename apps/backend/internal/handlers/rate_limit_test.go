@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/ratelimit"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubLimitState returns a fixed rate limit state
+type stubLimitState struct {
+	resetAt time.Time
+	err     error
+	key     string
+}
+
+func (s *stubLimitState) GetLimitState(ctx context.Context, key string) (int, int, time.Time, error) {
+	s.key = key
+	return 100, 42, s.resetAt, s.err
+}
+
+func rateLimitStatusRequest(handler *RateLimitHandler, key string, claims *auth.AppClaims) *httptest.ResponseRecorder {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/system/rate-limit-status/{key}", handler.GetLimitState)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/system/rate-limit-status/"+key, nil)
+	if claims != nil {
+		req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitHandler_GetLimitState(t *testing.T) {
+	resetAt := time.Date(2025, 6, 9, 12, 0, 0, 0, time.UTC)
+	state := &stubLimitState{resetAt: resetAt}
+	handler := NewRateLimitHandler(state)
+	admin := &auth.AppClaims{UserID: "admin-1", Role: auth.RoleAdmin}
+
+	w := rateLimitStatusRequest(handler, "ip:203.0.113.1", admin)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "ip:203.0.113.1", state.key)
+
+	var response RateLimitStateResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, RateLimitStateResponse{Key: "ip:203.0.113.1", Limit: 100, Remaining: 42, ResetAt: resetAt}, response)
+}
+
+func TestRateLimitHandler_GetLimitState_AdminOnly(t *testing.T) {
+	handler := NewRateLimitHandler(&stubLimitState{})
+
+	w := rateLimitStatusRequest(handler, "ip:203.0.113.1", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = rateLimitStatusRequest(handler, "ip:203.0.113.1", &auth.AppClaims{UserID: "user-1"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestRateLimitHandler_GetLimitState_Errors(t *testing.T) {
+	admin := &auth.AppClaims{UserID: "admin-1", Role: auth.RoleAdmin}
+
+	handler := NewRateLimitHandler(&stubLimitState{err: fmt.Errorf("%w: %q", ratelimit.ErrInvalidLimitKey, "bogus")})
+	w := rateLimitStatusRequest(handler, "bogus", admin)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	handler = NewRateLimitHandler(&stubLimitState{err: errors.New("redis down")})
+	w = rateLimitStatusRequest(handler, "ip:203.0.113.1", admin)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
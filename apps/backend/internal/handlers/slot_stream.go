@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/database"
+	"tennis-booker/internal/logging"
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/retention"
+	"tennis-booker/internal/utils"
+)
+
+const (
+	slotStreamSendBuffer   = 32               // Messages queued for a client before new ones are dropped
+	slotStreamWriteWait    = 10 * time.Second // Time allowed to write one message
+	slotStreamPongWait     = 60 * time.Second // Time allowed between messages or pongs from the client
+	slotStreamPingInterval = 30 * time.Second // Must be less than slotStreamPongWait
+	maxSlotStreamRequest   = 4 * 1024
+)
+
+// slotStreamUpgrader accepts connections from any origin: clients authenticate
+// with a token in the query string rather than a cookie, so another site can't
+// open a stream as the user.
+var slotStreamUpgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// Slot stream message types
+const (
+	SlotStreamSubscribe    = "subscribe"    // Client: set or replace the connection's filters
+	SlotStreamSubscribed   = "subscribed"   // Server: filters accepted, events follow
+	SlotStreamAvailability = "availability" // Server: a slot matching the filters became available
+	SlotStreamLagged       = "lagged"       // Server: messages were dropped because the client fell behind
+	SlotStreamError        = "error"        // Server: the last client message was rejected
+)
+
+// streamLogger records events on the streaming endpoints
+var streamLogger = logging.New("tennis-server")
+
+// AvailabilitySubscriberInterface subscribes to live court availability events
+// until ctx is done, when the returned channel is closed
+type AvailabilitySubscriberInterface interface {
+	SubscribeAvailability(ctx context.Context) (<-chan models.CourtAvailabilityEvent, error)
+}
+
+// PreferenceReaderInterface loads a user's saved preferences
+type PreferenceReaderInterface interface {
+	GetUserPreferences(ctx context.Context, userID primitive.ObjectID) (*models.UserPreferences, error)
+}
+
+// SlotStreamHandler pushes court availability to WebSocket clients as the scraper finds it
+type SlotStreamHandler struct {
+	jwtService  *auth.JWTService
+	preferences PreferenceReaderInterface
+	subscriber  AvailabilitySubscriberInterface
+	closing     chan struct{}
+	closeOnce   sync.Once
+}
+
+// NewSlotStreamHandler creates a new slot stream handler. Streaming is
+// unavailable until SetAvailabilitySubscriber is called.
+func NewSlotStreamHandler(db database.Database, jwtService *auth.JWTService) *SlotStreamHandler {
+	h := &SlotStreamHandler{
+		jwtService: jwtService,
+		closing:    make(chan struct{}),
+	}
+	if mongoDB := db.GetMongoDB(); mongoDB != nil {
+		h.preferences = models.NewPreferenceService(mongoDB)
+	}
+	return h
+}
+
+// SetAvailabilitySubscriber sets where availability events come from
+func (h *SlotStreamHandler) SetAvailabilitySubscriber(subscriber AvailabilitySubscriberInterface) {
+	h.subscriber = subscriber
+}
+
+// Close disconnects every client with a "going away" close, for server shutdown
+func (h *SlotStreamHandler) Close() {
+	h.closeOnce.Do(func() { close(h.closing) })
+}
+
+// SlotStreamRequest is sent by the client to choose which events it receives.
+// Events must match both these filters and the user's saved preferences; empty
+// filters don't narrow anything.
+type SlotStreamRequest struct {
+	Type     string             `json:"type"`
+	Venues   []string           `json:"venues,omitempty"` // Venue IDs
+	Days     []string           `json:"days,omitempty"`   // Lowercase weekday names
+	Times    []models.TimeRange `json:"times,omitempty"`
+	MaxPrice float64            `json:"maxPrice,omitempty"`
+}
+
+// SlotStreamMessage is sent by the server
+type SlotStreamMessage struct {
+	Type    string                         `json:"type"`
+	Event   *models.CourtAvailabilityEvent `json:"event,omitempty"`
+	Filters *SlotStreamRequest             `json:"filters,omitempty"`
+	Dropped int                            `json:"dropped,omitempty"`
+	Message string                         `json:"message,omitempty"`
+}
+
+// StreamSlots handles GET /api/ws/slots?token=<access token>. Browsers can't set
+// headers on WebSocket requests, so the JWT comes in the query string. Once the
+// client sends a SlotStreamRequest, matching availability events are pushed as
+// they are published until either side disconnects.
+func (h *SlotStreamHandler) StreamSlots(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		utils.WriteError(w, "token query parameter required", http.StatusUnauthorized)
+		return
+	}
+	claims, err := h.jwtService.ValidateToken(token)
+	if err != nil {
+		utils.WriteError(w, "Invalid or expired token", http.StatusUnauthorized)
+		return
+	}
+	userID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		utils.WriteError(w, "Invalid user ID", http.StatusUnauthorized)
+		return
+	}
+
+	if h.subscriber == nil || h.preferences == nil {
+		utils.WriteError(w, "Live availability is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	dbCtx, dbCancel := utils.WithDBTimeoutFrom(r.Context())
+	preferences, err := h.preferences.GetUserPreferences(dbCtx, userID)
+	dbCancel()
+	if err != nil {
+		streamLogger.WithContext(r.Context()).Error("Slot stream: failed to load preferences", map[string]interface{}{"user_id": claims.UserID, "error": err.Error()})
+		utils.WriteError(w, "Failed to load preferences", http.StatusInternalServerError)
+		return
+	}
+
+	// Cancelling ctx releases the subscription
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	events, err := h.subscriber.SubscribeAvailability(ctx)
+	if err != nil {
+		streamLogger.WithContext(ctx).Error("Slot stream: failed to subscribe to availability events", map[string]interface{}{"error": err.Error()})
+		utils.WriteError(w, "Live availability is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	conn, err := slotStreamUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade has already responded
+	}
+	defer conn.Close()
+
+	stream := &slotStream{
+		conn:        conn,
+		preferences: *preferences,
+		send:        make(chan []byte, slotStreamSendBuffer),
+	}
+
+	var writer sync.WaitGroup
+	writer.Add(1)
+	go func() {
+		defer writer.Done()
+		stream.writeMessages(ctx, cancel)
+	}()
+	go stream.readRequests(cancel)
+
+	closeCode := stream.forward(ctx, events, h.closing)
+	cancel()
+	writer.Wait()
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, ""), time.Now().Add(time.Second))
+}
+
+// slotStream is one client's connection
+type slotStream struct {
+	conn        *websocket.Conn
+	preferences models.UserPreferences
+	filters     atomic.Pointer[SlotStreamRequest] // Nil until the client subscribes
+
+	send    chan []byte
+	sendMu  sync.Mutex
+	dropped int // Messages dropped since the client last kept up
+}
+
+// forward pushes matching events until the connection ends, returning the close code to send
+func (s *slotStream) forward(ctx context.Context, events <-chan models.CourtAvailabilityEvent, closing <-chan struct{}) int {
+	for {
+		select {
+		case <-ctx.Done():
+			return websocket.CloseNormalClosure
+		case <-closing:
+			return websocket.CloseGoingAway
+		case event, ok := <-events:
+			if !ok {
+				// The event source went away; the client should reconnect
+				return websocket.CloseTryAgainLater
+			}
+			filters := s.filters.Load()
+			if filters == nil || !slotStreamMatches(event, s.preferences, filters) {
+				continue
+			}
+			s.enqueue(SlotStreamMessage{Type: SlotStreamAvailability, Event: &event})
+		}
+	}
+}
+
+// readRequests applies filter requests from the client and keeps the read
+// deadline moving while it is alive. Any read error ends the stream.
+func (s *slotStream) readRequests(cancel context.CancelFunc) {
+	defer cancel()
+
+	s.conn.SetReadLimit(maxSlotStreamRequest)
+	s.conn.SetReadDeadline(time.Now().Add(slotStreamPongWait))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(slotStreamPongWait))
+	})
+
+	for {
+		_, message, err := s.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.conn.SetReadDeadline(time.Now().Add(slotStreamPongWait))
+
+		request, err := parseSlotStreamRequest(message)
+		if err != nil {
+			s.enqueue(SlotStreamMessage{Type: SlotStreamError, Message: err.Error()})
+			continue
+		}
+		s.filters.Store(request)
+		s.enqueue(SlotStreamMessage{Type: SlotStreamSubscribed, Filters: request})
+	}
+}
+
+// writeMessages sends queued messages and keep-alive pings until the stream
+// ends. A client that can't take a message within slotStreamWriteWait is dropped.
+func (s *slotStream) writeMessages(ctx context.Context, cancel context.CancelFunc) {
+	defer cancel()
+
+	ticker := time.NewTicker(slotStreamPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-s.send:
+			s.conn.SetWriteDeadline(time.Now().Add(slotStreamWriteWait))
+			if err := s.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(slotStreamWriteWait)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// enqueue queues a message without blocking. When the client has fallen behind
+// messages are dropped, and once there is room again a "lagged" message tells
+// the client how many it missed so it can refetch /api/courts.
+func (s *slotStream) enqueue(message SlotStreamMessage) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	if s.dropped > 0 {
+		lagged, _ := json.Marshal(SlotStreamMessage{Type: SlotStreamLagged, Dropped: s.dropped})
+		select {
+		case s.send <- lagged:
+			s.dropped = 0
+		default:
+			s.dropped++
+			return
+		}
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+	select {
+	case s.send <- data:
+	default:
+		s.dropped++
+	}
+}
+
+// parseSlotStreamRequest decodes and validates a client request
+func parseSlotStreamRequest(message []byte) (*SlotStreamRequest, error) {
+	var request SlotStreamRequest
+	if err := json.Unmarshal(message, &request); err != nil {
+		return nil, errors.New("invalid JSON")
+	}
+	if request.Type != SlotStreamSubscribe {
+		return nil, errors.New(`unknown message type, expected "subscribe"`)
+	}
+
+	filters := request.asPreferences()
+	if errs := filters.Validate(); len(errs) > 0 {
+		return nil, errs
+	}
+	return &request, nil
+}
+
+// asPreferences expresses the filters as preferences, for the shared slot matcher
+func (r *SlotStreamRequest) asPreferences() models.UserPreferences {
+	return models.UserPreferences{
+		PreferredVenues: r.Venues,
+		PreferredDays:   r.Days,
+		Times:           r.Times,
+		MaxPrice:        r.MaxPrice,
+	}
+}
+
+// slotStreamMatches reports whether an event matches both the user's saved
+// preferences and the connection's filters, using the same matcher that
+// decides which slots the retention service keeps. Events for slots that
+// were just booked never match.
+func slotStreamMatches(event models.CourtAvailabilityEvent, preferences models.UserPreferences, filters *SlotStreamRequest) bool {
+	if !event.IsAvailable() {
+		return false
+	}
+	slot := availabilityEventSlot(event)
+	for _, pref := range []models.UserPreferences{preferences, filters.asPreferences()} {
+		matches, err := retention.DoesSlotMatchActivePreferences(slot, []models.UserPreferences{pref})
+		if err != nil || !matches {
+			return false
+		}
+	}
+	return true
+}
+
+// availabilityEventSlot converts an event into the slot the matcher expects.
+// Venue IDs that aren't ObjectIDs are left zero and match no venue filter.
+func availabilityEventSlot(event models.CourtAvailabilityEvent) models.CourtSlot {
+	venueID, _ := primitive.ObjectIDFromHex(event.VenueID)
+	return models.CourtSlot{
+		VenueID:    venueID,
+		VenueName:  event.VenueName,
+		CourtID:    event.CourtID,
+		CourtName:  event.CourtName,
+		Date:       event.Date,
+		StartTime:  event.StartTime,
+		EndTime:    event.EndTime,
+		Price:      event.Price,
+		Currency:   event.Currency,
+		BookingURL: event.BookingURL,
+		Available:  true,
+	}
+}
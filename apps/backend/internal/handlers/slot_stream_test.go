@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/models"
+)
+
+// MockAvailabilitySubscriber hands out a channel the test publishes on
+type MockAvailabilitySubscriber struct {
+	events     chan models.CourtAvailabilityEvent
+	subscribed chan context.Context
+}
+
+func (m *MockAvailabilitySubscriber) SubscribeAvailability(ctx context.Context) (<-chan models.CourtAvailabilityEvent, error) {
+	m.subscribed <- ctx
+	return m.events, nil
+}
+
+// MockPreferenceReader returns the same preferences for every user
+type MockPreferenceReader struct {
+	preferences models.UserPreferences
+}
+
+func (m *MockPreferenceReader) GetUserPreferences(ctx context.Context, userID primitive.ObjectID) (*models.UserPreferences, error) {
+	prefs := m.preferences
+	return &prefs, nil
+}
+
+// wsTestClient is a WebSocket client for exercising the stream
+type wsTestClient struct {
+	conn *websocket.Conn
+}
+
+func dialSlotStream(t *testing.T, server *httptest.Server, token string) *wsTestClient {
+	t.Helper()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/ws/slots?token=" + token
+	conn, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+
+	return &wsTestClient{conn: conn}
+}
+
+func (c *wsTestClient) send(t *testing.T, message interface{}) {
+	t.Helper()
+	require.NoError(t, c.conn.WriteJSON(message))
+}
+
+func (c *wsTestClient) receive(t *testing.T) SlotStreamMessage {
+	t.Helper()
+
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	messageType, payload, err := c.conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, websocket.TextMessage, messageType)
+
+	var message SlotStreamMessage
+	require.NoError(t, json.Unmarshal(payload, &message))
+	return message
+}
+
+func newTestSlotStreamHandler(t *testing.T, preferences models.UserPreferences) (*SlotStreamHandler, *MockAvailabilitySubscriber, string) {
+	jwtService := auth.NewJWTService(&MockSecretsProvider{secret: "test-secret-key"}, "test-issuer")
+	token, err := jwtService.GenerateToken(primitive.NewObjectID().Hex(), "player", time.Hour)
+	require.NoError(t, err)
+
+	subscriber := &MockAvailabilitySubscriber{
+		events:     make(chan models.CourtAvailabilityEvent),
+		subscribed: make(chan context.Context, 1),
+	}
+	handler := NewSlotStreamHandler(NewMockDatabase(), jwtService)
+	handler.preferences = &MockPreferenceReader{preferences: preferences}
+	handler.SetAvailabilitySubscriber(subscriber)
+	return handler, subscriber, token
+}
+
+func TestSlotStreamHandler_RequiresToken(t *testing.T) {
+	handler, _, _ := newTestSlotStreamHandler(t, models.UserPreferences{})
+
+	for _, target := range []string{"/api/ws/slots", "/api/ws/slots?token=not-a-jwt"} {
+		w := httptest.NewRecorder()
+		handler.StreamSlots(w, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code, target)
+	}
+}
+
+func TestSlotStreamHandler_PushesMatchingEvents(t *testing.T) {
+	closedClubID := primitive.NewObjectID().Hex()
+	handler, subscriber, token := newTestSlotStreamHandler(t, models.UserPreferences{
+		ExcludedVenues: []string{closedClubID},
+	})
+	server := httptest.NewServer(http.HandlerFunc(handler.StreamSlots))
+	defer server.Close()
+
+	client := dialSlotStream(t, server, token)
+	subscriptionCtx := <-subscriber.subscribed
+
+	client.send(t, map[string]interface{}{"type": "subscribe", "times": []models.TimeRange{{Start: "09:00"}}})
+	assert.Equal(t, SlotStreamError, client.receive(t).Type)
+
+	client.send(t, SlotStreamRequest{Type: SlotStreamSubscribe, Times: []models.TimeRange{{Start: "18:00", End: "21:00"}}})
+	subscribed := client.receive(t)
+	require.Equal(t, SlotStreamSubscribed, subscribed.Type)
+	assert.Equal(t, []models.TimeRange{{Start: "18:00", End: "21:00"}}, subscribed.Filters.Times)
+
+	evening := models.CourtAvailabilityEvent{VenueName: "Victoria Park", CourtName: "Court 1", Date: "2026-10-20", StartTime: "19:00", EndTime: "20:00"}
+	morning := evening
+	morning.StartTime, morning.EndTime = "08:00", "09:00"
+	excluded := evening
+	excluded.VenueID, excluded.VenueName = closedClubID, "Closed Club"
+	booked := evening
+	booked.Available = new(bool)
+	late := evening
+	late.CourtName = "Court 2"
+
+	for _, event := range []models.CourtAvailabilityEvent{evening, morning, excluded, booked, late} {
+		subscriber.events <- event
+	}
+
+	for _, want := range []string{"Court 1", "Court 2"} {
+		message := client.receive(t)
+		require.Equal(t, SlotStreamAvailability, message.Type)
+		assert.Equal(t, "Victoria Park", message.Event.VenueName)
+		assert.Equal(t, want, message.Event.CourtName)
+	}
+
+	// Disconnecting releases the subscription
+	client.conn.Close()
+	select {
+	case <-subscriptionCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not cancelled after the client disconnected")
+	}
+}
+
+func TestSlotStream_EnqueueDropsWhenClientLags(t *testing.T) {
+	stream := &slotStream{send: make(chan []byte, 2)}
+
+	for i := 0; i < 5; i++ {
+		stream.enqueue(SlotStreamMessage{Type: SlotStreamAvailability})
+	}
+	assert.Equal(t, 3, stream.dropped)
+
+	// Once the client catches up it is told how many messages it missed
+	<-stream.send
+	<-stream.send
+	stream.enqueue(SlotStreamMessage{Type: SlotStreamAvailability})
+
+	var lagged SlotStreamMessage
+	require.NoError(t, json.Unmarshal(<-stream.send, &lagged))
+	assert.Equal(t, SlotStreamLagged, lagged.Type)
+	assert.Equal(t, 3, lagged.Dropped)
+	assert.Equal(t, 0, stream.dropped)
+	assert.Len(t, stream.send, 1)
+}
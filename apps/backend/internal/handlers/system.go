@@ -5,10 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
+	"sync"
 	"time"
 
 	"tennis-booker/internal/database"
+	"tennis-booker/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -42,16 +43,79 @@ type SystemControlResponse struct {
 	Status  string `json:"status"`
 }
 
+// FeatureFlagsInterface reports the state of every feature flag; satisfied by config.Live
+type FeatureFlagsInterface interface {
+	FeatureFlags() map[string]bool
+}
+
+// FeaturesResponse is the response for GET /api/system/features
+type FeaturesResponse struct {
+	Features map[string]bool `json:"features"`
+}
+
 // SystemHandler handles system control requests
 type SystemHandler struct {
-	db database.Database
+	db             database.Database
+	features       FeatureFlagsInterface
+	scrapeOutcomes ScrapeOutcomeSourceInterface
+
+	eventPollInterval time.Duration // How often GET /api/system/events checks for new scrapes
+	eventPingInterval time.Duration // How often it sends a keep-alive comment
+	closing           chan struct{}
+	closeOnce         sync.Once
 }
 
 // NewSystemHandler creates a new system handler
 func NewSystemHandler(db database.Database) *SystemHandler {
-	return &SystemHandler{
-		db: db,
+	h := &SystemHandler{
+		db:                db,
+		eventPollInterval: defaultEventPollInterval,
+		eventPingInterval: defaultEventPingInterval,
+		closing:           make(chan struct{}),
 	}
+	if mongoDB := db.GetMongoDB(); mongoDB != nil {
+		h.scrapeOutcomes = database.NewScrapingLogRepository(mongoDB)
+	}
+	return h
+}
+
+// SetFeatureFlags enables GET /api/system/features
+func (h *SystemHandler) SetFeatureFlags(features FeatureFlagsInterface) {
+	h.features = features
+}
+
+// GetFeatures handles GET /api/system/features, so the frontend can hide
+// parts of the UI whose endpoints are switched off
+func (h *SystemHandler) GetFeatures(w http.ResponseWriter, r *http.Request) {
+	response := FeaturesResponse{Features: map[string]bool{}}
+	if h.features != nil {
+		response.Features = h.features.FeatureFlags()
+	}
+	utils.WriteSuccess(w, response)
+}
+
+// scrapingLogPageBounds paginates GET /api/system/logs
+var scrapingLogPageBounds = PageBounds{DefaultLimit: 50, MaxLimit: 200, MaxOffset: 10000}
+
+// ScrapingLogResponse is a scraping log as returned by GET /api/system/logs
+type ScrapingLogResponse struct {
+	ID               string    `json:"id"`
+	VenueID          string    `json:"venueId"`
+	VenueName        string    `json:"venueName"`
+	Provider         string    `json:"provider"`
+	Platform         string    `json:"platform"`
+	ScrapeTimestamp  time.Time `json:"scrapeTimestamp"`
+	Success          bool      `json:"success"`
+	SlotsFound       int       `json:"slotsFound"`
+	ScrapeDurationMs int       `json:"scrapeDurationMs"`
+	Errors           []string  `json:"errors"`
+	CreatedAt        time.Time `json:"createdAt"`
+}
+
+// ScrapingLogsResponse is the paginated response for GET /api/system/logs
+type ScrapingLogsResponse struct {
+	Logs []ScrapingLogResponse `json:"logs"`
+	PageInfo
 }
 
 // GetStatus handles GET /api/system/status
@@ -319,23 +383,12 @@ func (h *SystemHandler) GetScrapingLogs(w http.ResponseWriter, r *http.Request)
 
 	// Get query parameters
 	query := r.URL.Query()
-	limitStr := query.Get("limit")
-	offsetStr := query.Get("offset")
 	venueID := query.Get("venueId")
 
-	// Parse limit and offset
-	limit := int64(50) // Default limit
-	if limitStr != "" {
-		if parsedLimit, err := strconv.ParseInt(limitStr, 10, 64); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	offset := int64(0)
-	if offsetStr != "" {
-		if parsedOffset, err := strconv.ParseInt(offsetStr, 10, 64); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
+	page, err := parsePagination(query, scrapingLogPageBounds)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	// Query the database directly to handle the current schema
@@ -352,11 +405,17 @@ func (h *SystemHandler) GetScrapingLogs(w http.ResponseWriter, r *http.Request)
 		filter["venue_id"] = venueObjectID
 	}
 
-	// Set up options
+	total, err := scrapingLogsCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to count scraping logs: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Set up options; the sort matches the scrape_timestamp indexes, with _id breaking ties
 	opts := options.Find().
-		SetSort(bson.D{{Key: "scrape_timestamp", Value: -1}}).
-		SetSkip(offset).
-		SetLimit(limit)
+		SetSort(bson.D{{Key: "scrape_timestamp", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(page.Offset).
+		SetLimit(page.Limit)
 
 	cursor, err := scrapingLogsCollection.Find(ctx, filter, opts)
 	if err != nil {
@@ -367,21 +426,10 @@ func (h *SystemHandler) GetScrapingLogs(w http.ResponseWriter, r *http.Request)
 	defer cursor.Close(ctx)
 
 	// Transform logs for frontend consumption
-	type ScrapingLogResponse struct {
-		ID               string    `json:"id"`
-		VenueID          string    `json:"venueId"`
-		VenueName        string    `json:"venueName"`
-		Provider         string    `json:"provider"`
-		Platform         string    `json:"platform"`
-		ScrapeTimestamp  time.Time `json:"scrapeTimestamp"`
-		Success          bool      `json:"success"`
-		SlotsFound       int       `json:"slotsFound"`
-		ScrapeDurationMs int       `json:"scrapeDurationMs"`
-		Errors           []string  `json:"errors"`
-		CreatedAt        time.Time `json:"createdAt"`
+	response := ScrapingLogsResponse{
+		Logs:     []ScrapingLogResponse{},
+		PageInfo: page.PageInfo(total),
 	}
-
-	var response []ScrapingLogResponse
 	for cursor.Next(ctx) {
 		var rawLog bson.M
 		if err := cursor.Decode(&rawLog); err != nil {
@@ -412,7 +460,7 @@ func (h *SystemHandler) GetScrapingLogs(w http.ResponseWriter, r *http.Request)
 			}
 		}
 
-		response = append(response, ScrapingLogResponse{
+		response.Logs = append(response.Logs, ScrapingLogResponse{
 			ID:               id.Hex(),
 			VenueID:          venueID.Hex(),
 			VenueName:        venueName,
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/database"
+	"tennis-booker/internal/utils"
+)
+
+const (
+	defaultEventPollInterval = 2 * time.Second
+	defaultEventPingInterval = 15 * time.Second
+	scrapeEventBatchSize     = 100
+	eventRetryMs             = 5000 // How long browsers wait before reconnecting
+)
+
+// ScrapeEventType is the SSE event name for a completed scrape
+const ScrapeEventType = "scrape"
+
+// ScrapeOutcomeSourceInterface lists scrapes logged after a given scraping log;
+// satisfied by database.ScrapingLogRepository
+type ScrapeOutcomeSourceInterface interface {
+	FindOutcomesAfter(ctx context.Context, after primitive.ObjectID, limit int64) ([]database.ScrapeOutcome, error)
+}
+
+// StreamEvents handles GET /api/system/events, a Server-Sent Events stream with
+// a "scrape" event for each scrape the scraper logs. Event IDs are scraping log
+// IDs, so a reconnecting client's Last-Event-ID header (or lastEventId query
+// parameter) resumes right after the last event it saw. New clients start from
+// the time they connect. Comment lines are sent periodically to keep proxies
+// from closing an idle stream.
+func (h *SystemHandler) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	if h.scrapeOutcomes == nil {
+		utils.WriteError(w, "Event stream is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	after, err := lastEventID(r, time.Now())
+	if err != nil {
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The server's write timeout would end the stream
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Stop nginx buffering the stream
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", eventRetryMs); err != nil {
+		return
+	}
+
+	ctx := r.Context()
+	poll := time.NewTicker(h.eventPollInterval)
+	defer poll.Stop()
+	ping := time.NewTicker(h.eventPingInterval)
+	defer ping.Stop()
+
+	for {
+		after, err = h.writeScrapeEvents(ctx, w, after)
+		if err != nil {
+			return
+		}
+		if err := controller.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-h.closing:
+			return
+		case <-poll.C:
+		case <-ping.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeScrapeEvents writes an event for every scrape logged after the given
+// log and returns the ID of the last one written. Only write errors are
+// returned; a failed query is logged and retried on the next poll.
+func (h *SystemHandler) writeScrapeEvents(ctx context.Context, w http.ResponseWriter, after primitive.ObjectID) (primitive.ObjectID, error) {
+	for {
+		queryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		outcomes, err := h.scrapeOutcomes.FindOutcomesAfter(queryCtx, after, scrapeEventBatchSize)
+		cancel()
+		if err != nil {
+			if ctx.Err() == nil {
+				streamLogger.WithContext(ctx).Warn("System events: failed to read scraping logs", map[string]interface{}{"error": err.Error()})
+			}
+			return after, nil
+		}
+
+		for _, outcome := range outcomes {
+			data, err := json.Marshal(outcome)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", outcome.ID.Hex(), ScrapeEventType, data); err != nil {
+				return after, err
+			}
+			after = outcome.ID
+		}
+
+		// A full batch means a resuming client has more to catch up on
+		if len(outcomes) < scrapeEventBatchSize {
+			return after, nil
+		}
+	}
+}
+
+// lastEventID is where a stream resumes: after the event the client last saw,
+// or from now for a new client
+func lastEventID(r *http.Request, now time.Time) (primitive.ObjectID, error) {
+	id := r.Header.Get("Last-Event-ID")
+	if id == "" {
+		id = r.URL.Query().Get("lastEventId")
+	}
+	if id == "" {
+		return primitive.NewObjectIDFromTimestamp(now), nil
+	}
+
+	after, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("invalid Last-Event-ID %q", id)
+	}
+	return after, nil
+}
+
+// Close ends open event streams, for server shutdown
+func (h *SystemHandler) Close() {
+	h.closeOnce.Do(func() { close(h.closing) })
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/database"
+)
+
+// MockScrapeOutcomeSource serves outcomes from memory, in ID order
+type MockScrapeOutcomeSource struct {
+	mu       sync.Mutex
+	outcomes []database.ScrapeOutcome
+}
+
+func (m *MockScrapeOutcomeSource) add(outcome database.ScrapeOutcome) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.outcomes = append(m.outcomes, outcome)
+}
+
+func (m *MockScrapeOutcomeSource) FindOutcomesAfter(ctx context.Context, after primitive.ObjectID, limit int64) ([]database.ScrapeOutcome, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var found []database.ScrapeOutcome
+	for _, outcome := range m.outcomes {
+		if outcome.ID.Hex() > after.Hex() && int64(len(found)) < limit {
+			found = append(found, outcome)
+		}
+	}
+	return found, nil
+}
+
+// sseEvent is one parsed Server-Sent Event, or a comment
+type sseEvent struct {
+	id, event, data, comment string
+}
+
+// readSSE parses the stream into events until the body closes
+func readSSE(body *bufio.Reader, events chan<- sseEvent) {
+	defer close(events)
+
+	var current sseEvent
+	for {
+		line, err := body.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			if current != (sseEvent{}) {
+				events <- current
+			}
+			current = sseEvent{}
+		case strings.HasPrefix(line, ":"):
+			current.comment = strings.TrimSpace(line[1:])
+		case strings.HasPrefix(line, "id: "):
+			current.id = line[len("id: "):]
+		case strings.HasPrefix(line, "event: "):
+			current.event = line[len("event: "):]
+		case strings.HasPrefix(line, "data: "):
+			current.data = line[len("data: "):]
+		}
+	}
+}
+
+func TestSystemHandler_StreamEvents_ResumesFromLastEventID(t *testing.T) {
+	source := &MockScrapeOutcomeSource{}
+	seen := database.ScrapeOutcome{ID: primitive.NewObjectID(), VenueName: "Seen Club", Success: true}
+	missed := database.ScrapeOutcome{ID: primitive.NewObjectID(), VenueName: "Victoria Park", Success: true, SlotsFound: 4, DurationMs: 1500}
+	source.add(seen)
+	source.add(missed)
+
+	handler := NewSystemHandler(&MockDatabase{})
+	handler.scrapeOutcomes = source
+	handler.eventPollInterval = 10 * time.Millisecond
+	handler.eventPingInterval = 20 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(handler.StreamEvents))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", seen.ID.Hex())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan sseEvent, 16)
+	go readSSE(bufio.NewReader(resp.Body), events)
+
+	nextScrape := func() sseEvent {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case event := <-events:
+				if event.event == ScrapeEventType {
+					return event
+				}
+			case <-timeout:
+				t.Fatal("no scrape event received")
+			}
+		}
+	}
+
+	event := nextScrape()
+	assert.Equal(t, missed.ID.Hex(), event.id)
+	var outcome database.ScrapeOutcome
+	require.NoError(t, json.Unmarshal([]byte(event.data), &outcome))
+	assert.Equal(t, "Victoria Park", outcome.VenueName)
+	assert.Equal(t, 4, outcome.SlotsFound)
+	assert.Equal(t, 1500, outcome.DurationMs)
+
+	// Scrapes logged while connected are pushed, and pings keep the stream alive
+	live := database.ScrapeOutcome{ID: primitive.NewObjectID(), VenueName: "Highbury Fields"}
+	source.add(live)
+	assert.Equal(t, live.ID.Hex(), nextScrape().id)
+
+	timeout := time.After(5 * time.Second)
+	for pinged := false; !pinged; {
+		select {
+		case event := <-events:
+			pinged = event.comment == "ping"
+		case <-timeout:
+			t.Fatal("no keep-alive ping received")
+		}
+	}
+}
+
+func TestSystemHandler_StreamEvents_Errors(t *testing.T) {
+	handler := NewSystemHandler(&MockDatabase{})
+
+	w := httptest.NewRecorder()
+	handler.StreamEvents(w, httptest.NewRequest(http.MethodGet, "/api/system/events", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	handler.scrapeOutcomes = &MockScrapeOutcomeSource{}
+	w = httptest.NewRecorder()
+	handler.StreamEvents(w, httptest.NewRequest(http.MethodGet, "/api/system/events?lastEventId=nope", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLastEventID_NewClientsStartNow(t *testing.T) {
+	now := time.Now()
+	after, err := lastEventID(httptest.NewRequest(http.MethodGet, "/api/system/events", nil), now)
+	require.NoError(t, err)
+
+	assert.Equal(t, now.Unix(), after.Timestamp().Unix())
+	assert.Less(t, after.Hex(), primitive.NewObjectIDFromTimestamp(now.Add(time.Second)).Hex())
+}
@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Note: MockDatabase is defined in auth_test.go to avoid duplication
@@ -53,3 +57,28 @@ func TestSystemHandler_NewSystemHandler(t *testing.T) {
 	handler := NewSystemHandler(mockDB)
 	assert.NotNil(t, handler, "Handler should not be nil")
 }
+
+type staticFeatureFlags map[string]bool
+
+func (f staticFeatureFlags) FeatureFlags() map[string]bool {
+	return f
+}
+
+func TestSystemHandler_GetFeatures(t *testing.T) {
+	handler := NewSystemHandler(&MockDatabase{})
+
+	getFeatures := func() FeaturesResponse {
+		w := httptest.NewRecorder()
+		handler.GetFeatures(w, httptest.NewRequest(http.MethodGet, "/api/system/features", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response FeaturesResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	assert.Empty(t, getFeatures().Features, "no flags without a configuration")
+
+	handler.SetFeatureFlags(staticFeatureFlags{"analytics": false, "notifications": true})
+	assert.Equal(t, map[string]bool{"analytics": false, "notifications": true}, getFeatures().Features)
+}
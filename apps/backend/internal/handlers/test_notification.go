@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/ratelimit"
+	"tennis-booker/internal/utils"
+)
+
+// testNotificationLimit is how many test notifications a user can send. It's
+// kept tight since each one is a real email, SMS or webhook call, and SMS costs money.
+var testNotificationLimit = ratelimit.RateLimit{Requests: 3, Window: 10 * time.Minute}
+
+// TestNotifierInterface sends sample alerts through the notification service
+type TestNotifierInterface interface {
+	SendTestNotification(ctx context.Context, userID, channel string) (*models.TestNotificationResult, error)
+}
+
+// TestNotificationLimiter rate limits test notifications; satisfied by *ratelimit.Limiter
+type TestNotificationLimiter interface {
+	CheckCustomLimit(ctx context.Context, identifier string, rateLimit ratelimit.RateLimit) (*ratelimit.LimitResult, error)
+}
+
+// SetTestNotifier enables POST /api/notifications/test. Without a limiter the
+// endpoint stays unavailable rather than sending unlimited notifications.
+func (h *NotificationHandler) SetTestNotifier(notifier TestNotifierInterface, limiter TestNotificationLimiter) {
+	h.testNotifier = notifier
+	h.testLimiter = limiter
+}
+
+// SendTestNotification handles POST /api/notifications/test?channel=email|sms|webhook.
+// It sends a sample alert to the address the authenticated user has configured
+// for the channel, even if alerts on it are turned off, and says whether it was sent.
+func (h *NotificationHandler) SendTestNotification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return // RequireAuth already wrote the error response
+	}
+
+	channel := r.URL.Query().Get("channel")
+	if !models.ValidNotificationChannel(channel) {
+		utils.WriteError(w, "channel must be email, sms or webhook", http.StatusBadRequest)
+		return
+	}
+
+	if h.testNotifier == nil || h.testLimiter == nil {
+		utils.WriteError(w, "Test notifications are unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Limited per user across all channels, so switching channel doesn't reset it
+	limit, err := h.testLimiter.CheckCustomLimit(r.Context(), "notification_test:user:"+userID.Hex(), testNotificationLimit)
+	if err != nil {
+		courtLogger.WithContext(r.Context()).Error("Test notification rate limit check failed", map[string]interface{}{"user_id": userID.Hex(), "error": err.Error()})
+		utils.WriteError(w, "Test notifications are unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if !limit.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(limit.RetryAfter.Seconds()))))
+		utils.WriteError(w, "Too many test notifications. Please try again later.", http.StatusTooManyRequests)
+		return
+	}
+
+	result, err := h.testNotifier.SendTestNotification(r.Context(), userID.Hex(), channel)
+	if err != nil {
+		if errors.Is(err, models.ErrTestNotificationTimeout) {
+			utils.WriteError(w, "The notification service did not respond in time", http.StatusGatewayTimeout)
+			return
+		}
+		courtLogger.WithContext(r.Context()).Error("Test notification failed", map[string]interface{}{"user_id": userID.Hex(), "channel": channel, "error": err.Error()})
+		utils.WriteError(w, "Failed to send test notification", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteSuccess(w, result)
+}
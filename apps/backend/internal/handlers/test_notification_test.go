@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/ratelimit"
+)
+
+// MockTestNotifier records the channel and returns a canned result or error
+type MockTestNotifier struct {
+	userID  string
+	channel string
+	result  *models.TestNotificationResult
+	err     error
+}
+
+func (m *MockTestNotifier) SendTestNotification(ctx context.Context, userID, channel string) (*models.TestNotificationResult, error) {
+	m.userID, m.channel = userID, channel
+	return m.result, m.err
+}
+
+// MockTestNotificationLimiter allows a fixed number of requests per identifier
+type MockTestNotificationLimiter struct {
+	allowed int
+	counts  map[string]int
+	err     error
+}
+
+func (m *MockTestNotificationLimiter) CheckCustomLimit(ctx context.Context, identifier string, rateLimit ratelimit.RateLimit) (*ratelimit.LimitResult, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.counts == nil {
+		m.counts = map[string]int{}
+	}
+	m.counts[identifier]++
+	if m.counts[identifier] > m.allowed {
+		return &ratelimit.LimitResult{Allowed: false, RetryAfter: 90 * time.Second}, nil
+	}
+	return &ratelimit.LimitResult{Allowed: true}, nil
+}
+
+func testNotificationRequest(userID primitive.ObjectID, channel string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/notifications/test?channel="+channel, nil)
+	claims := &auth.AppClaims{UserID: userID.Hex(), Username: "testuser"}
+	return req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+}
+
+func TestNotificationHandler_SendTestNotification(t *testing.T) {
+	handler, _ := setupTestNotificationHandler()
+	notifier := &MockTestNotifier{result: &models.TestNotificationResult{Channel: "sms", Success: true, Address: "*******4567"}}
+	handler.SetTestNotifier(notifier, &MockTestNotificationLimiter{allowed: 3})
+	userID := primitive.NewObjectID()
+
+	w := httptest.NewRecorder()
+	handler.SendTestNotification(w, testNotificationRequest(userID, "sms"))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result models.TestNotificationResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	assert.Equal(t, "*******4567", result.Address)
+	assert.Equal(t, userID.Hex(), notifier.userID)
+	assert.Equal(t, "sms", notifier.channel)
+}
+
+func TestNotificationHandler_SendTestNotification_RateLimited(t *testing.T) {
+	handler, _ := setupTestNotificationHandler()
+	limiter := &MockTestNotificationLimiter{allowed: 1}
+	handler.SetTestNotifier(&MockTestNotifier{result: &models.TestNotificationResult{Success: true}}, limiter)
+	userID := primitive.NewObjectID()
+
+	w := httptest.NewRecorder()
+	handler.SendTestNotification(w, testNotificationRequest(userID, "email"))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.SendTestNotification(w, testNotificationRequest(userID, "webhook"))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "the limit is shared across channels")
+	assert.Equal(t, "90", w.Header().Get("Retry-After"))
+
+	w = httptest.NewRecorder()
+	handler.SendTestNotification(w, testNotificationRequest(primitive.NewObjectID(), "email"))
+	assert.Equal(t, http.StatusOK, w.Code, "other users have their own limit")
+}
+
+func TestNotificationHandler_SendTestNotificationErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		notifier     TestNotifierInterface
+		limiter      TestNotificationLimiter
+		channel      string
+		expectedCode int
+	}{
+		{"unknown channel", &MockTestNotifier{}, &MockTestNotificationLimiter{allowed: 3}, "push", http.StatusBadRequest},
+		{"missing channel", &MockTestNotifier{}, &MockTestNotificationLimiter{allowed: 3}, "", http.StatusBadRequest},
+		{"notifier unavailable", nil, &MockTestNotificationLimiter{allowed: 3}, "email", http.StatusServiceUnavailable},
+		{"limiter unavailable", &MockTestNotifier{}, nil, "email", http.StatusServiceUnavailable},
+		{"limiter failing", &MockTestNotifier{}, &MockTestNotificationLimiter{err: errors.New("redis down")}, "email", http.StatusServiceUnavailable},
+		{"service timed out", &MockTestNotifier{err: models.ErrTestNotificationTimeout}, &MockTestNotificationLimiter{allowed: 3}, "email", http.StatusGatewayTimeout},
+		{"service failed", &MockTestNotifier{err: errors.New("redis down")}, &MockTestNotificationLimiter{allowed: 3}, "email", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, _ := setupTestNotificationHandler()
+			handler.SetTestNotifier(tt.notifier, tt.limiter)
+			w := httptest.NewRecorder()
+			handler.SendTestNotification(w, testNotificationRequest(primitive.NewObjectID(), tt.channel))
+			assert.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+		})
+	}
+
+	handler, _ := setupTestNotificationHandler()
+	w := httptest.NewRecorder()
+	handler.SendTestNotification(w, httptest.NewRequest(http.MethodPost, "/api/notifications/test?channel=email", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// totpIssuer is the account name authenticator apps show for our codes
+const totpIssuer = "Tennis Booker"
+
+// TwoFactorRequiredStatus is the Login response status when a 2FA code is still needed
+const TwoFactorRequiredStatus = "2fa_required"
+
+// TwoFactorChallengeResponse is returned by Login instead of tokens for users with 2FA enabled
+type TwoFactorChallengeResponse struct {
+	Status         string `json:"status"`
+	ChallengeToken string `json:"challengeToken"`
+}
+
+// TwoFactorEnrollResponse carries the new secret for the user to add to their authenticator app
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauthUrl"`
+}
+
+// TwoFactorVerifyRequest confirms enrollment with a code from the authenticator app
+type TwoFactorVerifyRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TwoFactorVerifyResponse returns the recovery codes, which are only ever shown once
+type TwoFactorVerifyResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TwoFactorValidateRequest exchanges a login challenge and a code for tokens. The
+// code is either a 6-digit TOTP code or one of the user's recovery codes.
+type TwoFactorValidateRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+// SetTwoFactorCipher enables the 2FA endpoints, using cipher to encrypt TOTP secrets at rest
+func (h *AuthHandler) SetTwoFactorCipher(cipher *auth.TOTPSecretCipher) {
+	h.totpCipher = cipher
+}
+
+// EnrollTwoFactor handles POST /api/auth/2fa/enroll. It generates a new TOTP secret
+// that only takes effect once a code from it is confirmed at /api/auth/2fa/verify.
+func (h *AuthHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return
+	}
+	if h.totpCipher == nil {
+		utils.WriteError(w, "Two-factor authentication is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := utils.WithDBTimeout()
+	defer cancel()
+
+	user, ok := h.findUserForTwoFactor(ctx, w, userID)
+	if !ok {
+		return
+	}
+	if user.TwoFactorEnabled {
+		utils.WriteError(w, "Two-factor authentication is already enabled", http.StatusConflict)
+		return
+	}
+
+	key, err := auth.GenerateTOTPKey(totpIssuer, user.Email)
+	if err != nil {
+		utils.WriteError(w, "Failed to generate two-factor secret", http.StatusInternalServerError)
+		return
+	}
+	encrypted, err := h.totpCipher.Encrypt(key.Secret())
+	if err != nil {
+		utils.WriteError(w, "Two-factor authentication is unavailable", http.StatusInternalServerError)
+		return
+	}
+
+	_, err = h.db.Collection("users").UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{
+			"two_factor_pending_secret": encrypted,
+			"updated_at":                time.Now(),
+		},
+	})
+	if err != nil {
+		utils.WriteError(w, "Failed to save two-factor secret", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteSuccess(w, TwoFactorEnrollResponse{
+		Secret:     key.Secret(),
+		OTPAuthURL: key.URL(),
+	})
+}
+
+// VerifyTwoFactor handles POST /api/auth/2fa/verify. A valid code from the pending
+// secret turns 2FA on and returns a fresh set of recovery codes.
+func (h *AuthHandler) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return
+	}
+
+	var req TwoFactorVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		utils.WriteError(w, "A verification code is required", http.StatusBadRequest)
+		return
+	}
+	if h.totpCipher == nil {
+		utils.WriteError(w, "Two-factor authentication is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := utils.WithDBTimeout()
+	defer cancel()
+
+	user, ok := h.findUserForTwoFactor(ctx, w, userID)
+	if !ok {
+		return
+	}
+	if user.TwoFactorPending == "" {
+		utils.WriteError(w, "No two-factor enrollment in progress", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := h.totpCipher.Decrypt(user.TwoFactorPending)
+	if err != nil {
+		utils.WriteError(w, "Failed to read two-factor secret", http.StatusInternalServerError)
+		return
+	}
+	step, valid := auth.ValidateTOTPCode(secret, req.Code, time.Now())
+	if !valid {
+		utils.WriteError(w, "Invalid verification code", http.StatusBadRequest)
+		return
+	}
+
+	recoveryCodes, err := auth.GenerateRecoveryCodes(auth.RecoveryCodeCount)
+	if err != nil {
+		utils.WriteError(w, "Failed to generate recovery codes", http.StatusInternalServerError)
+		return
+	}
+	hashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashes[i] = auth.HashRecoveryCode(code)
+	}
+
+	_, err = h.db.Collection("users").UpdateOne(ctx, bson.M{"_id": userID}, bson.M{
+		"$set": bson.M{
+			"two_factor_enabled":   true,
+			"two_factor_secret":    user.TwoFactorPending,
+			"two_factor_last_step": step,
+			"recovery_code_hashes": hashes,
+			"updated_at":           time.Now(),
+		},
+		"$unset": bson.M{"two_factor_pending_secret": ""},
+	})
+	if err != nil {
+		utils.WriteError(w, "Failed to enable two-factor authentication", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteSuccess(w, TwoFactorVerifyResponse{RecoveryCodes: recoveryCodes})
+}
+
+// ValidateTwoFactor handles POST /api/auth/2fa/validate, completing a login that
+// Login answered with a 2FA challenge. Wrong codes count towards the account lockout.
+func (h *AuthHandler) ValidateTwoFactor(w http.ResponseWriter, r *http.Request) {
+	var req TwoFactorValidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		utils.WriteError(w, "A challenge token and code are required", http.StatusBadRequest)
+		return
+	}
+	if h.totpCipher == nil {
+		utils.WriteError(w, "Two-factor authentication is unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	claims, err := h.jwtService.ValidateTwoFactorChallenge(req.ChallengeToken)
+	if err != nil {
+		utils.WriteError(w, "Invalid or expired challenge", http.StatusUnauthorized)
+		return
+	}
+	userID, err := primitive.ObjectIDFromHex(claims.UserID)
+	if err != nil {
+		utils.WriteError(w, "Invalid or expired challenge", http.StatusUnauthorized)
+		return
+	}
+
+	ctx, cancel := utils.WithDBTimeout()
+	defer cancel()
+
+	if lockedFor := h.accountLockedFor(ctx, claims.Username); lockedFor > 0 {
+		writeAccountLocked(w, lockedFor)
+		return
+	}
+
+	user, ok := h.findUserForTwoFactor(ctx, w, userID)
+	if !ok {
+		return
+	}
+	if !user.TwoFactorEnabled {
+		utils.WriteError(w, "Invalid or expired challenge", http.StatusUnauthorized)
+		return
+	}
+
+	accepted, err := h.acceptSecondFactor(ctx, user, req.Code)
+	if err != nil {
+		utils.WriteError(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !accepted {
+		h.loginFailed(ctx, w, claims.Username)
+		return
+	}
+
+	h.resetLoginFailures(ctx, claims.Username)
+	h.completeLogin(ctx, w, user)
+}
+
+// acceptSecondFactor checks a TOTP code or recovery code and consumes it. Each
+// update is conditional, so a code can't be used twice even by concurrent requests.
+func (h *AuthHandler) acceptSecondFactor(ctx context.Context, user models.User, code string) (bool, error) {
+	collection := h.db.Collection("users")
+
+	secret, err := h.totpCipher.Decrypt(user.TwoFactorSecret)
+	if err != nil {
+		return false, err
+	}
+
+	if step, valid := auth.ValidateTOTPCode(secret, code, time.Now()); valid {
+		result, err := collection.UpdateOne(ctx, bson.M{
+			"_id": user.ID,
+			"$or": []bson.M{
+				{"two_factor_last_step": bson.M{"$lt": step}},
+				{"two_factor_last_step": bson.M{"$exists": false}},
+			},
+		}, bson.M{"$set": bson.M{"two_factor_last_step": step}})
+		if err != nil {
+			return false, err
+		}
+		return result.MatchedCount == 1, nil // No match: this code was already used
+	}
+
+	result, err := collection.UpdateOne(ctx,
+		bson.M{"_id": user.ID, "recovery_code_hashes": auth.HashRecoveryCode(code)},
+		bson.M{"$pull": bson.M{"recovery_code_hashes": auth.HashRecoveryCode(code)}},
+	)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+// findUserForTwoFactor loads a user, writing the error response if that fails
+func (h *AuthHandler) findUserForTwoFactor(ctx context.Context, w http.ResponseWriter, userID primitive.ObjectID) (models.User, bool) {
+	var user models.User
+	err := h.db.Collection("users").FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			utils.WriteError(w, "User not found", http.StatusNotFound)
+			return user, false
+		}
+		utils.WriteError(w, "Internal server error", http.StatusInternalServerError)
+		return user, false
+	}
+	return user, true
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tennis-booker/internal/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// testTOTPKey supplies a fixed TOTP encryption key
+type testTOTPKey struct{}
+
+func (testTOTPKey) GetTOTPEncryptionKey() (string, error) {
+	return "test-totp-key", nil
+}
+
+func TestAuthHandler_TwoFactor_RequiresCipher(t *testing.T) {
+	jwtService := auth.NewJWTService(&MockSecretsProvider{secret: "test-secret-key"}, "test-issuer")
+	handler := NewAuthHandler(jwtService, NewMockDatabase())
+	claims := &auth.AppClaims{UserID: primitive.NewObjectID().Hex(), Username: "player@example.com"}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/2fa/enroll", nil)
+	req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+	w := httptest.NewRecorder()
+	handler.EnrollTwoFactor(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = postJSON(handler.ValidateTwoFactor, "/api/auth/2fa/validate", TwoFactorValidateRequest{ChallengeToken: "x", Code: "123456"})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestAuthHandler_TwoFactor_RequiresAuth(t *testing.T) {
+	jwtService := auth.NewJWTService(&MockSecretsProvider{secret: "test-secret-key"}, "test-issuer")
+	handler := NewAuthHandler(jwtService, NewMockDatabase())
+	handler.SetTwoFactorCipher(auth.NewTOTPSecretCipher(testTOTPKey{}))
+
+	w := postJSON(handler.EnrollTwoFactor, "/api/auth/2fa/enroll", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = postJSON(handler.VerifyTwoFactor, "/api/auth/2fa/verify", TwoFactorVerifyRequest{Code: "123456"})
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestAuthHandler_ValidateTwoFactor_RejectsBadChallenges(t *testing.T) {
+	jwtService := auth.NewJWTService(&MockSecretsProvider{secret: "test-secret-key"}, "test-issuer")
+	handler := NewAuthHandler(jwtService, NewMockDatabase())
+	handler.SetTwoFactorCipher(auth.NewTOTPSecretCipher(testTOTPKey{}))
+
+	userID := primitive.NewObjectID().Hex()
+	accessToken, err := jwtService.GenerateToken(userID, "player@example.com", time.Hour)
+	require.NoError(t, err)
+
+	w := postJSON(handler.ValidateTwoFactor, "/api/auth/2fa/validate", TwoFactorValidateRequest{Code: "123456"})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Access tokens can't stand in for a challenge token
+	for _, token := range []string{"garbage", accessToken} {
+		w = postJSON(handler.ValidateTwoFactor, "/api/auth/2fa/validate", TwoFactorValidateRequest{ChallengeToken: token, Code: "123456"})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	}
+}
+
+func TestAuthHandler_ValidateTwoFactor_LockedAccount(t *testing.T) {
+	jwtService := auth.NewJWTService(&MockSecretsProvider{secret: "test-secret-key"}, "test-issuer")
+	handler := NewAuthHandler(jwtService, NewMockDatabase())
+	handler.SetTwoFactorCipher(auth.NewTOTPSecretCipher(testTOTPKey{}))
+	lockout := newMemoryAccountLockout()
+	handler.SetAccountLockout(lockout)
+
+	challenge, err := jwtService.GenerateTwoFactorChallenge(primitive.NewObjectID().Hex(), "player@example.com")
+	require.NoError(t, err)
+
+	lockout.locked["player@example.com"] = 5 * time.Minute
+
+	w := postJSON(handler.ValidateTwoFactor, "/api/auth/2fa/validate", TwoFactorValidateRequest{ChallengeToken: challenge, Code: "123456"})
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -16,21 +19,46 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// VenueIDCheckerInterface checks venue IDs against the venues collection
+type VenueIDCheckerInterface interface {
+	FindMissingIDs(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error)
+}
+
+// PreferenceStoreInterface updates individual preference settings
+type PreferenceStoreInterface interface {
+	SetPreferredVenues(ctx context.Context, userID primitive.ObjectID, venueIDs []string) error
+	SetSnoozeUntil(ctx context.Context, userID primitive.ObjectID, until time.Time) error
+	SetWebhookSecret(ctx context.Context, userID primitive.ObjectID, secret string) error
+}
+
+// AlertHistoryPagerInterface pages through a user's past alerts, newest first
+type AlertHistoryPagerInterface interface {
+	GetUserAlertHistoryPage(ctx context.Context, userID primitive.ObjectID, limit, offset int64) ([]models.AlertHistory, int64, error)
+}
+
 // UserHandler handles user-related requests
 type UserHandler struct {
-	db         database.Database
-	jwtService *auth.JWTService
+	db           database.Database
+	jwtService   *auth.JWTService
+	venueIDs     VenueIDCheckerInterface
+	preferences  PreferenceStoreInterface
+	alertHistory AlertHistoryPagerInterface
 }
 
 // NewUserHandler creates a new user handler
 func NewUserHandler(db database.Database, jwtService *auth.JWTService) *UserHandler {
-	return &UserHandler{
+	h := &UserHandler{
 		db:         db,
 		jwtService: jwtService,
 	}
+	if mongoDB := db.GetMongoDB(); mongoDB != nil {
+		h.venueIDs = database.NewVenueRepository(mongoDB)
+		h.preferences = models.NewPreferenceService(mongoDB)
+		h.alertHistory = models.NewAlertHistoryService(mongoDB)
+	}
+	return h
 }
 
 // UserPreferencesResponse represents user preferences for API responses
@@ -44,25 +72,73 @@ type UserPreferencesResponse struct {
 	ExcludedVenues       []string                    `json:"excludedVenues"`
 	PreferredDays        []string                    `json:"preferredDays"`
 	MaxPrice             float64                     `json:"maxPrice"`
+	TargetPrice          float64                     `json:"targetPrice,omitempty"` // Deal alert threshold; omitted when unset
 	NotificationSettings models.NotificationSettings `json:"notificationSettings"`
+	DisplaySettings      models.DisplaySettings      `json:"displaySettings"`
+	SnoozeRemaining      int64                       `json:"snoozeRemainingSeconds,omitempty"` // Seconds until snoozed alerts resume
 	CreatedAt            time.Time                   `json:"createdAt"`
 	UpdatedAt            time.Time                   `json:"updatedAt"`
 }
 
 // NotificationHistoryResponse represents a notification history entry for API responses
 type NotificationHistoryResponse struct {
-	ID           string    `json:"id"`
-	UserID       string    `json:"userId"`
-	VenueName    string    `json:"venueName"`
-	CourtName    string    `json:"courtName"`
-	Date         string    `json:"date"`
-	Time         string    `json:"time"`
-	Price        float64   `json:"price"`
-	EmailSent    bool      `json:"emailSent"`
-	EmailStatus  string    `json:"emailStatus"`
-	SlotKey      string    `json:"slotKey"`
-	CreatedAt    time.Time `json:"createdAt"`
-	Type         string    `json:"type"`
+	ID          string    `json:"id"`
+	UserID      string    `json:"userId"`
+	VenueID     string    `json:"venueId"`
+	VenueName   string    `json:"venueName"`
+	CourtName   string    `json:"courtName"`
+	Date        string    `json:"date"`
+	Time        string    `json:"time"`
+	EndTime     string    `json:"endTime"`
+	Price       float64   `json:"price"`
+	Currency    string    `json:"currency"`
+	BookingURL  string    `json:"bookingUrl"`
+	Channel     string    `json:"channel"`
+	EmailSent   bool      `json:"emailSent"`
+	EmailStatus string    `json:"emailStatus"`
+	SlotKey     string    `json:"slotKey"`
+	SentAt      time.Time `json:"sentAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Type        string    `json:"type"`
+}
+
+// ReplacePreferredVenuesRequest is the body of PUT /api/users/preferences/venues
+type ReplacePreferredVenuesRequest struct {
+	VenueIDs []string `json:"venueIds"`
+}
+
+// PreferredVenuesResponse lists the user's preferred venues after they are replaced
+type PreferredVenuesResponse struct {
+	PreferredVenues []string `json:"preferredVenues"`
+}
+
+// UnknownVenuesResponse is returned when some of the submitted venue IDs don't exist
+type UnknownVenuesResponse struct {
+	Error           string   `json:"error"`
+	Message         string   `json:"message"`
+	UnknownVenueIDs []string `json:"unknownVenueIds"`
+}
+
+// maxSnooze is the longest alerts can be snoozed for in one request
+const maxSnooze = 90 * 24 * time.Hour
+
+// SnoozeRequest is the body of POST /api/users/preferences/snooze. Set either
+// a duration, e.g. "7d" or "36h", or the time alerts should resume.
+type SnoozeRequest struct {
+	Duration string     `json:"duration,omitempty"`
+	Until    *time.Time `json:"until,omitempty"`
+}
+
+// SnoozeResponse says when snoozed alerts resume
+type SnoozeResponse struct {
+	SnoozeUntil     time.Time `json:"snoozeUntil"`
+	SnoozeRemaining int64     `json:"snoozeRemainingSeconds"`
+}
+
+// WebhookSecretResponse carries a newly generated webhook signing secret. It is
+// only ever returned here; later responses don't include it.
+type WebhookSecretResponse struct {
+	WebhookSecret string `json:"webhookSecret"`
 }
 
 // UpdatePreferencesRequest represents a request to update user preferences
@@ -74,56 +150,35 @@ type UpdatePreferencesRequest struct {
 	ExcludedVenues       []string                     `json:"excludedVenues"`
 	PreferredDays        []string                     `json:"preferredDays"`
 	MaxPrice             float64                      `json:"maxPrice"`
+	TargetPrice          *float64                     `json:"targetPrice"` // Optional; only changed when provided, 0 clears it
 	NotificationSettings *models.NotificationSettings `json:"notificationSettings"`
+	DisplaySettings      *models.DisplaySettings      `json:"displaySettings"` // Optional; replaces the timezone and language when provided
 }
 
 // GetPreferences handles GET /api/users/preferences
 func (h *UserHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context (set by JWT middleware)
-	userIDStr, ok := r.Context().Value("userID").(string)
+	userID, ok := utils.RequireAuth(w, r)
 	if !ok {
-		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
-		return
-	}
-
-	userID, err := primitive.ObjectIDFromHex(userIDStr)
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
-		return
+		return // RequireAuth already wrote the error response
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	collection := h.db.Collection("user_preferences")
+	if collection == nil {
+		http.Error(w, "Preferences are unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
 	var preferences models.UserPreferences
-	err = collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&preferences)
+	err := collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&preferences)
 
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			// Create default preferences if none exist
-			preferences = models.UserPreferences{
-				ID:              primitive.NewObjectID(),
-				UserID:          userID,
-				Times:           []models.TimeRange{},
-				WeekdayTimes:    []models.TimeRange{{Start: "18:00", End: "20:00"}},
-				WeekendTimes:    []models.TimeRange{{Start: "09:00", End: "11:00"}},
-				PreferredVenues: []string{},
-				ExcludedVenues:  []string{},
-				PreferredDays:   []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
-				MaxPrice:        100.0,
-				NotificationSettings: models.NotificationSettings{
-					Email:                true,
-					InstantAlerts:        true,
-					MaxAlertsPerHour:     10,
-					MaxAlertsPerDay:      50,
-					AlertTimeWindowStart: "07:00",
-					AlertTimeWindowEnd:   "22:00",
-					Unsubscribed:         false,
-				},
-				CreatedAt: time.Now(),
-				UpdatedAt: time.Now(),
-			}
+			preferences = defaultUserPreferences(userID)
 
 			// Insert default preferences
 			_, err = collection.InsertOne(ctx, preferences)
@@ -137,8 +192,43 @@ func (h *UserHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Convert to response format
-	response := UserPreferencesResponse{
+	response := toUserPreferencesResponse(preferences)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// defaultUserPreferences returns the preferences a user starts with before
+// saving any of their own
+func defaultUserPreferences(userID primitive.ObjectID) models.UserPreferences {
+	now := time.Now()
+	return models.UserPreferences{
+		ID:              primitive.NewObjectID(),
+		UserID:          userID,
+		Times:           []models.TimeRange{},
+		WeekdayTimes:    []models.TimeRange{{Start: "18:00", End: "20:00"}},
+		WeekendTimes:    []models.TimeRange{{Start: "09:00", End: "11:00"}},
+		PreferredVenues: []string{},
+		ExcludedVenues:  []string{},
+		PreferredDays:   []string{"monday", "tuesday", "wednesday", "thursday", "friday"},
+		MaxPrice:        100.0,
+		NotificationSettings: models.NotificationSettings{
+			Email:                true,
+			InstantAlerts:        true,
+			MaxAlertsPerHour:     10,
+			MaxAlertsPerDay:      50,
+			AlertTimeWindowStart: "07:00",
+			AlertTimeWindowEnd:   "22:00",
+			Unsubscribed:         false,
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// toUserPreferencesResponse converts stored preferences to the response format
+func toUserPreferencesResponse(preferences models.UserPreferences) UserPreferencesResponse {
+	return UserPreferencesResponse{
 		ID:                   preferences.ID.Hex(),
 		UserID:               preferences.UserID.Hex(),
 		Times:                preferences.Times,
@@ -148,28 +238,21 @@ func (h *UserHandler) GetPreferences(w http.ResponseWriter, r *http.Request) {
 		ExcludedVenues:       preferences.ExcludedVenues,
 		PreferredDays:        preferences.PreferredDays,
 		MaxPrice:             preferences.MaxPrice,
+		TargetPrice:          preferences.TargetPrice,
 		NotificationSettings: preferences.NotificationSettings,
+		DisplaySettings:      preferences.DisplaySettings,
+		SnoozeRemaining:      int64(preferences.NotificationSettings.SnoozeRemaining(time.Now()).Seconds()),
 		CreatedAt:            preferences.CreatedAt,
 		UpdatedAt:            preferences.UpdatedAt,
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
 }
 
 // UpdatePreferences handles PUT /api/users/preferences
 func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request) {
 	// Get user ID from context (set by JWT middleware)
-	userIDStr, ok := r.Context().Value("userID").(string)
+	userID, ok := utils.RequireAuth(w, r)
 	if !ok {
-		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
-		return
-	}
-
-	userID, err := primitive.ObjectIDFromHex(userIDStr)
-	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
-		return
+		return // RequireAuth already wrote the error response
 	}
 
 	var req UpdatePreferencesRequest
@@ -178,14 +261,41 @@ func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	submitted := models.UserPreferences{
+		Times:           req.Times,
+		WeekdayTimes:    req.WeekdayTimes,
+		WeekendTimes:    req.WeekendTimes,
+		PreferredVenues: req.PreferredVenues,
+		ExcludedVenues:  req.ExcludedVenues,
+		PreferredDays:   req.PreferredDays,
+		MaxPrice:        req.MaxPrice,
+	}
+	if req.TargetPrice != nil {
+		submitted.TargetPrice = *req.TargetPrice
+	}
+	if req.DisplaySettings != nil {
+		submitted.DisplaySettings = *req.DisplaySettings
+	}
+	if req.NotificationSettings != nil {
+		submitted.NotificationSettings = *req.NotificationSettings
+	}
+	if fieldErrs := submitted.Validate(); len(fieldErrs) > 0 {
+		h.writeValidationErrors(w, fieldErrs)
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	collection := h.db.Collection("user_preferences")
+	if collection == nil {
+		http.Error(w, "Preferences are unavailable", http.StatusServiceUnavailable)
+		return
+	}
 
 	// Check if preferences exist
 	var existingPreferences models.UserPreferences
-	err = collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&existingPreferences)
+	err := collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&existingPreferences)
 
 	if err != nil && err != mongo.ErrNoDocuments {
 		http.Error(w, "Failed to check existing preferences", http.StatusInternalServerError)
@@ -204,9 +314,13 @@ func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request)
 			ExcludedVenues:  req.ExcludedVenues,
 			PreferredDays:   req.PreferredDays,
 			MaxPrice:        req.MaxPrice,
+			TargetPrice:     submitted.TargetPrice,
+			DisplaySettings: submitted.DisplaySettings,
 			NotificationSettings: func() models.NotificationSettings {
 				if req.NotificationSettings != nil {
-					return *req.NotificationSettings
+					settings := *req.NotificationSettings
+					settings.SnoozeUntil = nil // Only set by SnoozeNotifications
+					return settings
 				}
 				return models.NotificationSettings{
 					Email:                true,
@@ -239,7 +353,9 @@ func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request)
 			ExcludedVenues:       preferences.ExcludedVenues,
 			PreferredDays:        preferences.PreferredDays,
 			MaxPrice:             preferences.MaxPrice,
+			TargetPrice:          preferences.TargetPrice,
 			NotificationSettings: preferences.NotificationSettings,
+			DisplaySettings:      preferences.DisplaySettings,
 			CreatedAt:            preferences.CreatedAt,
 			UpdatedAt:            preferences.UpdatedAt,
 		}
@@ -275,8 +391,17 @@ func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request)
 		updateFields["preferred_days"] = req.PreferredDays
 	}
 	updateFields["max_price"] = req.MaxPrice
+	if req.TargetPrice != nil {
+		updateFields["target_price"] = *req.TargetPrice
+	}
 	if req.NotificationSettings != nil {
-		updateFields["notification_settings"] = *req.NotificationSettings
+		// Field by field, so the webhook secret and snooze aren't wiped
+		for name, value := range req.NotificationSettings.SetFields("notification_settings") {
+			updateFields[name] = value
+		}
+	}
+	if req.DisplaySettings != nil {
+		updateFields["display_settings"] = *req.DisplaySettings
 	}
 
 	update := bson.M{"$set": updateFields}
@@ -311,7 +436,9 @@ func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request)
 		ExcludedVenues:       updatedPreferences.ExcludedVenues,
 		PreferredDays:        updatedPreferences.PreferredDays,
 		MaxPrice:             updatedPreferences.MaxPrice,
+		TargetPrice:          updatedPreferences.TargetPrice,
 		NotificationSettings: updatedPreferences.NotificationSettings,
+		DisplaySettings:      updatedPreferences.DisplaySettings,
 		CreatedAt:            updatedPreferences.CreatedAt,
 		UpdatedAt:            updatedPreferences.UpdatedAt,
 	}
@@ -320,175 +447,304 @@ func (h *UserHandler) UpdatePreferences(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(response)
 }
 
-// validatePreferences validates the preferences data
-func (h *UserHandler) validatePreferences(prefs *models.UserPreferences) error {
-	// Validate preferred days
-	validDays := map[string]bool{
-		"monday": true, "tuesday": true, "wednesday": true, "thursday": true,
-		"friday": true, "saturday": true, "sunday": true,
-	}
-	for _, day := range prefs.PreferredDays {
-		if !validDays[day] {
-			return &ValidationError{Field: "preferred_days", Message: "invalid day: " + day}
-		}
-	}
+// writeErrorResponse writes an error response in JSON format
+func (h *UserHandler) writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
 
-	// Validate time ranges
-	for i, timeRange := range prefs.Times {
-		if err := h.validateTimeRange(&timeRange); err != nil {
-			return &ValidationError{
-				Field:   "preferred_times",
-				Message: "invalid time range at index " + fmt.Sprintf("%d", i) + ": " + err.Error(),
-			}
-		}
+	errorResp := map[string]string{
+		"error":   http.StatusText(statusCode),
+		"message": message,
 	}
 
-	return nil
+	json.NewEncoder(w).Encode(errorResp)
 }
 
-// validateTimeRange validates a time range
-func (h *UserHandler) validateTimeRange(tr *models.TimeRange) error {
-	// Basic format validation (HH:MM)
-	if len(tr.Start) != 5 || tr.Start[2] != ':' {
-		return &ValidationError{Field: "start", Message: "invalid time format, expected HH:MM"}
+// ReplacePreferredVenues handles PUT /api/users/preferences/venues, replacing the
+// user's preferred venues with the submitted list. Nothing is changed unless
+// every ID belongs to a venue; an empty list clears the preference.
+func (h *UserHandler) ReplacePreferredVenues(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return // RequireAuth already wrote the error response
 	}
-	if len(tr.End) != 5 || tr.End[2] != ':' {
-		return &ValidationError{Field: "end", Message: "invalid time format, expected HH:MM"}
+
+	var req ReplacePreferredVenuesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.VenueIDs == nil {
+		utils.WriteError(w, "Request body must contain a venueIds list", http.StatusBadRequest)
+		return
 	}
 
-	// Parse and validate time values
-	startTime, err := time.Parse("15:04", tr.Start)
-	if err != nil {
-		return &ValidationError{Field: "start", Message: "invalid time value"}
+	if h.venueIDs == nil || h.preferences == nil {
+		utils.WriteError(w, "Preferences are unavailable", http.StatusServiceUnavailable)
+		return
 	}
 
-	endTime, err := time.Parse("15:04", tr.End)
+	// Drop duplicates, keeping the submitted order
+	venueIDs := make([]string, 0, len(req.VenueIDs))
+	objectIDs := make([]primitive.ObjectID, 0, len(req.VenueIDs))
+	unknown := []string{}
+	seen := make(map[string]bool, len(req.VenueIDs))
+	for _, id := range req.VenueIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		objectID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			unknown = append(unknown, id)
+			continue
+		}
+		venueIDs = append(venueIDs, id)
+		objectIDs = append(objectIDs, objectID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	missing, err := h.venueIDs.FindMissingIDs(ctx, objectIDs)
 	if err != nil {
-		return &ValidationError{Field: "end", Message: "invalid time value"}
+		utils.WriteError(w, "Failed to check venues", http.StatusInternalServerError)
+		return
+	}
+	for _, id := range missing {
+		unknown = append(unknown, id.Hex())
 	}
 
-	// Ensure start time is before end time
-	if !startTime.Before(endTime) {
-		return &ValidationError{Field: "time_range", Message: "start time must be before end time"}
+	if len(unknown) > 0 {
+		utils.WriteJSON(w, UnknownVenuesResponse{
+			Error:           http.StatusText(http.StatusBadRequest),
+			Message:         "Some venue IDs don't match any venue",
+			UnknownVenueIDs: unknown,
+		}, http.StatusBadRequest)
+		return
 	}
 
-	return nil
-}
+	if err := h.preferences.SetPreferredVenues(ctx, userID, venueIDs); err != nil {
+		utils.WriteError(w, "Failed to update preferred venues", http.StatusInternalServerError)
+		return
+	}
 
-// ValidationError represents a validation error
-type ValidationError struct {
-	Field   string `json:"field"`
-	Message string `json:"message"`
+	utils.WriteSuccess(w, PreferredVenuesResponse{PreferredVenues: venueIDs})
 }
 
-func (e *ValidationError) Error() string {
-	return e.Message
-}
+// SnoozeNotifications handles POST /api/users/preferences/snooze, pausing the
+// user's alerts for a while without changing anything else. Alerts resume on
+// their own when the snooze ends; snoozing again replaces the end time.
+func (h *UserHandler) SnoozeNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return // RequireAuth already wrote the error response
+	}
 
-// writeErrorResponse writes an error response in JSON format
-func (h *UserHandler) writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
+	var req SnoozeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
 
-	errorResp := map[string]string{
-		"error":   http.StatusText(statusCode),
-		"message": message,
+	now := time.Now()
+	var until time.Time
+	switch {
+	case req.Duration != "" && req.Until != nil:
+		utils.WriteError(w, "Set either duration or until, not both", http.StatusBadRequest)
+		return
+	case req.Duration != "":
+		duration, err := parseWindow(req.Duration)
+		if err != nil || duration <= 0 {
+			utils.WriteError(w, `duration must be positive, e.g. "7d" or "36h"`, http.StatusBadRequest)
+			return
+		}
+		until = now.Add(duration)
+	case req.Until != nil:
+		until = *req.Until
+		if !until.After(now) {
+			utils.WriteError(w, "until must be in the future", http.StatusBadRequest)
+			return
+		}
+	default:
+		utils.WriteError(w, "Set duration or until", http.StatusBadRequest)
+		return
+	}
+	if until.Sub(now) > maxSnooze {
+		utils.WriteError(w, fmt.Sprintf("Alerts can be snoozed for at most %d days", int(maxSnooze.Hours()/24)), http.StatusBadRequest)
+		return
 	}
 
-	json.NewEncoder(w).Encode(errorResp)
+	if h.preferences == nil {
+		utils.WriteError(w, "Preferences are unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := h.preferences.SetSnoozeUntil(ctx, userID, until)
+	if errors.Is(err, models.ErrPreferencesNotFound) {
+		utils.WriteError(w, "Set up your preferences before snoozing alerts", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		utils.WriteError(w, "Failed to snooze alerts", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteSuccess(w, SnoozeResponse{
+		SnoozeUntil:     until,
+		SnoozeRemaining: int64(until.Sub(now).Seconds()),
+	})
 }
 
-// GetNotifications handles GET /api/users/notifications
-func (h *UserHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
-	// Get user ID from context using the proper utility
+// webhookSecretBytes is how much randomness goes into a webhook secret
+const webhookSecretBytes = 32
+
+// RotateWebhookSecret handles POST /api/users/preferences/webhook-secret,
+// generating a new key for signing the user's webhook deliveries and returning
+// it once. Any previous secret stops being used straight away.
+func (h *UserHandler) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
 	userID, ok := utils.RequireAuth(w, r)
 	if !ok {
 		return // RequireAuth already wrote the error response
 	}
 
-	// Parse query parameters
-	limit := int64(50) // default limit
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if parsedLimit, err := strconv.ParseInt(limitStr, 10, 64); err == nil {
-			if parsedLimit > 0 && parsedLimit <= 100 {
-				limit = parsedLimit
-			}
-		}
+	if h.preferences == nil {
+		utils.WriteError(w, "Preferences are unavailable", http.StatusServiceUnavailable)
+		return
 	}
 
-	page := int64(0) // default page
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if parsedPage, err := strconv.ParseInt(pageStr, 10, 64); err == nil {
-			if parsedPage >= 0 {
-				page = parsedPage
-			}
-		}
+	raw := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		utils.WriteError(w, "Failed to generate webhook secret", http.StatusInternalServerError)
+		return
 	}
+	secret := hex.EncodeToString(raw)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	collection := h.db.Collection("alert_history")
-	
-	// Find notifications for this user
-	filter := bson.M{"user_id": userID}
-	opts := options.Find().
-		SetSort(bson.D{{Key: "created_at", Value: -1}}). // Sort by newest first
-		SetLimit(limit).
-		SetSkip(page * limit)
-
-	cursor, err := collection.Find(ctx, filter, opts)
+	err := h.preferences.SetWebhookSecret(ctx, userID, secret)
+	if errors.Is(err, models.ErrPreferencesNotFound) {
+		utils.WriteError(w, "Set up your preferences before adding a webhook secret", http.StatusNotFound)
+		return
+	}
 	if err != nil {
-		http.Error(w, "Failed to fetch notifications", http.StatusInternalServerError)
+		utils.WriteError(w, "Failed to save webhook secret", http.StatusInternalServerError)
 		return
 	}
-	defer cursor.Close(ctx)
 
-	var notifications []NotificationHistoryResponse
-	for cursor.Next(ctx) {
-		var alert models.AlertHistory
-		if err := cursor.Decode(&alert); err != nil {
-			continue // Skip invalid entries
-		}
+	utils.WriteSuccess(w, WebhookSecretResponse{WebhookSecret: secret})
+}
+
+// writeValidationErrors writes a 400 response listing each invalid field's message
+func (h *UserHandler) writeValidationErrors(w http.ResponseWriter, fieldErrs models.FieldErrors) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":   http.StatusText(http.StatusBadRequest),
+		"message": "Invalid preferences",
+		"fields":  fieldErrs.Map(),
+	})
+}
+
+// notificationPageBounds paginates GET /api/users/notifications
+var notificationPageBounds = PageBounds{DefaultLimit: 50, MaxLimit: 100, MaxOffset: 10000}
+
+// NotificationsResponse is the paginated response for GET /api/users/notifications
+type NotificationsResponse struct {
+	Notifications []NotificationHistoryResponse `json:"notifications"`
+	Pagination    NotificationPagination        `json:"pagination"`
+}
 
-		notification := NotificationHistoryResponse{
-			ID:          alert.ID.Hex(),
-			UserID:      alert.UserID.Hex(),
-			VenueName:   alert.VenueName,
-			CourtName:   alert.CourtName,
-			Date:        alert.SlotDate,
-			Time:        alert.SlotStartTime,
-			Price:       alert.Price,
-			EmailSent:   alert.EmailStatus == "sent" || alert.EmailStatus == "delivered",
-			EmailStatus: alert.EmailStatus,
-			SlotKey:     alert.SlotKey,
-			CreatedAt:   alert.CreatedAt,
-			Type:        "availability", // Default type for court availability notifications
+// NotificationPagination describes the returned page by offset and, for older
+// clients, by page number
+type NotificationPagination struct {
+	PageInfo
+	Page       int64 `json:"page"`
+	TotalPages int64 `json:"totalPages"`
+}
+
+// GetNotifications handles GET /api/users/notifications?limit=&offset=, newest first.
+// page= is still accepted in place of offset and counts pages of limit alerts.
+func (h *UserHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userID, ok := utils.RequireAuth(w, r)
+	if !ok {
+		return // RequireAuth already wrote the error response
+	}
+
+	query := r.URL.Query()
+	page, err := parsePagination(query, notificationPageBounds)
+	if err != nil {
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if pageStr := query.Get("page"); pageStr != "" && query.Get("offset") == "" {
+		pageNumber, err := strconv.ParseInt(pageStr, 10, 64)
+		if err != nil || pageNumber < 0 {
+			utils.WriteError(w, "page must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		page.Offset = notificationPageBounds.MaxOffset
+		if pageNumber <= notificationPageBounds.MaxOffset/page.Limit {
+			page.Offset = pageNumber * page.Limit
 		}
-		notifications = append(notifications, notification)
 	}
 
-	if err := cursor.Err(); err != nil {
-		http.Error(w, "Error reading notifications", http.StatusInternalServerError)
+	if h.alertHistory == nil {
+		utils.WriteError(w, "Notification history is unavailable", http.StatusServiceUnavailable)
 		return
 	}
 
-	// Get total count for pagination
-	totalCount, err := collection.CountDocuments(ctx, filter)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	alerts, total, err := h.alertHistory.GetUserAlertHistoryPage(ctx, userID, page.Limit, page.Offset)
 	if err != nil {
-		totalCount = 0 // Continue without count if it fails
+		utils.WriteError(w, "Failed to fetch notifications", http.StatusInternalServerError)
+		return
 	}
 
-	response := map[string]interface{}{
-		"notifications": notifications,
-		"pagination": map[string]interface{}{
-			"page":       page,
-			"limit":      limit,
-			"total":      totalCount,
-			"totalPages": (totalCount + limit - 1) / limit,
+	response := NotificationsResponse{
+		Notifications: make([]NotificationHistoryResponse, 0, len(alerts)),
+		Pagination: NotificationPagination{
+			PageInfo:   page.PageInfo(total),
+			Page:       page.Offset / page.Limit,
+			TotalPages: (total + page.Limit - 1) / page.Limit,
 		},
 	}
+	for _, alert := range alerts {
+		response.Notifications = append(response.Notifications, toNotificationHistoryResponse(alert))
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	utils.WriteSuccess(w, response)
+}
+
+// toNotificationHistoryResponse converts a stored alert to its API representation
+func toNotificationHistoryResponse(alert models.AlertHistory) NotificationHistoryResponse {
+	channel := alert.Channel
+	if channel == "" {
+		channel = "email" // Alerts recorded before channels were tracked were all emails
+	}
+
+	return NotificationHistoryResponse{
+		ID:          alert.ID.Hex(),
+		UserID:      alert.UserID.Hex(),
+		VenueID:     alert.VenueID,
+		VenueName:   alert.VenueName,
+		CourtName:   alert.CourtName,
+		Date:        alert.SlotDate,
+		Time:        alert.SlotStartTime,
+		EndTime:     alert.SlotEndTime,
+		Price:       alert.Price,
+		Currency:    alert.Currency,
+		BookingURL:  alert.BookingURL,
+		Channel:     channel,
+		EmailSent:   alert.EmailStatus == "sent" || alert.EmailStatus == "delivered",
+		EmailStatus: alert.EmailStatus,
+		SlotKey:     alert.SlotKey,
+		SentAt:      alert.AlertSentAt,
+		CreatedAt:   alert.CreatedAt,
+		Type:        "availability", // Default type for court availability notifications
+	}
 }
@@ -2,15 +2,18 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"tennis-booker/internal/auth"
 	"tennis-booker/internal/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -30,7 +33,7 @@ func TestUserHandler_UpdatePreferences(t *testing.T) {
 
 	t.Run("successful preferences update", func(t *testing.T) {
 		preferences := UpdatePreferencesRequest{
-			PreferredVenues: []string{"venue1", "venue2"},
+			PreferredVenues: []string{primitive.NewObjectID().Hex(), primitive.NewObjectID().Hex()},
 			PreferredDays:   []string{"monday", "wednesday", "friday"},
 			WeekdayTimes: []models.TimeRange{
 				{Start: "09:00", End: "11:00"},
@@ -77,3 +80,229 @@ func TestUserHandler_Constructor(t *testing.T) {
 		assert.NotNil(t, jwtService)
 	})
 }
+
+func TestUserHandler_UpdatePreferences_Validation(t *testing.T) {
+	userHandler, _ := setupTestUserHandler()
+
+	body := `{
+		"weekdayTimes": [{"start": "18:00", "end": "17:00"}],
+		"weekendTimes": [{"start": "9am", "end": "11:00"}],
+		"preferredDays": ["monday", "funday"],
+		"maxPrice": -5,
+		"targetPrice": -1,
+		"displaySettings": {"language": "not a language"},
+		"notificationSettings": {"webhook_url": "https://169.254.169.254/latest/meta-data/"}
+	}`
+	req := httptest.NewRequest(http.MethodPut, "/api/users/preferences", bytes.NewBufferString(body))
+	claims := &auth.AppClaims{UserID: primitive.NewObjectID().Hex(), Username: "testuser"}
+	req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+
+	w := httptest.NewRecorder()
+	userHandler.UpdatePreferences(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var response struct {
+		Fields map[string]string `json:"fields"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.Contains(t, response.Fields, "weekday_times[0]")
+	assert.Contains(t, response.Fields, "weekend_times[0].start")
+	assert.Contains(t, response.Fields, "preferred_days[1]")
+	assert.Contains(t, response.Fields, "max_price")
+	assert.Contains(t, response.Fields, "target_price")
+	assert.Contains(t, response.Fields, "display_settings.language")
+	assert.Contains(t, response.Fields, "notification_settings.webhook_url")
+	assert.Len(t, response.Fields, 7)
+}
+
+// MockPreferenceStore records venue lookups and preference changes
+type MockPreferenceStore struct {
+	existing    map[primitive.ObjectID]bool
+	saved       []string
+	saves       int
+	snoozeUntil time.Time
+	secret      string
+}
+
+func (m *MockPreferenceStore) FindMissingIDs(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	var missing []primitive.ObjectID
+	for _, id := range ids {
+		if !m.existing[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing, nil
+}
+
+func (m *MockPreferenceStore) SetPreferredVenues(ctx context.Context, userID primitive.ObjectID, venueIDs []string) error {
+	m.saved = venueIDs
+	m.saves++
+	return nil
+}
+
+func TestUserHandler_ReplacePreferredVenues(t *testing.T) {
+	known1, known2, unknown := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	store := &MockPreferenceStore{existing: map[primitive.ObjectID]bool{known1: true, known2: true}}
+	userHandler := &UserHandler{venueIDs: store, preferences: store}
+	claims := &auth.AppClaims{UserID: primitive.NewObjectID().Hex(), Username: "testuser"}
+
+	replace := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/users/preferences/venues", bytes.NewBufferString(body))
+		req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+		w := httptest.NewRecorder()
+		userHandler.ReplacePreferredVenues(w, req)
+		return w
+	}
+
+	t.Run("replaces the list", func(t *testing.T) {
+		w := replace(`{"venueIds": ["` + known2.Hex() + `", "` + known1.Hex() + `", "` + known2.Hex() + `"]}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{known2.Hex(), known1.Hex()}, store.saved, "duplicates are dropped")
+	})
+
+	t.Run("rejects unknown venues without saving", func(t *testing.T) {
+		saves := store.saves
+		w := replace(`{"venueIds": ["` + known1.Hex() + `", "not-an-id", "` + unknown.Hex() + `"]}`)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var response UnknownVenuesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.ElementsMatch(t, []string{"not-an-id", unknown.Hex()}, response.UnknownVenueIDs)
+		assert.Equal(t, saves, store.saves)
+	})
+
+	t.Run("empty list clears the preference", func(t *testing.T) {
+		w := replace(`{"venueIds": []}`)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, store.saved)
+	})
+
+	t.Run("missing list", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, replace(`{}`).Code)
+	})
+}
+
+func (m *MockPreferenceStore) SetSnoozeUntil(ctx context.Context, userID primitive.ObjectID, until time.Time) error {
+	m.snoozeUntil = until
+	return nil
+}
+
+func (m *MockPreferenceStore) SetWebhookSecret(ctx context.Context, userID primitive.ObjectID, secret string) error {
+	m.secret = secret
+	return nil
+}
+
+func TestUserHandler_RotateWebhookSecret(t *testing.T) {
+	store := &MockPreferenceStore{}
+	userHandler := &UserHandler{preferences: store}
+	claims := &auth.AppClaims{UserID: primitive.NewObjectID().Hex(), Username: "testuser"}
+
+	rotate := func() string {
+		req := httptest.NewRequest(http.MethodPost, "/api/users/preferences/webhook-secret", nil)
+		req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+		w := httptest.NewRecorder()
+		userHandler.RotateWebhookSecret(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response WebhookSecretResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		return response.WebhookSecret
+	}
+
+	first := rotate()
+	assert.Len(t, first, 2*webhookSecretBytes)
+	assert.Equal(t, first, store.secret)
+
+	second := rotate()
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, second, store.secret)
+}
+
+func TestUserHandler_SnoozeNotifications(t *testing.T) {
+	store := &MockPreferenceStore{}
+	userHandler := &UserHandler{preferences: store}
+	claims := &auth.AppClaims{UserID: primitive.NewObjectID().Hex(), Username: "testuser"}
+
+	snooze := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/users/preferences/snooze", bytes.NewBufferString(body))
+		req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+		w := httptest.NewRecorder()
+		userHandler.SnoozeNotifications(w, req)
+		return w
+	}
+
+	w := snooze(`{"duration": "7d"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), store.snoozeUntil, time.Minute)
+
+	until := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	w = snooze(`{"until": "` + until.Format(time.RFC3339) + `"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, until.Equal(store.snoozeUntil))
+
+	var response SnoozeResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	assert.InDelta(t, (48 * time.Hour).Seconds(), float64(response.SnoozeRemaining), 60)
+
+	for _, body := range []string{
+		`{}`,
+		`{"duration": "-1h"}`,
+		`{"duration": "soon"}`,
+		`{"duration": "365d"}`,
+		`{"until": "2020-01-01T00:00:00Z"}`,
+		`{"duration": "1d", "until": "` + until.Format(time.RFC3339) + `"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, snooze(body).Code, body)
+	}
+}
+
+type stubAlertHistoryPager struct {
+	alerts        []models.AlertHistory
+	total         int64
+	limit, offset int64
+}
+
+func (s *stubAlertHistoryPager) GetUserAlertHistoryPage(ctx context.Context, userID primitive.ObjectID, limit, offset int64) ([]models.AlertHistory, int64, error) {
+	s.limit, s.offset = limit, offset
+	return s.alerts, s.total, nil
+}
+
+func TestUserHandler_GetNotifications(t *testing.T) {
+	pager := &stubAlertHistoryPager{
+		alerts: []models.AlertHistory{{ID: primitive.NewObjectID(), VenueName: "Victoria Park", EmailStatus: "sent"}},
+		total:  45,
+	}
+	userHandler := &UserHandler{alertHistory: pager}
+	claims := &auth.AppClaims{UserID: primitive.NewObjectID().Hex(), Username: "testuser"}
+
+	list := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/users/notifications?"+query, nil)
+		req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+		w := httptest.NewRecorder()
+		userHandler.GetNotifications(w, req)
+		return w
+	}
+
+	w := list("page=2&limit=20")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(20), pager.limit)
+	assert.Equal(t, int64(40), pager.offset, "page counts pages of limit alerts")
+
+	var response NotificationsResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+	require.Len(t, response.Notifications, 1)
+	assert.Equal(t, "email", response.Notifications[0].Channel)
+	assert.True(t, response.Notifications[0].EmailSent)
+	assert.Equal(t, int64(2), response.Pagination.Page)
+	assert.Equal(t, int64(3), response.Pagination.TotalPages)
+	assert.False(t, response.Pagination.HasMore)
+
+	require.Equal(t, http.StatusOK, list("offset=5&page=2").Code)
+	assert.Equal(t, int64(5), pager.offset, "offset wins over page")
+
+	for _, query := range []string{"limit=0", "offset=-1", "page=-1", "page=two"} {
+		assert.Equal(t, http.StatusBadRequest, list(query).Code, query)
+	}
+}
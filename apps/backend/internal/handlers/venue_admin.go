@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"tennis-booker/internal/database"
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/utils"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VenueStoreInterface defines the venue writes behind the admin venue endpoints
+type VenueStoreInterface interface {
+	Create(ctx context.Context, venue *models.Venue) error
+	FindByID(ctx context.Context, id primitive.ObjectID) (*models.Venue, error)
+	Update(ctx context.Context, venue *models.Venue) error
+	SetActive(ctx context.Context, id primitive.ObjectID, active bool) error
+}
+
+// VenueCacheInvalidator drops cached venues after a venue changes
+type VenueCacheInvalidator interface {
+	Invalidate(ctx context.Context) error
+}
+
+// VenueAdminHandler lets admins add, edit and deactivate venues at runtime
+type VenueAdminHandler struct {
+	venues      VenueStoreInterface
+	cache       VenueCacheInvalidator
+	testScraper VenueTestScraperInterface
+}
+
+// NewVenueAdminHandler creates a new venue admin handler. The cache is the one
+// venue listings are served from, so changes show up immediately.
+func NewVenueAdminHandler(venues VenueStoreInterface, cache VenueCacheInvalidator) *VenueAdminHandler {
+	return &VenueAdminHandler{
+		venues: venues,
+		cache:  cache,
+	}
+}
+
+// VenueRequest is the body of POST /api/venues and PUT /api/venues/{id}
+type VenueRequest struct {
+	Name             string               `json:"name"`
+	Provider         string               `json:"provider"`
+	URL              string               `json:"url"`
+	Location         models.Location      `json:"location"`
+	Timezone         string               `json:"timezone,omitempty"`
+	Courts           []models.Court       `json:"courts"`
+	BookingWindow    int                  `json:"booking_window"`
+	ScraperConfig    models.ScraperConfig `json:"scraper_config"`
+	ScrapingInterval int                  `json:"scraping_interval"`
+	IsActive         *bool                `json:"is_active,omitempty"` // Defaults to true on create and is left unchanged on update
+}
+
+// applyTo copies the request onto venue and validates the result
+func (req VenueRequest) applyTo(venue *models.Venue) error {
+	venue.Name = strings.TrimSpace(req.Name)
+	venue.Provider = req.Provider
+	venue.URL = req.URL
+	venue.Location = req.Location
+	venue.Timezone = req.Timezone
+	venue.Courts = req.Courts
+	venue.BookingWindow = req.BookingWindow
+	venue.ScraperConfig = req.ScraperConfig
+	venue.ScrapingInterval = req.ScrapingInterval
+	if req.IsActive != nil {
+		venue.IsActive = *req.IsActive
+	}
+
+	if venue.Timezone != "" {
+		if _, err := time.LoadLocation(venue.Timezone); err != nil {
+			return fmt.Errorf("%w: unknown timezone %q", models.ErrInvalidInput, venue.Timezone)
+		}
+	}
+	return venue.Validate()
+}
+
+// CreateVenue handles POST /api/venues. Admins only.
+func (h *VenueAdminHandler) CreateVenue(w http.ResponseWriter, r *http.Request) {
+	if !utils.RequireAdmin(w, r) {
+		return
+	}
+
+	var req VenueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	venue := &models.Venue{IsActive: true}
+	if err := req.applyTo(venue); err != nil {
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := utils.WithDBTimeout()
+	defer cancel()
+
+	if err := h.venues.Create(ctx, venue); err != nil {
+		h.writeStoreError(w, err, "Failed to create venue")
+		return
+	}
+	h.invalidateCache(ctx)
+
+	utils.WriteCreated(w, venue)
+}
+
+// UpdateVenue handles PUT /api/venues/{id}, replacing the venue's settings.
+// Admins only.
+func (h *VenueAdminHandler) UpdateVenue(w http.ResponseWriter, r *http.Request) {
+	if !utils.RequireAdmin(w, r) {
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, "Invalid venue ID", http.StatusBadRequest)
+		return
+	}
+
+	var req VenueRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := utils.WithDBTimeout()
+	defer cancel()
+
+	venue, err := h.venues.FindByID(ctx, id)
+	if err != nil {
+		h.writeStoreError(w, err, "Failed to fetch venue")
+		return
+	}
+
+	if err := req.applyTo(venue); err != nil {
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.venues.Update(ctx, venue); err != nil {
+		h.writeStoreError(w, err, "Failed to update venue")
+		return
+	}
+	h.invalidateCache(ctx)
+
+	utils.WriteSuccess(w, venue)
+}
+
+// DeleteVenue handles DELETE /api/venues/{id}. Venues are deactivated rather
+// than removed, so their slots, logs and alert history stay intact. Admins only.
+func (h *VenueAdminHandler) DeleteVenue(w http.ResponseWriter, r *http.Request) {
+	if !utils.RequireAdmin(w, r) {
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, "Invalid venue ID", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := utils.WithDBTimeout()
+	defer cancel()
+
+	if err := h.venues.SetActive(ctx, id, false); err != nil {
+		h.writeStoreError(w, err, "Failed to deactivate venue")
+		return
+	}
+	h.invalidateCache(ctx)
+
+	utils.WriteSuccess(w, map[string]string{"message": "Venue deactivated"})
+}
+
+// writeStoreError maps venue repository errors to responses
+func (h *VenueAdminHandler) writeStoreError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrVenueNotFound):
+		utils.WriteError(w, "Venue not found", http.StatusNotFound)
+	case errors.Is(err, database.ErrDuplicateVenueName):
+		utils.WriteError(w, "A venue with that name already exists", http.StatusConflict)
+	default:
+		utils.WriteError(w, message, http.StatusInternalServerError)
+	}
+}
+
+// invalidateCache drops the cached venues. The change is already saved, so a
+// failure is logged and the stale entry expires with the cache TTL.
+func (h *VenueAdminHandler) invalidateCache(ctx context.Context) {
+	if h.cache == nil {
+		return
+	}
+	if err := h.cache.Invalidate(ctx); err != nil {
+		courtLogger.WithContext(ctx).Error("Failed to invalidate venue cache", map[string]interface{}{"error": err.Error()})
+	}
+}
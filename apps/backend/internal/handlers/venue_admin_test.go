@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/database"
+	"tennis-booker/internal/models"
+)
+
+// MockVenueStore is an in-memory VenueStoreInterface
+type MockVenueStore struct {
+	venues map[primitive.ObjectID]*models.Venue
+}
+
+func (m *MockVenueStore) Create(ctx context.Context, venue *models.Venue) error {
+	for _, existing := range m.venues {
+		if existing.Name == venue.Name {
+			return database.ErrDuplicateVenueName
+		}
+	}
+	venue.ID = primitive.NewObjectID()
+	stored := *venue
+	m.venues[venue.ID] = &stored
+	return nil
+}
+
+func (m *MockVenueStore) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Venue, error) {
+	venue, ok := m.venues[id]
+	if !ok {
+		return nil, database.ErrVenueNotFound
+	}
+	found := *venue
+	return &found, nil
+}
+
+func (m *MockVenueStore) Update(ctx context.Context, venue *models.Venue) error {
+	if _, ok := m.venues[venue.ID]; !ok {
+		return database.ErrVenueNotFound
+	}
+	stored := *venue
+	m.venues[venue.ID] = &stored
+	return nil
+}
+
+func (m *MockVenueStore) SetActive(ctx context.Context, id primitive.ObjectID, active bool) error {
+	venue, ok := m.venues[id]
+	if !ok {
+		return database.ErrVenueNotFound
+	}
+	venue.IsActive = active
+	return nil
+}
+
+// MockVenueCacheInvalidator counts invalidations
+type MockVenueCacheInvalidator struct {
+	invalidations int
+}
+
+func (m *MockVenueCacheInvalidator) Invalidate(ctx context.Context) error {
+	m.invalidations++
+	return nil
+}
+
+func venueAdminRequest(method, path, id string, body interface{}, role string) *http.Request {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewBuffer(data))
+	if id != "" {
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+	}
+	ctx := auth.SetUserClaimsInContext(req.Context(), &auth.AppClaims{UserID: primitive.NewObjectID().Hex(), Role: role})
+	return req.WithContext(ctx)
+}
+
+func validVenueRequest() VenueRequest {
+	return VenueRequest{
+		Name:          "Victoria Park",
+		Provider:      "courtsides",
+		Location:      models.Location{City: "London", PostCode: "E9 7DE"},
+		BookingWindow: 7,
+		ScraperConfig: models.ScraperConfig{Type: models.ScraperTypeCourtside},
+	}
+}
+
+func TestVenueAdminHandler_CreateUpdateDelete(t *testing.T) {
+	store := &MockVenueStore{venues: map[primitive.ObjectID]*models.Venue{}}
+	cache := &MockVenueCacheInvalidator{}
+	handler := NewVenueAdminHandler(store, cache)
+
+	w := httptest.NewRecorder()
+	handler.CreateVenue(w, venueAdminRequest(http.MethodPost, "/api/venues", "", validVenueRequest(), auth.RoleAdmin))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created models.Venue
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.False(t, created.ID.IsZero())
+	assert.True(t, created.IsActive, "new venues are active by default")
+	assert.Equal(t, 1, cache.invalidations)
+
+	update := validVenueRequest()
+	update.BookingWindow = 14
+	w = httptest.NewRecorder()
+	handler.UpdateVenue(w, venueAdminRequest(http.MethodPut, "/api/venues/"+created.ID.Hex(), created.ID.Hex(), update, auth.RoleAdmin))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 14, store.venues[created.ID].BookingWindow)
+	assert.True(t, store.venues[created.ID].IsActive, "is_active is unchanged when omitted")
+	assert.Equal(t, 2, cache.invalidations)
+
+	w = httptest.NewRecorder()
+	handler.DeleteVenue(w, venueAdminRequest(http.MethodDelete, "/api/venues/"+created.ID.Hex(), created.ID.Hex(), nil, auth.RoleAdmin))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Contains(t, store.venues, created.ID, "deleting only deactivates")
+	assert.False(t, store.venues[created.ID].IsActive)
+	assert.Equal(t, 3, cache.invalidations)
+}
+
+func TestVenueAdminHandler_Errors(t *testing.T) {
+	existingID := primitive.NewObjectID()
+	store := &MockVenueStore{venues: map[primitive.ObjectID]*models.Venue{
+		existingID: {ID: existingID, Name: "Victoria Park", BookingWindow: 7},
+	}}
+	cache := &MockVenueCacheInvalidator{}
+	handler := NewVenueAdminHandler(store, cache)
+	missingID := primitive.NewObjectID().Hex()
+
+	unknownScraper := validVenueRequest()
+	unknownScraper.Name = "Clissold Park"
+	unknownScraper.ScraperConfig.Type = "lta"
+
+	badTimezone := validVenueRequest()
+	badTimezone.Name = "Clissold Park"
+	badTimezone.Timezone = "Mars/Olympus"
+
+	tests := []struct {
+		name     string
+		call     func(w http.ResponseWriter)
+		expected int
+	}{
+		{"not an admin", func(w http.ResponseWriter) {
+			handler.CreateVenue(w, venueAdminRequest(http.MethodPost, "/api/venues", "", validVenueRequest(), ""))
+		}, http.StatusForbidden},
+		{"duplicate name", func(w http.ResponseWriter) {
+			handler.CreateVenue(w, venueAdminRequest(http.MethodPost, "/api/venues", "", validVenueRequest(), auth.RoleAdmin))
+		}, http.StatusConflict},
+		{"unknown scraper type", func(w http.ResponseWriter) {
+			handler.CreateVenue(w, venueAdminRequest(http.MethodPost, "/api/venues", "", unknownScraper, auth.RoleAdmin))
+		}, http.StatusBadRequest},
+		{"unknown timezone", func(w http.ResponseWriter) {
+			handler.CreateVenue(w, venueAdminRequest(http.MethodPost, "/api/venues", "", badTimezone, auth.RoleAdmin))
+		}, http.StatusBadRequest},
+		{"invalid ID", func(w http.ResponseWriter) {
+			handler.UpdateVenue(w, venueAdminRequest(http.MethodPut, "/api/venues/nope", "nope", validVenueRequest(), auth.RoleAdmin))
+		}, http.StatusBadRequest},
+		{"update missing venue", func(w http.ResponseWriter) {
+			handler.UpdateVenue(w, venueAdminRequest(http.MethodPut, "/api/venues/"+missingID, missingID, validVenueRequest(), auth.RoleAdmin))
+		}, http.StatusNotFound},
+		{"delete missing venue", func(w http.ResponseWriter) {
+			handler.DeleteVenue(w, venueAdminRequest(http.MethodDelete, "/api/venues/"+missingID, missingID, nil, auth.RoleAdmin))
+		}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		tt.call(w)
+		assert.Equal(t, tt.expected, w.Code, tt.name)
+	}
+	assert.Zero(t, cache.invalidations, "failed changes don't invalidate the cache")
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"sort"
+	"strings"
+
+	"tennis-booker/internal/models"
+)
+
+// Venue search match fields, reported as matchedField in search results
+const (
+	VenueMatchName     = "name"
+	VenueMatchPostCode = "postCode"
+	VenueMatchAddress  = "address"
+)
+
+// venueMatch is a venue found by searchVenues, with how well it matched
+type venueMatch struct {
+	venue models.Venue
+	field string
+	rank  int // Lower is better
+}
+
+// matchVenue reports whether venue matches the lowercased search term q and
+// ranks the match: an exact name, then a name prefix, then a word in the name
+// starting with q, then q anywhere in the name, then a postcode prefix, then q
+// anywhere in the address. Postcodes are compared without spaces, so "sw1a1aa"
+// finds "SW1A 1AA".
+func matchVenue(venue models.Venue, q string) (field string, rank int, ok bool) {
+	name := strings.ToLower(venue.Name)
+	switch {
+	case name == q:
+		return VenueMatchName, 0, true
+	case strings.HasPrefix(name, q):
+		return VenueMatchName, 1, true
+	case hasWordWithPrefix(name, q):
+		return VenueMatchName, 2, true
+	case strings.Contains(name, q):
+		return VenueMatchName, 3, true
+	}
+
+	postCode := strings.ReplaceAll(strings.ToLower(venue.Location.PostCode), " ", "")
+	if compact := strings.ReplaceAll(q, " ", ""); postCode != "" && compact != "" && strings.HasPrefix(postCode, compact) {
+		return VenueMatchPostCode, 4, true
+	}
+
+	if strings.Contains(strings.ToLower(venue.Location.Address), q) {
+		return VenueMatchAddress, 5, true
+	}
+	return "", 0, false
+}
+
+// hasWordWithPrefix reports whether any word of s starts with prefix
+func hasWordWithPrefix(s, prefix string) bool {
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == '-' || r == ',' }) {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// searchVenues returns the venues matching q, best matches first and then by name
+func searchVenues(venues []models.Venue, q string) []venueMatch {
+	q = strings.ToLower(strings.TrimSpace(q))
+
+	var matches []venueMatch
+	for _, venue := range venues {
+		if field, rank, ok := matchVenue(venue, q); ok {
+			matches = append(matches, venueMatch{venue: venue, field: field, rank: rank})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return matches[i].venue.Name < matches[j].venue.Name
+	})
+	return matches
+}
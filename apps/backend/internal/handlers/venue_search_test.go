@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tennis-booker/internal/models"
+)
+
+func TestMatchVenue(t *testing.T) {
+	venue := models.Venue{
+		Name:     "Victoria Park Tennis Centre",
+		Location: models.Location{Address: "Old Ford Road", PostCode: "E9 7BT"},
+	}
+
+	tests := []struct {
+		q     string
+		field string
+		rank  int
+		ok    bool
+	}{
+		{"victoria park tennis centre", VenueMatchName, 0, true},
+		{"vict", VenueMatchName, 1, true},
+		{"tennis", VenueMatchName, 2, true},
+		{"ark ten", VenueMatchName, 3, true},
+		{"e97", VenueMatchPostCode, 4, true},
+		{"e9 7b", VenueMatchPostCode, 4, true},
+		{"ford", VenueMatchAddress, 5, true},
+		{"clissold", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		field, rank, ok := matchVenue(venue, tt.q)
+		assert.Equal(t, tt.ok, ok, tt.q)
+		assert.Equal(t, tt.field, field, tt.q)
+		assert.Equal(t, tt.rank, rank, tt.q)
+	}
+}
+
+func TestCourtHandler_GetVenues_Search(t *testing.T) {
+	cache := &MockVenueRepository{venues: []*models.Venue{
+		{Name: "Albert Park", Provider: "lta", Location: models.Location{Address: "Victoria Road", PostCode: "M14 5AB"}},
+		{Name: "Victoria Park", Provider: "courtsides", Location: models.Location{PostCode: "E9 7BT"}},
+		{Name: "Queen Victoria Gardens", Provider: "lta", Location: models.Location{PostCode: "SW1A 1AA"}},
+		{Name: "Clissold Park", Provider: "lta", Location: models.Location{PostCode: "N16 9HJ"}},
+	}}
+	handler := &CourtHandler{venueCache: cache}
+
+	search := func(t *testing.T, query string) []VenueResponse {
+		w := httptest.NewRecorder()
+		handler.GetVenues(w, httptest.NewRequest(http.MethodGet, "/api/venues?"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code, query)
+
+		var response []VenueResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	results := search(t, "q=Victoria")
+	require.Len(t, results, 3)
+	assert.Equal(t, "Victoria Park", results[0].Name)
+	assert.Equal(t, "Queen Victoria Gardens", results[1].Name)
+	assert.Equal(t, "Albert Park", results[2].Name)
+	assert.Equal(t, VenueMatchAddress, results[2].MatchedField)
+
+	results = search(t, "q=victoria&provider=lta")
+	require.Len(t, results, 2)
+	assert.Equal(t, "Queen Victoria Gardens", results[0].Name)
+
+	results = search(t, "q=n16")
+	require.Len(t, results, 1)
+	assert.Equal(t, "Clissold Park", results[0].Name)
+	assert.Equal(t, VenueMatchPostCode, results[0].MatchedField)
+
+	results = search(t, "q=")
+	require.Len(t, results, 4)
+	assert.Equal(t, "Albert Park", results[0].Name)
+	assert.Empty(t, results[0].MatchedField)
+}
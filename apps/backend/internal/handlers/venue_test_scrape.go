@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"tennis-booker/internal/models"
+	"tennis-booker/internal/utils"
+)
+
+// VenueTestScraperInterface runs one-off scrapes on the scraper service
+type VenueTestScraperInterface interface {
+	TestScrape(ctx context.Context, req models.TestScrapeRequest) (*models.TestScrapeResult, error)
+}
+
+// SetTestScraper enables POST /api/venues/test-scrape
+func (h *VenueAdminHandler) SetTestScraper(scraper VenueTestScraperInterface) {
+	h.testScraper = scraper
+}
+
+// TestScrape handles POST /api/venues/test-scrape. It scrapes a single date
+// with the given scraper config and returns the slots found and how many
+// elements each selector matched, without saving anything. Admins only.
+func (h *VenueAdminHandler) TestScrape(w http.ResponseWriter, r *http.Request) {
+	if !utils.RequireAdmin(w, r) {
+		return
+	}
+
+	var req models.TestScrapeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		utils.WriteError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.testScraper == nil {
+		utils.WriteError(w, "Test scrapes are unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	result, err := h.testScraper.TestScrape(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, models.ErrTestScrapeTimeout) {
+			utils.WriteError(w, "The scraper did not respond in time", http.StatusGatewayTimeout)
+			return
+		}
+		courtLogger.WithContext(r.Context()).Error("Test scrape failed", map[string]interface{}{"url": req.URL, "error": err.Error()})
+		utils.WriteError(w, "Failed to run test scrape", http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteSuccess(w, result)
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"tennis-booker/internal/auth"
+	"tennis-booker/internal/models"
+)
+
+// MockVenueTestScraper records the request and returns a canned result or error
+type MockVenueTestScraper struct {
+	received *models.TestScrapeRequest
+	result   *models.TestScrapeResult
+	err      error
+}
+
+func (m *MockVenueTestScraper) TestScrape(ctx context.Context, req models.TestScrapeRequest) (*models.TestScrapeResult, error) {
+	m.received = &req
+	return m.result, m.err
+}
+
+func newTestScrapeHandler(scraper VenueTestScraperInterface) *VenueAdminHandler {
+	handler := NewVenueAdminHandler(&MockVenueStore{venues: map[primitive.ObjectID]*models.Venue{}}, nil)
+	if scraper != nil {
+		handler.SetTestScraper(scraper)
+	}
+	return handler
+}
+
+func validTestScrapeRequest() models.TestScrapeRequest {
+	return models.TestScrapeRequest{
+		URL:           "https://victoria.example.com/book",
+		ScraperConfig: models.ScraperConfig{Type: models.ScraperTypeCourtside},
+		Date:          "2030-06-01",
+	}
+}
+
+func TestVenueAdminHandler_TestScrape(t *testing.T) {
+	price := 12.5
+	scraper := &MockVenueTestScraper{result: &models.TestScrapeResult{
+		Success:   true,
+		Slots:     []models.TestScrapeSlot{{CourtName: "Court 1", Date: "2030-06-01", StartTime: "18:00", EndTime: "19:00", Price: &price}},
+		Selectors: []models.SelectorDiagnostic{{Name: "bookable_input", Selector: "input.bookable", Matches: 1}},
+		Errors:    []string{},
+	}}
+	handler := newTestScrapeHandler(scraper)
+
+	w := httptest.NewRecorder()
+	handler.TestScrape(w, venueAdminRequest(http.MethodPost, "/api/venues/test-scrape", "", validTestScrapeRequest(), auth.RoleAdmin))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result models.TestScrapeResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.True(t, result.Success)
+	require.Len(t, result.Slots, 1)
+	assert.Equal(t, "Court 1", result.Slots[0].CourtName)
+	require.Len(t, result.Selectors, 1)
+	assert.Equal(t, 1, result.Selectors[0].Matches)
+
+	require.NotNil(t, scraper.received)
+	assert.Equal(t, models.DefaultTestScrapeTimeoutSeconds, scraper.received.TimeoutSeconds, "the default timeout is applied")
+}
+
+func TestVenueAdminHandler_TestScrapeErrors(t *testing.T) {
+	invalidConfig := validTestScrapeRequest()
+	invalidConfig.ScraperConfig.Type = "unknown"
+
+	invalidURL := validTestScrapeRequest()
+	invalidURL.URL = "ftp://example.com"
+
+	tests := []struct {
+		name         string
+		scraper      VenueTestScraperInterface
+		body         models.TestScrapeRequest
+		role         string
+		expectedCode int
+	}{
+		{"non-admin", &MockVenueTestScraper{}, validTestScrapeRequest(), "user", http.StatusForbidden},
+		{"invalid scraper config", &MockVenueTestScraper{}, invalidConfig, auth.RoleAdmin, http.StatusBadRequest},
+		{"invalid url", &MockVenueTestScraper{}, invalidURL, auth.RoleAdmin, http.StatusBadRequest},
+		{"scraper unavailable", nil, validTestScrapeRequest(), auth.RoleAdmin, http.StatusServiceUnavailable},
+		{"scraper timed out", &MockVenueTestScraper{err: models.ErrTestScrapeTimeout}, validTestScrapeRequest(), auth.RoleAdmin, http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := newTestScrapeHandler(tt.scraper)
+			w := httptest.NewRecorder()
+			handler.TestScrape(w, venueAdminRequest(http.MethodPost, "/api/venues/test-scrape", "", tt.body, tt.role))
+			assert.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+		})
+	}
+}
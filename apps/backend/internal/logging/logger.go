@@ -1,11 +1,14 @@
 package logging
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -43,6 +46,7 @@ type Logger struct {
 	serviceName string
 	minLevel    LogLevel
 	logger      *log.Logger
+	requestID   string // Added to every entry; set by WithContext
 }
 
 // LogEntry represents a structured log entry
@@ -50,26 +54,43 @@ type LogEntry struct {
 	Timestamp   time.Time              `json:"timestamp"`
 	Level       string                 `json:"level"`
 	ServiceName string                 `json:"service"`
+	RequestID   string                 `json:"request_id,omitempty"`
 	Message     string                 `json:"message"`
 	Fields      map[string]interface{} `json:"fields,omitempty"`
 }
 
+// levelOverride replaces every logger's minimum level once SetLevel is called
+var levelOverride atomic.Pointer[LogLevel]
+
+// ParseLevel converts a level name such as "debug" or "WARN" to a LogLevel
+func ParseLevel(name string) (LogLevel, error) {
+	switch strings.ToUpper(name) {
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARN":
+		return WARN, nil
+	case "ERROR":
+		return ERROR, nil
+	case "FATAL":
+		return FATAL, nil
+	default:
+		return INFO, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// SetLevel changes the minimum level of all loggers, including ones already
+// created, so the level can be changed without restarting
+func SetLevel(level LogLevel) {
+	levelOverride.Store(&level)
+}
+
 // New creates a new structured logger
 func New(serviceName string) *Logger {
 	minLevel := INFO
-	if level := strings.ToUpper(os.Getenv("LOG_LEVEL")); level != "" {
-		switch level {
-		case "DEBUG":
-			minLevel = DEBUG
-		case "INFO":
-			minLevel = INFO
-		case "WARN":
-			minLevel = WARN
-		case "ERROR":
-			minLevel = ERROR
-		case "FATAL":
-			minLevel = FATAL
-		}
+	if level, err := ParseLevel(os.Getenv("LOG_LEVEL")); err == nil {
+		minLevel = level
 	}
 
 	return &Logger{
@@ -79,8 +100,42 @@ func New(serviceName string) *Logger {
 	}
 }
 
+// requestIDKey is the context key for a request's correlation ID
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request's correlation ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the correlation ID stored in ctx, or "" if there is none
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// WithContext returns a logger that adds the correlation ID from ctx to every
+// entry, so all log lines for one request can be tied together
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	requestID := RequestIDFromContext(ctx)
+	if requestID == "" {
+		return l
+	}
+	withID := *l
+	withID.requestID = requestID
+	return &withID
+}
+
+// SetOutput redirects the logger, e.g. to io.Discard in tests
+func (l *Logger) SetOutput(w io.Writer) {
+	l.logger.SetOutput(w)
+}
+
 // shouldLog checks if a message should be logged based on the minimum level
 func (l *Logger) shouldLog(level LogLevel) bool {
+	if override := levelOverride.Load(); override != nil {
+		return level >= *override
+	}
 	return level >= l.minLevel
 }
 
@@ -94,6 +149,7 @@ func (l *Logger) log(level LogLevel, message string, fields map[string]interface
 		Timestamp:   time.Now().UTC(),
 		Level:       level.String(),
 		ServiceName: l.serviceName,
+		RequestID:   l.requestID,
 		Message:     message,
 		Fields:      fields,
 	}
@@ -109,8 +165,11 @@ func (l *Logger) log(level LogLevel, message string, fields map[string]interface
 	} else {
 		// Human-readable format for development
 		var fieldsStr string
+		if l.requestID != "" {
+			fieldsStr = " request_id=" + l.requestID
+		}
 		if len(fields) > 0 {
-			fieldsStr = fmt.Sprintf(" %+v", fields)
+			fieldsStr += fmt.Sprintf(" %+v", fields)
 		}
 		l.logger.Printf("[%s] %s: %s%s", level.String(), l.serviceName, message, fieldsStr)
 	}
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"os"
 	"strings"
@@ -252,3 +253,66 @@ func TestLogLevels(t *testing.T) {
 	// Clean up
 	os.Unsetenv("LOG_LEVEL")
 }
+
+func TestSetLevel(t *testing.T) {
+	defer levelOverride.Store(nil)
+
+	logger := New("test-service")
+	logger.minLevel = INFO
+
+	level, err := ParseLevel("error")
+	if err != nil {
+		t.Fatalf("Failed to parse level: %v", err)
+	}
+	SetLevel(level)
+
+	if logger.shouldLog(WARN) {
+		t.Error("Existing loggers should follow the level set by SetLevel")
+	}
+	if !logger.shouldLog(ERROR) {
+		t.Error("Should log ERROR after SetLevel(ERROR)")
+	}
+
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
+
+func TestSetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New("test-service")
+	logger.SetOutput(&buf)
+
+	logger.Warn("redirected message")
+
+	if !strings.Contains(buf.String(), "redirected message") {
+		t.Error("Output should be written to the writer passed to SetOutput")
+	}
+}
+
+func TestWithContext_AddsRequestID(t *testing.T) {
+	os.Setenv("LOG_FORMAT", "json")
+	defer os.Unsetenv("LOG_FORMAT")
+
+	var buf bytes.Buffer
+	logger := New("test-service")
+	logger.SetOutput(&buf)
+
+	ctx := ContextWithRequestID(context.Background(), "req-123")
+	logger.WithContext(ctx).Info("handled request")
+
+	var logEntry LogEntry
+	if err := json.Unmarshal(buf.Bytes(), &logEntry); err != nil {
+		t.Fatalf("Failed to parse log entry: %v", err)
+	}
+	if logEntry.RequestID != "req-123" {
+		t.Errorf("Expected request ID 'req-123', got %q", logEntry.RequestID)
+	}
+
+	// Entries logged without a request context don't carry an ID
+	buf.Reset()
+	logger.WithContext(context.Background()).Info("background work")
+	if strings.Contains(buf.String(), "request_id") {
+		t.Error("Entries without a request ID should omit request_id")
+	}
+}
@@ -0,0 +1,183 @@
+// Package matching decides whether an available court slot matches a user's
+// preferences. It is the one place the rules live, so the notification
+// service, the retention service and the live slot stream agree on which
+// slots a user wants to hear about.
+package matching
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tennis-booker/internal/models"
+)
+
+// Reasons returned by Matches, naming the first preference the slot failed
+const (
+	// ReasonMatched: the slot satisfies every preference
+	ReasonMatched = "MATCHED"
+	// ReasonVenueExcluded: the slot's venue is in ExcludedVenues
+	ReasonVenueExcluded = "VENUE_EXCLUDED"
+	// ReasonVenueNotPreferred: PreferredVenues is set and doesn't include the slot's venue
+	ReasonVenueNotPreferred = "VENUE_NOT_PREFERRED"
+	// ReasonDayNotPreferred: PreferredDays is set and doesn't include the slot's day
+	ReasonDayNotPreferred = "DAY_NOT_PREFERRED"
+	// ReasonOutsideTimes: time windows are set and the slot doesn't start in one
+	ReasonOutsideTimes = "OUTSIDE_TIMES"
+	// ReasonOverMaxPrice: the slot costs more than MaxPrice
+	ReasonOverMaxPrice = "OVER_MAX_PRICE"
+)
+
+// Slot is what matching needs to know about an available court
+type Slot struct {
+	VenueID string    // Venue ObjectID hex, as stored in PreferredVenues and ExcludedVenues
+	Start   time.Time // In the user's timezone, which decides its weekday and clock time
+	Price   float64
+}
+
+// Preferences are the filters a slot is matched against. An empty field
+// doesn't filter.
+type Preferences struct {
+	PreferredVenues []string // Venue IDs to alert on
+	ExcludedVenues  []string // Venue IDs never alerted on; takes precedence over PreferredVenues
+	PreferredDays   []string // Lowercase weekday names, e.g. "tuesday"; checked before the time windows
+
+	// Windows the slot must start in, as "HH:MM" in the user's timezone. Times
+	// apply every day; WeekdayTimes Monday to Friday and WeekendTimes on
+	// Saturday and Sunday. With none set, any time matches; otherwise a slot
+	// must start in one of Times or its part of the week's windows.
+	Times        []models.TimeRange
+	WeekdayTimes []models.TimeRange
+	WeekendTimes []models.TimeRange
+
+	MaxPrice float64 // 0 means no limit
+}
+
+// FromUserPreferences returns the filters set in the user's saved preferences
+func FromUserPreferences(pref models.UserPreferences) Preferences {
+	return Preferences{
+		PreferredVenues: pref.PreferredVenues,
+		ExcludedVenues:  pref.ExcludedVenues,
+		PreferredDays:   pref.PreferredDays,
+		Times:           pref.Times,
+		WeekdayTimes:    pref.WeekdayTimes,
+		WeekendTimes:    pref.WeekendTimes,
+		MaxPrice:        pref.MaxPrice,
+	}
+}
+
+// Matches reports whether the slot satisfies the preferences. The reason is
+// ReasonMatched or names the first check the slot failed, in the order venue,
+// day, time, price.
+func Matches(slot Slot, prefs Preferences) (bool, string) {
+	if containsVenue(prefs.ExcludedVenues, slot) {
+		return false, ReasonVenueExcluded
+	}
+	if len(prefs.PreferredVenues) > 0 && !containsVenue(prefs.PreferredVenues, slot) {
+		return false, ReasonVenueNotPreferred
+	}
+
+	if !matchesDay(slot, prefs.PreferredDays) {
+		return false, ReasonDayNotPreferred
+	}
+
+	if !matchesTimes(slot, prefs) {
+		return false, ReasonOutsideTimes
+	}
+
+	// MaxPrice has no currency of its own, so it is compared in the slot's currency
+	if prefs.MaxPrice > 0 && slot.Price > prefs.MaxPrice {
+		return false, ReasonOverMaxPrice
+	}
+
+	return true, ReasonMatched
+}
+
+// containsVenue reports whether the venue list holds the slot's venue ID
+func containsVenue(venues []string, slot Slot) bool {
+	if slot.VenueID == "" {
+		return false
+	}
+	for _, venue := range venues {
+		if venue == slot.VenueID {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesDay reports whether the slot falls on one of the days; no days matches any day
+func matchesDay(slot Slot, days []string) bool {
+	if len(days) == 0 {
+		return true
+	}
+
+	slotDay := slot.Start.Weekday().String()
+	for _, day := range days {
+		if strings.EqualFold(day, slotDay) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTimes reports whether the slot starts in one of the windows that apply on its day
+func matchesTimes(slot Slot, prefs Preferences) bool {
+	if len(prefs.Times) == 0 && len(prefs.WeekdayTimes) == 0 && len(prefs.WeekendTimes) == 0 {
+		return true
+	}
+
+	partOfWeek := prefs.WeekdayTimes
+	if weekday := slot.Start.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		partOfWeek = prefs.WeekendTimes
+	}
+
+	start := slot.Start.Hour()*60 + slot.Start.Minute()
+	for _, windows := range [][]models.TimeRange{prefs.Times, partOfWeek} {
+		for _, window := range windows {
+			if startsIn(start, window) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// startsIn reports whether minutes since midnight fall in [window.Start, window.End).
+// A window that doesn't parse matches nothing.
+func startsIn(minutes int, window models.TimeRange) bool {
+	start, err := ParseClock(window.Start)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(window.End)
+	if err != nil {
+		return false
+	}
+	return minutes >= start && minutes < end
+}
+
+// ParseClock parses an "HH:MM" time to minutes since midnight
+func ParseClock(clock string) (int, error) {
+	parts := strings.Split(clock, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time format: %s", clock)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("invalid hours: %s", parts[0])
+	}
+
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid minutes: %s", parts[1])
+	}
+
+	if hours < 0 || hours > 23 || minutes < 0 || minutes > 59 {
+		return 0, fmt.Errorf("time out of range: %s", clock)
+	}
+
+	return hours*60 + minutes, nil
+}
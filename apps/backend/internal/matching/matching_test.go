@@ -0,0 +1,295 @@
+package matching
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tennis-booker/internal/models"
+)
+
+func TestMatches(t *testing.T) {
+	// Monday 10:00
+	slot := Slot{
+		VenueID: "665f1c2a9b1e8a0012345678",
+		Start:   time.Date(2025, 6, 16, 10, 0, 0, 0, time.UTC),
+		Price:   25.0,
+	}
+	// Saturday 10:00
+	weekendSlot := slot
+	weekendSlot.Start = time.Date(2025, 6, 21, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		slot       Slot
+		prefs      Preferences
+		wantReason string
+	}{
+		{
+			name:       "no preferences",
+			slot:       slot,
+			wantReason: ReasonMatched,
+		},
+
+		// Venues
+		{
+			name:       "preferred venue by ID",
+			slot:       slot,
+			prefs:      Preferences{PreferredVenues: []string{slot.VenueID}},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "venue names aren't matched",
+			slot:       slot,
+			prefs:      Preferences{PreferredVenues: []string{"Test Tennis Club"}},
+			wantReason: ReasonVenueNotPreferred,
+		},
+		{
+			name:       "different preferred venue",
+			slot:       slot,
+			prefs:      Preferences{PreferredVenues: []string{"665f1c2a9b1e8a0087654321"}},
+			wantReason: ReasonVenueNotPreferred,
+		},
+		{
+			name:       "empty venue ID doesn't match a slot without one",
+			slot:       Slot{Start: slot.Start},
+			prefs:      Preferences{PreferredVenues: []string{""}},
+			wantReason: ReasonVenueNotPreferred,
+		},
+		{
+			name:       "excluded venue by ID",
+			slot:       slot,
+			prefs:      Preferences{ExcludedVenues: []string{slot.VenueID}},
+			wantReason: ReasonVenueExcluded,
+		},
+		{
+			name:       "excluded takes precedence over preferred",
+			slot:       slot,
+			prefs:      Preferences{PreferredVenues: []string{slot.VenueID}, ExcludedVenues: []string{slot.VenueID}},
+			wantReason: ReasonVenueExcluded,
+		},
+
+		// Days
+		{
+			name:       "preferred day",
+			slot:       slot,
+			prefs:      Preferences{PreferredDays: []string{"monday"}},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "preferred day is case insensitive",
+			slot:       slot,
+			prefs:      Preferences{PreferredDays: []string{"MONDAY"}},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "one of several preferred days",
+			slot:       slot,
+			prefs:      Preferences{PreferredDays: []string{"tuesday", "monday", "friday"}},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "not a preferred day",
+			slot:       slot,
+			prefs:      Preferences{PreferredDays: []string{"tuesday", "wednesday"}},
+			wantReason: ReasonDayNotPreferred,
+		},
+
+		// Times
+		{
+			name:       "starts at the window start",
+			slot:       slot,
+			prefs:      Preferences{Times: []models.TimeRange{{Start: "10:00", End: "11:00"}}},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "starts inside the window",
+			slot:       slot,
+			prefs:      Preferences{Times: []models.TimeRange{{Start: "09:00", End: "10:30"}}},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "starts at the window end",
+			slot:       slot,
+			prefs:      Preferences{Times: []models.TimeRange{{Start: "09:00", End: "10:00"}}},
+			wantReason: ReasonOutsideTimes,
+		},
+		{
+			name:       "starts before the window",
+			slot:       slot,
+			prefs:      Preferences{Times: []models.TimeRange{{Start: "10:15", End: "10:45"}}},
+			wantReason: ReasonOutsideTimes,
+		},
+		{
+			name: "one of several windows",
+			slot: slot,
+			prefs: Preferences{Times: []models.TimeRange{
+				{Start: "07:00", End: "08:00"},
+				{Start: "09:30", End: "10:30"},
+				{Start: "14:00", End: "16:00"},
+			}},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "weekday window on a weekday",
+			slot:       slot,
+			prefs:      Preferences{WeekdayTimes: []models.TimeRange{{Start: "09:00", End: "12:00"}}},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "weekday window on a weekend",
+			slot:       weekendSlot,
+			prefs:      Preferences{WeekdayTimes: []models.TimeRange{{Start: "09:00", End: "12:00"}}},
+			wantReason: ReasonOutsideTimes,
+		},
+		{
+			name:       "weekend window on a weekday",
+			slot:       slot,
+			prefs:      Preferences{WeekendTimes: []models.TimeRange{{Start: "09:00", End: "12:00"}}},
+			wantReason: ReasonOutsideTimes,
+		},
+		{
+			name: "weekend window on a weekend",
+			slot: weekendSlot,
+			prefs: Preferences{
+				WeekdayTimes: []models.TimeRange{{Start: "18:00", End: "21:00"}},
+				WeekendTimes: []models.TimeRange{{Start: "09:00", End: "12:00"}},
+			},
+			wantReason: ReasonMatched,
+		},
+		{
+			name: "every-day window alongside weekday windows",
+			slot: weekendSlot,
+			prefs: Preferences{
+				Times:        []models.TimeRange{{Start: "09:00", End: "12:00"}},
+				WeekdayTimes: []models.TimeRange{{Start: "18:00", End: "21:00"}},
+			},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "unparseable window matches nothing",
+			slot:       slot,
+			prefs:      Preferences{Times: []models.TimeRange{{Start: "0900", End: "12:00"}}},
+			wantReason: ReasonOutsideTimes,
+		},
+
+		// Price
+		{
+			name:       "within budget",
+			slot:       slot,
+			prefs:      Preferences{MaxPrice: 30.0},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "exactly at the max price",
+			slot:       slot,
+			prefs:      Preferences{MaxPrice: 25.0},
+			wantReason: ReasonMatched,
+		},
+		{
+			name:       "over the max price",
+			slot:       slot,
+			prefs:      Preferences{MaxPrice: 20.0},
+			wantReason: ReasonOverMaxPrice,
+		},
+		{
+			name:       "negative max price is no limit",
+			slot:       slot,
+			prefs:      Preferences{MaxPrice: -1},
+			wantReason: ReasonMatched,
+		},
+
+		// Order of checks
+		{
+			name: "venue is checked before day, time and price",
+			slot: slot,
+			prefs: Preferences{
+				ExcludedVenues: []string{slot.VenueID},
+				PreferredDays:  []string{"tuesday"},
+				Times:          []models.TimeRange{{Start: "18:00", End: "21:00"}},
+				MaxPrice:       10,
+			},
+			wantReason: ReasonVenueExcluded,
+		},
+		{
+			name: "day is checked before time and price",
+			slot: slot,
+			prefs: Preferences{
+				PreferredDays: []string{"tuesday"},
+				Times:         []models.TimeRange{{Start: "18:00", End: "21:00"}},
+				MaxPrice:      10,
+			},
+			wantReason: ReasonDayNotPreferred,
+		},
+		{
+			name: "time is checked before price",
+			slot: slot,
+			prefs: Preferences{
+				Times:    []models.TimeRange{{Start: "18:00", End: "21:00"}},
+				MaxPrice: 10,
+			},
+			wantReason: ReasonOutsideTimes,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, reason := Matches(tt.slot, tt.prefs)
+			assert.Equal(t, tt.wantReason, reason)
+			assert.Equal(t, tt.wantReason == ReasonMatched, matches)
+		})
+	}
+}
+
+func TestFromUserPreferences(t *testing.T) {
+	pref := models.UserPreferences{
+		PreferredVenues: []string{"venue-1"},
+		ExcludedVenues:  []string{"venue-2"},
+		PreferredDays:   []string{"tuesday"},
+		Times:           []models.TimeRange{{Start: "07:00", End: "09:00"}},
+		WeekdayTimes:    []models.TimeRange{{Start: "18:00", End: "21:00"}},
+		WeekendTimes:    []models.TimeRange{{Start: "09:00", End: "12:00"}},
+		MaxPrice:        30,
+		TargetPrice:     15,
+	}
+
+	assert.Equal(t, Preferences{
+		PreferredVenues: []string{"venue-1"},
+		ExcludedVenues:  []string{"venue-2"},
+		PreferredDays:   []string{"tuesday"},
+		Times:           []models.TimeRange{{Start: "07:00", End: "09:00"}},
+		WeekdayTimes:    []models.TimeRange{{Start: "18:00", End: "21:00"}},
+		WeekendTimes:    []models.TimeRange{{Start: "09:00", End: "12:00"}},
+		MaxPrice:        30,
+	}, FromUserPreferences(pref))
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		clock   string
+		want    int
+		wantErr bool
+	}{
+		{clock: "10:30", want: 630},
+		{clock: "00:00", want: 0},
+		{clock: "23:59", want: 23*60 + 59},
+		{clock: "1030", wantErr: true},
+		{clock: "24:00", wantErr: true},
+		{clock: "10:60", wantErr: true},
+		{clock: "ab:cd", wantErr: true},
+		{clock: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.clock, func(t *testing.T) {
+			got, err := ParseClock(tt.clock)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
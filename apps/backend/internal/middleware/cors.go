@@ -2,71 +2,89 @@ package middleware
 
 import (
 	"net/http"
-	"os"
 	"strings"
+
+	"tennis-booker/internal/config"
 )
 
-// CORSMiddleware handles CORS headers for all requests
-func CORSMiddleware() func(http.Handler) http.Handler {
+// CORSMiddleware handles CORS headers for all requests. The request origin is
+// reflected only when it is in cfg.AllowedOrigins; entries like
+// "https://*.vercel.app" match any subdomain. A "*" entry allows every origin
+// for development, but then credentials are not allowed since browsers reject
+// credentialed requests to a wildcard origin.
+func CORSMiddleware(cfg config.CORSConfig) func(http.Handler) http.Handler {
+	allowAny := cfg.AllowsAnyOrigin()
+	allowedMethods := strings.Join(cfg.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(withRequestIDHeader(cfg.AllowedHeaders), ", ")
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			origin := r.Header.Get("Origin")
-
-			// Define allowed origins
-			allowedOrigins := []string{
-				"http://localhost:3000",
-				"http://localhost:5173",
-				"http://127.0.0.1:3000",
-				"http://127.0.0.1:5173",
-				"http://localhost:8080",
-				"http://127.0.0.1:8080",
-			}
-
-			// Add production frontend URL from environment
-			if frontendURL := os.Getenv("FRONTEND_URL"); frontendURL != "" {
-				allowedOrigins = append(allowedOrigins, frontendURL)
-			}
-
-			// Add Vercel preview deployment domains (pattern: https://*-username.vercel.app)
-			if strings.Contains(origin, ".vercel.app") {
-				allowedOrigins = append(allowedOrigins, origin)
-			}
-
-			// Check if origin is allowed
-			isAllowed := false
-			for _, allowedOrigin := range allowedOrigins {
-				if origin == allowedOrigin {
-					isAllowed = true
-					break
-				}
-			}
-
-			// For development, be more permissive with localhost
-			if !isAllowed && (strings.Contains(origin, "localhost") || strings.Contains(origin, "127.0.0.1")) {
-				isAllowed = true
+			if origin == "" {
+				// Not a cross-origin request
+				next.ServeHTTP(w, r)
+				return
 			}
 
-			if isAllowed {
+			switch {
+			case allowAny:
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			case originAllowed(origin, cfg.AllowedOrigins):
 				w.Header().Set("Access-Control-Allow-Origin", origin)
-			} else {
-				// Default to localhost for development
-				w.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+				w.Header().Add("Vary", "Origin")
+			default:
+				w.Header().Add("Vary", "Origin")
+				if r.Method == http.MethodOptions {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				// Without CORS headers the browser won't expose the response
+				next.ServeHTTP(w, r)
+				return
 			}
 
-			// Set CORS headers
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, PATCH, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With, Accept, Origin")
-			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Allow-Methods", allowedMethods)
+			w.Header().Set("Access-Control-Allow-Headers", allowedHeaders)
+			w.Header().Set("Access-Control-Expose-Headers", RequestIDHeader)
 			w.Header().Set("Access-Control-Max-Age", "86400") // 24 hours
 
 			// Handle preflight requests
-			if r.Method == "OPTIONS" {
+			if r.Method == http.MethodOptions {
 				w.WriteHeader(http.StatusOK)
 				return
 			}
 
-			// Continue to next handler
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+// originAllowed matches origin against the allowlist, where "scheme://*.domain"
+// entries match any subdomain of domain
+func originAllowed(origin string, allowed []string) bool {
+	for _, entry := range allowed {
+		if entry == origin {
+			return true
+		}
+		scheme, domain, ok := strings.Cut(entry, "://*.")
+		if !ok {
+			continue
+		}
+		host, found := strings.CutPrefix(origin, scheme+"://")
+		if found && len(host) > len(domain)+1 && strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// withRequestIDHeader makes sure clients can send their own request IDs
+func withRequestIDHeader(headers []string) []string {
+	for _, header := range headers {
+		if strings.EqualFold(header, RequestIDHeader) {
+			return headers
+		}
+	}
+	return append(append([]string{}, headers...), RequestIDHeader)
+}
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tennis-booker/internal/config"
+)
+
+func corsRequest(t *testing.T, cfg config.CORSConfig, method, origin string) (*httptest.ResponseRecorder, bool) {
+	t.Helper()
+	called := false
+	handler := CORSMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(method, "/api/venues", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w, called
+}
+
+func TestCORSMiddleware_Allowlist(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowedOrigins: []string{"https://tennis.example.com", "https://*.vercel.app"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+	}
+
+	for _, origin := range []string{"https://tennis.example.com", "https://preview-123.vercel.app"} {
+		w, called := corsRequest(t, cfg, http.MethodGet, origin)
+		if !called {
+			t.Errorf("Expected the request from %s to reach the handler", origin)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != origin {
+			t.Errorf("Expected %s to be reflected, got %q", origin, got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+			t.Errorf("Expected credentials to be allowed for %s, got %q", origin, got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(got, RequestIDHeader) {
+			t.Errorf("Expected %s to be an allowed header, got %q", RequestIDHeader, got)
+		}
+	}
+
+	for _, origin := range []string{"https://evil.example.com", "http://tennis.example.com", "https://vercel.app", "https://evil.com.vercel.app.evil.com"} {
+		w, called := corsRequest(t, cfg, http.MethodGet, origin)
+		if !called {
+			t.Errorf("Expected the simple request from %s to reach the handler", origin)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Expected no allowed origin for %s, got %q", origin, got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Errorf("Expected no credentials header for %s, got %q", origin, got)
+		}
+	}
+}
+
+func TestCORSMiddleware_Preflight(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowedOrigins: []string{"https://tennis.example.com"},
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Content-Type"},
+	}
+
+	w, called := corsRequest(t, cfg, http.MethodOptions, "https://tennis.example.com")
+	if called || w.Code != http.StatusOK {
+		t.Errorf("Expected an allowed preflight to be answered with 200, got %d (handler called: %v)", w.Code, called)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+		t.Errorf("Expected the configured methods, got %q", got)
+	}
+
+	w, called = corsRequest(t, cfg, http.MethodOptions, "https://evil.example.com")
+	if called || w.Code != http.StatusForbidden {
+		t.Errorf("Expected a disallowed preflight to be rejected with 403, got %d (handler called: %v)", w.Code, called)
+	}
+}
+
+func TestCORSMiddleware_Wildcard(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{"GET"},
+		AllowedHeaders: []string{"Content-Type"},
+	}
+
+	w, _ := corsRequest(t, cfg, http.MethodGet, "https://anywhere.example.com")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Expected a wildcard origin, got %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Expected credentials not to be allowed with a wildcard origin, got %q", got)
+	}
+}
+
+func TestCORSMiddleware_SameOrigin(t *testing.T) {
+	cfg := config.CORSConfig{AllowedOrigins: []string{"https://tennis.example.com"}}
+
+	w, called := corsRequest(t, cfg, http.MethodGet, "")
+	if !called {
+		t.Error("Expected a request without an Origin to reach the handler")
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Expected no CORS headers without an Origin, got %q", got)
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import "net/http"
+
+// FeatureFlags reports whether a feature is enabled; satisfied by config.Live
+type FeatureFlags interface {
+	IsFeatureEnabled(feature string) bool
+}
+
+// RequireFeature hides routes behind a feature flag, answering 404 while the
+// feature is disabled. Flags are checked on every request, so routes appear and
+// disappear when the configuration is reloaded.
+func RequireFeature(flags FeatureFlags, feature string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !flags.IsFeatureEnabled(feature) {
+				http.NotFound(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type staticFeatureFlags map[string]bool
+
+func (f staticFeatureFlags) IsFeatureEnabled(feature string) bool {
+	return f[feature]
+}
+
+func TestRequireFeature(t *testing.T) {
+	flags := staticFeatureFlags{"analytics": true}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	tests := []struct {
+		feature        string
+		expectedStatus int
+	}{
+		{"analytics", http.StatusOK},
+		{"notifications", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.feature, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			RequireFeature(flags, tt.feature)(ok).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/dashboard/stats", nil))
+			if w.Code != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+		})
+	}
+
+	// Flags are read per request
+	flags["notifications"] = true
+	w := httptest.NewRecorder()
+	RequireFeature(flags, "notifications")(ok).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/notifications/history", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected the route to appear once the flag is enabled, got %d", w.Code)
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tennis-booker/internal/logging"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	var seen string
+	handler := RequestIDMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestIDFromContext(r.Context())
+	}))
+
+	// A well-formed client ID is kept
+	req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+	req.Header.Set(RequestIDHeader, "client-id-123")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if seen != "client-id-123" {
+		t.Errorf("Expected the client's request ID in the context, got %q", seen)
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "client-id-123" {
+		t.Errorf("Expected the request ID to be echoed back, got %q", got)
+	}
+
+	// Missing or malformed IDs are replaced with a generated one
+	for _, clientID := range []string{"", "bad id\nwith newline", strings.Repeat("a", maxRequestIDLength+1)} {
+		req := httptest.NewRequest(http.MethodGet, "/api/health", nil)
+		req.Header.Set(RequestIDHeader, clientID)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if len(seen) != 32 || seen == clientID {
+			t.Errorf("Expected a generated request ID for %q, got %q", clientID, seen)
+		}
+		if got := w.Header().Get(RequestIDHeader); got != seen {
+			t.Errorf("Expected the generated request ID to be echoed back, got %q", got)
+		}
+	}
+}
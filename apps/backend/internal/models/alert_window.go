@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+const minutesPerDay = 24 * 60
+
+// parseClockMinutes converts "HH:MM" to minutes since midnight
+func parseClockMinutes(value string) (int, bool) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// IsWithinAlertTimeWindow reports whether t's wall-clock time falls inside the
+// [start, end] alert window (both "HH:MM", inclusive). A window whose end is
+// before its start spans midnight, e.g. 22:00-07:00. An empty or invalid
+// window allows alerts at any time.
+func IsWithinAlertTimeWindow(start, end string, t time.Time) bool {
+	startMin, okStart := parseClockMinutes(start)
+	endMin, okEnd := parseClockMinutes(end)
+	if !okStart || !okEnd {
+		return true
+	}
+
+	current := t.Hour()*60 + t.Minute()
+	if startMin <= endMin {
+		return current >= startMin && current <= endMin
+	}
+	return current >= startMin || current <= endMin
+}
+
+// NextAlertWindowOpen returns the first time at or after t that falls inside
+// the alert window, in t's location. It returns t itself when t is already
+// inside the window or no valid window is configured.
+func NextAlertWindowOpen(start, end string, t time.Time) time.Time {
+	if IsWithinAlertTimeWindow(start, end, t) {
+		return t
+	}
+
+	startMin, _ := parseClockMinutes(start)
+	current := t.Hour()*60 + t.Minute()
+
+	wait := startMin - current
+	if wait <= 0 {
+		wait += minutesPerDay
+	}
+
+	open := t.Truncate(time.Minute).Add(time.Duration(wait) * time.Minute)
+	// Re-anchor to the wall clock so DST changes don't shift the opening minute
+	return time.Date(open.Year(), open.Month(), open.Day(), startMin/60, startMin%60, 0, 0, t.Location())
+}
+
+// IsWithinAlertTimeWindow reports whether alerts may be sent at t under these settings.
+// t should already be in the user's timezone.
+func (n NotificationSettings) IsWithinAlertTimeWindow(t time.Time) bool {
+	return IsWithinAlertTimeWindow(n.AlertTimeWindowStart, n.AlertTimeWindowEnd, t)
+}
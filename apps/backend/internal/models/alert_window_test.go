@@ -0,0 +1,74 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func clock(hour, minute int) time.Time {
+	return time.Date(2024, 6, 15, hour, minute, 0, 0, time.UTC)
+}
+
+func TestIsWithinAlertTimeWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    string
+		end      string
+		at       time.Time
+		expected bool
+	}{
+		// Same-day window 07:00-22:00
+		{"same-day inside", "07:00", "22:00", clock(12, 0), true},
+		{"same-day before start", "07:00", "22:00", clock(6, 59), false},
+		{"same-day at start", "07:00", "22:00", clock(7, 0), true},
+		{"same-day at end", "07:00", "22:00", clock(22, 0), true},
+		{"same-day after end", "07:00", "22:00", clock(22, 1), false},
+		{"same-day at midnight", "07:00", "22:00", clock(0, 0), false},
+
+		// Overnight window 22:00-06:00
+		{"overnight late evening", "22:00", "06:00", clock(23, 30), true},
+		{"overnight early morning", "22:00", "06:00", clock(3, 0), true},
+		{"overnight at midnight", "22:00", "06:00", clock(0, 0), true},
+		{"overnight at start", "22:00", "06:00", clock(22, 0), true},
+		{"overnight minute before start", "22:00", "06:00", clock(21, 59), false},
+		{"overnight at end", "22:00", "06:00", clock(6, 0), true},
+		{"overnight minute after end", "22:00", "06:00", clock(6, 1), false},
+		{"overnight midday", "22:00", "06:00", clock(12, 0), false},
+
+		// Degenerate and missing windows
+		{"single minute window", "09:15", "09:15", clock(9, 15), true},
+		{"single minute window miss", "09:15", "09:15", clock(9, 16), false},
+		{"no window", "", "", clock(3, 0), true},
+		{"start only", "07:00", "", clock(3, 0), true},
+		{"invalid window", "7am", "10pm", clock(3, 0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsWithinAlertTimeWindow(tt.start, tt.end, tt.at))
+		})
+	}
+}
+
+func TestNotificationSettings_IsWithinAlertTimeWindow(t *testing.T) {
+	settings := NotificationSettings{AlertTimeWindowStart: "22:00", AlertTimeWindowEnd: "06:00"}
+
+	assert.True(t, settings.IsWithinAlertTimeWindow(clock(1, 0)))
+	assert.False(t, settings.IsWithinAlertTimeWindow(clock(14, 0)))
+}
+
+func TestNextAlertWindowOpen(t *testing.T) {
+	// Already inside: unchanged
+	assert.Equal(t, clock(12, 0), NextAlertWindowOpen("07:00", "22:00", clock(12, 0)))
+
+	// Before the window opens today
+	assert.Equal(t, clock(7, 0), NextAlertWindowOpen("07:00", "22:00", clock(3, 0)))
+
+	// After it closed: opens tomorrow
+	assert.Equal(t, clock(7, 0).AddDate(0, 0, 1), NextAlertWindowOpen("07:00", "22:00", clock(22, 1)))
+
+	// Overnight window, daytime quiet period
+	assert.Equal(t, clock(22, 0), NextAlertWindowOpen("22:00", "06:00", clock(12, 0)))
+}
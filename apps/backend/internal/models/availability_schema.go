@@ -0,0 +1,102 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrUnknownSchemaVersion is returned for availability messages published with
+// a schema version this build has no decoder for, typically by a newer producer
+var ErrUnknownSchemaVersion = errors.New("unknown court availability schema version")
+
+// availabilityDecoders decode each published schema version, upgrading older
+// messages to the current CourtAvailabilityEvent. Add a decoder here whenever
+// CourtAvailabilitySchemaVersion is bumped, migrating the previous version.
+var availabilityDecoders = map[int]func(data []byte) (CourtAvailabilityEvent, error){
+	0: decodeUnversionedAvailability,
+	1: decodeAvailabilityV1,
+}
+
+// DecodeCourtAvailabilityEvent decodes an availability message from Redis,
+// dispatching on its schema_version and migrating older versions to the
+// current event. Versions without a decoder return ErrUnknownSchemaVersion.
+func DecodeCourtAvailabilityEvent(data []byte) (CourtAvailabilityEvent, error) {
+	var version struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &version); err != nil {
+		return CourtAvailabilityEvent{}, err
+	}
+
+	decode, ok := availabilityDecoders[version.SchemaVersion]
+	if !ok {
+		return CourtAvailabilityEvent{}, fmt.Errorf("%w: %d", ErrUnknownSchemaVersion, version.SchemaVersion)
+	}
+
+	event, err := decode(data)
+	if err != nil {
+		return CourtAvailabilityEvent{}, err
+	}
+	event.SchemaVersion = CourtAvailabilitySchemaVersion
+	return event, nil
+}
+
+// decodeAvailabilityV1 decodes the first versioned event, the current shape
+func decodeAvailabilityV1(data []byte) (CourtAvailabilityEvent, error) {
+	var event CourtAvailabilityEvent
+	err := json.Unmarshal(data, &event)
+	return event, err
+}
+
+// legacySlotMessage is the camelCase slot the scraper pushed onto the slot
+// queue before availability messages were versioned
+type legacySlotMessage struct {
+	VenueID     string    `json:"venueId"`
+	VenueName   string    `json:"venueName"`
+	Platform    string    `json:"platform"`
+	CourtID     string    `json:"courtId"`
+	CourtName   string    `json:"courtName"`
+	Date        string    `json:"date"`
+	StartTime   string    `json:"startTime"`
+	EndTime     string    `json:"endTime"`
+	Price       float64   `json:"price"`
+	Currency    string    `json:"currency"`
+	IsAvailable bool      `json:"isAvailable"`
+	BookingURL  string    `json:"bookingUrl"`
+	ScrapedAt   time.Time `json:"scrapedAt"`
+}
+
+// decodeUnversionedAvailability migrates messages from before versioning.
+// The backend already published snake_case events, which only lack the
+// version; the scraper's camelCase slots are told apart by their venueId.
+func decodeUnversionedAvailability(data []byte) (CourtAvailabilityEvent, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return CourtAvailabilityEvent{}, err
+	}
+	if _, ok := keys["venueId"]; !ok {
+		return decodeAvailabilityV1(data)
+	}
+
+	var slot legacySlotMessage
+	if err := json.Unmarshal(data, &slot); err != nil {
+		return CourtAvailabilityEvent{}, err
+	}
+	return CourtAvailabilityEvent{
+		VenueID:      slot.VenueID,
+		VenueName:    slot.VenueName,
+		Platform:     slot.Platform,
+		CourtID:      slot.CourtID,
+		CourtName:    slot.CourtName,
+		Date:         slot.Date,
+		StartTime:    slot.StartTime,
+		EndTime:      slot.EndTime,
+		Price:        slot.Price,
+		Currency:     slot.Currency,
+		BookingURL:   slot.BookingURL,
+		Available:    &slot.IsAvailable,
+		DiscoveredAt: slot.ScrapedAt,
+	}, nil
+}
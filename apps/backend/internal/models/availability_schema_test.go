@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCourtAvailabilityEvent_CurrentVersion(t *testing.T) {
+	available := false
+	published := CourtAvailabilityEvent{
+		SchemaVersion: CourtAvailabilitySchemaVersion,
+		VenueID:       "v1", VenueName: "Victoria Park", Platform: "courtside", CourtID: "c1", CourtName: "Court 1",
+		Date: "2024-06-15", StartTime: "18:00", EndTime: "19:00", Price: 12.5, Currency: "GBP",
+		BookingURL: "https://example.com/vp", Available: &available,
+		DiscoveredAt: time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC),
+	}
+	data, err := json.Marshal(published)
+	require.NoError(t, err)
+
+	event, err := DecodeCourtAvailabilityEvent(data)
+	require.NoError(t, err)
+	assert.Equal(t, published, event)
+	assert.False(t, event.IsAvailable())
+}
+
+func TestDecodeCourtAvailabilityEvent_MigratesUnversioned(t *testing.T) {
+	tests := []struct {
+		name          string
+		message       string
+		wantAvailable bool
+	}{
+		{
+			name: "scraper slot",
+			message: `{"venueId":"v1","venueName":"Victoria Park","platform":"courtside","courtId":"c1","courtName":"Court 1",` +
+				`"date":"2024-06-15","startTime":"18:00","endTime":"19:00","price":12.5,"currency":"GBP",` +
+				`"isAvailable":true,"bookingUrl":"https://example.com/vp","scrapedAt":"2024-06-10T09:00:00Z"}`,
+			wantAvailable: true,
+		},
+		{
+			name: "backend event",
+			message: `{"venue_id":"v1","venue_name":"Victoria Park","platform":"courtside","court_id":"c1","court_name":"Court 1",` +
+				`"date":"2024-06-15","start_time":"18:00","end_time":"19:00","price":12.5,"currency":"GBP",` +
+				`"booking_url":"https://example.com/vp","discovered_at":"2024-06-10T09:00:00Z"}`,
+			wantAvailable: true,
+		},
+		{
+			name:          "booked scraper slot",
+			message:       `{"venueId":"v1","venueName":"Victoria Park","courtId":"c1","courtName":"Court 1","isAvailable":false}`,
+			wantAvailable: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := DecodeCourtAvailabilityEvent([]byte(tt.message))
+			require.NoError(t, err)
+
+			assert.Equal(t, CourtAvailabilitySchemaVersion, event.SchemaVersion)
+			assert.Equal(t, "v1", event.VenueID)
+			assert.Equal(t, "Victoria Park", event.VenueName)
+			assert.Equal(t, "Court 1", event.CourtName)
+			assert.Equal(t, tt.wantAvailable, event.IsAvailable())
+		})
+	}
+
+	event, err := DecodeCourtAvailabilityEvent([]byte(tests[0].message))
+	require.NoError(t, err)
+	assert.Equal(t, "18:00", event.StartTime)
+	assert.Equal(t, "https://example.com/vp", event.BookingURL)
+	assert.Equal(t, time.Date(2024, 6, 10, 9, 0, 0, 0, time.UTC), event.DiscoveredAt)
+}
+
+func TestDecodeCourtAvailabilityEvent_Rejects(t *testing.T) {
+	_, err := DecodeCourtAvailabilityEvent([]byte(`{"schema_version":99,"venue_id":"v1"}`))
+	assert.ErrorIs(t, err, ErrUnknownSchemaVersion)
+
+	_, err = DecodeCourtAvailabilityEvent([]byte(`{"schema_version":"1"}`))
+	assert.Error(t, err)
+
+	_, err = DecodeCourtAvailabilityEvent([]byte("not json"))
+	assert.Error(t, err)
+}
@@ -20,6 +20,31 @@ const (
 	BookingStatusCancelled BookingStatus = "cancelled"
 )
 
+// bookingTransitions lists the statuses a booking may move to from each status.
+// Confirmed, failed and cancelled bookings are final.
+var bookingTransitions = map[BookingStatus][]BookingStatus{
+	BookingStatusPending: {BookingStatusConfirmed, BookingStatusFailed, BookingStatusCancelled},
+}
+
+// IsValid reports whether s is a known booking status
+func (s BookingStatus) IsValid() bool {
+	switch s {
+	case BookingStatusPending, BookingStatusConfirmed, BookingStatusFailed, BookingStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether a booking in status s may move to next
+func (s BookingStatus) CanTransitionTo(next BookingStatus) bool {
+	for _, allowed := range bookingTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 // Booking represents a tennis court booking
 type Booking struct {
 	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBookingStatus_IsValid(t *testing.T) {
+	for _, status := range []BookingStatus{BookingStatusPending, BookingStatusConfirmed, BookingStatusFailed, BookingStatusCancelled} {
+		assert.True(t, status.IsValid(), status)
+	}
+	assert.False(t, BookingStatus("").IsValid())
+	assert.False(t, BookingStatus("booked").IsValid())
+}
+
+func TestBookingStatus_CanTransitionTo(t *testing.T) {
+	assert.True(t, BookingStatusPending.CanTransitionTo(BookingStatusConfirmed))
+	assert.True(t, BookingStatusPending.CanTransitionTo(BookingStatusFailed))
+	assert.True(t, BookingStatusPending.CanTransitionTo(BookingStatusCancelled))
+
+	assert.False(t, BookingStatusPending.CanTransitionTo(BookingStatusPending))
+	assert.False(t, BookingStatusConfirmed.CanTransitionTo(BookingStatusPending))
+	assert.False(t, BookingStatusConfirmed.CanTransitionTo(BookingStatusCancelled))
+	assert.False(t, BookingStatusFailed.CanTransitionTo(BookingStatusConfirmed))
+	assert.False(t, BookingStatusCancelled.CanTransitionTo(BookingStatusPending))
+	assert.False(t, BookingStatusPending.CanTransitionTo(BookingStatus("booked")))
+}
@@ -2,28 +2,157 @@ package models
 
 import (
 	"context"
-	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"time"
 
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-// DeduplicationService provides advanced duplicate prevention for notifications
+// Default deduplication record handling, used unless the service is configured otherwise
+const (
+	// DefaultDeduplicationRetention is how long a record of a sent notification is
+	// kept. It caps the exact-match window, since a slot with no record can't be
+	// recognised as a duplicate.
+	DefaultDeduplicationRetention = 48 * time.Hour
+	// DefaultDeduplicationCleanupInterval is how often expired records are deleted
+	DefaultDeduplicationCleanupInterval = time.Hour
+)
+
+// Default deduplication windows, used unless the service or the user overrides them
+const (
+	DefaultExactDuplicateWindow   = 24 * time.Hour // Same slot at the same price
+	DefaultSimilarDuplicateWindow = 1 * time.Hour  // Same venue, court and start time on another date
+)
+
+// Reason codes returned in DuplicateCheckResult.ReasonCode, so callers can log why
+// a notification was or wasn't suppressed
+const (
+	// DuplicateReasonExactSlotRecent: the same slot at the same price was sent within the exact-match window
+	DuplicateReasonExactSlotRecent = "EXACT_SLOT_RECENT"
+	// DuplicateReasonSimilarContentRecent: the same venue, court and start time on a different
+	// date was sent within the similarity window
+	DuplicateReasonSimilarContentRecent = "SIMILAR_CONTENT_RECENT"
+	// DuplicateReasonVenueFlooding: the user had maxVenueNotificationsPerHour alerts for the venue in the last hour
+	DuplicateReasonVenueFlooding = "VENUE_FLOODING"
+	// DuplicateReasonNotDuplicate: nothing matched, so the notification can be sent
+	DuplicateReasonNotDuplicate = "NOT_DUPLICATE"
+)
+
+// maxVenueNotificationsPerHour is how many slots from one venue a user is alerted about per hour
+const maxVenueNotificationsPerHour = 5
+
+// DeduplicationWindows sets how long a sent notification suppresses later ones.
+// A zero field means "use the default".
+type DeduplicationWindows struct {
+	ExactMatch time.Duration // Same slot at the same price
+	Similar    time.Duration // Same venue, court and start time on a different date
+}
+
+// DefaultDeduplicationWindows returns the built-in windows
+func DefaultDeduplicationWindows() DeduplicationWindows {
+	return DeduplicationWindows{
+		ExactMatch: DefaultExactDuplicateWindow,
+		Similar:    DefaultSimilarDuplicateWindow,
+	}
+}
+
+// Or returns w with any unset windows taken from defaults
+func (w DeduplicationWindows) Or(defaults DeduplicationWindows) DeduplicationWindows {
+	if w.ExactMatch <= 0 {
+		w.ExactMatch = defaults.ExactMatch
+	}
+	if w.Similar <= 0 {
+		w.Similar = defaults.Similar
+	}
+	return w
+}
+
+// DeduplicationWindows returns the user's overrides from their notification settings.
+// Windows the user hasn't set are zero. The service caps the exact-match window at
+// its record retention when checking.
+func (n NotificationSettings) DeduplicationWindows() DeduplicationWindows {
+	var windows DeduplicationWindows
+	if n.ExactDuplicateWindowMinutes > 0 {
+		windows.ExactMatch = time.Duration(n.ExactDuplicateWindowMinutes) * time.Minute
+	}
+	if n.SimilarDuplicateWindowMinutes > 0 {
+		windows.Similar = time.Duration(n.SimilarDuplicateWindowMinutes) * time.Minute
+	}
+	return windows
+}
+
+// DeduplicationConfig sets how long records of sent notifications are kept and how
+// often expired ones are deleted
+type DeduplicationConfig struct {
+	Retention       time.Duration // Also the longest exact-match window
+	CleanupInterval time.Duration
+}
+
+// DefaultDeduplicationConfig returns the built-in record handling
+func DefaultDeduplicationConfig() DeduplicationConfig {
+	return DeduplicationConfig{
+		Retention:       DefaultDeduplicationRetention,
+		CleanupInterval: DefaultDeduplicationCleanupInterval,
+	}
+}
+
+// Or returns c with any unset fields taken from defaults
+func (c DeduplicationConfig) Or(defaults DeduplicationConfig) DeduplicationConfig {
+	if c.Retention <= 0 {
+		c.Retention = defaults.Retention
+	}
+	if c.CleanupInterval <= 0 {
+		c.CleanupInterval = defaults.CleanupInterval
+	}
+	return c
+}
+
+// DeduplicationService provides advanced duplicate prevention for notifications.
+// A slot is identified by its slot key (venue, court, date, start) and its content
+// by a hash that also covers the end time and price, so a re-listed slot at a new
+// price is a fresh notifiable event rather than a duplicate.
 type DeduplicationService struct {
-	collection *mongo.Collection
+	store   DedupStore
+	config  DeduplicationConfig
+	windows DeduplicationWindows // Deployment-wide windows, overridable per user
 }
 
-// NewDeduplicationService creates a new deduplication service
-func NewDeduplicationService(db *mongo.Database) *DeduplicationService {
+// NewDeduplicationService creates a new deduplication service over the store, using
+// the default windows. Unset config fields keep the defaults. Production code passes
+// a MongoDedupStore.
+func NewDeduplicationService(store DedupStore, config DeduplicationConfig) *DeduplicationService {
 	return &DeduplicationService{
-		collection: db.Collection("notification_deduplication"),
+		store:   store,
+		config:  config.Or(DefaultDeduplicationConfig()),
+		windows: DefaultDeduplicationWindows(),
 	}
 }
 
+// Config returns the service's record retention and cleanup interval
+func (s *DeduplicationService) Config() DeduplicationConfig {
+	return s.config.Or(DefaultDeduplicationConfig())
+}
+
+// SetWindows replaces the deployment-wide windows. Unset fields keep the defaults.
+func (s *DeduplicationService) SetWindows(windows DeduplicationWindows) {
+	s.windows = windows.Or(DefaultDeduplicationWindows())
+}
+
+// Windows returns the deployment-wide windows, with the exact-match window capped
+// at the record retention
+func (s *DeduplicationService) Windows() DeduplicationWindows {
+	return s.capWindows(s.windows.Or(DefaultDeduplicationWindows()))
+}
+
+// capWindows limits the exact-match window to the record retention, since a slot
+// whose record has expired can't be recognised as a duplicate
+func (s *DeduplicationService) capWindows(windows DeduplicationWindows) DeduplicationWindows {
+	windows.ExactMatch = min(windows.ExactMatch, s.Config().Retention)
+	return windows
+}
+
 // DeduplicationRecord tracks sent notifications to prevent duplicates
 type DeduplicationRecord struct {
 	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
@@ -51,26 +180,31 @@ type DuplicateCheckResult struct {
 	TimeSinceLastSent time.Duration        `json:"time_since_last_sent"`
 }
 
-// CheckForDuplicate checks if a notification would be a duplicate
-func (s *DeduplicationService) CheckForDuplicate(ctx context.Context, userID primitive.ObjectID, event CourtAvailabilityEvent) (*DuplicateCheckResult, error) {
+// CheckForDuplicate checks if a notification would be a duplicate. userWindows holds
+// the user's overrides, usually from NotificationSettings.DeduplicationWindows; unset
+// windows fall back to the service's. The result's ReasonCode is one of the
+// DuplicateReason constants.
+func (s *DeduplicationService) CheckForDuplicate(ctx context.Context, userID primitive.ObjectID, event CourtAvailabilityEvent, userWindows DeduplicationWindows) (*DuplicateCheckResult, error) {
 	slotKey := event.GenerateSlotKey()
 	contentHash := s.generateContentHash(event)
+	windows := s.capWindows(userWindows.Or(s.Windows()))
 
 	// Check for exact slot match (same slot, same user)
-	exactMatch, err := s.findExactMatch(ctx, userID, slotKey)
+	exactMatch, err := s.store.FindExactMatch(ctx, userID, slotKey)
 	if err != nil {
 		return nil, err
 	}
 
-	if exactMatch != nil {
+	// A changed hash means the price or end time changed, which users want to hear about
+	if exactMatch != nil && exactMatch.ContentHash == contentHash {
 		timeSince := time.Since(exactMatch.LastSentAt)
 
-		// Allow resending after 24 hours for the same slot
-		if timeSince < 24*time.Hour {
+		// Allow resending the same slot once the exact-match window has passed
+		if timeSince < windows.ExactMatch {
 			return &DuplicateCheckResult{
 				IsDuplicate:       true,
 				ExistingRecord:    exactMatch,
-				ReasonCode:        "EXACT_SLOT_RECENT",
+				ReasonCode:        DuplicateReasonExactSlotRecent,
 				ReasonDescription: "Same slot notification sent recently",
 				TimeSinceLastSent: timeSince,
 			}, nil
@@ -78,7 +212,7 @@ func (s *DeduplicationService) CheckForDuplicate(ctx context.Context, userID pri
 	}
 
 	// Check for similar content (same venue, court, time, different date)
-	similarMatch, err := s.findSimilarMatch(ctx, userID, event, contentHash)
+	similarMatch, err := s.store.FindSimilarMatch(ctx, userID, event, time.Now().Add(-windows.Similar))
 	if err != nil {
 		return nil, err
 	}
@@ -86,12 +220,12 @@ func (s *DeduplicationService) CheckForDuplicate(ctx context.Context, userID pri
 	if similarMatch != nil {
 		timeSince := time.Since(similarMatch.LastSentAt)
 
-		// Prevent spam of very similar notifications within 1 hour
-		if timeSince < 1*time.Hour {
+		// Prevent spam of very similar notifications within the similarity window
+		if timeSince < windows.Similar {
 			return &DuplicateCheckResult{
 				IsDuplicate:       true,
 				ExistingRecord:    similarMatch,
-				ReasonCode:        "SIMILAR_CONTENT_RECENT",
+				ReasonCode:        DuplicateReasonSimilarContentRecent,
 				ReasonDescription: "Very similar notification sent recently",
 				TimeSinceLastSent: timeSince,
 			}, nil
@@ -99,15 +233,15 @@ func (s *DeduplicationService) CheckForDuplicate(ctx context.Context, userID pri
 	}
 
 	// Check for venue flooding (too many notifications from same venue)
-	venueCount, err := s.getRecentVenueNotificationCount(ctx, userID, event.VenueID, 1*time.Hour)
+	venueCount, err := s.store.CountVenueNotifications(ctx, userID, event.VenueID, time.Now().Add(-1*time.Hour))
 	if err != nil {
 		return nil, err
 	}
 
-	if venueCount >= 5 { // Max 5 notifications per venue per hour
+	if venueCount >= maxVenueNotificationsPerHour {
 		return &DuplicateCheckResult{
 			IsDuplicate:       true,
-			ReasonCode:        "VENUE_FLOODING",
+			ReasonCode:        DuplicateReasonVenueFlooding,
 			ReasonDescription: "Too many notifications from this venue recently",
 		}, nil
 	}
@@ -115,123 +249,41 @@ func (s *DeduplicationService) CheckForDuplicate(ctx context.Context, userID pri
 	// Not a duplicate
 	return &DuplicateCheckResult{
 		IsDuplicate:       false,
-		ReasonCode:        "NOT_DUPLICATE",
+		ReasonCode:        DuplicateReasonNotDuplicate,
 		ReasonDescription: "Notification is unique and can be sent",
 	}, nil
 }
 
-// RecordNotification records that a notification was sent
+// RecordNotification records that a notification was sent. The store upserts on
+// user and slot key, so recording the same event twice is harmless, and keeps the
+// latest content hash and price so a later price change is detected.
 func (s *DeduplicationService) RecordNotification(ctx context.Context, userID primitive.ObjectID, event CourtAvailabilityEvent) error {
-	slotKey := event.GenerateSlotKey()
-	contentHash := s.generateContentHash(event)
 	now := time.Now()
 
-	// Check if record already exists
-	existing, err := s.findExactMatch(ctx, userID, slotKey)
-	if err != nil {
-		return err
-	}
-
-	if existing != nil {
-		// Update existing record
-		update := bson.M{
-			"$set": bson.M{
-				"last_sent_at": now,
-				"expires_at":   now.Add(48 * time.Hour), // Extend expiry
-			},
-			"$inc": bson.M{
-				"send_count": 1,
-			},
-		}
-
-		_, err = s.collection.UpdateOne(ctx, bson.M{"_id": existing.ID}, update)
-		return err
-	}
-
-	// Create new record
-	record := &DeduplicationRecord{
+	return s.store.Record(ctx, &DeduplicationRecord{
 		UserID:        userID,
-		SlotKey:       slotKey,
-		ContentHash:   contentHash,
+		SlotKey:       event.GenerateSlotKey(),
+		ContentHash:   s.generateContentHash(event),
 		VenueID:       event.VenueID,
 		CourtID:       event.CourtID,
 		SlotDate:      event.Date,
 		SlotStartTime: event.StartTime,
 		Price:         event.Price,
-		FirstSentAt:   now,
 		LastSentAt:    now,
-		SendCount:     1,
-		ExpiresAt:     now.Add(48 * time.Hour), // Records expire after 48 hours
-		CreatedAt:     now,
-	}
+		ExpiresAt:     now.Add(s.Config().Retention), // Extend expiry
+	})
+}
 
-	_, err = s.collection.InsertOne(ctx, record)
-	return err
+// ClearSlot deletes the user's deduplication record for a slot, so the next time the
+// slot is seen becoming available the user is alerted again. Only the user's own
+// record can match. It returns how many records were deleted.
+func (s *DeduplicationService) ClearSlot(ctx context.Context, userID primitive.ObjectID, slotKey string) (int64, error) {
+	return s.store.DeleteSlot(ctx, userID, slotKey)
 }
 
 // CleanupExpiredRecords removes expired deduplication records
 func (s *DeduplicationService) CleanupExpiredRecords(ctx context.Context) (int64, error) {
-	filter := bson.M{
-		"expires_at": bson.M{"$lt": time.Now()},
-	}
-
-	result, err := s.collection.DeleteMany(ctx, filter)
-	if err != nil {
-		return 0, err
-	}
-
-	return result.DeletedCount, nil
-}
-
-// GetUserNotificationStats returns notification statistics for a user
-func (s *DeduplicationService) GetUserNotificationStats(ctx context.Context, userID primitive.ObjectID, since time.Time) (*NotificationStats, error) {
-	pipeline := []bson.M{
-		{
-			"$match": bson.M{
-				"user_id":       userID,
-				"first_sent_at": bson.M{"$gte": since},
-			},
-		},
-		{
-			"$group": bson.M{
-				"_id":                 nil,
-				"total_notifications": bson.M{"$sum": "$send_count"},
-				"unique_slots":        bson.M{"$sum": 1},
-				"venues":              bson.M{"$addToSet": "$venue_id"},
-				"avg_price":           bson.M{"$avg": "$price"},
-				"min_price":           bson.M{"$min": "$price"},
-				"max_price":           bson.M{"$max": "$price"},
-			},
-		},
-	}
-
-	cursor, err := s.collection.Aggregate(ctx, pipeline)
-	if err != nil {
-		return nil, err
-	}
-	defer cursor.Close(ctx)
-
-	var results []bson.M
-	if err = cursor.All(ctx, &results); err != nil {
-		return nil, err
-	}
-
-	if len(results) == 0 {
-		return &NotificationStats{}, nil
-	}
-
-	result := results[0]
-	venues, _ := result["venues"].(primitive.A)
-
-	return &NotificationStats{
-		TotalNotifications: getInt64(result, "total_notifications"),
-		UniqueSlots:        getInt64(result, "unique_slots"),
-		UniqueVenues:       int64(len(venues)),
-		AveragePrice:       getFloat64(result, "avg_price"),
-		MinPrice:           getFloat64(result, "min_price"),
-		MaxPrice:           getFloat64(result, "max_price"),
-		Period:             time.Since(since),
-	}, nil
+	return s.store.DeleteExpired(ctx, time.Now())
 }
 
 // NotificationStats contains notification statistics
@@ -245,134 +297,11 @@ type NotificationStats struct {
 	Period             time.Duration `json:"period"`
 }
 
-// findExactMatch finds an exact slot match for a user
-func (s *DeduplicationService) findExactMatch(ctx context.Context, userID primitive.ObjectID, slotKey string) (*DeduplicationRecord, error) {
-	filter := bson.M{
-		"user_id":  userID,
-		"slot_key": slotKey,
-	}
-
-	var record DeduplicationRecord
-	err := s.collection.FindOne(ctx, filter).Decode(&record)
-	if err == mongo.ErrNoDocuments {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	return &record, nil
-}
-
-// findSimilarMatch finds a similar notification (same venue, court, time, different date)
-func (s *DeduplicationService) findSimilarMatch(ctx context.Context, userID primitive.ObjectID, event CourtAvailabilityEvent, contentHash string) (*DeduplicationRecord, error) {
-	filter := bson.M{
-		"user_id":         userID,
-		"venue_id":        event.VenueID,
-		"court_id":        event.CourtID,
-		"slot_start_time": event.StartTime,
-		"slot_date":       bson.M{"$ne": event.Date},                      // Different date
-		"last_sent_at":    bson.M{"$gte": time.Now().Add(-1 * time.Hour)}, // Within last hour
-	}
-
-	opts := options.FindOne().SetSort(bson.M{"last_sent_at": -1})
-
-	var record DeduplicationRecord
-	err := s.collection.FindOne(ctx, filter, opts).Decode(&record)
-	if err == mongo.ErrNoDocuments {
-		return nil, nil
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	return &record, nil
-}
-
-// getRecentVenueNotificationCount counts recent notifications from a venue
-func (s *DeduplicationService) getRecentVenueNotificationCount(ctx context.Context, userID primitive.ObjectID, venueID string, since time.Duration) (int64, error) {
-	filter := bson.M{
-		"user_id":      userID,
-		"venue_id":     venueID,
-		"last_sent_at": bson.M{"$gte": time.Now().Add(-since)},
-	}
-
-	return s.collection.CountDocuments(ctx, filter)
-}
-
-// generateContentHash creates a hash of the notification content for similarity detection
+// generateContentHash hashes everything about a slot that makes it worth notifying:
+// venue, court, date, start, end and price
 func (s *DeduplicationService) generateContentHash(event CourtAvailabilityEvent) string {
-	content := fmt.Sprintf("%s:%s:%s:%.2f",
-		event.VenueID, event.CourtID, event.StartTime, event.Price)
-	return fmt.Sprintf("%x", md5.Sum([]byte(content)))
-}
-
-// Helper functions for type conversion
-func getInt64(m bson.M, key string) int64 {
-	if val, ok := m[key]; ok {
-		switch v := val.(type) {
-		case int64:
-			return v
-		case int32:
-			return int64(v)
-		case int:
-			return int64(v)
-		}
-	}
-	return 0
-}
-
-func getFloat64(m bson.M, key string) float64 {
-	if val, ok := m[key]; ok {
-		switch v := val.(type) {
-		case float64:
-			return v
-		case float32:
-			return float64(v)
-		case int64:
-			return float64(v)
-		case int32:
-			return float64(v)
-		case int:
-			return float64(v)
-		}
-	}
-	return 0
-}
-
-// CreateIndexes creates necessary indexes for the deduplication collection
-func (s *DeduplicationService) CreateIndexes(ctx context.Context) error {
-	indexes := []mongo.IndexModel{
-		{
-			Keys: bson.D{
-				{Key: "user_id", Value: 1},
-				{Key: "slot_key", Value: 1},
-			},
-			Options: options.Index().SetUnique(true),
-		},
-		{
-			Keys: bson.D{
-				{Key: "expires_at", Value: 1},
-			},
-			Options: options.Index().SetExpireAfterSeconds(0),
-		},
-		{
-			Keys: bson.D{
-				{Key: "user_id", Value: 1},
-				{Key: "venue_id", Value: 1},
-				{Key: "last_sent_at", Value: -1},
-			},
-		},
-		{
-			Keys: bson.D{
-				{Key: "user_id", Value: 1},
-				{Key: "venue_id", Value: 1},
-				{Key: "court_id", Value: 1},
-				{Key: "slot_start_time", Value: 1},
-			},
-		},
-	}
-
-	_, err := s.collection.Indexes().CreateMany(ctx, indexes)
-	return err
+	content := fmt.Sprintf("%s|%s|%s|%s|%s|%.2f",
+		event.VenueID, event.CourtID, event.Date, event.StartTime, event.EndTime, event.Price)
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,43 @@
+//go:build integration
+
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/testenv"
+)
+
+func TestIntegration_DeduplicationService_SuppressesRepeatAlerts(t *testing.T) {
+	env, cleanup := testenv.SetupTestEnv(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	store := NewMongoDedupStore(env.DB)
+	require.NoError(t, store.CreateIndexes(ctx))
+	service := NewDeduplicationService(store, DefaultDeduplicationConfig())
+
+	userID := primitive.NewObjectID()
+	event := testAvailabilityEvent()
+
+	result, err := service.CheckForDuplicate(ctx, userID, event, DeduplicationWindows{})
+	require.NoError(t, err)
+	assert.False(t, result.IsDuplicate)
+
+	require.NoError(t, service.RecordNotification(ctx, userID, event))
+
+	result, err = service.CheckForDuplicate(ctx, userID, event, DeduplicationWindows{})
+	require.NoError(t, err)
+	assert.True(t, result.IsDuplicate)
+	assert.Equal(t, DuplicateReasonExactSlotRecent, result.ReasonCode)
+
+	// Another user hasn't been alerted about the slot yet
+	result, err = service.CheckForDuplicate(ctx, primitive.NewObjectID(), event, DeduplicationWindows{})
+	require.NoError(t, err)
+	assert.False(t, result.IsDuplicate)
+}
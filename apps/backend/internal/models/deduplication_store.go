@@ -0,0 +1,283 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DedupStore holds the records of sent notifications that DeduplicationService
+// checks new ones against. The service decides what counts as a duplicate; the
+// store only looks records up and keeps them.
+type DedupStore interface {
+	// FindExactMatch returns the user's record for the slot, or nil if there isn't one
+	FindExactMatch(ctx context.Context, userID primitive.ObjectID, slotKey string) (*DeduplicationRecord, error)
+
+	// FindSimilarMatch returns the user's most recently sent record for the event's
+	// venue, court and start time on a different date, sent at or after since, or nil
+	FindSimilarMatch(ctx context.Context, userID primitive.ObjectID, event CourtAvailabilityEvent, since time.Time) (*DeduplicationRecord, error)
+
+	// CountVenueNotifications counts the user's records for the venue sent at or after since
+	CountVenueNotifications(ctx context.Context, userID primitive.ObjectID, venueID string, since time.Time) (int64, error)
+
+	// Record upserts the record on user and slot key. Every field but the ID, send
+	// count and first-sent and created times is overwritten; on insert FirstSentAt
+	// and CreatedAt are set to LastSentAt. SendCount is incremented either way.
+	Record(ctx context.Context, record *DeduplicationRecord) error
+
+	// DeleteSlot deletes the user's record for the slot, returning how many were deleted
+	DeleteSlot(ctx context.Context, userID primitive.ObjectID, slotKey string) (int64, error)
+
+	// DeleteExpired deletes records that expired before now, returning how many were deleted
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// MongoDedupStore keeps deduplication records in the notification_deduplication collection
+type MongoDedupStore struct {
+	collection *mongo.Collection
+}
+
+// NewMongoDedupStore creates a store over the database's notification_deduplication collection
+func NewMongoDedupStore(db *mongo.Database) *MongoDedupStore {
+	return &MongoDedupStore{
+		collection: db.Collection("notification_deduplication"),
+	}
+}
+
+// FindExactMatch finds an exact slot match for a user
+func (s *MongoDedupStore) FindExactMatch(ctx context.Context, userID primitive.ObjectID, slotKey string) (*DeduplicationRecord, error) {
+	filter := bson.M{
+		"user_id":  userID,
+		"slot_key": slotKey,
+	}
+
+	var record DeduplicationRecord
+	err := s.collection.FindOne(ctx, filter).Decode(&record)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// FindSimilarMatch finds a similar notification (same venue, court, time, different date) sent since
+func (s *MongoDedupStore) FindSimilarMatch(ctx context.Context, userID primitive.ObjectID, event CourtAvailabilityEvent, since time.Time) (*DeduplicationRecord, error) {
+	filter := bson.M{
+		"user_id":         userID,
+		"venue_id":        event.VenueID,
+		"court_id":        event.CourtID,
+		"slot_start_time": event.StartTime,
+		"slot_date":       bson.M{"$ne": event.Date}, // Different date
+		"last_sent_at":    bson.M{"$gte": since},     // Within the similarity window
+	}
+
+	opts := options.FindOne().SetSort(bson.M{"last_sent_at": -1})
+
+	var record DeduplicationRecord
+	err := s.collection.FindOne(ctx, filter, opts).Decode(&record)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// CountVenueNotifications counts recent notifications from a venue
+func (s *MongoDedupStore) CountVenueNotifications(ctx context.Context, userID primitive.ObjectID, venueID string, since time.Time) (int64, error) {
+	filter := bson.M{
+		"user_id":      userID,
+		"venue_id":     venueID,
+		"last_sent_at": bson.M{"$gte": since},
+	}
+
+	return s.collection.CountDocuments(ctx, filter)
+}
+
+// Record upserts the record on user and slot key
+func (s *MongoDedupStore) Record(ctx context.Context, record *DeduplicationRecord) error {
+	filter := bson.M{
+		"user_id":  record.UserID,
+		"slot_key": record.SlotKey,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"content_hash":    record.ContentHash,
+			"venue_id":        record.VenueID,
+			"court_id":        record.CourtID,
+			"slot_date":       record.SlotDate,
+			"slot_start_time": record.SlotStartTime,
+			"price":           record.Price,
+			"last_sent_at":    record.LastSentAt,
+			"expires_at":      record.ExpiresAt,
+		},
+		"$setOnInsert": bson.M{
+			"first_sent_at": record.LastSentAt,
+			"created_at":    record.LastSentAt,
+		},
+		"$inc": bson.M{
+			"send_count": 1,
+		},
+	}
+
+	_, err := s.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// DeleteSlot deletes the user's record for the slot
+func (s *MongoDedupStore) DeleteSlot(ctx context.Context, userID primitive.ObjectID, slotKey string) (int64, error) {
+	result, err := s.collection.DeleteMany(ctx, bson.M{
+		"user_id":  userID,
+		"slot_key": slotKey,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// DeleteExpired removes records that expired before now. The TTL index does the
+// same, but only runs once a minute.
+func (s *MongoDedupStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	filter := bson.M{
+		"expires_at": bson.M{"$lt": now},
+	}
+
+	result, err := s.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// GetUserNotificationStats returns notification statistics for a user
+func (s *MongoDedupStore) GetUserNotificationStats(ctx context.Context, userID primitive.ObjectID, since time.Time) (*NotificationStats, error) {
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"user_id":       userID,
+				"first_sent_at": bson.M{"$gte": since},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":                 nil,
+				"total_notifications": bson.M{"$sum": "$send_count"},
+				"unique_slots":        bson.M{"$sum": 1},
+				"venues":              bson.M{"$addToSet": "$venue_id"},
+				"avg_price":           bson.M{"$avg": "$price"},
+				"min_price":           bson.M{"$min": "$price"},
+				"max_price":           bson.M{"$max": "$price"},
+			},
+		},
+	}
+
+	cursor, err := s.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []bson.M
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return &NotificationStats{}, nil
+	}
+
+	result := results[0]
+	venues, _ := result["venues"].(primitive.A)
+
+	return &NotificationStats{
+		TotalNotifications: getInt64(result, "total_notifications"),
+		UniqueSlots:        getInt64(result, "unique_slots"),
+		UniqueVenues:       int64(len(venues)),
+		AveragePrice:       getFloat64(result, "avg_price"),
+		MinPrice:           getFloat64(result, "min_price"),
+		MaxPrice:           getFloat64(result, "max_price"),
+		Period:             time.Since(since),
+	}, nil
+}
+
+// Helper functions for type conversion
+func getInt64(m bson.M, key string) int64 {
+	if val, ok := m[key]; ok {
+		switch v := val.(type) {
+		case int64:
+			return v
+		case int32:
+			return int64(v)
+		case int:
+			return int64(v)
+		}
+	}
+	return 0
+}
+
+func getFloat64(m bson.M, key string) float64 {
+	if val, ok := m[key]; ok {
+		switch v := val.(type) {
+		case float64:
+			return v
+		case float32:
+			return float64(v)
+		case int64:
+			return float64(v)
+		case int32:
+			return float64(v)
+		case int:
+			return float64(v)
+		}
+	}
+	return 0
+}
+
+// CreateIndexes creates necessary indexes for the deduplication collection
+func (s *MongoDedupStore) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "slot_key", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "expires_at", Value: 1},
+			},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "venue_id", Value: 1},
+				{Key: "last_sent_at", Value: -1},
+			},
+		},
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "venue_id", Value: 1},
+				{Key: "court_id", Value: 1},
+				{Key: "slot_start_time", Value: 1},
+			},
+		},
+	}
+
+	_, err := s.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -0,0 +1,319 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryDedupStore is a DedupStore over a slice, so the service's decisions can be
+// tested without MongoDB
+type memoryDedupStore struct {
+	mu      sync.Mutex
+	records []*DeduplicationRecord
+}
+
+func newMemoryDedupStore(records ...DeduplicationRecord) *memoryDedupStore {
+	store := &memoryDedupStore{}
+	for _, record := range records {
+		store.records = append(store.records, &record)
+	}
+	return store
+}
+
+func (s *memoryDedupStore) FindExactMatch(ctx context.Context, userID primitive.ObjectID, slotKey string) (*DeduplicationRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range s.records {
+		if record.UserID == userID && record.SlotKey == slotKey {
+			found := *record
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryDedupStore) FindSimilarMatch(ctx context.Context, userID primitive.ObjectID, event CourtAvailabilityEvent, since time.Time) (*DeduplicationRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var latest *DeduplicationRecord
+	for _, record := range s.records {
+		if record.UserID != userID || record.VenueID != event.VenueID || record.CourtID != event.CourtID ||
+			record.SlotStartTime != event.StartTime || record.SlotDate == event.Date || record.LastSentAt.Before(since) {
+			continue
+		}
+		if latest == nil || record.LastSentAt.After(latest.LastSentAt) {
+			latest = record
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	found := *latest
+	return &found, nil
+}
+
+func (s *memoryDedupStore) CountVenueNotifications(ctx context.Context, userID primitive.ObjectID, venueID string, since time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var count int64
+	for _, record := range s.records {
+		if record.UserID == userID && record.VenueID == venueID && !record.LastSentAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *memoryDedupStore) Record(ctx context.Context, record *DeduplicationRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	updated := *record
+	for i, existing := range s.records {
+		if existing.UserID == record.UserID && existing.SlotKey == record.SlotKey {
+			updated.ID = existing.ID
+			updated.FirstSentAt = existing.FirstSentAt
+			updated.CreatedAt = existing.CreatedAt
+			updated.SendCount = existing.SendCount + 1
+			s.records[i] = &updated
+			return nil
+		}
+	}
+
+	updated.ID = primitive.NewObjectID()
+	updated.FirstSentAt = record.LastSentAt
+	updated.CreatedAt = record.LastSentAt
+	updated.SendCount = 1
+	s.records = append(s.records, &updated)
+	return nil
+}
+
+func (s *memoryDedupStore) DeleteSlot(ctx context.Context, userID primitive.ObjectID, slotKey string) (int64, error) {
+	return s.deleteWhere(func(record *DeduplicationRecord) bool {
+		return record.UserID == userID && record.SlotKey == slotKey
+	}), nil
+}
+
+func (s *memoryDedupStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return s.deleteWhere(func(record *DeduplicationRecord) bool {
+		return record.ExpiresAt.Before(now)
+	}), nil
+}
+
+func (s *memoryDedupStore) deleteWhere(match func(*DeduplicationRecord) bool) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.records[:0]
+	for _, record := range s.records {
+		if !match(record) {
+			kept = append(kept, record)
+		}
+	}
+	deleted := int64(len(s.records) - len(kept))
+	s.records = kept
+	return deleted
+}
+
+// sentRecord is the record left by notifying the user about the event ago
+func sentRecord(userID primitive.ObjectID, event CourtAvailabilityEvent, ago time.Duration) DeduplicationRecord {
+	sentAt := time.Now().Add(-ago)
+	return DeduplicationRecord{
+		UserID:        userID,
+		SlotKey:       event.GenerateSlotKey(),
+		ContentHash:   (&DeduplicationService{}).generateContentHash(event),
+		VenueID:       event.VenueID,
+		CourtID:       event.CourtID,
+		SlotDate:      event.Date,
+		SlotStartTime: event.StartTime,
+		Price:         event.Price,
+		FirstSentAt:   sentAt,
+		LastSentAt:    sentAt,
+		SendCount:     1,
+		ExpiresAt:     sentAt.Add(DefaultDeduplicationRetention),
+		CreatedAt:     sentAt,
+	}
+}
+
+func TestDeduplicationService_CheckForDuplicate(t *testing.T) {
+	userID := primitive.NewObjectID()
+	event := testAvailabilityEvent()
+
+	repriced := event
+	repriced.Price = 10.00
+
+	otherDate := event
+	otherDate.Date = "2024-06-16"
+
+	otherStart := otherDate
+	otherStart.StartTime = "19:00"
+
+	// venueRecords are n slots at the event's venue on other courts, sent ago
+	venueRecords := func(n int, ago time.Duration) []DeduplicationRecord {
+		var records []DeduplicationRecord
+		for i := range n {
+			slot := event
+			slot.CourtID = fmt.Sprintf("court-%d", i+2)
+			records = append(records, sentRecord(userID, slot, ago))
+		}
+		return records
+	}
+
+	tests := []struct {
+		name        string
+		records     []DeduplicationRecord
+		userWindows DeduplicationWindows
+		wantReason  string
+	}{
+		{
+			name:       "nothing sent",
+			wantReason: DuplicateReasonNotDuplicate,
+		},
+		{
+			name:       "same slot sent recently",
+			records:    []DeduplicationRecord{sentRecord(userID, event, time.Hour)},
+			wantReason: DuplicateReasonExactSlotRecent,
+		},
+		{
+			name:       "same slot sent before the exact-match window",
+			records:    []DeduplicationRecord{sentRecord(userID, event, 25*time.Hour)},
+			wantReason: DuplicateReasonNotDuplicate,
+		},
+		{
+			name:        "user's shorter exact-match window has passed",
+			records:     []DeduplicationRecord{sentRecord(userID, event, 3*time.Hour)},
+			userWindows: DeduplicationWindows{ExactMatch: 2 * time.Hour},
+			wantReason:  DuplicateReasonNotDuplicate,
+		},
+		{
+			name:       "same slot at a different price",
+			records:    []DeduplicationRecord{sentRecord(userID, repriced, time.Minute)},
+			wantReason: DuplicateReasonNotDuplicate,
+		},
+		{
+			name:       "another user's record",
+			records:    []DeduplicationRecord{sentRecord(primitive.NewObjectID(), event, time.Minute)},
+			wantReason: DuplicateReasonNotDuplicate,
+		},
+		{
+			name:       "same court and start on another date sent recently",
+			records:    []DeduplicationRecord{sentRecord(userID, otherDate, 30*time.Minute)},
+			wantReason: DuplicateReasonSimilarContentRecent,
+		},
+		{
+			name:       "same court and start on another date sent before the similarity window",
+			records:    []DeduplicationRecord{sentRecord(userID, otherDate, 2*time.Hour)},
+			wantReason: DuplicateReasonNotDuplicate,
+		},
+		{
+			name:        "user's longer similarity window",
+			records:     []DeduplicationRecord{sentRecord(userID, otherDate, 2*time.Hour)},
+			userWindows: DeduplicationWindows{Similar: 3 * time.Hour},
+			wantReason:  DuplicateReasonSimilarContentRecent,
+		},
+		{
+			name:       "different start time on another date",
+			records:    []DeduplicationRecord{sentRecord(userID, otherStart, time.Minute)},
+			wantReason: DuplicateReasonNotDuplicate,
+		},
+		{
+			name:       "venue limit reached in the last hour",
+			records:    venueRecords(maxVenueNotificationsPerHour, 10*time.Minute),
+			wantReason: DuplicateReasonVenueFlooding,
+		},
+		{
+			name:       "one below the venue limit",
+			records:    venueRecords(maxVenueNotificationsPerHour-1, 10*time.Minute),
+			wantReason: DuplicateReasonNotDuplicate,
+		},
+		{
+			name:       "venue limit reached over an hour ago",
+			records:    venueRecords(maxVenueNotificationsPerHour, 2*time.Hour),
+			wantReason: DuplicateReasonNotDuplicate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewDeduplicationService(newMemoryDedupStore(tt.records...), DefaultDeduplicationConfig())
+
+			result, err := service.CheckForDuplicate(context.Background(), userID, event, tt.userWindows)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantReason, result.ReasonCode)
+			assert.Equal(t, tt.wantReason != DuplicateReasonNotDuplicate, result.IsDuplicate)
+		})
+	}
+}
+
+func TestDeduplicationService_RecordAndClear(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryDedupStore()
+	service := NewDeduplicationService(store, DefaultDeduplicationConfig())
+	userID := primitive.NewObjectID()
+	event := testAvailabilityEvent()
+
+	require.NoError(t, service.RecordNotification(ctx, userID, event))
+	require.NoError(t, service.RecordNotification(ctx, userID, event))
+	require.Len(t, store.records, 1)
+	assert.Equal(t, 2, store.records[0].SendCount)
+	assert.WithinDuration(t, time.Now().Add(DefaultDeduplicationRetention), store.records[0].ExpiresAt, time.Minute)
+
+	result, err := service.CheckForDuplicate(ctx, userID, event, DeduplicationWindows{})
+	require.NoError(t, err)
+	assert.Equal(t, DuplicateReasonExactSlotRecent, result.ReasonCode)
+
+	deleted, err := service.ClearSlot(ctx, userID, event.GenerateSlotKey())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	result, err = service.CheckForDuplicate(ctx, userID, event, DeduplicationWindows{})
+	require.NoError(t, err)
+	assert.False(t, result.IsDuplicate)
+}
+
+func TestDeduplicationService_Retention(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryDedupStore()
+	service := NewDeduplicationService(store, DeduplicationConfig{Retention: 6 * time.Hour})
+	userID := primitive.NewObjectID()
+	event := testAvailabilityEvent()
+
+	require.NoError(t, service.RecordNotification(ctx, userID, event))
+	require.Len(t, store.records, 1)
+	assert.WithinDuration(t, time.Now().Add(6*time.Hour), store.records[0].ExpiresAt, time.Minute)
+
+	// A user's longer exact-match window is capped at the retention
+	store.records[0].LastSentAt = time.Now().Add(-7 * time.Hour)
+	result, err := service.CheckForDuplicate(ctx, userID, event, DeduplicationWindows{ExactMatch: 24 * time.Hour})
+	require.NoError(t, err)
+	assert.False(t, result.IsDuplicate)
+}
+
+func TestDeduplicationService_CleanupExpiredRecords(t *testing.T) {
+	userID := primitive.NewObjectID()
+	expired := testAvailabilityEvent()
+	current := expired
+	current.Date = "2024-06-16"
+
+	store := newMemoryDedupStore(
+		sentRecord(userID, expired, DefaultDeduplicationRetention+time.Hour),
+		sentRecord(userID, current, time.Hour),
+	)
+	service := NewDeduplicationService(store, DefaultDeduplicationConfig())
+
+	deleted, err := service.CleanupExpiredRecords(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	require.Len(t, store.records, 1)
+	assert.Equal(t, current.GenerateSlotKey(), store.records[0].SlotKey)
+}
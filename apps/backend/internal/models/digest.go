@@ -0,0 +1,172 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Delivery modes for court availability alerts
+const (
+	DeliveryModeInstant = "instant" // Send alerts as soon as matching courts are found (default)
+	DeliveryModeDigest  = "digest"  // Accumulate alerts and send one summary per day
+)
+
+// DefaultDigestSendHour is the local hour digests are sent when the user hasn't chosen one
+const DefaultDigestSendHour = 8
+
+// PendingDigestEntry is a matched court slot waiting to be included in a user's daily digest
+type PendingDigestEntry struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID        primitive.ObjectID `bson:"user_id" json:"user_id"`
+	SlotKey       string             `bson:"slot_key" json:"slot_key"`
+	VenueID       string             `bson:"venue_id" json:"venue_id"`
+	VenueName     string             `bson:"venue_name" json:"venue_name"`
+	CourtID       string             `bson:"court_id" json:"court_id"`
+	CourtName     string             `bson:"court_name" json:"court_name"`
+	SlotDate      string             `bson:"slot_date" json:"slot_date"`             // YYYY-MM-DD
+	SlotStartTime string             `bson:"slot_start_time" json:"slot_start_time"` // HH:MM
+	SlotEndTime   string             `bson:"slot_end_time" json:"slot_end_time"`     // HH:MM
+	Price         float64            `bson:"price" json:"price"`
+	Currency      string             `bson:"currency" json:"currency"`
+	BookingURL    string             `bson:"booking_url" json:"booking_url"`
+	DiscoveredAt  time.Time          `bson:"discovered_at" json:"discovered_at"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// DigestService manages the pending_digests collection
+type DigestService struct {
+	collection *mongo.Collection
+}
+
+// NewDigestService creates a new digest service
+func NewDigestService(db *mongo.Database) *DigestService {
+	return &DigestService{
+		collection: db.Collection("pending_digests"),
+	}
+}
+
+// AddToDigest queues an availability event for the user's next digest.
+// The same slot is only stored once per user, so repeated scrapes don't list it twice.
+func (s *DigestService) AddToDigest(ctx context.Context, userID primitive.ObjectID, event CourtAvailabilityEvent) error {
+	slotKey := event.GenerateSlotKey()
+
+	filter := bson.M{
+		"user_id":  userID,
+		"slot_key": slotKey,
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"venue_id":        event.VenueID,
+			"venue_name":      event.VenueName,
+			"court_id":        event.CourtID,
+			"court_name":      event.CourtName,
+			"slot_date":       event.Date,
+			"slot_start_time": event.StartTime,
+			"slot_end_time":   event.EndTime,
+			"price":           event.Price,
+			"currency":        event.Currency,
+			"booking_url":     event.BookingURL,
+			"discovered_at":   event.DiscoveredAt,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": time.Now(),
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := s.collection.UpdateOne(ctx, filter, update, opts)
+	return err
+}
+
+// GetPendingDigest returns all queued entries for a user, ordered by slot date and time
+func (s *DigestService) GetPendingDigest(ctx context.Context, userID primitive.ObjectID) ([]PendingDigestEntry, error) {
+	opts := options.Find().SetSort(bson.D{
+		{Key: "venue_name", Value: 1},
+		{Key: "slot_date", Value: 1},
+		{Key: "slot_start_time", Value: 1},
+	})
+
+	cursor, err := s.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []PendingDigestEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetUsersWithPendingDigests returns the IDs of users who have at least one queued entry
+func (s *DigestService) GetUsersWithPendingDigests(ctx context.Context) ([]primitive.ObjectID, error) {
+	values, err := s.collection.Distinct(ctx, "user_id", bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]primitive.ObjectID, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	return userIDs, nil
+}
+
+// ClearDigestEntries removes the given entries once they have been sent
+func (s *DigestService) ClearDigestEntries(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result, err := s.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// ClearExpiredEntries removes entries for slots whose date has already passed.
+// Dates are compared in UTC so the cutoff doesn't depend on the server's timezone.
+func (s *DigestService) ClearExpiredEntries(ctx context.Context) (int64, error) {
+	today := time.Now().UTC().Format("2006-01-02")
+
+	result, err := s.collection.DeleteMany(ctx, bson.M{"slot_date": bson.M{"$lt": today}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// CreateIndexes creates necessary indexes for the pending_digests collection
+func (s *DigestService) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "user_id", Value: 1},
+				{Key: "slot_key", Value: 1},
+			},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "slot_date", Value: 1},
+			},
+		},
+	}
+
+	_, err := s.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
@@ -26,25 +26,47 @@ type AlertHistory struct {
 	BookingURL    string             `bson:"booking_url" json:"booking_url"`
 	EmailAddress  string             `bson:"email_address" json:"email_address"`
 	AlertSentAt   time.Time          `bson:"alert_sent_at" json:"alert_sent_at"`
-	EmailStatus   string             `bson:"email_status" json:"email_status"` // sent, delivered, failed, bounced
-	SlotKey       string             `bson:"slot_key" json:"slot_key"`         // Unique key for deduplication
+	Channel       string             `bson:"channel,omitempty" json:"channel,omitempty"` // email, sms or webhook; empty means email
+	EmailStatus   string             `bson:"email_status" json:"email_status"`           // sent, delivered, failed, bounced
+	SlotKey       string             `bson:"slot_key" json:"slot_key"`                   // Unique key for deduplication
 	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
 }
 
+// CourtAvailabilitySchemaVersion is the version of the CourtAvailabilityEvent
+// JSON published to Redis. Bump it when a field is renamed or changes meaning,
+// so consumers can tell which shape a message has.
+const CourtAvailabilitySchemaVersion = 1
+
 // CourtAvailabilityEvent represents a court availability event from Redis
 type CourtAvailabilityEvent struct {
-	VenueID      string    `json:"venue_id"`
-	VenueName    string    `json:"venue_name"`
-	CourtID      string    `json:"court_id"`
-	CourtName    string    `json:"court_name"`
-	Date         string    `json:"date"`       // Format: "YYYY-MM-DD"
-	StartTime    string    `json:"start_time"` // Format: "HH:MM"
-	EndTime      string    `json:"end_time"`   // Format: "HH:MM"
-	Price        float64   `json:"price"`
-	Currency     string    `json:"currency"`
-	BookingURL   string    `json:"booking_url"`
-	DiscoveredAt time.Time `json:"discovered_at"`
-	ScrapeLogID  string    `json:"scrape_log_id"`
+	SchemaVersion int       `json:"schema_version"` // CourtAvailabilitySchemaVersion; 0 on events from before versioning
+	VenueID       string    `json:"venue_id"`
+	VenueName     string    `json:"venue_name"`
+	Platform      string    `json:"platform,omitempty"`
+	CourtID       string    `json:"court_id"`
+	CourtName     string    `json:"court_name"`
+	Date          string    `json:"date"`       // Format: "YYYY-MM-DD"
+	StartTime     string    `json:"start_time"` // Format: "HH:MM"
+	EndTime       string    `json:"end_time"`   // Format: "HH:MM"
+	Price         float64   `json:"price"`
+	Currency      string    `json:"currency"`
+	BookingURL    string    `json:"booking_url"`
+	Available     *bool     `json:"available,omitempty"` // False when the slot was booked; unset means available
+	DiscoveredAt  time.Time `json:"discovered_at"`
+	ScrapeLogID   string    `json:"scrape_log_id"`
+	AlertType     string    `json:"alert_type,omitempty"` // AlertTypeNewSlot or AlertTypeCancellation
+}
+
+// Alert types, describing why a slot became available
+const (
+	AlertTypeNewSlot      = "new_slot"     // The slot appeared for the first time
+	AlertTypeCancellation = "cancellation" // The slot was booked and has freed up again
+)
+
+// IsAvailable reports whether the slot can be booked. Events without the
+// field predate cancellation tracking, when only available slots were published.
+func (e *CourtAvailabilityEvent) IsAvailable() bool {
+	return e.Available == nil || *e.Available
 }
 
 // GenerateSlotKey creates a unique identifier for a court slot
@@ -153,6 +175,34 @@ func (s *AlertHistoryService) GetUserAlertHistory(ctx context.Context, userID pr
 	return alerts, nil
 }
 
+// GetUserAlertHistoryPage retrieves one page of a user's alert history, newest first,
+// along with the total number of alerts for the user
+func (s *AlertHistoryService) GetUserAlertHistoryPage(ctx context.Context, userID primitive.ObjectID, limit, offset int64) ([]AlertHistory, int64, error) {
+	filter := bson.M{"user_id": userID}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "alert_sent_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(offset).
+		SetLimit(limit)
+
+	cursor, err := s.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	alerts := []AlertHistory{}
+	if err = cursor.All(ctx, &alerts); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return alerts, total, nil
+}
+
 // Collection returns the MongoDB collection name
 func (s *AlertHistoryService) Collection() string {
 	return "alert_history"
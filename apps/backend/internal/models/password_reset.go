@@ -0,0 +1,143 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PasswordResetTokenTTL is how long an emailed password reset link stays valid
+const PasswordResetTokenTTL = time.Hour
+
+// ErrInvalidPasswordResetToken is returned for unknown, expired or already used reset tokens
+var ErrInvalidPasswordResetToken = errors.New("invalid or expired password reset token")
+
+// PasswordReset represents a single-use password reset token stored in the database
+type PasswordReset struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	TokenHash string             `bson:"token_hash" json:"-"` // Never expose in JSON
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty" json:"used_at,omitempty"`
+}
+
+// PasswordResetService manages password reset tokens in the password_resets collection
+type PasswordResetService struct {
+	collection *mongo.Collection
+}
+
+// NewPasswordResetService creates a new password reset service
+func NewPasswordResetService(db *mongo.Database) *PasswordResetService {
+	return &PasswordResetService{
+		collection: db.Collection("password_resets"),
+	}
+}
+
+// hashResetToken creates a SHA-256 hash of the token for secure storage
+func hashResetToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateResetToken generates a reset token for the user, stores its hash and
+// returns the token to email to the user
+func (s *PasswordResetService) CreateResetToken(ctx context.Context, userID primitive.ObjectID, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	now := time.Now()
+	reset := &PasswordReset{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	if _, err := s.collection.InsertOne(ctx, reset); err != nil {
+		return "", fmt.Errorf("failed to create password reset token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ConsumeResetToken claims an unused, unexpired reset token and calls apply with
+// its user. The claim is atomic, so a token can only ever be consumed once. If
+// apply fails the claim is released and the token can be used again.
+func (s *PasswordResetService) ConsumeResetToken(ctx context.Context, token string, apply func(userID primitive.ObjectID) error) error {
+	now := time.Now()
+
+	filter := bson.M{
+		"token_hash": hashResetToken(token),
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+	update := bson.M{"$set": bson.M{"used_at": now}}
+
+	var reset PasswordReset
+	err := s.collection.FindOneAndUpdate(ctx, filter, update).Decode(&reset)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ErrInvalidPasswordResetToken
+		}
+		return fmt.Errorf("failed to consume password reset token: %w", err)
+	}
+
+	if err := apply(reset.UserID); err != nil {
+		release := bson.M{"$unset": bson.M{"used_at": ""}}
+		if _, releaseErr := s.collection.UpdateOne(ctx, bson.M{"_id": reset.ID, "used_at": now}, release); releaseErr != nil {
+			return fmt.Errorf("%w (and failed to release password reset token: %v)", err, releaseErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// InvalidateUserResetTokens marks every outstanding reset token for the user as used
+func (s *PasswordResetService) InvalidateUserResetTokens(ctx context.Context, userID primitive.ObjectID) error {
+	filter := bson.M{
+		"user_id": userID,
+		"used_at": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"used_at": time.Now()}}
+
+	if _, err := s.collection.UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to invalidate password reset tokens: %w", err)
+	}
+	return nil
+}
+
+// CreateIndexes creates the token lookup index and a TTL index that removes expired tokens
+func (s *PasswordResetService) CreateIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}},
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	}
+
+	_, err := s.collection.Indexes().CreateMany(ctx, indexes)
+	return err
+}
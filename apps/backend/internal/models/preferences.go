@@ -3,6 +3,9 @@ package models
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -11,6 +14,9 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrPreferencesNotFound is returned when a user has no saved preferences to update
+var ErrPreferencesNotFound = errors.New("preferences not found")
+
 // UserPreferences represents user preferences for tennis court booking
 type UserPreferences struct {
 	ID                   primitive.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`
@@ -19,10 +25,12 @@ type UserPreferences struct {
 	WeekdayTimes         []TimeRange          `bson:"weekday_times,omitempty" json:"weekday_times,omitempty"` // Monday-Friday preferred times
 	WeekendTimes         []TimeRange          `bson:"weekend_times,omitempty" json:"weekend_times,omitempty"` // Saturday-Sunday preferred times
 	MaxPrice             float64              `bson:"max_price,omitempty" json:"max_price,omitempty"`
+	TargetPrice          float64              `bson:"target_price,omitempty" json:"target_price,omitempty"` // Slots at or below this price are alerted as deals; 0 disables deal alerts
 	PreferredVenues      []string             `bson:"preferred_venues,omitempty" json:"preferred_venues,omitempty"`
 	ExcludedVenues       []string             `bson:"excluded_venues,omitempty" json:"excluded_venues,omitempty"`
 	PreferredDays        []string             `bson:"preferred_days,omitempty" json:"preferred_days,omitempty"` // "monday", "tuesday", etc.
 	NotificationSettings NotificationSettings `bson:"notification_settings,omitempty" json:"notification_settings,omitempty"`
+	DisplaySettings      DisplaySettings      `bson:"display_settings,omitempty" json:"display_settings,omitempty"`
 	CreatedAt            time.Time            `bson:"created_at" json:"created_at"`
 	UpdatedAt            time.Time            `bson:"updated_at" json:"updated_at"`
 }
@@ -31,12 +39,205 @@ type UserPreferences struct {
 type NotificationSettings struct {
 	Email                bool   `bson:"email" json:"email"`
 	EmailAddress         string `bson:"email_address,omitempty" json:"email_address,omitempty"`
+	SMS                  bool   `bson:"sms,omitempty" json:"sms,omitempty"`                                         // Receive alerts via SMS
+	PhoneNumber          string `bson:"phone_number,omitempty" json:"phone_number,omitempty"`                       // E.164 number used for SMS alerts, e.g. "+447700900123"
 	InstantAlerts        bool   `bson:"instant_alerts" json:"instant_alerts"`                                       // Receive alerts immediately when courts become available
 	MaxAlertsPerHour     int    `bson:"max_alerts_per_hour,omitempty" json:"max_alerts_per_hour,omitempty"`         // Rate limiting (default: 10)
 	MaxAlertsPerDay      int    `bson:"max_alerts_per_day,omitempty" json:"max_alerts_per_day,omitempty"`           // Daily limit (default: 50)
+	BufferLimitedAlerts  bool   `bson:"buffer_limited_alerts,omitempty" json:"buffer_limited_alerts,omitempty"`     // Over a limit, send what was missed once it resets instead of dropping it
 	AlertTimeWindowStart string `bson:"alert_time_window_start,omitempty" json:"alert_time_window_start,omitempty"` // e.g., "07:00" - when to start sending alerts
 	AlertTimeWindowEnd   string `bson:"alert_time_window_end,omitempty" json:"alert_time_window_end,omitempty"`     // e.g., "22:00" - when to stop sending alerts
 	Unsubscribed         bool   `bson:"unsubscribed,omitempty" json:"unsubscribed,omitempty"`                       // User has unsubscribed from all alerts
+	DeliveryMode         string `bson:"delivery_mode,omitempty" json:"delivery_mode,omitempty"`                     // "instant" (default) or "digest"
+	DigestSendHour       *int   `bson:"digest_send_hour,omitempty" json:"digest_send_hour,omitempty"`               // Local hour (0-23) to send the daily digest, defaults to 8 when unset
+	WebhookURL           string `bson:"webhook_url,omitempty" json:"webhook_url,omitempty"`                         // Endpoint that receives alerts as JSON POSTs
+	WebhookSecret        string `bson:"webhook_secret,omitempty" json:"-"`                                          // Optional HMAC-SHA256 key used to sign webhook bodies
+	VenueCooldownMinutes int    `bson:"venue_cooldown_minutes,omitempty" json:"venue_cooldown_minutes,omitempty"`   // Minimum gap between alerts for the same venue; 0 disables the cooldown
+
+	// Override how long a sent alert suppresses repeats; 0 uses the service defaults
+	ExactDuplicateWindowMinutes   int `bson:"exact_duplicate_window_minutes,omitempty" json:"exact_duplicate_window_minutes,omitempty"`     // Same slot at the same price (default 24h, at most 48h)
+	SimilarDuplicateWindowMinutes int `bson:"similar_duplicate_window_minutes,omitempty" json:"similar_duplicate_window_minutes,omitempty"` // Same court and start time on another date (default 1h)
+
+	// SnoozeUntil pauses alerts until this time; they resume on their own once it passes.
+	// Only POST /api/users/preferences/snooze sets it.
+	SnoozeUntil *time.Time `bson:"snooze_until,omitempty" json:"snooze_until,omitempty"`
+}
+
+// SetFields returns a $set document for the settings a client can change, each
+// under prefix, e.g. "notification_settings.email". Setting fields one at a time
+// leaves server-managed ones, the webhook secret and the snooze, as they are.
+// Every client-settable field is included, so one left out of a request is cleared.
+func (n NotificationSettings) SetFields(prefix string) bson.M {
+	return bson.M{
+		prefix + ".email":                            n.Email,
+		prefix + ".email_address":                    n.EmailAddress,
+		prefix + ".sms":                              n.SMS,
+		prefix + ".phone_number":                     n.PhoneNumber,
+		prefix + ".instant_alerts":                   n.InstantAlerts,
+		prefix + ".max_alerts_per_hour":              n.MaxAlertsPerHour,
+		prefix + ".max_alerts_per_day":               n.MaxAlertsPerDay,
+		prefix + ".buffer_limited_alerts":            n.BufferLimitedAlerts,
+		prefix + ".alert_time_window_start":          n.AlertTimeWindowStart,
+		prefix + ".alert_time_window_end":            n.AlertTimeWindowEnd,
+		prefix + ".unsubscribed":                     n.Unsubscribed,
+		prefix + ".delivery_mode":                    n.DeliveryMode,
+		prefix + ".digest_send_hour":                 n.DigestSendHour,
+		prefix + ".webhook_url":                      n.WebhookURL,
+		prefix + ".venue_cooldown_minutes":           n.VenueCooldownMinutes,
+		prefix + ".exact_duplicate_window_minutes":   n.ExactDuplicateWindowMinutes,
+		prefix + ".similar_duplicate_window_minutes": n.SimilarDuplicateWindowMinutes,
+	}
+}
+
+// SnoozeRemaining returns how long alerts are still snoozed for at now, or 0 if they aren't
+func (n NotificationSettings) SnoozeRemaining(now time.Time) time.Duration {
+	if n.SnoozeUntil == nil || !n.SnoozeUntil.After(now) {
+		return 0
+	}
+	return n.SnoozeUntil.Sub(now)
+}
+
+// DisplaySettings represents how times and dates are presented to the user
+type DisplaySettings struct {
+	Timezone string `bson:"timezone,omitempty" json:"timezone,omitempty"` // IANA zone name, e.g. "Europe/London"
+	Language string `bson:"language,omitempty" json:"language,omitempty"` // Language tag for alert emails, e.g. "fr" or "es-MX"; unsupported languages get English
+}
+
+// languageTagPattern loosely matches BCP 47 language tags such as "en", "fr-CA" or "es_419"
+var languageTagPattern = regexp.MustCompile(`^[A-Za-z]{2,3}([-_][A-Za-z0-9]{2,8})*$`)
+
+// FieldError describes why one field failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrors lists every field that failed validation
+type FieldErrors []FieldError
+
+// Error joins the field errors into one message
+func (e FieldErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Map returns the errors keyed by field
+func (e FieldErrors) Map() map[string]string {
+	fields := make(map[string]string, len(e))
+	for _, fieldErr := range e {
+		fields[fieldErr.Field] = fieldErr.Message
+	}
+	return fields
+}
+
+// weekdays are the accepted PreferredDays values
+var weekdays = map[string]bool{
+	"monday": true, "tuesday": true, "wednesday": true, "thursday": true,
+	"friday": true, "saturday": true, "sunday": true,
+}
+
+// Validate checks the preferences the alert matcher relies on: time ranges are
+// "HH:MM" with the start before the end, prices aren't negative, preferred
+// days are lowercase weekday names, venues are given by ID, the webhook URL is
+// https to a public host and display settings name a known timezone and a
+// language code. It returns nil when
+// they're valid.
+func (p *UserPreferences) Validate() FieldErrors {
+	var errs FieldErrors
+
+	for _, field := range []struct {
+		name   string
+		ranges []TimeRange
+	}{
+		{"times", p.Times},
+		{"weekday_times", p.WeekdayTimes},
+		{"weekend_times", p.WeekendTimes},
+	} {
+		for i, timeRange := range field.ranges {
+			errs = append(errs, timeRange.validate(fmt.Sprintf("%s[%d]", field.name, i))...)
+		}
+	}
+
+	if p.MaxPrice < 0 {
+		errs = append(errs, FieldError{Field: "max_price", Message: "must not be negative"})
+	}
+	if p.TargetPrice < 0 {
+		errs = append(errs, FieldError{Field: "target_price", Message: "must not be negative"})
+	}
+
+	if tz := p.DisplaySettings.Timezone; tz != "" {
+		if _, err := time.LoadLocation(tz); err != nil {
+			errs = append(errs, FieldError{Field: "display_settings.timezone", Message: fmt.Sprintf("unknown timezone %q", tz)})
+		}
+	}
+	if lang := p.DisplaySettings.Language; lang != "" && !languageTagPattern.MatchString(lang) {
+		errs = append(errs, FieldError{Field: "display_settings.language", Message: fmt.Sprintf("invalid language %q, expected a language code such as \"fr\"", lang)})
+	}
+
+	for i, day := range p.PreferredDays {
+		if !weekdays[day] {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("preferred_days[%d]", i),
+				Message: fmt.Sprintf("unknown day %q, expected a lowercase weekday such as \"monday\"", day),
+			})
+		}
+	}
+
+	if webhookURL := p.NotificationSettings.WebhookURL; webhookURL != "" {
+		if err := ValidateWebhookURL(webhookURL); err != nil {
+			errs = append(errs, FieldError{Field: "notification_settings.webhook_url", Message: err.Error()})
+		}
+	}
+
+	// The matcher compares venues by ID, so a venue name would never match
+	for _, field := range []struct {
+		name   string
+		venues []string
+	}{
+		{"preferred_venues", p.PreferredVenues},
+		{"excluded_venues", p.ExcludedVenues},
+	} {
+		for i, venue := range field.venues {
+			if !primitive.IsValidObjectID(venue) {
+				errs = append(errs, FieldError{
+					Field:   fmt.Sprintf("%s[%d]", field.name, i),
+					Message: fmt.Sprintf("invalid venue %q, expected a venue ID", venue),
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// validate checks the range's format and order; field prefixes the error fields
+func (tr TimeRange) validate(field string) FieldErrors {
+	var errs FieldErrors
+
+	start, okStart := parseStrictClockMinutes(tr.Start)
+	if !okStart {
+		errs = append(errs, FieldError{Field: field + ".start", Message: fmt.Sprintf("invalid time %q, expected HH:MM", tr.Start)})
+	}
+	end, okEnd := parseStrictClockMinutes(tr.End)
+	if !okEnd {
+		errs = append(errs, FieldError{Field: field + ".end", Message: fmt.Sprintf("invalid time %q, expected HH:MM", tr.End)})
+	}
+	if okStart && okEnd && start >= end {
+		errs = append(errs, FieldError{Field: field, Message: "start must be before end"})
+	}
+
+	return errs
+}
+
+// parseStrictClockMinutes is parseClockMinutes, also requiring two-digit hours
+func parseStrictClockMinutes(value string) (int, bool) {
+	if len(value) != len("15:04") {
+		return 0, false
+	}
+	return parseClockMinutes(value)
 }
 
 // PreferenceRequest represents the request payload for updating preferences
@@ -45,10 +246,12 @@ type PreferenceRequest struct {
 	WeekdayTimes         []TimeRange           `json:"weekday_times,omitempty" binding:"dive"` // Monday-Friday preferred times
 	WeekendTimes         []TimeRange           `json:"weekend_times,omitempty" binding:"dive"` // Saturday-Sunday preferred times
 	MaxPrice             *float64              `json:"max_price,omitempty" binding:"omitempty,gte=0"`
+	TargetPrice          *float64              `json:"target_price,omitempty" binding:"omitempty,gte=0"`
 	PreferredVenues      []string              `json:"preferred_venues,omitempty"`
 	ExcludedVenues       []string              `json:"excluded_venues,omitempty"`
 	PreferredDays        []string              `json:"preferred_days,omitempty" binding:"dive,oneof=monday tuesday wednesday thursday friday saturday sunday"`
 	NotificationSettings *NotificationSettings `json:"notification_settings,omitempty"`
+	DisplaySettings      *DisplaySettings      `json:"display_settings,omitempty"`
 }
 
 // AddVenueRequest represents the request payload for adding a venue to preferences
@@ -133,6 +336,9 @@ func (s *PreferenceService) UpdateUserPreferences(ctx context.Context, userID pr
 	if req.MaxPrice != nil {
 		updateDoc["$set"].(bson.M)["max_price"] = *req.MaxPrice
 	}
+	if req.TargetPrice != nil {
+		updateDoc["$set"].(bson.M)["target_price"] = *req.TargetPrice
+	}
 	if req.PreferredVenues != nil {
 		updateDoc["$set"].(bson.M)["preferred_venues"] = req.PreferredVenues
 	}
@@ -143,7 +349,12 @@ func (s *PreferenceService) UpdateUserPreferences(ctx context.Context, userID pr
 		updateDoc["$set"].(bson.M)["preferred_days"] = req.PreferredDays
 	}
 	if req.NotificationSettings != nil {
-		updateDoc["$set"].(bson.M)["notification_settings"] = *req.NotificationSettings
+		for name, value := range req.NotificationSettings.SetFields("notification_settings") {
+			updateDoc["$set"].(bson.M)[name] = value
+		}
+	}
+	if req.DisplaySettings != nil {
+		updateDoc["$set"].(bson.M)["display_settings"] = *req.DisplaySettings
 	}
 
 	// Upsert the document
@@ -193,6 +404,93 @@ func (s *PreferenceService) AddVenueToPreferredList(ctx context.Context, userID
 	return err
 }
 
+// SetPreferredVenues replaces the user's preferred venues list in a single update
+func (s *PreferenceService) SetPreferredVenues(ctx context.Context, userID primitive.ObjectID, venueIDs []string) error {
+	filter := bson.M{"user_id": userID}
+	update := bson.M{
+		"$set": bson.M{
+			"preferred_venues": venueIDs,
+			"updated_at":       time.Now(),
+		},
+		"$setOnInsert": bson.M{
+			"user_id":         userID,
+			"created_at":      time.Now(),
+			"times":           []TimeRange{},
+			"max_price":       0,
+			"excluded_venues": []string{},
+			"preferred_days":  []string{},
+			"notification_settings": NotificationSettings{
+				Email:                true,
+				InstantAlerts:        true,
+				MaxAlertsPerHour:     10,
+				MaxAlertsPerDay:      50,
+				AlertTimeWindowStart: "07:00",
+				AlertTimeWindowEnd:   "22:00",
+				Unsubscribed:         false,
+			},
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := s.collection.UpdateOne(ctx, filter, update, opts)
+	return err
+}
+
+// SetSnoozeUntil pauses the user's alerts until the given time
+func (s *PreferenceService) SetSnoozeUntil(ctx context.Context, userID primitive.ObjectID, until time.Time) error {
+	filter := bson.M{"user_id": userID}
+	update := bson.M{
+		"$set": bson.M{
+			"notification_settings.snooze_until": until,
+			"updated_at":                         time.Now(),
+		},
+	}
+
+	result, err := s.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrPreferencesNotFound
+	}
+	return nil
+}
+
+// Unsubscribe turns off all of the user's alerts. It's idempotent: a user
+// without preferences has nothing to unsubscribe from.
+func (s *PreferenceService) Unsubscribe(ctx context.Context, userID primitive.ObjectID) error {
+	filter := bson.M{"user_id": userID}
+	update := bson.M{
+		"$set": bson.M{
+			"notification_settings.unsubscribed": true,
+			"updated_at":                         time.Now(),
+		},
+	}
+
+	_, err := s.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// SetWebhookSecret replaces the key the user's webhook deliveries are signed with
+func (s *PreferenceService) SetWebhookSecret(ctx context.Context, userID primitive.ObjectID, secret string) error {
+	filter := bson.M{"user_id": userID}
+	update := bson.M{
+		"$set": bson.M{
+			"notification_settings.webhook_secret": secret,
+			"updated_at":                           time.Now(),
+		},
+	}
+
+	result, err := s.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrPreferencesNotFound
+	}
+	return nil
+}
+
 // AddVenueToExcludedList adds a venue to the user's excluded venues list
 func (s *PreferenceService) AddVenueToExcludedList(ctx context.Context, userID primitive.ObjectID, venueID string) error {
 	filter := bson.M{"user_id": userID}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -241,3 +243,90 @@ func TestPreferenceService_CreateIndexes(t *testing.T) {
 	// Verify all indexes were created correctly
 }
 */
+
+func TestUserPreferences_Validate(t *testing.T) {
+	valid := &UserPreferences{
+		Times:                []TimeRange{{Start: "09:00", End: "11:00"}},
+		WeekdayTimes:         []TimeRange{{Start: "18:00", End: "20:00"}},
+		WeekendTimes:         []TimeRange{{Start: "00:00", End: "23:59"}},
+		PreferredDays:        []string{"monday", "sunday"},
+		PreferredVenues:      []string{primitive.NewObjectID().Hex()},
+		ExcludedVenues:       []string{primitive.NewObjectID().Hex()},
+		MaxPrice:             25,
+		TargetPrice:          10,
+		DisplaySettings:      DisplaySettings{Timezone: "Europe/Paris", Language: "fr-CA"},
+		NotificationSettings: NotificationSettings{WebhookURL: "https://hooks.example.com/tennis"},
+	}
+	assert.Empty(t, valid.Validate())
+	assert.Empty(t, (&UserPreferences{}).Validate())
+
+	invalid := &UserPreferences{
+		Times:                []TimeRange{{Start: "9:00", End: "25:00"}},
+		WeekdayTimes:         []TimeRange{{Start: "18:00", End: "20:00"}, {Start: "20:00", End: "18:00"}},
+		WeekendTimes:         []TimeRange{{Start: "10:00", End: "10:00"}},
+		PreferredDays:        []string{"Monday", "someday"},
+		PreferredVenues:      []string{primitive.NewObjectID().Hex(), "Victoria Park"},
+		ExcludedVenues:       []string{""},
+		MaxPrice:             -1,
+		TargetPrice:          -5,
+		DisplaySettings:      DisplaySettings{Timezone: "Mars/Olympus", Language: "french!"},
+		NotificationSettings: NotificationSettings{WebhookURL: "http://hooks.example.com/tennis"},
+	}
+	errs := invalid.Validate()
+
+	assert.Equal(t, []string{
+		"times[0].start",
+		"times[0].end",
+		"weekday_times[1]",
+		"weekend_times[0]",
+		"max_price",
+		"target_price",
+		"display_settings.timezone",
+		"display_settings.language",
+		"preferred_days[0]",
+		"preferred_days[1]",
+		"notification_settings.webhook_url",
+		"preferred_venues[1]",
+		"excluded_venues[0]",
+	}, fieldNames(errs))
+	assert.Len(t, errs.Map(), len(errs))
+	assert.Contains(t, errs.Error(), "max_price: must not be negative")
+}
+
+func fieldNames(errs FieldErrors) []string {
+	names := make([]string, 0, len(errs))
+	for _, fieldErr := range errs {
+		names = append(names, fieldErr.Field)
+	}
+	return names
+}
+
+func TestNotificationSettings_SnoozeRemaining(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(90*time.Minute), now.Add(-time.Minute)
+
+	assert.Equal(t, 90*time.Minute, NotificationSettings{SnoozeUntil: &later}.SnoozeRemaining(now))
+	assert.Zero(t, NotificationSettings{SnoozeUntil: &earlier}.SnoozeRemaining(now))
+	assert.Zero(t, NotificationSettings{}.SnoozeRemaining(now))
+}
+
+func TestNotificationSettings_SetFields(t *testing.T) {
+	snoozeUntil := time.Now().Add(365 * 24 * time.Hour)
+	fields := NotificationSettings{Email: true, WebhookURL: "https://hooks.example.com", WebhookSecret: "s3cret", SnoozeUntil: &snoozeUntil}.SetFields("notification_settings")
+
+	assert.Equal(t, true, fields["notification_settings.email"])
+	assert.Equal(t, "https://hooks.example.com", fields["notification_settings.webhook_url"])
+	assert.Contains(t, fields, "notification_settings.phone_number", "unset fields are cleared")
+	assert.NotContains(t, fields, "notification_settings.webhook_secret", "server-managed fields are left alone")
+	assert.NotContains(t, fields, "notification_settings.snooze_until", "server-managed fields are left alone")
+
+	// Every other setting must be listed, or clients can't change it
+	settingsType := reflect.TypeOf(NotificationSettings{})
+	for i := 0; i < settingsType.NumField(); i++ {
+		name, _, _ := strings.Cut(settingsType.Field(i).Tag.Get("bson"), ",")
+		if name == "webhook_secret" || name == "snooze_until" {
+			continue
+		}
+		assert.Contains(t, fields, "notification_settings."+name)
+	}
+}
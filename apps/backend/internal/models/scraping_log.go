@@ -22,7 +22,14 @@ type ScrapingLog struct {
 	UserAgent        string             `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
 	IPAddress        string             `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
 	RunID            string             `bson:"run_id,omitempty" json:"run_id,omitempty"` // To group multiple scrapes
-	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+
+	// Set by the scraper when a venue that usually has slots returns none several scrapes in a row
+	SuspectedBrokenSelector bool `bson:"suspected_broken_selector,omitempty" json:"suspected_broken_selector,omitempty"`
+
+	// Set by the scraper, which retries failed scrapes up to the venue's RetryCount
+	Attempts       int       `bson:"attempts,omitempty" json:"attempts,omitempty"`
+	RetrySucceeded bool      `bson:"retry_succeeded,omitempty" json:"retry_succeeded,omitempty"`
+	CreatedAt      time.Time `bson:"created_at" json:"created_at"`
 }
 
 // Slot represents an available court slot found during scraping
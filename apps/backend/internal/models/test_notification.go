@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Channels a test notification can be sent on, matching AlertHistory.Channel
+const (
+	NotificationChannelEmail   = "email"
+	NotificationChannelSMS     = "sms"
+	NotificationChannelWebhook = "webhook"
+)
+
+// TestNotificationTimeout is how long the backend waits for the notification
+// service to send a test notification and reply
+const TestNotificationTimeout = 15 * time.Second
+
+// ErrTestNotificationTimeout is returned when the notification service doesn't
+// reply to a test notification in time, because it is busy or stopped
+var ErrTestNotificationTimeout = errors.New("notification service did not reply to the test notification in time")
+
+// ValidNotificationChannel reports whether channel is one alerts can be sent on
+func ValidNotificationChannel(channel string) bool {
+	switch channel {
+	case NotificationChannelEmail, NotificationChannelSMS, NotificationChannelWebhook:
+		return true
+	}
+	return false
+}
+
+// TestNotificationRequest asks the notification service to send a sample alert
+// to the address the user has configured for a channel, so they can check it
+// reaches them
+type TestNotificationRequest struct {
+	ID        string `json:"id"` // Set by the backend; the reply is pushed to a key named after it
+	UserID    string `json:"user_id"`
+	Channel   string `json:"channel"`
+	ExpiresAt int64  `json:"expires_at"` // Unix seconds; set by the backend, which stops waiting then
+}
+
+// TestNotificationResult is whether the sample alert was sent. Address is
+// masked, since it's echoed back to the client.
+type TestNotificationResult struct {
+	RequestID string `json:"request_id"`
+	Channel   string `json:"channel"`
+	Success   bool   `json:"success"`
+	Address   string `json:"address,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidNotificationChannel(t *testing.T) {
+	for _, channel := range []string{NotificationChannelEmail, NotificationChannelSMS, NotificationChannelWebhook} {
+		assert.True(t, ValidNotificationChannel(channel), channel)
+	}
+	for _, channel := range []string{"", "EMAIL", "push", "email,sms"} {
+		assert.False(t, ValidNotificationChannel(channel), channel)
+	}
+}
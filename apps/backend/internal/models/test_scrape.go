@@ -0,0 +1,97 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Test scrape timeouts. The scrape has to finish well inside the server's
+// write timeout, since the admin waits on the request.
+const (
+	DefaultTestScrapeTimeoutSeconds = 15
+	MaxTestScrapeTimeoutSeconds     = 20
+)
+
+// ErrTestScrapeTimeout is returned when the scraper service doesn't reply to a
+// test scrape in time, because it is busy, stopped or the scrape hung
+var ErrTestScrapeTimeout = errors.New("scraper did not reply to the test scrape in time")
+
+// TestScrapeRequest asks the scraper service to scrape one date with a scraper
+// config that hasn't been saved, so admins can check a venue before adding it
+type TestScrapeRequest struct {
+	ID             string        `json:"id"` // Set by the backend; the reply is pushed to a key named after it
+	URL            string        `json:"url"`
+	ScraperConfig  ScraperConfig `json:"scraper_config"`
+	Date           string        `json:"date,omitempty"`     // YYYY-MM-DD; defaults to today in the venue's timezone
+	Timezone       string        `json:"timezone,omitempty"` // Defaults to Europe/London
+	TimeoutSeconds int           `json:"timeout_seconds,omitempty"`
+	ExpiresAt      int64         `json:"expires_at"` // Unix seconds; set by the backend, which stops waiting then
+}
+
+// Validate checks the request and applies the default timeout
+func (r *TestScrapeRequest) Validate() error {
+	parsed, err := url.Parse(r.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: url must be an http or https URL", ErrInvalidInput)
+	}
+	if err := r.ScraperConfig.Validate(); err != nil {
+		return err
+	}
+	if r.Date != "" {
+		if _, err := time.Parse("2006-01-02", r.Date); err != nil {
+			return fmt.Errorf("%w: date must be in YYYY-MM-DD format", ErrInvalidInput)
+		}
+	}
+	if r.Timezone != "" {
+		if _, err := time.LoadLocation(r.Timezone); err != nil {
+			return fmt.Errorf("%w: unknown timezone %q", ErrInvalidInput, r.Timezone)
+		}
+	}
+
+	switch {
+	case r.TimeoutSeconds < 0:
+		return fmt.Errorf("%w: timeout_seconds must not be negative", ErrInvalidInput)
+	case r.TimeoutSeconds == 0:
+		r.TimeoutSeconds = DefaultTestScrapeTimeoutSeconds
+	case r.TimeoutSeconds > MaxTestScrapeTimeoutSeconds:
+		r.TimeoutSeconds = MaxTestScrapeTimeoutSeconds
+	}
+	return nil
+}
+
+// TestScrapeResult is what a test scrape found. Nothing in it is stored.
+type TestScrapeResult struct {
+	RequestID  string               `json:"request_id"`
+	Success    bool                 `json:"success"`
+	Date       string               `json:"date,omitempty"`
+	PageURL    string               `json:"page_url,omitempty"` // The page selectors were checked on
+	UserAgent  string               `json:"user_agent,omitempty"`
+	Slots      []TestScrapeSlot     `json:"slots"`
+	Selectors  []SelectorDiagnostic `json:"selectors"`
+	Errors     []string             `json:"errors"`
+	DurationMs int                  `json:"duration_ms"`
+}
+
+// TestScrapeSlot is a slot found by a test scrape
+type TestScrapeSlot struct {
+	CourtID    string   `json:"court_id"`
+	CourtName  string   `json:"court_name"`
+	Date       string   `json:"date"`
+	StartTime  string   `json:"start_time"`
+	EndTime    string   `json:"end_time"`
+	Price      *float64 `json:"price"`
+	Currency   string   `json:"currency"`
+	Available  bool     `json:"available"`
+	BookingURL string   `json:"booking_url,omitempty"`
+}
+
+// SelectorDiagnostic reports how many elements a selector matched on the page.
+// A selector that matches nothing is the usual reason a scrape finds no slots.
+type SelectorDiagnostic struct {
+	Name     string `json:"name"` // Key in selector_mappings, or the scraper's default
+	Selector string `json:"selector"`
+	Matches  int    `json:"matches"`
+	Error    string `json:"error,omitempty"` // e.g. the selector isn't valid CSS
+}
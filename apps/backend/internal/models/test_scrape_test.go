@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestScrapeRequest_Validate(t *testing.T) {
+	valid := func() TestScrapeRequest {
+		return TestScrapeRequest{
+			URL:           "https://example.com/book",
+			ScraperConfig: ScraperConfig{Type: ScraperTypeCourtside},
+		}
+	}
+
+	req := valid()
+	assert.NoError(t, req.Validate())
+	assert.Equal(t, DefaultTestScrapeTimeoutSeconds, req.TimeoutSeconds)
+
+	req = valid()
+	req.TimeoutSeconds = 600
+	assert.NoError(t, req.Validate())
+	assert.Equal(t, MaxTestScrapeTimeoutSeconds, req.TimeoutSeconds, "long timeouts are capped")
+
+	invalid := map[string]func(*TestScrapeRequest){
+		"missing url":      func(r *TestScrapeRequest) { r.URL = "" },
+		"relative url":     func(r *TestScrapeRequest) { r.URL = "/book" },
+		"invalid config":   func(r *TestScrapeRequest) { r.ScraperConfig.Type = "" },
+		"bad date":         func(r *TestScrapeRequest) { r.Date = "01/06/2030" },
+		"unknown timezone": func(r *TestScrapeRequest) { r.Timezone = "Mars/Olympus" },
+		"negative timeout": func(r *TestScrapeRequest) { r.TimeoutSeconds = -1 },
+	}
+	for name, modify := range invalid {
+		req := valid()
+		modify(&req)
+		assert.ErrorIs(t, req.Validate(), ErrInvalidInput, name)
+	}
+}
@@ -14,18 +14,25 @@ import (
 
 // User represents a user in the system
 type User struct {
-	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Username        string             `bson:"username" json:"username"`
-	Email           string             `bson:"email" json:"email"`
-	HashedPassword  string             `bson:"hashed_password" json:"-"` // Never expose in JSON
-	Name            string             `bson:"name" json:"name"`
-	Phone           string             `bson:"phone,omitempty" json:"phone,omitempty"`
-	PreferredCourts []string           `bson:"preferred_courts,omitempty" json:"preferred_courts,omitempty"`
-	PreferredDays   []string           `bson:"preferred_days,omitempty" json:"preferred_days,omitempty"`
-	PreferredTimes  []TimeRange        `bson:"preferred_times,omitempty" json:"preferred_times,omitempty"`
-	NotifyBy        []string           `bson:"notify_by,omitempty" json:"notify_by,omitempty"` // "email", "sms"
-	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+	ID                 primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Username           string             `bson:"username" json:"username"`
+	Email              string             `bson:"email" json:"email"`
+	HashedPassword     string             `bson:"hashed_password" json:"-"` // Never expose in JSON
+	Name               string             `bson:"name" json:"name"`
+	Phone              string             `bson:"phone,omitempty" json:"phone,omitempty"`
+	PreferredCourts    []string           `bson:"preferred_courts,omitempty" json:"preferred_courts,omitempty"`
+	PreferredDays      []string           `bson:"preferred_days,omitempty" json:"preferred_days,omitempty"`
+	PreferredTimes     []TimeRange        `bson:"preferred_times,omitempty" json:"preferred_times,omitempty"`
+	NotifyBy           []string           `bson:"notify_by,omitempty" json:"notify_by,omitempty"` // "email", "sms"
+	Role               string             `bson:"role,omitempty" json:"role,omitempty"`           // "admin" or empty for regular users
+	PasswordChangedAt  *time.Time         `bson:"password_changed_at,omitempty" json:"-"`         // Tokens issued before this are rejected
+	TwoFactorEnabled   bool               `bson:"two_factor_enabled,omitempty" json:"two_factor_enabled"`
+	TwoFactorSecret    string             `bson:"two_factor_secret,omitempty" json:"-"`         // Encrypted TOTP secret
+	TwoFactorPending   string             `bson:"two_factor_pending_secret,omitempty" json:"-"` // Encrypted secret awaiting its first code
+	TwoFactorLastStep  int64              `bson:"two_factor_last_step,omitempty" json:"-"`      // Last TOTP time step used, so codes can't be replayed
+	RecoveryCodeHashes []string           `bson:"recovery_code_hashes,omitempty" json:"-"`      // Hashed single-use backup codes
+	CreatedAt          time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt          time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
 // TimeRange represents a time range for preferred booking times
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -13,6 +15,7 @@ type Venue struct {
 	Provider         string             `bson:"provider" json:"provider"` // "lta", "courtsides", etc.
 	URL              string             `bson:"url" json:"url"`
 	Location         Location           `bson:"location" json:"location"`
+	Timezone         string             `bson:"timezone,omitempty" json:"timezone,omitempty"` // IANA zone slot times are listed in, e.g. "Europe/London"
 	Courts           []Court            `bson:"courts" json:"courts"`
 	BookingWindow    int                `bson:"booking_window" json:"booking_window"` // Days in advance booking is allowed
 	ScraperConfig    ScraperConfig      `bson:"scraper_config" json:"scraper_config"`
@@ -30,6 +33,34 @@ type Location struct {
 	PostCode  string  `bson:"post_code" json:"post_code"`
 	Latitude  float64 `bson:"latitude,omitempty" json:"latitude,omitempty"`
 	Longitude float64 `bson:"longitude,omitempty" json:"longitude,omitempty"`
+
+	// Point mirrors Latitude/Longitude as GeoJSON for the 2dsphere index; see SyncPoint
+	Point *GeoPoint `bson:"point,omitempty" json:"-"`
+}
+
+// GeoPoint is a GeoJSON point, the shape MongoDB's 2dsphere indexes expect
+type GeoPoint struct {
+	Type        string    `bson:"type" json:"type"`
+	Coordinates []float64 `bson:"coordinates" json:"coordinates"` // [longitude, latitude]
+}
+
+// NewGeoPoint creates a GeoJSON point; note GeoJSON puts longitude first
+func NewGeoPoint(lat, lng float64) *GeoPoint {
+	return &GeoPoint{Type: "Point", Coordinates: []float64{lng, lat}}
+}
+
+// HasCoordinates reports whether the location has been geocoded
+func (l Location) HasCoordinates() bool {
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
+// SyncPoint sets Point from Latitude/Longitude, clearing it when there are no coordinates
+func (l *Location) SyncPoint() {
+	if !l.HasCoordinates() {
+		l.Point = nil
+		return
+	}
+	l.Point = NewGeoPoint(l.Latitude, l.Longitude)
 }
 
 // Court represents a tennis court within a venue
@@ -41,23 +72,117 @@ type Court struct {
 	Floodlights bool     `bson:"floodlights,omitempty" json:"floodlights,omitempty"`
 	CourtType   string   `bson:"court_type,omitempty" json:"court_type,omitempty"` // "singles", "doubles"
 	Tags        []string `bson:"tags,omitempty" json:"tags,omitempty"`
+
+	// ScrapeIntervalMinutes overrides the venue's ScrapingInterval for this
+	// court, e.g. to check contested floodlit courts more often
+	ScrapeIntervalMinutes *int `bson:"scrape_interval_minutes,omitempty" json:"scrape_interval_minutes,omitempty"`
+}
+
+// Validate checks the venue's settings before it is saved
+func (v Venue) Validate() error {
+	if strings.TrimSpace(v.Name) == "" {
+		return fmt.Errorf("%w: venue name is required", ErrInvalidInput)
+	}
+	if v.BookingWindow <= 0 {
+		return fmt.Errorf("%w: booking window must be at least 1 day, got %d", ErrInvalidInput, v.BookingWindow)
+	}
+	if v.Location.Latitude < -90 || v.Location.Latitude > 90 || v.Location.Longitude < -180 || v.Location.Longitude > 180 {
+		return fmt.Errorf("%w: coordinates (%g, %g) are out of range", ErrInvalidInput, v.Location.Latitude, v.Location.Longitude)
+	}
+	for _, court := range v.Courts {
+		if court.ScrapeIntervalMinutes != nil && *court.ScrapeIntervalMinutes <= 0 {
+			return fmt.Errorf("%w: court %q scrape interval must be positive, got %d", ErrInvalidInput, court.ID, *court.ScrapeIntervalMinutes)
+		}
+	}
+	return v.ScraperConfig.Validate()
+}
+
+// WithinBookingWindow reports whether date (YYYY-MM-DD) can be booked today:
+// no earlier than now's date and at most window days after it. now should be
+// in the venue's timezone. A non-positive window is treated as unknown and
+// only rejects past dates.
+func WithinBookingWindow(date string, window int, now time.Time) bool {
+	day, err := time.ParseInLocation("2006-01-02", date, now.Location())
+	if err != nil {
+		return false
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if day.Before(today) {
+		return false
+	}
+	return window <= 0 || !day.After(today.AddDate(0, 0, window))
+}
+
+// ScrapeInterval returns the minutes between scrapes of court, which is the
+// court's own override when set and the venue's ScrapingInterval otherwise
+func (v Venue) ScrapeInterval(court Court) int {
+	if court.ScrapeIntervalMinutes != nil && *court.ScrapeIntervalMinutes > 0 {
+		return *court.ScrapeIntervalMinutes
+	}
+	return v.ScrapingInterval
 }
 
 // ScraperConfig represents configuration for scraping a venue
 type ScraperConfig struct {
-	Type               string                 `bson:"type" json:"type"`                                         // "clubspark", "courtsides", etc.
-	RequiresLogin      bool                   `bson:"requires_login" json:"requires_login"`                     // Whether login is required to scrape
-	CredentialKey      string                 `bson:"credential_key,omitempty" json:"credential_key,omitempty"` // Key to retrieve credentials from Vault
-	CustomParameters   map[string]interface{} `bson:"custom_parameters,omitempty" json:"custom_parameters,omitempty"`
+	Type               string                 `bson:"type" json:"type"`                                               // "clubspark", "courtsides", etc.
+	RequiresLogin      bool                   `bson:"requires_login" json:"requires_login"`                           // Whether login is required to scrape
+	CredentialKey      string                 `bson:"credential_key,omitempty" json:"credential_key,omitempty"`       // Secrets manager key for the login; defaults to the venue ID
+	CustomParameters   map[string]interface{} `bson:"custom_parameters,omitempty" json:"custom_parameters,omitempty"` // ClubSpark logins use login_url, username_selector, password_selector and submit_selector
 	SelectorMappings   map[string]string      `bson:"selector_mappings,omitempty" json:"selector_mappings,omitempty"`
 	NavigationSteps    []string               `bson:"navigation_steps,omitempty" json:"navigation_steps,omitempty"`
 	RetryCount         int                    `bson:"retry_count" json:"retry_count"`
 	TimeoutSeconds     int                    `bson:"timeout_seconds" json:"timeout_seconds"`
 	WaitAfterLoadMs    int                    `bson:"wait_after_load_ms" json:"wait_after_load_ms"`
-	UserAgent          string                 `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	UserAgent          string                 `bson:"user_agent,omitempty" json:"user_agent,omitempty"` // Defaults to one of a few common browser user agents, chosen per scrape
+	Headers            map[string]string      `bson:"headers,omitempty" json:"headers,omitempty"`       // Extra request headers, e.g. Referer or Accept-Language
 	UseHeadlessBrowser bool                   `bson:"use_headless_browser" json:"use_headless_browser"`
 }
 
+// Scraper types the scraper service has a scraper for
+const (
+	ScraperTypeClubSpark = "clubspark"
+	ScraperTypeCourtside = "courtside"
+	ScraperTypePlaytomic = "playtomic"
+)
+
+// Validate checks that the scraper service can scrape with this config: the
+// type has a scraper, and the parameters that scraper can't run without are set
+func (c ScraperConfig) Validate() error {
+	switch c.Type {
+	case ScraperTypeClubSpark:
+		// Logging in needs the sign-in page; the form selectors have defaults
+		if c.RequiresLogin && c.customString("login_url") == "" {
+			return fmt.Errorf("%w: clubspark venues that require login need custom_parameters.login_url", ErrInvalidInput)
+		}
+	case ScraperTypeCourtside:
+	case ScraperTypePlaytomic:
+		if c.customString("tenant_id") == "" {
+			return fmt.Errorf("%w: playtomic venues need custom_parameters.tenant_id", ErrInvalidInput)
+		}
+	case "":
+		return fmt.Errorf("%w: scraper type is required", ErrInvalidInput)
+	default:
+		return fmt.Errorf("%w: unknown scraper type %q, expected %s, %s or %s", ErrInvalidInput, c.Type, ScraperTypeClubSpark, ScraperTypeCourtside, ScraperTypePlaytomic)
+	}
+
+	for name, selector := range c.SelectorMappings {
+		if strings.TrimSpace(selector) == "" {
+			return fmt.Errorf("%w: selector %q is empty", ErrInvalidInput, name)
+		}
+	}
+	if c.RetryCount < 0 || c.TimeoutSeconds < 0 || c.WaitAfterLoadMs < 0 {
+		return fmt.Errorf("%w: retry count, timeout and wait after load must not be negative", ErrInvalidInput)
+	}
+	return nil
+}
+
+// customString returns a string custom parameter, or "" if it is missing or not a string
+func (c ScraperConfig) customString(key string) string {
+	value, _ := c.CustomParameters[key].(string)
+	return strings.TrimSpace(value)
+}
+
 // VenueService provides methods for interacting with venues
 type VenueService struct {
 	// Will be implemented later with MongoDB connection
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVenue_ScrapeInterval(t *testing.T) {
+	five, zero := 5, 0
+	venue := Venue{ScrapingInterval: 30}
+
+	assert.Equal(t, 30, venue.ScrapeInterval(Court{ID: "1"}))
+	assert.Equal(t, 5, venue.ScrapeInterval(Court{ID: "2", ScrapeIntervalMinutes: &five}))
+	assert.Equal(t, 30, venue.ScrapeInterval(Court{ID: "3", ScrapeIntervalMinutes: &zero}), "non-positive overrides are ignored")
+}
+
+func TestCourt_ScrapeIntervalOmittedWhenUnset(t *testing.T) {
+	data, err := json.Marshal(Court{ID: "1", Name: "Court 1"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "scrape_interval_minutes")
+}
+
+func TestVenue_Validate(t *testing.T) {
+	five, zero := 5, 0
+	scraper := ScraperConfig{Type: ScraperTypeCourtside}
+
+	assert.NoError(t, Venue{Name: "Victoria Park", BookingWindow: 7, ScraperConfig: scraper}.Validate())
+	assert.NoError(t, Venue{Name: "Victoria Park", BookingWindow: 7, ScraperConfig: scraper, Courts: []Court{{ID: "1", ScrapeIntervalMinutes: &five}}}.Validate())
+
+	for _, venue := range []Venue{
+		{Name: "Victoria Park", BookingWindow: 0, ScraperConfig: scraper},
+		{Name: "Victoria Park", BookingWindow: -3, ScraperConfig: scraper},
+		{Name: "Victoria Park", BookingWindow: 7, ScraperConfig: scraper, Courts: []Court{{ID: "1", ScrapeIntervalMinutes: &zero}}},
+		{Name: " ", BookingWindow: 7, ScraperConfig: scraper},
+		{Name: "Victoria Park", BookingWindow: 7},
+	} {
+		assert.ErrorIs(t, venue.Validate(), ErrInvalidInput)
+	}
+}
+
+func TestScraperConfig_Validate(t *testing.T) {
+	valid := []ScraperConfig{
+		{Type: ScraperTypeCourtside, SelectorMappings: map[string]string{"court_widget": ".court-widget"}},
+		{Type: ScraperTypeClubSpark},
+		{Type: ScraperTypeClubSpark, RequiresLogin: true, CustomParameters: map[string]interface{}{"login_url": "https://example.com/login"}},
+		{Type: ScraperTypePlaytomic, CustomParameters: map[string]interface{}{"tenant_id": "abc"}},
+	}
+	for _, config := range valid {
+		assert.NoError(t, config.Validate(), config.Type)
+	}
+
+	invalid := map[string]ScraperConfig{
+		"missing type":         {},
+		"unknown type":         {Type: "lta"},
+		"login without url":    {Type: ScraperTypeClubSpark, RequiresLogin: true},
+		"playtomic without id": {Type: ScraperTypePlaytomic, CustomParameters: map[string]interface{}{"tenant_id": 42}},
+		"empty selector":       {Type: ScraperTypeCourtside, SelectorMappings: map[string]string{"court_widget": " "}},
+		"negative timeout":     {Type: ScraperTypeCourtside, TimeoutSeconds: -1},
+	}
+	for name, config := range invalid {
+		assert.ErrorIs(t, config.Validate(), ErrInvalidInput, name)
+	}
+}
+
+func TestWithinBookingWindow(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+	now := time.Date(2024, 6, 10, 23, 30, 0, 0, london)
+
+	assert.True(t, WithinBookingWindow("2024-06-10", 7, now))
+	assert.True(t, WithinBookingWindow("2024-06-17", 7, now))
+	assert.False(t, WithinBookingWindow("2024-06-18", 7, now))
+	assert.False(t, WithinBookingWindow("2024-06-09", 7, now))
+	assert.False(t, WithinBookingWindow("not-a-date", 7, now))
+
+	// Without a configured window only past dates are rejected
+	assert.True(t, WithinBookingWindow("2025-01-01", 0, now))
+}
+
+func TestLocation_SyncPoint(t *testing.T) {
+	loc := Location{Latitude: 51.5362, Longitude: -0.0403}
+	loc.SyncPoint()
+	require.NotNil(t, loc.Point)
+	assert.Equal(t, "Point", loc.Point.Type)
+	assert.Equal(t, []float64{-0.0403, 51.5362}, loc.Point.Coordinates, "GeoJSON is longitude first")
+
+	loc = Location{Point: NewGeoPoint(1, 1)}
+	loc.SyncPoint()
+	assert.Nil(t, loc.Point, "a location without coordinates has no point")
+}
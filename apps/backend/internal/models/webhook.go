@@ -0,0 +1,69 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// nonPublicNets are ranges net.IP's own checks don't cover that still aren't
+// reachable on the public internet
+var nonPublicNets = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),     // "This" network
+	mustParseCIDR("100.64.0.0/10"), // Carrier-grade NAT, also used for some cloud metadata services
+	mustParseCIDR("192.0.0.0/24"),  // IETF protocol assignments
+	mustParseCIDR("198.18.0.0/15"), // Benchmarking
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return ipNet
+}
+
+// IsPublicIP reports whether ip is a public unicast address a webhook may be
+// delivered to. Loopback, private, link-local (which includes cloud metadata
+// endpoints such as 169.254.169.254), multicast and unspecified addresses
+// aren't.
+func IsPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, ipNet := range nonPublicNets {
+		if ipNet.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateWebhookURL checks that a webhook URL is https and doesn't name a
+// local or private host. Hostnames are only checked by name here; the
+// notification service checks the address they resolve to when it connects.
+func ValidateWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return errors.New("invalid URL")
+	}
+	if u.Scheme != "https" {
+		return errors.New("must be an https URL")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return errors.New("must include a host")
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("host %q is not allowed", host)
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return fmt.Errorf("address %s is not a public address", ip)
+	}
+
+	return nil
+}
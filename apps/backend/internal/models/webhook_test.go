@@ -0,0 +1,58 @@
+package models
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{url: "https://hooks.example.com/tennis"},
+		{url: "https://hooks.example.com:8443/tennis?key=abc"},
+		{url: "https://93.184.216.34/hook"},
+		{url: "http://hooks.example.com/tennis", wantErr: true},
+		{url: "ftp://hooks.example.com/tennis", wantErr: true},
+		{url: "hooks.example.com/tennis", wantErr: true},
+		{url: "https:///tennis", wantErr: true},
+		{url: "https://localhost/hook", wantErr: true},
+		{url: "https://LOCALHOST./hook", wantErr: true},
+		{url: "https://api.localhost/hook", wantErr: true},
+		{url: "https://127.0.0.1/hook", wantErr: true},
+		{url: "https://10.0.0.5/hook", wantErr: true},
+		{url: "https://192.168.1.10/hook", wantErr: true},
+		{url: "https://172.16.0.1/hook", wantErr: true},
+		{url: "https://169.254.169.254/latest/meta-data/", wantErr: true},
+		{url: "https://100.100.100.200/hook", wantErr: true},
+		{url: "https://0.0.0.0/hook", wantErr: true},
+		{url: "https://[::1]/hook", wantErr: true},
+		{url: "https://[fd00::1]/hook", wantErr: true},
+		{url: "https://[fe80::1]/hook", wantErr: true},
+		{url: "https://[::ffff:127.0.0.1]/hook", wantErr: true},
+		{url: "https://%zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := ValidateWebhookURL(tt.url)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	assert.True(t, IsPublicIP(net.ParseIP("93.184.216.34")))
+	assert.True(t, IsPublicIP(net.ParseIP("2606:2800:220:1:248:1893:25c8:1946")))
+	assert.False(t, IsPublicIP(net.ParseIP("127.0.0.53")))
+	assert.False(t, IsPublicIP(net.ParseIP("169.254.169.254")))
+	assert.False(t, IsPublicIP(net.ParseIP("224.0.0.1")))
+	assert.False(t, IsPublicIP(nil))
+}
@@ -2,11 +2,40 @@ package ratelimit
 
 import (
 	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
 	"time"
+
+	"tennis-booker/internal/auth"
+)
+
+// TrustedProxiesEnv is a comma-separated list of proxy IPs and CIDR ranges whose
+// forwarding headers are used to find the client IP
+const TrustedProxiesEnv = "RATE_LIMIT_TRUSTED_PROXIES"
+
+// ExemptRolesEnv is a comma-separated list of JWT roles exempt from the IP, user,
+// data and custom limits. Set it empty to exempt nobody.
+const ExemptRolesEnv = "RATE_LIMIT_EXEMPT_ROLES"
+
+// StoreEnv selects where counts are kept: "redis" (default) or "memory"
+const StoreEnv = "RATE_LIMIT_STORE"
+
+// Environment variables overriding the default limits, each as "<requests>/<window>", e.g. "100/1m"
+const (
+	IPLimitEnv        = "RATE_LIMIT_IP"
+	UserLimitEnv      = "RATE_LIMIT_USER"
+	AuthLimitEnv      = "RATE_LIMIT_AUTH"
+	DataLimitEnv      = "RATE_LIMIT_DATA"
+	SensitiveLimitEnv = "RATE_LIMIT_SENSITIVE"
 )
 
 // Config holds rate limiting configuration
 type Config struct {
+	// Where counts are kept (default: Redis)
+	Store StoreType `mapstructure:"store"`
+
 	// Redis connection settings
 	RedisAddr     string `mapstructure:"redis_addr"`
 	RedisPassword string `mapstructure:"redis_password"`
@@ -21,22 +50,65 @@ type Config struct {
 	DataEndpointLimit      RateLimit `mapstructure:"data_endpoint_limit"`
 	SensitiveEndpointLimit RateLimit `mapstructure:"sensitive_endpoint_limit"`
 
+	// Algorithm used to count requests against the limits (default: fixed window)
+	Algorithm Algorithm `mapstructure:"algorithm"`
+
 	// Rate limit headers
 	IncludeHeaders bool `mapstructure:"include_headers"`
 
-	// Trusted proxy settings for IP extraction
+	// Roles (from the JWT role claim) exempt from IP, user, data and custom limits.
+	// Needs the JWT middleware to run before the rate limit middleware.
+	ExemptRoles []string `mapstructure:"exempt_roles"`
+
+	// Trusted proxy settings for IP extraction. Entries are IPs or CIDR ranges.
 	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
+// StoreType selects where rate limit counts are kept
+type StoreType string
+
+const (
+	// StoreRedis keeps counts in Redis, shared by every server instance
+	StoreRedis StoreType = "redis"
+
+	// StoreMemory keeps counts in the process. Each instance enforces the limits
+	// on its own, so it suits single-node deployments and tests.
+	StoreMemory StoreType = "memory"
+)
+
+// Algorithm selects how requests are counted against a rate limit
+type Algorithm string
+
+const (
+	// AlgorithmFixedWindow counts requests in consecutive windows. A client can make
+	// up to twice the limit in quick succession across a window boundary.
+	AlgorithmFixedWindow Algorithm = "fixed_window"
+
+	// AlgorithmSlidingWindow counts requests in the window ending now, so the limit
+	// holds over any span of that length
+	AlgorithmSlidingWindow Algorithm = "sliding_window"
+
+	// AlgorithmTokenBucket gives each key a bucket of Capacity tokens that refills at
+	// RefillPerSecond. Clients can burst up to the capacity, then are throttled to
+	// the refill rate rather than blocked until a window resets.
+	AlgorithmTokenBucket Algorithm = "token_bucket"
+)
+
 // RateLimit defines a rate limit configuration
 type RateLimit struct {
 	Requests int           `mapstructure:"requests"`
 	Window   time.Duration `mapstructure:"window"`
+
+	// Token bucket settings. When unset they're derived from Requests and Window:
+	// a bucket of Requests tokens refilling at Requests per Window.
+	Capacity        int     `mapstructure:"capacity"`
+	RefillPerSecond float64 `mapstructure:"refill_per_second"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
+		Store:         StoreRedis,
 		RedisAddr:     "localhost:6379",
 		RedisPassword: "",
 		RedisDB:       0,
@@ -65,9 +137,15 @@ func DefaultConfig() *Config {
 			Window:   time.Minute,
 		},
 
+		// Fixed windows keep the existing behaviour
+		Algorithm: AlgorithmFixedWindow,
+
 		// Include rate limit headers in responses
 		IncludeHeaders: true,
 
+		// Admins aren't throttled while they work through the dashboard
+		ExemptRoles: []string{auth.RoleAdmin},
+
 		// Common trusted proxy headers
 		TrustedProxies: []string{
 			"127.0.0.1",
@@ -76,7 +154,168 @@ func DefaultConfig() *Config {
 	}
 }
 
+// ConfigFromEnv returns the default configuration with any overrides from the
+// environment applied
+func ConfigFromEnv() (*Config, error) {
+	config := DefaultConfig()
+
+	if value := os.Getenv(StoreEnv); value != "" {
+		store := StoreType(strings.ToLower(strings.TrimSpace(value)))
+		if store != StoreRedis && store != StoreMemory {
+			return nil, fmt.Errorf("invalid %s: %q is not redis or memory", StoreEnv, value)
+		}
+		config.Store = store
+	}
+
+	if value := os.Getenv(TrustedProxiesEnv); value != "" {
+		proxies, err := ParseTrustedProxies(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", TrustedProxiesEnv, err)
+		}
+		config.TrustedProxies = proxies
+	}
+
+	if value, ok := os.LookupEnv(ExemptRolesEnv); ok {
+		config.ExemptRoles = ParseExemptRoles(value)
+	}
+
+	limits := []struct {
+		env   string
+		limit *RateLimit
+	}{
+		{IPLimitEnv, &config.DefaultIPLimit},
+		{UserLimitEnv, &config.DefaultUserLimit},
+		{AuthLimitEnv, &config.AuthEndpointLimit},
+		{DataLimitEnv, &config.DataEndpointLimit},
+		{SensitiveLimitEnv, &config.SensitiveEndpointLimit},
+	}
+	for _, l := range limits {
+		if value := os.Getenv(l.env); value != "" {
+			limit, err := ParseRateLimit(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", l.env, err)
+			}
+			*l.limit = limit
+		}
+	}
+
+	return config, nil
+}
+
+// ParseRateLimit parses a limit written as "<requests>/<window>", such as "100/1m"
+func ParseRateLimit(value string) (RateLimit, error) {
+	requestsPart, windowPart, found := strings.Cut(strings.TrimSpace(value), "/")
+	if !found {
+		return RateLimit{}, fmt.Errorf("expected <requests>/<window>, got %q", value)
+	}
+
+	requests, err := strconv.Atoi(strings.TrimSpace(requestsPart))
+	if err != nil || requests <= 0 {
+		return RateLimit{}, fmt.Errorf("requests must be a positive number, got %q", requestsPart)
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(windowPart))
+	if err != nil || window <= 0 {
+		return RateLimit{}, fmt.Errorf("window must be a positive duration, got %q", windowPart)
+	}
+
+	return RateLimit{Requests: requests, Window: window}, nil
+}
+
+// ParseExemptRoles parses a comma-separated list of roles
+func ParseExemptRoles(value string) []string {
+	roles := []string{}
+	for _, role := range strings.Split(value, ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// ParseTrustedProxies parses a comma-separated list of IPs and CIDR ranges
+func ParseTrustedProxies(value string) ([]string, error) {
+	var proxies []string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, err := parseProxyEntry(entry); err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, entry)
+	}
+	return proxies, nil
+}
+
+// trustedProxySet is Config.TrustedProxies parsed into networks, so requests
+// don't have to parse the list again
+type trustedProxySet []*net.IPNet
+
+// parseTrustedProxySet parses each trusted proxy IP or CIDR range
+func parseTrustedProxySet(entries []string) (trustedProxySet, error) {
+	set := make(trustedProxySet, 0, len(entries))
+	for _, entry := range entries {
+		network, err := parseProxyEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, network)
+	}
+	return set, nil
+}
+
+// contains reports whether ip is one of the trusted proxies
+func (s trustedProxySet) contains(ip net.IP) bool {
+	for _, network := range s {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseProxyEntry parses a trusted proxy IP or CIDR range into a network. A
+// single IP becomes a network containing just that address.
+func parseProxyEntry(entry string) (*net.IPNet, error) {
+	if strings.Contains(entry, "/") {
+		_, cidr, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy CIDR %q", entry)
+		}
+		return cidr, nil
+	}
+
+	ip := net.ParseIP(entry)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid trusted proxy IP %q", entry)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
 // String returns a string representation of the rate limit
 func (rl RateLimit) String() string {
 	return fmt.Sprintf("%d requests per %v", rl.Requests, rl.Window)
 }
+
+// bucket returns the token bucket capacity and refill rate for the rate limit,
+// deriving any that aren't set from Requests and Window
+func (rl RateLimit) bucket() (capacity int, refillPerSecond float64, err error) {
+	capacity = rl.Capacity
+	if capacity <= 0 {
+		capacity = rl.Requests
+	}
+
+	refillPerSecond = rl.RefillPerSecond
+	if refillPerSecond <= 0 && rl.Window > 0 {
+		refillPerSecond = float64(rl.Requests) / rl.Window.Seconds()
+	}
+
+	if capacity <= 0 || refillPerSecond <= 0 {
+		return 0, 0, fmt.Errorf("token bucket needs a positive capacity and refill rate: %v", rl)
+	}
+	return capacity, refillPerSecond, nil
+}
@@ -2,27 +2,52 @@ package ratelimit
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
-
-	"github.com/redis/go-redis/v9"
-	"github.com/ulule/limiter/v3"
-	redisstore "github.com/ulule/limiter/v3/drivers/store/redis"
 )
 
-// Limiter wraps the ulule/limiter with our configuration
+// Limiter checks requests against the configured limits, keeping counts in a Store
 type Limiter struct {
-	config      *Config
-	redisClient *redis.Client
-	ipLimiter   *limiter.Limiter
-	userLimiter *limiter.Limiter
+	config *Config
+	store  Store
+
+	// config.TrustedProxies, parsed once here rather than on every request
+	trustedProxies trustedProxySet
+
+	// Swapped as a whole by UpdateLimits while requests are being checked
+	backends atomic.Pointer[backendSet]
+
+	// Requests let through without counting because of an exempt role
+	exemptRequests atomic.Int64
+}
+
+// backendSet holds a limiter for each of the configured limits
+type backendSet struct {
+	limits []RateLimit // What the limiters were created from, as returned by configuredLimits
+
+	ip   limitBackend
+	user limitBackend
 
 	// Endpoint-specific limiters
-	authLimiter      *limiter.Limiter
-	dataLimiter      *limiter.Limiter
-	sensitiveLimiter *limiter.Limiter
+	auth      limitBackend
+	data      limitBackend
+	sensitive limitBackend
+}
+
+// limitBackend counts requests for one rate limit using a particular algorithm
+type limitBackend interface {
+	Check(ctx context.Context, key string) (*LimitResult, error)
+	Peek(ctx context.Context, key string) (*LimitResult, error)
+	Reset(ctx context.Context, key string) error
 }
 
+// ErrInvalidLimitKey is returned by GetLimitState for keys that don't name a known limiter
+var ErrInvalidLimitKey = errors.New("invalid rate limit key")
+
 // LimitResult contains the result of a rate limit check
 type LimitResult struct {
 	Allowed    bool
@@ -32,125 +57,158 @@ type LimitResult struct {
 	RetryAfter time.Duration
 }
 
-// NewLimiter creates a new rate limiter with Redis backend
+// NewLimiter creates a rate limiter, keeping counts in the store selected by
+// config.Store: Redis unless it's StoreMemory
 func NewLimiter(config *Config) (*Limiter, error) {
-	// Create Redis client
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     config.RedisAddr,
-		Password: config.RedisPassword,
-		DB:       config.RedisDB,
-	})
-
-	// Test Redis connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	if err := validateAlgorithm(config.Algorithm); err != nil {
+		return nil, err
+	}
+	trustedProxies, err := parseTrustedProxySet(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := redisClient.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	store, err := newStore(config)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create Redis store for limiter
-	store, err := redisstore.NewStore(redisClient)
+	l, err := newLimiterWithStore(config, store, trustedProxies)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Redis store: %w", err)
+		store.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// newLimiterWithStore creates a rate limiter keeping counts in store
+func newLimiterWithStore(config *Config, store Store, trustedProxies trustedProxySet) (*Limiter, error) {
+	l := &Limiter{
+		config:         config,
+		store:          store,
+		trustedProxies: trustedProxies,
 	}
 
 	// Create limiters for different use cases
-	ipLimiter := limiter.New(store, limiter.Rate{
-		Period: config.DefaultIPLimit.Window,
-		Limit:  int64(config.DefaultIPLimit.Requests),
-	})
-
-	userLimiter := limiter.New(store, limiter.Rate{
-		Period: config.DefaultUserLimit.Window,
-		Limit:  int64(config.DefaultUserLimit.Requests),
-	})
-
-	authLimiter := limiter.New(store, limiter.Rate{
-		Period: config.AuthEndpointLimit.Window,
-		Limit:  int64(config.AuthEndpointLimit.Requests),
-	})
-
-	dataLimiter := limiter.New(store, limiter.Rate{
-		Period: config.DataEndpointLimit.Window,
-		Limit:  int64(config.DataEndpointLimit.Requests),
-	})
-
-	sensitiveLimiter := limiter.New(store, limiter.Rate{
-		Period: config.SensitiveEndpointLimit.Window,
-		Limit:  int64(config.SensitiveEndpointLimit.Requests),
-	})
-
-	return &Limiter{
-		config:           config,
-		redisClient:      redisClient,
-		ipLimiter:        ipLimiter,
-		userLimiter:      userLimiter,
-		authLimiter:      authLimiter,
-		dataLimiter:      dataLimiter,
-		sensitiveLimiter: sensitiveLimiter,
-	}, nil
+	backends, err := l.newBackendSet(config)
+	if err != nil {
+		return nil, err
+	}
+	l.backends.Store(backends)
+
+	return l, nil
+}
+
+// configuredLimits lists the IP, user and endpoint limits in config
+func configuredLimits(config *Config) []RateLimit {
+	return []RateLimit{
+		config.DefaultIPLimit,
+		config.DefaultUserLimit,
+		config.AuthEndpointLimit,
+		config.DataEndpointLimit,
+		config.SensitiveEndpointLimit,
+	}
+}
+
+// newBackendSet creates limiters for the limits in config
+func (l *Limiter) newBackendSet(config *Config) (*backendSet, error) {
+	set := backendSet{limits: configuredLimits(config)}
+	var err error
+	if set.ip, err = l.newBackend(config.DefaultIPLimit); err != nil {
+		return nil, err
+	}
+	if set.user, err = l.newBackend(config.DefaultUserLimit); err != nil {
+		return nil, err
+	}
+	if set.auth, err = l.newBackend(config.AuthEndpointLimit); err != nil {
+		return nil, err
+	}
+	if set.data, err = l.newBackend(config.DataEndpointLimit); err != nil {
+		return nil, err
+	}
+	if set.sensitive, err = l.newBackend(config.SensitiveEndpointLimit); err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+// UpdateLimits switches to the IP, user and endpoint limits in config without
+// interrupting requests, and reports whether any of them changed. Counts already
+// in Redis are kept. Other settings, such as the algorithm and trusted proxies,
+// only change on restart.
+func (l *Limiter) UpdateLimits(config *Config) (bool, error) {
+	if slices.Equal(l.backends.Load().limits, configuredLimits(config)) {
+		return false, nil
+	}
+
+	backends, err := l.newBackendSet(config)
+	if err != nil {
+		return false, err
+	}
+	l.backends.Store(backends)
+	return true, nil
+}
+
+// validateAlgorithm rejects algorithms the limiter doesn't implement
+func validateAlgorithm(algorithm Algorithm) error {
+	switch algorithm {
+	case "", AlgorithmFixedWindow, AlgorithmSlidingWindow, AlgorithmTokenBucket:
+		return nil
+	default:
+		return fmt.Errorf("unknown rate limit algorithm: %s", algorithm)
+	}
+}
+
+// newBackend creates a limiter for one rate limit using the configured algorithm
+func (l *Limiter) newBackend(rateLimit RateLimit) (limitBackend, error) {
+	return l.store.newBackend(l.config.Algorithm, rateLimit)
 }
 
 // CheckIPLimit checks rate limit for an IP address
 func (l *Limiter) CheckIPLimit(ctx context.Context, ip string) (*LimitResult, error) {
-	return l.checkLimit(ctx, l.ipLimiter, fmt.Sprintf("ip:%s", ip))
+	return l.checkLimit(ctx, l.backends.Load().ip, fmt.Sprintf("ip:%s", ip))
 }
 
 // CheckUserLimit checks rate limit for a user
 func (l *Limiter) CheckUserLimit(ctx context.Context, userID string) (*LimitResult, error) {
-	return l.checkLimit(ctx, l.userLimiter, fmt.Sprintf("user:%s", userID))
+	return l.checkLimit(ctx, l.backends.Load().user, fmt.Sprintf("user:%s", userID))
 }
 
 // CheckAuthLimit checks rate limit for authentication endpoints
 func (l *Limiter) CheckAuthLimit(ctx context.Context, identifier string) (*LimitResult, error) {
-	return l.checkLimit(ctx, l.authLimiter, fmt.Sprintf("auth:%s", identifier))
+	return l.checkLimit(ctx, l.backends.Load().auth, fmt.Sprintf("auth:%s", identifier))
 }
 
 // CheckDataLimit checks rate limit for data endpoints
 func (l *Limiter) CheckDataLimit(ctx context.Context, identifier string) (*LimitResult, error) {
-	return l.checkLimit(ctx, l.dataLimiter, fmt.Sprintf("data:%s", identifier))
+	return l.checkLimit(ctx, l.backends.Load().data, fmt.Sprintf("data:%s", identifier))
 }
 
 // CheckSensitiveLimit checks rate limit for sensitive endpoints
 func (l *Limiter) CheckSensitiveLimit(ctx context.Context, identifier string) (*LimitResult, error) {
-	return l.checkLimit(ctx, l.sensitiveLimiter, fmt.Sprintf("sensitive:%s", identifier))
+	return l.checkLimit(ctx, l.backends.Load().sensitive, fmt.Sprintf("sensitive:%s", identifier))
 }
 
 // CheckCustomLimit checks rate limit with custom configuration
 func (l *Limiter) CheckCustomLimit(ctx context.Context, identifier string, rateLimit RateLimit) (*LimitResult, error) {
-	// Create Redis store for custom limiter
-	store, err := redisstore.NewStore(l.redisClient)
+	customLimiter, err := l.newBackend(rateLimit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Redis store: %w", err)
+		return nil, err
 	}
 
-	customLimiter := limiter.New(store, limiter.Rate{
-		Period: rateLimit.Window,
-		Limit:  int64(rateLimit.Requests),
-	})
-
 	return l.checkLimit(ctx, customLimiter, identifier)
 }
 
 // checkLimit is a helper method that performs the actual rate limit check
-func (l *Limiter) checkLimit(ctx context.Context, lim *limiter.Limiter, key string) (*LimitResult, error) {
-	limitContext, err := lim.Get(ctx, key)
+func (l *Limiter) checkLimit(ctx context.Context, lim limitBackend, key string) (*LimitResult, error) {
+	result, err := lim.Check(ctx, key)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check rate limit: %w", err)
 	}
 
-	resetTime := time.Unix(limitContext.Reset, 0)
-	result := &LimitResult{
-		Allowed:   limitContext.Reached == false,
-		Limit:     limitContext.Limit,
-		Remaining: limitContext.Remaining,
-		ResetTime: resetTime,
-	}
-
 	// Calculate retry after duration if limit exceeded
 	if !result.Allowed {
-		result.RetryAfter = time.Until(resetTime)
+		result.RetryAfter = time.Until(result.ResetTime)
 		if result.RetryAfter < 0 {
 			result.RetryAfter = 0
 		}
@@ -161,48 +219,74 @@ func (l *Limiter) checkLimit(ctx context.Context, lim *limiter.Limiter, key stri
 
 // Reset resets the rate limit for a specific key
 func (l *Limiter) Reset(ctx context.Context, limiterType, identifier string) error {
-	var key string
-	var lim *limiter.Limiter
+	lim, err := l.backendFor(limiterType)
+	if err != nil {
+		return err
+	}
+
+	return lim.Reset(ctx, fmt.Sprintf("%s:%s", limiterType, identifier))
+}
 
+// GetLimitState returns the live state of a rate limit key, such as "ip:203.0.113.1"
+// or "user:<id>", without counting a request against it
+func (l *Limiter) GetLimitState(ctx context.Context, key string) (limit, remaining int, resetAt time.Time, err error) {
+	limiterType, identifier, found := strings.Cut(key, ":")
+	if !found || identifier == "" {
+		return 0, 0, time.Time{}, fmt.Errorf("%w: %q", ErrInvalidLimitKey, key)
+	}
+
+	lim, err := l.backendFor(limiterType)
+	if err != nil {
+		return 0, 0, time.Time{}, fmt.Errorf("%w: %v", ErrInvalidLimitKey, err)
+	}
+
+	result, err := lim.Peek(ctx, key)
+	if err != nil {
+		return 0, 0, time.Time{}, fmt.Errorf("failed to read rate limit state: %w", err)
+	}
+
+	return int(result.Limit), int(result.Remaining), result.ResetTime, nil
+}
+
+// backendFor returns the limiter for a limiter type ("ip", "user", "auth", "data", "sensitive")
+func (l *Limiter) backendFor(limiterType string) (limitBackend, error) {
+	backends := l.backends.Load()
 	switch limiterType {
 	case "ip":
-		key = fmt.Sprintf("ip:%s", identifier)
-		lim = l.ipLimiter
+		return backends.ip, nil
 	case "user":
-		key = fmt.Sprintf("user:%s", identifier)
-		lim = l.userLimiter
+		return backends.user, nil
 	case "auth":
-		key = fmt.Sprintf("auth:%s", identifier)
-		lim = l.authLimiter
+		return backends.auth, nil
 	case "data":
-		key = fmt.Sprintf("data:%s", identifier)
-		lim = l.dataLimiter
+		return backends.data, nil
 	case "sensitive":
-		key = fmt.Sprintf("sensitive:%s", identifier)
-		lim = l.sensitiveLimiter
+		return backends.sensitive, nil
 	default:
-		return fmt.Errorf("unknown limiter type: %s", limiterType)
+		return nil, fmt.Errorf("unknown limiter type: %s", limiterType)
 	}
+}
 
-	_, err := lim.Reset(ctx, key)
-	return err
+// ExemptRequests returns how many requests skipped rate limiting because of an exempt role
+func (l *Limiter) ExemptRequests() int64 {
+	return l.exemptRequests.Load()
 }
 
-// GetConfig returns the current configuration
+// GetConfig returns the configuration the limiter was created with
 func (l *Limiter) GetConfig() *Config {
 	return l.config
 }
 
-// Close closes the Redis connection
+// Close closes the store's connections
 func (l *Limiter) Close() error {
-	return l.redisClient.Close()
+	return l.store.Close()
 }
 
 // HealthCheck verifies that the rate limiter is working correctly
 func (l *Limiter) HealthCheck(ctx context.Context) error {
-	// Test Redis connection
-	if err := l.redisClient.Ping(ctx).Err(); err != nil {
-		return fmt.Errorf("Redis connection failed: %w", err)
+	// Test the store's connection
+	if err := l.store.Ping(ctx); err != nil {
+		return fmt.Errorf("rate limit store unavailable: %w", err)
 	}
 
 	// Test rate limiter functionality with a test key
@@ -0,0 +1,56 @@
+//go:build integration
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tennis-booker/internal/testenv"
+)
+
+func TestIntegration_LimiterAlgorithms(t *testing.T) {
+	algorithms := []Algorithm{AlgorithmFixedWindow, AlgorithmSlidingWindow, AlgorithmTokenBucket}
+
+	for _, algorithm := range algorithms {
+		t.Run(string(algorithm), func(t *testing.T) {
+			env, cleanup := testenv.SetupTestEnv(t)
+			defer cleanup()
+
+			config := DefaultConfig()
+			config.RedisAddr = env.RedisAddr
+			config.Algorithm = algorithm
+			config.DefaultIPLimit = RateLimit{Requests: 3, Window: time.Minute}
+
+			limiter, err := NewLimiter(config)
+			require.NoError(t, err)
+			defer limiter.Close()
+
+			ctx := context.Background()
+			for i := 0; i < 3; i++ {
+				result, err := limiter.CheckIPLimit(ctx, "192.168.1.100")
+				require.NoError(t, err)
+				assert.True(t, result.Allowed, "Request %d should be allowed", i+1)
+			}
+
+			result, err := limiter.CheckIPLimit(ctx, "192.168.1.100")
+			require.NoError(t, err)
+			assert.False(t, result.Allowed, "4th request should be denied")
+			assert.True(t, result.RetryAfter > 0, "RetryAfter should be set")
+
+			// Limits are counted per IP
+			result, err = limiter.CheckIPLimit(ctx, "192.168.1.101")
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+
+			require.NoError(t, limiter.Reset(ctx, "ip", "192.168.1.100"))
+			result, err = limiter.CheckIPLimit(ctx, "192.168.1.100")
+			require.NoError(t, err)
+			assert.True(t, result.Allowed, "Reset clears the count")
+		})
+	}
+}
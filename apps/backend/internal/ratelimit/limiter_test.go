@@ -2,6 +2,7 @@ package ratelimit
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -9,17 +10,36 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// newTestLimiter creates a limiter keeping counts in memory, so tests don't need Redis
+func newTestLimiter(t *testing.T, config *Config) *Limiter {
+	t.Helper()
+	config.Store = StoreMemory
+	limiter, err := NewLimiter(config)
+	require.NoError(t, err)
+	return limiter
+}
+
+// newTestLimiterWithClock creates an in-memory limiter whose time only moves
+// when the returned func advances it
+func newTestLimiterWithClock(t *testing.T, config *Config) (*Limiter, func(time.Duration)) {
+	t.Helper()
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	config.Store = StoreMemory
+	trustedProxies, err := parseTrustedProxySet(config.TrustedProxies)
+	require.NoError(t, err)
+	limiter, err := newLimiterWithStore(config, store, trustedProxies)
+	require.NoError(t, err)
+	return limiter, func(d time.Duration) { now = now.Add(d) }
+}
+
 // TestNewLimiter tests the creation of a new rate limiter
 func TestNewLimiter(t *testing.T) {
 	config := DefaultConfig()
 
-	// Note: This test requires Redis to be running
-	// In a real environment, you might want to use a test container
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	assert.NotNil(t, limiter)
@@ -30,15 +50,11 @@ func TestNewLimiter(t *testing.T) {
 func TestLimiterHealthCheck(t *testing.T) {
 	config := DefaultConfig()
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	ctx := context.Background()
-	err = limiter.HealthCheck(ctx)
+	err := limiter.HealthCheck(ctx)
 	assert.NoError(t, err)
 }
 
@@ -51,11 +67,7 @@ func TestIPRateLimit(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	ctx := context.Background()
@@ -87,11 +99,7 @@ func TestUserRateLimit(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	ctx := context.Background()
@@ -122,11 +130,7 @@ func TestAuthEndpointLimit(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	ctx := context.Background()
@@ -149,11 +153,7 @@ func TestAuthEndpointLimit(t *testing.T) {
 func TestCustomRateLimit(t *testing.T) {
 	config := DefaultConfig()
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	ctx := context.Background()
@@ -185,11 +185,7 @@ func TestRateLimitReset(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	ctx := context.Background()
@@ -215,6 +211,37 @@ func TestRateLimitReset(t *testing.T) {
 	assert.True(t, result.Allowed)
 }
 
+// TestUpdateLimits tests switching limits on a running limiter
+func TestUpdateLimits(t *testing.T) {
+	config := DefaultConfig()
+	config.DefaultIPLimit = RateLimit{Requests: 1, Window: time.Minute}
+
+	limiter := newTestLimiter(t, config)
+	defer limiter.Close()
+
+	ctx := context.Background()
+	ip := fmt.Sprintf("update-limits-%d", time.Now().UnixNano())
+
+	result, err := limiter.CheckIPLimit(ctx, ip)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Limit)
+
+	updated := DefaultConfig()
+	updated.DefaultIPLimit = RateLimit{Requests: 5, Window: time.Minute}
+	changed, err := limiter.UpdateLimits(updated)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	result, err = limiter.CheckIPLimit(ctx, ip)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(5), result.Limit)
+
+	changed, err = limiter.UpdateLimits(updated)
+	require.NoError(t, err)
+	assert.False(t, changed, "same limits again")
+}
+
 // TestDifferentEndpointLimits tests that different endpoints have different limits
 func TestDifferentEndpointLimits(t *testing.T) {
 	config := DefaultConfig()
@@ -222,11 +249,7 @@ func TestDifferentEndpointLimits(t *testing.T) {
 	config.DataEndpointLimit = RateLimit{Requests: 2, Window: time.Minute}
 	config.SensitiveEndpointLimit = RateLimit{Requests: 1, Window: time.Minute}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	ctx := context.Background()
@@ -259,11 +282,7 @@ func TestConcurrentRequests(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	ctx := context.Background()
@@ -303,6 +322,7 @@ func TestConcurrentRequests(t *testing.T) {
 func TestDefaultConfig(t *testing.T) {
 	config := DefaultConfig()
 
+	assert.Equal(t, StoreRedis, config.Store)
 	assert.Equal(t, "localhost:6379", config.RedisAddr)
 	assert.Equal(t, "", config.RedisPassword)
 	assert.Equal(t, 0, config.RedisDB)
@@ -317,6 +337,7 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, 200, config.DataEndpointLimit.Requests)
 	assert.Equal(t, 5, config.SensitiveEndpointLimit.Requests)
 
+	assert.Equal(t, AlgorithmFixedWindow, config.Algorithm)
 	assert.True(t, config.IncludeHeaders)
 	assert.Contains(t, config.TrustedProxies, "127.0.0.1")
 }
@@ -331,3 +352,220 @@ func TestRateLimitString(t *testing.T) {
 	expected := "100 requests per 1m0s"
 	assert.Equal(t, expected, rl.String())
 }
+
+// TestNewLimiter_UnknownAlgorithm tests that unsupported algorithms are rejected
+func TestNewLimiter_UnknownAlgorithm(t *testing.T) {
+	config := DefaultConfig()
+	config.Algorithm = "leaky_bucket"
+
+	limiter, err := NewLimiter(config)
+	assert.Nil(t, limiter)
+	assert.ErrorContains(t, err, "unknown rate limit algorithm")
+}
+
+// boundaryBurst makes one request, waits until late in the window, uses up the
+// rest of the limit, then bursts again just after the first window has ended.
+// It returns how many requests of the second burst were allowed.
+func boundaryBurst(t *testing.T, algorithm Algorithm) int {
+	config := DefaultConfig()
+	config.Algorithm = algorithm
+	config.DefaultIPLimit = RateLimit{
+		Requests: 3,
+		Window:   time.Second,
+	}
+
+	limiter, advance := newTestLimiterWithClock(t, config)
+	defer limiter.Close()
+
+	// Fixed windows are counted on the real clock, so it has to move as well
+	wait := func(d time.Duration) {
+		advance(d)
+		time.Sleep(d)
+	}
+
+	ctx := context.Background()
+	testIP := fmt.Sprintf("boundary-%s-%d", algorithm, time.Now().UnixNano())
+
+	result, err := limiter.CheckIPLimit(ctx, testIP)
+	require.NoError(t, err)
+	require.True(t, result.Allowed)
+
+	wait(700 * time.Millisecond)
+	for i := 0; i < 2; i++ {
+		result, err := limiter.CheckIPLimit(ctx, testIP)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	}
+
+	// Just past the end of the first window
+	wait(400 * time.Millisecond)
+	allowed := 0
+	for i := 0; i < 3; i++ {
+		result, err := limiter.CheckIPLimit(ctx, testIP)
+		require.NoError(t, err)
+		if result.Allowed {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+// TestFixedWindowBoundaryBurst documents the fixed window weakness: the full limit
+// is available again right after the boundary, so 5 requests land within ~0.5s
+func TestFixedWindowBoundaryBurst(t *testing.T) {
+	assert.Equal(t, 3, boundaryBurst(t, AlgorithmFixedWindow))
+}
+
+// TestSlidingWindowSmoothsBoundaryBurst tests that the sliding window still counts
+// the requests made late in the previous window
+func TestSlidingWindowSmoothsBoundaryBurst(t *testing.T) {
+	assert.Equal(t, 1, boundaryBurst(t, AlgorithmSlidingWindow))
+}
+
+// TestSlidingWindowLimit tests basic counting, headers data and reset with the sliding window
+func TestSlidingWindowLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.Algorithm = AlgorithmSlidingWindow
+	config.AuthEndpointLimit = RateLimit{
+		Requests: 3,
+		Window:   time.Minute,
+	}
+
+	limiter := newTestLimiter(t, config)
+	defer limiter.Close()
+
+	ctx := context.Background()
+	identifier := fmt.Sprintf("sliding-%d", time.Now().UnixNano())
+
+	for i := 0; i < 3; i++ {
+		result, err := limiter.CheckAuthLimit(ctx, identifier)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed, "Request %d should be allowed", i+1)
+		assert.Equal(t, int64(3), result.Limit)
+		assert.Equal(t, int64(3-i-1), result.Remaining)
+	}
+
+	result, err := limiter.CheckAuthLimit(ctx, identifier)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.True(t, result.RetryAfter > 55*time.Second, "RetryAfter should run until the first request leaves the window")
+
+	require.NoError(t, limiter.Reset(ctx, "auth", identifier))
+	result, err = limiter.CheckAuthLimit(ctx, identifier)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+}
+
+// TestRateLimitBucket tests how token bucket settings are derived
+func TestRateLimitBucket(t *testing.T) {
+	capacity, refill, err := RateLimit{Requests: 60, Window: time.Minute}.bucket()
+	require.NoError(t, err)
+	assert.Equal(t, 60, capacity)
+	assert.Equal(t, 1.0, refill)
+
+	capacity, refill, err = RateLimit{Requests: 60, Window: time.Minute, Capacity: 10, RefillPerSecond: 0.5}.bucket()
+	require.NoError(t, err)
+	assert.Equal(t, 10, capacity)
+	assert.Equal(t, 0.5, refill)
+
+	_, _, err = RateLimit{Capacity: 10}.bucket()
+	assert.Error(t, err)
+}
+
+// TestTokenBucketLimit tests bursting up to the capacity and refilling over time
+func TestTokenBucketLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.Algorithm = AlgorithmTokenBucket
+	config.DefaultIPLimit = RateLimit{
+		Capacity:        3,
+		RefillPerSecond: 5,
+	}
+
+	limiter, advance := newTestLimiterWithClock(t, config)
+	defer limiter.Close()
+
+	ctx := context.Background()
+	testIP := fmt.Sprintf("bucket-%d", time.Now().UnixNano())
+
+	// A full bucket allows a burst up to its capacity
+	for i := 0; i < 3; i++ {
+		result, err := limiter.CheckIPLimit(ctx, testIP)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed, "Request %d should be allowed", i+1)
+		assert.Equal(t, int64(3), result.Limit)
+		assert.Equal(t, int64(3-i-1), result.Remaining)
+	}
+
+	result, err := limiter.CheckIPLimit(ctx, testIP)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, int64(0), result.Remaining)
+	assert.True(t, result.RetryAfter > 0 && result.RetryAfter <= 200*time.Millisecond,
+		"RetryAfter should be the time until the next token, got %v", result.RetryAfter)
+
+	// At 5 tokens per second, one token arrives every 200ms
+	advance(250 * time.Millisecond)
+	result, err = limiter.CheckIPLimit(ctx, testIP)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = limiter.CheckIPLimit(ctx, testIP)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	require.NoError(t, limiter.Reset(ctx, "ip", testIP))
+	result, err = limiter.CheckIPLimit(ctx, testIP)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(2), result.Remaining)
+}
+
+// TestGetLimitState_InvalidKey tests that keys must name a known limiter
+func TestGetLimitState_InvalidKey(t *testing.T) {
+	limiter := &Limiter{config: DefaultConfig()}
+
+	for _, key := range []string{"", "ip", "ip:", "bogus:203.0.113.1"} {
+		_, _, _, err := limiter.GetLimitState(context.Background(), key)
+		assert.ErrorIs(t, err, ErrInvalidLimitKey, key)
+	}
+}
+
+// TestGetLimitState tests reading live state without consuming the limit, for each algorithm
+func TestGetLimitState(t *testing.T) {
+	for _, algorithm := range []Algorithm{AlgorithmFixedWindow, AlgorithmSlidingWindow, AlgorithmTokenBucket} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			config := DefaultConfig()
+			config.Algorithm = algorithm
+			config.DefaultIPLimit = RateLimit{
+				Requests: 5,
+				Window:   time.Minute,
+			}
+
+			limiter := newTestLimiter(t, config)
+			defer limiter.Close()
+
+			ctx := context.Background()
+			testIP := fmt.Sprintf("state-%s-%d", algorithm, time.Now().UnixNano())
+
+			limit, remaining, _, err := limiter.GetLimitState(ctx, "ip:"+testIP)
+			require.NoError(t, err)
+			assert.Equal(t, 5, limit)
+			assert.Equal(t, 5, remaining)
+
+			for i := 0; i < 2; i++ {
+				_, err := limiter.CheckIPLimit(ctx, testIP)
+				require.NoError(t, err)
+			}
+
+			// Reading the state twice doesn't use anything up
+			for i := 0; i < 2; i++ {
+				limit, remaining, resetAt, err := limiter.GetLimitState(ctx, "ip:"+testIP)
+				require.NoError(t, err)
+				assert.Equal(t, 5, limit)
+				assert.Equal(t, 3, remaining)
+				assert.True(t, resetAt.After(time.Now()))
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ulule/limiter/v3"
+	memorystore "github.com/ulule/limiter/v3/drivers/store/memory"
+)
+
+// memorySweepInterval is how often MemoryStore drops counts that have expired,
+// the job Redis key expiry does for RedisStore
+const memorySweepInterval = time.Minute
+
+// MemoryStore keeps counts in the process, using the same algorithms as
+// RedisStore. Limits aren't shared between instances, so it's for single-node
+// deployments and tests, which don't need Redis running.
+type MemoryStore struct {
+	fixedWindows limiter.Store // ulule/limiter's memory store, which expires its own counts
+
+	mu        sync.Mutex
+	logs      map[string]*memoryLog    // Sliding window request logs
+	buckets   map[string]*memoryBucket // Token buckets
+	lastSweep time.Time
+
+	// now is swapped out in tests to control time. The fixed window store
+	// always uses the real clock.
+	now func() time.Time
+}
+
+// memoryLog holds the times of the requests allowed in the sliding window
+type memoryLog struct {
+	requests  []time.Time
+	expiresAt time.Time
+}
+
+// memoryBucket is a token bucket's fractional token count as of updatedAt
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	expiresAt time.Time // When the bucket is full again and can be forgotten
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		fixedWindows: memorystore.NewStore(),
+		logs:         make(map[string]*memoryLog),
+		buckets:      make(map[string]*memoryBucket),
+		now:          time.Now,
+	}
+}
+
+func (s *MemoryStore) newBackend(algorithm Algorithm, rateLimit RateLimit) (limitBackend, error) {
+	switch algorithm {
+	case AlgorithmSlidingWindow:
+		return &memorySlidingWindowBackend{store: s, limit: rateLimit}, nil
+	case AlgorithmTokenBucket:
+		capacity, refillPerSecond, err := rateLimit.bucket()
+		if err != nil {
+			return nil, err
+		}
+		return &memoryTokenBucketBackend{store: s, capacity: capacity, refillPerSecond: refillPerSecond}, nil
+	}
+	return &fixedWindowBackend{
+		limiter: limiter.New(s.fixedWindows, limiter.Rate{
+			Period: rateLimit.Window,
+			Limit:  int64(rateLimit.Requests),
+		}),
+	}, nil
+}
+
+// Ping always succeeds, since there's nothing to connect to
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close forgets the sliding window and token bucket counts. Fixed window
+// counts are left to expire in the ulule/limiter store.
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	clear(s.logs)
+	clear(s.buckets)
+	return nil
+}
+
+// lock locks the store and returns the current time, first dropping expired
+// counts if it's been a while. Callers unlock s.mu.
+func (s *MemoryStore) lock() time.Time {
+	s.mu.Lock()
+	now := s.now()
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return now
+	}
+	s.lastSweep = now
+
+	for key, log := range s.logs {
+		if !now.Before(log.expiresAt) {
+			delete(s.logs, key)
+		}
+	}
+	for key, bucket := range s.buckets {
+		if !now.Before(bucket.expiresAt) {
+			delete(s.buckets, key)
+		}
+	}
+	return now
+}
+
+// memorySlidingWindowBackend keeps a log of request times per key and counts
+// the ones inside the window ending now, like slidingWindowBackend
+type memorySlidingWindowBackend struct {
+	store *MemoryStore
+	limit RateLimit
+}
+
+// Check records a request if the window has room for it
+func (m *memorySlidingWindowBackend) Check(ctx context.Context, key string) (*LimitResult, error) {
+	now := m.store.lock()
+	defer m.store.mu.Unlock()
+
+	log := m.store.logs[key]
+	if log == nil {
+		log = &memoryLog{}
+		m.store.logs[key] = log
+	}
+	log.requests = m.inWindow(log.requests, now)
+
+	allowed := len(log.requests) < m.limit.Requests
+	if allowed {
+		log.requests = append(log.requests, now)
+	}
+	log.expiresAt = now.Add(m.limit.Window)
+
+	return m.result(allowed, log.requests, now), nil
+}
+
+// Peek counts the requests in the window ending now without recording one
+func (m *memorySlidingWindowBackend) Peek(ctx context.Context, key string) (*LimitResult, error) {
+	now := m.store.lock()
+	defer m.store.mu.Unlock()
+
+	var requests []time.Time
+	if log := m.store.logs[key]; log != nil {
+		requests = m.inWindow(log.requests, now)
+	}
+	return m.result(len(requests) < m.limit.Requests, requests, now), nil
+}
+
+// inWindow drops the requests that have left the window ending now
+func (m *memorySlidingWindowBackend) inWindow(requests []time.Time, now time.Time) []time.Time {
+	start := now.Add(-m.limit.Window)
+	for len(requests) > 0 && !requests[0].After(start) {
+		requests = requests[1:]
+	}
+	return requests
+}
+
+// result describes the window holding requests. The next request is freed up
+// when the oldest one in the window expires.
+func (m *memorySlidingWindowBackend) result(allowed bool, requests []time.Time, now time.Time) *LimitResult {
+	limit := int64(m.limit.Requests)
+	oldest := now
+	if len(requests) > 0 {
+		oldest = requests[0]
+	}
+	return &LimitResult{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: max(0, limit-int64(len(requests))),
+		ResetTime: oldest.Add(m.limit.Window),
+	}
+}
+
+// Reset clears the request log for the key
+func (m *memorySlidingWindowBackend) Reset(ctx context.Context, key string) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+	delete(m.store.logs, key)
+	return nil
+}
+
+// memoryTokenBucketBackend gives each key a bucket of capacity tokens that
+// refills continuously, like tokenBucketBackend
+type memoryTokenBucketBackend struct {
+	store           *MemoryStore
+	capacity        int
+	refillPerSecond float64
+}
+
+// Check takes a token from the key's bucket if one is available. ResetTime is
+// when the next token arrives for a rejected request, or when the bucket will
+// be full again otherwise.
+func (b *memoryTokenBucketBackend) Check(ctx context.Context, key string) (*LimitResult, error) {
+	now := b.store.lock()
+	defer b.store.mu.Unlock()
+
+	tokens := b.tokens(b.store.buckets[key], now)
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	untilFull := b.duration(float64(b.capacity) - tokens)
+	b.store.buckets[key] = &memoryBucket{tokens: tokens, updatedAt: now, expiresAt: now.Add(untilFull)}
+
+	wait := untilFull
+	if !allowed {
+		wait = b.duration(1 - tokens)
+	}
+	return &LimitResult{
+		Allowed:   allowed,
+		Limit:     int64(b.capacity),
+		Remaining: int64(math.Floor(tokens)),
+		ResetTime: now.Add(wait),
+	}, nil
+}
+
+// Peek returns the tokens in the key's bucket without taking one
+func (b *memoryTokenBucketBackend) Peek(ctx context.Context, key string) (*LimitResult, error) {
+	now := b.store.lock()
+	defer b.store.mu.Unlock()
+
+	tokens := b.tokens(b.store.buckets[key], now)
+	return &LimitResult{
+		Allowed:   tokens >= 1,
+		Limit:     int64(b.capacity),
+		Remaining: int64(math.Floor(tokens)),
+		ResetTime: now.Add(b.duration(float64(b.capacity) - tokens)),
+	}, nil
+}
+
+// tokens returns the bucket's tokens refilled up to now; a missing bucket is full
+func (b *memoryTokenBucketBackend) tokens(bucket *memoryBucket, now time.Time) float64 {
+	if bucket == nil {
+		return float64(b.capacity)
+	}
+	elapsed := math.Max(0, now.Sub(bucket.updatedAt).Seconds())
+	return math.Min(float64(b.capacity), bucket.tokens+elapsed*b.refillPerSecond)
+}
+
+// duration returns how long the bucket takes to refill the tokens
+func (b *memoryTokenBucketBackend) duration(tokens float64) time.Duration {
+	return time.Duration(math.Ceil(tokens / b.refillPerSecond * float64(time.Second)))
+}
+
+// Reset refills the key's bucket
+func (b *memoryTokenBucketBackend) Reset(ctx context.Context, key string) error {
+	b.store.mu.Lock()
+	defer b.store.mu.Unlock()
+	delete(b.store.buckets, key)
+	return nil
+}
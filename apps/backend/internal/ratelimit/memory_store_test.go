@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewLimiter_UnknownStore tests that unsupported stores are rejected
+func TestNewLimiter_UnknownStore(t *testing.T) {
+	config := DefaultConfig()
+	config.Store = "memcached"
+
+	limiter, err := NewLimiter(config)
+	assert.Nil(t, limiter)
+	assert.ErrorContains(t, err, "unknown rate limit store")
+}
+
+// TestMemoryStore_FixedWindowResets tests that the window starts with the
+// first request and the full limit is available once it ends. Fixed windows
+// are counted by ulule/limiter on the real clock, so the window is short.
+func TestMemoryStore_FixedWindowResets(t *testing.T) {
+	config := DefaultConfig()
+	config.DefaultIPLimit = RateLimit{Requests: 2, Window: 200 * time.Millisecond}
+	limiter := newTestLimiter(t, config)
+	defer limiter.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		result, err := limiter.CheckIPLimit(ctx, "203.0.113.1")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+
+	result, err := limiter.CheckIPLimit(ctx, "203.0.113.1")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, int64(0), result.Remaining)
+
+	time.Sleep(250 * time.Millisecond)
+	result, err = limiter.CheckIPLimit(ctx, "203.0.113.1")
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, int64(1), result.Remaining)
+}
+
+// TestMemoryStore_SweepsExpiredCounts tests that counts are forgotten once
+// they've expired, so the store doesn't grow with every client ever seen.
+// Fixed window counts are swept by ulule/limiter's store itself.
+func TestMemoryStore_SweepsExpiredCounts(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for _, algorithm := range []Algorithm{AlgorithmSlidingWindow, AlgorithmTokenBucket} {
+		backend, err := store.newBackend(algorithm, RateLimit{Requests: 5, Window: time.Minute})
+		require.NoError(t, err)
+		_, err = backend.Check(ctx, "ip:203.0.113.1")
+		require.NoError(t, err)
+	}
+	assert.Len(t, store.logs, 1)
+	assert.Len(t, store.buckets, 1)
+
+	now = now.Add(2 * memorySweepInterval)
+	store.lock()
+	store.mu.Unlock()
+
+	assert.Empty(t, store.logs)
+	assert.Empty(t, store.buckets)
+}
@@ -1,8 +1,8 @@
 package ratelimit
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"net"
 	"net/http"
 	"strconv"
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"tennis-booker/internal/auth"
+	"tennis-booker/internal/logging"
 )
 
 // RateLimitEvent represents a rate limiting event for logging
@@ -27,17 +28,30 @@ type RateLimitEvent struct {
 	UserAgent    string    `json:"user_agent,omitempty"`
 }
 
+// rateLimitLogger records rate limiting decisions; entries carry the request ID when there is one
+var rateLimitLogger = logging.New("tennis-server")
+
 // logRateLimitEvent logs rate limiting events for monitoring
-func logRateLimitEvent(event RateLimitEvent) {
+func logRateLimitEvent(ctx context.Context, event RateLimitEvent) {
+	fields := map[string]interface{}{
+		"ip":            event.IP,
+		"user_id":       event.UserID,
+		"endpoint":      event.Endpoint,
+		"method":        event.Method,
+		"limit_type":    event.LimitType,
+		"requests_made": event.RequestsMade,
+		"limit":         event.Limit,
+		"window":        event.Window,
+	}
+
 	if event.Blocked {
-		log.Printf("[RATE_LIMIT_BLOCKED] IP=%s UserID=%s Endpoint=%s Method=%s Type=%s Requests=%d/%d Window=%s UserAgent=%s",
-			event.IP, event.UserID, event.Endpoint, event.Method, event.LimitType,
-			event.RequestsMade, event.Limit, event.Window, event.UserAgent)
+		fields["user_agent"] = event.UserAgent
+		rateLimitLogger.WithContext(ctx).Warn("Rate limit exceeded", fields)
 	} else {
-		// Only log successful requests in debug mode to avoid spam
-		// In production, you might want to send this to a metrics system instead
-		log.Printf("[RATE_LIMIT_DEBUG] IP=%s UserID=%s Endpoint=%s Requests=%d/%d Remaining=%d",
-			event.IP, event.UserID, event.Endpoint, event.RequestsMade, event.Limit, event.Limit-event.RequestsMade)
+		// Only logged at debug level to avoid spam; in production, you might want
+		// to send this to a metrics system instead
+		fields["remaining"] = event.Limit - event.RequestsMade
+		rateLimitLogger.WithContext(ctx).Debug("Rate limit checked", fields)
 	}
 }
 
@@ -45,15 +59,22 @@ func logRateLimitEvent(event RateLimitEvent) {
 func IPRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limiter.exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Extract client IP address
-			clientIP := extractClientIP(r, limiter.config.TrustedProxies)
+			clientIP := extractClientIP(r, limiter.trustedProxies)
 
 			// Check rate limit for this IP
 			result, err := limiter.CheckIPLimit(r.Context(), clientIP)
 			if err != nil {
 				// Log error but don't block request on rate limiter failure
 				// In production, you might want to fail open or closed based on your security requirements
-				log.Printf("[RATE_LIMIT_ERROR] IP=%s Endpoint=%s Error=%v", clientIP, r.URL.Path, err)
+				rateLimitLogger.WithContext(r.Context()).Error("Rate limit check failed", map[string]interface{}{
+					"ip": clientIP, "endpoint": r.URL.Path, "error": err.Error(),
+				})
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
@@ -71,7 +92,7 @@ func IPRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handler {
 				Blocked:      !result.Allowed,
 				UserAgent:    r.Header.Get("User-Agent"),
 			}
-			logRateLimitEvent(event)
+			logRateLimitEvent(r.Context(), event)
 
 			// Add rate limit headers if configured
 			if limiter.config.IncludeHeaders {
@@ -99,7 +120,7 @@ func AuthRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract client IP address for auth rate limiting
-			clientIP := extractClientIP(r, limiter.config.TrustedProxies)
+			clientIP := extractClientIP(r, limiter.trustedProxies)
 
 			// Check auth-specific rate limit for this IP
 			result, err := limiter.CheckAuthLimit(r.Context(), clientIP)
@@ -129,8 +150,13 @@ func AuthRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handler {
 func DataRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limiter.exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Extract client IP address
-			clientIP := extractClientIP(r, limiter.config.TrustedProxies)
+			clientIP := extractClientIP(r, limiter.trustedProxies)
 
 			// Check data-specific rate limit for this IP
 			result, err := limiter.CheckDataLimit(r.Context(), clientIP)
@@ -161,7 +187,7 @@ func SensitiveRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Hand
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract client IP address
-			clientIP := extractClientIP(r, limiter.config.TrustedProxies)
+			clientIP := extractClientIP(r, limiter.trustedProxies)
 
 			// Check sensitive-specific rate limit for this IP
 			result, err := limiter.CheckSensitiveLimit(r.Context(), clientIP)
@@ -191,8 +217,13 @@ func SensitiveRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Hand
 func CustomRateLimitMiddleware(limiter *Limiter, rateLimit RateLimit, keyPrefix string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limiter.exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Extract client IP address
-			clientIP := extractClientIP(r, limiter.config.TrustedProxies)
+			clientIP := extractClientIP(r, limiter.trustedProxies)
 			identifier := fmt.Sprintf("%s:%s", keyPrefix, clientIP)
 
 			// Check custom rate limit
@@ -224,12 +255,17 @@ func CustomRateLimitMiddleware(limiter *Limiter, rateLimit RateLimit, keyPrefix
 func UserRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limiter.exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Try to extract user ID from JWT context
 			userID, err := auth.GetUserIDFromContext(r.Context())
 			if err != nil {
 				// No user context found - this could be an unauthenticated request
 				// or middleware applied in wrong order. Fall back to IP-based limiting.
-				clientIP := extractClientIP(r, limiter.config.TrustedProxies)
+				clientIP := extractClientIP(r, limiter.trustedProxies)
 				result, err := limiter.CheckIPLimit(r.Context(), clientIP)
 				if err != nil {
 					http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -255,13 +291,15 @@ func UserRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handler {
 			// Check user-specific rate limit
 			result, err := limiter.CheckUserLimit(r.Context(), userID)
 			if err != nil {
-				log.Printf("[RATE_LIMIT_ERROR] UserID=%s Endpoint=%s Error=%v", userID, r.URL.Path, err)
+				rateLimitLogger.WithContext(r.Context()).Error("Rate limit check failed", map[string]interface{}{
+					"user_id": userID, "endpoint": r.URL.Path, "error": err.Error(),
+				})
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
 
 			// Log rate limit event
-			clientIP := extractClientIP(r, limiter.config.TrustedProxies)
+			clientIP := extractClientIP(r, limiter.trustedProxies)
 			event := RateLimitEvent{
 				Timestamp:    time.Now(),
 				IP:           clientIP,
@@ -275,7 +313,7 @@ func UserRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handler {
 				Blocked:      !result.Allowed,
 				UserAgent:    r.Header.Get("User-Agent"),
 			}
-			logRateLimitEvent(event)
+			logRateLimitEvent(r.Context(), event)
 
 			// Add rate limit headers if configured
 			if limiter.config.IncludeHeaders {
@@ -300,8 +338,13 @@ func UserRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handler {
 func CombinedRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if limiter.exempt(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			// Always check IP-based rate limit first
-			clientIP := extractClientIP(r, limiter.config.TrustedProxies)
+			clientIP := extractClientIP(r, limiter.trustedProxies)
 			ipResult, err := limiter.CheckIPLimit(r.Context(), clientIP)
 			if err != nil {
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -373,7 +416,7 @@ func UserAuthRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handl
 
 			if err != nil {
 				// No user context, fall back to IP-based auth limiting
-				clientIP := extractClientIP(r, limiter.config.TrustedProxies)
+				clientIP := extractClientIP(r, limiter.trustedProxies)
 				identifier = clientIP
 			} else {
 				// Use user ID for more specific limiting
@@ -404,50 +447,75 @@ func UserAuthRateLimitMiddleware(limiter *Limiter) func(http.Handler) http.Handl
 	}
 }
 
-// extractClientIP extracts the real client IP address from the request
-// It checks various headers in order of preference and validates against trusted proxies
-func extractClientIP(r *http.Request, trustedProxies []string) string {
-	// Helper function to check if IP is in trusted proxies
-	isTrustedProxy := func(ip string) bool {
-		for _, trusted := range trustedProxies {
-			if ip == trusted {
-				return true
-			}
-			// Check if it's a CIDR range
-			if strings.Contains(trusted, "/") {
-				_, cidr, err := net.ParseCIDR(trusted)
-				if err == nil {
-					if parsedIP := net.ParseIP(ip); parsedIP != nil {
-						if cidr.Contains(parsedIP) {
-							return true
-						}
-					}
-				}
-			}
-		}
+// exempt reports whether the request comes from a user with an exempt role. It's
+// checked before Redis, so exempt traffic doesn't use up anyone's limit, but it is
+// still counted and logged for monitoring.
+func (l *Limiter) exempt(r *http.Request) bool {
+	if len(l.config.ExemptRoles) == 0 {
 		return false
 	}
 
+	claims, err := auth.GetUserClaimsFromContext(r.Context())
+	if err != nil || claims.Role == "" {
+		return false
+	}
+
+	for _, role := range l.config.ExemptRoles {
+		if claims.Role == role {
+			l.exemptRequests.Add(1)
+			rateLimitLogger.WithContext(r.Context()).Debug("Rate limit exempt", map[string]interface{}{
+				"user_id": claims.UserID, "role": claims.Role, "endpoint": r.URL.Path, "method": r.Method,
+			})
+			return true
+		}
+	}
+	return false
+}
+
+// extractClientIP extracts the real client IP address from the request. The
+// forwarding headers can be set by anyone, so they're only used when the
+// request comes straight from a trusted proxy; otherwise it's RemoteAddr.
+func extractClientIP(r *http.Request, trustedProxies trustedProxySet) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		// If SplitHostPort fails, RemoteAddr might not have a port
+		host = r.RemoteAddr
+	}
+	remoteIP := net.ParseIP(host)
+	if remoteIP == nil {
+		// Last resort: return a default IP if parsing fails
+		return "unknown"
+	}
+	if !trustedProxies.contains(remoteIP) {
+		return host
+	}
+
+	isTrustedProxy := func(ip string) bool {
+		return trustedProxies.contains(net.ParseIP(ip))
+	}
+
 	// Check X-Forwarded-For header (most common for load balancers)
 	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		// X-Forwarded-For can contain multiple IPs: "client, proxy1, proxy2"
-		ips := strings.Split(xff, ",")
-
-		// Start from the leftmost IP (original client) and work right
-		for i := 0; i < len(ips); i++ {
-			ip := strings.TrimSpace(ips[i])
-			if ip != "" && net.ParseIP(ip) != nil {
-				// If this is not a trusted proxy, it's likely the real client IP
-				if !isTrustedProxy(ip) {
-					return ip
-				}
-				// If it is a trusted proxy and we're at the leftmost position,
-				// this might be the client IP in a trusted proxy setup
-				if i == 0 {
-					return ip
-				}
+		// X-Forwarded-For can contain multiple IPs: "client, proxy1, proxy2". Each
+		// proxy appends the address it received the request from, so walk from the
+		// right past our own proxies; the first untrusted IP is the client.
+		var ips []string
+		for _, ip := range strings.Split(xff, ",") {
+			if ip = strings.TrimSpace(ip); net.ParseIP(ip) != nil {
+				ips = append(ips, ip)
+			}
+		}
+
+		for i := len(ips) - 1; i >= 0; i-- {
+			if !isTrustedProxy(ips[i]) {
+				return ips[i]
 			}
 		}
+
+		// Every hop is a trusted proxy, so the leftmost is the origin
+		if len(ips) > 0 {
+			return ips[0]
+		}
 	}
 
 	// Check X-Real-IP header (used by nginx and others)
@@ -471,20 +539,8 @@ func extractClientIP(r *http.Request, trustedProxies []string) string {
 		}
 	}
 
-	// Fall back to RemoteAddr
-	host, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		// If SplitHostPort fails, RemoteAddr might not have a port
-		host = r.RemoteAddr
-	}
-
-	// Validate and return the IP
-	if ip := net.ParseIP(host); ip != nil {
-		return host
-	}
-
-	// Last resort: return a default IP if parsing fails
-	return "unknown"
+	// No usable header, so the proxy itself is the client
+	return host
 }
 
 // addRateLimitHeaders adds standard rate limiting headers to the response
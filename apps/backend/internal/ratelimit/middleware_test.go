@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"tennis-booker/internal/auth"
 )
 
@@ -44,6 +46,24 @@ func TestExtractClientIP(t *testing.T) {
 			trustedProxies: []string{"10.0.0.1", "198.51.100.1"},
 			expectedIP:     "203.0.113.1",
 		},
+		{
+			name:       "X-Forwarded-For skips trusted CIDR ranges from the right",
+			remoteAddr: "10.0.0.5:12345",
+			headers: map[string]string{
+				"X-Forwarded-For": "198.51.100.7, 203.0.113.9, 173.245.48.10, 10.0.0.5",
+			},
+			trustedProxies: []string{"173.245.48.0/20", "10.0.0.0/8"},
+			expectedIP:     "203.0.113.9",
+		},
+		{
+			name:       "X-Forwarded-For all trusted uses leftmost",
+			remoteAddr: "10.0.0.5:12345",
+			headers: map[string]string{
+				"X-Forwarded-For": "10.1.1.1, 10.0.0.5",
+			},
+			trustedProxies: []string{"10.0.0.0/8"},
+			expectedIP:     "10.1.1.1",
+		},
 		{
 			name:       "X-Real-IP header",
 			remoteAddr: "10.0.0.1:12345",
@@ -90,6 +110,23 @@ func TestExtractClientIP(t *testing.T) {
 			headers:    map[string]string{},
 			expectedIP: "192.168.1.100",
 		},
+		{
+			name:       "Headers from an untrusted client are ignored",
+			remoteAddr: "198.51.100.20:12345",
+			headers: map[string]string{
+				"X-Forwarded-For":  "203.0.113.1",
+				"X-Real-IP":        "203.0.113.2",
+				"X-Client-IP":      "203.0.113.3",
+				"CF-Connecting-IP": "203.0.113.4",
+			},
+			expectedIP: "198.51.100.20",
+		},
+		{
+			name:       "Trusted proxy without forwarding headers",
+			remoteAddr: "10.0.0.1:12345",
+			headers:    map[string]string{},
+			expectedIP: "10.0.0.1",
+		},
 		{
 			name:       "Invalid IP in header falls back to RemoteAddr",
 			remoteAddr: "192.168.1.100:12345",
@@ -110,10 +147,12 @@ func TestExtractClientIP(t *testing.T) {
 			}
 
 			if tt.trustedProxies == nil {
-				tt.trustedProxies = []string{"127.0.0.1", "::1"}
+				tt.trustedProxies = []string{"127.0.0.1", "::1", "10.0.0.1"}
 			}
+			trustedProxies, err := parseTrustedProxySet(tt.trustedProxies)
+			require.NoError(t, err)
 
-			result := extractClientIP(req, tt.trustedProxies)
+			result := extractClientIP(req, trustedProxies)
 			assert.Equal(t, tt.expectedIP, result)
 		})
 	}
@@ -127,11 +166,7 @@ func TestIPRateLimitMiddleware(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := IPRateLimitMiddleware(limiter)
@@ -186,11 +221,7 @@ func TestAuthRateLimitMiddleware(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := AuthRateLimitMiddleware(limiter)
@@ -235,11 +266,7 @@ func TestDataRateLimitMiddleware(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := DataRateLimitMiddleware(limiter)
@@ -284,11 +311,7 @@ func TestSensitiveRateLimitMiddleware(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := SensitiveRateLimitMiddleware(limiter)
@@ -327,11 +350,7 @@ func TestSensitiveRateLimitMiddleware(t *testing.T) {
 func TestCustomRateLimitMiddleware(t *testing.T) {
 	config := DefaultConfig()
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	customLimit := RateLimit{
@@ -381,11 +400,7 @@ func TestMiddlewareWithDifferentIPs(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := IPRateLimitMiddleware(limiter)
@@ -431,16 +446,13 @@ func TestMiddlewareWithDifferentIPs(t *testing.T) {
 // TestMiddlewareWithXForwardedFor tests IP extraction from X-Forwarded-For header
 func TestMiddlewareWithXForwardedFor(t *testing.T) {
 	config := DefaultConfig()
+	config.TrustedProxies = []string{"10.0.0.0/8"}
 	config.DefaultIPLimit = RateLimit{
 		Requests: 1,
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := IPRateLimitMiddleware(limiter)
@@ -483,11 +495,7 @@ func TestRateLimitHeaders(t *testing.T) {
 	}
 	config.IncludeHeaders = true
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := IPRateLimitMiddleware(limiter)
@@ -514,7 +522,7 @@ func TestRateLimitHeaders(t *testing.T) {
 
 	// Verify reset time is a valid Unix timestamp
 	resetTime := w.Header().Get("X-RateLimit-Reset")
-	_, err = strconv.ParseInt(resetTime, 10, 64)
+	_, err := strconv.ParseInt(resetTime, 10, 64)
 	assert.NoError(t, err, "Reset time should be a valid Unix timestamp")
 
 	// Verify reset time is in RFC3339 format
@@ -532,11 +540,7 @@ func TestMiddlewareWithoutHeaders(t *testing.T) {
 	}
 	config.IncludeHeaders = false
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := IPRateLimitMiddleware(limiter)
@@ -570,11 +574,7 @@ func TestUserRateLimitMiddleware(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := UserRateLimitMiddleware(limiter)
@@ -636,11 +636,7 @@ func TestUserRateLimitMiddleware_FallbackToIP(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := UserRateLimitMiddleware(limiter)
@@ -690,11 +686,7 @@ func TestCombinedRateLimitMiddleware(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := CombinedRateLimitMiddleware(limiter)
@@ -755,11 +747,7 @@ func TestCombinedRateLimitMiddleware_IPLimitFirst(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := CombinedRateLimitMiddleware(limiter)
@@ -816,11 +804,7 @@ func TestUserAuthRateLimitMiddleware(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := UserAuthRateLimitMiddleware(limiter)
@@ -873,11 +857,7 @@ func TestUserAuthRateLimitMiddleware_NoUserContext(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := UserAuthRateLimitMiddleware(limiter)
@@ -923,11 +903,7 @@ func TestUserRateLimitMiddleware_DifferentUsers(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := UserRateLimitMiddleware(limiter)
@@ -994,11 +970,7 @@ func TestCombinedRateLimitMiddleware_UnauthenticatedUser(t *testing.T) {
 		Window:   time.Minute,
 	}
 
-	limiter, err := NewLimiter(config)
-	if err != nil {
-		t.Skipf("Skipping test - Redis not available: %v", err)
-		return
-	}
+	limiter := newTestLimiter(t, config)
 	defer limiter.Close()
 
 	middleware := CombinedRateLimitMiddleware(limiter)
@@ -1035,3 +1007,176 @@ func TestCombinedRateLimitMiddleware_UnauthenticatedUser(t *testing.T) {
 	assert.Equal(t, http.StatusTooManyRequests, w.Code)
 	assert.Contains(t, w.Body.String(), "Too many requests from IP")
 }
+
+// TestParseTrustedProxies tests parsing the trusted proxy list from its env format
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies(" 173.245.48.0/20, 10.0.0.1,,2400:cb00::/32 ")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"173.245.48.0/20", "10.0.0.1", "2400:cb00::/32"}, proxies)
+
+	_, err = ParseTrustedProxies("10.0.0.1, not-an-ip")
+	assert.Error(t, err)
+
+	_, err = ParseTrustedProxies("10.0.0.0/33")
+	assert.Error(t, err)
+}
+
+// TestConfigFromEnv tests loading trusted proxies from the environment
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(TrustedProxiesEnv, "")
+	config, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig().TrustedProxies, config.TrustedProxies)
+
+	t.Setenv(TrustedProxiesEnv, "173.245.48.0/20,127.0.0.1")
+	config, err = ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"173.245.48.0/20", "127.0.0.1"}, config.TrustedProxies)
+
+	t.Setenv(TrustedProxiesEnv, "garbage")
+	_, err = ConfigFromEnv()
+	assert.ErrorContains(t, err, TrustedProxiesEnv)
+}
+
+// TestConfigFromEnv_Limits tests overriding limits from the environment
+func TestConfigFromEnv_Limits(t *testing.T) {
+	t.Setenv(TrustedProxiesEnv, "")
+	t.Setenv(IPLimitEnv, "250/1m")
+	t.Setenv(AuthLimitEnv, " 3 / 30s ")
+	config, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, RateLimit{Requests: 250, Window: time.Minute}, config.DefaultIPLimit)
+	assert.Equal(t, RateLimit{Requests: 3, Window: 30 * time.Second}, config.AuthEndpointLimit)
+	assert.Equal(t, DefaultConfig().DataEndpointLimit, config.DataEndpointLimit)
+
+	for _, value := range []string{"100", "0/1m", "100/soon", "100/-1m"} {
+		t.Setenv(IPLimitEnv, value)
+		_, err = ConfigFromEnv()
+		assert.ErrorContains(t, err, IPLimitEnv, value)
+	}
+}
+
+// TestConfigFromEnv_Store tests selecting the store from the environment
+func TestConfigFromEnv_Store(t *testing.T) {
+	t.Setenv(TrustedProxiesEnv, "")
+	t.Setenv(StoreEnv, "")
+	config, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, StoreRedis, config.Store)
+
+	t.Setenv(StoreEnv, " Memory ")
+	config, err = ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, StoreMemory, config.Store)
+
+	t.Setenv(StoreEnv, "memcached")
+	_, err = ConfigFromEnv()
+	assert.ErrorContains(t, err, StoreEnv)
+}
+
+// TestNewLimiter_InvalidTrustedProxy tests that a bad proxy entry fails fast
+func TestNewLimiter_InvalidTrustedProxy(t *testing.T) {
+	config := DefaultConfig()
+	config.TrustedProxies = []string{"10.0.0.0/99"}
+
+	_, err := NewLimiter(config)
+	assert.ErrorContains(t, err, "invalid trusted proxy")
+}
+
+// TestAdminExemptFromIPLimit tests that an admin keeps going after a regular user
+// from the same IP has been rate limited, and isn't counted against the limit
+func TestAdminExemptFromIPLimit(t *testing.T) {
+	config := DefaultConfig()
+	config.ExemptRoles = []string{auth.RoleAdmin}
+	config.DefaultIPLimit = RateLimit{
+		Requests: 2,
+		Window:   time.Minute,
+	}
+
+	limiter := newTestLimiter(t, config)
+	defer limiter.Close()
+
+	handler := IPRateLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	remoteAddr := fmt.Sprintf("10.%d.%d.1:12345", time.Now().UnixNano()%250, time.Now().Unix()%250)
+	request := func(claims *auth.AppClaims) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/system/health", nil)
+		req.RemoteAddr = remoteAddr
+		req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	user := &auth.AppClaims{UserID: "user-1", Username: "user"}
+	admin := &auth.AppClaims{UserID: "admin-1", Username: "admin", Role: auth.RoleAdmin}
+
+	// Admin requests don't use up the IP's limit
+	for i := 0; i < 5; i++ {
+		w := request(admin)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+
+	assert.Equal(t, http.StatusOK, request(user).Code)
+	assert.Equal(t, http.StatusOK, request(user).Code)
+	assert.Equal(t, http.StatusTooManyRequests, request(user).Code)
+
+	// The regular user is blocked but the admin on the same IP isn't
+	assert.Equal(t, http.StatusOK, request(admin).Code)
+	assert.Equal(t, int64(6), limiter.ExemptRequests())
+}
+
+// TestExemptRoles tests the exemption check itself, which never touches Redis
+func TestExemptRoles(t *testing.T) {
+	limiter := &Limiter{config: DefaultConfig()}
+	admin := &auth.AppClaims{UserID: "admin-1", Role: auth.RoleAdmin}
+
+	withClaims := func(claims *auth.AppClaims) *http.Request {
+		req := httptest.NewRequest("GET", "/api/system/health", nil)
+		if claims != nil {
+			req = req.WithContext(auth.SetUserClaimsInContext(req.Context(), claims))
+		}
+		return req
+	}
+
+	// Admins are exempt by default
+	assert.True(t, limiter.exempt(withClaims(admin)))
+	assert.False(t, limiter.exempt(withClaims(&auth.AppClaims{UserID: "user-1"})))
+	assert.False(t, limiter.exempt(withClaims(nil)))
+	assert.Equal(t, int64(1), limiter.ExemptRequests())
+
+	// Exempt requests reach the handler without any limiter backend being used
+	var reached bool
+	handler := UserRateLimitMiddleware(limiter)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), withClaims(admin))
+	assert.True(t, reached)
+
+	limiter.config.ExemptRoles = nil
+	assert.False(t, limiter.exempt(withClaims(admin)))
+}
+
+// TestConfigFromEnv_ExemptRoles tests setting the exempt roles from the environment
+func TestConfigFromEnv_ExemptRoles(t *testing.T) {
+	t.Setenv(TrustedProxiesEnv, "")
+	t.Setenv(ExemptRolesEnv, "") // Restored after the test
+	os.Unsetenv(ExemptRolesEnv)
+	config, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{auth.RoleAdmin}, config.ExemptRoles)
+
+	t.Setenv(ExemptRolesEnv, " admin, support ,,")
+	config, err = ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admin", "support"}, config.ExemptRoles)
+
+	// Set but empty exempts nobody
+	t.Setenv(ExemptRolesEnv, "")
+	config, err = ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Empty(t, config.ExemptRoles)
+}
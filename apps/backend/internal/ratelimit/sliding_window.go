@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// slidingWindowKeyPrefix namespaces the sorted sets that hold sliding window logs
+const slidingWindowKeyPrefix = "ratelimit:sliding:"
+
+// slidingWindowScript atomically drops timestamps that have left the window, counts
+// what remains and, if there is room, records the new request. Rejected requests
+// aren't recorded, so a client that keeps retrying isn't locked out indefinitely.
+//
+// KEYS[1]: sorted set of request timestamps (ms)
+// ARGV: now (ms), window (ms), limit, unique member for this request
+// Returns: {allowed (0/1), requests in window, oldest timestamp in window (ms)}
+var slidingWindowScript = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local oldest = now
+local first = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if first[2] then
+	oldest = tonumber(first[2])
+end
+
+return {allowed, count, oldest}
+`)
+
+// slidingWindowBackend keeps a log of request timestamps per key in a Redis sorted
+// set and counts the ones inside the window ending now. Unlike fixed windows, a
+// client can't fit twice the limit into a short burst across a window boundary.
+type slidingWindowBackend struct {
+	client *redis.Client
+	limit  RateLimit
+}
+
+// newSlidingWindowBackend creates a sliding window limiter for one rate limit
+func newSlidingWindowBackend(client *redis.Client, limit RateLimit) *slidingWindowBackend {
+	return &slidingWindowBackend{
+		client: client,
+		limit:  limit,
+	}
+}
+
+// Check records a request if the window has room for it
+func (s *slidingWindowBackend) Check(ctx context.Context, key string) (*LimitResult, error) {
+	member, err := requestID()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	window := s.limit.Window.Milliseconds()
+
+	values, err := slidingWindowScript.Run(ctx, s.client, []string{slidingWindowKeyPrefix + key},
+		now, window, s.limit.Requests, member).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("unexpected sliding window script result: %v", values)
+	}
+
+	limit := int64(s.limit.Requests)
+	remaining := limit - values[1]
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	// The next request is freed up when the oldest one in the window expires
+	return &LimitResult{
+		Allowed:   values[0] == 1,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetTime: time.UnixMilli(values[2] + window),
+	}, nil
+}
+
+// Peek counts the requests in the window ending now without recording one
+func (s *slidingWindowBackend) Peek(ctx context.Context, key string) (*LimitResult, error) {
+	now := time.Now().UnixMilli()
+	window := s.limit.Window.Milliseconds()
+	inWindow := &redis.ZRangeBy{
+		Min:   fmt.Sprintf("(%d", now-window),
+		Max:   "+inf",
+		Count: 1,
+	}
+
+	oldest, err := s.client.ZRangeByScoreWithScores(ctx, slidingWindowKeyPrefix+key, inWindow).Result()
+	if err != nil {
+		return nil, err
+	}
+	count, err := s.client.ZCount(ctx, slidingWindowKeyPrefix+key, inWindow.Min, inWindow.Max).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(s.limit.Requests)
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	resetTime := time.UnixMilli(now)
+	if len(oldest) > 0 {
+		resetTime = time.UnixMilli(int64(oldest[0].Score) + window)
+	}
+
+	return &LimitResult{
+		Allowed:   remaining > 0,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetTime: resetTime,
+	}, nil
+}
+
+// Reset clears the request log for the key
+func (s *slidingWindowBackend) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, slidingWindowKeyPrefix+key).Err()
+}
+
+// requestID returns a unique sorted set member, since requests in the same
+// millisecond would otherwise overwrite each other
+func requestID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate request ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketKeyPrefix namespaces the hashes that hold token bucket state
+const tokenBucketKeyPrefix = "ratelimit:bucket:"
+
+// tokenBucketScript atomically refills a bucket for the time since it was last
+// touched and takes a token if one is available. Token counts are fractional and
+// stored as strings, since Lua numbers returned to Redis are truncated to integers.
+//
+// KEYS[1]: hash with fields tokens and ts (ms)
+// ARGV: capacity, refill rate (tokens per ms), now (ms)
+// Returns: {allowed (0/1), whole tokens left, ms until the next token (rejected)
+// or until the bucket is full again (allowed)}
+var tokenBucketScript = redis.NewScript(`
+local key = KEYS[1]
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', key, 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+-- Another instance's clock may be ahead; never refill for negative time
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * rate)
+	ts = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', key, 'tokens', tostring(tokens), 'ts', tostring(ts))
+
+-- A full bucket is the same as no bucket, so let the key expire once it refills
+local untilFull = math.ceil((capacity - tokens) / rate)
+redis.call('PEXPIRE', key, untilFull + 1000)
+
+local wait = untilFull
+if allowed == 0 then
+	wait = math.ceil((1 - tokens) / rate)
+end
+
+return {allowed, math.floor(tokens), wait}
+`)
+
+// tokenBucketBackend gives each key a bucket that holds up to capacity tokens and
+// refills continuously. Each request takes a token, so clients can burst up to the
+// capacity and are then smoothly throttled to the refill rate.
+type tokenBucketBackend struct {
+	client          *redis.Client
+	capacity        int
+	refillPerSecond float64
+}
+
+// newTokenBucketBackend creates a token bucket limiter for one rate limit
+func newTokenBucketBackend(client *redis.Client, rateLimit RateLimit) (*tokenBucketBackend, error) {
+	capacity, refillPerSecond, err := rateLimit.bucket()
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenBucketBackend{
+		client:          client,
+		capacity:        capacity,
+		refillPerSecond: refillPerSecond,
+	}, nil
+}
+
+// Check takes a token from the key's bucket if one is available. Remaining is the
+// number of whole tokens left. ResetTime is when the next token arrives for a
+// rejected request, or when the bucket will be full again otherwise.
+func (b *tokenBucketBackend) Check(ctx context.Context, key string) (*LimitResult, error) {
+	now := time.Now()
+	refillPerMs := b.refillPerSecond / 1000
+
+	values, err := tokenBucketScript.Run(ctx, b.client, []string{tokenBucketKeyPrefix + key},
+		b.capacity, refillPerMs, now.UnixMilli()).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 3 {
+		return nil, fmt.Errorf("unexpected token bucket script result: %v", values)
+	}
+
+	return &LimitResult{
+		Allowed:   values[0] == 1,
+		Limit:     int64(b.capacity),
+		Remaining: values[1],
+		ResetTime: now.Add(time.Duration(values[2]) * time.Millisecond),
+	}, nil
+}
+
+// Peek returns the tokens in the key's bucket without taking one
+func (b *tokenBucketBackend) Peek(ctx context.Context, key string) (*LimitResult, error) {
+	now := time.Now()
+	tokens := float64(b.capacity)
+
+	state, err := b.client.HMGet(ctx, tokenBucketKeyPrefix+key, "tokens", "ts").Result()
+	if err != nil {
+		return nil, err
+	}
+	if stored, ok := state[0].(string); ok {
+		if ts, ok := state[1].(string); ok {
+			storedTokens, err1 := strconv.ParseFloat(stored, 64)
+			storedAt, err2 := strconv.ParseFloat(ts, 64)
+			if err1 == nil && err2 == nil {
+				elapsed := math.Max(0, float64(now.UnixMilli())-storedAt) / 1000
+				tokens = math.Min(float64(b.capacity), storedTokens+elapsed*b.refillPerSecond)
+			}
+		}
+	}
+
+	untilFull := (float64(b.capacity) - tokens) / b.refillPerSecond
+	return &LimitResult{
+		Allowed:   tokens >= 1,
+		Limit:     int64(b.capacity),
+		Remaining: int64(math.Floor(tokens)),
+		ResetTime: now.Add(time.Duration(untilFull * float64(time.Second))),
+	}, nil
+}
+
+// Reset refills the key's bucket
+func (b *tokenBucketBackend) Reset(ctx context.Context, key string) error {
+	return b.client.Del(ctx, tokenBucketKeyPrefix+key).Err()
+}
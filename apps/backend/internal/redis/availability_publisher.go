@@ -0,0 +1,49 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"tennis-booker/internal/models"
+)
+
+// SlotQueue is the durable list the notification service works through.
+// The scraper publishes to it under the same name.
+const SlotQueue = "court_slots"
+
+// AvailabilityPublisher publishes court availability events to every Redis
+// destination that consumes them
+type AvailabilityPublisher struct {
+	redisClient *redis.Client
+}
+
+// NewAvailabilityPublisher creates a new availability publisher
+func NewAvailabilityPublisher(redisClient *redis.Client) *AvailabilityPublisher {
+	return &AvailabilityPublisher{redisClient: redisClient}
+}
+
+// PublishAvailability pushes the event onto SlotQueue for the notification
+// service and publishes it on AvailabilityChannel for live subscribers. Both
+// writes go in one MULTI/EXEC, so neither consumer sees an event the other
+// missed. Events without a schema version are stamped with the current one.
+func (p *AvailabilityPublisher) PublishAvailability(ctx context.Context, event *models.CourtAvailabilityEvent) error {
+	if event.SchemaVersion == 0 {
+		event.SchemaVersion = models.CourtAvailabilitySchemaVersion
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	pipe := p.redisClient.TxPipeline()
+	pipe.LPush(ctx, SlotQueue, payload)
+	pipe.Publish(ctx, AvailabilityChannel, payload)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to publish availability event: %w", err)
+	}
+	return nil
+}
@@ -2,7 +2,6 @@ package redis
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -16,9 +15,13 @@ import (
 	"tennis-booker/internal/models"
 )
 
+// AvailabilityChannel is the Redis pub/sub channel court availability events are published on
+const AvailabilityChannel = "court:availability"
+
 // EventPublisher publishes court availability events to Redis
 type EventPublisher struct {
 	redisClient *redis.Client
+	publisher   *AvailabilityPublisher
 	db          *mongo.Database
 	logger      *log.Logger
 	channel     string
@@ -28,9 +31,10 @@ type EventPublisher struct {
 func NewEventPublisher(redisClient *redis.Client, db *mongo.Database, logger *log.Logger) *EventPublisher {
 	return &EventPublisher{
 		redisClient: redisClient,
+		publisher:   NewAvailabilityPublisher(redisClient),
 		db:          db,
 		logger:      logger,
-		channel:     "court:availability",
+		channel:     AvailabilityChannel,
 	}
 }
 
@@ -128,14 +132,8 @@ func (p *EventPublisher) StartScrapingLogListener(ctx context.Context) error {
 
 // PublishManualAvailabilityEvent publishes a manually created availability event
 func (p *EventPublisher) PublishManualAvailabilityEvent(ctx context.Context, event *models.CourtAvailabilityEvent) error {
-	eventJSON, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
-	}
-
-	err = p.redisClient.Publish(ctx, p.channel, eventJSON).Err()
-	if err != nil {
-		return fmt.Errorf("failed to publish to Redis: %w", err)
+	if err := p.publisher.PublishAvailability(ctx, event); err != nil {
+		return err
 	}
 
 	p.logger.Printf("Published manual court availability event: %s at %s %s-%s",
@@ -0,0 +1,68 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+
+	"tennis-booker/internal/models"
+)
+
+// subscriptionBuffer is how many events a subscription holds for a slow reader
+const subscriptionBuffer = 64
+
+// AvailabilitySubscriber receives the court availability events EventPublisher publishes
+type AvailabilitySubscriber struct {
+	redisClient *redis.Client
+	channel     string
+}
+
+// NewAvailabilitySubscriber creates a new availability subscriber
+func NewAvailabilitySubscriber(redisClient *redis.Client) *AvailabilitySubscriber {
+	return &AvailabilitySubscriber{
+		redisClient: redisClient,
+		channel:     AvailabilityChannel,
+	}
+}
+
+// SubscribeAvailability subscribes to availability events until ctx is done,
+// when the Redis subscription is released and the returned channel closed.
+// Older events are migrated to the current schema; messages that aren't
+// valid events, or have a schema version this build can't decode, are skipped.
+func (s *AvailabilitySubscriber) SubscribeAvailability(ctx context.Context) (<-chan models.CourtAvailabilityEvent, error) {
+	pubsub := s.redisClient.Subscribe(ctx, s.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", s.channel, err)
+	}
+
+	events := make(chan models.CourtAvailabilityEvent, subscriptionBuffer)
+	go func() {
+		defer close(events)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				event, err := models.DecodeCourtAvailabilityEvent([]byte(message.Payload))
+				if err != nil {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
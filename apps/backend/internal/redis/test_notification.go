@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/models"
+)
+
+// Keys shared with the notification service's test notification worker
+const (
+	TestNotificationQueue       = "notifications:test:requests"
+	TestNotificationReplyPrefix = "notifications:test:reply:" // + request ID
+)
+
+// TestNotificationClient sends test notifications through the notification service over Redis
+type TestNotificationClient struct {
+	redisClient *redis.Client
+}
+
+// NewTestNotificationClient creates a new test notification client
+func NewTestNotificationClient(redisClient *redis.Client) *TestNotificationClient {
+	return &TestNotificationClient{redisClient: redisClient}
+}
+
+// SendTestNotification asks the notification service to send a sample alert to
+// the user's address for channel and waits for its reply. Requests the service
+// gets to after the backend has stopped waiting are dropped, so a busy or
+// stopped service returns models.ErrTestNotificationTimeout rather than a late alert.
+func (c *TestNotificationClient) SendTestNotification(ctx context.Context, userID, channel string) (*models.TestNotificationResult, error) {
+	req := models.TestNotificationRequest{
+		ID:        primitive.NewObjectID().Hex(),
+		UserID:    userID,
+		Channel:   channel,
+		ExpiresAt: time.Now().Add(models.TestNotificationTimeout).Unix(),
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode test notification request: %w", err)
+	}
+	if err := c.redisClient.RPush(ctx, TestNotificationQueue, payload).Err(); err != nil {
+		return nil, fmt.Errorf("failed to queue test notification: %w", err)
+	}
+
+	reply, err := c.redisClient.BLPop(ctx, models.TestNotificationTimeout, TestNotificationReplyPrefix+req.ID).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, models.ErrTestNotificationTimeout
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for test notification: %w", err)
+	}
+
+	var result models.TestNotificationResult
+	if err := json.Unmarshal([]byte(reply[1]), &result); err != nil {
+		return nil, fmt.Errorf("failed to decode test notification reply: %w", err)
+	}
+	return &result, nil
+}
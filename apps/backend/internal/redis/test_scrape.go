@@ -0,0 +1,65 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"tennis-booker/internal/models"
+)
+
+// Keys shared with the scraper service's test scrape worker
+const (
+	TestScrapeQueue       = "scraper:test_scrape:requests"
+	TestScrapeReplyPrefix = "scraper:test_scrape:reply:" // + request ID
+)
+
+// testScrapeGrace is how much longer than the scrape's own timeout to wait,
+// for the scraper to pick the request up and send the reply
+const testScrapeGrace = 5 * time.Second
+
+// TestScrapeClient runs test scrapes on the scraper service over Redis
+type TestScrapeClient struct {
+	redisClient *redis.Client
+}
+
+// NewTestScrapeClient creates a new test scrape client
+func NewTestScrapeClient(redisClient *redis.Client) *TestScrapeClient {
+	return &TestScrapeClient{redisClient: redisClient}
+}
+
+// TestScrape queues req for the scraper and waits for its reply. The scraper
+// drops requests it gets to after the backend has stopped waiting, so a busy
+// or stopped scraper returns models.ErrTestScrapeTimeout rather than a late scrape.
+func (c *TestScrapeClient) TestScrape(ctx context.Context, req models.TestScrapeRequest) (*models.TestScrapeResult, error) {
+	wait := time.Duration(req.TimeoutSeconds)*time.Second + testScrapeGrace
+	req.ID = primitive.NewObjectID().Hex()
+	req.ExpiresAt = time.Now().Add(wait).Unix()
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode test scrape request: %w", err)
+	}
+	if err := c.redisClient.RPush(ctx, TestScrapeQueue, payload).Err(); err != nil {
+		return nil, fmt.Errorf("failed to queue test scrape: %w", err)
+	}
+
+	reply, err := c.redisClient.BLPop(ctx, wait, TestScrapeReplyPrefix+req.ID).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, models.ErrTestScrapeTimeout
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for test scrape: %w", err)
+	}
+
+	var result models.TestScrapeResult
+	if err := json.Unmarshal([]byte(reply[1]), &result); err != nil {
+		return nil, fmt.Errorf("failed to decode test scrape reply: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,182 @@
+package retention
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"tennis-booker/internal/models"
+)
+
+// archiveFileSuffix is the extension of the gzip-compressed JSON Lines archive files
+const archiveFileSuffix = ".jsonl.gz"
+
+// ArchiveSink stores archived slots. Each chunk is a complete gzip member of
+// JSON Lines slots that all share the given date, so chunks can be concatenated
+// or stored side by side and still be read back as gzip streams.
+type ArchiveSink interface {
+	WriteArchive(ctx context.Context, date string, chunk []byte) error
+}
+
+// Archive sink names, as selected by RETENTION_ARCHIVE_SINK
+const (
+	ArchiveSinkLocal = "local"
+	ArchiveSinkS3    = "s3"
+)
+
+// LocalArchiveSink appends chunks to one file per date in Dir
+type LocalArchiveSink struct {
+	Dir string
+}
+
+// NewLocalArchiveSink creates a sink writing archive files to dir
+func NewLocalArchiveSink(dir string) *LocalArchiveSink {
+	return &LocalArchiveSink{Dir: dir}
+}
+
+// WriteArchive appends chunk to the archive file for date. Each file is
+// rewritten to a temp file and renamed into place, so a crash leaves either the
+// old or the new file, never a partial one.
+func (l *LocalArchiveSink) WriteArchive(ctx context.Context, date string, chunk []byte) error {
+	if err := os.MkdirAll(l.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	path := filepath.Join(l.Dir, date+archiveFileSuffix)
+	if err := appendToArchive(path, chunk); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// SetArchiveSink replaces the default local sink, e.g. with an S3ArchiveSink
+func (s *RetentionService) SetArchiveSink(sink ArchiveSink) {
+	s.archiveSink = sink
+}
+
+// sink returns the configured archive sink, defaulting to local files in ArchiveDir
+func (s *RetentionService) sink() ArchiveSink {
+	if s.archiveSink != nil {
+		return s.archiveSink
+	}
+	return NewLocalArchiveSink(s.config.ArchiveDir)
+}
+
+// archiveSlots writes slots to the archive sink, one chunk per slot date. The
+// caller only deletes slots after this returns, so a crash can at worst archive
+// a batch twice on the next run; archive readers should treat the slot ID as unique.
+func (s *RetentionService) archiveSlots(ctx context.Context, slots []models.CourtSlot) error {
+	partitions := make(map[string][]models.CourtSlot)
+	for _, slot := range slots {
+		date := archiveDate(slot)
+		partitions[date] = append(partitions[date], slot)
+	}
+
+	dates := make([]string, 0, len(partitions))
+	for date := range partitions {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	sink := s.sink()
+	for _, date := range dates {
+		chunk, err := encodeArchiveChunk(partitions[date])
+		if err != nil {
+			return fmt.Errorf("failed to encode archive for %s: %w", date, err)
+		}
+		if err := sink.WriteArchive(ctx, date, chunk); err != nil {
+			return err
+		}
+
+		s.logDebug("Archived slots", map[string]interface{}{
+			"date":  date,
+			"count": len(partitions[date]),
+		})
+	}
+
+	return nil
+}
+
+// encodeArchiveChunk returns slots as gzip-compressed JSON Lines
+func encodeArchiveChunk(slots []models.CourtSlot) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+	for _, slot := range slots {
+		if err := encoder.Encode(slot); err != nil {
+			return nil, err
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// archiveDate returns the date partition a slot is archived under
+func archiveDate(slot models.CourtSlot) string {
+	if slot.Date != "" {
+		return slot.Date
+	}
+	if !slot.SlotDate.IsZero() {
+		return slot.SlotDate.UTC().Format("2006-01-02")
+	}
+	return "undated"
+}
+
+// appendToArchive atomically replaces path with its current contents followed by
+// chunk. Concatenated gzip members form a valid gzip stream, so the existing data
+// is copied without recompressing.
+func appendToArchive(path string, chunk []byte) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	existing, err := os.Open(path)
+	switch {
+	case err == nil:
+		_, err = io.Copy(tmp, existing)
+		existing.Close()
+		if err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	if _, err = tmp.Write(chunk); err != nil {
+		return err
+	}
+
+	// Make sure the data is on disk before the rename makes it the archive
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Persist the rename itself; not every platform supports syncing a directory
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
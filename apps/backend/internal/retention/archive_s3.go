@@ -0,0 +1,183 @@
+package retention
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3Config configures an S3ArchiveSink. Endpoint can point at any S3-compatible
+// service such as MinIO; leave it empty for AWS.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// UsePathStyle addresses the bucket as endpoint/bucket/key rather than
+	// bucket.endpoint/key. MinIO needs this.
+	UsePathStyle bool
+
+	// MaxAttempts is how many times an upload is tried before giving up
+	MaxAttempts int
+
+	// RetryBackoff is the delay before the first retry, doubling each attempt
+	RetryBackoff time.Duration
+}
+
+// S3ArchiveSink uploads each archive chunk as its own object under
+// Prefix/<date>/, so nothing already uploaded is ever overwritten
+type S3ArchiveSink struct {
+	config S3Config
+	client *s3.Client
+	now    func() time.Time
+}
+
+// errS3Permanent marks upload failures that retrying won't fix
+var errS3Permanent = errors.New("permanent S3 error")
+
+// NewS3ArchiveSink creates an S3 sink, filling in defaults for anything unset
+func NewS3ArchiveSink(config S3Config) (*S3ArchiveSink, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 credentials are required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint != "" {
+		if _, err := url.Parse(config.Endpoint); err != nil {
+			return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+		}
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = 500 * time.Millisecond
+	}
+	config.Prefix = strings.Trim(config.Prefix, "/")
+
+	return &S3ArchiveSink{
+		config: config,
+		client: newS3Client(config, &http.Client{Timeout: 60 * time.Second}),
+		now:    time.Now,
+	}, nil
+}
+
+// newS3Client creates the S3 client an S3ArchiveSink uploads with
+func newS3Client(config S3Config, httpClient s3.HTTPClient) *s3.Client {
+	options := s3.Options{
+		Region:       config.Region,
+		Credentials:  credentials.NewStaticCredentialsProvider(config.AccessKeyID, config.SecretAccessKey, ""),
+		UsePathStyle: config.UsePathStyle,
+		HTTPClient:   httpClient,
+
+		// WriteArchive retries on its own, so a mismatched ETag is retried too
+		// and attempts follow MaxAttempts and RetryBackoff
+		Retryer: aws.NopRetryer{},
+
+		// The body is checked with Content-MD5, which S3-compatible services
+		// without the newer checksum headers also support
+		RequestChecksumCalculation: aws.RequestChecksumCalculationWhenRequired,
+		ResponseChecksumValidation: aws.ResponseChecksumValidationWhenRequired,
+	}
+	if config.Endpoint != "" {
+		options.BaseEndpoint = aws.String(config.Endpoint)
+	}
+	return s3.New(options)
+}
+
+// WriteArchive uploads chunk, retrying network errors, throttling and 5xx
+// responses. S3 checks the body against Content-MD5 and the returned ETag is
+// compared with it too, so a nil error means the object was stored intact.
+func (s *S3ArchiveSink) WriteArchive(ctx context.Context, date string, chunk []byte) error {
+	key, err := s.objectKey(date)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.config.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err = s.putObject(ctx, key, chunk)
+		if err == nil || errors.Is(err, errS3Permanent) || attempt >= s.config.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upload s3://%s/%s: %w", s.config.Bucket, key, err)
+	}
+	return nil
+}
+
+// objectKey returns a unique key for a new chunk of the given date
+func (s *S3ArchiveSink) objectKey(date string) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s-%s%s", s.now().UTC().Format("20060102T150405Z"), hex.EncodeToString(suffix), archiveFileSuffix)
+
+	if s.config.Prefix == "" {
+		return date + "/" + name, nil
+	}
+	return s.config.Prefix + "/" + date + "/" + name, nil
+}
+
+// putObject makes a single upload attempt
+func (s *S3ArchiveSink) putObject(ctx context.Context, key string, body []byte) error {
+	sum := md5.Sum(body)
+	output, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(s.config.Bucket),
+		Key:           aws.String(key),
+		Body:          bytes.NewReader(body),
+		ContentLength: aws.Int64(int64(len(body))),
+		ContentMD5:    aws.String(base64.StdEncoding.EncodeToString(sum[:])),
+		ContentType:   aws.String("application/gzip"),
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("%w: %v", errS3Permanent, ctx.Err())
+		}
+		var respErr *awshttp.ResponseError
+		if errors.As(err, &respErr) {
+			status := respErr.HTTPStatusCode()
+			if status != http.StatusTooManyRequests && status < 500 {
+				return fmt.Errorf("%w: %v", errS3Permanent, err)
+			}
+		}
+		return err
+	}
+
+	// Single-part uploads without SSE-KMS get the body's MD5 as their ETag
+	etag := strings.Trim(aws.ToString(output.ETag), `"`)
+	if etag != "" && len(etag) == 32 && etag != hex.EncodeToString(sum[:]) {
+		return fmt.Errorf("uploaded object ETag %s doesn't match the archive checksum", etag)
+	}
+	return nil
+}
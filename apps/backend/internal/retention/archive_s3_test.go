@@ -0,0 +1,162 @@
+package retention
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeS3 stores PUT bodies, failing the first failures requests with status
+type fakeS3 struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	requests int32
+	failures int32
+	status   int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt32(&f.requests, 1)
+	if n <= f.failures {
+		w.WriteHeader(f.status)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	sum := md5.Sum(body)
+	if r.Header.Get("Content-MD5") != base64.StdEncoding.EncodeToString(sum[:]) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, "<Error><Code>BadDigest</Code></Error>")
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	if f.objects == nil {
+		f.objects = make(map[string][]byte)
+	}
+	f.objects[r.URL.Path] = body
+	f.mu.Unlock()
+
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+func newTestS3Sink(t *testing.T, endpoint string) *S3ArchiveSink {
+	sink, err := NewS3ArchiveSink(S3Config{
+		Endpoint:        endpoint,
+		Bucket:          "archives",
+		Prefix:          "/retention/",
+		AccessKeyID:     "key",
+		SecretAccessKey: "secret",
+		UsePathStyle:    true,
+		RetryBackoff:    time.Millisecond,
+	})
+	require.NoError(t, err)
+	return sink
+}
+
+func TestS3ArchiveSink_UploadsChunkUnderDatePrefix(t *testing.T) {
+	s3 := &fakeS3{}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+
+	sink := newTestS3Sink(t, server.URL)
+	require.NoError(t, sink.WriteArchive(context.Background(), "2024-06-09", []byte("chunk-1")))
+	require.NoError(t, sink.WriteArchive(context.Background(), "2024-06-09", []byte("chunk-2")))
+
+	require.Len(t, s3.objects, 2, "each chunk gets its own object")
+	for path, body := range s3.objects {
+		assert.True(t, strings.HasPrefix(path, "/archives/retention/2024-06-09/"), path)
+		assert.True(t, strings.HasSuffix(path, ".jsonl.gz"), path)
+		assert.Contains(t, []string{"chunk-1", "chunk-2"}, string(body))
+	}
+}
+
+func TestS3ArchiveSink_RetriesTransientErrors(t *testing.T) {
+	s3 := &fakeS3{failures: 2, status: http.StatusServiceUnavailable}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+
+	sink := newTestS3Sink(t, server.URL)
+	require.NoError(t, sink.WriteArchive(context.Background(), "2024-06-09", []byte("chunk")))
+	assert.Equal(t, int32(3), s3.requests)
+	assert.Len(t, s3.objects, 1)
+}
+
+func TestS3ArchiveSink_GivesUpAfterMaxAttempts(t *testing.T) {
+	s3 := &fakeS3{failures: 10, status: http.StatusInternalServerError}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+
+	sink := newTestS3Sink(t, server.URL)
+	err := sink.WriteArchive(context.Background(), "2024-06-09", []byte("chunk"))
+	require.Error(t, err)
+	assert.Equal(t, int32(3), s3.requests)
+}
+
+func TestS3ArchiveSink_DoesNotRetryClientErrors(t *testing.T) {
+	s3 := &fakeS3{failures: 10, status: http.StatusForbidden}
+	server := httptest.NewServer(s3)
+	defer server.Close()
+
+	sink := newTestS3Sink(t, server.URL)
+	err := sink.WriteArchive(context.Background(), "2024-06-09", []byte("chunk"))
+	require.Error(t, err)
+	assert.Equal(t, int32(1), s3.requests)
+}
+
+func TestS3ArchiveSink_RejectsMismatchedETag(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("ETag", `"00000000000000000000000000000000"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink := newTestS3Sink(t, server.URL)
+	err := sink.WriteArchive(context.Background(), "2024-06-09", []byte("chunk"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ETag")
+	assert.Equal(t, int32(3), requests, "a corrupted upload is retried")
+}
+
+// recordingHTTPClient answers every request with an empty 200 and keeps the URLs
+type recordingHTTPClient struct {
+	urls []string
+}
+
+func (c *recordingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.urls = append(c.urls, req.URL.String())
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+}
+
+func TestS3ArchiveSink_AddressesAWSVirtualHostedStyle(t *testing.T) {
+	sink, err := NewS3ArchiveSink(S3Config{Bucket: "archives", Region: "eu-west-2", AccessKeyID: "key", SecretAccessKey: "secret"})
+	require.NoError(t, err)
+	httpClient := &recordingHTTPClient{}
+	sink.client = newS3Client(sink.config, httpClient)
+
+	require.NoError(t, sink.WriteArchive(context.Background(), "2024-06-09", []byte("chunk")))
+	require.Len(t, httpClient.urls, 1)
+	assert.True(t, strings.HasPrefix(httpClient.urls[0], "https://archives.s3.eu-west-2.amazonaws.com/2024-06-09/"), httpClient.urls[0])
+
+	_, err = NewS3ArchiveSink(S3Config{Bucket: "archives"})
+	assert.Error(t, err, "credentials are required")
+}
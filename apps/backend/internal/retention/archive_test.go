@@ -0,0 +1,155 @@
+package retention
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tennis-booker/internal/models"
+)
+
+// readArchive returns the slots in an archive file
+func readArchive(t *testing.T, path string) []models.CourtSlot {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	var slots []models.CourtSlot
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var slot models.CourtSlot
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &slot))
+		slots = append(slots, slot)
+	}
+	require.NoError(t, scanner.Err())
+	return slots
+}
+
+func newArchiveTestService(dir string) *RetentionService {
+	config := DefaultRetentionConfig()
+	config.ArchiveBeforeDelete = true
+	config.ArchiveDir = dir
+	return &RetentionService{config: config, logger: log.New(io.Discard, "", 0)}
+}
+
+func TestArchiveSlots_PartitionsByDateAndAppends(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "archive")
+	service := newArchiveTestService(dir)
+
+	err := service.archiveSlots(context.Background(), []models.CourtSlot{
+		{ID: "a", Date: "2024-06-09", VenueName: "Venue A", Price: 12.5},
+		{ID: "b", Date: "2024-06-10", VenueName: "Venue B"},
+		{ID: "c", Date: "2024-06-09", VenueName: "Venue A"},
+	})
+	require.NoError(t, err)
+
+	// A later batch for the same date is appended to the existing file
+	err = service.archiveSlots(context.Background(), []models.CourtSlot{{ID: "d", Date: "2024-06-09"}})
+	require.NoError(t, err)
+
+	june9 := readArchive(t, filepath.Join(dir, "2024-06-09.jsonl.gz"))
+	require.Len(t, june9, 3)
+	assert.Equal(t, []string{"a", "c", "d"}, slotIDs(june9))
+	assert.Equal(t, 12.5, june9[0].Price)
+
+	june10 := readArchive(t, filepath.Join(dir, "2024-06-10.jsonl.gz"))
+	assert.Equal(t, []string{"b"}, slotIDs(june10))
+
+	// No temp files are left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestArchiveDate(t *testing.T) {
+	assert.Equal(t, "2024-06-09", archiveDate(models.CourtSlot{Date: "2024-06-09"}))
+	assert.Equal(t, "2024-06-11", archiveDate(models.CourtSlot{SlotDate: time.Date(2024, 6, 11, 18, 0, 0, 0, time.UTC)}))
+	assert.Equal(t, "undated", archiveDate(models.CourtSlot{}))
+}
+
+func TestDeleteSlotsInBatches_ArchiveFailureSkipsDelete(t *testing.T) {
+	// A regular file where the archive directory should be makes archiving fail
+	blocker := filepath.Join(t.TempDir(), "not-a-dir")
+	require.NoError(t, os.WriteFile(blocker, []byte("x"), 0o644))
+
+	// No court slot service: reaching the delete step would panic
+	service := newArchiveTestService(blocker)
+
+	deleted, archived, err := service.deleteSlotsInBatches(context.Background(), []models.CourtSlot{{ID: "a", Date: "2024-06-09"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not deleting")
+	assert.Equal(t, 0, deleted)
+	assert.Equal(t, 0, archived)
+}
+
+func TestArchiveSlots_FailureKeepsExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	service := newArchiveTestService(dir)
+	path := filepath.Join(dir, "2024-06-09.jsonl.gz")
+	require.NoError(t, service.archiveSlots(context.Background(), []models.CourtSlot{{ID: "a", Date: "2024-06-09"}}))
+
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// Slots that can't be encoded fail before anything is written
+	bad := []models.CourtSlot{{ID: "b", Date: "2024-06-09", Price: math.NaN()}}
+	require.Error(t, service.archiveSlots(context.Background(), bad))
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temp files should be left behind")
+}
+
+// recordingSink keeps the chunks it is given
+type recordingSink struct {
+	chunks map[string][][]byte
+}
+
+func (r *recordingSink) WriteArchive(ctx context.Context, date string, chunk []byte) error {
+	if r.chunks == nil {
+		r.chunks = make(map[string][][]byte)
+	}
+	r.chunks[date] = append(r.chunks[date], chunk)
+	return nil
+}
+
+func TestArchiveSlots_UsesConfiguredSink(t *testing.T) {
+	service := newArchiveTestService("")
+	sink := &recordingSink{}
+	service.SetArchiveSink(sink)
+	require.NoError(t, service.ValidateConfiguration(), "no archive directory is needed with a custom sink")
+
+	err := service.archiveSlots(context.Background(), []models.CourtSlot{
+		{ID: "a", Date: "2024-06-09"},
+		{ID: "b", Date: "2024-06-10"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sink.chunks["2024-06-09"], 1)
+	require.Len(t, sink.chunks["2024-06-10"], 1)
+
+	gz, err := gzip.NewReader(bytes.NewReader(sink.chunks["2024-06-09"][0]))
+	require.NoError(t, err)
+	var slot models.CourtSlot
+	require.NoError(t, json.NewDecoder(gz).Decode(&slot))
+	assert.Equal(t, "a", slot.ID)
+}
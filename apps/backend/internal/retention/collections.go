@@ -0,0 +1,130 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// SlotsCollection is the court slot collection. Unlike the other collections it
+// isn't purged purely by age: slots matching an active preference are kept.
+const SlotsCollection = "court_slots"
+
+// timestampFields maps each collection that can be purged by age to the field
+// holding the time its documents were written
+var timestampFields = map[string]string{
+	"scraping_logs": "scrape_timestamp",
+	"alert_history": "alert_sent_at",
+}
+
+// WindowFor returns the retention window for a collection, falling back to
+// RetentionWindow for collections without their own entry in CollectionWindows
+func (c RetentionConfig) WindowFor(collection string) time.Duration {
+	if window, ok := c.CollectionWindows[collection]; ok {
+		return window
+	}
+	return c.RetentionWindow
+}
+
+// validateCollectionWindows checks every configured collection is one we know
+// how to purge and has a positive window
+func validateCollectionWindows(windows map[string]time.Duration) error {
+	for collection, window := range windows {
+		if _, ok := timestampFields[collection]; !ok && collection != SlotsCollection {
+			return fmt.Errorf("retention is not supported for collection %q (supported: %s)", collection, strings.Join(supportedCollections(), ", "))
+		}
+		if window <= 0 {
+			return fmt.Errorf("retention window for %s must be positive, got %v", collection, window)
+		}
+	}
+	return nil
+}
+
+// supportedCollections lists the collections that accept a retention window
+func supportedCollections() []string {
+	collections := []string{SlotsCollection}
+	for collection := range timestampFields {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	return collections
+}
+
+// purgeCollections deletes documents older than their window from every
+// configured collection other than the slots collection. A failure in one
+// collection doesn't stop the others from being purged.
+func (s *RetentionService) purgeCollections(ctx context.Context, metrics *RetentionMetrics) error {
+	collections := make([]string, 0, len(s.config.CollectionWindows))
+	for collection := range s.config.CollectionWindows {
+		if collection != SlotsCollection {
+			collections = append(collections, collection)
+		}
+	}
+	sort.Strings(collections)
+
+	var firstErr error
+	for _, collection := range collections {
+		deleted, err := s.purgeCollection(ctx, collection, s.config.WindowFor(collection))
+		if err != nil {
+			metrics.ErrorsEncountered++
+			s.logError("Failed to purge collection", err, map[string]interface{}{
+				"collection": collection,
+			})
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to purge %s: %w", collection, err)
+			}
+			continue
+		}
+		metrics.DeletedByCollection[collection] = deleted
+	}
+
+	return firstErr
+}
+
+// purgeCollection deletes documents in collection older than window, or just
+// counts them in dry-run mode
+func (s *RetentionService) purgeCollection(ctx context.Context, collection string, window time.Duration) (int, error) {
+	filter, err := expiredFilter(collection, window)
+	if err != nil {
+		return 0, err
+	}
+	coll := s.db.Collection(collection)
+
+	if s.config.DryRun {
+		count, err := coll.CountDocuments(ctx, filter)
+		if err != nil {
+			return 0, err
+		}
+		s.logInfo("DRY RUN: Would delete documents", map[string]interface{}{
+			"collection":       collection,
+			"retention_window": window,
+			"count":            count,
+		})
+		return 0, nil
+	}
+
+	result, err := coll.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	s.logInfo("Purged expired documents", map[string]interface{}{
+		"collection":       collection,
+		"retention_window": window,
+		"count":            result.DeletedCount,
+	})
+	return int(result.DeletedCount), nil
+}
+
+// expiredFilter matches the documents in collection older than window
+func expiredFilter(collection string, window time.Duration) (bson.M, error) {
+	field, ok := timestampFields[collection]
+	if !ok {
+		return nil, fmt.Errorf("retention is not supported for collection %q", collection)
+	}
+	return bson.M{field: bson.M{"$lt": time.Now().Add(-window)}}, nil
+}
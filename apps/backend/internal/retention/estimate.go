@@ -0,0 +1,123 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// namespaceNotFound is the MongoDB error code for a collection that doesn't exist
+const namespaceNotFound = 26
+
+// CollectionEstimate is how much a retention cycle would remove from one collection
+type CollectionEstimate struct {
+	Collection       string
+	RetentionWindow  time.Duration
+	Documents        int64
+	TotalDocuments   int64
+	AvgDocumentBytes int64
+	ReclaimableBytes int64
+}
+
+// RetentionEstimate is the result of Estimate across every configured collection
+type RetentionEstimate struct {
+	Collections      []CollectionEstimate
+	TotalDocuments   int64
+	ReclaimableBytes int64
+	Metrics          *RetentionMetrics
+}
+
+// collectionStats is the part of the collStats output Estimate needs
+type collectionStats struct {
+	Count      float64 `bson:"count"`
+	AvgObjSize float64 `bson:"avgObjSize"`
+}
+
+// Estimate reports how many documents a retention cycle would delete from each
+// collection and roughly how many bytes that would free, based on each
+// collection's average document size. It never deletes anything, whatever DryRun says.
+func (s *RetentionService) Estimate(ctx context.Context) (*RetentionEstimate, error) {
+	metrics := &RetentionMetrics{
+		StartTime:           time.Now(),
+		DryRunMode:          true,
+		DeletedByCollection: make(map[string]int),
+	}
+
+	slotsToDelete, err := s.findSlotsToDelete(ctx, metrics)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := s.estimateCollection(ctx, SlotsCollection, int64(len(slotsToDelete)))
+	if err != nil {
+		return nil, err
+	}
+	estimates := []CollectionEstimate{slots}
+
+	collections := make([]string, 0, len(s.config.CollectionWindows))
+	for collection := range s.config.CollectionWindows {
+		if collection != SlotsCollection {
+			collections = append(collections, collection)
+		}
+	}
+	sort.Strings(collections)
+
+	for _, collection := range collections {
+		filter, err := expiredFilter(collection, s.config.WindowFor(collection))
+		if err != nil {
+			return nil, err
+		}
+		count, err := s.db.Collection(collection).CountDocuments(ctx, filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count expired documents in %s: %w", collection, err)
+		}
+
+		estimate, err := s.estimateCollection(ctx, collection, count)
+		if err != nil {
+			return nil, err
+		}
+		estimates = append(estimates, estimate)
+	}
+
+	metrics.EndTime = time.Now()
+	metrics.Duration = metrics.EndTime.Sub(metrics.StartTime)
+
+	result := newRetentionEstimate(estimates)
+	result.Metrics = metrics
+	return result, nil
+}
+
+// estimateCollection sizes the removal of documents from collection using collStats
+func (s *RetentionService) estimateCollection(ctx context.Context, collection string, documents int64) (CollectionEstimate, error) {
+	var stats collectionStats
+	err := s.db.RunCommand(ctx, bson.D{{Key: "collStats", Value: collection}}).Decode(&stats)
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && cmdErr.Code == namespaceNotFound) {
+		return CollectionEstimate{}, fmt.Errorf("failed to get stats for %s: %w", collection, err)
+	}
+
+	avg := int64(stats.AvgObjSize)
+	return CollectionEstimate{
+		Collection:       collection,
+		RetentionWindow:  s.config.WindowFor(collection),
+		Documents:        documents,
+		TotalDocuments:   int64(stats.Count),
+		AvgDocumentBytes: avg,
+		ReclaimableBytes: documents * avg,
+	}, nil
+}
+
+// newRetentionEstimate totals the per-collection estimates
+func newRetentionEstimate(collections []CollectionEstimate) *RetentionEstimate {
+	estimate := &RetentionEstimate{Collections: collections}
+	for _, c := range collections {
+		estimate.TotalDocuments += c.Documents
+		estimate.ReclaimableBytes += c.ReclaimableBytes
+	}
+	return estimate
+}
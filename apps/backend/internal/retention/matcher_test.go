@@ -245,399 +245,6 @@ func TestDoesSlotMatchActivePreferences(t *testing.T) {
 	}
 }
 
-func TestMatchesVenuePreferences(t *testing.T) {
-	venueID := primitive.NewObjectID()
-	slot := models.CourtSlot{
-		VenueID:   venueID,
-		VenueName: "Test Tennis Club",
-	}
-
-	tests := []struct {
-		name     string
-		pref     models.UserPreferences
-		expected bool
-	}{
-		{
-			name:     "no venue preferences - matches",
-			pref:     models.UserPreferences{},
-			expected: true,
-		},
-		{
-			name: "preferred venue by ID - matches",
-			pref: models.UserPreferences{
-				PreferredVenues: []string{venueID.Hex()},
-			},
-			expected: true,
-		},
-		{
-			name: "preferred venue by name - matches",
-			pref: models.UserPreferences{
-				PreferredVenues: []string{"Test Tennis Club"},
-			},
-			expected: true,
-		},
-		{
-			name: "excluded venue by ID - no match",
-			pref: models.UserPreferences{
-				ExcludedVenues: []string{venueID.Hex()},
-			},
-			expected: false,
-		},
-		{
-			name: "excluded venue by name - no match",
-			pref: models.UserPreferences{
-				ExcludedVenues: []string{"Test Tennis Club"},
-			},
-			expected: false,
-		},
-		{
-			name: "different preferred venue - no match",
-			pref: models.UserPreferences{
-				PreferredVenues: []string{"Other Tennis Club"},
-			},
-			expected: false,
-		},
-		{
-			name: "excluded takes precedence over preferred",
-			pref: models.UserPreferences{
-				PreferredVenues: []string{venueID.Hex()},
-				ExcludedVenues:  []string{venueID.Hex()},
-			},
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := matchesVenuePreferences(slot, tt.pref)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-func TestMatchesDayPreferences(t *testing.T) {
-	// Monday slot
-	slot := models.CourtSlot{
-		Date:     "2025-06-16", // Monday
-		SlotDate: time.Date(2025, 6, 16, 10, 0, 0, 0, time.UTC),
-	}
-
-	tests := []struct {
-		name     string
-		pref     models.UserPreferences
-		expected bool
-	}{
-		{
-			name:     "no day preferences - matches",
-			pref:     models.UserPreferences{},
-			expected: true,
-		},
-		{
-			name: "preferred day matches - matches",
-			pref: models.UserPreferences{
-				PreferredDays: []string{"monday"},
-			},
-			expected: true,
-		},
-		{
-			name: "preferred day matches (case insensitive) - matches",
-			pref: models.UserPreferences{
-				PreferredDays: []string{"MONDAY"},
-			},
-			expected: true,
-		},
-		{
-			name: "multiple preferred days, one matches - matches",
-			pref: models.UserPreferences{
-				PreferredDays: []string{"tuesday", "monday", "friday"},
-			},
-			expected: true,
-		},
-		{
-			name: "preferred day doesn't match - no match",
-			pref: models.UserPreferences{
-				PreferredDays: []string{"tuesday"},
-			},
-			expected: false,
-		},
-		{
-			name: "multiple preferred days, none match - no match",
-			pref: models.UserPreferences{
-				PreferredDays: []string{"tuesday", "wednesday", "friday"},
-			},
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := matchesDayPreferences(slot, tt.pref)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-func TestMatchesTimePreferences(t *testing.T) {
-	slot := models.CourtSlot{
-		StartTime: "10:00",
-		EndTime:   "11:00",
-	}
-
-	tests := []struct {
-		name        string
-		pref        models.UserPreferences
-		expected    bool
-		expectError bool
-	}{
-		{
-			name:        "no time preferences - matches",
-			pref:        models.UserPreferences{},
-			expected:    true,
-			expectError: false,
-		},
-		{
-			name: "exact time match - matches",
-			pref: models.UserPreferences{
-				Times: []models.TimeRange{
-					{Start: "10:00", End: "11:00"},
-				},
-			},
-			expected:    true,
-			expectError: false,
-		},
-		{
-			name: "overlapping time - matches",
-			pref: models.UserPreferences{
-				Times: []models.TimeRange{
-					{Start: "09:00", End: "10:30"},
-				},
-			},
-			expected:    true,
-			expectError: false,
-		},
-		{
-			name: "slot within preference range - matches",
-			pref: models.UserPreferences{
-				Times: []models.TimeRange{
-					{Start: "09:00", End: "12:00"},
-				},
-			},
-			expected:    true,
-			expectError: false,
-		},
-		{
-			name: "preference within slot range - matches",
-			pref: models.UserPreferences{
-				Times: []models.TimeRange{
-					{Start: "10:15", End: "10:45"},
-				},
-			},
-			expected:    true,
-			expectError: false,
-		},
-		{
-			name: "no overlap - no match",
-			pref: models.UserPreferences{
-				Times: []models.TimeRange{
-					{Start: "14:00", End: "16:00"},
-				},
-			},
-			expected:    false,
-			expectError: false,
-		},
-		{
-			name: "adjacent times - no match",
-			pref: models.UserPreferences{
-				Times: []models.TimeRange{
-					{Start: "11:00", End: "12:00"},
-				},
-			},
-			expected:    false,
-			expectError: false,
-		},
-		{
-			name: "multiple time ranges, one matches - matches",
-			pref: models.UserPreferences{
-				Times: []models.TimeRange{
-					{Start: "07:00", End: "08:00"},
-					{Start: "09:30", End: "10:30"},
-					{Start: "14:00", End: "16:00"},
-				},
-			},
-			expected:    true,
-			expectError: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result, err := matchesTimePreferences(slot, tt.pref)
-
-			if tt.expectError {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.expected, result)
-			}
-		})
-	}
-}
-
-func TestMatchesPricePreferences(t *testing.T) {
-	slot := models.CourtSlot{
-		Price: 25.0,
-	}
-
-	tests := []struct {
-		name     string
-		pref     models.UserPreferences
-		expected bool
-	}{
-		{
-			name:     "no price preference - matches",
-			pref:     models.UserPreferences{MaxPrice: 0},
-			expected: true,
-		},
-		{
-			name:     "negative price preference - matches",
-			pref:     models.UserPreferences{MaxPrice: -1},
-			expected: true,
-		},
-		{
-			name:     "price within budget - matches",
-			pref:     models.UserPreferences{MaxPrice: 30.0},
-			expected: true,
-		},
-		{
-			name:     "exact price match - matches",
-			pref:     models.UserPreferences{MaxPrice: 25.0},
-			expected: true,
-		},
-		{
-			name:     "price too high - no match",
-			pref:     models.UserPreferences{MaxPrice: 20.0},
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := matchesPricePreferences(slot, tt.pref)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
-func TestParseTimeString(t *testing.T) {
-	tests := []struct {
-		name        string
-		timeStr     string
-		expected    int
-		expectError bool
-	}{
-		{
-			name:        "valid time - 10:30",
-			timeStr:     "10:30",
-			expected:    630, // 10*60 + 30
-			expectError: false,
-		},
-		{
-			name:        "invalid format",
-			timeStr:     "1030",
-			expected:    0,
-			expectError: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result, err := parseTimeString(tt.timeStr)
-
-			if tt.expectError {
-				assert.Error(t, err)
-			} else {
-				assert.NoError(t, err)
-				assert.Equal(t, tt.expected, result)
-			}
-		})
-	}
-}
-
-func TestTimesOverlap(t *testing.T) {
-	tests := []struct {
-		name     string
-		start1   int
-		end1     int
-		start2   int
-		end2     int
-		expected bool
-	}{
-		{
-			name:     "exact overlap",
-			start1:   600, // 10:00
-			end1:     660, // 11:00
-			start2:   600, // 10:00
-			end2:     660, // 11:00
-			expected: true,
-		},
-		{
-			name:     "partial overlap - start",
-			start1:   600, // 10:00
-			end1:     660, // 11:00
-			start2:   570, // 09:30
-			end2:     630, // 10:30
-			expected: true,
-		},
-		{
-			name:     "partial overlap - end",
-			start1:   600, // 10:00
-			end1:     660, // 11:00
-			start2:   630, // 10:30
-			end2:     690, // 11:30
-			expected: true,
-		},
-		{
-			name:     "one contains the other",
-			start1:   600, // 10:00
-			end1:     660, // 11:00
-			start2:   540, // 09:00
-			end2:     720, // 12:00
-			expected: true,
-		},
-		{
-			name:     "no overlap - before",
-			start1:   600, // 10:00
-			end1:     660, // 11:00
-			start2:   480, // 08:00
-			end2:     540, // 09:00
-			expected: false,
-		},
-		{
-			name:     "no overlap - after",
-			start1:   600, // 10:00
-			end1:     660, // 11:00
-			start2:   720, // 12:00
-			end2:     780, // 13:00
-			expected: false,
-		},
-		{
-			name:     "adjacent - no overlap",
-			start1:   600, // 10:00
-			end1:     660, // 11:00
-			start2:   660, // 11:00
-			end2:     720, // 12:00
-			expected: false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := timesOverlap(tt.start1, tt.end1, tt.start2, tt.end2)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestGetWeekdayFromSlot(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -694,6 +301,34 @@ func TestGetWeekdayFromSlot(t *testing.T) {
 	}
 }
 
+func TestSlotForMatching(t *testing.T) {
+	venueID := primitive.NewObjectID()
+
+	slot, err := slotForMatching(models.CourtSlot{
+		VenueID:   venueID,
+		VenueName: "Test Tennis Club",
+		Date:      "2025-06-17",
+		SlotDate:  time.Date(2025, 6, 16, 0, 0, 0, 0, time.UTC), // SlotDate wins over Date
+		StartTime: "10:30",
+		Price:     25.0,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, venueID.Hex(), slot.VenueID)
+	assert.Equal(t, time.Date(2025, 6, 16, 10, 30, 0, 0, time.UTC), slot.Start)
+	assert.Equal(t, 25.0, slot.Price)
+
+	slot, err = slotForMatching(models.CourtSlot{Date: "2025-06-17", StartTime: "18:00"})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 6, 17, 18, 0, 0, 0, time.UTC), slot.Start)
+	assert.Empty(t, slot.VenueID, "a slot without a venue matches no venue list")
+
+	_, err = slotForMatching(models.CourtSlot{Date: "invalid-date", StartTime: "18:00"})
+	assert.Error(t, err)
+
+	_, err = slotForMatching(models.CourtSlot{Date: "2025-06-17", StartTime: "1800"})
+	assert.Error(t, err)
+}
+
 func TestDoesSlotMatchActivePreferencesDetailed(t *testing.T) {
 	venueID := primitive.NewObjectID()
 	userID := primitive.NewObjectID()
@@ -27,6 +27,18 @@ type RetentionConfig struct {
 
 	// LogLevel controls the verbosity of logging
 	LogLevel string
+
+	// ArchiveBeforeDelete writes slots to gzip-compressed JSON Lines files before
+	// deleting them, so they're kept for analytics
+	ArchiveBeforeDelete bool
+
+	// ArchiveDir is where archive files go, one per slot date (e.g. 2024-06-09.jsonl.gz)
+	ArchiveDir string
+
+	// CollectionWindows overrides RetentionWindow per collection, e.g. keeping
+	// scraping_logs for 3 days and alert_history for 90. Collections other than
+	// court_slots are only purged when listed here.
+	CollectionWindows map[string]time.Duration
 }
 
 // DefaultRetentionConfig returns a sensible default configuration
@@ -37,6 +49,7 @@ func DefaultRetentionConfig() RetentionConfig {
 		DryRun:          false,
 		EnableMetrics:   true,
 		LogLevel:        "info",
+		ArchiveDir:      "archive",
 	}
 }
 
@@ -49,6 +62,8 @@ type RetentionMetrics struct {
 	SlotsCheckedAgainstPrefs   int
 	SlotsIdentifiedForDeletion int
 	SlotsActuallyDeleted       int
+	SlotsArchived              int
+	DeletedByCollection        map[string]int
 	ActivePreferencesCount     int
 	ErrorsEncountered          int
 	DryRunMode                 bool
@@ -59,6 +74,8 @@ type RetentionService struct {
 	config            RetentionConfig
 	courtSlotService  *models.CourtSlotService
 	preferenceService *models.PreferenceService
+	archiveSink       ArchiveSink
+	db                *mongo.Database
 	logger            *log.Logger
 }
 
@@ -76,28 +93,105 @@ func NewRetentionService(
 		config:            config,
 		courtSlotService:  models.NewCourtSlotService(db),
 		preferenceService: models.NewPreferenceService(db),
+		db:                db,
 		logger:            logger,
 	}
 }
 
-// RunRetentionCycle executes a complete retention cycle
+// RunRetentionCycle executes a complete retention cycle: preference-aware
+// deletion of court slots, then age-based purging of every other collection
+// in CollectionWindows
 func (s *RetentionService) RunRetentionCycle(ctx context.Context) (*RetentionMetrics, error) {
 	metrics := &RetentionMetrics{
-		StartTime:  time.Now(),
-		DryRunMode: s.config.DryRun,
+		StartTime:           time.Now(),
+		DryRunMode:          s.config.DryRun,
+		DeletedByCollection: make(map[string]int),
 	}
 
 	s.logInfo("Starting retention cycle", map[string]interface{}{
-		"retention_window": s.config.RetentionWindow,
-		"batch_size":       s.config.BatchSize,
-		"dry_run":          s.config.DryRun,
+		"retention_window":   s.config.RetentionWindow,
+		"collection_windows": s.config.CollectionWindows,
+		"batch_size":         s.config.BatchSize,
+		"dry_run":            s.config.DryRun,
 	})
 
+	slotErr := s.runSlotRetention(ctx, metrics)
+	purgeErr := s.purgeCollections(ctx, metrics)
+
+	// Complete metrics and logging
+	metrics.EndTime = time.Now()
+	metrics.Duration = metrics.EndTime.Sub(metrics.StartTime)
+
+	if slotErr != nil {
+		return metrics, slotErr
+	}
+	if purgeErr != nil {
+		return metrics, purgeErr
+	}
+
+	s.logInfo("Retention cycle completed", map[string]interface{}{
+		"duration":                      metrics.Duration,
+		"candidate_slots":               metrics.CandidateSlotsFound,
+		"slots_checked":                 metrics.SlotsCheckedAgainstPrefs,
+		"slots_identified_for_deletion": metrics.SlotsIdentifiedForDeletion,
+		"slots_actually_deleted":        metrics.SlotsActuallyDeleted,
+		"slots_archived":                metrics.SlotsArchived,
+		"deleted_by_collection":         metrics.DeletedByCollection,
+		"active_preferences":            metrics.ActivePreferencesCount,
+		"errors":                        metrics.ErrorsEncountered,
+		"dry_run":                       metrics.DryRunMode,
+	})
+
+	return metrics, nil
+}
+
+// runSlotRetention deletes old court slots that don't match any active preference
+func (s *RetentionService) runSlotRetention(ctx context.Context, metrics *RetentionMetrics) error {
+	slotsToDelete, err := s.findSlotsToDelete(ctx, metrics)
+	if err != nil {
+		return err
+	}
+
+	// Step 4: Delete slots (or log in dry-run mode)
+	if len(slotsToDelete) > 0 {
+		if s.config.DryRun {
+			s.logInfo("DRY RUN: Would delete the following slots", map[string]interface{}{
+				"slot_ids": slotIDs(slotsToDelete),
+				"count":    len(slotsToDelete),
+				"archive":  s.config.ArchiveBeforeDelete,
+			})
+			metrics.SlotsActuallyDeleted = 0 // No actual deletion in dry-run
+		} else {
+			// Process deletions in batches, archiving each batch first if enabled
+			deletedCount, archivedCount, err := s.deleteSlotsInBatches(ctx, slotsToDelete)
+			metrics.SlotsActuallyDeleted = deletedCount
+			metrics.SlotsArchived = archivedCount
+			metrics.DeletedByCollection[SlotsCollection] = deletedCount
+			if err != nil {
+				metrics.ErrorsEncountered++
+				return fmt.Errorf("failed to delete slots: %w", err)
+			}
+
+			s.logInfo("Successfully deleted slots", map[string]interface{}{
+				"count":    deletedCount,
+				"archived": archivedCount,
+			})
+		}
+	}
+
+	return nil
+}
+
+// findSlotsToDelete returns the court slots older than the slots window that
+// don't match any active preference, recording what it found in metrics
+func (s *RetentionService) findSlotsToDelete(ctx context.Context, metrics *RetentionMetrics) ([]models.CourtSlot, error) {
+	window := s.config.WindowFor(SlotsCollection)
+
 	// Step 1: Get all active user preferences
 	activePreferences, err := s.preferenceService.GetActiveUserPreferences(ctx)
 	if err != nil {
 		metrics.ErrorsEncountered++
-		return metrics, fmt.Errorf("failed to get active user preferences: %w", err)
+		return nil, fmt.Errorf("failed to get active user preferences: %w", err)
 	}
 
 	metrics.ActivePreferencesCount = len(activePreferences)
@@ -106,27 +200,25 @@ func (s *RetentionService) RunRetentionCycle(ctx context.Context) (*RetentionMet
 	})
 
 	// Step 2: Find candidate slots for deletion
-	candidateSlots, err := s.courtSlotService.FindOldUnnotifiedSlots(ctx, s.config.RetentionWindow)
+	candidateSlots, err := s.courtSlotService.FindOldUnnotifiedSlots(ctx, window)
 	if err != nil {
 		metrics.ErrorsEncountered++
-		return metrics, fmt.Errorf("failed to find candidate slots: %w", err)
+		return nil, fmt.Errorf("failed to find candidate slots: %w", err)
 	}
 
 	metrics.CandidateSlotsFound = len(candidateSlots)
 	s.logInfo("Found candidate slots for retention", map[string]interface{}{
 		"count":            len(candidateSlots),
-		"retention_window": s.config.RetentionWindow,
+		"retention_window": window,
 	})
 
 	if len(candidateSlots) == 0 {
-		s.logInfo("No candidate slots found, retention cycle complete", nil)
-		metrics.EndTime = time.Now()
-		metrics.Duration = metrics.EndTime.Sub(metrics.StartTime)
-		return metrics, nil
+		s.logInfo("No candidate slots found", nil)
+		return nil, nil
 	}
 
 	// Step 3: Filter slots that don't match any active preferences
-	slotsToDelete := []string{}
+	slotsToDelete := []models.CourtSlot{}
 
 	for _, slot := range candidateSlots {
 		metrics.SlotsCheckedAgainstPrefs++
@@ -142,7 +234,7 @@ func (s *RetentionService) RunRetentionCycle(ctx context.Context) (*RetentionMet
 
 		// If slot doesn't match any active preferences, mark for deletion
 		if !matches {
-			slotsToDelete = append(slotsToDelete, slot.ID)
+			slotsToDelete = append(slotsToDelete, slot)
 
 			if s.config.LogLevel == "debug" {
 				s.logDebug("Slot marked for deletion", map[string]interface{}{
@@ -161,65 +253,34 @@ func (s *RetentionService) RunRetentionCycle(ctx context.Context) (*RetentionMet
 		"count": len(slotsToDelete),
 	})
 
-	// Step 4: Delete slots (or log in dry-run mode)
-	if len(slotsToDelete) > 0 {
-		if s.config.DryRun {
-			s.logInfo("DRY RUN: Would delete the following slots", map[string]interface{}{
-				"slot_ids": slotsToDelete,
-				"count":    len(slotsToDelete),
-			})
-			metrics.SlotsActuallyDeleted = 0 // No actual deletion in dry-run
-		} else {
-			// Process deletions in batches
-			deletedCount, err := s.deleteSlotsInBatches(ctx, slotsToDelete)
-			if err != nil {
-				metrics.ErrorsEncountered++
-				return metrics, fmt.Errorf("failed to delete slots: %w", err)
-			}
-
-			metrics.SlotsActuallyDeleted = deletedCount
-			s.logInfo("Successfully deleted slots", map[string]interface{}{
-				"count": deletedCount,
-			})
-		}
-	}
-
-	// Step 5: Complete metrics and logging
-	metrics.EndTime = time.Now()
-	metrics.Duration = metrics.EndTime.Sub(metrics.StartTime)
-
-	s.logInfo("Retention cycle completed", map[string]interface{}{
-		"duration":                      metrics.Duration,
-		"candidate_slots":               metrics.CandidateSlotsFound,
-		"slots_checked":                 metrics.SlotsCheckedAgainstPrefs,
-		"slots_identified_for_deletion": metrics.SlotsIdentifiedForDeletion,
-		"slots_actually_deleted":        metrics.SlotsActuallyDeleted,
-		"active_preferences":            metrics.ActivePreferencesCount,
-		"errors":                        metrics.ErrorsEncountered,
-		"dry_run":                       metrics.DryRunMode,
-	})
-
-	return metrics, nil
+	return slotsToDelete, nil
 }
 
-// deleteSlotsInBatches deletes slots in configurable batch sizes
-func (s *RetentionService) deleteSlotsInBatches(ctx context.Context, slotIDs []string) (int, error) {
-	totalDeleted := 0
+// deleteSlotsInBatches deletes slots in configurable batch sizes. With archiving
+// enabled, each batch is only deleted once its archive write has succeeded.
+func (s *RetentionService) deleteSlotsInBatches(ctx context.Context, slots []models.CourtSlot) (deleted int, archived int, err error) {
 	batchSize := s.config.BatchSize
 
-	for i := 0; i < len(slotIDs); i += batchSize {
+	for i := 0; i < len(slots); i += batchSize {
 		end := i + batchSize
-		if end > len(slotIDs) {
-			end = len(slotIDs)
+		if end > len(slots) {
+			end = len(slots)
+		}
+
+		batch := slots[i:end]
+		if s.config.ArchiveBeforeDelete {
+			if err := s.archiveSlots(ctx, batch); err != nil {
+				return deleted, archived, fmt.Errorf("failed to archive batch %d-%d, not deleting it: %w", i, end, err)
+			}
+			archived += len(batch)
 		}
 
-		batch := slotIDs[i:end]
-		deletedCount, err := s.courtSlotService.DeleteSlotsByIDs(ctx, batch)
+		deletedCount, err := s.courtSlotService.DeleteSlotsByIDs(ctx, slotIDs(batch))
 		if err != nil {
-			return totalDeleted, fmt.Errorf("failed to delete batch %d-%d: %w", i, end, err)
+			return deleted, archived, fmt.Errorf("failed to delete batch %d-%d: %w", i, end, err)
 		}
 
-		totalDeleted += int(deletedCount)
+		deleted += int(deletedCount)
 
 		s.logDebug("Deleted batch of slots", map[string]interface{}{
 			"batch_start": i,
@@ -228,7 +289,16 @@ func (s *RetentionService) deleteSlotsInBatches(ctx context.Context, slotIDs []s
 		})
 	}
 
-	return totalDeleted, nil
+	return deleted, archived, nil
+}
+
+// slotIDs returns the IDs of the given slots
+func slotIDs(slots []models.CourtSlot) []string {
+	ids := make([]string, len(slots))
+	for i, slot := range slots {
+		ids[i] = slot.ID
+	}
+	return ids
 }
 
 // ValidateConfiguration checks if the retention configuration is valid
@@ -245,6 +315,14 @@ func (s *RetentionService) ValidateConfiguration() error {
 		return fmt.Errorf("batch size too large (max 10000), got %d", s.config.BatchSize)
 	}
 
+	if s.config.ArchiveBeforeDelete && s.archiveSink == nil && s.config.ArchiveDir == "" {
+		return fmt.Errorf("archive directory is required when archiving before delete")
+	}
+
+	if err := validateCollectionWindows(s.config.CollectionWindows); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -256,18 +334,21 @@ func (s *RetentionService) GetConfiguration() RetentionConfig {
 // UpdateConfiguration updates the service configuration
 func (s *RetentionService) UpdateConfiguration(config RetentionConfig) error {
 	// Create a temporary service to validate the new config
-	tempService := &RetentionService{config: config}
+	tempService := &RetentionService{config: config, archiveSink: s.archiveSink}
 	if err := tempService.ValidateConfiguration(); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
 	s.config = config
 	s.logInfo("Configuration updated", map[string]interface{}{
-		"retention_window": config.RetentionWindow,
-		"batch_size":       config.BatchSize,
-		"dry_run":          config.DryRun,
-		"enable_metrics":   config.EnableMetrics,
-		"log_level":        config.LogLevel,
+		"retention_window":   config.RetentionWindow,
+		"batch_size":         config.BatchSize,
+		"dry_run":            config.DryRun,
+		"enable_metrics":     config.EnableMetrics,
+		"log_level":          config.LogLevel,
+		"archive":            config.ArchiveBeforeDelete,
+		"archive_dir":        config.ArchiveDir,
+		"collection_windows": config.CollectionWindows,
 	})
 
 	return nil
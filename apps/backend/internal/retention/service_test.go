@@ -78,6 +78,49 @@ func TestRetentionService_ValidateConfiguration(t *testing.T) {
 			expectError: true,
 			errorMsg:    "batch size too large",
 		},
+		{
+			name: "archive without directory",
+			config: RetentionConfig{
+				RetentionWindow:     7 * 24 * time.Hour,
+				BatchSize:           1000,
+				ArchiveBeforeDelete: true,
+			},
+			expectError: true,
+			errorMsg:    "archive directory is required",
+		},
+		{
+			name: "unsupported collection window",
+			config: RetentionConfig{
+				RetentionWindow:   7 * 24 * time.Hour,
+				BatchSize:         1000,
+				CollectionWindows: map[string]time.Duration{"users": time.Hour},
+			},
+			expectError: true,
+			errorMsg:    `retention is not supported for collection "users"`,
+		},
+		{
+			name: "non-positive collection window",
+			config: RetentionConfig{
+				RetentionWindow:   7 * 24 * time.Hour,
+				BatchSize:         1000,
+				CollectionWindows: map[string]time.Duration{"scraping_logs": 0},
+			},
+			expectError: true,
+			errorMsg:    "retention window for scraping_logs must be positive",
+		},
+		{
+			name: "valid collection windows",
+			config: RetentionConfig{
+				RetentionWindow: 7 * 24 * time.Hour,
+				BatchSize:       1000,
+				CollectionWindows: map[string]time.Duration{
+					"court_slots":   7 * 24 * time.Hour,
+					"scraping_logs": 3 * 24 * time.Hour,
+					"alert_history": 90 * 24 * time.Hour,
+				},
+			},
+			expectError: false,
+		},
 		{
 			name: "valid custom config",
 			config: RetentionConfig{
@@ -192,6 +235,17 @@ func TestRetentionMetrics_Structure(t *testing.T) {
 	assert.False(t, metrics.DryRunMode)
 }
 
+func TestRetentionConfig_WindowFor(t *testing.T) {
+	config := RetentionConfig{
+		RetentionWindow:   7 * 24 * time.Hour,
+		CollectionWindows: map[string]time.Duration{"scraping_logs": 3 * 24 * time.Hour},
+	}
+
+	assert.Equal(t, 3*24*time.Hour, config.WindowFor("scraping_logs"))
+	assert.Equal(t, 7*24*time.Hour, config.WindowFor(SlotsCollection), "unlisted collections use RetentionWindow")
+	assert.Equal(t, 7*24*time.Hour, config.WindowFor("alert_history"))
+}
+
 func TestRetentionConfig_Structure(t *testing.T) {
 	// Test that RetentionConfig struct has all expected fields
 	config := RetentionConfig{
@@ -291,3 +345,14 @@ func TestRetentionService_DeleteSlotsInBatches(t *testing.T) {
 	// Verify all slots are deleted correctly
 }
 */
+
+func TestNewRetentionEstimate_Totals(t *testing.T) {
+	estimate := newRetentionEstimate([]CollectionEstimate{
+		{Collection: SlotsCollection, Documents: 100, AvgDocumentBytes: 500, ReclaimableBytes: 50000},
+		{Collection: "scraping_logs", Documents: 10, AvgDocumentBytes: 20000, ReclaimableBytes: 200000},
+	})
+
+	assert.Equal(t, int64(110), estimate.TotalDocuments)
+	assert.Equal(t, int64(250000), estimate.ReclaimableBytes)
+	assert.Len(t, estimate.Collections, 2)
+}
@@ -84,6 +84,9 @@ const (
 	// JWT environment variables
 	JWTSecretEnv = "JWT_SECRET"
 
+	// Key used to encrypt users' TOTP secrets at rest
+	TOTPEncryptionKeyEnv = "TOTP_ENCRYPTION_KEY"
+
 	// Email environment variables
 	EmailAddressEnv  = "EMAIL_ADDRESS"
 	EmailPasswordEnv = "EMAIL_PASSWORD"
@@ -103,7 +106,12 @@ const (
 	// Notification services
 	TwilioSIDEnv        = "TWILIO_SID"
 	TwilioTokenEnv      = "TWILIO_TOKEN"
+	TwilioFromNumberEnv = "TWILIO_FROM_NUMBER"
 	SendGridAPIKeyEnv   = "SENDGRID_API_KEY"
+
+	// S3-compatible storage for retention archives
+	S3AccessKeyIDEnv     = "S3_ACCESS_KEY_ID"
+	S3SecretAccessKeyEnv = "S3_SECRET_ACCESS_KEY"
 )
 
 // Convenience methods for common secrets
@@ -136,6 +144,11 @@ func (sm *SecretsManager) GetJWTSecret() (string, error) {
 	return sm.GetSecret(JWTSecretEnv)
 }
 
+// GetTOTPEncryptionKey retrieves the key used to encrypt two-factor secrets
+func (sm *SecretsManager) GetTOTPEncryptionKey() (string, error) {
+	return sm.GetSecret(TOTPEncryptionKeyEnv)
+}
+
 // GetEmailCredentials retrieves email service credentials
 func (sm *SecretsManager) GetEmailCredentials() (email, password, smtpHost, smtpPort string, err error) {
 	email, err = sm.GetSecret(EmailAddressEnv)
@@ -161,6 +174,26 @@ func (sm *SecretsManager) GetEmailCredentials() (email, password, smtpHost, smtp
 	return email, password, smtpHost, smtpPort, nil
 }
 
+// GetTwilioCredentials retrieves Twilio SMS credentials
+func (sm *SecretsManager) GetTwilioCredentials() (accountSID, authToken, fromNumber string, err error) {
+	accountSID, err = sm.GetSecret(TwilioSIDEnv)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	authToken, err = sm.GetSecret(TwilioTokenEnv)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	fromNumber, err = sm.GetSecret(TwilioFromNumberEnv)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return accountSID, authToken, fromNumber, nil
+}
+
 // GetRedisCredentials retrieves Redis connection credentials
 func (sm *SecretsManager) GetRedisCredentials() (addr, password string, err error) {
 	addr, err = sm.GetSecret(RedisAddrEnv)
@@ -175,4 +208,19 @@ func (sm *SecretsManager) GetRedisCredentials() (addr, password string, err erro
 	}
 
 	return addr, password, nil
-}
\ No newline at end of file
+}
+
+// GetS3Credentials retrieves the access key pair for S3-compatible storage
+func (sm *SecretsManager) GetS3Credentials() (accessKeyID, secretAccessKey string, err error) {
+	accessKeyID, err = sm.GetSecret(S3AccessKeyIDEnv)
+	if err != nil {
+		return "", "", err
+	}
+
+	secretAccessKey, err = sm.GetSecret(S3SecretAccessKeyEnv)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessKeyID, secretAccessKey, nil
+}
@@ -254,4 +254,4 @@ func TestSecretsManager_Constants(t *testing.T) {
 	assert.Equal(t, "TWILIO_SID", TwilioSIDEnv)
 	assert.Equal(t, "TWILIO_TOKEN", TwilioTokenEnv)
 	assert.Equal(t, "SENDGRID_API_KEY", SendGridAPIKeyEnv)
-}
\ No newline at end of file
+}
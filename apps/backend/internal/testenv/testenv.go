@@ -0,0 +1,136 @@
+//go:build integration
+
+// Package testenv runs integration tests against throwaway MongoDB and Redis
+// containers, started with testcontainers-go, so they don't depend on
+// services running on the machine. It needs Docker and is only built with the
+// integration tag:
+//
+//	go test -tags integration -run Integration ./...
+package testenv
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/testcontainers/testcontainers-go/modules/mongodb"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Images match the versions deployed in docker-compose.prod.yml
+const (
+	mongoImage = "mongo:6.0"
+	redisImage = "redis:7.0-alpine"
+)
+
+// startTimeout bounds pulling the images and starting the containers
+const startTimeout = 2 * time.Minute
+
+// Env holds clients connected to the test containers
+type Env struct {
+	Mongo *mongo.Client
+	DB    *mongo.Database // A database of the test's own, dropped on cleanup
+	Redis *redis.Client   // Flushed on cleanup
+
+	MongoURI  string
+	RedisAddr string
+}
+
+// containers are started once per test binary and shared by its tests. Ryuk,
+// testcontainers' reaper, removes them when the binary exits.
+var (
+	startOnce sync.Once
+	mongoURI  string
+	redisOpts *redis.Options
+	startErr  error
+)
+
+func startContainers() {
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+
+	mongoContainer, err := mongodb.Run(ctx, mongoImage)
+	if err != nil {
+		startErr = err
+		return
+	}
+	if mongoURI, err = mongoContainer.ConnectionString(ctx); err != nil {
+		startErr = err
+		return
+	}
+
+	redisContainer, err := tcredis.Run(ctx, redisImage)
+	if err != nil {
+		startErr = err
+		return
+	}
+	redisURL, err := redisContainer.ConnectionString(ctx)
+	if err != nil {
+		startErr = err
+		return
+	}
+	redisOpts, startErr = redis.ParseURL(redisURL)
+}
+
+// SetupTestEnv connects to the MongoDB and Redis containers, starting them
+// for the first test that needs them, and returns a cleanup func that drops
+// the test's database, flushes Redis and disconnects. Tests sharing the
+// containers shouldn't run in parallel.
+func SetupTestEnv(t testing.TB) (*Env, func()) {
+	t.Helper()
+
+	startOnce.Do(startContainers)
+	if startErr != nil {
+		t.Fatalf("Failed to start test containers: %v", startErr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	if err := mongoClient.Ping(ctx, nil); err != nil {
+		mongoClient.Disconnect(context.Background())
+		t.Fatalf("Failed to ping MongoDB: %v", err)
+	}
+
+	redisClient := redis.NewClient(redisOpts)
+	if err := redisClient.FlushDB(ctx).Err(); err != nil {
+		mongoClient.Disconnect(context.Background())
+		redisClient.Close()
+		t.Fatalf("Failed to flush Redis: %v", err)
+	}
+
+	env := &Env{
+		Mongo:     mongoClient,
+		DB:        mongoClient.Database("tennis_booking_test_" + primitive.NewObjectID().Hex()),
+		Redis:     redisClient,
+		MongoURI:  mongoURI,
+		RedisAddr: redisOpts.Addr,
+	}
+
+	cleanup := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := env.DB.Drop(ctx); err != nil {
+			t.Logf("Failed to drop test database: %v", err)
+		}
+		if err := redisClient.FlushDB(ctx).Err(); err != nil {
+			t.Logf("Failed to flush Redis: %v", err)
+		}
+		redisClient.Close()
+		if err := mongoClient.Disconnect(ctx); err != nil {
+			t.Logf("Failed to disconnect from MongoDB: %v", err)
+		}
+	}
+
+	return env, cleanup
+}
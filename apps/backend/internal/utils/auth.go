@@ -25,3 +25,17 @@ func RequireAuth(w http.ResponseWriter, r *http.Request) (primitive.ObjectID, bo
 	}
 	return userID, true
 }
+
+// RequireAdmin validates that the request is from an authenticated admin
+func RequireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	claims, err := auth.GetUserClaimsFromContext(r.Context())
+	if err != nil {
+		WriteError(w, "Authentication required", http.StatusUnauthorized)
+		return false
+	}
+	if claims.Role != auth.RoleAdmin {
+		WriteError(w, "Admin access required", http.StatusForbidden)
+		return false
+	}
+	return true
+}
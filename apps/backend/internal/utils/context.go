@@ -19,3 +19,9 @@ func WithStandardTimeout() (context.Context, context.CancelFunc) {
 func WithDBTimeout() (context.Context, context.CancelFunc) {
 	return WithTimeout(10 * time.Second)
 }
+
+// WithDBTimeoutFrom is WithDBTimeout for work done on behalf of a request: the
+// context keeps parent's values, such as the request ID, but not its cancellation
+func WithDBTimeoutFrom(parent context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(parent), 10*time.Second)
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Config holds the load test settings
+type Config struct {
+	BaseURL       string
+	Endpoint      string
+	Method        string
+	Body          string
+	Token         string
+	Requests      int
+	Concurrent    int
+	Duration      time.Duration
+	Ramp          time.Duration
+	Timeout       time.Duration
+	CSVPath       string
+	ScenarioPath  string
+	TestRateLimit bool
+}
+
+// Result is the outcome of a single request
+type Result struct {
+	Worker     int
+	Step       string // Scenario step name; empty for single-endpoint runs
+	Skipped    bool   // An earlier step in the flow failed, so this one wasn't sent
+	StartedAt  time.Time
+	Latency    time.Duration
+	StatusCode int
+	Err        error
+}
+
+func main() {
+	config := parseFlags()
+
+	client := &http.Client{Timeout: config.Timeout}
+	iterate := func(worker int) []Result {
+		result, _ := sendRequest(client, config, config.Method, config.Endpoint, []byte(config.Body), config.Token)
+		return []Result{result}
+	}
+
+	var scenario *Scenario
+	if config.ScenarioPath != "" {
+		var err error
+		scenario, err = loadScenario(config.ScenarioPath)
+		if err != nil {
+			log.Fatalf("❌ Failed to load scenario: %v", err)
+		}
+		iterate = func(worker int) []Result {
+			return scenario.Run(client, config)
+		}
+		log.Printf("🚀 Load testing scenario %q (%d steps) against %s", scenario.Name, len(scenario.Steps), config.BaseURL)
+	} else {
+		log.Printf("🚀 Load testing %s %s%s", config.Method, config.BaseURL, config.Endpoint)
+	}
+	if config.Duration > 0 {
+		log.Printf("   Duration: %s, concurrency: %d", config.Duration, config.Concurrent)
+	} else {
+		log.Printf("   Iterations: %d, concurrency: %d", config.Requests, config.Concurrent)
+	}
+	if config.Ramp > 0 {
+		log.Printf("   Ramping from 1 to %d workers over %s", config.Concurrent, config.Ramp)
+	}
+
+	start := time.Now()
+	results := run(config, iterate)
+	elapsed := time.Since(start)
+
+	printSummary(config, results, elapsed)
+	if scenario != nil {
+		printStepSummary(scenario, results)
+	}
+
+	if config.CSVPath != "" {
+		if err := writeCSV(config.CSVPath, start, results); err != nil {
+			log.Fatalf("❌ Failed to write CSV: %v", err)
+		}
+		log.Printf("📄 Wrote per-request results to %s", config.CSVPath)
+	}
+}
+
+func parseFlags() Config {
+	var config Config
+	flag.StringVar(&config.BaseURL, "base-url", "http://localhost:8080", "Base URL of the API")
+	flag.StringVar(&config.Endpoint, "endpoint", "/api/health", "Endpoint to request")
+	flag.StringVar(&config.Method, "method", http.MethodGet, "HTTP method")
+	flag.StringVar(&config.Body, "body", "", "JSON request body")
+	flag.StringVar(&config.Token, "token", "", "Bearer token sent in the Authorization header")
+	flag.IntVar(&config.Requests, "requests", 100, "Total number of requests, or scenario runs with -scenario (ignored when -duration is set)")
+	flag.IntVar(&config.Concurrent, "concurrent", 10, "Maximum number of concurrent workers")
+	flag.DurationVar(&config.Duration, "duration", 0, "Run for this long instead of a fixed number of requests")
+	flag.DurationVar(&config.Ramp, "ramp", 0, "Linearly increase concurrency from 1 to -concurrent over this duration")
+	flag.DurationVar(&config.Timeout, "timeout", 30*time.Second, "Per-request timeout")
+	flag.StringVar(&config.CSVPath, "csv", "", "Write per-request latency and status rows to this CSV file")
+	flag.StringVar(&config.ScenarioPath, "scenario", "", "Run the multi-step flow in this JSON file per virtual user instead of a single endpoint")
+	flag.BoolVar(&config.TestRateLimit, "test-rate-limit", false, "Report whether requests were rate limited (HTTP 429)")
+	flag.Parse()
+
+	if config.Concurrent < 1 {
+		log.Fatal("❌ -concurrent must be at least 1")
+	}
+	if config.Duration <= 0 && config.Requests < 1 {
+		log.Fatal("❌ -requests must be at least 1")
+	}
+	if config.Ramp < 0 {
+		log.Fatal("❌ -ramp must not be negative")
+	}
+	return config
+}
+
+// workerStartDelay returns how long worker n (0-based) waits before sending its
+// first request, so the number of active workers grows linearly from 1 to
+// concurrent over the ramp
+func workerStartDelay(n, concurrent int, ramp time.Duration) time.Duration {
+	if ramp <= 0 || concurrent <= 1 {
+		return 0
+	}
+	return time.Duration(int64(ramp) * int64(n) / int64(concurrent-1))
+}
+
+// run calls iterate repeatedly from config.Concurrent workers and returns every
+// result in the order the requests started
+func run(config Config, iterate func(worker int) []Result) []Result {
+	start := time.Now()
+
+	var deadline time.Time
+	if config.Duration > 0 {
+		deadline = start.Add(config.Duration)
+	}
+
+	var (
+		sent    int64
+		mu      sync.Mutex
+		results []Result
+		wg      sync.WaitGroup
+	)
+
+	// nextIteration reports whether a worker should start another iteration
+	nextIteration := func() bool {
+		if !deadline.IsZero() {
+			return time.Now().Before(deadline)
+		}
+		return atomic.AddInt64(&sent, 1) <= int64(config.Requests)
+	}
+
+	for n := 0; n < config.Concurrent; n++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+
+			delay := workerStartDelay(worker, config.Concurrent, config.Ramp)
+			if !deadline.IsZero() && start.Add(delay).After(deadline) {
+				return // The run ends before this worker's turn
+			}
+			time.Sleep(time.Until(start.Add(delay)))
+
+			for nextIteration() {
+				iteration := iterate(worker)
+				for i := range iteration {
+					iteration[i].Worker = worker
+				}
+				mu.Lock()
+				results = append(results, iteration...)
+				mu.Unlock()
+			}
+		}(n)
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].StartedAt.Before(results[j].StartedAt)
+	})
+	return results
+}
+
+// sendRequest sends one request and returns its result and response body
+func sendRequest(client *http.Client, config Config, method, endpoint string, body []byte, token string) (Result, []byte) {
+	result := Result{StartedAt: time.Now()}
+
+	var reader io.Reader
+	if len(body) > 0 {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequest(method, config.BaseURL+endpoint, reader)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Latency = time.Since(result.StartedAt)
+		result.Err = err
+		return result, nil
+	}
+	// Read the whole body so the latency covers the full response
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	result.Latency = time.Since(result.StartedAt)
+	result.StatusCode = resp.StatusCode
+	result.Err = err
+	return result, respBody
+}
+
+// succeeded reports whether the request got a 2xx response
+func (r Result) succeeded() bool {
+	return !r.Skipped && r.Err == nil && r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// percentile returns the nearest-rank percentile p (0-100) of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+func printSummary(config Config, results []Result, elapsed time.Duration) {
+	var sent, succeeded, rateLimited, failed int
+	latencies := make([]time.Duration, 0, len(results))
+	statusCounts := make(map[int]int)
+
+	for _, result := range results {
+		if result.Skipped {
+			continue
+		}
+		sent++
+		if result.Err != nil {
+			failed++
+			continue
+		}
+		latencies = append(latencies, result.Latency)
+		statusCounts[result.StatusCode]++
+		switch {
+		case result.StatusCode == http.StatusTooManyRequests:
+			rateLimited++
+		case result.StatusCode >= 200 && result.StatusCode < 300:
+			succeeded++
+		default:
+			failed++
+		}
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	fmt.Println()
+	fmt.Println("📊 Load Test Results")
+	fmt.Println("====================")
+	fmt.Printf("Total requests:   %d\n", sent)
+	fmt.Printf("Successful (2xx): %d\n", succeeded)
+	fmt.Printf("Rate limited:     %d\n", rateLimited)
+	fmt.Printf("Failed:           %d\n", failed)
+	fmt.Printf("Elapsed:          %s\n", elapsed.Round(time.Millisecond))
+	if elapsed > 0 {
+		fmt.Printf("Requests/sec:     %.2f\n", float64(sent)/elapsed.Seconds())
+	}
+
+	if len(latencies) > 0 {
+		var total time.Duration
+		for _, latency := range latencies {
+			total += latency
+		}
+		fmt.Println()
+		fmt.Println("⏱️  Latency")
+		fmt.Printf("Min: %s\n", latencies[0].Round(time.Microsecond))
+		fmt.Printf("Avg: %s\n", (total / time.Duration(len(latencies))).Round(time.Microsecond))
+		fmt.Printf("p50: %s\n", percentile(latencies, 50).Round(time.Microsecond))
+		fmt.Printf("p95: %s\n", percentile(latencies, 95).Round(time.Microsecond))
+		fmt.Printf("p99: %s\n", percentile(latencies, 99).Round(time.Microsecond))
+		fmt.Printf("Max: %s\n", latencies[len(latencies)-1].Round(time.Microsecond))
+	}
+
+	if len(statusCounts) > 0 {
+		codes := make([]int, 0, len(statusCounts))
+		for code := range statusCounts {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+
+		fmt.Println()
+		fmt.Println("📋 Status codes")
+		for _, code := range codes {
+			fmt.Printf("%d: %d\n", code, statusCounts[code])
+		}
+	}
+
+	if config.TestRateLimit {
+		fmt.Println()
+		if rateLimited > 0 {
+			fmt.Printf("✅ Rate limiting is active: %d of %d requests were rejected with 429\n", rateLimited, sent)
+		} else {
+			fmt.Println("⚠️  No requests were rate limited")
+		}
+	}
+}
+
+// writeCSV writes one row per request sent with its offset from the start of the run
+func writeCSV(path string, start time.Time, results []Result) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	writer.Write([]string{"started_at", "offset_ms", "worker", "step", "latency_ms", "status", "error"})
+	for _, result := range results {
+		if result.Skipped {
+			continue
+		}
+		errText := ""
+		if result.Err != nil {
+			errText = result.Err.Error()
+		}
+		writer.Write([]string{
+			result.StartedAt.Format(time.RFC3339Nano),
+			formatMillis(result.StartedAt.Sub(start)),
+			strconv.Itoa(result.Worker),
+			result.Step,
+			formatMillis(result.Latency),
+			strconv.Itoa(result.StatusCode),
+			errText,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func formatMillis(d time.Duration) string {
+	return strconv.FormatFloat(float64(d)/float64(time.Millisecond), 'f', 3, 64)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Scenario is an ordered flow of requests each virtual user runs, such as
+// logging in and then fetching preferences and courts with the access token
+type Scenario struct {
+	Name  string         `json:"name"`
+	Steps []ScenarioStep `json:"steps"`
+}
+
+// ScenarioStep is a single request in a scenario
+type ScenarioStep struct {
+	Name     string          `json:"name"`
+	Method   string          `json:"method"`
+	Endpoint string          `json:"endpoint"`
+	Body     json.RawMessage `json:"body,omitempty"`
+
+	// ExtractToken is the dot-separated path of a token in the JSON response,
+	// e.g. "accessToken", kept for later steps that set UseToken
+	ExtractToken string `json:"extract_token,omitempty"`
+
+	// UseToken sends the last extracted token as a bearer token
+	UseToken bool `json:"use_token,omitempty"`
+}
+
+func loadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	if err := scenario.validate(); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+func (s *Scenario) validate() error {
+	if len(s.Steps) == 0 {
+		return errors.New("scenario has no steps")
+	}
+	if s.Name == "" {
+		s.Name = "scenario"
+	}
+
+	names := make(map[string]bool)
+	tokenExtracted := false
+	for i := range s.Steps {
+		step := &s.Steps[i]
+		if step.Endpoint == "" {
+			return fmt.Errorf("step %d has no endpoint", i+1)
+		}
+		if step.Method == "" {
+			step.Method = http.MethodGet
+		}
+		step.Method = strings.ToUpper(step.Method)
+		if step.Name == "" {
+			step.Name = fmt.Sprintf("%d %s %s", i+1, step.Method, step.Endpoint)
+		}
+		if names[step.Name] {
+			return fmt.Errorf("step name %q is used more than once", step.Name)
+		}
+		names[step.Name] = true
+
+		if step.UseToken && !tokenExtracted {
+			return fmt.Errorf("step %q uses a token but no earlier step extracts one", step.Name)
+		}
+		if step.ExtractToken != "" {
+			tokenExtracted = true
+		}
+	}
+	return nil
+}
+
+// Run sends each step in order, carrying the extracted token between steps.
+// Once a step fails the remaining steps are recorded as skipped, since they
+// usually depend on it.
+func (s *Scenario) Run(client *http.Client, config Config) []Result {
+	results := make([]Result, 0, len(s.Steps))
+	token := config.Token
+	failed := false
+
+	for _, step := range s.Steps {
+		if failed {
+			results = append(results, Result{Step: step.Name, Skipped: true, StartedAt: time.Now()})
+			continue
+		}
+
+		stepToken := ""
+		if step.UseToken {
+			stepToken = token
+		}
+
+		result, body := sendRequest(client, config, step.Method, step.Endpoint, step.Body, stepToken)
+		result.Step = step.Name
+
+		if result.succeeded() && step.ExtractToken != "" {
+			extracted, err := extractToken(body, step.ExtractToken)
+			if err != nil {
+				result.Err = err
+			} else {
+				token = extracted
+			}
+		}
+
+		failed = !result.succeeded()
+		results = append(results, result)
+	}
+	return results
+}
+
+// extractToken returns the string at a dot-separated path in a JSON document
+func extractToken(body []byte, path string) (string, error) {
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "", fmt.Errorf("response is not JSON: %w", err)
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("no %q in response", path)
+		}
+		value = object[key]
+	}
+
+	token, ok := value.(string)
+	if !ok || token == "" {
+		return "", fmt.Errorf("no %q in response", path)
+	}
+	return token, nil
+}
+
+// printStepSummary prints the success rate and latency percentiles of each step
+func printStepSummary(scenario *Scenario, results []Result) {
+	type stepStats struct {
+		succeeded, rateLimited, failed, skipped int
+		latencies                               []time.Duration
+	}
+	stats := make(map[string]*stepStats, len(scenario.Steps))
+	for _, step := range scenario.Steps {
+		stats[step.Name] = &stepStats{}
+	}
+
+	for _, result := range results {
+		step := stats[result.Step]
+		switch {
+		case result.Skipped:
+			step.skipped++
+			continue
+		case result.succeeded():
+			step.succeeded++
+		case result.Err == nil && result.StatusCode == http.StatusTooManyRequests:
+			step.rateLimited++
+		default:
+			step.failed++
+		}
+		if result.Err == nil {
+			step.latencies = append(step.latencies, result.Latency)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("🧭 Scenario steps (%s)\n", scenario.Name)
+	for _, step := range scenario.Steps {
+		stat := stats[step.Name]
+		sent := stat.succeeded + stat.rateLimited + stat.failed
+		rate := 0.0
+		if sent > 0 {
+			rate = float64(stat.succeeded) / float64(sent) * 100
+		}
+		sort.Slice(stat.latencies, func(i, j int) bool { return stat.latencies[i] < stat.latencies[j] })
+
+		fmt.Printf("%s: %.1f%% success (%d ok, %d rate limited, %d failed, %d skipped), p50 %s, p95 %s, p99 %s\n",
+			step.Name, rate, stat.succeeded, stat.rateLimited, stat.failed, stat.skipped,
+			percentile(stat.latencies, 50).Round(time.Microsecond),
+			percentile(stat.latencies, 95).Round(time.Microsecond),
+			percentile(stat.latencies, 99).Round(time.Microsecond))
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -20,6 +22,7 @@ import (
 type IndexInfo struct {
 	Name      string                 `json:"name"`
 	Keys      map[string]interface{} `json:"keys"`
+	KeyOrder  []string               `json:"key_order,omitempty"`
 	Unique    bool                   `json:"unique,omitempty"`
 	Sparse    bool                   `json:"sparse,omitempty"`
 	TTL       *int32                 `json:"ttl,omitempty"`
@@ -58,6 +61,10 @@ type QueryPattern struct {
 type IndexRecommendation struct {
 	Type            string                 `json:"type"`
 	Keys            map[string]interface{} `json:"keys"`
+	KeyOrder        []string               `json:"key_order,omitempty"`   // Field order for compound indexes
+	TTLSeconds      *int32                 `json:"ttl_seconds,omitempty"` // expireAfterSeconds for TTL indexes
+	IndexName       string                 `json:"index_name,omitempty"`  // Existing index to drop, for DropRedundant
+	CoveredBy       string                 `json:"covered_by,omitempty"`  // Index that makes IndexName redundant
 	Reason          string                 `json:"reason"`
 	Priority        string                 `json:"priority"`
 	EstimatedImpact string                 `json:"estimated_impact"`
@@ -78,11 +85,26 @@ type AnalysisSummary struct {
 	TotalDataSize     int64                 `json:"total_data_size"`
 	TotalIndexSize    int64                 `json:"total_index_size"`
 	UnusedIndexes     []string              `json:"unused_indexes"`
+	RedundantIndexes  []RedundantIndex      `json:"redundant_indexes"`
 	MissingIndexes    []IndexRecommendation `json:"missing_indexes"`
 	PerformanceIssues []string              `json:"performance_issues"`
 }
 
+// RedundantIndex is an index whose keys are an ordered prefix of another index's
+type RedundantIndex struct {
+	Collection string `json:"collection"`
+	Index      string `json:"index"`
+	CoveredBy  string `json:"covered_by"`
+}
+
+// Recommendation type for dropping an index another index already covers
+const dropRedundantType = "DropRedundant"
+
 func main() {
+	apply := flag.Bool("apply", false, "Create the high-priority and TTL index recommendations")
+	yes := flag.Bool("yes", false, "Apply without asking for confirmation")
+	flag.Parse()
+
 	log.Println("🔍 Starting MongoDB Index Analysis...")
 
 	// Get MongoDB connection details from environment
@@ -122,8 +144,8 @@ func main() {
 
 	// Output results
 	outputFile := "mongodb_index_analysis.json"
-	if len(os.Args) > 1 {
-		outputFile = os.Args[1]
+	if flag.NArg() > 0 {
+		outputFile = flag.Arg(0)
 	}
 
 	if err := saveAnalysis(analysis, outputFile); err != nil {
@@ -134,6 +156,12 @@ func main() {
 	printSummary(analysis)
 
 	log.Printf("✅ Analysis complete. Results saved to: %s", outputFile)
+
+	if *apply {
+		if err := applyRecommendations(ctx, db, analysis, *yes); err != nil {
+			log.Fatalf("Failed to apply recommendations: %v", err)
+		}
+	}
 }
 
 func analyzeDatabase(ctx context.Context, db *mongo.Database) (*DatabaseAnalysis, error) {
@@ -220,6 +248,7 @@ func analyzeCollection(ctx context.Context, collection *mongo.Collection) (*Coll
 		}
 
 		indexInfo := parseIndexInfo(indexDoc)
+		indexInfo.KeyOrder = indexKeyOrder(cursor.Current)
 
 		// Try to get index usage stats (MongoDB 3.2+)
 		if usage := getIndexUsage(ctx, collection, indexInfo.Name); usage != nil {
@@ -264,6 +293,24 @@ func parseIndexInfo(indexDoc bson.M) IndexInfo {
 	return info
 }
 
+// indexKeyOrder returns an index's key fields in order, which decoding into bson.M loses
+func indexKeyOrder(indexDoc bson.Raw) []string {
+	keyDoc, ok := indexDoc.Lookup("key").DocumentOK()
+	if !ok {
+		return nil
+	}
+	elements, err := keyDoc.Elements()
+	if err != nil {
+		return nil
+	}
+
+	order := make([]string, 0, len(elements))
+	for _, element := range elements {
+		order = append(order, element.Key())
+	}
+	return order
+}
+
 func getIndexUsage(ctx context.Context, collection *mongo.Collection, indexName string) *IndexUsageInfo {
 	// Try to get index stats (requires appropriate permissions)
 	pipeline := mongo.Pipeline{
@@ -528,21 +575,67 @@ func generateRecommendations(analysis *CollectionAnalysis) []IndexRecommendation
 		addDeduplicationIndexRecommendations(&recommendations, existingIndexes)
 	}
 
+	recommendations = append(recommendations, findRedundantIndexes(analysis.Indexes)...)
+
 	return recommendations
 }
 
-func generateIndexRecommendation(pattern QueryPattern, existingIndexes map[string]bool) *IndexRecommendation {
-	// Extract fields from filter
-	fields := extractFieldsFromFilter(pattern.Filter)
-
-	// Add sort fields
-	if pattern.Sort != nil {
-		for field := range pattern.Sort {
-			if !contains(fields, field) {
-				fields = append(fields, field)
+// findRedundantIndexes recommends dropping indexes whose keys are a strict
+// ordered prefix of another index's keys, since the longer index serves the
+// same queries. Unique and TTL indexes are kept because they do more than
+// speed up queries, as are indexes only covered by a sparse index.
+func findRedundantIndexes(indexes []IndexInfo) []IndexRecommendation {
+	recommendations := []IndexRecommendation{}
+
+	for _, index := range indexes {
+		if index.Name == "_id_" || index.Unique || index.TTL != nil {
+			continue
+		}
+
+		for _, covering := range indexes {
+			if covering.Name == index.Name || (covering.Sparse && !index.Sparse) {
+				continue
+			}
+			if !isKeyPrefix(index, covering) {
+				continue
 			}
+
+			recommendations = append(recommendations, IndexRecommendation{
+				Type:            dropRedundantType,
+				Keys:            index.Keys,
+				KeyOrder:        index.KeyOrder,
+				IndexName:       index.Name,
+				CoveredBy:       covering.Name,
+				Reason:          fmt.Sprintf("Drop redundant index %s: it is a prefix of %s", index.Name, covering.Name),
+				Priority:        "Medium",
+				EstimatedImpact: "Medium - Faster writes and less index storage",
+			})
+			break
+		}
+	}
+
+	return recommendations
+}
+
+// isKeyPrefix reports whether index's keys, with their directions, are a
+// strict ordered prefix of other's keys
+func isKeyPrefix(index, other IndexInfo) bool {
+	if len(index.KeyOrder) == 0 || len(index.KeyOrder) >= len(other.KeyOrder) {
+		return false
+	}
+	for i, field := range index.KeyOrder {
+		if other.KeyOrder[i] != field {
+			return false
+		}
+		if fmt.Sprint(normalizeDirection(index.Keys[field])) != fmt.Sprint(normalizeDirection(other.Keys[field])) {
+			return false
 		}
 	}
+	return true
+}
+
+func generateIndexRecommendation(pattern QueryPattern, existingIndexes map[string]bool) *IndexRecommendation {
+	fields := orderPatternFields(pattern)
 
 	if len(fields) == 0 {
 		return nil
@@ -573,6 +666,7 @@ func generateIndexRecommendation(pattern QueryPattern, existingIndexes map[strin
 	return &IndexRecommendation{
 		Type:            "Compound",
 		Keys:            keys,
+		KeyOrder:        fields,
 		Reason:          fmt.Sprintf("Optimize query: %s", pattern.Description),
 		Priority:        priority,
 		EstimatedImpact: estimateImpact(pattern.Frequency, pattern.Performance),
@@ -589,6 +683,7 @@ func addSlotIndexRecommendations(recommendations *[]IndexRecommendation, existin
 				"slot_date":  1,
 				"start_time": 1,
 			},
+			KeyOrder:        []string{"venue_id", "slot_date", "start_time"},
 			Reason:          "Critical for venue-date-time slot lookups",
 			Priority:        "High",
 			EstimatedImpact: "Very High - Core booking functionality",
@@ -603,6 +698,7 @@ func addSlotIndexRecommendations(recommendations *[]IndexRecommendation, existin
 				"available":    1,
 				"last_scraped": -1,
 			},
+			KeyOrder:        []string{"available", "last_scraped"},
 			Reason:          "Optimize notification queries for new available slots",
 			Priority:        "High",
 			EstimatedImpact: "High - Notification performance",
@@ -616,6 +712,7 @@ func addSlotIndexRecommendations(recommendations *[]IndexRecommendation, existin
 			Keys: map[string]interface{}{
 				"slot_date": 1,
 			},
+			TTLSeconds:      ttlSeconds(7 * 24 * time.Hour),
 			Reason:          "Automatic cleanup of old slots",
 			Priority:        "Medium",
 			EstimatedImpact: "Medium - Reduces storage and improves performance",
@@ -632,6 +729,7 @@ func addPreferenceIndexRecommendations(recommendations *[]IndexRecommendation, e
 				"notification_settings.unsubscribed": 1,
 				"preferred_venues":                   1,
 			},
+			KeyOrder:        []string{"notification_settings.unsubscribed", "preferred_venues"},
 			Reason:          "Optimize venue-based notification targeting",
 			Priority:        "High",
 			EstimatedImpact: "High - Notification matching performance",
@@ -648,6 +746,7 @@ func addScrapingLogIndexRecommendations(recommendations *[]IndexRecommendation,
 				"venue_id":         1,
 				"scrape_timestamp": -1,
 			},
+			KeyOrder:        []string{"venue_id", "scrape_timestamp"},
 			Reason:          "Optimize venue scraping history queries",
 			Priority:        "Medium",
 			EstimatedImpact: "Medium - Monitoring and debugging",
@@ -661,6 +760,7 @@ func addScrapingLogIndexRecommendations(recommendations *[]IndexRecommendation,
 			Keys: map[string]interface{}{
 				"scrape_timestamp": 1,
 			},
+			TTLSeconds:      ttlSeconds(30 * 24 * time.Hour),
 			Reason:          "Automatic cleanup of old scraping logs",
 			Priority:        "Medium",
 			EstimatedImpact: "Medium - Storage management",
@@ -677,6 +777,7 @@ func addDeduplicationIndexRecommendations(recommendations *[]IndexRecommendation
 				"user_id":  1,
 				"slot_key": 1,
 			},
+			KeyOrder:        []string{"user_id", "slot_key"},
 			Reason:          "Critical for exact slot deduplication",
 			Priority:        "High",
 			EstimatedImpact: "Very High - Prevents duplicate notifications",
@@ -694,6 +795,7 @@ func addDeduplicationIndexRecommendations(recommendations *[]IndexRecommendation
 				"slot_start_time": 1,
 				"last_sent_at":    -1,
 			},
+			KeyOrder:        []string{"user_id", "venue_id", "court_id", "slot_start_time", "last_sent_at"},
 			Reason:          "Optimize similar notification detection",
 			Priority:        "High",
 			EstimatedImpact: "High - Reduces notification spam",
@@ -701,6 +803,74 @@ func addDeduplicationIndexRecommendations(recommendations *[]IndexRecommendation
 	}
 }
 
+// orderPatternFields orders a query pattern's fields for a compound index:
+// equality matches first, then sort fields, then range conditions
+func orderPatternFields(pattern QueryPattern) []string {
+	var equality, ranges, sortFields []string
+	for _, field := range extractFieldsFromFilter(pattern.Filter) {
+		if pattern.Sort[field] != nil {
+			continue
+		}
+		if isRangeCondition(findFieldCondition(pattern.Filter, field)) {
+			ranges = append(ranges, field)
+		} else {
+			equality = append(equality, field)
+		}
+	}
+	for field := range pattern.Sort {
+		sortFields = append(sortFields, field)
+	}
+
+	sort.Strings(equality)
+	sort.Strings(sortFields)
+	sort.Strings(ranges)
+
+	fields := []string{}
+	for _, field := range append(append(equality, sortFields...), ranges...) {
+		if !contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// findFieldCondition returns a field's condition, looking inside $or/$and clauses
+func findFieldCondition(filter map[string]interface{}, field string) interface{} {
+	if value, ok := filter[field]; ok {
+		return value
+	}
+	for _, op := range []string{"$or", "$and"} {
+		conditions, _ := filter[op].([]interface{})
+		for _, condition := range conditions {
+			if condMap, ok := condition.(map[string]interface{}); ok {
+				if value := findFieldCondition(condMap, field); value != nil {
+					return value
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// isRangeCondition reports whether a condition is an operator expression rather than an equality match
+func isRangeCondition(condition interface{}) bool {
+	operators, ok := condition.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for op := range operators {
+		if strings.HasPrefix(op, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+func ttlSeconds(d time.Duration) *int32 {
+	seconds := int32(d.Seconds())
+	return &seconds
+}
+
 func extractFieldsFromFilter(filter map[string]interface{}) []string {
 	fields := []string{}
 
@@ -768,6 +938,7 @@ func generateSummary(collections []CollectionAnalysis) AnalysisSummary {
 	summary := AnalysisSummary{
 		TotalCollections:  len(collections),
 		UnusedIndexes:     []string{},
+		RedundantIndexes:  []RedundantIndex{},
 		MissingIndexes:    []IndexRecommendation{},
 		PerformanceIssues: []string{},
 	}
@@ -784,9 +955,15 @@ func generateSummary(collections []CollectionAnalysis) AnalysisSummary {
 			}
 		}
 
-		// Collect high-priority recommendations
+		// Collect redundant indexes and high-priority recommendations
 		for _, rec := range coll.Recommendations {
-			if rec.Priority == "High" {
+			if rec.Type == dropRedundantType {
+				summary.RedundantIndexes = append(summary.RedundantIndexes, RedundantIndex{
+					Collection: coll.Name,
+					Index:      rec.IndexName,
+					CoveredBy:  rec.CoveredBy,
+				})
+			} else if rec.Priority == "High" {
 				summary.MissingIndexes = append(summary.MissingIndexes, rec)
 			}
 		}
@@ -830,6 +1007,13 @@ func printSummary(analysis *DatabaseAnalysis) {
 		}
 	}
 
+	if len(analysis.Summary.RedundantIndexes) > 0 {
+		fmt.Printf("\n♻️  REDUNDANT INDEXES (%d):\n", len(analysis.Summary.RedundantIndexes))
+		for _, index := range analysis.Summary.RedundantIndexes {
+			fmt.Printf("   - %s.%s (covered by %s)\n", index.Collection, index.Index, index.CoveredBy)
+		}
+	}
+
 	if len(analysis.Summary.MissingIndexes) > 0 {
 		fmt.Printf("\n🚀 HIGH-PRIORITY INDEX RECOMMENDATIONS (%d):\n", len(analysis.Summary.MissingIndexes))
 		for _, rec := range analysis.Summary.MissingIndexes {
@@ -889,3 +1073,188 @@ func formatNumber(num int64) string {
 		return fmt.Sprintf("%.1fM", float64(num)/1000000)
 	}
 }
+
+// pendingIndex is a recommendation selected for --apply
+type pendingIndex struct {
+	Collection     string
+	Recommendation IndexRecommendation
+}
+
+// existingIndex is an index as listed by the server, with its keys in order
+type existingIndex struct {
+	Name string `bson:"name"`
+	Key  bson.D `bson:"key"`
+}
+
+// applyRecommendations creates the high-priority and TTL recommendations that
+// don't exist yet. Indexes are matched on their ordered keys, so re-running is safe.
+func applyRecommendations(ctx context.Context, db *mongo.Database, analysis *DatabaseAnalysis, skipConfirm bool) error {
+	pending := selectRecommendations(analysis)
+	if len(pending) == 0 {
+		log.Println("✅ No high-priority or TTL index recommendations to apply")
+		return nil
+	}
+
+	fmt.Printf("\n🛠️  INDEXES TO CREATE (%d):\n", len(pending))
+	for _, p := range pending {
+		fmt.Printf("   - %s %s%s: %s\n", p.Collection, formatOrderedKeys(orderedKeys(p.Recommendation)),
+			formatTTL(p.Recommendation.TTLSeconds), p.Recommendation.Reason)
+	}
+
+	if !skipConfirm && !confirm("Create these indexes?") {
+		log.Println("Aborted, no indexes created")
+		return nil
+	}
+
+	created, skipped := 0, 0
+	for _, p := range pending {
+		collection := db.Collection(p.Collection)
+		keys := orderedKeys(p.Recommendation)
+
+		existing, err := listIndexes(ctx, collection)
+		if err != nil {
+			return fmt.Errorf("failed to list indexes on %s: %w", p.Collection, err)
+		}
+		if name, ok := findIndex(existing, keys); ok {
+			log.Printf("   ℹ️ %s.%s already covers %s, skipping", p.Collection, name, formatOrderedKeys(keys))
+			skipped++
+			continue
+		}
+
+		opts := options.Index()
+		if p.Recommendation.TTLSeconds != nil {
+			opts.SetExpireAfterSeconds(*p.Recommendation.TTLSeconds)
+		}
+
+		name, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: keys, Options: opts})
+		if err != nil {
+			return fmt.Errorf("failed to create index %s on %s: %w", formatOrderedKeys(keys), p.Collection, err)
+		}
+		log.Printf("   ✅ Created %s.%s%s", p.Collection, name, formatTTL(p.Recommendation.TTLSeconds))
+		created++
+	}
+
+	log.Printf("✅ Applied recommendations: %d created, %d already existed", created, skipped)
+	return nil
+}
+
+// selectRecommendations returns each collection's high-priority and TTL
+// recommendations, dropping ones that repeat the same keys
+func selectRecommendations(analysis *DatabaseAnalysis) []pendingIndex {
+	pending := []pendingIndex{}
+	seen := make(map[string]bool)
+
+	for _, coll := range analysis.Collections {
+		for _, rec := range coll.Recommendations {
+			if rec.Type == dropRedundantType || (rec.Priority != "High" && rec.Type != "TTL") {
+				continue
+			}
+
+			key := coll.Name + ":" + formatOrderedKeys(orderedKeys(rec))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			pending = append(pending, pendingIndex{Collection: coll.Name, Recommendation: rec})
+		}
+	}
+
+	return pending
+}
+
+// orderedKeys returns a recommendation's index keys in KeyOrder, falling back
+// to alphabetical order for keys KeyOrder doesn't list
+func orderedKeys(rec IndexRecommendation) bson.D {
+	keys := bson.D{}
+	for _, field := range rec.KeyOrder {
+		if value, ok := rec.Keys[field]; ok {
+			keys = append(keys, bson.E{Key: field, Value: normalizeDirection(value)})
+		}
+	}
+
+	var rest []string
+	for field := range rec.Keys {
+		if !contains(rec.KeyOrder, field) {
+			rest = append(rest, field)
+		}
+	}
+	sort.Strings(rest)
+	for _, field := range rest {
+		keys = append(keys, bson.E{Key: field, Value: normalizeDirection(rec.Keys[field])})
+	}
+
+	return keys
+}
+
+// normalizeDirection turns the numeric key directions from a recommendation
+// (ints, or float64 after a JSON round trip) into an int32
+func normalizeDirection(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return int32(v)
+	case int32:
+		return v
+	case int64:
+		return int32(v)
+	case float64:
+		return int32(v)
+	default:
+		return value // e.g. "2dsphere" or "text"
+	}
+}
+
+func listIndexes(ctx context.Context, collection *mongo.Collection) ([]existingIndex, error) {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var indexes []existingIndex
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, err
+	}
+	return indexes, nil
+}
+
+// findIndex returns the name of an existing index with exactly the given keys
+func findIndex(indexes []existingIndex, keys bson.D) (string, bool) {
+	want := formatOrderedKeys(keys)
+	for _, index := range indexes {
+		normalized := bson.D{}
+		for _, e := range index.Key {
+			normalized = append(normalized, bson.E{Key: e.Key, Value: normalizeDirection(e.Value)})
+		}
+		if formatOrderedKeys(normalized) == want {
+			return index.Name, true
+		}
+	}
+	return "", false
+}
+
+func formatOrderedKeys(keys bson.D) string {
+	var parts []string
+	for _, e := range keys {
+		parts = append(parts, fmt.Sprintf("%s: %v", e.Key, e.Value))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func formatTTL(seconds *int32) string {
+	if seconds == nil {
+		return ""
+	}
+	return fmt.Sprintf(" (TTL %ds)", *seconds)
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("\n%s [y/N]: ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
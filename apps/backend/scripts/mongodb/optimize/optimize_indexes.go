@@ -153,7 +153,8 @@ func applyOptimizations(ctx context.Context, db *mongo.Database, analysis *Datab
 
 		for _, priority := range priorities {
 			for _, rec := range collAnalysis.Recommendations {
-				if rec.Priority != priority {
+				// Redundant index findings are for review, not indexes to create
+				if rec.Priority != priority || rec.Type == "DropRedundant" {
 					continue
 				}
 
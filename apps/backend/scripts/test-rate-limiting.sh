@@ -22,7 +22,7 @@ echo ""
 # Test 1: Basic load test
 echo "📊 Test 1: Basic Load Test (Health Endpoint)"
 echo "--------------------------------------------"
-go run scripts/load-test/main.go \
+go run ./scripts/load-test \
     -endpoint=/health \
     -requests=50 \
     -concurrent=5 \
@@ -33,7 +33,7 @@ echo ""
 # Test 2: Rate limit test
 echo "🚫 Test 2: Rate Limit Test (Auth Endpoint)"
 echo "------------------------------------------"
-go run scripts/load-test/main.go \
+go run ./scripts/load-test \
     -endpoint=/login \
     -method=POST \
     -body='{"username":"testuser","password":"testpass"}' \
@@ -47,7 +47,7 @@ echo ""
 # Test 3: Burst traffic test
 echo "💥 Test 3: Burst Traffic Test"
 echo "-----------------------------"
-go run scripts/load-test/main.go \
+go run ./scripts/load-test \
     -endpoint=/health \
     -requests=30 \
     -concurrent=30 \
@@ -58,7 +58,7 @@ echo ""
 # Test 4: Duration-based test
 echo "⏱️  Test 4: Duration-Based Test (30 seconds)"
 echo "--------------------------------------------"
-go run scripts/load-test/main.go \
+go run ./scripts/load-test \
     -endpoint=/health \
     -duration=30s \
     -concurrent=5 \
@@ -5,6 +5,7 @@ import { Button } from '@/components/ui/button'
 import { Skeleton } from '@/components/ui/skeleton'
 import { useNavigate } from 'react-router-dom'
 import { userApi } from '@/services/userApi'
+import { courtApi } from '@/services/courtApi'
 import { useAppStore } from '@/stores/appStore'
 import { 
   Settings, 
@@ -21,6 +22,7 @@ export default function UserPreferencesCard() {
   const { userPreferences, setUserPreferences, addNotification } = useAppStore()
   const [isLoading, setIsLoading] = useState(true)
   const [error, setError] = useState<string | null>(null)
+  const [venueNames, setVenueNames] = useState<Record<string, string>>({})
 
   useEffect(() => {
     const loadPreferences = async () => {
@@ -48,6 +50,13 @@ export default function UserPreferencesCard() {
     loadPreferences()
   }, [setUserPreferences, addNotification])
 
+  // Preferences hold venue IDs; look up names to show instead
+  useEffect(() => {
+    courtApi.getVenues()
+      .then(venues => setVenueNames(Object.fromEntries(venues.map(venue => [venue.id, venue.name]))))
+      .catch(error => console.warn('⚠️ UserPreferencesCard: Failed to load venue names:', error))
+  }, [])
+
   const formatTimeSlots = (timeSlots?: Array<{ start: string; end: string }>) => {
     if (!timeSlots || timeSlots.length === 0) return 'None set'
     return timeSlots.map(slot => `${slot.start} - ${slot.end}`).join(', ')
@@ -69,7 +78,7 @@ export default function UserPreferencesCard() {
 
   const formatVenues = (venues?: string[]) => {
     if (!venues || venues.length === 0) return 'None selected'
-    return venues.join(', ')
+    return venues.map(venue => venueNames[venue] || venue).join(', ')
   }
 
   if (isLoading) {
@@ -20,6 +20,7 @@ import {
 import { useNavigate } from 'react-router-dom'
 import { useAppStore } from '@/stores/appStore'
 import { userApi } from '@/services/userApi'
+import type { Venue } from '@/services/courtApi'
 import { 
   ArrowLeft,
   Settings2, 
@@ -69,7 +70,7 @@ const Settings: React.FC = () => {
   
   const [isLoading, setIsLoading] = useState(false)
   const [isSaving, setIsSaving] = useState(false)
-  const [availableVenues, setAvailableVenues] = useState<string[]>([])
+  const [availableVenues, setAvailableVenues] = useState<Venue[]>([])
 
   const form = useForm<UserPreferencesForm>({
     resolver: zodResolver(userPreferencesSchema),
@@ -105,35 +106,32 @@ const Settings: React.FC = () => {
         console.log('📋 Settings: Loaded user preferences:', prefs)
         setUserPreferences(prefs)
         
-        // Load real venues from API
+        // Load real venues from API. Preferences store venue IDs, so there's
+        // no useful fallback list if this fails.
         console.log('🏟️ Settings: Loading available venues from API...')
+        let venueIds: Set<string> | null = null
         try {
           const { courtApi } = await import('@/services/courtApi')
           const venues = await courtApi.getVenues()
           console.log('🏟️ Settings: Loaded venues from API:', venues)
-          const venueNames = venues.map(venue => venue.name)
-          setAvailableVenues(venueNames)
-          console.log('✅ Settings: Available venues set:', venueNames)
+          setAvailableVenues(venues)
+          if (venues.length > 0) {
+            venueIds = new Set(venues.map(venue => venue.id))
+          }
         } catch (venueError) {
-          console.warn('⚠️ Settings: Failed to load venues from API, using fallback:', venueError)
-          // Fallback to hardcoded venues if API fails
-          const fallbackVenues = [
-            'Victoria Park Tennis Centre',
-            'Stratford Park Tennis Club', 
-            'Ropemakers Field Tennis Courts',
-            'Tennis Club Central',
-            'Riverside Tennis Club',
-            'Elite Tennis Academy',
-            'Community Recreation Center'
-          ]
-          setAvailableVenues(fallbackVenues)
-          console.log('🔄 Settings: Using fallback venues:', fallbackVenues)
+          console.warn('⚠️ Settings: Failed to load venues from API:', venueError)
+          setAvailableVenues([])
         }
-        
+
+        // Drop entries that aren't current venue IDs (e.g. unmigrated names),
+        // which the API would reject on save
+        const knownVenues = (venues?: string[]) =>
+          (venues || []).filter(venue => venueIds === null || venueIds.has(venue))
+
         // Update form with loaded preferences
         const formData = {
-          preferredVenues: prefs.preferredVenues || [],
-          excludedVenues: prefs.excludedVenues || [],
+          preferredVenues: knownVenues(prefs.preferredVenues),
+          excludedVenues: knownVenues(prefs.excludedVenues),
           weekdayTimes: prefs.weekdayTimes || prefs.times || [{ start: '18:00', end: '20:00' }],
           weekendTimes: prefs.weekendTimes || prefs.times || [{ start: '09:00', end: '11:00' }],
           preferredDays: prefs.preferredDays || ['monday', 'tuesday', 'wednesday', 'thursday', 'friday'],
@@ -314,20 +312,20 @@ const Settings: React.FC = () => {
                       <FormControl>
                         <div className="grid grid-cols-1 sm:grid-cols-2 gap-3">
                           {availableVenues.map((venue) => (
-                            <div key={venue} className="flex items-center space-x-2">
+                            <div key={venue.id} className="flex items-center space-x-2">
                               <Checkbox
-                                id={venue}
-                                checked={field.value?.includes(venue) || false}
+                                id={venue.id}
+                                checked={field.value?.includes(venue.id) || false}
                                 onCheckedChange={(checked) => {
                                   if (checked) {
-                                    field.onChange([...(field.value || []), venue])
+                                    field.onChange([...(field.value || []), venue.id])
                                   } else {
-                                    field.onChange((field.value || []).filter((v) => v !== venue))
+                                    field.onChange((field.value || []).filter((v) => v !== venue.id))
                                   }
                                 }}
                               />
-                              <label htmlFor={venue} className="text-sm font-medium cursor-pointer">
-                                {venue}
+                              <label htmlFor={venue.id} className="text-sm font-medium cursor-pointer">
+                                {venue.name}
                               </label>
                             </div>
                           ))}
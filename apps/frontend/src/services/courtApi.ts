@@ -200,7 +200,7 @@ export const courtApi = {
       
       const url = `/api/courts${params.toString() ? `?${params.toString()}` : ''}`
       const response = await courtApiClient.get(url)
-      return response.data || []
+      return response.data?.slots || []
     } catch (error) {
       handleCourtError(error as AxiosError)
       return []
@@ -240,7 +240,7 @@ export const courtApi = {
         activeCourts: Math.max(0, (backendStats.totalCourtSlots || 0) - (backendStats.availableSlots || 0)), // Calculate active courts
         availableSlots: backendStats.availableSlots || 0,
         systemStatus: 'RUNNING' as const,
-        lastUpdate: new Date(),
+        lastUpdate: backendStats.generatedAt ? new Date(backendStats.generatedAt) : new Date(),
       }
 
 
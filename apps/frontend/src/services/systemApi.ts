@@ -192,7 +192,7 @@ export const systemApi = {
   async getScrapingLogs(limit: number = 50): Promise<ScrapingLog[]> {
     try {
       const response = await apiClient.get(`/api/system/logs?limit=${limit}`)
-      return response.data || []
+      return response.data?.logs || []
     } catch (error) {
       console.error('Failed to fetch scraping logs:', error)
       return []
@@ -45,8 +45,8 @@ export interface UserPreferences {
   times?: TimeRange[]           // Legacy field for backward compatibility
   weekdayTimes?: TimeRange[]    // Monday-Friday preferred times
   weekendTimes?: TimeRange[]    // Saturday-Sunday preferred times
-  preferredVenues: string[]
-  excludedVenues: string[]
+  preferredVenues: string[]    // Venue IDs
+  excludedVenues: string[]     // Venue IDs
   preferredDays: string[]
   maxPrice: number
   notificationSettings: NotificationSettings
@@ -145,7 +145,7 @@ const defaultUserPreferences: UserPreferences = {
   weekendTimes: [
     { start: '09:00', end: '11:00' }
   ],
-  preferredVenues: ['Tennis Club Central', 'Riverside Tennis Club'],
+  preferredVenues: [],
   excludedVenues: [],
   preferredDays: ['monday', 'tuesday', 'wednesday', 'thursday', 'friday'],
   maxPrice: 100.0,
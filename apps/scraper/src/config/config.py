@@ -118,6 +118,8 @@ class Config:
             'CLUBSPARK_BASE_URL': ['scraper', 'platforms', 'clubspark', 'baseUrl'],
             'COURTSIDES_ENABLED': ['scraper', 'platforms', 'courtsides', 'enabled'],
             'COURTSIDES_BASE_URL': ['scraper', 'platforms', 'courtsides', 'baseUrl'],
+            'PLAYTOMIC_ENABLED': ['scraper', 'platforms', 'playtomic', 'enabled'],
+            'PLAYTOMIC_BASE_URL': ['scraper', 'platforms', 'playtomic', 'baseUrl'],
         }
         
         for env_var, config_path in env_mappings.items():
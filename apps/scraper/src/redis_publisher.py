@@ -3,7 +3,50 @@ import json
 import logging
 import os
 from typing import Dict, Any, Optional
-from datetime import datetime
+from datetime import datetime, timezone
+
+# Shared with the backend's AvailabilityPublisher (internal/redis/availability_publisher.go)
+SLOT_QUEUE = 'court_slots'
+AVAILABILITY_CHANNEL = 'court:availability'
+AVAILABILITY_SCHEMA_VERSION = 1
+
+
+def availability_event(slot_data: Dict[str, Any]) -> Dict[str, Any]:
+    """
+    Convert a scraped slot into a court availability event, the versioned
+    JSON document both the notification service and live subscribers read
+
+    Args:
+        slot_data: Dictionary containing slot information, keyed as publish_slot expects
+
+    Returns:
+        Dict: The event, with snake_case keys and a schema_version
+    """
+    discovered_at = slot_data.get('scrapedAt')
+    if isinstance(discovered_at, datetime):
+        discovered_at = discovered_at.strftime('%Y-%m-%dT%H:%M:%SZ')
+    if not discovered_at:
+        discovered_at = datetime.now(timezone.utc).strftime('%Y-%m-%dT%H:%M:%SZ')
+
+    event = {
+        'schema_version': AVAILABILITY_SCHEMA_VERSION,
+        'venue_id': slot_data['venueId'],
+        'venue_name': slot_data['venueName'],
+        'court_id': slot_data['courtId'],
+        'court_name': slot_data['courtName'],
+        'date': slot_data['date'],
+        'start_time': slot_data['startTime'],
+        'end_time': slot_data['endTime'],
+        'price': slot_data['price'],
+        'currency': slot_data.get('currency', ''),
+        'booking_url': slot_data['bookingUrl'],
+        'available': bool(slot_data['isAvailable']),
+        'discovered_at': discovered_at,
+    }
+    if slot_data.get('platform'):
+        event['platform'] = slot_data['platform']
+    return event
+
 
 class RedisPublisher:
     """Redis publisher for sending slot notifications to the notification service"""
@@ -23,7 +66,8 @@ class RedisPublisher:
         self.redis_password = redis_password
         self.redis_db = redis_db
         self.client = None
-        self.queue_name = 'court_slots'
+        self.queue_name = SLOT_QUEUE
+        self.channel = AVAILABILITY_CHANNEL
         self.logger = logging.getLogger(__name__)
         
     def connect(self):
@@ -62,12 +106,14 @@ class RedisPublisher:
                 self.logger.error(f"❌ Failed to connect to Redis: {e2}")
                 return False
     
-    def publish_slot(self, slot_data: Dict[str, Any]) -> bool:
+    def publish_availability(self, event: Dict[str, Any]) -> bool:
         """
-        Publish a slot notification to Redis queue
+        Publish a court availability event to the notification service's queue
+        and the live availability channel in one MULTI/EXEC, so neither misses
+        an event the other saw. Mirrors the backend's PublishAvailability.
         
         Args:
-            slot_data: Dictionary containing slot information
+            event: Court availability event, as built by availability_event
             
         Returns:
             bool: True if successful, False otherwise
@@ -77,35 +123,43 @@ class RedisPublisher:
                 return False
                 
         try:
-            # Ensure required fields are present
-            required_fields = ['venueId', 'venueName', 'courtId', 'courtName', 
-                             'date', 'startTime', 'endTime', 'price', 'isAvailable', 'bookingUrl']
-            
-            for field in required_fields:
-                if field not in slot_data:
-                    self.logger.warning(f"Missing required field '{field}' in slot data")
-                    return False
-            
-            # Add timestamp if not present
-            if 'scrapedAt' not in slot_data:
-                slot_data['scrapedAt'] = datetime.now().isoformat()
+            event.setdefault('schema_version', AVAILABILITY_SCHEMA_VERSION)
+            event_json = json.dumps(event, default=str)
             
-            # Convert to JSON
-            slot_json = json.dumps(slot_data, default=str)
-            
-            # Push to Redis queue
-            result = self.client.lpush(self.queue_name, slot_json)
-            
-            if result:
-                self.logger.info(f"Published slot notification: {slot_data['venueName']} - {slot_data['courtName']} on {slot_data['date']} at {slot_data['startTime']}")
-                return True
-            else:
-                self.logger.error("Failed to push to Redis queue")
-                return False
+            pipe = self.client.pipeline(transaction=True)
+            pipe.lpush(self.queue_name, event_json)
+            pipe.publish(self.channel, event_json)
+            pipe.execute()
+            return True
                 
         except Exception as e:
-            self.logger.error(f"Error publishing slot notification: {e}")
+            self.logger.error(f"Error publishing availability event: {e}")
+            return False
+    
+    def publish_slot(self, slot_data: Dict[str, Any]) -> bool:
+        """
+        Publish a slot notification as a court availability event
+        
+        Args:
+            slot_data: Dictionary containing slot information
+            
+        Returns:
+            bool: True if successful, False otherwise
+        """
+        # Ensure required fields are present
+        required_fields = ['venueId', 'venueName', 'courtId', 'courtName', 
+                         'date', 'startTime', 'endTime', 'price', 'isAvailable', 'bookingUrl']
+        
+        for field in required_fields:
+            if field not in slot_data:
+                self.logger.warning(f"Missing required field '{field}' in slot data")
+                return False
+        
+        if not self.publish_availability(availability_event(slot_data)):
             return False
+            
+        self.logger.info(f"Published slot notification: {slot_data['venueName']} - {slot_data['courtName']} on {slot_data['date']} at {slot_data['startTime']}")
+        return True
     
     def publish_new_slots(self, new_slots: list) -> int:
         """
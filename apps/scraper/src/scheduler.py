@@ -17,12 +17,22 @@ from typing import Optional
 # Handle imports for both module and script execution
 try:
     from .scrapers.scraper_orchestrator import ScraperOrchestrator
+    from .scrapers.scrape_tasks import ScrapeTaskTracker
+    from .scrapers.scrape_tester import ScrapeTestWorker
+    from .scrapers.adaptive_schedule import (
+        SCHEDULE_MODE_ADAPTIVE, AdaptiveConfig, AdaptiveSchedule, schedule_mode_from_env,
+    )
 except ImportError:
     # Fallback for when running as script
     current_dir = os.path.dirname(os.path.abspath(__file__))
     sys.path.insert(0, current_dir)
     
     from scrapers.scraper_orchestrator import ScraperOrchestrator
+    from scrapers.scrape_tasks import ScrapeTaskTracker
+    from scrapers.scrape_tester import ScrapeTestWorker
+    from scrapers.adaptive_schedule import (
+        SCHEDULE_MODE_ADAPTIVE, AdaptiveConfig, AdaptiveSchedule, schedule_mode_from_env,
+    )
 
 class ScrapingScheduler:
     """Scheduler for periodic scraping operations"""
@@ -31,6 +41,7 @@ class ScrapingScheduler:
         self.setup_logging()
         self.running = False
         self.next_run_time: Optional[datetime] = None
+        self.test_worker: Optional[ScrapeTestWorker] = None
         
         # Get interval from environment (in minutes) - handle both minute and second formats
         interval_env = os.getenv("SCRAPER_INTERVAL_MINUTES", os.getenv("SCRAPER_INTERVAL", "30"))
@@ -51,7 +62,19 @@ class ScrapingScheduler:
         except (ValueError, TypeError):
             self.interval_minutes = 30  # Default fallback
         
-        self.logger.info(f"Scraping scheduler initialized with {self.interval_minutes}-minute intervals")
+        # Venues and courts can set their own intervals; this one is the fallback.
+        # In adaptive mode venue intervals follow how often each venue has availability.
+        self.mode = schedule_mode_from_env()
+        self.adaptive: Optional[AdaptiveSchedule] = None
+        if self.mode == SCHEDULE_MODE_ADAPTIVE:
+            self.adaptive = AdaptiveSchedule(AdaptiveConfig.from_env())
+        self.tracker = ScrapeTaskTracker(self.interval_minutes, adaptive=self.adaptive)
+        
+        self.logger.info(f"Scraping scheduler initialized with {self.interval_minutes}-minute intervals ({self.mode} mode)")
+        if self.adaptive is not None:
+            config = self.adaptive.config
+            self.logger.info(f"Adaptive intervals between {config.min_interval} and {config.max_interval} minutes, "
+                             f"recomputed every {config.recompute_minutes} minutes from the last {config.lookback_hours} hours")
         
     def setup_logging(self):
         """Configure logging for the scheduler"""
@@ -68,8 +91,15 @@ class ScrapingScheduler:
         self.logger = logging.getLogger(__name__)
         
     def calculate_next_run_time(self) -> datetime:
-        """Calculate the next scheduled run time"""
-        return datetime.now() + timedelta(minutes=self.interval_minutes)
+        """
+        Calculate the next scheduled run time: when the next scrape task is due,
+        but no later than the default interval so new venues are picked up
+        """
+        fallback = datetime.now() + timedelta(minutes=self.interval_minutes)
+        next_due = self.tracker.next_due_time()
+        if next_due is None:
+            return fallback
+        return min(next_due, fallback)
         
     async def run_scraping_session(self):
         """Run a single scraping session"""
@@ -80,7 +110,7 @@ class ScrapingScheduler:
             
             # Create orchestrator and run scraping
             orchestrator = ScraperOrchestrator()
-            results = await orchestrator.run_scraping_session()
+            results = await orchestrator.run_scraping_session(tracker=self.tracker)
             
             session_duration = time.time() - session_start
             
@@ -99,10 +129,20 @@ class ScrapingScheduler:
             self.logger.error(f"❌ Scraping session failed: {e}")
             raise
             
+    def start_test_worker(self):
+        """Serve admin test scrapes from Redis alongside the schedule"""
+        orchestrator = ScraperOrchestrator()
+        if not orchestrator.redis_publisher.connect():
+            self.logger.warning("⚠️ Redis unavailable, test scrapes disabled")
+            return
+        self.test_worker = ScrapeTestWorker(orchestrator.redis_publisher.client, orchestrator.scrapers)
+        asyncio.create_task(self.test_worker.run())
+        
     async def start_scheduler(self):
         """Start the periodic scraping scheduler"""
         self.running = True
         self.logger.info(f"🕐 Starting scraping scheduler (interval: {self.interval_minutes} minutes)")
+        self.start_test_worker()
         
         # Run initial scraping session immediately
         self.logger.info("🎯 Running initial scraping session...")
@@ -140,15 +180,22 @@ class ScrapingScheduler:
         """Stop the scheduler gracefully"""
         self.logger.info("🛑 Stopping scraping scheduler...")
         self.running = False
+        if self.test_worker is not None:
+            self.test_worker.stop()
         
     def get_status(self) -> dict:
         """Get current scheduler status"""
-        return {
+        status = {
             "running": self.running,
+            "mode": self.mode,
             "interval_minutes": self.interval_minutes,
             "next_run_time": self.next_run_time.isoformat() if self.next_run_time else None,
             "time_until_next_run": str(self.next_run_time - datetime.now()) if self.next_run_time else None
         }
+        if self.adaptive is not None:
+            status["adaptive"] = self.adaptive.status()
+        status["queue"] = self.tracker.queue_status(datetime.now())
+        return status
 
 # Global scheduler instance
 scheduler = None
@@ -4,11 +4,13 @@ Tennis court scrapers package.
 This package contains platform-specific scrapers for different tennis booking systems:
 - CourtsideScraper: For Courtside platform (Victoria Park, Ropemakers Field)
 - ClubSparkScraper: For ClubSpark platform (Stratford Park)
+- PlaytomicScraper: For clubs listed on Playtomic
 """
 
 from .base_scraper import BaseScraper, ScrapedSlot, ScrapingResult
 from .courtside_scraper import CourtsideScraper
 from .clubspark_scraper import ClubSparkScraper
+from .playtomic_scraper import PlaytomicScraper
 from .scraper_orchestrator import ScraperOrchestrator
 
 __all__ = [
@@ -17,5 +19,6 @@ __all__ = [
     'ScrapingResult',
     'CourtsideScraper',
     'ClubSparkScraper',
+    'PlaytomicScraper',
     'ScraperOrchestrator'
 ] 
\ No newline at end of file
@@ -0,0 +1,153 @@
+#!/usr/bin/env python3
+
+"""
+Adaptive scrape intervals.
+
+In adaptive mode the scheduler looks at each venue's recent scraping_logs and
+scrapes venues that often turn up availability more frequently than venues
+that rarely do. A venue's hit rate is the share of its recent scrapes that
+found at least one slot; it maps linearly onto an interval between the
+configured maximum (never finds anything) and minimum (always finds
+something). Venues without enough recent scrapes keep their configured
+interval, and courts with their own scrape_interval_minutes are left alone.
+"""
+
+import logging
+import os
+from dataclasses import dataclass
+from datetime import datetime, timedelta
+from typing import Any, Dict, Iterable, List, Optional
+
+SCHEDULE_MODE_FIXED = 'fixed'
+SCHEDULE_MODE_ADAPTIVE = 'adaptive'
+
+
+def _env_int(name: str, default: int) -> int:
+    """Read a positive integer from the environment, falling back to default"""
+    try:
+        value = int(os.getenv(name, default))
+    except (TypeError, ValueError):
+        return default
+    return value if value > 0 else default
+
+
+@dataclass(frozen=True)
+class AdaptiveConfig:
+    """Bounds and timing for adaptive intervals"""
+    min_interval: int = 10  # Minutes, for venues where every scrape finds slots
+    max_interval: int = 120  # Minutes, for venues where no scrape finds slots
+    lookback_hours: int = 24  # How much scrape history the hit rate covers
+    recompute_minutes: int = 60  # How often intervals are recalculated
+    min_samples: int = 3  # Scrapes needed before a venue's interval is adjusted
+
+    @classmethod
+    def from_env(cls) -> 'AdaptiveConfig':
+        """Read the SCRAPER_ADAPTIVE_* settings, swapping the bounds if they're reversed"""
+        defaults = cls()
+        low = _env_int('SCRAPER_ADAPTIVE_MIN_INTERVAL', defaults.min_interval)
+        high = _env_int('SCRAPER_ADAPTIVE_MAX_INTERVAL', defaults.max_interval)
+        return cls(
+            min_interval=min(low, high),
+            max_interval=max(low, high),
+            lookback_hours=_env_int('SCRAPER_ADAPTIVE_LOOKBACK_HOURS', defaults.lookback_hours),
+            recompute_minutes=_env_int('SCRAPER_ADAPTIVE_RECOMPUTE_MINUTES', defaults.recompute_minutes),
+            min_samples=_env_int('SCRAPER_ADAPTIVE_MIN_SAMPLES', defaults.min_samples),
+        )
+
+
+def schedule_mode_from_env() -> str:
+    """Return SCRAPER_SCHEDULE_MODE, defaulting to fixed intervals"""
+    mode = os.getenv('SCRAPER_SCHEDULE_MODE', SCHEDULE_MODE_FIXED).strip().lower()
+    return mode if mode in (SCHEDULE_MODE_FIXED, SCHEDULE_MODE_ADAPTIVE) else SCHEDULE_MODE_FIXED
+
+
+def interval_for_hit_rate(hit_rate: float, config: AdaptiveConfig) -> int:
+    """Map a hit rate between 0 and 1 onto the configured interval range"""
+    hit_rate = min(max(hit_rate, 0.0), 1.0)
+    span = config.max_interval - config.min_interval
+    return round(config.max_interval - span * hit_rate)
+
+
+# A scraping log's slots_found as a number. It is a count in logs written by
+# the orchestrator, but a list of slots in logs written by the backend.
+SLOTS_FOUND_COUNT = {'$cond': [
+    {'$isArray': '$slots_found'},
+    {'$size': '$slots_found'},
+    {'$ifNull': ['$slots_found', 0]},
+]}
+
+
+def hit_rate_pipeline(since: datetime) -> List[Dict[str, Any]]:
+    """Count each venue's scrapes since a time and how many found slots"""
+    return [
+        {'$match': {'scrape_timestamp': {'$gte': since}}},
+        {'$group': {
+            '_id': '$venue_id',
+            'scrapes': {'$sum': 1},
+            'hits': {'$sum': {'$cond': [{'$gt': [SLOTS_FOUND_COUNT, 0]}, 1, 0]}},
+        }},
+    ]
+
+
+class AdaptiveSchedule:
+    """Per-venue intervals derived from recent hit rates, recomputed periodically"""
+
+    def __init__(self, config: AdaptiveConfig):
+        self.config = config
+        self.intervals: Dict[str, int] = {}
+        self.hit_rates: Dict[str, float] = {}
+        self.computed_at: Optional[datetime] = None
+        self.logger = logging.getLogger(__name__)
+
+    def is_stale(self, now: datetime) -> bool:
+        """Report whether the intervals are due to be recomputed"""
+        return self.computed_at is None or now >= self.computed_at + timedelta(minutes=self.config.recompute_minutes)
+
+    def interval_for(self, venue_id: str) -> Optional[int]:
+        """Return a venue's adaptive interval, or None to keep its configured one"""
+        return self.intervals.get(venue_id)
+
+    def update(self, stats: Iterable[Dict[str, Any]], now: datetime):
+        """Recompute intervals from per-venue scrape counts, as produced by hit_rate_pipeline"""
+        intervals: Dict[str, int] = {}
+        hit_rates: Dict[str, float] = {}
+        for venue in stats:
+            scrapes = venue.get('scrapes') or 0
+            if venue.get('_id') is None or scrapes < self.config.min_samples:
+                continue
+            venue_id = str(venue['_id'])
+            hit_rates[venue_id] = (venue.get('hits') or 0) / scrapes
+            intervals[venue_id] = interval_for_hit_rate(hit_rates[venue_id], self.config)
+
+        self.intervals = intervals
+        self.hit_rates = hit_rates
+        self.computed_at = now
+
+    def refresh(self, db, now: datetime):
+        """
+        Recompute intervals from scraping_logs. On failure the previous
+        intervals stay in place and the next refresh is tried after the
+        usual recompute period.
+        """
+        since = now - timedelta(hours=self.config.lookback_hours)
+        try:
+            stats = list(db.scraping_logs.aggregate(hit_rate_pipeline(since)))
+        except Exception as e:
+            self.logger.error(f"Failed to compute adaptive scrape intervals, keeping previous ones: {e}")
+            self.computed_at = now
+            return
+
+        self.update(stats, now)
+        self.logger.info(f"Adaptive intervals recomputed for {len(self.intervals)} venues "
+                         f"(bounds {self.config.min_interval}-{self.config.max_interval} minutes)")
+        for venue_id, interval in sorted(self.intervals.items()):
+            self.logger.debug(f"Venue {venue_id}: hit rate {self.hit_rates[venue_id]:.0%}, every {interval} minutes")
+
+    def status(self) -> Dict[str, Any]:
+        """Describe the current intervals for the scheduler's status"""
+        return {
+            "min_interval": self.config.min_interval,
+            "max_interval": self.config.max_interval,
+            "computed_at": self.computed_at.isoformat() if self.computed_at else None,
+            "venue_intervals": dict(self.intervals),
+        }
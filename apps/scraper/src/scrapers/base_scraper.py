@@ -5,12 +5,30 @@ Base scraper class for tennis court availability scraping.
 Defines the interface that platform-specific scrapers must implement.
 """
 
+import asyncio
 import logging
+import random
 import time
 from abc import ABC, abstractmethod
 from dataclasses import dataclass
 from typing import List, Dict, Any, Optional
-from datetime import datetime, timedelta
+from datetime import date, datetime, timedelta
+from zoneinfo import ZoneInfo
+
+from .proxy_pool import Proxy, ProxyPool, venue_uses_proxy
+
+DEFAULT_TIMEOUT_SECONDS = 30
+DEFAULT_RETRY_BACKOFF_SECONDS = 2  # Multiplied by the attempt number
+
+# Current desktop browsers, one of which is used when a venue doesn't set
+# scraper_config.user_agent; some booking sites block unfamiliar agents
+BROWSER_USER_AGENTS = [
+    'Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36',
+    'Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36',
+    'Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Safari/605.1.15',
+    'Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:127.0) Gecko/20100101 Firefox/127.0',
+    'Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.0.0 Safari/537.36 Edg/126.0.0.0',
+]
 
 @dataclass
 class ScrapedSlot:
@@ -43,11 +61,44 @@ class ScrapingResult:
     errors: List[str]
     duration_ms: int
     scraped_at: datetime
+    user_agent: Optional[str] = None  # Recorded in the scraping log for debugging blocks
+    attempts: int = 1  # Including retries after transient failures
     
+def bookable_dates(target_dates: List[str], venue_config: Dict[str, Any],
+                   today: Optional[date] = None) -> List[str]:
+    """
+    Drop dates that can't be booked at the venue: anything before today, or more
+    than booking_window days after it. today defaults to the date in the venue's
+    timezone. Venues without a booking window only lose past dates.
+    """
+    if today is None:
+        today = datetime.now(ZoneInfo(venue_config.get('timezone') or 'Europe/London')).date()
+
+    window = venue_config.get('booking_window') or 0
+    last = today + timedelta(days=window) if window > 0 else None
+
+    bookable = []
+    for target_date in target_dates:
+        try:
+            day = datetime.strptime(target_date, "%Y-%m-%d").date()
+        except ValueError:
+            continue
+        if day >= today and (last is None or day <= last):
+            bookable.append(target_date)
+    return bookable
+
+def extra_request_headers(scraper_config: Dict[str, Any]) -> Dict[str, str]:
+    """
+    Return scraper_config.headers as strings. A User-Agent there is dropped:
+    scraper_config.user_agent sets it, so the scraping log records the one used.
+    """
+    headers = scraper_config.get('headers') or {}
+    return {str(name): str(value) for name, value in headers.items() if str(name).lower() != 'user-agent'}
+
 class BaseScraper(ABC):
     """Base class for platform-specific scrapers"""
     
-    def __init__(self, venue_config: Dict[str, Any]):
+    def __init__(self, venue_config: Dict[str, Any], proxy_pool: Optional[ProxyPool] = None):
         self.venue_config = venue_config
         self.venue_id = venue_config['_id']
         self.venue_name = venue_config['name']
@@ -56,6 +107,18 @@ class BaseScraper(ABC):
         self.courts = venue_config['courts']
         self.scraper_config = venue_config['scraper_config']
         
+        # A venue created through the API stores 0 for settings it didn't set
+        self.timeout_seconds = self.config_int('timeout_seconds', DEFAULT_TIMEOUT_SECONDS)
+        self.retry_count = max(int(self.scraper_config.get('retry_count') or 0), 0)
+        self.retry_backoff_seconds = DEFAULT_RETRY_BACKOFF_SECONDS
+        
+        # Requests identify as a browser, with any extra headers the venue needs
+        self.user_agent = self.scraper_config.get('user_agent') or random.choice(BROWSER_USER_AGENTS)
+        self.extra_headers = extra_request_headers(self.scraper_config)
+        
+        # Only venues that opt in are scraped through proxies
+        self.proxy_pool = proxy_pool if proxy_pool is not None and venue_uses_proxy(venue_config) else None
+        
         self.logger = logging.getLogger(f"{self.__class__.__name__}_{self.venue_name}")
         
     @abstractmethod
@@ -71,6 +134,76 @@ class BaseScraper(ABC):
         """
         pass
         
+    def config_int(self, key: str, default: int) -> int:
+        """Read a positive integer from scraper_config, treating missing or 0 as unset"""
+        try:
+            value = int(self.scraper_config.get(key) or 0)
+        except (TypeError, ValueError):
+            return default
+        return value if value > 0 else default
+        
+    def scrape_timeout(self, target_dates: List[str]) -> float:
+        """
+        Return the time allowed for one scrape attempt: timeout_seconds for each
+        date plus one more for setup such as logging in. Individual page loads
+        and requests are held to timeout_seconds by the platform scrapers.
+        """
+        return float(self.timeout_seconds * (len(target_dates) + 1))
+        
+    def is_transient_failure(self, result: ScrapingResult) -> bool:
+        """Report whether a failed attempt is worth retrying: nothing at all was scraped"""
+        return not result.success and not result.slots_found
+        
+    async def scrape_with_retries(self, target_dates: List[str]) -> ScrapingResult:
+        """
+        Scrape the given dates, bounding each attempt by scrape_timeout and
+        retrying up to retry_count times, with a growing pause, when an attempt
+        fails outright. The result records how many attempts were made.
+        """
+        max_attempts = 1 + self.retry_count
+        for attempt in range(1, max_attempts + 1):
+            started = time.time()
+            timeout = self.scrape_timeout(target_dates)
+            try:
+                result = await asyncio.wait_for(self.scrape_availability(target_dates), timeout=timeout)
+            except asyncio.TimeoutError:
+                result = self.create_scraping_result(False, [], [f"Scrape timed out after {timeout:.0f}s"],
+                                                     int((time.time() - started) * 1000))
+            except Exception as e:
+                result = self.create_scraping_result(False, [], [f"Scrape failed: {str(e)}"],
+                                                     int((time.time() - started) * 1000))
+            result.attempts = attempt
+            
+            if attempt == max_attempts or not self.is_transient_failure(result):
+                break
+            
+            delay = self.retry_backoff_seconds * attempt
+            self.logger.warning(f"Attempt {attempt} of {max_attempts} failed for {self.venue_name}, "
+                                f"retrying in {delay}s: {'; '.join(result.errors)}")
+            await asyncio.sleep(delay)
+            
+        if result.attempts > 1 and result.success:
+            self.logger.info(f"Scrape for {self.venue_name} succeeded on attempt {result.attempts}")
+        return result
+        
+    def acquire_proxy(self) -> Optional[Proxy]:
+        """Return the proxy for the next request, or None to connect directly"""
+        if self.proxy_pool is None:
+            return None
+        proxy = self.proxy_pool.acquire()
+        if proxy is not None:
+            self.logger.debug(f"Using proxy {proxy.name}")
+        return proxy
+        
+    def report_proxy(self, proxy: Optional[Proxy], success: bool):
+        """Record how a request through a proxy went"""
+        if proxy is None or self.proxy_pool is None:
+            return
+        if success:
+            self.proxy_pool.report_success(proxy)
+        else:
+            self.proxy_pool.report_failure(proxy)
+        
     def get_target_dates(self, days_ahead: int = 7) -> List[str]:
         """Get list of target dates to scrape (default 7 days ahead)"""
         target_dates = []
@@ -168,5 +301,6 @@ class BaseScraper(ABC):
             slots_found=slots,
             errors=errors,
             duration_ms=duration_ms,
-            scraped_at=datetime.now()
+            scraped_at=datetime.now(),
+            user_agent=self.user_agent
         ) 
\ No newline at end of file
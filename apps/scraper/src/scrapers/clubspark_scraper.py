@@ -8,27 +8,47 @@ Based on Firecrawl analysis of the ClubSpark booking system.
 import asyncio
 import time
 import re
-from typing import List, Dict, Any
+from typing import List, Dict, Any, Optional
+from urllib.parse import urlparse
 from playwright.async_api import async_playwright, Page, Browser
 from .base_scraper import BaseScraper, ScrapedSlot, ScrapingResult
+from .proxy_pool import ProxyPool
+from .venue_session import SessionStore, credential_env_prefix, get_venue_credentials
+
+# Default selectors for the LTA ClubSpark sign-in form, overridable per venue
+DEFAULT_USERNAME_SELECTOR = '#EmailAddress'
+DEFAULT_PASSWORD_SELECTOR = '#Password'
+DEFAULT_SUBMIT_SELECTOR = 'button[type="submit"]'
 
 class ClubSparkScraper(BaseScraper):
     """Scraper for ClubSpark platform (Stratford Park)"""
     
-    def __init__(self, venue_config: Dict[str, Any]):
-        super().__init__(venue_config)
+    def __init__(self, venue_config: Dict[str, Any], proxy_pool: Optional[ProxyPool] = None):
+        super().__init__(venue_config, proxy_pool)
         self.selectors = self.scraper_config.get('selector_mappings', {})
         self.navigation_steps = self.scraper_config.get('navigation_steps', [])
-        self.timeout = self.scraper_config.get('timeout_seconds', 45) * 1000
-        self.wait_after_load = self.scraper_config.get('wait_after_load_ms', 3000)
+        self.timeout_seconds = self.config_int('timeout_seconds', 45)
+        self.timeout = self.timeout_seconds * 1000
+        self.wait_after_load = self.config_int('wait_after_load_ms', 3000)
         self.custom_params = self.scraper_config.get('custom_parameters', {})
         
+        # Venues that only show member pricing and booking to a signed-in LTA account
+        self.requires_login = self.scraper_config.get('requires_login', False)
+        self.login_url = self.custom_params.get('login_url')
+        self.username_selector = self.custom_params.get('username_selector', DEFAULT_USERNAME_SELECTOR)
+        self.password_selector = self.custom_params.get('password_selector', DEFAULT_PASSWORD_SELECTOR)
+        self.submit_selector = self.custom_params.get('submit_selector', DEFAULT_SUBMIT_SELECTOR)
+        self.session_store = SessionStore()
+        
     async def scrape_availability(self, target_dates: List[str]) -> ScrapingResult:
         """Scrape court availability for ClubSpark platform"""
         start_time = time.time()
         slots = []
         errors = []
         
+        # A browser session keeps one proxy for the whole scrape
+        proxy = self.acquire_proxy()
+        
         try:
             async with async_playwright() as p:
                 browser = await p.chromium.launch(
@@ -36,13 +56,18 @@ class ClubSparkScraper(BaseScraper):
                 )
                 
                 try:
-                    page = await browser.new_page()
-                    await page.set_viewport_size({"width": 1280, "height": 720})
+                    # Start from the saved session, if any, so we don't log in every scrape
+                    context = await browser.new_context(
+                        viewport={"width": 1280, "height": 720},
+                        storage_state=self.session_store.load(self.venue_id) if self.requires_login else None,
+                        proxy=proxy.playwright_proxy() if proxy else None,
+                        user_agent=self.user_agent,
+                        extra_http_headers=self.extra_headers or None,
+                    )
+                    page = await context.new_page()
                     
-                    # Set user agent if specified
-                    user_agent = self.scraper_config.get('user_agent')
-                    if user_agent:
-                        await page.set_extra_http_headers({'User-Agent': user_agent})
+                    if self.requires_login and not self.session_store.load(self.venue_id):
+                        await self._login(page)
                     
                     for date in target_dates:
                         try:
@@ -67,6 +92,7 @@ class ClubSparkScraper(BaseScraper):
             
         duration_ms = int((time.time() - start_time) * 1000)
         success = len(errors) == 0
+        self.report_proxy(proxy, success)
         
         self.logger.info(f"Scraped {len(slots)} slots for {self.venue_name} in {duration_ms}ms")
         
@@ -83,6 +109,14 @@ class ClubSparkScraper(BaseScraper):
         await page.goto(date_url, timeout=self.timeout)
         await page.wait_for_timeout(self.wait_after_load)
         
+        # A saved session can expire between scrapes; log in again and retry once
+        if self.requires_login and await self._on_login_page(page):
+            self.logger.info("Session expired, logging in again")
+            self.session_store.clear(self.venue_id)
+            await self._login(page)
+            await page.goto(date_url, timeout=self.timeout)
+            await page.wait_for_timeout(self.wait_after_load)
+        
         # Wait for booking sheet to load (ClubSpark specific selector)
         try:
             await page.wait_for_selector('.booking-sheet', timeout=15000)
@@ -231,11 +265,45 @@ class ClubSparkScraper(BaseScraper):
         
         return slot
         
+    async def _login(self, page: Page):
+        """Sign in with the venue's credentials and save the session for later scrapes"""
+        if not self.login_url:
+            raise ValueError("requires_login is set but custom_parameters.login_url is missing")
+            
+        credentials = get_venue_credentials(self.venue_id, self.scraper_config.get('credential_key'))
+        if not credentials:
+            prefix = credential_env_prefix(self.scraper_config.get('credential_key') or self.venue_id)
+            raise ValueError(f"No login credentials found (set {prefix}_USERNAME and {prefix}_PASSWORD)")
+        username, password = credentials
+        
+        self.logger.info(f"Logging in to {self.login_url}")
+        await page.goto(self.login_url, timeout=self.timeout)
+        await page.fill(self.username_selector, username)
+        await page.fill(self.password_selector, password)
+        await page.click(self.submit_selector)
+        await page.wait_for_load_state('networkidle', timeout=self.timeout)
+        
+        if await self._on_login_page(page):
+            raise RuntimeError("Login failed: still on the sign-in page after submitting credentials")
+            
+        await page.context.storage_state(path=self.session_store.save_path(self.venue_id))
+        
+    async def _on_login_page(self, page: Page) -> bool:
+        """Whether the page is the sign-in form, meaning we aren't logged in"""
+        login_path = urlparse(self.login_url or '').path.lower()
+        if login_path and urlparse(page.url).path.lower().startswith(login_path):
+            return True
+        return await page.query_selector(self.password_selector) is not None
+        
+    def _booking_role(self) -> str:
+        """ClubSpark shows member prices to signed-in users and guest prices otherwise"""
+        return self.custom_params.get('role', 'member' if self.requires_login else 'guest')
+        
     def _build_date_url(self, date: str) -> str:
         """Build URL for specific date on ClubSpark platform"""
         # ClubSpark URL format: /Booking/BookByDate#?date=YYYY-MM-DD&role=guest
         base_url = self.url.split('#')[0]  # Remove any existing fragment
-        return f"{base_url}#?date={date}&role=guest"
+        return f"{base_url}#?date={date}&role={self._booking_role()}"
         
     async def _build_booking_url(self, date: str, start_time: str, court_id: str) -> str:
         """Build booking URL for ClubSpark platform"""
@@ -8,15 +8,16 @@ Based on Firecrawl analysis of the Courtside booking system.
 import asyncio
 import time
 import re
-from typing import List, Dict, Any
+from typing import List, Dict, Any, Optional
 from playwright.async_api import async_playwright, Page, Browser
 from .base_scraper import BaseScraper, ScrapedSlot, ScrapingResult
+from .proxy_pool import ProxyPool
 
 class CourtsideScraper(BaseScraper):
     """Scraper for Courtside platform (Victoria Park, Ropemakers Field)"""
     
-    def __init__(self, venue_config: Dict[str, Any]):
-        super().__init__(venue_config)
+    def __init__(self, venue_config: Dict[str, Any], proxy_pool: Optional[ProxyPool] = None):
+        super().__init__(venue_config, proxy_pool)
         # Default selectors if not in database
         default_selectors = {
             'court_widget': '.court-widget',
@@ -25,9 +26,9 @@ class CourtsideScraper(BaseScraper):
         }
         self.selectors = self.scraper_config.get('selector_mappings', default_selectors)
         self.navigation_steps = self.scraper_config.get('navigation_steps', [])
-        self.timeout = self.scraper_config.get('timeout_seconds', 30) * 1000
+        self.timeout = self.timeout_seconds * 1000
         # Increase default wait time to allow JavaScript to fully render
-        self.wait_after_load = self.scraper_config.get('wait_after_load_ms', 3000)
+        self.wait_after_load = self.config_int('wait_after_load_ms', 3000)
         
     async def scrape_availability(self, target_dates: List[str]) -> ScrapingResult:
         """Scrape court availability for Courtside platform"""
@@ -35,6 +36,9 @@ class CourtsideScraper(BaseScraper):
         slots = []
         errors = []
         
+        # A browser session keeps one proxy for the whole scrape
+        proxy = self.acquire_proxy()
+        
         try:
             async with async_playwright() as p:
                 browser = await p.chromium.launch(
@@ -45,13 +49,13 @@ class CourtsideScraper(BaseScraper):
                     # Create context with proper session handling
                     context = await browser.new_context(
                         viewport={"width": 1280, "height": 720},
-                        user_agent=self.scraper_config.get('user_agent',
-                            'Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Safari/605.1.15'
-                        ),
+                        user_agent=self.user_agent,
+                        extra_http_headers=self.extra_headers or None,
                         locale='en-GB',
                         timezone_id='Europe/London',
                         accept_downloads=False,
                         ignore_https_errors=False,
+                        proxy=proxy.playwright_proxy() if proxy else None,
                     )
                     page = await context.new_page()
 
@@ -64,7 +68,7 @@ class CourtsideScraper(BaseScraper):
                     self.logger.info(f"Establishing session at {base_url}")
                     try:
                         await page.goto(base_url, timeout=self.timeout)
-                        await page.wait_for_timeout(2000)
+                        await page.wait_for_timeout(self.wait_after_load)
                         cookies = await context.cookies()
                         self.logger.info(f"Session established with {len(cookies)} cookies")
                     except Exception as e:
@@ -94,6 +98,7 @@ class CourtsideScraper(BaseScraper):
             
         duration_ms = int((time.time() - start_time) * 1000)
         success = len(errors) == 0
+        self.report_proxy(proxy, success)
         
         self.logger.info(f"Scraped {len(slots)} slots for {self.venue_name} in {duration_ms}ms")
         
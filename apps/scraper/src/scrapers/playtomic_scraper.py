@@ -0,0 +1,213 @@
+#!/usr/bin/env python3
+
+"""
+Playtomic platform scraper.
+Playtomic exposes availability as JSON, so no headless browser is needed.
+"""
+
+import asyncio
+import re
+import time
+from datetime import datetime, timedelta
+from typing import List, Dict, Any, Optional, Tuple
+from zoneinfo import ZoneInfo
+
+import requests
+
+from .base_scraper import BaseScraper, ScrapedSlot, ScrapingResult
+from .proxy_pool import ProxyPool
+
+DEFAULT_API_BASE_URL = "https://api.playtomic.io"
+DEFAULT_SPORT_ID = "TENNIS"
+DEFAULT_PAGE_SIZE = 50
+MAX_PAGES = 20  # Guards against an API that never returns a short page
+BLOCKED_STATUS_CODES = (403, 407, 429)  # Responses that suggest the proxy is blocked or rate-limited
+
+
+class PlaytomicScraper(BaseScraper):
+    """Scraper for Playtomic platform, scoped to one club (tenant) per venue"""
+
+    def __init__(self, venue_config: Dict[str, Any], proxy_pool: Optional[ProxyPool] = None):
+        super().__init__(venue_config, proxy_pool)
+        self.custom_params = self.scraper_config.get('custom_parameters', {})
+        self.tenant_id = self.custom_params.get('tenant_id')
+        self.sport_id = self.custom_params.get('sport_id', DEFAULT_SPORT_ID)
+        self.api_base_url = self.custom_params.get('api_base_url', DEFAULT_API_BASE_URL).rstrip('/')
+        self.page_size = int(self.custom_params.get('page_size', DEFAULT_PAGE_SIZE))
+        self.timeout = self.timeout_seconds
+
+        # Playtomic returns slot times in UTC; we store them in the venue's local time
+        self.timezone = ZoneInfo(venue_config.get('timezone') or 'Europe/London')
+
+        self.session = requests.Session()
+        self.session.headers.update({
+            'Accept': 'application/json',
+            **self.extra_headers,
+            'User-Agent': self.user_agent,
+        })
+
+    async def scrape_availability(self, target_dates: List[str]) -> ScrapingResult:
+        """Scrape court availability for Playtomic platform"""
+        start_time = time.time()
+        slots = []
+        errors = []
+
+        if not self.tenant_id:
+            errors.append("custom_parameters.tenant_id is required for Playtomic venues")
+        else:
+            try:
+                court_names = await asyncio.to_thread(self._fetch_court_names)
+            except Exception as e:
+                error_msg = f"Error loading Playtomic club: {str(e)}"
+                self.logger.error(error_msg)
+                errors.append(error_msg)
+                court_names = None
+
+            if court_names is not None:
+                for date in target_dates:
+                    try:
+                        entries = await asyncio.to_thread(self._fetch_availability, date)
+                        slots.extend(self.parse_availability(entries, court_names, date))
+
+                        # Rate limiting between dates
+                        await asyncio.sleep(1)
+
+                    except Exception as e:
+                        error_msg = f"Error scraping {date}: {str(e)}"
+                        self.logger.error(error_msg)
+                        errors.append(error_msg)
+
+        duration_ms = int((time.time() - start_time) * 1000)
+        success = len(errors) == 0
+
+        self.logger.info(f"Scraped {len(slots)} slots for {self.venue_name} in {duration_ms}ms")
+
+        return self.create_scraping_result(success, slots, errors, duration_ms)
+
+    def _get(self, path: str, params: Optional[Dict[str, Any]] = None) -> Any:
+        """
+        GET a Playtomic API path and return the decoded JSON. Proxied venues
+        use the next proxy for each request; connection errors and block
+        responses count against that proxy.
+        """
+        proxy = self.acquire_proxy()
+        try:
+            response = self.session.get(f"{self.api_base_url}{path}", params=params, timeout=self.timeout,
+                                        proxies=proxy.requests_proxies() if proxy else None)
+        except Exception:
+            self.report_proxy(proxy, False)
+            raise
+        self.report_proxy(proxy, response.status_code not in BLOCKED_STATUS_CODES)
+        response.raise_for_status()
+        return response.json()
+
+    def _fetch_court_names(self) -> Dict[str, str]:
+        """Map the club's resource ids to court names, preferring names from the venue config"""
+        tenant = self._get(f"/v1/tenants/{self.tenant_id}")
+
+        names = {}
+        for resource in tenant.get('resources', []):
+            sport = resource.get('sport_id')
+            if sport and sport != self.sport_id:
+                continue
+            names[resource['resource_id']] = resource.get('name') or resource['resource_id']
+
+        for court in self.courts:
+            if court.get('id') in names and court.get('name'):
+                names[court['id']] = court['name']
+
+        return names
+
+    def _fetch_availability(self, date: str) -> List[Dict[str, Any]]:
+        """Fetch every page of availability for one local date"""
+        # Query the UTC range covering the whole local day
+        local_start = datetime.strptime(date, "%Y-%m-%d").replace(tzinfo=self.timezone)
+        utc_start = local_start.astimezone(ZoneInfo('UTC'))
+        utc_end = (local_start + timedelta(days=1)).astimezone(ZoneInfo('UTC')) - timedelta(seconds=1)
+
+        params = {
+            'tenant_id': self.tenant_id,
+            'sport_id': self.sport_id,
+            'start_min': utc_start.strftime("%Y-%m-%dT%H:%M:%S"),
+            'start_max': utc_end.strftime("%Y-%m-%dT%H:%M:%S"),
+            'size': self.page_size,
+        }
+
+        entries = []
+        for page in range(MAX_PAGES):
+            params['page'] = page
+            batch = self._get("/v1/availability", params)
+            entries.extend(batch)
+            if len(batch) < self.page_size:
+                break
+        else:
+            self.logger.warning(f"Stopped after {MAX_PAGES} pages of availability for {date}")
+
+        return entries
+
+    def parse_availability(self, entries: List[Dict[str, Any]], court_names: Dict[str, str],
+                           date: str) -> List[ScrapedSlot]:
+        """
+        Map Playtomic availability entries to slots on the given local date.
+
+        Each entry is one court (resource) on one UTC day, with slots like
+        {"start_time": "18:00:00", "duration": 60, "price": "24 GBP"}. A court
+        offering several durations at one start time becomes one slot per duration.
+        """
+        slots = []
+        seen = set()
+
+        for entry in entries:
+            resource_id = entry.get('resource_id')
+            if resource_id not in court_names:
+                continue  # Another sport, or a resource the club has since removed
+
+            for raw_slot in entry.get('slots', []):
+                try:
+                    start_utc = datetime.strptime(
+                        f"{entry['start_date']}T{raw_slot['start_time']}", "%Y-%m-%dT%H:%M:%S"
+                    ).replace(tzinfo=ZoneInfo('UTC'))
+                    duration = timedelta(minutes=int(raw_slot.get('duration', 60)))
+                except (KeyError, ValueError) as e:
+                    self.logger.warning(f"Skipping malformed Playtomic slot {raw_slot}: {e}")
+                    continue
+
+                start_local = start_utc.astimezone(self.timezone)
+                end_local = (start_utc + duration).astimezone(self.timezone)
+                if start_local.strftime("%Y-%m-%d") != date:
+                    continue
+
+                key = (resource_id, start_local, duration)
+                if key in seen:
+                    continue  # Overlapping pages can repeat an entry
+                seen.add(key)
+
+                price, currency = self.parse_playtomic_price(raw_slot.get('price'))
+                slots.append(ScrapedSlot(
+                    venue_id=self.venue_id,
+                    venue_name=self.venue_name,
+                    court_id=resource_id,
+                    court_name=court_names[resource_id],
+                    date=date,
+                    start_time=start_local.strftime("%H:%M"),
+                    end_time=end_local.strftime("%H:%M"),
+                    price=price,
+                    currency=currency,
+                    available=True,
+                    booking_url=self._build_booking_url(date),
+                ))
+
+        return slots
+
+    def parse_playtomic_price(self, price_text: Optional[str]) -> Tuple[Optional[float], str]:
+        """Split a Playtomic price such as "24.5 GBP" into amount and currency"""
+        if not price_text:
+            return None, "GBP"
+        match = re.search(r'\b([A-Z]{3})\b', str(price_text))
+        currency = match.group(1) if match else "GBP"
+        return self.parse_price(str(price_text)), currency
+
+    def _build_booking_url(self, date: str) -> str:
+        """Build a link to the club's booking page on the given date"""
+        base_url = self.url.split('?')[0]
+        return f"{base_url}?date={date}"
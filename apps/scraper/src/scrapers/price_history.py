@@ -0,0 +1,83 @@
+#!/usr/bin/env python3
+
+"""
+Price history for scraped slots.
+
+A slot's price is recorded in the price_history collection the first time the
+slot is scraped and again whenever it differs from the last recorded price, so
+the API can show how prices move with peak times and seasons.
+"""
+
+from datetime import datetime
+from typing import Any, Dict, List, Optional, Tuple
+
+PriceKey = Tuple[str, str, str]  # (court_id, date, start_time)
+
+
+def price_key(slot_doc: Dict[str, Any]) -> PriceKey:
+    """Identify a slot within its venue"""
+    return (str(slot_doc["court_id"]), slot_doc["date"], slot_doc["start_time"])
+
+
+def price_changes(slots_data: List[Dict[str, Any]], last_prices: Dict[PriceKey, float],
+                  observed_at: datetime) -> List[Dict[str, Any]]:
+    """
+    Return price_history documents for slots whose price differs from
+    last_prices, updating last_prices as it goes. Slots without a price are
+    skipped, since a missing price says nothing about what it would cost.
+    """
+    changes = []
+    for slot_doc in slots_data:
+        if slot_doc.get("price") is None:
+            continue
+        price = float(slot_doc["price"])
+        key = price_key(slot_doc)
+        if last_prices.get(key) == price:
+            continue
+        last_prices[key] = price
+        changes.append({
+            "venue_id": slot_doc["venue_id"],
+            "court_id": key[0],
+            "court_name": slot_doc.get("court_name"),
+            "date": slot_doc["date"],
+            "start_time": slot_doc["start_time"],
+            "price": price,
+            "currency": slot_doc.get("currency") or "GBP",
+            "observed_at": observed_at,
+        })
+    return changes
+
+
+def last_recorded_prices(collection, venue_id: Any, dates: List[str]) -> Dict[PriceKey, float]:
+    """Return the last recorded price of each of the venue's slots on the given dates"""
+    pipeline = [
+        {"$match": {"venue_id": venue_id, "date": {"$in": dates}}},
+        {"$sort": {"observed_at": 1}},
+        {"$group": {
+            "_id": {"court_id": "$court_id", "date": "$date", "start_time": "$start_time"},
+            "price": {"$last": "$price"},
+        }},
+    ]
+    return {
+        (doc["_id"]["court_id"], doc["_id"]["date"], doc["_id"]["start_time"]): doc["price"]
+        for doc in collection.aggregate(pipeline)
+    }
+
+
+def record_price_changes(collection, slots_data: List[Dict[str, Any]],
+                         observed_at: Optional[datetime] = None) -> int:
+    """
+    Record the price of each slot in slots_data, all from one venue, that has
+    changed since it was last recorded. Returns how many prices were recorded.
+    """
+    if not slots_data:
+        return 0
+
+    venue_id = slots_data[0]["venue_id"]
+    dates = sorted({slot_doc["date"] for slot_doc in slots_data})
+    last_prices = last_recorded_prices(collection, venue_id, dates)
+
+    changes = price_changes(slots_data, last_prices, observed_at or datetime.now())
+    if changes:
+        collection.insert_many(changes)
+    return len(changes)
@@ -0,0 +1,231 @@
+#!/usr/bin/env python3
+
+"""
+Proxy rotation for venues that rate-limit or block the scraper's IP.
+
+Proxies come from SCRAPER_PROXIES (comma-separated, each `name=url` or just a
+url, named after its host) and from active documents in the `proxies`
+collection ({name, url, is_active}). Proxy URLs must not contain credentials:
+a proxy that needs a login gets PROXY_<NAME>_USERNAME and PROXY_<NAME>_PASSWORD
+from the environment, which is where the secrets manager puts them, with NAME
+upper-cased and anything that isn't a letter or digit replaced by an
+underscore.
+
+A venue opts in with scraper_config.custom_parameters.use_proxy. Each request
+takes the next proxy in turn; a proxy that fails several times in a row is
+benched for a while and then tried again.
+"""
+
+import logging
+import os
+import re
+from dataclasses import dataclass
+from datetime import datetime, timedelta
+from typing import Any, Dict, List, Optional
+from urllib.parse import quote, urlsplit
+
+DEFAULT_FAILURE_THRESHOLD = 3
+DEFAULT_BENCH_MINUTES = 15
+PROXY_SCHEMES = ('http', 'https', 'socks5')
+
+logger = logging.getLogger(__name__)
+
+
+def _env_int(name: str, default: int) -> int:
+    """Read a positive integer from the environment, falling back to default"""
+    try:
+        value = int(os.getenv(name, default))
+    except (TypeError, ValueError):
+        return default
+    return value if value > 0 else default
+
+
+def proxy_env_prefix(name: str) -> str:
+    """Return the environment variable prefix holding a proxy's credentials"""
+    return "PROXY_" + re.sub(r'[^A-Za-z0-9]', '_', str(name)).upper()
+
+
+def venue_uses_proxy(venue_config: Dict[str, Any]) -> bool:
+    """Report whether a venue has opted into proxying"""
+    custom_params = (venue_config.get('scraper_config') or {}).get('custom_parameters') or {}
+    value = custom_params.get('use_proxy', False)
+    if isinstance(value, str):
+        return value.strip().lower() in ('true', '1', 'yes')
+    return bool(value)
+
+
+@dataclass
+class Proxy:
+    """One proxy and its recent failures"""
+    name: str
+    url: str  # scheme://host:port, never with credentials
+    username: Optional[str] = None
+    password: Optional[str] = None
+    consecutive_failures: int = 0
+    benched_until: Optional[datetime] = None
+
+    def is_benched(self, now: datetime) -> bool:
+        """Report whether the proxy is sitting out after repeated failures"""
+        return self.benched_until is not None and now < self.benched_until
+
+    def requests_url(self) -> str:
+        """Return the URL for requests, with credentials if the proxy has them"""
+        if not self.username:
+            return self.url
+        parts = urlsplit(self.url)
+        userinfo = f"{quote(self.username, safe='')}:{quote(self.password or '', safe='')}"
+        return f"{parts.scheme}://{userinfo}@{parts.netloc}"
+
+    def requests_proxies(self) -> Dict[str, str]:
+        """Return the proxies argument for a requests call"""
+        url = self.requests_url()
+        return {'http': url, 'https': url}
+
+    def playwright_proxy(self) -> Dict[str, str]:
+        """Return the proxy argument for a Playwright browser context"""
+        proxy = {'server': self.url}
+        if self.username:
+            proxy['username'] = self.username
+            proxy['password'] = self.password or ''
+        return proxy
+
+
+def parse_proxy(name: Optional[str], url: str) -> Optional[Proxy]:
+    """
+    Build a proxy from its configured URL, reading its credentials from the
+    environment. URLs with embedded credentials or an unsupported scheme are
+    rejected with a warning so they never end up in logs or requests.
+    """
+    url = url.strip()
+    try:
+        parts = urlsplit(url)
+    except ValueError:
+        parts = None
+    if parts is None or parts.scheme not in PROXY_SCHEMES or not parts.hostname:
+        logger.warning(f"Ignoring proxy {name or '?'}: expected {'/'.join(PROXY_SCHEMES)}://host:port")
+        return None
+    if parts.username or parts.password:
+        logger.warning(f"Ignoring proxy {name or parts.hostname}: credentials belong in the secrets manager, not the proxy URL")
+        return None
+
+    name = name or parts.hostname
+    prefix = proxy_env_prefix(name)
+    return Proxy(
+        name=name,
+        url=f"{parts.scheme}://{parts.netloc}",
+        username=os.getenv(f"{prefix}_USERNAME"),
+        password=os.getenv(f"{prefix}_PASSWORD"),
+    )
+
+
+def proxies_from_env(value: Optional[str] = None) -> List[Proxy]:
+    """Parse SCRAPER_PROXIES"""
+    if value is None:
+        value = os.getenv('SCRAPER_PROXIES', '')
+
+    proxies = []
+    for entry in value.split(','):
+        entry = entry.strip()
+        if not entry:
+            continue
+        name, url = None, entry
+        if '=' in entry.split('://', 1)[0]:
+            name, url = entry.split('=', 1)
+            name = name.strip()
+        proxy = parse_proxy(name, url)
+        if proxy is not None:
+            proxies.append(proxy)
+    return proxies
+
+
+class ProxyPool:
+    """Round-robin proxy rotation that benches proxies after consecutive failures"""
+
+    def __init__(self, proxies: Optional[List[Proxy]] = None,
+                 failure_threshold: int = DEFAULT_FAILURE_THRESHOLD,
+                 bench_minutes: int = DEFAULT_BENCH_MINUTES):
+        self.proxies: List[Proxy] = []
+        self.failure_threshold = failure_threshold
+        self.bench_minutes = bench_minutes
+        self._next = 0
+        self.set_proxies(proxies or [])
+
+    @classmethod
+    def from_env(cls) -> 'ProxyPool':
+        """Create a pool from SCRAPER_PROXIES and the SCRAPER_PROXY_* settings"""
+        return cls(
+            proxies_from_env(),
+            failure_threshold=_env_int('SCRAPER_PROXY_FAILURE_THRESHOLD', DEFAULT_FAILURE_THRESHOLD),
+            bench_minutes=_env_int('SCRAPER_PROXY_BENCH_MINUTES', DEFAULT_BENCH_MINUTES),
+        )
+
+    def set_proxies(self, proxies: List[Proxy]):
+        """Replace the pool's proxies, keeping the failure history of any that remain"""
+        previous = {proxy.name: proxy for proxy in self.proxies}
+        merged: Dict[str, Proxy] = {}
+        for proxy in proxies:
+            if proxy.name in merged:
+                continue
+            if proxy.name in previous:
+                proxy.consecutive_failures = previous[proxy.name].consecutive_failures
+                proxy.benched_until = previous[proxy.name].benched_until
+            merged[proxy.name] = proxy
+        self.proxies = list(merged.values())
+        self._next = 0
+
+    def refresh(self, db):
+        """
+        Reload the pool from SCRAPER_PROXIES and the proxies collection. On a
+        database error the environment's proxies are still used.
+        """
+        proxies = proxies_from_env()
+        try:
+            for doc in db.proxies.find({'is_active': {'$ne': False}}):
+                if not doc.get('url'):
+                    continue
+                proxy = parse_proxy(doc.get('name') or None, doc['url'])
+                if proxy is not None:
+                    proxies.append(proxy)
+        except Exception as e:
+            logger.error(f"Failed to load proxies from MongoDB, using SCRAPER_PROXIES only: {e}")
+
+        self.set_proxies(proxies)
+
+    def acquire(self, now: Optional[datetime] = None) -> Optional[Proxy]:
+        """
+        Return the next proxy that isn't benched, or None if the pool is empty
+        or every proxy is benched, in which case the request goes direct
+        """
+        now = now or datetime.now()
+        for _ in range(len(self.proxies)):
+            proxy = self.proxies[self._next % len(self.proxies)]
+            self._next = (self._next + 1) % len(self.proxies)
+            if not proxy.is_benched(now):
+                return proxy
+
+        if self.proxies:
+            logger.warning("Every proxy is benched, connecting directly")
+        return None
+
+    def report_success(self, proxy: Proxy):
+        """Record that a request through the proxy worked"""
+        proxy.consecutive_failures = 0
+        proxy.benched_until = None
+
+    def report_failure(self, proxy: Proxy, now: Optional[datetime] = None):
+        """Record a failed request, benching the proxy once it reaches the threshold"""
+        now = now or datetime.now()
+        proxy.consecutive_failures += 1
+        if proxy.consecutive_failures >= self.failure_threshold:
+            proxy.benched_until = now + timedelta(minutes=self.bench_minutes)
+            proxy.consecutive_failures = 0
+            logger.warning(f"Benching proxy {proxy.name} for {self.bench_minutes} minutes after "
+                           f"{self.failure_threshold} consecutive failures")
+
+    def status(self, now: Optional[datetime] = None) -> Dict[str, Any]:
+        """Describe the pool without exposing credentials"""
+        now = now or datetime.now()
+        return {
+            "proxies": len(self.proxies),
+            "benched": [proxy.name for proxy in self.proxies if proxy.is_benched(now)],
+        }
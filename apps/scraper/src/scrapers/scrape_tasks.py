@@ -0,0 +1,221 @@
+#!/usr/bin/env python3
+
+"""
+Scrape task planning for the scheduler.
+
+A venue is scraped every scraping_interval minutes, but individual courts can
+override that with scrape_interval_minutes (e.g. floodlit courts that get
+booked quickly). Each distinct interval within a venue becomes its own task:
+the venue-wide task covers every court without an override, and each override
+interval gets a task covering just its courts.
+"""
+
+from dataclasses import dataclass, replace
+from datetime import datetime, timedelta
+from typing import Any, Dict, Iterable, List, Optional, Tuple
+
+from .adaptive_schedule import AdaptiveSchedule
+from .base_scraper import ScrapedSlot
+
+
+@dataclass(frozen=True)
+class ScrapeTask:
+    """One periodically scheduled scrape of a venue, or of some of its courts"""
+    venue_id: str
+    venue_name: str
+    interval_minutes: int
+    court_ids: Tuple[str, ...] = ()  # Empty for the venue-wide task
+    excluded_court_ids: Tuple[str, ...] = ()  # Courts the venue-wide task leaves to their own tasks
+
+    @property
+    def key(self) -> str:
+        """Identify the task for scheduling; court-scoped tasks never share the venue's key"""
+        if not self.court_ids:
+            return self.venue_id
+        return f"{self.venue_id}:courts:{','.join(self.court_ids)}"
+
+    def covers(self, slot: ScrapedSlot, courts: List[Dict[str, Any]]) -> bool:
+        """Report whether a scraped slot belongs to this task"""
+        if self.court_ids:
+            return _matches_any(slot, courts, self.court_ids)
+        return not _matches_any(slot, courts, self.excluded_court_ids)
+
+
+def _positive_int(value: Any) -> Optional[int]:
+    """Return value as a positive int, or None if it isn't one"""
+    try:
+        value = int(value)
+    except (TypeError, ValueError):
+        return None
+    return value if value > 0 else None
+
+
+def _matches_any(slot: ScrapedSlot, courts: List[Dict[str, Any]], court_ids: Iterable[str]) -> bool:
+    """
+    Report whether a slot was scraped from one of the given courts. Platforms
+    don't always report the configured court id, so the court name counts too.
+    """
+    wanted = set(court_ids)
+    for court in courts:
+        if str(court.get('id')) not in wanted:
+            continue
+        if slot.court_id == str(court.get('id')) or (court.get('name') and slot.court_name == court['name']):
+            return True
+    return False
+
+
+def build_scrape_tasks(venue: Dict[str, Any], default_interval: int) -> List[ScrapeTask]:
+    """
+    Split a venue into one task per distinct scrape interval.
+
+    Args:
+        venue: Venue document as loaded by the orchestrator
+        default_interval: Minutes to use when the venue has no scraping_interval
+
+    Returns:
+        The venue-wide task first, followed by court-scoped tasks by interval
+    """
+    venue_interval = _positive_int(venue.get('scraping_interval')) or default_interval
+
+    by_interval: Dict[int, List[str]] = {}
+    for court in venue.get('courts') or []:
+        interval = _positive_int(court.get('scrape_interval_minutes'))
+        if interval is None or interval == venue_interval or court.get('id') is None:
+            continue
+        by_interval.setdefault(interval, []).append(str(court['id']))
+
+    overridden = tuple(sorted(court_id for ids in by_interval.values() for court_id in ids))
+    tasks = [ScrapeTask(
+        venue_id=str(venue['_id']),
+        venue_name=venue['name'],
+        interval_minutes=venue_interval,
+        excluded_court_ids=overridden,
+    )]
+
+    for interval in sorted(by_interval):
+        tasks.append(ScrapeTask(
+            venue_id=str(venue['_id']),
+            venue_name=venue['name'],
+            interval_minutes=interval,
+            court_ids=tuple(sorted(by_interval[interval])),
+        ))
+
+    return tasks
+
+
+def fair_order(due: List[Tuple[Dict[str, Any], ScrapeTask, datetime]]) -> List[Tuple[Dict[str, Any], ScrapeTask]]:
+    """
+    Order due tasks so no venue monopolises a session. Tasks are taken
+    round-robin, one per venue per round, so a venue with many court-scoped
+    tasks can't push every other venue to the back. Within a venue the most
+    overdue task goes first, and venues whose oldest task has waited longest
+    lead each round.
+
+    Args:
+        due: (venue, task, due_since) for every due task
+    """
+    by_venue: Dict[str, List[Tuple[Dict[str, Any], ScrapeTask]]] = {}
+    for venue, task, _ in sorted(due, key=lambda item: item[2]):
+        by_venue.setdefault(task.venue_id, []).append((venue, task))
+
+    ordered = []
+    queues = list(by_venue.values())
+    while queues:
+        for queue in queues:
+            ordered.append(queue.pop(0))
+        queues = [queue for queue in queues if queue]
+    return ordered
+
+
+class ScrapeTaskTracker:
+    """Remembers when each task last ran so the scheduler only runs the ones that are due"""
+
+    def __init__(self, default_interval: int, adaptive: Optional[AdaptiveSchedule] = None):
+        self.default_interval = default_interval  # Minutes, for venues without a scraping_interval
+        self.adaptive = adaptive  # Replaces venue-wide intervals in adaptive mode
+        self.last_run: Dict[str, datetime] = {}
+        self.intervals: Dict[str, int] = {}
+        self.first_seen: Dict[str, datetime] = {}  # When never-run tasks were first planned
+        self.pending: Dict[str, Tuple[ScrapeTask, datetime]] = {}  # Due tasks not yet started, with when they became due
+        self.last_wait: Dict[str, float] = {}  # Seconds each venue's last task waited after becoming due
+
+    def is_due(self, task: ScrapeTask, now: datetime) -> bool:
+        """Report whether a task has never run or its interval has elapsed"""
+        last = self.last_run.get(task.key)
+        return last is None or now >= last + timedelta(minutes=task.interval_minutes)
+
+    def due_tasks(self, venues: List[Dict[str, Any]], now: datetime) -> List[Tuple[Dict[str, Any], ScrapeTask]]:
+        """
+        Plan every venue's tasks and return the due ones with their venue, in
+        fair order (see fair_order). venues must be all active venues: tasks
+        that no longer exist (a venue was deactivated, or a court lost its
+        override) are forgotten so they don't hold up next_due_time.
+        The returned tasks are pending until mark_run is called for them.
+        """
+        planned = [(venue, self._adapt(task)) for venue in venues for task in build_scrape_tasks(venue, self.default_interval)]
+        tasks = [task for _, task in planned]
+
+        self.intervals = {task.key: task.interval_minutes for task in tasks}
+        self.last_run = {key: when for key, when in self.last_run.items() if key in self.intervals}
+        self.first_seen = {key: self.first_seen.get(key, now) for key in self.intervals}
+
+        due = [(venue, task, self.due_since(task)) for venue, task in planned if self.is_due(task, now)]
+        self.pending = {task.key: (task, since) for _, task, since in due}
+        return fair_order(due)
+
+    def due_since(self, task: ScrapeTask) -> datetime:
+        """Return when a task became due: its interval after it last ran, or when it was first planned"""
+        last = self.last_run.get(task.key)
+        if last is None:
+            return self.first_seen.get(task.key, datetime.now())
+        return last + timedelta(minutes=task.interval_minutes)
+
+    def _adapt(self, task: ScrapeTask) -> ScrapeTask:
+        """Apply the venue's adaptive interval to its venue-wide task; court overrides are kept"""
+        if self.adaptive is None or task.court_ids:
+            return task
+        interval = self.adaptive.interval_for(task.venue_id)
+        return task if interval is None else replace(task, interval_minutes=interval)
+
+    def mark_run(self, task: ScrapeTask, when: datetime):
+        """Record that a task has just run"""
+        pending = self.pending.pop(task.key, None)
+        if pending is not None:
+            self.last_wait[task.venue_id] = max(0.0, (when - pending[1]).total_seconds())
+        self.last_run[task.key] = when
+        self.intervals[task.key] = task.interval_minutes
+
+    def queue_status(self, now: datetime) -> Dict[str, Any]:
+        """
+        Describe the tasks waiting to run: how many there are, and per venue
+        how many are pending, how long the oldest has been due, and how long
+        the venue's last task waited before it started
+        """
+        venues: Dict[str, Dict[str, Any]] = {}
+        for task, since in self.pending.values():
+            venue = venues.setdefault(task.venue_id, {
+                "venue_name": task.venue_name,
+                "pending": 0,
+                "oldest_wait_seconds": 0.0,
+            })
+            venue["pending"] += 1
+            venue["oldest_wait_seconds"] = max(venue["oldest_wait_seconds"], max(0.0, (now - since).total_seconds()))
+
+        for venue_id, wait in self.last_wait.items():
+            if venue_id in venues:
+                venues[venue_id]["last_wait_seconds"] = wait
+
+        return {
+            "depth": len(self.pending),
+            "venues": venues,
+            "last_wait_seconds": dict(self.last_wait),
+        }
+
+    def next_due_time(self) -> Optional[datetime]:
+        """Return when the earliest known task is next due, or None if none have run"""
+        due_times = [
+            self.last_run[key] + timedelta(minutes=interval)
+            for key, interval in self.intervals.items()
+            if key in self.last_run
+        ]
+        return min(due_times) if due_times else None
@@ -0,0 +1,239 @@
+#!/usr/bin/env python3
+
+"""
+One-off test scrapes of an unsaved scraper config, requested by admins through
+the backend's POST /api/venues/test-scrape.
+
+The backend pushes a request onto TEST_SCRAPE_QUEUE and waits on the request's
+reply key. The worker here scrapes a single date with no retries, counts how
+many elements each selector matches on the page, and pushes back the slots and
+diagnostics. Nothing is written to MongoDB or published for notifications.
+"""
+
+import asyncio
+import json
+import logging
+import time
+from datetime import datetime
+from typing import Any, Dict, List, Optional
+from zoneinfo import ZoneInfo
+
+from .base_scraper import BaseScraper, bookable_dates
+
+TEST_SCRAPE_QUEUE = 'scraper:test_scrape:requests'
+TEST_SCRAPE_REPLY_PREFIX = 'scraper:test_scrape:reply:'  # + request id
+TEST_SCRAPE_REPLY_TTL_SECONDS = 60  # Long enough for the backend to pick up a reply
+
+DEFAULT_TEST_TIMEOUT_SECONDS = 15
+MAX_TEST_TIMEOUT_SECONDS = 20  # Matches the backend, which waits on the scrape within its write timeout
+
+# Selectors the platform scrapers fall back to when selector_mappings doesn't
+# set them; diagnostics cover these too, since a scrape depends on them
+PLATFORM_SELECTORS = {
+    'courtside': {
+        'court_widget': '.court-widget',
+        'closed_message': '.closed-today',
+        'bookable_input': 'input.bookable',
+    },
+    'clubspark': {
+        'booking_sheet': '.booking-sheet',
+        'available_slot': 'a.book-interval.not-booked',
+    },
+}
+
+
+def request_timeout(request: Dict[str, Any]) -> float:
+    """Return the request's timeout in seconds, defaulting and capping it"""
+    try:
+        timeout = int(request.get('timeout_seconds') or 0)
+    except (TypeError, ValueError):
+        timeout = 0
+    if timeout <= 0:
+        timeout = DEFAULT_TEST_TIMEOUT_SECONDS
+    return float(min(timeout, MAX_TEST_TIMEOUT_SECONDS))
+
+
+def build_venue_config(request: Dict[str, Any]) -> Dict[str, Any]:
+    """Build the venue config a platform scraper expects from a test request"""
+    return {
+        '_id': 'test-scrape',
+        'name': request.get('name') or 'Test scrape',
+        'url': request['url'],
+        'courts': [],
+        'timezone': request.get('timezone') or 'Europe/London',
+        'scraper_config': dict(request.get('scraper_config') or {}),
+    }
+
+
+def selectors_to_check(scraper_config: Dict[str, Any]) -> Dict[str, str]:
+    """Return the selectors to check: the platform defaults overridden by selector_mappings"""
+    selectors = dict(PLATFORM_SELECTORS.get(scraper_config.get('type'), {}))
+    selectors.update(scraper_config.get('selector_mappings') or {})
+    return selectors
+
+
+def slot_to_dict(slot) -> Dict[str, Any]:
+    """Serialise a scraped slot for the reply"""
+    return {
+        'court_id': slot.court_id,
+        'court_name': slot.court_name,
+        'date': slot.date,
+        'start_time': slot.start_time,
+        'end_time': slot.end_time,
+        'price': slot.price,
+        'currency': slot.currency,
+        'available': slot.available,
+        'booking_url': slot.booking_url,
+    }
+
+
+async def count_selector_matches(scraper: BaseScraper, page_url: str,
+                                 selectors: Dict[str, str]) -> List[Dict[str, Any]]:
+    """Load page_url once and count the elements each selector matches"""
+    from playwright.async_api import async_playwright
+
+    diagnostics = []
+    async with async_playwright() as p:
+        browser = await p.chromium.launch(headless=True)
+        try:
+            context = await browser.new_context(user_agent=scraper.user_agent,
+                                                extra_http_headers=scraper.extra_headers or None)
+            page = await context.new_page()
+            await page.goto(page_url, timeout=scraper.timeout_seconds * 1000)
+            await page.wait_for_timeout(scraper.config_int('wait_after_load_ms', 3000))
+
+            for name, selector in selectors.items():
+                diagnostic = {'name': name, 'selector': selector, 'matches': 0}
+                try:
+                    diagnostic['matches'] = len(await page.query_selector_all(selector))
+                except Exception as e:
+                    diagnostic['error'] = str(e)
+                diagnostics.append(diagnostic)
+        finally:
+            await browser.close()
+    return diagnostics
+
+
+async def run_test_scrape(request: Dict[str, Any], scrapers: Dict[str, type]) -> Dict[str, Any]:
+    """
+    Run a test scrape and return the reply. The scrape and the selector check
+    run side by side, each bounded by the request's timeout.
+    """
+    started = time.time()
+    reply: Dict[str, Any] = {
+        'request_id': request.get('id'),
+        'success': False,
+        'slots': [],
+        'selectors': [],
+        'errors': [],
+    }
+
+    venue_config = build_venue_config(request)
+    platform = venue_config['scraper_config'].get('type')
+    scraper_class = scrapers.get(platform)
+    if scraper_class is None:
+        reply['errors'].append(f"No scraper available for platform: {platform}")
+        reply['duration_ms'] = int((time.time() - started) * 1000)
+        return reply
+
+    scraper = scraper_class(venue_config)
+    scraper.retry_count = 0
+
+    target_date = request.get('date') or datetime.now(ZoneInfo(venue_config['timezone'])).strftime("%Y-%m-%d")
+    if not bookable_dates([target_date], venue_config):
+        reply['errors'].append(f"Date {target_date} is in the past")
+        reply['duration_ms'] = int((time.time() - started) * 1000)
+        return reply
+
+    timeout = request_timeout(request)
+    scraper.timeout_seconds = min(scraper.timeout_seconds, int(timeout))
+    reply['date'] = target_date
+    reply['user_agent'] = scraper.user_agent
+
+    selectors = selectors_to_check(venue_config['scraper_config'])
+    page_url = scraper._build_date_url(target_date) if hasattr(scraper, '_build_date_url') else venue_config['url']
+
+    async def scrape():
+        return await asyncio.wait_for(scraper.scrape_availability([target_date]), timeout=timeout)
+
+    async def check_selectors():
+        if not selectors:
+            return []
+        return await asyncio.wait_for(count_selector_matches(scraper, page_url, selectors), timeout=timeout)
+
+    scrape_result, selector_result = await asyncio.gather(scrape(), check_selectors(), return_exceptions=True)
+
+    if isinstance(scrape_result, asyncio.TimeoutError):
+        reply['errors'].append(f"Scrape timed out after {timeout:.0f}s")
+    elif isinstance(scrape_result, Exception):
+        reply['errors'].append(f"Scrape failed: {scrape_result}")
+    else:
+        reply['success'] = scrape_result.success
+        reply['slots'] = [slot_to_dict(slot) for slot in scrape_result.slots_found]
+        reply['errors'].extend(scrape_result.errors)
+
+    if isinstance(selector_result, asyncio.TimeoutError):
+        reply['errors'].append(f"Selector check timed out after {timeout:.0f}s")
+    elif isinstance(selector_result, Exception):
+        reply['errors'].append(f"Selector check failed on {page_url}: {selector_result}")
+    else:
+        reply['selectors'] = selector_result
+        reply['page_url'] = page_url
+
+    reply['duration_ms'] = int((time.time() - started) * 1000)
+    return reply
+
+
+class ScrapeTestWorker:
+    """Serves test scrape requests from Redis, one at a time"""
+
+    def __init__(self, redis_client, scrapers: Dict[str, type], block_seconds: int = 5):
+        self.redis = redis_client
+        self.scrapers = scrapers
+        self.block_seconds = block_seconds
+        self.running = False
+        self.logger = logging.getLogger(__name__)
+
+    async def run(self):
+        """Handle requests until stop is called"""
+        self.running = True
+        self.logger.info(f"🧪 Listening for test scrape requests on {TEST_SCRAPE_QUEUE}")
+        loop = asyncio.get_running_loop()
+        while self.running:
+            try:
+                item = await loop.run_in_executor(None, self.redis.blpop, [TEST_SCRAPE_QUEUE], self.block_seconds)
+                if item:
+                    await self.handle(item[1])
+            except Exception as e:
+                self.logger.error(f"Test scrape worker error: {e}")
+                await asyncio.sleep(self.block_seconds)
+
+    def stop(self):
+        self.running = False
+
+    async def handle(self, message: str) -> Optional[Dict[str, Any]]:
+        """Run one request and push the reply. Returns the reply, or None if the request was dropped."""
+        try:
+            request = json.loads(message)
+        except (TypeError, ValueError):
+            self.logger.warning("Dropping test scrape request that isn't JSON")
+            return None
+
+        request_id = request.get('id')
+        if not request_id or not request.get('url'):
+            self.logger.warning("Dropping test scrape request without an id or url")
+            return None
+
+        # The backend has stopped waiting for requests that sat in the queue too long
+        expires_at = request.get('expires_at')
+        if expires_at and time.time() > float(expires_at):
+            self.logger.info(f"Dropping expired test scrape request {request_id}")
+            return None
+
+        self.logger.info(f"🧪 Test scrape {request_id} of {request['url']}")
+        reply = await run_test_scrape(request, self.scrapers)
+
+        reply_key = TEST_SCRAPE_REPLY_PREFIX + request_id
+        self.redis.rpush(reply_key, json.dumps(reply, default=str))
+        self.redis.expire(reply_key, TEST_SCRAPE_REPLY_TTL_SECONDS)
+        return reply
@@ -11,7 +11,7 @@ import os
 import sys
 import time
 from datetime import datetime
-from typing import List, Dict, Any
+from typing import List, Dict, Any, Optional
 from pymongo import MongoClient
 from bson import ObjectId
 
@@ -19,9 +19,14 @@ from bson import ObjectId
 try:
     # Try relative imports first (when run as module)
     from ..redis_publisher import RedisPublisher
-    from .base_scraper import ScrapedSlot, ScrapingResult
+    from .base_scraper import ScrapedSlot, ScrapingResult, bookable_dates
     from .courtside_scraper import CourtsideScraper
     from .clubspark_scraper import ClubSparkScraper
+    from .playtomic_scraper import PlaytomicScraper
+    from .scrape_tasks import ScrapeTask, ScrapeTaskTracker
+    from .proxy_pool import ProxyPool, venue_uses_proxy
+    from .selector_health import SelectorHealthMonitor
+    from .price_history import record_price_changes
 except ImportError:
     # Fallback for when running as script - add parent directories to path
     current_dir = os.path.dirname(os.path.abspath(__file__))
@@ -29,9 +34,14 @@ except ImportError:
     sys.path.insert(0, parent_dir)
     
     from redis_publisher import RedisPublisher
-    from scrapers.base_scraper import ScrapedSlot, ScrapingResult
+    from scrapers.base_scraper import ScrapedSlot, ScrapingResult, bookable_dates
     from scrapers.courtside_scraper import CourtsideScraper
     from scrapers.clubspark_scraper import ClubSparkScraper
+    from scrapers.playtomic_scraper import PlaytomicScraper
+    from scrapers.scrape_tasks import ScrapeTask, ScrapeTaskTracker
+    from scrapers.proxy_pool import ProxyPool, venue_uses_proxy
+    from scrapers.selector_health import SelectorHealthMonitor
+    from scrapers.price_history import record_price_changes
 
 # Import Redis deduplicator
 try:
@@ -68,12 +78,20 @@ class ScraperOrchestrator:
             expiry_hours=int(os.getenv("REDIS_DEDUPE_EXPIRY_HOURS", "48"))
         )
         
-        # Scraper registry - enable both platforms by default
+        # Proxies for venues that opt in; reloaded from the proxies collection each session
+        self.proxy_pool = ProxyPool.from_env()
+        
+        # Spots venues whose selectors have stopped matching
+        self.selector_health = SelectorHealthMonitor()
+        
+        # Scraper registry - enable all platforms by default
         self.scrapers = {}
         if os.getenv('COURTSIDE_ENABLED', 'true').lower() == 'true':
             self.scrapers['courtside'] = CourtsideScraper
         if os.getenv('CLUBSPARK_ENABLED', 'true').lower() == 'true':
             self.scrapers['clubspark'] = ClubSparkScraper
+        if os.getenv('PLAYTOMIC_ENABLED', 'true').lower() == 'true':
+            self.scrapers['playtomic'] = PlaytomicScraper
         
     def setup_logging(self):
         """Configure logging"""
@@ -127,7 +145,8 @@ class ScraperOrchestrator:
             venues_collection = self.db.venues
             
             # Build query filter
-            query = {}
+            # Venues seeded as examples stay inactive until configured
+            query = {"is_active": {"$ne": False}}
             if venue_names:
                 query["name"] = {"$in": venue_names}
                 
@@ -144,8 +163,12 @@ class ScraperOrchestrator:
             self.logger.error(f"Failed to load venues: {e}")
             return []
             
-    async def scrape_venue(self, venue_config: Dict[str, Any], target_dates: List[str] = None) -> ScrapingResult:
-        """Scrape a single venue using the appropriate platform scraper"""
+    async def scrape_venue(self, venue_config: Dict[str, Any], target_dates: List[str] = None,
+                           task: Optional[ScrapeTask] = None) -> ScrapingResult:
+        """
+        Scrape a single venue using the appropriate platform scraper. With a
+        task, only slots for the courts that task covers are kept.
+        """
         
         platform_type = venue_config['scraper_config']['type']
         venue_name = venue_config['name']
@@ -166,26 +189,52 @@ class ScraperOrchestrator:
             
         # Create platform-specific scraper
         scraper_class = self.scrapers[platform_type]
-        scraper = scraper_class(venue_config)
+        scraper = scraper_class(venue_config, self.proxy_pool)
         
         # Use default dates if none provided
         if not target_dates:
             days_ahead = int(os.getenv('SCRAPER_DAYS_AHEAD', '7'))
             target_dates = scraper.get_target_dates(days_ahead=days_ahead)
             
+        # Don't spend scrapes on dates the venue won't take bookings for yet
+        requested = len(target_dates)
+        target_dates = bookable_dates(target_dates, venue_config)
+        if len(target_dates) < requested:
+            self.logger.info(f"Skipping {requested - len(target_dates)} dates outside {venue_name}'s booking window")
+        if not target_dates:
+            return ScrapingResult(
+                venue_id=venue_config['_id'],
+                venue_name=venue_name,
+                platform=platform_type,
+                success=True,
+                slots_found=[],
+                errors=[],
+                duration_ms=0,
+                scraped_at=datetime.now()
+            )
+            
         self.logger.info(f"Starting scrape for {venue_name} ({platform_type}) - {len(target_dates)} dates")
         
         # Run the scraper
-        result = await scraper.scrape_availability(target_dates)
+        result = await scraper.scrape_with_retries(target_dates)
         
+        if task is not None:
+            result.slots_found = [slot for slot in result.slots_found if task.covers(slot, venue_config['courts'])]
+            
         self.logger.info(f"Completed scrape for {venue_name}: {len(result.slots_found)} slots, "
                         f"success={result.success}, duration={result.duration_ms}ms")
         
         return result
         
     async def scrape_all_venues(self, venue_names: List[str] = None, 
-                               target_dates: List[str] = None) -> List[ScrapingResult]:
-        """Scrape all active venues"""
+                               target_dates: List[str] = None,
+                               tracker: Optional[ScrapeTaskTracker] = None) -> List[ScrapingResult]:
+        """
+        Scrape all active venues. With a tracker, only the scrape tasks that
+        are due are run, so courts with their own interval are scraped on it.
+        In adaptive mode the tracker's venue intervals are recomputed here
+        when they are stale.
+        """
         
         if not self.mongo_client:
             self.connect_mongodb()
@@ -195,12 +244,29 @@ class ScraperOrchestrator:
             self.logger.warning("No venues to scrape")
             return []
             
+        if any(venue_uses_proxy(venue) for venue in venues):
+            self.proxy_pool.refresh(self.db)
+            if not self.proxy_pool.proxies:
+                self.logger.warning("Venues are set to use_proxy but no proxies are configured; scraping them directly")
+            
+        if tracker is not None:
+            if tracker.adaptive is not None and tracker.adaptive.is_stale(datetime.now()):
+                tracker.adaptive.refresh(self.db, datetime.now())
+            scheduled = tracker.due_tasks(venues, datetime.now())
+            self.logger.info(f"{len(scheduled)} scrape tasks due")
+        else:
+            scheduled = [(venue, None) for venue in venues]
+            
         results = []
         
         # Scrape venues sequentially to avoid overwhelming target sites
-        for venue in venues:
+        for venue, task in scheduled:
             try:
-                result = await self.scrape_venue(venue, target_dates)
+                if tracker is not None:
+                    # Mark up front so a failing task waits its interval rather than retrying every tick
+                    tracker.mark_run(task, datetime.now())
+                    
+                result = await self.scrape_venue(venue, target_dates, task)
                 results.append(result)
                 
                 # Store results in MongoDB
@@ -268,6 +334,15 @@ class ScraperOrchestrator:
                     }
                     slots_data.append(slot_doc)
                 
+                # Prices are tracked for every slot scraped, since deduplication
+                # skips slots seen recently even when their price has changed
+                try:
+                    price_changes = record_price_changes(self.db.price_history, slots_data, result.scraped_at)
+                    if price_changes:
+                        self.logger.info(f"💷 Recorded {price_changes} price changes for {result.venue_name}")
+                except Exception as e:
+                    self.logger.error(f"Failed to record price history: {e}")
+                
                 # Use Redis deduplication to filter out recently seen slots
                 self.logger.debug(f"Checking {len(slots_data)} slots for duplicates using Redis deduplication")
                 new_slots, duplicate_slots = self.redis_deduplicator.check_multiple_slots(slots_data)
@@ -293,8 +368,10 @@ class ScraperOrchestrator:
                     # Upsert the slot
                     slots_collection.replace_one(filter_query, slot_doc, upsert=True)
                     
-                    # If it's a new available slot, add to notification queue
-                    if is_new_slot and slot_doc["available"]:
+                    # Publish new available slots, and known slots whose availability changed,
+                    # so the notification service can tell when a booked slot frees up again
+                    availability_changed = not is_new_slot and existing_slot.get("available") != slot_doc["available"]
+                    if (is_new_slot and slot_doc["available"]) or availability_changed:
                         notification_slot = {
                             'venueId': str(slot_doc["venue_id"]),
                             'venueName': slot_doc["venue_name"],
@@ -305,12 +382,16 @@ class ScraperOrchestrator:
                             'startTime': slot_doc["start_time"],
                             'endTime': slot_doc["end_time"],
                             'price': float(slot_doc["price"]),
+                            'currency': slot_doc.get("currency", "GBP"),
                             'isAvailable': slot_doc["available"],
                             'bookingUrl': slot_doc["booking_url"],
                             'scrapedAt': slot_doc["scraped_at"].strftime('%Y-%m-%dT%H:%M:%SZ')
                         }
                         new_slots_for_notification.append(notification_slot)
-                        self.logger.info(f"🆕 New slot detected: {result.venue_name} - {slot_doc['court_name']} on {slot_doc['date']} at {slot_doc['start_time']}")
+                        if is_new_slot:
+                            self.logger.info(f"🆕 New slot detected: {result.venue_name} - {slot_doc['court_name']} on {slot_doc['date']} at {slot_doc['start_time']}")
+                        else:
+                            self.logger.info(f"🔁 Availability changed to {slot_doc['available']}: {result.venue_name} - {slot_doc['court_name']} on {slot_doc['date']} at {slot_doc['start_time']}")
                     
                 self.logger.info(f"Processed {len(new_slots)} new slots for {result.venue_name} (skipped {duplicate_slots_count} duplicates)")
                 
@@ -339,9 +420,21 @@ class ScraperOrchestrator:
                 "slots_found": len(result.slots_found),
                 "scrape_duration_ms": result.duration_ms,
                 "errors": result.errors,
+                "user_agent": result.user_agent,
+                "attempts": result.attempts,
+                "retry_succeeded": result.success and result.attempts > 1,
                 "created_at": datetime.now()
             }
             
+            # An empty result from a venue that usually has slots may mean its page changed
+            if result.success and not result.slots_found:
+                suspicion = self.selector_health.evaluate(self.db, log_doc["venue_id"], result.scraped_at)
+                if suspicion is not None:
+                    log_doc["suspected_broken_selector"] = True
+                    if suspicion.new_streak:
+                        self.selector_health.alert(result.venue_id, result.venue_name, result.platform,
+                                                   suspicion, result.scraped_at)
+            
             logs_collection = self.db.scraping_logs
             logs_collection.insert_one(log_doc)
             
@@ -377,8 +470,9 @@ class ScraperOrchestrator:
             self.logger.error(f"Failed to update last scrape time: {e}")
 
     async def run_scraping_session(self, venue_names: List[str] = None, 
-                                  target_dates: List[str] = None):
-        """Run a complete scraping session"""
+                                  target_dates: List[str] = None,
+                                  tracker: Optional[ScrapeTaskTracker] = None):
+        """Run a complete scraping session, limited to due tasks when a tracker is given"""
         session_start = time.time()
         
         try:
@@ -387,7 +481,7 @@ class ScraperOrchestrator:
             # Ensure MongoDB connection is established
             self.connect_mongodb()
             
-            results = await self.scrape_all_venues(venue_names, target_dates)
+            results = await self.scrape_all_venues(venue_names, target_dates, tracker)
             
             # Summary statistics
             total_venues = len(results)
@@ -0,0 +1,177 @@
+#!/usr/bin/env python3
+
+"""
+Detection of selectors that have silently stopped matching.
+
+When a venue changes its pages, its selectors match nothing and the scrape
+still "succeeds" with zero slots, which looks just like a fully booked venue.
+A venue is suspect when its last few successful scrapes all found nothing even
+though, over the baseline window before them, most of its scrapes found slots.
+The scraping log of each suspect scrape gets suspected_broken_selector, and
+the first one in a streak posts an ops alert to SCRAPER_OPS_WEBHOOK_URL.
+"""
+
+import logging
+import os
+from dataclasses import dataclass
+from datetime import datetime, timedelta
+from typing import Any, Dict, List, Optional
+
+import requests
+
+from .adaptive_schedule import SLOTS_FOUND_COUNT
+
+OPS_ALERT_EVENT = 'suspected_broken_selector'
+OPS_ALERT_TIMEOUT_SECONDS = 5
+
+
+def _env_int(name: str, default: int) -> int:
+    """Read a positive integer from the environment, falling back to default"""
+    try:
+        value = int(os.getenv(name, default))
+    except (TypeError, ValueError):
+        return default
+    return value if value > 0 else default
+
+
+def _env_rate(name: str, default: float) -> float:
+    """Read a rate between 0 and 1 from the environment, falling back to default"""
+    try:
+        value = float(os.getenv(name, default))
+    except (TypeError, ValueError):
+        return default
+    return value if 0 < value <= 1 else default
+
+
+def slot_count(slots_found: Any) -> int:
+    """Return a scraping log's slots_found as a number, whether it holds a count or the slots"""
+    if isinstance(slots_found, list):
+        return len(slots_found)
+    return slots_found or 0
+
+
+@dataclass(frozen=True)
+class SelectorHealthConfig:
+    """When a run of empty scrapes counts as broken selectors"""
+    threshold: int = 3  # Consecutive successful scrapes with no slots
+    baseline_days: int = 7  # History before the streak that sets the venue's baseline
+    min_hit_rate: float = 0.5  # Share of baseline scrapes that must have found slots
+    min_baseline_scrapes: int = 10  # Baseline scrapes needed before a venue is judged
+
+    @classmethod
+    def from_env(cls) -> 'SelectorHealthConfig':
+        """Read the SCRAPER_SELECTOR_* settings"""
+        defaults = cls()
+        return cls(
+            threshold=_env_int('SCRAPER_SELECTOR_ALERT_THRESHOLD', defaults.threshold),
+            baseline_days=_env_int('SCRAPER_SELECTOR_BASELINE_DAYS', defaults.baseline_days),
+            min_hit_rate=_env_rate('SCRAPER_SELECTOR_MIN_HIT_RATE', defaults.min_hit_rate),
+            min_baseline_scrapes=_env_int('SCRAPER_SELECTOR_MIN_BASELINE_SCRAPES', defaults.min_baseline_scrapes),
+        )
+
+
+def baseline_pipeline(venue_id: Any, since: datetime, before: datetime) -> List[Dict[str, Any]]:
+    """Count a venue's successful scrapes in a window and how many found slots"""
+    return [
+        {'$match': {
+            'venue_id': venue_id,
+            'success': True,
+            'scrape_timestamp': {'$gte': since, '$lt': before},
+        }},
+        {'$group': {
+            '_id': None,
+            'scrapes': {'$sum': 1},
+            'hits': {'$sum': {'$cond': [{'$gt': [SLOTS_FOUND_COUNT, 0]}, 1, 0]}},
+        }},
+    ]
+
+
+@dataclass
+class SelectorSuspicion:
+    """Why a scrape's empty result looks like broken selectors"""
+    empty_scrapes: int  # Including the scrape being checked
+    baseline_hit_rate: float
+    baseline_scrapes: int
+    new_streak: bool  # False when earlier scrapes in the streak were already flagged
+
+
+class SelectorHealthMonitor:
+    """Flags venues whose scrapes suddenly stop finding slots, and alerts ops"""
+
+    def __init__(self, config: Optional[SelectorHealthConfig] = None, webhook_url: Optional[str] = None):
+        self.config = config or SelectorHealthConfig.from_env()
+        self.webhook_url = webhook_url if webhook_url is not None else os.getenv('SCRAPER_OPS_WEBHOOK_URL', '')
+        self.logger = logging.getLogger(__name__)
+
+    def evaluate(self, db, venue_id: Any, now: datetime) -> Optional[SelectorSuspicion]:
+        """
+        Decide whether a successful scrape that found no slots, and is about to
+        be logged, looks like broken selectors. Returns None when it doesn't,
+        when the venue has too little history to judge, or when the check
+        itself fails.
+        """
+        try:
+            # The scrape being checked isn't logged yet, so the rest of the streak is before it
+            previous = []
+            if self.config.threshold > 1:
+                previous = list(db.scraping_logs.find(
+                    {'venue_id': venue_id, 'success': True},
+                    {'slots_found': 1, 'scrape_timestamp': 1, 'suspected_broken_selector': 1},
+                ).sort('scrape_timestamp', -1).limit(self.config.threshold - 1))
+
+            if len(previous) < self.config.threshold - 1:
+                return None
+            if any(slot_count(log.get('slots_found')) > 0 for log in previous):
+                return None
+
+            streak_start = previous[-1]['scrape_timestamp'] if previous else now
+            since = streak_start - timedelta(days=self.config.baseline_days)
+            stats = next(iter(db.scraping_logs.aggregate(baseline_pipeline(venue_id, since, streak_start))), None)
+        except Exception as e:
+            self.logger.error(f"Failed to check selector health for venue {venue_id}: {e}")
+            return None
+
+        scrapes = (stats or {}).get('scrapes') or 0
+        if scrapes < self.config.min_baseline_scrapes:
+            return None
+        hit_rate = ((stats or {}).get('hits') or 0) / scrapes
+        if hit_rate < self.config.min_hit_rate:
+            return None
+
+        return SelectorSuspicion(
+            empty_scrapes=len(previous) + 1,
+            baseline_hit_rate=hit_rate,
+            baseline_scrapes=scrapes,
+            new_streak=not any(log.get('suspected_broken_selector') for log in previous),
+        )
+
+    def alert(self, venue_id: str, venue_name: str, platform: str, suspicion: SelectorSuspicion,
+              now: datetime) -> bool:
+        """Post an ops alert about a suspect venue. Returns whether it was delivered."""
+        text = (f"{venue_name} ({platform}) returned no slots in {suspicion.empty_scrapes} scrapes in a row, "
+                f"but {suspicion.baseline_hit_rate:.0%} of its previous {suspicion.baseline_scrapes} scrapes "
+                f"found slots. Its selectors may no longer match the site.")
+        self.logger.error(f"Suspected broken selectors: {text}")
+
+        if not self.webhook_url:
+            self.logger.warning("SCRAPER_OPS_WEBHOOK_URL is not set; suspected broken selectors were only logged")
+            return False
+
+        payload = {
+            'event': OPS_ALERT_EVENT,
+            'text': text,  # Shown as-is by Slack-style incoming webhooks
+            'venue_id': str(venue_id),
+            'venue_name': venue_name,
+            'platform': platform,
+            'empty_scrapes': suspicion.empty_scrapes,
+            'baseline_hit_rate': round(suspicion.baseline_hit_rate, 3),
+            'baseline_scrapes': suspicion.baseline_scrapes,
+            'detected_at': now.isoformat(),
+        }
+        try:
+            response = requests.post(self.webhook_url, json=payload, timeout=OPS_ALERT_TIMEOUT_SECONDS)
+            response.raise_for_status()
+        except Exception as e:
+            self.logger.error(f"Failed to send ops alert for {venue_name}: {e}")
+            return False
+        return True
@@ -0,0 +1,82 @@
+#!/usr/bin/env python3
+
+"""
+Login credentials and persisted browser sessions for venues that need an account.
+
+Credentials come from the environment, which is where the secrets manager puts
+them: VENUE_<KEY>_USERNAME and VENUE_<KEY>_PASSWORD, where KEY is the venue's
+scraper_config.credential_key (or its id if unset), upper-cased with anything
+that isn't a letter or digit replaced by an underscore.
+"""
+
+import logging
+import os
+import re
+from pathlib import Path
+from typing import Optional, Tuple
+
+DEFAULT_SESSION_DIR = ".sessions"
+
+logger = logging.getLogger(__name__)
+
+
+def credential_env_prefix(key: str) -> str:
+    """Return the environment variable prefix holding a venue's credentials"""
+    return "VENUE_" + re.sub(r'[^A-Za-z0-9]', '_', str(key)).upper()
+
+
+def get_venue_credentials(venue_id: str, credential_key: Optional[str] = None) -> Optional[Tuple[str, str]]:
+    """
+    Look up the login for a venue.
+
+    Args:
+        venue_id: The venue's id, used when no credential key is configured
+        credential_key: scraper_config.credential_key, if set
+
+    Returns:
+        Tuple of (username, password), or None if either is missing
+    """
+    prefix = credential_env_prefix(credential_key or venue_id)
+    username = os.getenv(f"{prefix}_USERNAME")
+    password = os.getenv(f"{prefix}_PASSWORD")
+
+    if not username or not password:
+        return None
+    return username, password
+
+
+class SessionStore:
+    """
+    Persists each venue's Playwright storage state (its cookies) to disk, so a
+    login is reused across scrapes and survives restarts until it expires.
+    """
+
+    def __init__(self, directory: Optional[str] = None):
+        self.directory = Path(directory or os.getenv("SCRAPER_SESSION_DIR", DEFAULT_SESSION_DIR))
+
+    def path_for(self, venue_id: str) -> Path:
+        """Return the storage state file for a venue"""
+        safe_id = re.sub(r'[^A-Za-z0-9_-]', '_', str(venue_id))
+        return self.directory / f"{safe_id}.json"
+
+    def load(self, venue_id: str) -> Optional[str]:
+        """Return the saved storage state path for a venue, if there is one"""
+        path = self.path_for(venue_id)
+        if path.is_file():
+            return str(path)
+        return None
+
+    def save_path(self, venue_id: str) -> str:
+        """Return the path to save a venue's storage state to, creating the directory"""
+        # Session cookies are as good as a password, so keep them private
+        self.directory.mkdir(mode=0o700, parents=True, exist_ok=True)
+        return str(self.path_for(venue_id))
+
+    def clear(self, venue_id: str):
+        """Forget a venue's session, e.g. once it has expired"""
+        try:
+            self.path_for(venue_id).unlink()
+        except FileNotFoundError:
+            pass
+        except OSError as e:
+            logger.warning(f"Could not remove session for {venue_id}: {e}")
@@ -0,0 +1,154 @@
+import sys
+import os
+from datetime import datetime, timedelta
+
+# Add the src directory to the Python path
+sys.path.append(os.path.join(os.path.dirname(__file__), '..', 'src'))
+
+from scrapers.adaptive_schedule import (
+    AdaptiveConfig,
+    AdaptiveSchedule,
+    hit_rate_pipeline,
+    interval_for_hit_rate,
+    schedule_mode_from_env,
+)
+from scrapers.scrape_tasks import ScrapeTaskTracker
+
+
+CONFIG = AdaptiveConfig(min_interval=10, max_interval=110, recompute_minutes=60, min_samples=3)
+
+
+def make_venue(venue_id, courts=None, scraping_interval=30):
+    return {
+        '_id': venue_id,
+        'name': f'Venue {venue_id}',
+        'scraping_interval': scraping_interval,
+        'courts': courts or [],
+    }
+
+
+class FakeCollection:
+    def __init__(self, stats=None, error=None):
+        self.stats = stats or []
+        self.error = error
+        self.pipelines = []
+
+    def aggregate(self, pipeline):
+        self.pipelines.append(pipeline)
+        if self.error:
+            raise self.error
+        return iter(self.stats)
+
+
+class FakeDB:
+    def __init__(self, collection):
+        self.scraping_logs = collection
+
+
+class TestIntervalForHitRate:
+    def test_maps_hit_rate_onto_bounds(self):
+        """Test that venues that always find slots get the minimum and those that never do the maximum."""
+        assert interval_for_hit_rate(1.0, CONFIG) == 10
+        assert interval_for_hit_rate(0.0, CONFIG) == 110
+        assert interval_for_hit_rate(0.5, CONFIG) == 60
+
+    def test_clamps_out_of_range_rates(self):
+        """Test that rates outside 0-1 stay within the bounds."""
+        assert interval_for_hit_rate(1.5, CONFIG) == 10
+        assert interval_for_hit_rate(-1, CONFIG) == 110
+
+
+class TestAdaptiveConfig:
+    def test_reads_environment(self, monkeypatch):
+        """Test that reversed bounds are swapped and invalid values use the defaults."""
+        monkeypatch.setenv('SCRAPER_ADAPTIVE_MIN_INTERVAL', '90')
+        monkeypatch.setenv('SCRAPER_ADAPTIVE_MAX_INTERVAL', '15')
+        monkeypatch.setenv('SCRAPER_ADAPTIVE_LOOKBACK_HOURS', 'soon')
+
+        config = AdaptiveConfig.from_env()
+
+        assert (config.min_interval, config.max_interval) == (15, 90)
+        assert config.lookback_hours == AdaptiveConfig().lookback_hours
+
+    def test_schedule_mode_defaults_to_fixed(self, monkeypatch):
+        """Test that only an explicit adaptive mode turns adaptive scheduling on."""
+        monkeypatch.delenv('SCRAPER_SCHEDULE_MODE', raising=False)
+        assert schedule_mode_from_env() == 'fixed'
+
+        monkeypatch.setenv('SCRAPER_SCHEDULE_MODE', 'Adaptive')
+        assert schedule_mode_from_env() == 'adaptive'
+
+        monkeypatch.setenv('SCRAPER_SCHEDULE_MODE', 'cron')
+        assert schedule_mode_from_env() == 'fixed'
+
+
+class TestAdaptiveSchedule:
+    def test_refresh_computes_intervals_from_hit_rates(self):
+        """Test that each venue with enough history gets an interval from its hit rate."""
+        collection = FakeCollection(stats=[
+            {'_id': 'hot', 'scrapes': 10, 'hits': 9},
+            {'_id': 'cold', 'scrapes': 10, 'hits': 0},
+            {'_id': 'new', 'scrapes': 2, 'hits': 2},  # Too few scrapes to judge
+        ])
+        schedule = AdaptiveSchedule(CONFIG)
+        now = datetime(2024, 1, 1, 9, 0)
+
+        schedule.refresh(FakeDB(collection), now)
+
+        assert schedule.interval_for('hot') == 20
+        assert schedule.interval_for('cold') == 110
+        assert schedule.interval_for('new') is None
+        assert collection.pipelines[0][0]['$match']['scrape_timestamp']['$gte'] == now - timedelta(hours=CONFIG.lookback_hours)
+
+    def test_recomputes_periodically(self):
+        """Test that intervals go stale after the recompute period."""
+        schedule = AdaptiveSchedule(CONFIG)
+        now = datetime(2024, 1, 1, 9, 0)
+        assert schedule.is_stale(now)
+
+        schedule.update([], now)
+
+        assert not schedule.is_stale(now + timedelta(minutes=59))
+        assert schedule.is_stale(now + timedelta(minutes=60))
+
+    def test_failed_refresh_keeps_previous_intervals(self):
+        """Test that a database error doesn't reset venues to their configured intervals."""
+        schedule = AdaptiveSchedule(CONFIG)
+        now = datetime(2024, 1, 1, 9, 0)
+        schedule.update([{'_id': 'hot', 'scrapes': 4, 'hits': 4}], now)
+
+        schedule.refresh(FakeDB(FakeCollection(error=RuntimeError('mongo down'))), now + timedelta(hours=1))
+
+        assert schedule.interval_for('hot') == 10
+        assert not schedule.is_stale(now + timedelta(hours=1, minutes=30))
+
+    def test_pipeline_counts_slots_found_as_count_or_list(self):
+        """Test that hits are counted whether slots_found holds a count or the slots."""
+        group = hit_rate_pipeline(datetime(2024, 1, 1))[1]['$group']
+        slots_found = group['hits']['$sum']['$cond'][0]['$gt'][0]
+
+        assert slots_found['$cond'][0] == {'$isArray': '$slots_found'}
+
+
+class TestTrackerWithAdaptiveSchedule:
+    def test_venue_task_uses_adaptive_interval(self):
+        """Test that adaptive intervals replace venue intervals but not court overrides."""
+        schedule = AdaptiveSchedule(CONFIG)
+        start = datetime(2024, 1, 1, 9, 0)
+        schedule.update([{'_id': 'hot', 'scrapes': 10, 'hits': 10}], start)
+
+        tracker = ScrapeTaskTracker(default_interval=60, adaptive=schedule)
+        venues = [
+            make_venue('hot', courts=[{'id': '2', 'name': 'Court 2', 'scrape_interval_minutes': 5}]),
+            make_venue('quiet'),
+        ]
+
+        due = tracker.due_tasks(venues, start)
+        intervals = {(task.venue_id, task.court_ids): task.interval_minutes for _, task in due}
+        assert intervals == {('hot', ()): 10, ('hot', ('2',)): 5, ('quiet', ()): 30}
+
+        for _, task in due:
+            tracker.mark_run(task, start)
+        # The quiet venue keeps its configured 30 minutes
+        due = tracker.due_tasks(venues, start + timedelta(minutes=10))
+        assert {(task.venue_id, task.court_ids) for _, task in due} == {('hot', ()), ('hot', ('2',))}
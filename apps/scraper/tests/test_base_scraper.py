@@ -1,3 +1,4 @@
+import asyncio
 import sys
 import os
 import pytest
@@ -7,7 +8,7 @@ from datetime import datetime, timedelta
 # Add the src directory to the Python path
 sys.path.append(os.path.join(os.path.dirname(__file__), '..', 'src'))
 
-from scrapers.base_scraper import BaseScraper
+from scrapers.base_scraper import BROWSER_USER_AGENTS, BaseScraper, bookable_dates
 
 # Create a concrete implementation for testing
 class ConcreteScraper(BaseScraper):
@@ -15,6 +16,32 @@ class ConcreteScraper(BaseScraper):
         """Concrete implementation for testing."""
         return self.create_scraping_result(True, [], [], 0)
 
+class FlakyScraper(BaseScraper):
+    """Fails, or hangs past the timeout, for the first few attempts and then succeeds"""
+    def __init__(self, venue_config, failures, hang=False):
+        super().__init__(venue_config)
+        self.failures = failures
+        self.hang = hang
+        self.calls = 0
+        self.retry_backoff_seconds = 0
+
+    async def scrape_availability(self, target_dates):
+        self.calls += 1
+        if self.calls <= self.failures:
+            if self.hang:
+                await asyncio.sleep(10)
+            raise ConnectionError("connection reset by peer")
+        return self.create_scraping_result(True, [], [], 0)
+
+def retry_venue(**scraper_config):
+    return {
+        "_id": "test_venue_id",
+        "name": "Test Venue",
+        "url": "https://example.com",
+        "courts": [],
+        "scraper_config": {"type": "test_provider", **scraper_config},
+    }
+
 class TestBaseScraper:
     def test_base_scraper_init(self):
         """Test that the BaseScraper initializes correctly."""
@@ -45,6 +72,43 @@ class TestBaseScraper:
         assert len(scraper.courts) == 2
         assert scraper.scraper_config["timeoutSeconds"] == 30
 
+    @pytest.mark.asyncio
+    async def test_user_agent_and_headers(self):
+        """Test that a configured user agent is used and recorded, and headers can't override it."""
+        venue = {
+            "_id": "test_venue_id",
+            "name": "Test Venue",
+            "url": "https://example.com",
+            "courts": [],
+            "scraper_config": {
+                "type": "test_provider",
+                "user_agent": "Mozilla/5.0 Test",
+                "headers": {"Referer": "https://example.com/", "Accept-Language": "en-GB", "user-agent": "ignored"},
+            }
+        }
+
+        scraper = ConcreteScraper(venue)
+        result = await scraper.scrape_availability([])
+
+        assert scraper.user_agent == "Mozilla/5.0 Test"
+        assert scraper.extra_headers == {"Referer": "https://example.com/", "Accept-Language": "en-GB"}
+        assert result.user_agent == "Mozilla/5.0 Test"
+
+    def test_user_agent_defaults_to_browser_rotation(self):
+        """Test that venues without a user agent get one of the built-in browser agents."""
+        venue = {
+            "_id": "test_venue_id",
+            "name": "Test Venue",
+            "url": "https://example.com",
+            "courts": [],
+            "scraper_config": {"type": "test_provider"}
+        }
+
+        scraper = ConcreteScraper(venue)
+
+        assert scraper.user_agent in BROWSER_USER_AGENTS
+        assert scraper.extra_headers == {}
+
     def test_get_target_dates_default(self):
         """Test get_target_dates with default 7 days ahead."""
         venue = {
@@ -116,4 +180,63 @@ class TestBaseScraper:
             assert date_str[4] == '-'
             assert date_str[7] == '-'
             # Verify it can be parsed as a date
-            datetime.strptime(date_str, "%Y-%m-%d") 
\ No newline at end of file
+            datetime.strptime(date_str, "%Y-%m-%d") 
+class TestBookableDates:
+    def test_clamps_to_booking_window(self):
+        """Test that dates past the venue's booking window are dropped."""
+        today = datetime(2024, 6, 10).date()
+        dates = ['2024-06-09', '2024-06-10', '2024-06-12', '2024-06-13', '2024-06-20']
+
+        assert bookable_dates(dates, {'booking_window': 3}, today=today) == ['2024-06-10', '2024-06-12', '2024-06-13']
+
+    def test_without_window_only_drops_past_dates(self):
+        """Test that venues without a booking window keep every future date."""
+        today = datetime(2024, 6, 10).date()
+
+        assert bookable_dates(['2024-06-09', '2024-07-01'], {}, today=today) == ['2024-07-01']
+
+
+class TestScrapeWithRetries:
+    @pytest.mark.asyncio
+    async def test_retries_until_success(self):
+        """Test that a failed scrape is retried and the result records the attempts."""
+        scraper = FlakyScraper(retry_venue(retry_count=2), failures=2)
+
+        result = await scraper.scrape_with_retries(["2024-06-09"])
+
+        assert result.success is True
+        assert result.attempts == 3
+        assert scraper.calls == 3
+
+    @pytest.mark.asyncio
+    async def test_gives_up_after_retry_count(self):
+        """Test that the last failure is returned once the retries are used up."""
+        scraper = FlakyScraper(retry_venue(retry_count=1), failures=5)
+
+        result = await scraper.scrape_with_retries(["2024-06-09"])
+
+        assert result.success is False
+        assert result.attempts == 2
+        assert "connection reset" in result.errors[0]
+
+    @pytest.mark.asyncio
+    async def test_unset_retry_count_scrapes_once(self):
+        """Test that a venue stored with retry_count 0 is not retried."""
+        scraper = FlakyScraper(retry_venue(retry_count=0, timeout_seconds=0, wait_after_load_ms=0), failures=1)
+
+        result = await scraper.scrape_with_retries([])
+
+        assert result.attempts == 1
+        assert scraper.calls == 1
+        assert scraper.timeout_seconds == 30  # 0 means the venue never set it
+
+    @pytest.mark.asyncio
+    async def test_attempt_that_hangs_times_out(self):
+        """Test that an attempt running past its timeout is abandoned and retried."""
+        scraper = FlakyScraper(retry_venue(retry_count=1), failures=1, hang=True)
+        scraper.scrape_timeout = lambda target_dates: 0.05
+
+        result = await scraper.scrape_with_retries([])
+
+        assert result.success is True
+        assert result.attempts == 2
@@ -0,0 +1,152 @@
+import sys
+import os
+import pytest
+from unittest.mock import Mock
+
+# Add the src directory to the Python path
+sys.path.append(os.path.join(os.path.dirname(__file__), '..', 'src'))
+
+from scrapers.playtomic_scraper import PlaytomicScraper
+from scrapers.proxy_pool import Proxy, ProxyPool
+
+
+def make_venue(**custom_parameters):
+    return {
+        "_id": "665f1c2ab3e4d5f6a7b8c9d0",
+        "name": "Example Padel & Tennis",
+        "url": "https://playtomic.io/example-club/tenant-123",
+        "timezone": "Europe/London",
+        "courts": [{"id": "res-1", "name": "Centre Court"}],
+        "scraper_config": {
+            "type": "playtomic",
+            "custom_parameters": {"tenant_id": "tenant-123", **custom_parameters},
+        },
+    }
+
+
+def json_response(payload):
+    response = Mock()
+    response.json.return_value = payload
+    response.raise_for_status.return_value = None
+    return response
+
+
+class TestPlaytomicScraper:
+    def test_parse_availability_converts_utc_to_local_time(self):
+        """Test that UTC slot times are stored in the venue's local time."""
+        scraper = PlaytomicScraper(make_venue())
+        entries = [{
+            "resource_id": "res-1",
+            "start_date": "2024-06-09",
+            "slots": [
+                {"start_time": "17:00:00", "duration": 60, "price": "24.5 GBP"},
+                {"start_time": "17:00:00", "duration": 90, "price": "36 GBP"},
+            ],
+        }]
+
+        slots = scraper.parse_availability(entries, {"res-1": "Centre Court"}, "2024-06-09")
+
+        assert len(slots) == 2
+        assert slots[0].court_id == "res-1"
+        assert slots[0].court_name == "Centre Court"
+        assert slots[0].start_time == "18:00"  # BST is UTC+1
+        assert slots[0].end_time == "19:00"
+        assert slots[0].price == 24.5
+        assert slots[0].currency == "GBP"
+        assert slots[0].booking_url == "https://playtomic.io/example-club/tenant-123?date=2024-06-09"
+        assert slots[1].end_time == "19:30"
+
+    def test_parse_availability_skips_other_dates_and_unknown_courts(self):
+        """Test that slots outside the local date or on unknown resources are dropped."""
+        scraper = PlaytomicScraper(make_venue())
+        entries = [
+            {"resource_id": "res-1", "start_date": "2024-06-09",
+             "slots": [{"start_time": "23:30:00", "duration": 60, "price": "20 GBP"}]},  # 00:30 on the 10th locally
+            {"resource_id": "padel-1", "start_date": "2024-06-09",
+             "slots": [{"start_time": "10:00:00", "duration": 60, "price": "20 GBP"}]},
+        ]
+
+        assert scraper.parse_availability(entries, {"res-1": "Centre Court"}, "2024-06-09") == []
+
+    def test_fetch_availability_follows_pages(self):
+        """Test that availability is requested page by page until a short page."""
+        scraper = PlaytomicScraper(make_venue(page_size=2))
+        scraper.session.get = Mock(side_effect=[
+            json_response([{"resource_id": "a"}, {"resource_id": "b"}]),
+            json_response([{"resource_id": "c"}]),
+        ])
+
+        entries = scraper._fetch_availability("2024-06-09")
+
+        assert [e["resource_id"] for e in entries] == ["a", "b", "c"]
+        assert scraper.session.get.call_count == 2
+        params = scraper.session.get.call_args.kwargs["params"]
+        assert params["tenant_id"] == "tenant-123"
+        assert params["page"] == 1
+        assert params["start_min"] == "2024-06-08T23:00:00"
+        assert params["start_max"] == "2024-06-09T22:59:59"
+
+    def test_fetch_court_names_filters_sport_and_prefers_venue_names(self):
+        """Test that only tennis resources are kept and venue court names win."""
+        scraper = PlaytomicScraper(make_venue())
+        scraper.session.get = Mock(return_value=json_response({"resources": [
+            {"resource_id": "res-1", "name": "Pista 1", "sport_id": "TENNIS"},
+            {"resource_id": "res-2", "name": "Court 2", "sport_id": "TENNIS"},
+            {"resource_id": "padel-1", "name": "Padel 1", "sport_id": "PADEL"},
+        ]}))
+
+        assert scraper._fetch_court_names() == {"res-1": "Centre Court", "res-2": "Court 2"}
+
+    def test_proxied_venue_rotates_proxies_per_request(self):
+        """Test that each API request goes through the next proxy and block responses count against it."""
+        pool = ProxyPool([Proxy('a', 'http://a:8080'), Proxy('b', 'http://b:8080')], failure_threshold=1)
+        scraper = PlaytomicScraper(make_venue(use_proxy=True), pool)
+        blocked = json_response({})
+        blocked.status_code = 429
+        scraper.session.get = Mock(side_effect=[blocked, json_response({"resources": []})])
+
+        scraper._get("/v1/tenants/tenant-123")
+        scraper._get("/v1/tenants/tenant-123")
+
+        proxies = [c.kwargs["proxies"] for c in scraper.session.get.call_args_list]
+        assert proxies == [{"http": "http://a:8080", "https": "http://a:8080"},
+                           {"http": "http://b:8080", "https": "http://b:8080"}]
+        assert pool.status()["benched"] == ["a"]
+
+    def test_venue_without_opt_in_connects_directly(self):
+        """Test that venues that haven't set use_proxy ignore the pool."""
+        scraper = PlaytomicScraper(make_venue(), ProxyPool([Proxy('a', 'http://a:8080')]))
+        scraper.session.get = Mock(return_value=json_response({}))
+
+        scraper._get("/v1/tenants/tenant-123")
+
+        assert scraper.session.get.call_args.kwargs["proxies"] is None
+
+    @pytest.mark.asyncio
+    async def test_retries_after_api_failure(self):
+        """Test that a scrape whose API call fails is retried and succeeds on the next attempt."""
+        venue = make_venue()
+        venue["scraper_config"]["retry_count"] = 2
+        scraper = PlaytomicScraper(venue)
+        scraper.retry_backoff_seconds = 0
+        scraper.session.get = Mock(side_effect=[
+            ConnectionError("connection reset by peer"),
+            json_response({"resources": []}),
+        ])
+
+        result = await scraper.scrape_with_retries([])
+
+        assert result.success is True
+        assert result.attempts == 2
+        assert scraper.session.get.call_count == 2
+
+    @pytest.mark.asyncio
+    async def test_missing_tenant_id_fails_scrape(self):
+        """Test that a venue without a tenant id reports an error instead of scraping."""
+        venue = make_venue()
+        del venue["scraper_config"]["custom_parameters"]["tenant_id"]
+
+        result = await PlaytomicScraper(venue).scrape_availability(["2024-06-09"])
+
+        assert result.success is False
+        assert "tenant_id" in result.errors[0]
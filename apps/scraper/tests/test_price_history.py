@@ -0,0 +1,87 @@
+import sys
+import os
+from datetime import datetime
+
+# Add the src directory to the Python path
+sys.path.append(os.path.join(os.path.dirname(__file__), '..', 'src'))
+
+from scrapers.price_history import price_changes, record_price_changes
+
+OBSERVED_AT = datetime(2024, 1, 10, 9, 0)
+
+def slot(court_id="1", date="2024-01-15", start_time="18:00", price=12.0):
+    return {
+        "venue_id": "venue1",
+        "court_id": court_id,
+        "court_name": f"Court {court_id}",
+        "date": date,
+        "start_time": start_time,
+        "price": price,
+        "currency": "GBP",
+    }
+
+class FakePriceHistoryCollection:
+    """Answers the last-price aggregation from the documents it was given"""
+    def __init__(self, recorded=None):
+        self.recorded = list(recorded or [])
+        self.inserted = []
+
+    def aggregate(self, pipeline):
+        last = {}
+        for doc in sorted(self.recorded, key=lambda d: d["observed_at"]):
+            last[(doc["court_id"], doc["date"], doc["start_time"])] = doc["price"]
+        return [{"_id": {"court_id": k[0], "date": k[1], "start_time": k[2]}, "price": v} for k, v in last.items()]
+
+    def insert_many(self, docs):
+        self.inserted.extend(docs)
+
+
+class TestPriceChanges:
+    def test_first_sighting_is_recorded(self):
+        """Test that a slot's first price is recorded."""
+        changes = price_changes([slot()], {}, OBSERVED_AT)
+
+        assert len(changes) == 1
+        assert changes[0]["price"] == 12.0
+        assert changes[0]["observed_at"] == OBSERVED_AT
+
+    def test_unchanged_price_is_skipped(self):
+        """Test that only prices that differ from the last recorded one are kept."""
+        last_prices = {("1", "2024-01-15", "18:00"): 12.0, ("2", "2024-01-15", "18:00"): 12.0}
+
+        changes = price_changes([slot(), slot(court_id="2", price=9.5)], last_prices, OBSERVED_AT)
+
+        assert [c["court_id"] for c in changes] == ["2"]
+        assert last_prices[("2", "2024-01-15", "18:00")] == 9.5
+
+    def test_missing_price_is_skipped(self):
+        """Test that slots without a price don't add to the history."""
+        assert price_changes([slot(price=None)], {}, OBSERVED_AT) == []
+
+
+class TestRecordPriceChanges:
+    def test_records_changes_since_last_scrape(self):
+        """Test that only changed or new slot prices are inserted."""
+        collection = FakePriceHistoryCollection(recorded=[
+            {"court_id": "1", "date": "2024-01-15", "start_time": "18:00", "price": 10.0, "observed_at": datetime(2024, 1, 1)},
+            {"court_id": "1", "date": "2024-01-15", "start_time": "18:00", "price": 12.0, "observed_at": datetime(2024, 1, 5)},
+            {"court_id": "1", "date": "2024-01-15", "start_time": "19:00", "price": 12.0, "observed_at": datetime(2024, 1, 5)},
+        ])
+
+        recorded = record_price_changes(collection, [
+            slot(),                                 # Same as the latest price
+            slot(start_time="19:00", price=15.0),   # Peak pricing kicked in
+            slot(date="2024-01-16"),                # Not seen before
+        ], OBSERVED_AT)
+
+        assert recorded == 2
+        assert [(c["start_time"], c["date"], c["price"]) for c in collection.inserted] == [
+            ("19:00", "2024-01-15", 15.0),
+            ("18:00", "2024-01-16", 12.0),
+        ]
+
+    def test_no_slots(self):
+        """Test that an empty scrape records nothing."""
+        collection = FakePriceHistoryCollection()
+        assert record_price_changes(collection, []) == 0
+        assert collection.inserted == []
@@ -0,0 +1,138 @@
+import sys
+import os
+import json
+from datetime import datetime
+
+# Add the src directory to the Python path
+sys.path.append(os.path.join(os.path.dirname(__file__), '..', 'src'))
+
+from redis_publisher import (
+    RedisPublisher,
+    availability_event,
+    AVAILABILITY_CHANNEL,
+    AVAILABILITY_SCHEMA_VERSION,
+    SLOT_QUEUE,
+)
+
+def slot(**overrides):
+    data = {
+        'venueId': '64f8a123b456789012345678',
+        'venueName': 'Victoria Park',
+        'platform': 'courtside',
+        'courtId': '1',
+        'courtName': 'Court 1',
+        'date': '2025-06-10',
+        'startTime': '19:00',
+        'endTime': '20:00',
+        'price': 8.0,
+        'currency': 'GBP',
+        'isAvailable': True,
+        'bookingUrl': 'https://example.com/book',
+        'scrapedAt': '2025-06-09T08:00:00Z',
+    }
+    data.update(overrides)
+    return data
+
+class FakePipeline:
+    """Records the commands queued in a MULTI/EXEC"""
+    def __init__(self, client, transaction):
+        self.client = client
+        self.transaction = transaction
+        self.commands = []
+
+    def lpush(self, key, value):
+        self.commands.append(('lpush', key, value))
+
+    def publish(self, channel, message):
+        self.commands.append(('publish', channel, message))
+
+    def execute(self):
+        if self.client.fail:
+            raise ConnectionError("redis down")
+        self.client.executed.append(self)
+
+class FakeRedis:
+    def __init__(self, fail=False):
+        self.fail = fail
+        self.executed = []
+
+    def pipeline(self, transaction=True):
+        return FakePipeline(self, transaction)
+
+def publisher(client):
+    p = RedisPublisher(redis_host='localhost', redis_port=6379)
+    p.client = client
+    return p
+
+
+class TestAvailabilityEvent:
+    def test_converts_slot(self):
+        """Test that a scraped slot becomes a versioned snake_case event."""
+        event = availability_event(slot())
+
+        assert event == {
+            'schema_version': AVAILABILITY_SCHEMA_VERSION,
+            'venue_id': '64f8a123b456789012345678',
+            'venue_name': 'Victoria Park',
+            'platform': 'courtside',
+            'court_id': '1',
+            'court_name': 'Court 1',
+            'date': '2025-06-10',
+            'start_time': '19:00',
+            'end_time': '20:00',
+            'price': 8.0,
+            'currency': 'GBP',
+            'booking_url': 'https://example.com/book',
+            'available': True,
+            'discovered_at': '2025-06-09T08:00:00Z',
+        }
+
+    def test_booked_slot(self):
+        """Test that slots that were just booked are published as unavailable."""
+        assert availability_event(slot(isAvailable=False))['available'] is False
+
+    def test_discovered_at_defaults_to_now_in_utc(self):
+        """Test that a missing or datetime scrape time is written the way the backend parses it."""
+        assert availability_event(slot(scrapedAt=datetime(2025, 6, 9, 8, 0)))['discovered_at'] == '2025-06-09T08:00:00Z'
+
+        data = slot()
+        del data['scrapedAt']
+        assert availability_event(data)['discovered_at'].endswith('Z')
+
+
+class TestPublishAvailability:
+    def test_writes_queue_and_channel_atomically(self):
+        """Test that one transaction pushes the same event to the queue and the channel."""
+        client = FakeRedis()
+
+        assert publisher(client).publish_slot(slot()) is True
+
+        assert len(client.executed) == 1
+        pipe = client.executed[0]
+        assert pipe.transaction is True
+        assert [(c[0], c[1]) for c in pipe.commands] == [('lpush', SLOT_QUEUE), ('publish', AVAILABILITY_CHANNEL)]
+        assert pipe.commands[0][2] == pipe.commands[1][2]
+        assert json.loads(pipe.commands[0][2])['schema_version'] == AVAILABILITY_SCHEMA_VERSION
+
+    def test_stamps_schema_version(self):
+        """Test that events without a schema version get the current one."""
+        client = FakeRedis()
+
+        assert publisher(client).publish_availability({'venue_id': 'v1'}) is True
+        assert json.loads(client.executed[0].commands[0][2])['schema_version'] == AVAILABILITY_SCHEMA_VERSION
+
+    def test_missing_field_is_not_published(self):
+        """Test that incomplete slots are rejected before anything is written."""
+        client = FakeRedis()
+        data = slot()
+        del data['bookingUrl']
+
+        assert publisher(client).publish_slot(data) is False
+        assert client.executed == []
+
+    def test_redis_failure(self):
+        """Test that a failed transaction is reported and counted as unpublished."""
+        p = publisher(FakeRedis(fail=True))
+
+        assert p.publish_slot(slot()) is False
+        assert p.publish_new_slots([slot(), slot(courtId='2')]) == 0
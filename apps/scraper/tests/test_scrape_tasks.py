@@ -0,0 +1,165 @@
+import sys
+import os
+from datetime import datetime, timedelta
+
+# Add the src directory to the Python path
+sys.path.append(os.path.join(os.path.dirname(__file__), '..', 'src'))
+
+from scrapers.base_scraper import ScrapedSlot
+from scrapers.scrape_tasks import ScrapeTaskTracker, build_scrape_tasks, fair_order
+
+
+def make_venue(courts, scraping_interval=30, venue_id='venue1'):
+    return {
+        '_id': venue_id,
+        'name': 'Test Venue',
+        'scraping_interval': scraping_interval,
+        'courts': courts,
+    }
+
+
+def make_slot(court_id, court_name):
+    return ScrapedSlot(
+        venue_id='venue1',
+        venue_name='Test Venue',
+        court_id=court_id,
+        court_name=court_name,
+        date='2024-01-01',
+        start_time='18:00',
+        end_time='19:00',
+        price=20.0,
+    )
+
+
+class TestBuildScrapeTasks:
+    def test_venue_without_overrides_is_one_task(self):
+        """Test that a venue whose courts share its interval gets a single venue-wide task."""
+        venue = make_venue([{'id': '1', 'name': 'Court 1'}, {'id': '2', 'name': 'Court 2'}])
+
+        tasks = build_scrape_tasks(venue, default_interval=60)
+
+        assert len(tasks) == 1
+        assert tasks[0].interval_minutes == 30
+        assert tasks[0].court_ids == ()
+        assert tasks[0].key == 'venue1'
+
+    def test_missing_venue_interval_uses_default(self):
+        """Test that the scheduler's interval applies when the venue has none."""
+        venue = make_venue([], scraping_interval=0)
+
+        assert build_scrape_tasks(venue, default_interval=60)[0].interval_minutes == 60
+
+    def test_court_overrides_get_their_own_tasks(self):
+        """Test that each override interval becomes a separate task with a distinct key."""
+        venue = make_venue([
+            {'id': '1', 'name': 'Court 1'},
+            {'id': '2', 'name': 'Court 2', 'scrape_interval_minutes': 5},
+            {'id': '3', 'name': 'Court 3', 'scrape_interval_minutes': 5},
+            {'id': '4', 'name': 'Court 4', 'scrape_interval_minutes': 10},
+            {'id': '5', 'name': 'Court 5', 'scrape_interval_minutes': 30},
+        ])
+
+        tasks = build_scrape_tasks(venue, default_interval=60)
+
+        assert [(t.interval_minutes, t.court_ids) for t in tasks] == [
+            (30, ()),
+            (5, ('2', '3')),
+            (10, ('4',)),
+        ]
+        assert tasks[0].excluded_court_ids == ('2', '3', '4')
+        assert len({t.key for t in tasks}) == 3
+
+    def test_tasks_keep_only_their_courts(self):
+        """Test that court-scoped tasks keep their courts' slots and the venue task keeps the rest."""
+        courts = [
+            {'id': '1', 'name': 'Court 1'},
+            {'id': '2', 'name': 'Court 2', 'scrape_interval_minutes': 5},
+        ]
+        venue_task, court_task = build_scrape_tasks(make_venue(courts), default_interval=60)
+
+        # Platforms may report their own court ids, so names match too
+        floodlit = make_slot('court-2', 'Court 2')
+        other = make_slot('court-1', 'Court 1')
+
+        assert court_task.covers(floodlit, courts)
+        assert not court_task.covers(other, courts)
+        assert venue_task.covers(other, courts)
+        assert not venue_task.covers(floodlit, courts)
+
+
+class TestScrapeTaskTracker:
+    def test_only_due_tasks_run(self):
+        """Test that court tasks run on their own interval without rerunning the venue task."""
+        venue = make_venue([
+            {'id': '1', 'name': 'Court 1'},
+            {'id': '2', 'name': 'Court 2', 'scrape_interval_minutes': 5},
+        ])
+        tracker = ScrapeTaskTracker(default_interval=60)
+        start = datetime(2024, 1, 1, 9, 0)
+
+        due = tracker.due_tasks([venue], start)
+        assert len(due) == 2
+        for _, task in due:
+            tracker.mark_run(task, start)
+
+        assert tracker.next_due_time() == start + timedelta(minutes=5)
+
+        due = tracker.due_tasks([venue], start + timedelta(minutes=5))
+        assert [task.court_ids for _, task in due] == [('2',)]
+
+        due = tracker.due_tasks([venue], start + timedelta(minutes=30))
+        assert len(due) == 2
+
+    def test_removed_tasks_are_forgotten(self):
+        """Test that a venue that is no longer active doesn't hold up the next run."""
+        tracker = ScrapeTaskTracker(default_interval=60)
+        start = datetime(2024, 1, 1, 9, 0)
+
+        for _, task in tracker.due_tasks([make_venue([], scraping_interval=5)], start):
+            tracker.mark_run(task, start)
+
+        assert tracker.due_tasks([], start + timedelta(minutes=10)) == []
+        assert tracker.next_due_time() is None
+
+    def test_queue_status_reports_depth_and_waits(self):
+        """Test that pending tasks are counted per venue until they run, and their wait is recorded."""
+        busy = make_venue([{'id': '2', 'name': 'Court 2', 'scrape_interval_minutes': 5}], venue_id='busy')
+        quiet = make_venue([], venue_id='quiet')
+        tracker = ScrapeTaskTracker(default_interval=60)
+        start = datetime(2024, 1, 1, 9, 0)
+
+        due = tracker.due_tasks([busy, quiet], start)
+        status = tracker.queue_status(start + timedelta(seconds=30))
+        assert status['depth'] == 3
+        assert status['venues']['busy']['pending'] == 2
+        assert status['venues']['busy']['oldest_wait_seconds'] == 30
+
+        for _, task in due:
+            tracker.mark_run(task, start + timedelta(seconds=45))
+
+        status = tracker.queue_status(start + timedelta(minutes=1))
+        assert status['depth'] == 0
+        assert status['venues'] == {}
+        assert status['last_wait_seconds'] == {'busy': 45, 'quiet': 45}
+
+
+class TestFairOrder:
+    def test_venues_take_turns(self):
+        """Test that a venue with many due tasks can't push other venues to the back."""
+        start = datetime(2024, 1, 1, 9, 0)
+        busy = make_venue([
+            {'id': '1', 'name': 'Court 1', 'scrape_interval_minutes': 5},
+            {'id': '2', 'name': 'Court 2', 'scrape_interval_minutes': 10},
+        ], venue_id='busy')
+        quiet = make_venue([], venue_id='quiet')
+        busy_tasks = build_scrape_tasks(busy, default_interval=60)
+        quiet_task = build_scrape_tasks(quiet, default_interval=60)[0]
+
+        due = [(busy, task, start + timedelta(minutes=i)) for i, task in enumerate(busy_tasks)]
+        due.append((quiet, quiet_task, start + timedelta(minutes=1)))
+
+        ordered = [task for _, task in fair_order(due)]
+
+        assert ordered[0] is busy_tasks[0]  # Most overdue overall
+        assert ordered[1] is quiet_task
+        assert ordered[2:] == busy_tasks[1:]
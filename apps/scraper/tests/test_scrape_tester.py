@@ -0,0 +1,135 @@
+import asyncio
+import json
+import sys
+import os
+import time
+import pytest
+from datetime import date, timedelta
+
+# Add the src directory to the Python path
+sys.path.append(os.path.join(os.path.dirname(__file__), '..', 'src'))
+
+from scrapers.base_scraper import BaseScraper, ScrapedSlot
+from scrapers.scrape_tester import (
+    MAX_TEST_TIMEOUT_SECONDS, TEST_SCRAPE_REPLY_PREFIX, ScrapeTestWorker,
+    request_timeout, run_test_scrape, selectors_to_check,
+)
+
+TOMORROW = (date.today() + timedelta(days=1)).strftime("%Y-%m-%d")
+
+class FakeScraper(BaseScraper):
+    """Returns one slot for each date, or hangs when the config asks it to"""
+    async def scrape_availability(self, target_dates):
+        if self.scraper_config.get('hang'):
+            await asyncio.sleep(10)
+        slots = [ScrapedSlot(venue_id=self.venue_id, venue_name=self.venue_name, court_id="c1",
+                             court_name="Court 1", date=d, start_time="18:00", end_time="19:00", price=12.5)
+                 for d in target_dates]
+        return self.create_scraping_result(True, slots, [], 5)
+
+class FakeRedis:
+    def __init__(self):
+        self.lists = {}
+        self.expiries = {}
+
+    def rpush(self, key, value):
+        self.lists.setdefault(key, []).append(value)
+
+    def expire(self, key, seconds):
+        self.expiries[key] = seconds
+
+def request(**overrides):
+    req = {
+        "id": "req1",
+        "url": "https://example.com/book",
+        "date": TOMORROW,
+        "scraper_config": {"type": "fake"},
+    }
+    req.update(overrides)
+    return req
+
+
+class TestRequestSettings:
+    def test_timeout_defaults_and_caps(self):
+        """Test that a missing timeout gets the default and a long one is capped."""
+        assert request_timeout({}) == 15
+        assert request_timeout({"timeout_seconds": 5}) == 5
+        assert request_timeout({"timeout_seconds": 600}) == MAX_TEST_TIMEOUT_SECONDS
+
+    def test_selector_mappings_override_platform_defaults(self):
+        """Test that diagnostics cover default selectors unless the config replaces them."""
+        selectors = selectors_to_check({"type": "courtside", "selector_mappings": {"court_widget": ".widget", "extra": ".x"}})
+        assert selectors["court_widget"] == ".widget"
+        assert selectors["bookable_input"] == "input.bookable"
+        assert selectors["extra"] == ".x"
+
+        assert selectors_to_check({"type": "playtomic"}) == {}
+
+
+class TestRunTestScrape:
+    @pytest.mark.asyncio
+    async def test_returns_slots_for_the_date(self):
+        """Test that a test scrape returns the slots for the requested date."""
+        reply = await run_test_scrape(request(), {"fake": FakeScraper})
+
+        assert reply["success"] is True
+        assert reply["request_id"] == "req1"
+        assert reply["date"] == TOMORROW
+        assert len(reply["slots"]) == 1
+        assert reply["slots"][0]["court_name"] == "Court 1"
+        assert reply["slots"][0]["price"] == 12.5
+        assert reply["errors"] == []
+
+    @pytest.mark.asyncio
+    async def test_unknown_platform(self):
+        """Test that a platform without a scraper is reported as an error."""
+        reply = await run_test_scrape(request(scraper_config={"type": "unknown"}), {"fake": FakeScraper})
+
+        assert reply["success"] is False
+        assert "No scraper available" in reply["errors"][0]
+
+    @pytest.mark.asyncio
+    async def test_past_date_is_rejected(self):
+        """Test that dates before today aren't scraped."""
+        yesterday = (date.today() - timedelta(days=2)).strftime("%Y-%m-%d")
+        reply = await run_test_scrape(request(date=yesterday), {"fake": FakeScraper})
+
+        assert reply["success"] is False
+        assert "in the past" in reply["errors"][0]
+
+    @pytest.mark.asyncio
+    async def test_scrape_is_bounded_by_timeout(self):
+        """Test that a hanging scrape is cut off at the request's timeout."""
+        req = request(scraper_config={"type": "fake", "hang": True}, timeout_seconds=1)
+
+        started = time.time()
+        reply = await run_test_scrape(req, {"fake": FakeScraper})
+
+        assert time.time() - started < 5
+        assert reply["success"] is False
+        assert "timed out" in reply["errors"][0]
+
+
+class TestScrapeTestWorker:
+    @pytest.mark.asyncio
+    async def test_pushes_reply_to_request_key(self):
+        """Test that the worker replies on the request's own key, with an expiry."""
+        redis = FakeRedis()
+        worker = ScrapeTestWorker(redis, {"fake": FakeScraper})
+
+        reply = await worker.handle(json.dumps(request()))
+
+        key = TEST_SCRAPE_REPLY_PREFIX + "req1"
+        assert json.loads(redis.lists[key][0])["slots"] == reply["slots"]
+        assert redis.expiries[key] > 0
+
+    @pytest.mark.asyncio
+    async def test_drops_expired_and_invalid_requests(self):
+        """Test that requests the backend stopped waiting for, or can't be read, are dropped."""
+        redis = FakeRedis()
+        worker = ScrapeTestWorker(redis, {"fake": FakeScraper})
+
+        assert await worker.handle(json.dumps(request(expires_at=time.time() - 1))) is None
+        assert await worker.handle("not json") is None
+        assert await worker.handle(json.dumps({"url": "https://example.com"})) is None
+        assert redis.lists == {}
@@ -0,0 +1,147 @@
+import sys
+import os
+from datetime import datetime, timedelta
+from unittest.mock import Mock, patch
+
+# Add the src directory to the Python path
+sys.path.append(os.path.join(os.path.dirname(__file__), '..', 'src'))
+
+from scrapers.selector_health import (
+    SelectorHealthConfig,
+    SelectorHealthMonitor,
+    SelectorSuspicion,
+    baseline_pipeline,
+    slot_count,
+)
+
+
+CONFIG = SelectorHealthConfig(threshold=3, baseline_days=7, min_hit_rate=0.5, min_baseline_scrapes=10)
+NOW = datetime(2024, 6, 1, 12, 0)
+
+
+class FakeCursor:
+    def __init__(self, docs):
+        self.docs = docs
+
+    def sort(self, field, direction):
+        self.docs = sorted(self.docs, key=lambda doc: doc[field], reverse=direction < 0)
+        return self
+
+    def limit(self, n):
+        self.docs = self.docs[:n]
+        return self
+
+    def __iter__(self):
+        return iter(self.docs)
+
+
+class FakeLogs:
+    """Recent logs come from find; the baseline comes from aggregate"""
+
+    def __init__(self, recent, baseline=None, error=None):
+        self.recent = recent
+        self.baseline = baseline
+        self.error = error
+        self.pipelines = []
+
+    def find(self, query, projection=None):
+        if self.error:
+            raise self.error
+        return FakeCursor(list(self.recent))
+
+    def aggregate(self, pipeline):
+        self.pipelines.append(pipeline)
+        return iter([self.baseline] if self.baseline else [])
+
+
+class FakeDB:
+    def __init__(self, logs):
+        self.scraping_logs = logs
+
+
+def empty_logs(count, flagged=False):
+    return [{'slots_found': 0, 'scrape_timestamp': NOW - timedelta(minutes=30 * (i + 1)),
+             'suspected_broken_selector': flagged} for i in range(count)]
+
+
+class TestSelectorHealthMonitor:
+    def test_flags_venue_that_stops_finding_slots(self):
+        """Test that a busy venue's third empty scrape in a row is flagged as a new streak."""
+        logs = FakeLogs(empty_logs(2), baseline={'scrapes': 20, 'hits': 18})
+        monitor = SelectorHealthMonitor(CONFIG, webhook_url='')
+
+        suspicion = monitor.evaluate(FakeDB(logs), 'venue1', NOW)
+
+        assert suspicion == SelectorSuspicion(empty_scrapes=3, baseline_hit_rate=0.9, baseline_scrapes=20, new_streak=True)
+        # The baseline ends where the streak starts
+        window = logs.pipelines[0][0]['$match']['scrape_timestamp']
+        assert window['$lt'] == NOW - timedelta(minutes=60)
+        assert window['$gte'] == NOW - timedelta(minutes=60, days=7)
+
+    def test_ignores_short_streaks_and_quiet_venues(self):
+        """Test that venues are not flagged without a full streak or a busy baseline."""
+        monitor = SelectorHealthMonitor(CONFIG, webhook_url='')
+        busy = {'scrapes': 20, 'hits': 18}
+
+        assert monitor.evaluate(FakeDB(FakeLogs(empty_logs(1), busy)), 'venue1', NOW) is None
+
+        recent = empty_logs(2)
+        recent[0]['slots_found'] = [{'court': '1'}]  # A backend log that found a slot
+        assert monitor.evaluate(FakeDB(FakeLogs(recent, busy)), 'venue1', NOW) is None
+
+        assert monitor.evaluate(FakeDB(FakeLogs(empty_logs(2), {'scrapes': 20, 'hits': 4})), 'venue1', NOW) is None
+        assert monitor.evaluate(FakeDB(FakeLogs(empty_logs(2), {'scrapes': 5, 'hits': 5})), 'venue1', NOW) is None
+        assert monitor.evaluate(FakeDB(FakeLogs([], error=RuntimeError('mongo down'))), 'venue1', NOW) is None
+
+    def test_continuing_streak_is_not_new(self):
+        """Test that only the first flagged scrape in a streak asks for an alert."""
+        logs = FakeLogs(empty_logs(2, flagged=True), baseline={'scrapes': 20, 'hits': 18})
+
+        suspicion = SelectorHealthMonitor(CONFIG, webhook_url='').evaluate(FakeDB(logs), 'venue1', NOW)
+
+        assert suspicion is not None
+        assert not suspicion.new_streak
+
+    def test_alert_posts_to_ops_webhook(self):
+        """Test that the alert is posted with the venue and the evidence."""
+        monitor = SelectorHealthMonitor(CONFIG, webhook_url='https://hooks.example.com/ops')
+        suspicion = SelectorSuspicion(empty_scrapes=3, baseline_hit_rate=0.9, baseline_scrapes=20, new_streak=True)
+
+        with patch('scrapers.selector_health.requests.post') as post:
+            post.return_value = Mock(raise_for_status=Mock(return_value=None))
+            assert monitor.alert('venue1', 'Victoria Park', 'clubspark', suspicion, NOW)
+
+        assert post.call_args.args[0] == 'https://hooks.example.com/ops'
+        payload = post.call_args.kwargs['json']
+        assert payload['event'] == 'suspected_broken_selector'
+        assert payload['venue_name'] == 'Victoria Park'
+        assert payload['empty_scrapes'] == 3
+        assert 'Victoria Park' in payload['text']
+
+    def test_alert_without_webhook_only_logs(self):
+        """Test that nothing is posted when no ops webhook is configured."""
+        suspicion = SelectorSuspicion(empty_scrapes=3, baseline_hit_rate=0.9, baseline_scrapes=20, new_streak=True)
+
+        with patch('scrapers.selector_health.requests.post') as post:
+            assert not SelectorHealthMonitor(CONFIG, webhook_url='').alert('venue1', 'Victoria Park', 'clubspark', suspicion, NOW)
+
+        post.assert_not_called()
+
+
+class TestSelectorHealthConfig:
+    def test_reads_environment(self, monkeypatch):
+        """Test that invalid settings fall back to the defaults."""
+        monkeypatch.setenv('SCRAPER_SELECTOR_ALERT_THRESHOLD', '5')
+        monkeypatch.setenv('SCRAPER_SELECTOR_MIN_HIT_RATE', '1.5')
+
+        config = SelectorHealthConfig.from_env()
+
+        assert config.threshold == 5
+        assert config.min_hit_rate == SelectorHealthConfig().min_hit_rate
+
+    def test_slot_count_handles_counts_and_lists(self):
+        """Test that slots_found is counted whether the log holds a count or the slots."""
+        assert slot_count(4) == 4
+        assert slot_count([{}, {}]) == 2
+        assert slot_count(None) == 0
+        assert baseline_pipeline('venue1', NOW, NOW)[0]['$match']['success'] is True
@@ -0,0 +1,60 @@
+import sys
+import os
+
+# Add the src directory to the Python path
+sys.path.append(os.path.join(os.path.dirname(__file__), '..', 'src'))
+
+from scrapers.venue_session import SessionStore, credential_env_prefix, get_venue_credentials
+
+
+class TestVenueCredentials:
+    def test_env_prefix_normalises_key(self):
+        """Test that credential keys become valid environment variable prefixes."""
+        assert credential_env_prefix("665f1c2ab3e4d5f6a7b8c9d0") == "VENUE_665F1C2AB3E4D5F6A7B8C9D0"
+        assert credential_env_prefix("stratford-park") == "VENUE_STRATFORD_PARK"
+
+    def test_credentials_keyed_by_venue_id(self, monkeypatch):
+        """Test that credentials are found by venue id when no credential key is set."""
+        monkeypatch.setenv("VENUE_ABC123_USERNAME", "player@example.com")
+        monkeypatch.setenv("VENUE_ABC123_PASSWORD", "secret")
+
+        assert get_venue_credentials("abc123") == ("player@example.com", "secret")
+
+    def test_credential_key_takes_precedence(self, monkeypatch):
+        """Test that scraper_config.credential_key overrides the venue id."""
+        monkeypatch.setenv("VENUE_ABC123_USERNAME", "by-id")
+        monkeypatch.setenv("VENUE_ABC123_PASSWORD", "by-id")
+        monkeypatch.setenv("VENUE_LTA_MAIN_USERNAME", "by-key")
+        monkeypatch.setenv("VENUE_LTA_MAIN_PASSWORD", "by-key")
+
+        assert get_venue_credentials("abc123", "lta-main") == ("by-key", "by-key")
+
+    def test_missing_password_returns_none(self, monkeypatch):
+        """Test that a partial login is treated as no login."""
+        monkeypatch.setenv("VENUE_XYZ_USERNAME", "player@example.com")
+        monkeypatch.delenv("VENUE_XYZ_PASSWORD", raising=False)
+
+        assert get_venue_credentials("xyz") is None
+
+
+class TestSessionStore:
+    def test_round_trip(self, tmp_path):
+        """Test that a saved session is found on the next scrape and can be cleared."""
+        store = SessionStore(str(tmp_path / "sessions"))
+        assert store.load("venue1") is None
+
+        path = store.save_path("venue1")
+        with open(path, "w") as f:
+            f.write('{"cookies": []}')
+
+        assert store.load("venue1") == path
+        store.clear("venue1")
+        assert store.load("venue1") is None
+
+        # Clearing a missing session is a no-op
+        store.clear("venue1")
+
+    def test_venue_id_cannot_escape_directory(self, tmp_path):
+        """Test that odd venue ids still map to a file inside the session directory."""
+        store = SessionStore(str(tmp_path))
+        assert store.path_for("../../etc/passwd").parent == tmp_path